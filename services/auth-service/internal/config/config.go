@@ -8,15 +8,19 @@ import (
 )
 
 type Config struct {
-	Environment string    `mapstructure:"environment"`
-	Port        int       `mapstructure:"port"`
-	LogLevel    string    `mapstructure:"log_level"`
-	Database    Database  `mapstructure:"database"`
-	Redis       Redis     `mapstructure:"redis"`
-	NATS        NATS      `mapstructure:"nats"`
-	JWT         JWT       `mapstructure:"jwt"`
-	Email       Email     `mapstructure:"email"`
-	RateLimit   RateLimit `mapstructure:"rate_limit"`
+	Environment  string       `mapstructure:"environment"`
+	Port         int          `mapstructure:"port"`
+	LogLevel     string       `mapstructure:"log_level"`
+	Database     Database     `mapstructure:"database"`
+	Redis        Redis        `mapstructure:"redis"`
+	NATS         NATS         `mapstructure:"nats"`
+	JWT          JWT          `mapstructure:"jwt"`
+	Email        Email        `mapstructure:"email"`
+	RateLimit    RateLimit    `mapstructure:"rate_limit"`
+	Password     Password     `mapstructure:"password"`
+	Registration Registration `mapstructure:"registration"`
+	Logging      Logging      `mapstructure:"logging"`
+	ServiceAuth  ServiceAuth  `mapstructure:"service_auth"`
 }
 
 type Database struct {
@@ -45,6 +49,19 @@ type JWT struct {
 	AccessTokenTTL  time.Duration `mapstructure:"access_token_ttl"`
 	RefreshTokenTTL time.Duration `mapstructure:"refresh_token_ttl"`
 	Issuer          string        `mapstructure:"issuer"`
+	// Audience is set as the aud claim of issued tokens and required on validated ones, so tokens minted
+	// for another environment are rejected. Empty issues tokens without an audience and accepts any.
+	Audience string `mapstructure:"audience"`
+	// EmailVerificationTTL and PasswordResetTTL bound how long emailed verification and reset links work.
+	// Reset links grant account access, so their TTL must be the shorter of the two.
+	EmailVerificationTTL time.Duration `mapstructure:"email_verification_ttl"`
+	PasswordResetTTL     time.Duration `mapstructure:"password_reset_ttl"`
+	// SessionCheckFailOpen accepts a valid access token when the session store cannot be reached,
+	// instead of rejecting every authenticated request during a Redis outage.
+	SessionCheckFailOpen bool `mapstructure:"session_check_fail_open"`
+	// MaxSessionsPerUser caps how many sessions a user may hold at once, to limit account sharing.
+	// Logging in at the cap ends the user's oldest session. Zero means unlimited.
+	MaxSessionsPerUser int `mapstructure:"max_sessions_per_user"`
 }
 
 type Email struct {
@@ -60,6 +77,35 @@ type RateLimit struct {
 	AuthRequestsPerMinute int           `mapstructure:"auth_requests_per_minute"`
 }
 
+type Password struct {
+	BlocklistPath      string        `mapstructure:"blocklist_path"`
+	BreachCheckEnabled bool          `mapstructure:"breach_check_enabled"`
+	BreachCheckURL     string        `mapstructure:"breach_check_url"`
+	BreachCheckTimeout time.Duration `mapstructure:"breach_check_timeout"`
+}
+
+type Registration struct {
+	// SelfRegisterRoles lists the roles users may pick when registering themselves.
+	// Requests for any other valid role are rejected rather than downgraded.
+	SelfRegisterRoles []string `mapstructure:"self_register_roles"`
+	// AutoLoginOnRegister activates new accounts immediately and returns tokens from registration,
+	// for deployments that don't require email verification.
+	AutoLoginOnRegister bool `mapstructure:"auto_login_on_register"`
+}
+
+type Logging struct {
+	// LogBodies adds request and response bodies to request logs, with RedactFields masked.
+	// It is meant for debugging and stays off unless explicitly enabled.
+	LogBodies    bool     `mapstructure:"log_bodies"`
+	RedactFields []string `mapstructure:"redact_fields"`
+}
+
+type ServiceAuth struct {
+	// Tokens are the shared secrets other services send in the X-Service-Token header to call
+	// service-only endpoints. Several may be set to rotate them; with none those endpoints reject every call.
+	Tokens []string `mapstructure:"tokens"`
+}
+
 func Load() (*Config, error) {
 	viper.SetConfigName("config")
 	viper.SetConfigType("yaml")
@@ -83,6 +129,19 @@ func Load() (*Config, error) {
 	viper.BindEnv("redis.port", "REDIS_PORT")
 	viper.BindEnv("nats.url", "NATS_URL")
 	viper.BindEnv("jwt.secret", "JWT_SECRET")
+	viper.BindEnv("jwt.issuer", "JWT_ISSUER")
+	viper.BindEnv("jwt.audience", "JWT_AUDIENCE")
+	viper.BindEnv("jwt.email_verification_ttl", "JWT_EMAIL_VERIFICATION_TTL")
+	viper.BindEnv("jwt.password_reset_ttl", "JWT_PASSWORD_RESET_TTL")
+	viper.BindEnv("jwt.session_check_fail_open", "SESSION_CHECK_FAIL_OPEN")
+	viper.BindEnv("jwt.max_sessions_per_user", "MAX_SESSIONS_PER_USER")
+	viper.BindEnv("password.blocklist_path", "PASSWORD_BLOCKLIST_PATH")
+	viper.BindEnv("password.breach_check_enabled", "PASSWORD_BREACH_CHECK_ENABLED")
+	viper.BindEnv("registration.self_register_roles", "SELF_REGISTER_ROLES") // Comma-separated
+	viper.BindEnv("registration.auto_login_on_register", "AUTO_LOGIN_ON_REGISTER")
+	viper.BindEnv("logging.log_bodies", "LOG_BODIES")
+	viper.BindEnv("logging.redact_fields", "LOG_REDACT_FIELDS") // Comma-separated
+	viper.BindEnv("service_auth.tokens", "SERVICE_AUTH_TOKENS") // Comma-separated
 	viper.BindEnv("environment", "ENVIRONMENT")
 	viper.BindEnv("log_level", "LOG_LEVEL")
 
@@ -99,9 +158,25 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
 	return &config, nil
 }
 
+// Validate rejects settings the service cannot run safely with.
+func (c *Config) Validate() error {
+	if c.JWT.EmailVerificationTTL <= 0 || c.JWT.PasswordResetTTL <= 0 {
+		return fmt.Errorf("jwt.email_verification_ttl and jwt.password_reset_ttl must be positive")
+	}
+	if c.JWT.PasswordResetTTL >= c.JWT.EmailVerificationTTL {
+		return fmt.Errorf("jwt.password_reset_ttl (%s) must be shorter than jwt.email_verification_ttl (%s)",
+			c.JWT.PasswordResetTTL, c.JWT.EmailVerificationTTL)
+	}
+	return nil
+}
+
 func setDefaults() {
 	// Server defaults
 	viper.SetDefault("environment", "development")
@@ -131,6 +206,11 @@ func setDefaults() {
 	viper.SetDefault("jwt.access_token_ttl", "15m")
 	viper.SetDefault("jwt.refresh_token_ttl", "168h") // 7 days
 	viper.SetDefault("jwt.issuer", "slotwise-auth-service")
+	viper.SetDefault("jwt.audience", "slotwise-api")
+	viper.SetDefault("jwt.email_verification_ttl", "24h")
+	viper.SetDefault("jwt.password_reset_ttl", "1h")
+	viper.SetDefault("jwt.session_check_fail_open", false)
+	viper.SetDefault("jwt.max_sessions_per_user", 0) // Unlimited
 
 	// Email defaults
 	viper.SetDefault("email.provider", "sendgrid")
@@ -142,4 +222,21 @@ func setDefaults() {
 	viper.SetDefault("rate_limit.burst_size", 100)
 	viper.SetDefault("rate_limit.cleanup_interval", "1m")
 	viper.SetDefault("rate_limit.auth_requests_per_minute", 100)
+
+	// Password policy defaults
+	viper.SetDefault("password.blocklist_path", "")
+	viper.SetDefault("password.breach_check_enabled", false)
+	viper.SetDefault("password.breach_check_url", "https://api.pwnedpasswords.com")
+	viper.SetDefault("password.breach_check_timeout", "2s")
+
+	// Registration defaults
+	viper.SetDefault("registration.self_register_roles", []string{"client", "business_owner"})
+	viper.SetDefault("registration.auto_login_on_register", false)
+
+	// Logging defaults
+	viper.SetDefault("logging.log_bodies", false)
+	viper.SetDefault("logging.redact_fields", []string{"password", "currentPassword", "newPassword", "token", "accessToken", "refreshToken", "code"})
+
+	// Service-to-service defaults
+	viper.SetDefault("service_auth.tokens", []string{})
 }
@@ -64,6 +64,10 @@ func Migrate(db *gorm.DB) error {
 		return fmt.Errorf("failed to migrate Business model: %w", err)
 	}
 
+	if err := db.AutoMigrate(&models.LoginFingerprint{}); err != nil {
+		return fmt.Errorf("failed to migrate LoginFingerprint model: %w", err)
+	}
+
 	// Create indexes
 	if err := createIndexes(db); err != nil {
 		return fmt.Errorf("failed to create indexes: %w", err)
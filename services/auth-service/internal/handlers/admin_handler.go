@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/slotwise/auth-service/internal/models"
+	"github.com/slotwise/auth-service/internal/service"
+	"github.com/slotwise/auth-service/pkg/logger"
+)
+
+// userExportBatchSize is the number of users loaded per query during an export
+const userExportBatchSize = 500
+
+// AdminHandler handles admin HTTP requests
+type AdminHandler struct {
+	adminService service.AdminService
+	logger       logger.Logger
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(adminService service.AdminService, logger logger.Logger) *AdminHandler {
+	return &AdminHandler{
+		adminService: adminService,
+		logger:       logger,
+	}
+}
+
+// ExportUsers streams all users as newline-delimited JSON, one user per line.
+// Each batch is flushed as soon as it is written, so memory use does not grow with the user count.
+func (h *AdminHandler) ExportUsers(c *gin.Context) {
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", `attachment; filename="users.ndjson"`)
+	c.Status(http.StatusOK)
+
+	encoder := json.NewEncoder(c.Writer)
+	exported := 0
+	err := h.adminService.StreamUsers(c.Request.Context(), userExportBatchSize, func(batch []*models.User) error {
+		for _, user := range batch {
+			if err := encoder.Encode(user); err != nil {
+				return err
+			}
+		}
+		c.Writer.Flush()
+		exported += len(batch)
+		return nil
+	})
+	if err != nil {
+		// Headers are already sent, so the client only sees a truncated export
+		h.logger.Error("User export failed", "error", err.Error(), "exported", exported)
+		return
+	}
+
+	h.logger.Info("User export completed", "exported", exported)
+}
+
+// ForceVerifyEmail marks a user's email as verified on behalf of support and returns the updated user
+func (h *AdminHandler) ForceVerifyEmail(c *gin.Context) {
+	userID := c.Param("id")
+	adminID := c.GetString("user_id")
+
+	user, err := h.adminService.ForceVerifyEmail(c.Request.Context(), userID, adminID)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, APIResponse{
+				Success:   false,
+				Error:     &APIError{Code: "USER_NOT_FOUND", Message: "User not found"},
+				Timestamp: getCurrentTimestamp(),
+			})
+			return
+		}
+
+		h.logger.Error("Failed to force-verify email", "error", err.Error(), "user_id", userID, "admin_id", adminID)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success:   false,
+			Error:     &APIError{Code: "INTERNAL_SERVER_ERROR", Message: "An unexpected error occurred"},
+			Timestamp: getCurrentTimestamp(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, APIResponse{
+		Success:   true,
+		Data:      user,
+		Timestamp: getCurrentTimestamp(),
+	})
+}
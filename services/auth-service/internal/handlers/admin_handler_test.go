@@ -0,0 +1,102 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/slotwise/auth-service/internal/handlers"
+	"github.com/slotwise/auth-service/internal/middleware"
+	"github.com/slotwise/auth-service/internal/models"
+	"github.com/slotwise/auth-service/internal/service"
+	"github.com/slotwise/auth-service/pkg/events"
+	"github.com/slotwise/auth-service/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// verifyingUserRepo is a stubUserRepo that can also mark emails as verified
+type verifyingUserRepo struct {
+	stubUserRepo
+}
+
+func (r *verifyingUserRepo) VerifyEmail(id string) error {
+	user := r.users[id]
+	user.IsEmailVerified = true
+	user.Status = models.StatusActive
+	return nil
+}
+
+// setupForceVerifyRouter serves the force-verify route as an authenticated user with the given role
+func setupForceVerifyRouter(role string) (*gin.Engine, *verifyingUserRepo, *MockEventPublisher) {
+	userRepo := &verifyingUserRepo{stubUserRepo{users: map[string]*models.User{
+		"user-1": {ID: "user-1", Email: "no-inbox@example.com", Status: models.StatusPendingVerification},
+	}}}
+	publisher := &MockEventPublisher{}
+	testLogger := logger.New("debug")
+	adminHandler := handlers.NewAdminHandler(service.NewAdminService(userRepo, publisher, testLogger), testLogger)
+	authMiddleware := middleware.NewAuthMiddleware(nil, nil, testLogger)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		// Stands in for RequireAuth
+		c.Set("user_id", "caller-1")
+		c.Set("user_role", role)
+	})
+	router.POST("/api/v1/users/:id/verify-email", authMiddleware.RequireAdmin(), adminHandler.ForceVerifyEmail)
+	return router, userRepo, publisher
+}
+
+func TestForceVerifyEmail(t *testing.T) {
+	t.Run("Non-admin is rejected", func(t *testing.T) {
+		router, userRepo, publisher := setupForceVerifyRouter(string(models.RoleBusinessOwner))
+
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/users/user-1/verify-email", nil)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		assert.False(t, userRepo.users["user-1"].IsEmailVerified)
+		assert.Empty(t, publisher.PublishedEvents)
+	})
+
+	t.Run("Admin activates the user", func(t *testing.T) {
+		router, userRepo, publisher := setupForceVerifyRouter(string(models.RoleAdmin))
+
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/users/user-1/verify-email", nil)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var resp struct {
+			Success bool                   `json:"success"`
+			Data    map[string]interface{} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.True(t, resp.Success)
+		assert.Equal(t, string(models.StatusActive), resp.Data["status"])
+		assert.Equal(t, true, resp.Data["isEmailVerified"])
+
+		assert.True(t, userRepo.users["user-1"].IsEmailVerified)
+		assert.Equal(t, models.StatusActive, userRepo.users["user-1"].Status)
+		if assert.Len(t, publisher.PublishedEvents, 1) {
+			assert.Equal(t, events.UserEmailVerifiedEvent, publisher.PublishedEvents[0].EventType)
+			assert.Equal(t, "user-1", publisher.PublishedEvents[0].Data["userId"])
+			assert.Equal(t, "caller-1", publisher.PublishedEvents[0].Data["verifiedBy"])
+		}
+	})
+
+	t.Run("Unknown user returns 404", func(t *testing.T) {
+		router, _, publisher := setupForceVerifyRouter(string(models.RoleAdmin))
+
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/users/does-not-exist/verify-email", nil)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Empty(t, publisher.PublishedEvents)
+	})
+}
@@ -1,11 +1,13 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/slotwise/auth-service/internal/service"
+	"github.com/slotwise/auth-service/pkg/jwt"
 	"github.com/slotwise/auth-service/pkg/logger"
 )
 
@@ -61,6 +63,11 @@ type VerifyEmailRequest struct {
 	Token string `json:"token" binding:"required"`
 }
 
+// ValidateTokenRequest represents the validate token request payload
+type ValidateTokenRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
 // Magic login request types
 type PhoneLoginRequest struct {
 	Phone string `json:"phone" binding:"required"`
@@ -87,14 +94,17 @@ type APIResponse struct {
 type APIError struct {
 	Code    string `json:"code"`
 	Message string `json:"message"`
-	Details string `json:"details,omitempty"`
+	// Details is a string, or a map of field name to message for request validation failures
+	Details interface{} `json:"details,omitempty"`
+	// RemainingAttempts is set on wrong verification codes so clients can warn before lockout
+	RemainingAttempts *int `json:"remainingAttempts,omitempty"`
 }
 
 // Register handles user registration
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.respondWithError(c, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request payload", err.Error())
+		h.respondWithBindError(c, err, &req)
 		return
 	}
 
@@ -107,6 +117,8 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		Timezone:     req.Timezone,
 		Role:         req.Role,
 		BusinessName: req.BusinessName, // Pass through business name
+		IPAddress:    c.ClientIP(),
+		UserAgent:    c.GetHeader("User-Agent"),
 	}
 
 	response, err := h.authService.Register(serviceReq)
@@ -128,7 +140,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.respondWithError(c, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request payload", err.Error())
+		h.respondWithBindError(c, err, &req)
 		return
 	}
 
@@ -201,8 +213,10 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 
 	// Convert to service request
 	serviceReq := &service.LogoutRequest{
-		UserID:    userID.(string),
-		SessionID: sessionID.(string),
+		UserID:         userID.(string),
+		SessionID:      sessionID.(string),
+		TokenID:        c.GetString("token_id"),
+		TokenExpiresAt: c.GetTime("token_expires_at"),
 	}
 
 	if err := h.authService.Logout(serviceReq); err != nil {
@@ -219,6 +233,52 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 	h.respondWithSuccess(c, http.StatusOK, gin.H{"message": "Logged out successfully"})
 }
 
+// ValidateToken lets other services check a user's access token without verifying JWTs themselves.
+// It applies the same session and denylist checks as RequireAuth and answers 401 for any token it rejects.
+func (h *AuthHandler) ValidateToken(c *gin.Context) {
+	var req ValidateTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request payload", err.Error())
+		return
+	}
+
+	user, claims, err := h.authService.ValidateTokenClaims(req.Token)
+	if err != nil {
+		code, message := tokenErrorCode(err)
+		if code == "TOKEN_VALIDATION_ERROR" {
+			h.logger.Error("Token validation error", "error", err.Error(), "ip_address", c.ClientIP())
+		}
+		h.respondWithError(c, http.StatusUnauthorized, code, message, "")
+		return
+	}
+
+	h.respondWithSuccess(c, http.StatusOK, gin.H{"user": user, "claims": claims})
+}
+
+// tokenErrorCode maps a token validation error to an error code and message, as RequireAuth reports them
+func tokenErrorCode(err error) (string, string) {
+	switch {
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return "TOKEN_EXPIRED", "Token has expired"
+	case errors.Is(err, jwt.ErrTokenRevoked):
+		return "TOKEN_REVOKED", "Token has been revoked"
+	case errors.Is(err, jwt.ErrTokenNotValidYet):
+		return "TOKEN_NOT_VALID_YET", "Token is not valid yet"
+	case errors.Is(err, jwt.ErrInvalidToken):
+		return "INVALID_TOKEN", "Invalid token"
+	case errors.Is(err, jwt.ErrInvalidTokenType):
+		return "INVALID_TOKEN_TYPE", "Invalid token type"
+	case errors.Is(err, jwt.ErrInvalidIssuer):
+		return "INVALID_ISSUER", "Invalid token issuer"
+	case errors.Is(err, jwt.ErrInvalidAudience):
+		return "INVALID_AUDIENCE", "Invalid token audience"
+	case errors.Is(err, service.ErrAccountDisabled):
+		return "ACCOUNT_DISABLED", "Account is disabled"
+	default:
+		return "TOKEN_VALIDATION_ERROR", "Token validation failed"
+	}
+}
+
 // VerifyEmail handles email verification
 func (h *AuthHandler) VerifyEmail(c *gin.Context) {
 	var req VerifyEmailRequest
@@ -291,15 +351,58 @@ func (h *AuthHandler) ResetPassword(c *gin.Context) {
 	h.respondWithSuccess(c, http.StatusOK, gin.H{"message": "Password reset successfully"})
 }
 
-// Me returns the current user's information
+// Me returns the current user's information, with when they last logged in and how many sessions they have open
 func (h *AuthHandler) Me(c *gin.Context) {
 	// Get user from context (set by auth middleware)
-	user, exists := c.Get("user")
-	if !exists {
+	userID := c.GetString("user_id")
+	if userID == "" {
 		h.respondWithError(c, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated", "")
 		return
 	}
 
+	profile, err := h.authService.GetProfile(userID)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			h.respondWithError(c, http.StatusNotFound, "USER_NOT_FOUND", "User not found", "")
+			return
+		}
+		h.logger.Error("Failed to get user profile", "error", err, "user_id", userID)
+		h.respondWithError(c, http.StatusInternalServerError, "INTERNAL_SERVER_ERROR", "Failed to get user profile", "")
+		return
+	}
+
+	h.respondWithSuccess(c, http.StatusOK, gin.H{"user": profile})
+}
+
+// UpdateProfile changes only the profile fields present in the request body and returns the updated user
+func (h *AuthHandler) UpdateProfile(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		h.respondWithError(c, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated", "")
+		return
+	}
+
+	var req service.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request payload", err.Error())
+		return
+	}
+
+	user, err := h.authService.UpdateProfile(userID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUserNotFound):
+			h.respondWithError(c, http.StatusNotFound, "USER_NOT_FOUND", "User not found", "")
+		case errors.Is(err, service.ErrInvalidProfileField):
+			h.respondWithError(c, http.StatusBadRequest, "INVALID_PROFILE_FIELD", "Invalid profile field", err.Error())
+		case errors.Is(err, service.ErrInvalidLanguage):
+			h.respondWithError(c, http.StatusBadRequest, "INVALID_LANGUAGE", "Language must be a language code, e.g. en or pt-BR", "")
+		default:
+			h.handleServiceError(c, err, "update profile")
+		}
+		return
+	}
+
 	h.respondWithSuccess(c, http.StatusOK, gin.H{"user": user})
 }
 
@@ -408,13 +511,16 @@ func (h *AuthHandler) respondWithSuccess(c *gin.Context, statusCode int, data in
 
 // respondWithError sends an error response
 func (h *AuthHandler) respondWithError(c *gin.Context, statusCode int, code, message, details string) {
+	apiErr := &APIError{
+		Code:    code,
+		Message: message,
+	}
+	if details != "" {
+		apiErr.Details = details
+	}
 	response := APIResponse{
-		Success: false,
-		Error: &APIError{
-			Code:    code,
-			Message: message,
-			Details: details,
-		},
+		Success:   false,
+		Error:     apiErr,
 		Timestamp: getCurrentTimestamp(),
 	}
 
@@ -431,8 +537,58 @@ func (h *AuthHandler) respondWithError(c *gin.Context, statusCode int, code, mes
 	c.JSON(statusCode, response)
 }
 
+// respondWithBindError reports a request that failed to bind. Validation
+// failures list every invalid field instead of only the first one.
+func (h *AuthHandler) respondWithBindError(c *gin.Context, err error, req interface{}) {
+	fields := validationErrorDetails(err, req)
+	if fields == nil {
+		h.respondWithError(c, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request payload", err.Error())
+		return
+	}
+
+	h.logger.Error("API error",
+		"status_code", http.StatusBadRequest,
+		"error_code", "VALIDATION_ERROR",
+		"fields", fields,
+		"path", c.Request.URL.Path,
+		"method", c.Request.Method,
+		"ip_address", c.ClientIP(),
+	)
+
+	c.JSON(http.StatusBadRequest, APIResponse{
+		Success: false,
+		Error: &APIError{
+			Code:    "VALIDATION_ERROR",
+			Message: "Request validation failed",
+			Details: fields,
+		},
+		Timestamp: getCurrentTimestamp(),
+	})
+}
+
 // handleServiceError maps service errors to HTTP responses
 func (h *AuthHandler) handleServiceError(c *gin.Context, err error, operation string) {
+	// Password policy failures wrap the specific rule that was violated
+	if errors.Is(err, service.ErrWeakPassword) {
+		h.respondWithError(c, http.StatusBadRequest, "WEAK_PASSWORD", "Password does not meet security requirements", err.Error())
+		return
+	}
+
+	var invalidCode *service.InvalidVerificationCodeError
+	if errors.As(err, &invalidCode) {
+		remaining := invalidCode.RemainingAttempts
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error: &APIError{
+				Code:              "INVALID_VERIFICATION_CODE",
+				Message:           "Invalid verification code",
+				RemainingAttempts: &remaining,
+			},
+			Timestamp: getCurrentTimestamp(),
+		})
+		return
+	}
+
 	switch err {
 	case service.ErrUserAlreadyExists:
 		h.respondWithError(c, http.StatusConflict, "USER_ALREADY_EXISTS", "User already exists", "")
@@ -448,6 +604,16 @@ func (h *AuthHandler) handleServiceError(c *gin.Context, err error, operation st
 		h.respondWithError(c, http.StatusBadRequest, "INVALID_RESET_TOKEN", "Invalid or expired reset token", "")
 	case service.ErrInvalidVerificationToken:
 		h.respondWithError(c, http.StatusBadRequest, "INVALID_VERIFICATION_TOKEN", "Invalid or expired verification token", "")
+	case service.ErrInvalidTimezone:
+		h.respondWithError(c, http.StatusBadRequest, "INVALID_TIMEZONE", "Timezone must be a valid IANA time zone name, e.g. America/New_York", "")
+	case service.ErrInvalidRole:
+		h.respondWithError(c, http.StatusBadRequest, "INVALID_ROLE", "Unknown role", "")
+	case service.ErrRoleNotAllowed:
+		h.respondWithError(c, http.StatusForbidden, "ROLE_NOT_ALLOWED", "This role cannot be chosen at registration", "")
+	case service.ErrTooManyVerificationAttempts:
+		h.respondWithError(c, http.StatusTooManyRequests, "TOO_MANY_ATTEMPTS", "Too many wrong codes, request a new one", "")
+	case service.ErrInvalidPhoneNumber:
+		h.respondWithError(c, http.StatusBadRequest, "INVALID_PHONE_NUMBER", "Phone number must include a country code, e.g. +15551234567", "")
 	default:
 		h.logger.Error("Unexpected service error",
 			"error", err.Error(),
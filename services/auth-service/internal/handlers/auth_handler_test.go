@@ -2,16 +2,22 @@ package handlers_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/slotwise/auth-service/internal/config"
 	"github.com/slotwise/auth-service/internal/database"
 	"github.com/slotwise/auth-service/internal/handlers"
+	"github.com/slotwise/auth-service/internal/middleware"
 	"github.com/slotwise/auth-service/internal/models"
 	"github.com/slotwise/auth-service/internal/repository"
 	"github.com/slotwise/auth-service/internal/service"
@@ -24,6 +30,15 @@ import (
 	"gorm.io/gorm"
 )
 
+// unavailableSessionRepository simulates Redis being down for session lookups
+type unavailableSessionRepository struct {
+	repository.SessionRepository
+}
+
+func (r *unavailableSessionRepository) Exists(id string) (bool, error) {
+	return false, fmt.Errorf("failed to check session existence: %w", errors.New("dial tcp 127.0.0.1:6379: connect: connection refused"))
+}
+
 // MockEventPublisher is a mock implementation of the events.Publisher interface
 type MockEventPublisher struct {
 	PublishedEvents []struct {
@@ -148,6 +163,9 @@ func (suite *AuthHandlerTestSuite) SetupSuite() {
 	`).Error
 	assert.NoError(suite.T(), err, "Businesses table creation should not fail")
 
+	err = suite.DB.AutoMigrate(&models.LoginFingerprint{})
+	assert.NoError(suite.T(), err, "Login fingerprints table migration should not fail")
+
 	// Initialize repositories
 	suite.userRepo = repository.NewUserRepository(suite.DB)
 	suite.businessRepo = repository.NewBusinessRepository(suite.DB, suite.testLogger)
@@ -166,8 +184,13 @@ func (suite *AuthHandlerTestSuite) SetupSuite() {
 		suite.userRepo,
 		suite.businessRepo,
 		suite.sessionRepo,
+		repository.NewVerificationRepository(nil),
+		repository.NewLoginHistoryRepository(suite.DB),
+		repository.NewTokenDenylistRepository(nil),
+		pkgPassword.NewManager(nil),
 		suite.mockPublisher,
 		suite.cfg.JWT,
+		suite.cfg.Registration,
 		suite.testLogger,
 	)
 
@@ -185,6 +208,7 @@ func (suite *AuthHandlerTestSuite) SetupSuite() {
 		{
 			authRoutes.POST("/register", suite.authHandler.Register)
 			authRoutes.POST("/login", suite.authHandler.Login)
+			authRoutes.POST("/forgot-password", suite.authHandler.ForgotPassword)
 			// Add other routes as needed for testing
 		}
 	}
@@ -206,6 +230,7 @@ func (suite *AuthHandlerTestSuite) SetupTest() {
 	// Clean up database tables before each test to ensure isolation
 	// Order matters due to foreign key constraints.
 	suite.DB.Exec("DELETE FROM businesses") // Or use gorm.Delete for soft deletes if applicable
+	suite.DB.Exec("DELETE FROM login_fingerprints")
 	suite.DB.Exec("DELETE FROM users")
 }
 
@@ -327,6 +352,128 @@ func (suite *AuthHandlerTestSuite) TestRegisterUserBusinessOwner() {
 	})
 }
 
+// TestRegisterUserTimezone tests timezone validation on registration
+func (suite *AuthHandlerTestSuite) TestRegisterUserTimezone() {
+	register := func(email, timezone string) *httptest.ResponseRecorder {
+		regDetails := handlers.RegisterRequest{
+			Email:     email,
+			Password:  "Password123!",
+			FirstName: "Test",
+			LastName:  "Timezone",
+			Timezone:  timezone,
+		}
+		body, _ := json.Marshal(regDetails)
+
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/auth/register", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+		suite.Router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	suite.T().Run("Valid IANA Timezone", func(t *testing.T) {
+		rr := register("tz-valid@example.com", "America/New_York")
+		assert.Equal(t, http.StatusCreated, rr.Code, "HTTP status code should be 201 Created")
+
+		var user models.User
+		err := suite.DB.Where("email = ?", "tz-valid@example.com").First(&user).Error
+		assert.NoError(t, err, "User should be found in DB")
+		assert.Equal(t, "America/New_York", user.Timezone)
+	})
+
+	suite.T().Run("Invalid Timezone", func(t *testing.T) {
+		suite.mockPublisher.Reset()
+		rr := register("tz-invalid@example.com", "Mars/Phobos")
+		assert.Equal(t, http.StatusBadRequest, rr.Code, "HTTP status code should be 400 Bad Request")
+
+		var respBody handlers.APIResponse
+		err := json.Unmarshal(rr.Body.Bytes(), &respBody)
+		assert.NoError(t, err)
+		if assert.NotNil(t, respBody.Error) {
+			assert.Equal(t, "INVALID_TIMEZONE", respBody.Error.Code)
+		}
+
+		var count int64
+		suite.DB.Model(&models.User{}).Where("email = ?", "tz-invalid@example.com").Count(&count)
+		assert.Equal(t, int64(0), count, "User should not be created")
+		assert.Len(t, suite.mockPublisher.PublishedEvents, 0, "No events should be published")
+	})
+}
+
+// TestRegisterRoleAllowlist tests that privileged roles are rejected instead of silently downgraded
+func (suite *AuthHandlerTestSuite) TestRegisterRoleAllowlist() {
+	register := func(email, role string, businessName *string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(handlers.RegisterRequest{
+			Email:        email,
+			Password:     "Password123!",
+			FirstName:    "Test",
+			LastName:     "Role",
+			Timezone:     "UTC",
+			Role:         role,
+			BusinessName: businessName,
+		})
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/auth/register", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		suite.Router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	suite.T().Run("Admin is rejected", func(t *testing.T) {
+		rr := register("wannabe-admin@example.com", string(models.RoleAdmin), nil)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		assert.Contains(t, rr.Body.String(), "ROLE_NOT_ALLOWED")
+
+		var count int64
+		suite.DB.Model(&models.User{}).Where("email = ?", "wannabe-admin@example.com").Count(&count)
+		assert.Equal(t, int64(0), count, "User should not be created")
+	})
+
+	suite.T().Run("Unknown role is rejected", func(t *testing.T) {
+		rr := register("superuser@example.com", "superuser", nil)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Contains(t, rr.Body.String(), "INVALID_ROLE")
+	})
+
+	suite.T().Run("Business owner is allowed", func(t *testing.T) {
+		businessName := "Allowlisted Biz"
+		rr := register("allowed-owner@example.com", string(models.RoleBusinessOwner), &businessName)
+		assert.Equal(t, http.StatusCreated, rr.Code)
+
+		var user models.User
+		assert.NoError(t, suite.DB.Where("email = ?", "allowed-owner@example.com").First(&user).Error)
+		assert.Equal(t, models.RoleBusinessOwner, user.Role)
+	})
+}
+
+// TestRegisterOversizedBody tests that the body size limit rejects a giant payload before it is decoded
+func (suite *AuthHandlerTestSuite) TestRegisterOversizedBody() {
+	t := suite.T()
+	router := gin.New()
+	router.Use(middleware.MaxBodyBytes(middleware.DefaultMaxBodyBytes))
+	router.POST("/api/v1/auth/register", suite.authHandler.Register)
+
+	regDetails := handlers.RegisterRequest{
+		Email:     "huge@example.com",
+		Password:  "Password123!",
+		FirstName: strings.Repeat("A", int(middleware.DefaultMaxBodyBytes)),
+		LastName:  "Client",
+		Role:      string(models.RoleClient),
+	}
+	body, _ := json.Marshal(regDetails)
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/auth/register", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	var count int64
+	suite.DB.Model(&models.User{}).Where("email = ?", regDetails.Email).Count(&count)
+	assert.Equal(t, int64(0), count, "User should not be created")
+}
+
 // TestLoginUser tests user login
 func (suite *AuthHandlerTestSuite) TestLoginUser() {
 	// Pre-requisite: Create a user to login with
@@ -377,6 +524,14 @@ func (suite *AuthHandlerTestSuite) TestLoginUser() {
 		_, tokenOk := data["accessToken"].(string)
 		assert.True(t, tokenOk, "Access token should be present in response")
 
+		// The refresh token's expiry is reported separately and follows the refresh TTL
+		refreshExpiresAt, err := time.Parse(time.RFC3339, data["refreshExpiresAt"].(string))
+		assert.NoError(t, err, "refreshExpiresAt should be an RFC3339 timestamp")
+		assert.WithinDuration(t, time.Now().Add(suite.cfg.JWT.RefreshTokenTTL), refreshExpiresAt, 5*time.Second)
+		accessExpiresAt, err := time.Parse(time.RFC3339, data["expiresAt"].(string))
+		assert.NoError(t, err)
+		assert.True(t, refreshExpiresAt.After(accessExpiresAt), "Refresh token should outlive the access token")
+
 		// Verify NATS events. Expecting 2 events: UserLoginEvent and UserSessionCreatedEvent.
 		// UserSessionCreatedEvent is the one that matches `user.authenticated: { userId, sessionId }`.
 		assert.Len(t, suite.mockPublisher.PublishedEvents, 2, "Should publish 2 events on successful login")
@@ -427,7 +582,219 @@ func (suite *AuthHandlerTestSuite) TestLoginUser() {
 	})
 }
 
+// TestLoginNewDeviceDetection tests that a login from a never-before-seen IP is flagged once
+func (suite *AuthHandlerTestSuite) TestLoginNewDeviceDetection() {
+	t := suite.T()
+	passMgr := pkgPassword.NewManager(pkgPassword.DefaultConfig())
+	hashedPassword, _ := passMgr.Hash("Password123!")
+
+	testUser := models.User{
+		Email:           "traveller@example.com",
+		PasswordHash:    hashedPassword,
+		FirstName:       "Travelling",
+		LastName:        "User",
+		Role:            models.RoleClient,
+		IsEmailVerified: true,
+		Status:          models.StatusActive,
+		Timezone:        "UTC",
+	}
+	suite.DB.Create(&testUser)
+
+	login := func(remoteAddr string) []string {
+		suite.mockPublisher.Reset()
+		body, _ := json.Marshal(handlers.LoginRequest{Email: "traveller@example.com", Password: "Password123!"})
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "TestBrowser/1.0")
+		req.RemoteAddr = remoteAddr
+		rr := httptest.NewRecorder()
+		suite.Router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var eventTypes []string
+		for _, e := range suite.mockPublisher.PublishedEvents {
+			eventTypes = append(eventTypes, e.EventType)
+		}
+		return eventTypes
+	}
+
+	// First ever login establishes the history and is not flagged
+	assert.NotContains(t, login("203.0.113.10:40000"), events.UserLoginNewDeviceEvent)
+
+	// First login from a new IP is flagged
+	eventTypes := login("198.51.100.20:40000")
+	assert.Contains(t, eventTypes, events.UserLoginNewDeviceEvent)
+	for _, e := range suite.mockPublisher.PublishedEvents {
+		if e.EventType == events.UserLoginNewDeviceEvent {
+			assert.Equal(t, testUser.ID, e.Data["userId"])
+			assert.Equal(t, "198.51.100.20", e.Data["ipAddress"])
+			assert.Equal(t, true, e.Data["newIp"])
+			assert.Equal(t, false, e.Data["newDevice"])
+		}
+	}
+
+	// A repeat login from the same IP is not flagged
+	assert.NotContains(t, login("198.51.100.20:40000"), events.UserLoginNewDeviceEvent)
+
+	var count int64
+	suite.DB.Model(&models.LoginFingerprint{}).Where("user_id = ?", testUser.ID).Count(&count)
+	assert.Equal(t, int64(2), count)
+}
+
+// TestForgotPasswordIgnoresNotificationPreferences tests that password reset emails are sent
+// even when the user has turned off email notifications
+func (suite *AuthHandlerTestSuite) TestForgotPasswordIgnoresNotificationPreferences() {
+	t := suite.T()
+
+	testUser := models.User{
+		Email:           "no-emails@example.com",
+		PasswordHash:    "unused",
+		FirstName:       "Quiet",
+		LastName:        "User",
+		Role:            models.RoleClient,
+		IsEmailVerified: true,
+		Status:          models.StatusActive,
+		Timezone:        "UTC",
+		Language:        "fr",
+	}
+	suite.DB.Create(&testUser)
+	// email_notifications defaults to true, so zero-value false is not written on create
+	suite.DB.Model(&testUser).Update("email_notifications", false)
+
+	body, _ := json.Marshal(handlers.ForgotPasswordRequest{Email: "no-emails@example.com"})
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/auth/forgot-password", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	suite.Router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	if assert.Len(t, suite.mockPublisher.PublishedEvents, 1) {
+		event := suite.mockPublisher.PublishedEvents[0]
+		assert.Equal(t, events.UserPasswordResetRequestedEvent, event.EventType)
+		assert.Equal(t, testUser.ID, event.Data["userId"])
+		assert.Equal(t, "no-emails@example.com", event.Data["email"])
+		assert.Equal(t, "fr", event.Data["language"])
+		assert.Equal(t, true, event.Data["transactional"])
+		assert.NotEmpty(t, event.Data["resetToken"])
+	}
+}
+
 // TestAuthHandlerTestSuite runs the entire test suite
+// TestStreamUsers checks that the admin export visits every user in bounded batches
+func (suite *AuthHandlerTestSuite) TestStreamUsers() {
+	t := suite.T()
+	for i := 0; i < 7; i++ {
+		suite.DB.Create(&models.User{
+			Email:        fmt.Sprintf("export%d@example.com", i),
+			PasswordHash: "hash",
+			FirstName:    "Export",
+			LastName:     fmt.Sprintf("User%d", i),
+			Timezone:     "UTC",
+			Status:       models.StatusActive,
+		})
+	}
+
+	adminService := service.NewAdminService(suite.userRepo, suite.mockPublisher, suite.testLogger)
+
+	seen := make(map[string]bool)
+	var batchSizes []int
+	err := adminService.StreamUsers(context.Background(), 3, func(batch []*models.User) error {
+		batchSizes = append(batchSizes, len(batch))
+		for _, user := range batch {
+			assert.False(t, seen[user.ID], "User %s visited twice", user.ID)
+			seen[user.ID] = true
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, seen, 7, "Every user should be visited")
+	assert.Equal(t, []int{3, 3, 1}, batchSizes, "Users should be loaded at most one batch at a time")
+
+	// The export endpoint writes one JSON object per line
+	router := gin.New()
+	router.GET("/api/v1/admin/users/export", handlers.NewAdminHandler(adminService, suite.testLogger).ExportUsers)
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/admin/users/export", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/x-ndjson", rr.Header().Get("Content-Type"))
+	lines := strings.Split(strings.TrimSpace(rr.Body.String()), "\n")
+	assert.Len(t, lines, 7)
+	for _, line := range lines {
+		var exported map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &exported))
+		assert.NotContains(t, exported, "passwordHash")
+	}
+}
+
+// validateTokenWithRedisDown creates an active user and validates a token for them while the session store is unreachable
+func (suite *AuthHandlerTestSuite) validateTokenWithRedisDown(email string, failOpen bool) (*models.AuthUser, error) {
+	user := &models.User{
+		Email:           email,
+		PasswordHash:    "hash",
+		FirstName:       "Session",
+		LastName:        "Check",
+		Timezone:        "UTC",
+		Role:            models.RoleClient,
+		Status:          models.StatusActive,
+		IsEmailVerified: true,
+	}
+	suite.Require().NoError(suite.DB.Create(user).Error)
+
+	jwtConfig := suite.cfg.JWT
+	jwtConfig.SessionCheckFailOpen = failOpen
+	authService := service.NewAuthService(
+		suite.userRepo,
+		suite.businessRepo,
+		&unavailableSessionRepository{},
+		repository.NewVerificationRepository(nil),
+		repository.NewLoginHistoryRepository(suite.DB),
+		repository.NewTokenDenylistRepository(nil),
+		pkgPassword.NewManager(nil),
+		suite.mockPublisher,
+		jwtConfig,
+		suite.cfg.Registration,
+		suite.testLogger,
+	)
+
+	tokens, err := jwt.NewManager(jwtConfig).GenerateTokenPair(user.ToAuthUser(), "session-during-outage")
+	suite.Require().NoError(err)
+	return authService.ValidateToken(tokens.AccessToken)
+}
+
+// TestValidateTokenRedisDownFailClosed tests that session checks are enforced by default when Redis errors
+func (suite *AuthHandlerTestSuite) TestValidateTokenRedisDownFailClosed() {
+	t := suite.T()
+	errorsBefore, failOpensBefore := service.SessionCheckMetrics()
+
+	user, err := suite.validateTokenWithRedisDown("fail-closed@example.com", false)
+	assert.Nil(t, user)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "failed to check session")
+	}
+
+	errorsAfter, failOpensAfter := service.SessionCheckMetrics()
+	assert.Equal(t, errorsBefore+1, errorsAfter)
+	assert.Equal(t, failOpensBefore, failOpensAfter)
+}
+
+// TestValidateTokenRedisDownFailOpen tests that a valid token is accepted during a Redis outage when fail-open is enabled
+func (suite *AuthHandlerTestSuite) TestValidateTokenRedisDownFailOpen() {
+	t := suite.T()
+	errorsBefore, failOpensBefore := service.SessionCheckMetrics()
+
+	user, err := suite.validateTokenWithRedisDown("fail-open@example.com", true)
+	assert.NoError(t, err)
+	if assert.NotNil(t, user) {
+		assert.Equal(t, "fail-open@example.com", user.Email)
+	}
+
+	errorsAfter, failOpensAfter := service.SessionCheckMetrics()
+	assert.Equal(t, errorsBefore+1, errorsAfter)
+	assert.Equal(t, failOpensBefore+1, failOpensAfter)
+}
+
 func TestAuthHandlerTestSuite(t *testing.T) {
 	suite.Run(t, new(AuthHandlerTestSuite))
 }
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/slotwise/auth-service/internal/service"
+	"github.com/slotwise/auth-service/pkg/logger"
+)
+
+// BusinessHandler handles business HTTP requests
+type BusinessHandler struct {
+	businessService service.BusinessService
+	logger          logger.Logger
+}
+
+// NewBusinessHandler creates a new business handler
+func NewBusinessHandler(businessService service.BusinessService, logger logger.Logger) *BusinessHandler {
+	return &BusinessHandler{
+		businessService: businessService,
+		logger:          logger,
+	}
+}
+
+// GetBusiness returns the public profile of a business
+func (h *BusinessHandler) GetBusiness(c *gin.Context) {
+	businessID := c.Param("id")
+
+	profile, err := h.businessService.GetPublicProfile(businessID)
+	if err != nil {
+		if err == service.ErrBusinessNotFound {
+			c.JSON(http.StatusNotFound, APIResponse{
+				Success:   false,
+				Error:     &APIError{Code: "BUSINESS_NOT_FOUND", Message: "Business not found"},
+				Timestamp: getCurrentTimestamp(),
+			})
+			return
+		}
+
+		h.logger.Error("Failed to get business", "error", err.Error(), "business_id", businessID)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success:   false,
+			Error:     &APIError{Code: "INTERNAL_SERVER_ERROR", Message: "An unexpected error occurred"},
+			Timestamp: getCurrentTimestamp(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, APIResponse{
+		Success:   true,
+		Data:      profile,
+		Timestamp: getCurrentTimestamp(),
+	})
+}
@@ -0,0 +1,97 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/slotwise/auth-service/internal/handlers"
+	"github.com/slotwise/auth-service/internal/models"
+	"github.com/slotwise/auth-service/internal/repository"
+	"github.com/slotwise/auth-service/internal/service"
+	"github.com/slotwise/auth-service/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubBusinessRepo serves businesses from memory; unused methods panic via the embedded nil interface
+type stubBusinessRepo struct {
+	repository.BusinessRepository
+	businesses map[string]*models.Business
+}
+
+func (r *stubBusinessRepo) GetByID(id string) (*models.Business, error) {
+	if b, ok := r.businesses[id]; ok {
+		return b, nil
+	}
+	return nil, repository.ErrBusinessNotFound
+}
+
+// stubUserRepo serves users from memory; unused methods panic via the embedded nil interface
+type stubUserRepo struct {
+	repository.UserRepository
+	users map[string]*models.User
+}
+
+func (r *stubUserRepo) GetByID(id string) (*models.User, error) {
+	if u, ok := r.users[id]; ok {
+		return u, nil
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+func setupBusinessRouter() *gin.Engine {
+	businessRepo := &stubBusinessRepo{businesses: map[string]*models.Business{
+		"biz-1": {ID: "biz-1", OwnerID: "owner-1", Name: "Downtown Barbers"},
+	}}
+	userRepo := &stubUserRepo{users: map[string]*models.User{
+		"owner-1": {ID: "owner-1", Email: "owner@example.com", Timezone: "Europe/Madrid"},
+	}}
+	testLogger := logger.New("debug")
+	businessHandler := handlers.NewBusinessHandler(service.NewBusinessService(businessRepo, userRepo, testLogger), testLogger)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/v1/businesses/:id", businessHandler.GetBusiness)
+	return router
+}
+
+func TestGetBusiness(t *testing.T) {
+	router := setupBusinessRouter()
+
+	t.Run("Returns public profile", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/businesses/biz-1", nil)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var resp struct {
+			Success bool                   `json:"success"`
+			Data    map[string]interface{} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.True(t, resp.Success)
+		assert.Equal(t, "biz-1", resp.Data["id"])
+		assert.Equal(t, "Downtown Barbers", resp.Data["name"])
+		assert.Equal(t, "Europe/Madrid", resp.Data["timezone"])
+		assert.NotContains(t, resp.Data, "ownerId", "Owner details must not be exposed publicly")
+	})
+
+	t.Run("Unknown business returns 404", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/businesses/does-not-exist", nil)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+
+		var resp handlers.APIResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.False(t, resp.Success)
+		if assert.NotNil(t, resp.Error) {
+			assert.Equal(t, "BUSINESS_NOT_FOUND", resp.Error.Code)
+		}
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
 	"github.com/slotwise/auth-service/internal/database"
+	"github.com/slotwise/auth-service/internal/service"
 	"github.com/slotwise/auth-service/pkg/logger"
 	"gorm.io/gorm"
 )
@@ -186,13 +187,16 @@ func (h *HealthHandler) checkRedis() CheckResult {
 // Metrics returns basic metrics about the service
 func (h *HealthHandler) Metrics(c *gin.Context) {
 	// Basic metrics - in production, you'd use Prometheus or similar
+	sessionCheckErrors, sessionCheckFailOpens := service.SessionCheckMetrics()
 	metrics := map[string]interface{}{
-		"uptime_seconds":  time.Since(startTime).Seconds(),
-		"timestamp":       time.Now().UTC().Format(time.RFC3339),
-		"go_version":      runtime.Version(),
-		"service_name":    "auth-service",
-		"service_version": getServiceVersion(),
-		"environment":     getEnvironment(),
+		"uptime_seconds":                time.Since(startTime).Seconds(),
+		"timestamp":                     time.Now().UTC().Format(time.RFC3339),
+		"go_version":                    runtime.Version(),
+		"service_name":                  "auth-service",
+		"service_version":               getServiceVersion(),
+		"environment":                   getEnvironment(),
+		"session_check_errors_total":    sessionCheckErrors,
+		"session_check_fail_open_total": sessionCheckFailOpens,
 	}
 
 	c.JSON(http.StatusOK, metrics)
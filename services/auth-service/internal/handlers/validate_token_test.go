@@ -0,0 +1,131 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/slotwise/auth-service/internal/config"
+	"github.com/slotwise/auth-service/internal/handlers"
+	"github.com/slotwise/auth-service/internal/middleware"
+	"github.com/slotwise/auth-service/internal/models"
+	"github.com/slotwise/auth-service/internal/repository"
+	"github.com/slotwise/auth-service/internal/service"
+	"github.com/slotwise/auth-service/pkg/jwt"
+	"github.com/slotwise/auth-service/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testServiceToken = "scheduling-service-token"
+
+// memoryDenylist keeps revoked token ids in memory instead of Redis
+type memoryDenylist struct {
+	repository.TokenDenylistRepository
+	denied map[string]bool
+}
+
+func (d *memoryDenylist) Deny(jti string, _ time.Time) error {
+	d.denied[jti] = true
+	return nil
+}
+
+func (d *memoryDenylist) IsDenied(jti string) (bool, error) { return d.denied[jti], nil }
+
+func (d *memoryDenylist) IssuedBeforeCutoff(string) (*time.Time, error) { return nil, nil }
+
+// noopSessionRepo stands in for Redis sessions; tokens in these tests carry no session
+type noopSessionRepo struct {
+	repository.SessionRepository
+}
+
+func (noopSessionRepo) Delete(string) error { return nil }
+
+func setupValidateTokenRouter() (*gin.Engine, service.AuthService, *jwt.Manager, *models.User) {
+	user := &models.User{ID: "user-1", Email: "client@example.com", FirstName: "Ana", LastName: "Ruiz", Role: models.RoleClient, Status: models.StatusActive, IsEmailVerified: true}
+	jwtConfig := config.JWT{Secret: "test-secret", AccessTokenTTL: 15 * time.Minute, RefreshTokenTTL: time.Hour, Issuer: "slotwise-test"}
+	testLogger := logger.New("error")
+	authService := service.NewAuthService(
+		&stubUserRepo{users: map[string]*models.User{user.ID: user}},
+		nil,
+		noopSessionRepo{},
+		nil,
+		nil,
+		&memoryDenylist{denied: map[string]bool{}},
+		nil,
+		&MockEventPublisher{},
+		jwtConfig,
+		config.Registration{},
+		testLogger,
+	)
+	authHandler := handlers.NewAuthHandler(authService, testLogger)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/v1/auth/validate", middleware.RequireServiceToken([]string{testServiceToken}, testLogger), authHandler.ValidateToken)
+	return router, authService, jwt.NewManager(jwtConfig), user
+}
+
+func postValidate(router *gin.Engine, serviceToken, accessToken string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(map[string]string{"token": accessToken})
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/auth/validate", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if serviceToken != "" {
+		req.Header.Set(middleware.ServiceTokenHeader, serviceToken)
+	}
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestValidateToken(t *testing.T) {
+	router, authService, jwtManager, user := setupValidateTokenRouter()
+	tokens, err := jwtManager.GenerateTokenPair(user.ToAuthUser(), "")
+	require.NoError(t, err)
+
+	t.Run("Requires a service token", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, postValidate(router, "", tokens.AccessToken).Code)
+		assert.Equal(t, http.StatusUnauthorized, postValidate(router, "wrong-token", tokens.AccessToken).Code)
+	})
+
+	t.Run("Valid token returns the user and claims", func(t *testing.T) {
+		rr := postValidate(router, testServiceToken, tokens.AccessToken)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		var resp struct {
+			Success bool `json:"success"`
+			Data    struct {
+				User   models.AuthUser `json:"user"`
+				Claims jwt.Claims      `json:"claims"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.True(t, resp.Success)
+		assert.Equal(t, user.ID, resp.Data.User.ID)
+		assert.Equal(t, string(models.RoleClient), resp.Data.User.Role)
+		assert.Equal(t, user.ID, resp.Data.Claims.UserID)
+		assert.NotEmpty(t, resp.Data.Claims.ID)
+	})
+
+	t.Run("Revoked token is rejected", func(t *testing.T) {
+		claims, err := jwtManager.ValidateAccessToken(tokens.AccessToken)
+		require.NoError(t, err)
+		require.NoError(t, authService.Logout(&service.LogoutRequest{
+			UserID:         user.ID,
+			TokenID:        claims.ID,
+			TokenExpiresAt: claims.ExpiresAt.Time,
+		}))
+
+		rr := postValidate(router, testServiceToken, tokens.AccessToken)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Contains(t, rr.Body.String(), "TOKEN_REVOKED")
+	})
+
+	t.Run("Malformed token is rejected", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, postValidate(router, testServiceToken, "not-a-jwt").Code)
+	})
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/slotwise/auth-service/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterReportsEveryInvalidField(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := NewAuthHandler(nil, logger.New("error"))
+	router.POST("/register", h.Register)
+	router.POST("/login", h.Login)
+
+	post := func(path, body string) (int, map[string]interface{}) {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var resp struct {
+			Error struct {
+				Code    string      `json:"code"`
+				Details interface{} `json:"details"`
+			} `json:"error"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		details, _ := resp.Error.Details.(map[string]interface{})
+		if w.Code == http.StatusBadRequest && details != nil {
+			assert.Equal(t, "VALIDATION_ERROR", resp.Error.Code)
+		}
+		return w.Code, details
+	}
+
+	code, fields := post("/register", `{"email":"not-an-email","password":"short","lastName":"Doe"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, map[string]interface{}{
+		"email":     "must be a valid email address",
+		"password":  "must be at least 8 characters",
+		"firstName": "is required",
+		"timezone":  "is required",
+	}, fields)
+
+	code, fields = post("/login", `{}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, map[string]interface{}{
+		"email":    "is required",
+		"password": "is required",
+	}, fields)
+
+	// Malformed JSON is not a validation failure and keeps the plain error string
+	code, fields = post("/login", `{"email":`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Nil(t, fields)
+}
+
+func TestValidationMessageWordsLimitsByFieldKind(t *testing.T) {
+	validate := validator.New()
+	validate.SetTagName("binding")
+	type limits struct {
+		Code     string         `json:"code" binding:"len=4"`
+		Name     string         `json:"name" binding:"max=3"`
+		Initial  string         `json:"initial" binding:"min=1"`
+		Age      int            `json:"age" binding:"min=18"`
+		Items    []string       `json:"items" binding:"min=1"`
+		Settings map[string]int `json:"settings" binding:"max=2"`
+	}
+
+	err := validate.Struct(limits{Code: "12", Name: "Alice", Age: 7, Settings: map[string]int{"a": 1, "b": 2, "c": 3}})
+	assert.Equal(t, map[string]string{
+		"code":     "must be exactly 4 characters",
+		"name":     "must be at most 3 characters",
+		"initial":  "must be at least 1 character",
+		"age":      "must be at least 18",
+		"items":    "must be at least 1 item",
+		"settings": "must be at most 2 items",
+	}, validationErrorDetails(err, limits{}))
+}
@@ -54,6 +54,10 @@ func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 		c.Set("user_email", user.Email)
 		c.Set("user_role", user.Role)
 		c.Set("session_id", claims.SessionID)
+		c.Set("token_id", claims.ID)
+		if claims.ExpiresAt != nil {
+			c.Set("token_expires_at", claims.ExpiresAt.Time)
+		}
 
 		m.logger.Debug("User authenticated",
 			"user_id", user.ID,
@@ -154,6 +158,8 @@ func (m *AuthMiddleware) handleTokenError(c *gin.Context, err error) {
 	switch err {
 	case jwt.ErrTokenExpired:
 		m.respondUnauthorized(c, "TOKEN_EXPIRED", "Token has expired")
+	case jwt.ErrTokenRevoked:
+		m.respondUnauthorized(c, "TOKEN_REVOKED", "Token has been revoked")
 	case jwt.ErrTokenNotValidYet:
 		m.respondUnauthorized(c, "TOKEN_NOT_VALID_YET", "Token is not valid yet")
 	case jwt.ErrInvalidToken:
@@ -162,6 +168,8 @@ func (m *AuthMiddleware) handleTokenError(c *gin.Context, err error) {
 		m.respondUnauthorized(c, "INVALID_TOKEN_TYPE", "Invalid token type")
 	case jwt.ErrInvalidIssuer:
 		m.respondUnauthorized(c, "INVALID_ISSUER", "Invalid token issuer")
+	case jwt.ErrInvalidAudience:
+		m.respondUnauthorized(c, "INVALID_AUDIENCE", "Invalid token audience")
 	case jwt.ErrMissingToken:
 		m.respondUnauthorized(c, "MISSING_TOKEN", "Authorization token required")
 	case jwt.ErrInvalidTokenFormat:
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultMaxBodyBytes is the request body limit applied to every route.
+// Auth payloads are small JSON documents; 1 MiB leaves ample headroom.
+const DefaultMaxBodyBytes int64 = 1 << 20 // 1 MiB
+
+// MaxBodyBytes rejects requests whose body is larger than limit with 413 Request Entity Too Large.
+// The body is read up front so that chunked requests without a Content-Length are caught too,
+// and handlers never see a truncated payload.
+func MaxBodyBytes(limit int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > limit {
+			abortBodyTooLarge(c, limit)
+			return
+		}
+		if c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, limit+1))
+		c.Request.Body.Close()
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error": gin.H{
+					"code":    "INVALID_REQUEST",
+					"message": "Failed to read request body",
+				},
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		}
+		if int64(len(body)) > limit {
+			abortBodyTooLarge(c, limit)
+			return
+		}
+
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Next()
+	}
+}
+
+func abortBodyTooLarge(c *gin.Context, limit int64) {
+	c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+		"success": false,
+		"error": gin.H{
+			"code":    "REQUEST_TOO_LARGE",
+			"message": fmt.Sprintf("Request body exceeds the limit of %d bytes", limit),
+		},
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
@@ -2,7 +2,9 @@ package middleware
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -12,11 +14,18 @@ import (
 
 // LoggingConfig holds logging middleware configuration
 type LoggingConfig struct {
-	SkipPaths   []string // Paths to skip logging
-	LogBody     bool     // Whether to log request/response bodies
-	MaxBodySize int      // Maximum body size to log (in bytes)
+	SkipPaths    []string // Paths to skip logging
+	LogBody      bool     // Whether to log request/response bodies
+	MaxBodySize  int      // Maximum body size to log (in bytes)
+	RedactFields []string // JSON fields whose values are masked in logged bodies, matched case-insensitively
 }
 
+// DefaultRedactFields are the body fields that carry credentials in this service's API
+var DefaultRedactFields = []string{"password", "currentPassword", "newPassword", "token", "accessToken", "refreshToken", "code"}
+
+// redactedValue replaces the value of a redacted field
+const redactedValue = "[REDACTED]"
+
 // DefaultLoggingConfig returns default logging configuration
 func DefaultLoggingConfig() LoggingConfig {
 	return LoggingConfig{
@@ -26,13 +35,19 @@ func DefaultLoggingConfig() LoggingConfig {
 			"/health/readiness",
 			"/metrics",
 		},
-		LogBody:     false, // Don't log bodies by default for security
-		MaxBodySize: 1024,  // 1KB max body size to log
+		LogBody:      false, // Don't log bodies by default for security
+		MaxBodySize:  1024,  // 1KB max body size to log
+		RedactFields: DefaultRedactFields,
 	}
 }
 
 // RequestLogging returns a logging middleware
 func RequestLogging(logger logger.Logger, config LoggingConfig) gin.HandlerFunc {
+	redactFields := make(map[string]bool, len(config.RedactFields))
+	for _, field := range config.RedactFields {
+		redactFields[strings.ToLower(field)] = true
+	}
+
 	return func(c *gin.Context) {
 		// Skip logging for specified paths
 		for _, skipPath := range config.SkipPaths {
@@ -57,10 +72,12 @@ func RequestLogging(logger logger.Logger, config LoggingConfig) gin.HandlerFunc
 		var requestBody string
 		if config.LogBody && c.Request.Body != nil {
 			bodyBytes, err := io.ReadAll(c.Request.Body)
-			if err == nil && len(bodyBytes) <= config.MaxBodySize {
-				requestBody = string(bodyBytes)
+			if err == nil {
 				// Restore the body for the actual handler
 				c.Request.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
+				if len(bodyBytes) <= config.MaxBodySize {
+					requestBody = redactBody(bodyBytes, redactFields)
+				}
 			}
 		}
 
@@ -105,7 +122,7 @@ func RequestLogging(logger logger.Logger, config LoggingConfig) gin.HandlerFunc
 		// Log response body if enabled and not too large
 		var responseBody string
 		if config.LogBody && writer.body.Len() <= config.MaxBodySize {
-			responseBody = writer.body.String()
+			responseBody = redactBody(writer.body.Bytes(), redactFields)
 		}
 
 		// Create response logger
@@ -136,6 +153,42 @@ func RequestLogging(logger logger.Logger, config LoggingConfig) gin.HandlerFunc
 	}
 }
 
+// redactBody returns a JSON body for logging with the values of the given fields masked at any depth.
+// Bodies that aren't JSON are not logged, since their secrets can't be found reliably.
+func redactBody(body []byte, fields map[string]bool) string {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return ""
+	}
+	var parsed interface{}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return "[non-JSON body omitted]"
+	}
+	redacted, err := json.Marshal(redactValue(parsed, fields))
+	if err != nil {
+		return "[body omitted]"
+	}
+	return string(redacted)
+}
+
+// redactValue masks the redacted fields of JSON objects within value
+func redactValue(value interface{}, fields map[string]bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if fields[strings.ToLower(key)] {
+				v[key] = redactedValue
+			} else {
+				v[key] = redactValue(item, fields)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item, fields)
+		}
+	}
+	return value
+}
+
 // DefaultRequestLogging returns a logging middleware with default configuration
 func DefaultRequestLogging(logger logger.Logger) gin.HandlerFunc {
 	return RequestLogging(logger, DefaultLoggingConfig())
@@ -0,0 +1,106 @@
+package middleware_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/slotwise/auth-service/internal/middleware"
+	"github.com/slotwise/auth-service/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingLogger keeps the fields attached with With so tests can inspect what would be logged
+type recordingLogger struct {
+	mu     *sync.Mutex
+	fields map[string]interface{}
+}
+
+func newRecordingLogger() *recordingLogger {
+	return &recordingLogger{mu: &sync.Mutex{}, fields: map[string]interface{}{}}
+}
+
+func (l *recordingLogger) Debug(string, ...interface{}) {}
+func (l *recordingLogger) Info(string, ...interface{})  {}
+func (l *recordingLogger) Warn(string, ...interface{})  {}
+func (l *recordingLogger) Error(string, ...interface{}) {}
+func (l *recordingLogger) Fatal(string, ...interface{}) {}
+
+func (l *recordingLogger) With(args ...interface{}) logger.Logger {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i := 0; i+1 < len(args); i += 2 {
+		if key, ok := args[i].(string); ok {
+			l.fields[key] = args[i+1]
+		}
+	}
+	return l
+}
+
+func (l *recordingLogger) WithContext(context.Context) logger.Logger { return l }
+
+func (l *recordingLogger) field(key string) string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	value, _ := l.fields[key].(string)
+	return value
+}
+
+func TestRequestLoggingRedactsSensitiveBodyFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := newRecordingLogger()
+	config := middleware.DefaultLoggingConfig()
+	config.LogBody = true
+
+	var handlerBody string
+	router := gin.New()
+	router.Use(middleware.RequestLogging(recorder, config))
+	router.POST("/auth/login", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		handlerBody = string(body)
+		c.JSON(http.StatusOK, gin.H{"accessToken": "issued-access-token", "user": gin.H{"email": "jane@example.com"}})
+	})
+
+	reqBody := `{"email":"jane@example.com","password":"Sup3r$ecret!"}`
+	req := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, reqBody, handlerBody, "The handler should still receive the original body")
+
+	loggedRequest := recorder.field("request_body")
+	assert.Contains(t, loggedRequest, `"password":"[REDACTED]"`)
+	assert.Contains(t, loggedRequest, `"email":"jane@example.com"`)
+	assert.NotContains(t, loggedRequest, "Sup3r$ecret!")
+
+	loggedResponse := recorder.field("response_body")
+	assert.Contains(t, loggedResponse, `"accessToken":"[REDACTED]"`)
+	assert.NotContains(t, loggedResponse, "issued-access-token")
+}
+
+func TestRequestLoggingOmitsBodiesByDefault(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := newRecordingLogger()
+
+	router := gin.New()
+	router.Use(middleware.RequestLogging(recorder, middleware.DefaultLoggingConfig()))
+	router.POST("/auth/login", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(`{"password":"Sup3r$ecret!"}`))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, recorder.field("request_body"))
+	assert.Empty(t, recorder.field("response_body"))
+}
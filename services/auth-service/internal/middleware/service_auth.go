@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/slotwise/auth-service/pkg/logger"
+)
+
+// ServiceTokenHeader carries the shared secret of a calling service.
+const ServiceTokenHeader = "X-Service-Token"
+
+// RequireServiceToken rejects requests that don't carry one of tokens in the X-Service-Token header.
+// With no tokens configured every request is rejected, so service-only endpoints are never left open.
+func RequireServiceToken(tokens []string, log logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provided := c.GetHeader(ServiceTokenHeader)
+		if provided != "" && matchesAny(provided, tokens) {
+			c.Next()
+			return
+		}
+
+		log.Warn("Rejected service request",
+			"has_token", provided != "",
+			"path", c.Request.URL.Path,
+			"ip_address", c.ClientIP(),
+		)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error": gin.H{
+				"code":    "INVALID_SERVICE_TOKEN",
+				"message": "A valid service token is required",
+			},
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+	}
+}
+
+// matchesAny compares provided against every token in constant time, so timing reveals neither which nor how much matched.
+func matchesAny(provided string, tokens []string) bool {
+	matched := 0
+	for _, token := range tokens {
+		if token != "" {
+			matched |= subtle.ConstantTimeCompare([]byte(provided), []byte(token))
+		}
+	}
+	return matched == 1
+}
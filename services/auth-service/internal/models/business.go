@@ -40,3 +40,10 @@ func (b *Business) ToBusinessInfo() *BusinessInfo {
 		Name: b.Name,
 	}
 }
+
+// BusinessPublicProfile is the subset of business details that is safe to expose publicly
+type BusinessPublicProfile struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Timezone string `json:"timezone"` // Taken from the business owner
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LoginFingerprint records an IP address and device (user agent) a user has logged in from.
+// Only a small number of recent fingerprints are kept per user.
+type LoginFingerprint struct {
+	ID          string    `gorm:"type:uuid;primary_key;" json:"id"`
+	UserID      string    `gorm:"type:uuid;not null;index" json:"userId"`
+	IPAddress   string    `gorm:"type:varchar(45)" json:"ipAddress"`
+	UserAgent   string    `gorm:"type:text" json:"userAgent"`
+	FirstSeenAt time.Time `gorm:"not null" json:"firstSeenAt"`
+	LastSeenAt  time.Time `gorm:"not null;index" json:"lastSeenAt"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID.
+func (f *LoginFingerprint) BeforeCreate(tx *gorm.DB) error {
+	if f.ID == "" {
+		f.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -191,9 +191,20 @@ func (vc *VerificationCode) IsExpired() bool {
 	return time.Now().After(vc.ExpiresAt)
 }
 
+// MaxVerificationAttempts is how many wrong codes may be entered before a code is locked
+const MaxVerificationAttempts = 3
+
 // CanAttempt checks if more verification attempts are allowed
 func (vc *VerificationCode) CanAttempt() bool {
-	return vc.Attempts < 3 // Allow max 3 attempts
+	return vc.Attempts < MaxVerificationAttempts
+}
+
+// RemainingAttempts returns how many more codes may be tried
+func (vc *VerificationCode) RemainingAttempts() int {
+	if vc.Attempts >= MaxVerificationAttempts {
+		return 0
+	}
+	return MaxVerificationAttempts - vc.Attempts
 }
 
 // IncrementAttempts increments the attempt counter
@@ -49,7 +49,8 @@ func (r *businessRepository) Create(business *models.Business) error {
 // GetByID retrieves a business by its ID
 func (r *businessRepository) GetByID(id string) (*models.Business, error) {
 	var business models.Business
-	if err := r.db.Preload("Owner").First(&business, "id = ?", id).Error; err != nil {
+	// The Owner relationship is disabled on the model, so it cannot be preloaded here
+	if err := r.db.First(&business, "id = ?", id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrBusinessNotFound
 		}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/slotwise/auth-service/internal/models"
+	"gorm.io/gorm"
+)
+
+// MaxLoginFingerprintsPerUser is the number of recent login fingerprints kept per user
+const MaxLoginFingerprintsPerUser = 10
+
+// LoginHistoryRepository defines the interface for login fingerprint operations
+type LoginHistoryRepository interface {
+	GetRecentByUserID(userID string, limit int) ([]*models.LoginFingerprint, error)
+	Record(userID, ipAddress, userAgent string, seenAt time.Time) error
+}
+
+// loginHistoryRepository implements LoginHistoryRepository interface
+type loginHistoryRepository struct {
+	db *gorm.DB
+}
+
+// NewLoginHistoryRepository creates a new login history repository
+func NewLoginHistoryRepository(db *gorm.DB) LoginHistoryRepository {
+	return &loginHistoryRepository{db: db}
+}
+
+// GetRecentByUserID retrieves the most recently seen login fingerprints of a user
+func (r *loginHistoryRepository) GetRecentByUserID(userID string, limit int) ([]*models.LoginFingerprint, error) {
+	var fingerprints []*models.LoginFingerprint
+	if err := r.db.Where("user_id = ?", userID).
+		Order("last_seen_at DESC").
+		Limit(limit).
+		Find(&fingerprints).Error; err != nil {
+		return nil, fmt.Errorf("failed to get login history: %w", err)
+	}
+	return fingerprints, nil
+}
+
+// Record stores a login fingerprint, refreshing it if already known, and prunes
+// the user's history down to MaxLoginFingerprintsPerUser entries
+func (r *loginHistoryRepository) Record(userID, ipAddress, userAgent string, seenAt time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var existing models.LoginFingerprint
+		err := tx.Where("user_id = ? AND ip_address = ? AND user_agent = ?", userID, ipAddress, userAgent).
+			First(&existing).Error
+		switch {
+		case err == nil:
+			if err := tx.Model(&existing).Update("last_seen_at", seenAt).Error; err != nil {
+				return fmt.Errorf("failed to update login fingerprint: %w", err)
+			}
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			fingerprint := &models.LoginFingerprint{
+				UserID:      userID,
+				IPAddress:   ipAddress,
+				UserAgent:   userAgent,
+				FirstSeenAt: seenAt,
+				LastSeenAt:  seenAt,
+			}
+			if err := tx.Create(fingerprint).Error; err != nil {
+				return fmt.Errorf("failed to create login fingerprint: %w", err)
+			}
+		default:
+			return fmt.Errorf("failed to get login fingerprint: %w", err)
+		}
+
+		// Keep only the most recent fingerprints
+		recent := tx.Model(&models.LoginFingerprint{}).
+			Select("id").
+			Where("user_id = ?", userID).
+			Order("last_seen_at DESC").
+			Limit(MaxLoginFingerprintsPerUser)
+		if err := tx.Where("user_id = ? AND id NOT IN (?)", userID, recent).
+			Delete(&models.LoginFingerprint{}).Error; err != nil {
+			return fmt.Errorf("failed to prune login history: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// TokenDenylistRepository tracks access tokens that were revoked before they expired.
+// Entries only need to live as long as the tokens they cover, so every key has a TTL.
+type TokenDenylistRepository interface {
+	// Deny revokes the access token with the given jti until it expires
+	Deny(jti string, expiresAt time.Time) error
+	// IsDenied reports whether the access token with the given jti was revoked
+	IsDenied(jti string) (bool, error)
+	// DenyIssuedBefore revokes every access token of a user issued before cutoff, for ttl
+	DenyIssuedBefore(userID string, cutoff time.Time, ttl time.Duration) error
+	// IssuedBeforeCutoff returns the user's revocation cutoff, or nil if there is none
+	IssuedBeforeCutoff(userID string) (*time.Time, error)
+}
+
+// tokenDenylistRepository implements TokenDenylistRepository interface
+type tokenDenylistRepository struct {
+	redis *redis.Client
+	ctx   context.Context
+}
+
+// NewTokenDenylistRepository creates a new token denylist repository
+func NewTokenDenylistRepository(redis *redis.Client) TokenDenylistRepository {
+	return &tokenDenylistRepository{
+		redis: redis,
+		ctx:   context.Background(),
+	}
+}
+
+// Deny adds a token to the denylist until it expires
+func (r *tokenDenylistRepository) Deny(jti string, expiresAt time.Time) error {
+	if r.redis == nil {
+		return nil
+	}
+
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil // Already expired, nothing to revoke
+	}
+
+	if err := r.redis.Set(r.ctx, r.tokenKey(jti), 1, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to deny token: %w", err)
+	}
+
+	return nil
+}
+
+// IsDenied checks whether a token is on the denylist
+func (r *tokenDenylistRepository) IsDenied(jti string) (bool, error) {
+	if r.redis == nil {
+		return false, nil
+	}
+
+	exists, err := r.redis.Exists(r.ctx, r.tokenKey(jti)).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check token denylist: %w", err)
+	}
+
+	return exists > 0, nil
+}
+
+// DenyIssuedBefore stores a per-user cutoff; tokens issued before it are revoked
+func (r *tokenDenylistRepository) DenyIssuedBefore(userID string, cutoff time.Time, ttl time.Duration) error {
+	if r.redis == nil || ttl <= 0 {
+		return nil
+	}
+
+	if err := r.redis.Set(r.ctx, r.userCutoffKey(userID), cutoff.Unix(), ttl).Err(); err != nil {
+		return fmt.Errorf("failed to deny user tokens: %w", err)
+	}
+
+	return nil
+}
+
+// IssuedBeforeCutoff gets the per-user revocation cutoff
+func (r *tokenDenylistRepository) IssuedBeforeCutoff(userID string) (*time.Time, error) {
+	if r.redis == nil {
+		return nil, nil
+	}
+
+	value, err := r.redis.Get(r.ctx, r.userCutoffKey(userID)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get user token cutoff: %w", err)
+	}
+
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse user token cutoff: %w", err)
+	}
+
+	cutoff := time.Unix(seconds, 0)
+	return &cutoff, nil
+}
+
+// tokenKey generates the Redis key for a denied token
+func (r *tokenDenylistRepository) tokenKey(jti string) string {
+	return fmt.Sprintf("denied_token:%s", jti)
+}
+
+// userCutoffKey generates the Redis key for a user's revocation cutoff
+func (r *tokenDenylistRepository) userCutoffKey(userID string) string {
+	return fmt.Sprintf("denied_tokens_before:%s", userID)
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
@@ -19,6 +20,7 @@ type UserRepository interface {
 	GetByPasswordResetToken(token string) (*models.User, error)
 	GetByEmailVerificationToken(token string) (*models.User, error)
 	Update(user *models.User) error
+	UpdateFields(id string, fields map[string]interface{}) error
 	Delete(id string) error
 	List(limit, offset int) ([]*models.User, int64, error)
 	UpdateLastLogin(id string) error
@@ -29,6 +31,7 @@ type UserRepository interface {
 	VerifyPhone(id string) error
 	UpdatePassword(id, passwordHash string) error
 	GetActiveUsers() ([]*models.User, error)
+	StreamUsers(ctx context.Context, batchSize int, fn func(batch []*models.User) error) error
 	CountByRole(role models.UserRole) (int64, error)
 }
 
@@ -132,6 +135,18 @@ func (r *userRepository) Update(user *models.User) error {
 	return nil
 }
 
+// UpdateFields sets only the given columns on a user, leaving every other column as stored
+func (r *userRepository) UpdateFields(id string, fields map[string]interface{}) error {
+	result := r.db.Model(&models.User{}).Where("id = ?", id).Updates(fields)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update user fields: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
 // Delete soft deletes a user
 func (r *userRepository) Delete(id string) error {
 	if err := r.db.Where("id = ?", id).Delete(&models.User{}).Error; err != nil {
@@ -247,6 +262,36 @@ func (r *userRepository) GetActiveUsers() ([]*models.User, error) {
 	return users, nil
 }
 
+// StreamUsers calls fn with every user in batches of at most batchSize, ordered by ID.
+// Batches are fetched with a keyset cursor so only one batch is held in memory at a time.
+func (r *userRepository) StreamUsers(ctx context.Context, batchSize int, fn func(batch []*models.User) error) error {
+	if batchSize <= 0 {
+		return fmt.Errorf("batch size must be positive, got %d", batchSize)
+	}
+
+	lastID := ""
+	for {
+		var batch []*models.User
+		query := r.db.WithContext(ctx).Order("id").Limit(batchSize)
+		if lastID != "" {
+			query = query.Where("id > ?", lastID)
+		}
+		if err := query.Find(&batch).Error; err != nil {
+			return fmt.Errorf("failed to stream users: %w", err)
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := fn(batch); err != nil {
+			return err
+		}
+		if len(batch) < batchSize {
+			return nil
+		}
+		lastID = batch[len(batch)-1].ID
+	}
+}
+
 // CountByRole counts users by role
 func (r *userRepository) CountByRole(role models.UserRole) (int64, error) {
 	var count int64
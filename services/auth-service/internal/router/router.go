@@ -16,12 +16,14 @@ import (
 
 // RouterConfig holds router configuration
 type RouterConfig struct {
-	DB          *gorm.DB
-	Redis       *redis.Client
-	AuthService service.AuthService
-	JWTManager  *jwt.Manager
-	Config      *config.Config
-	Logger      logger.Logger
+	DB              *gorm.DB
+	Redis           *redis.Client
+	AuthService     service.AuthService
+	BusinessService service.BusinessService
+	AdminService    service.AdminService
+	JWTManager      *jwt.Manager
+	Config          *config.Config
+	Logger          logger.Logger
 }
 
 // SetupRouter sets up the Gin router with all routes and middleware
@@ -52,10 +54,21 @@ func SetupRouter(cfg RouterConfig) *gin.Engine {
 	}
 
 	// Logging middleware
-	router.Use(middleware.DefaultRequestLogging(cfg.Logger))
+	loggingConfig := middleware.DefaultLoggingConfig()
+	loggingConfig.LogBody = cfg.Config.Logging.LogBodies
+	if len(cfg.Config.Logging.RedactFields) > 0 {
+		loggingConfig.RedactFields = cfg.Config.Logging.RedactFields
+	}
+	if loggingConfig.LogBody && cfg.Config.Environment == "production" {
+		cfg.Logger.Warn("Request body logging is enabled in production", "redact_fields", loggingConfig.RedactFields)
+	}
+	router.Use(middleware.RequestLogging(cfg.Logger, loggingConfig))
 	router.Use(middleware.SecurityLogging(cfg.Logger))
 	router.Use(middleware.ErrorLogging(cfg.Logger))
 
+	// Reject oversized bodies before any handler tries to decode them
+	router.Use(middleware.MaxBodyBytes(middleware.DefaultMaxBodyBytes))
+
 	// General rate limiting (configurable per environment)
 	generalRateLimit := cfg.Config.RateLimit.RequestsPerMinute
 	if generalRateLimit == 0 {
@@ -65,6 +78,8 @@ func SetupRouter(cfg RouterConfig) *gin.Engine {
 
 	// Create handlers
 	authHandler := handlers.NewAuthHandler(cfg.AuthService, cfg.Logger)
+	businessHandler := handlers.NewBusinessHandler(cfg.BusinessService, cfg.Logger)
+	adminHandler := handlers.NewAdminHandler(cfg.AdminService, cfg.Logger)
 	healthHandler := handlers.NewHealthHandler(cfg.DB, cfg.Redis, cfg.Logger)
 
 	// Create auth middleware
@@ -109,6 +124,13 @@ func SetupRouter(cfg RouterConfig) *gin.Engine {
 			auth.POST("/verify-code", authHandler.VerifyCode)
 		}
 
+		// Service-only auth routes: outside the per-IP auth rate limit, since every call comes from a few service hosts
+		serviceOnly := v1.Group("/auth")
+		serviceOnly.Use(middleware.RequireServiceToken(cfg.Config.ServiceAuth.Tokens, cfg.Logger))
+		{
+			serviceOnly.POST("/validate", authHandler.ValidateToken)
+		}
+
 		// Protected auth routes (authentication required)
 		authProtected := v1.Group("/auth")
 		authProtected.Use(authMiddleware.RequireAuth())
@@ -117,12 +139,20 @@ func SetupRouter(cfg RouterConfig) *gin.Engine {
 			authProtected.GET("/me", authHandler.Me)
 		}
 
+		// Public business routes (no authentication required, limited fields)
+		businesses := v1.Group("/businesses")
+		{
+			businesses.GET("/:id", businessHandler.GetBusiness)
+		}
+
 		// User management routes (authentication required)
 		users := v1.Group("/users")
 		users.Use(authMiddleware.RequireAuth())
 		{
 			users.GET("/profile", authHandler.Me) // Alias for /auth/me
-			// TODO: Add user profile update endpoints
+			users.PATCH("/profile", authHandler.UpdateProfile)
+			// Support can verify a user who cannot receive the verification email
+			users.POST("/:id/verify-email", authMiddleware.RequireAdmin(), adminHandler.ForceVerifyEmail)
 		}
 
 		// Admin routes (admin authentication required)
@@ -130,6 +160,7 @@ func SetupRouter(cfg RouterConfig) *gin.Engine {
 		admin.Use(authMiddleware.RequireAuth())
 		admin.Use(authMiddleware.RequireAdmin())
 		{
+			admin.GET("/users/export", adminHandler.ExportUsers)
 			// TODO: Add admin-specific endpoints
 			// admin.GET("/users", adminHandler.ListUsers)
 			// admin.PUT("/users/:id/status", adminHandler.UpdateUserStatus)
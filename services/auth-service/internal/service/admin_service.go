@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/slotwise/auth-service/internal/models"
+	"github.com/slotwise/auth-service/internal/repository"
+	"github.com/slotwise/auth-service/pkg/events"
+	"github.com/slotwise/auth-service/pkg/logger"
+)
+
+// AdminService defines the interface for admin operations
+type AdminService interface {
+	StreamUsers(ctx context.Context, batchSize int, fn func(batch []*models.User) error) error
+	ForceVerifyEmail(ctx context.Context, userID, adminID string) (*models.User, error)
+}
+
+// adminService implements AdminService
+type adminService struct {
+	userRepo       repository.UserRepository
+	eventPublisher events.Publisher
+	logger         logger.Logger
+}
+
+// NewAdminService creates a new admin service
+func NewAdminService(userRepo repository.UserRepository, eventPublisher events.Publisher, logger logger.Logger) AdminService {
+	return &adminService{
+		userRepo:       userRepo,
+		eventPublisher: eventPublisher,
+		logger:         logger,
+	}
+}
+
+// StreamUsers visits every user in batches without loading all of them into memory.
+// Iteration stops at the first error returned by fn.
+func (s *adminService) StreamUsers(ctx context.Context, batchSize int, fn func(batch []*models.User) error) error {
+	return s.userRepo.StreamUsers(ctx, batchSize, fn)
+}
+
+// ForceVerifyEmail marks a user's email as verified without a token, for users who cannot receive email.
+// Like token verification it activates the account and publishes user.email.verified.
+func (s *adminService) ForceVerifyEmail(ctx context.Context, userID, adminID string) (*models.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := s.userRepo.VerifyEmail(user.ID); err != nil {
+		return nil, fmt.Errorf("failed to verify email: %w", err)
+	}
+	s.logger.Info("Email force-verified by admin", "user_id", user.ID, "admin_id", adminID)
+
+	eventData := events.CreateUserEmailVerifiedEventData(user.ID, user.Email)
+	eventData["verifiedBy"] = adminID
+	if err := s.eventPublisher.Publish(events.UserEmailVerifiedEvent, eventData); err != nil {
+		s.logger.Error("Failed to publish email verified event", "error", err, "user_id", user.ID)
+	}
+
+	verified, err := s.userRepo.GetByID(user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reload user: %w", err)
+	}
+	return verified, nil
+}
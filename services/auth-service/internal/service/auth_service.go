@@ -7,7 +7,11 @@ import (
 	"errors"
 	"fmt"
 	mathrand "math/rand"
+	"net/url"
 	"regexp"
+	"sort"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -30,7 +34,11 @@ type AuthService interface {
 	ForgotPassword(email string) error
 	ResetPassword(req *ResetPasswordRequest) error
 	ValidateToken(token string) (*models.AuthUser, error)
+	// ValidateTokenClaims is ValidateToken that also returns the token's claims, for other services
+	ValidateTokenClaims(token string) (*models.AuthUser, *jwt.Claims, error)
 	RevokeAllSessions(userID string) error
+	GetProfile(userID string) (*UserProfile, error)
+	UpdateProfile(userID string, req *UpdateProfileRequest) (*models.User, error)
 	// Magic login methods
 	SendPhoneCode(req *PhoneLoginRequest) error
 	SendEmailCode(req *EmailLoginRequest) error
@@ -46,6 +54,8 @@ type RegisterRequest struct {
 	Timezone     string  `json:"timezone" validate:"required"`
 	Role         string  `json:"role,omitempty"`
 	BusinessName *string `json:"businessName,omitempty"` // Added for business registration
+	IPAddress    string  `json:"-"`                      // Recorded on the session when registration logs the user in
+	UserAgent    string  `json:"-"`
 }
 
 type LoginRequest struct {
@@ -61,9 +71,30 @@ type RefreshTokenRequest struct {
 	UserAgent    string `json:"-"`
 }
 
+// UserProfile is the current user along with their sign-in activity
+type UserProfile struct {
+	*models.AuthUser
+	LastLoginAt    *time.Time `json:"lastLoginAt"`
+	ActiveSessions *int       `json:"activeSessions,omitempty"` // Omitted when the session store is unavailable
+}
+
+// UpdateProfileRequest holds the profile fields to change; nil fields are left as they are.
+// An empty Avatar removes the avatar.
+type UpdateProfileRequest struct {
+	FirstName          *string `json:"firstName,omitempty"`
+	LastName           *string `json:"lastName,omitempty"`
+	Avatar             *string `json:"avatar,omitempty"`
+	Timezone           *string `json:"timezone,omitempty"`
+	Language           *string `json:"language,omitempty"`
+	EmailNotifications *bool   `json:"emailNotifications,omitempty"`
+	SMSNotifications   *bool   `json:"smsNotifications,omitempty"`
+}
+
 type LogoutRequest struct {
-	SessionID string `json:"-"`
-	UserID    string `json:"-"`
+	SessionID      string    `json:"-"`
+	UserID         string    `json:"-"`
+	TokenID        string    `json:"-"` // jti of the access token used to log out; denied until TokenExpiresAt
+	TokenExpiresAt time.Time `json:"-"`
 }
 
 type ResetPasswordRequest struct {
@@ -92,11 +123,25 @@ type VerifyCodeRequest struct {
 }
 
 type AuthResponse struct {
-	User         *models.AuthUser `json:"user"`
-	AccessToken  string           `json:"accessToken"`
-	RefreshToken string           `json:"refreshToken"`
-	ExpiresIn    int64            `json:"expiresIn"`
-	ExpiresAt    time.Time        `json:"expiresAt"`
+	User             *models.AuthUser `json:"user"`
+	AccessToken      string           `json:"accessToken"`
+	RefreshToken     string           `json:"refreshToken"`
+	ExpiresIn        int64            `json:"expiresIn"`
+	ExpiresAt        time.Time        `json:"expiresAt"`
+	RefreshExpiresAt time.Time        `json:"refreshExpiresAt"`    // Session end; the refresh token is rejected after it
+	SessionID        string           `json:"sessionId,omitempty"` // Lets clients mark the current session when listing sessions
+}
+
+// Session check counters, reported by the metrics endpoint
+var (
+	sessionCheckErrors    atomic.Int64 // Session store lookups that failed
+	sessionCheckFailOpens atomic.Int64 // Failed lookups where the token was accepted anyway
+)
+
+// SessionCheckMetrics returns how many session store lookups have failed since startup,
+// and how many of those requests were let through because fail-open is enabled.
+func SessionCheckMetrics() (failures, failOpens int64) {
+	return sessionCheckErrors.Load(), sessionCheckFailOpens.Load()
 }
 
 // authService implements AuthService interface
@@ -105,10 +150,15 @@ type authService struct {
 	businessRepo     repository.BusinessRepository // Added
 	sessionRepo      repository.SessionRepository
 	verificationRepo repository.VerificationRepository // Added for magic login
+	loginHistoryRepo repository.LoginHistoryRepository
+	tokenDenylist    repository.TokenDenylistRepository // Access tokens revoked before they expire
 	passwordMgr      *password.Manager
 	jwtMgr           *jwt.Manager
 	eventPublisher   events.Publisher
 	config           config.JWT
+	selfRegister     map[models.UserRole]bool // Roles users may register themselves as
+	autoLogin        bool                     // Activate accounts at registration and return tokens
+	now              func() time.Time         // Expiry of emailed verification and reset tokens is measured against it
 	logger           logger.Logger
 }
 
@@ -118,25 +168,62 @@ func NewAuthService(
 	businessRepo repository.BusinessRepository, // Added
 	sessionRepo repository.SessionRepository,
 	verificationRepo repository.VerificationRepository, // Added for magic login
+	loginHistoryRepo repository.LoginHistoryRepository,
+	tokenDenylist repository.TokenDenylistRepository,
+	passwordMgr *password.Manager,
 	eventPublisher events.Publisher,
 	config config.JWT,
+	registration config.Registration,
 	logger logger.Logger,
 ) AuthService {
+	if passwordMgr == nil {
+		passwordMgr = password.NewManager(nil)
+	}
+	selfRegister := make(map[models.UserRole]bool)
+	for _, role := range registration.SelfRegisterRoles {
+		selfRegister[models.UserRole(role)] = true
+	}
+	if len(selfRegister) == 0 {
+		selfRegister[models.RoleClient] = true
+	}
 	return &authService{
 		userRepo:         userRepo,
 		businessRepo:     businessRepo, // Added
 		sessionRepo:      sessionRepo,
 		verificationRepo: verificationRepo, // Added for magic login
-		passwordMgr:      password.NewManager(nil),
+		loginHistoryRepo: loginHistoryRepo,
+		tokenDenylist:    tokenDenylist,
+		passwordMgr:      passwordMgr,
 		jwtMgr:           jwt.NewManager(config),
 		eventPublisher:   eventPublisher,
 		config:           config,
+		selfRegister:     selfRegister,
+		autoLogin:        registration.AutoLoginOnRegister,
+		now:              time.Now,
 		logger:           logger,
 	}
 }
 
-// Register creates a new user account
+// Register creates a new user account. The account awaits email verification and no tokens are returned,
+// unless auto-login on register is enabled, in which case it is active at once and a session is started.
 func (s *authService) Register(req *RegisterRequest) (*AuthResponse, error) {
+	// Reject unknown timezones up front; they break slot formatting later
+	if !isValidTimezone(req.Timezone) {
+		return nil, ErrInvalidTimezone
+	}
+
+	role := models.RoleClient
+	if req.Role != "" {
+		role = models.UserRole(req.Role)
+		if !role.IsValid() {
+			return nil, ErrInvalidRole
+		}
+		if !s.selfRegister[role] {
+			s.logger.Warn("Rejected self-registration with a restricted role", "role", role, "email", req.Email)
+			return nil, ErrRoleNotAllowed
+		}
+	}
+
 	// Check if user already exists
 	existingUser, err := s.userRepo.GetByEmail(req.Email)
 	if err != nil && !errors.Is(err, repository.ErrUserNotFound) {
@@ -146,20 +233,17 @@ func (s *authService) Register(req *RegisterRequest) (*AuthResponse, error) {
 		return nil, ErrUserAlreadyExists
 	}
 
+	// Validate password strength against the policy, blocklist and breach check
+	if err := s.passwordMgr.ValidatePassword(req.Password); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrWeakPassword, err)
+	}
+
 	// Hash password
 	passwordHash, err := s.passwordMgr.Hash(req.Password)
 	if err != nil {
 		return nil, fmt.Errorf("failed to hash password: %w", err)
 	}
 
-	// Determine role
-	role := models.RoleClient
-	if req.Role != "" {
-		if models.UserRole(req.Role).IsValid() {
-			role = models.UserRole(req.Role)
-		}
-	}
-
 	// Create user object (without BusinessID initially)
 	user := &models.User{
 		ID:           uuid.New().String(), // GORM BeforeCreate hook will also set this if empty
@@ -174,6 +258,10 @@ func (s *authService) Register(req *RegisterRequest) (*AuthResponse, error) {
 		UpdatedAt:    time.Now(),
 		// BusinessID will be set below if applicable
 	}
+	if s.autoLogin {
+		user.Status = models.StatusActive
+		user.IsEmailVerified = true
+	}
 
 	// Handle Business Creation if user role is BusinessOwner
 	if role == models.RoleBusinessOwner {
@@ -220,20 +308,23 @@ func (s *authService) Register(req *RegisterRequest) (*AuthResponse, error) {
 		}
 	}
 
-	// Generate email verification token
-	verificationToken, err := s.generateToken()
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate verification token: %w", err)
-	}
+	// Generate email verification token, unless the account is already active
+	if !s.autoLogin {
+		verificationToken, err := s.generateToken()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate verification token: %w", err)
+		}
 
-	expiresAt := time.Now().Add(24 * time.Hour) // 24 hours
-	if err := s.userRepo.SetEmailVerificationToken(user.ID, verificationToken, expiresAt); err != nil {
-		return nil, fmt.Errorf("failed to set verification token: %w", err)
+		expiresAt := s.now().Add(s.config.EmailVerificationTTL)
+		if err := s.userRepo.SetEmailVerificationToken(user.ID, verificationToken, expiresAt); err != nil {
+			return nil, fmt.Errorf("failed to set verification token: %w", err)
+		}
 	}
 
 	// Publish user created event
 	eventData := events.CreateUserCreatedEventData(
 		user.ID, user.Email, user.FirstName, user.LastName, string(user.Role),
+		user.Language, user.EmailNotifications, user.SMSNotifications,
 	)
 	if err := s.eventPublisher.Publish(events.UserCreatedEvent, eventData); err != nil {
 		s.logger.Error("Failed to publish user created event", "error", err, "user_id", user.ID)
@@ -241,8 +332,53 @@ func (s *authService) Register(req *RegisterRequest) (*AuthResponse, error) {
 
 	// For pending verification users, don't create session yet
 	// They need to verify email first
+	if !s.autoLogin {
+		return &AuthResponse{
+			User: user.ToAuthUser(),
+		}, nil
+	}
+
+	// Create session
+	session := &models.Session{
+		ID:         uuid.New().String(),
+		UserID:     user.ID,
+		ExpiresAt:  time.Now().Add(s.config.RefreshTokenTTL),
+		CreatedAt:  time.Now(),
+		LastUsedAt: time.Now(),
+		IPAddress:  req.IPAddress,
+		UserAgent:  req.UserAgent,
+	}
+
+	// Generate tokens
+	tokenPair, err := s.jwtMgr.GenerateTokenPair(user.ToAuthUser(), session.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate tokens: %w", err)
+	}
+
+	session.RefreshToken = tokenPair.RefreshToken
+
+	// Save session
+	if err := s.sessionRepo.Create(session); err != nil {
+		s.logger.Warn("Failed to create session", "error", err, "user_id", user.ID)
+	}
+
+	if err := s.userRepo.UpdateLastLogin(user.ID); err != nil {
+		s.logger.Error("Failed to update last login", "error", err, "user_id", user.ID)
+	}
+
+	sessionEventData := events.CreateUserSessionCreatedEventData(user.ID, session.ID, req.IPAddress, req.UserAgent)
+	if err := s.eventPublisher.Publish(events.UserSessionCreatedEvent, sessionEventData); err != nil {
+		s.logger.Error("Failed to publish session created event", "error", err, "user_id", user.ID)
+	}
+
 	return &AuthResponse{
-		User: user.ToAuthUser(),
+		User:             user.ToAuthUser(),
+		AccessToken:      tokenPair.AccessToken,
+		RefreshToken:     tokenPair.RefreshToken,
+		ExpiresIn:        tokenPair.ExpiresIn,
+		ExpiresAt:        tokenPair.ExpiresAt,
+		RefreshExpiresAt: session.ExpiresAt,
+		SessionID:        session.ID,
 	}, nil
 }
 
@@ -293,6 +429,8 @@ func (s *authService) Login(req *LoginRequest) (*AuthResponse, error) {
 
 	session.RefreshToken = tokenPair.RefreshToken
 
+	s.evictOldestSessions(user.ID)
+
 	// Save session (optional in development without Redis)
 	if err := s.sessionRepo.Create(session); err != nil {
 		s.logger.Warn("Failed to create session (continuing without session storage)", "error", err, "user_id", user.ID)
@@ -310,6 +448,8 @@ func (s *authService) Login(req *LoginRequest) (*AuthResponse, error) {
 		s.logger.Error("Failed to publish login event", "error", err, "user_id", user.ID)
 	}
 
+	s.detectNewDevice(user, req.IPAddress, req.UserAgent)
+
 	// Publish session created event
 	sessionEventData := events.CreateUserSessionCreatedEventData(user.ID, session.ID, req.IPAddress, req.UserAgent)
 	if err := s.eventPublisher.Publish(events.UserSessionCreatedEvent, sessionEventData); err != nil {
@@ -317,11 +457,13 @@ func (s *authService) Login(req *LoginRequest) (*AuthResponse, error) {
 	}
 
 	return &AuthResponse{
-		User:         user.ToAuthUser(),
-		AccessToken:  tokenPair.AccessToken,
-		RefreshToken: tokenPair.RefreshToken,
-		ExpiresIn:    tokenPair.ExpiresIn,
-		ExpiresAt:    tokenPair.ExpiresAt,
+		User:             user.ToAuthUser(),
+		AccessToken:      tokenPair.AccessToken,
+		RefreshToken:     tokenPair.RefreshToken,
+		ExpiresIn:        tokenPair.ExpiresIn,
+		ExpiresAt:        tokenPair.ExpiresAt,
+		RefreshExpiresAt: session.ExpiresAt,
+		SessionID:        session.ID,
 	}, nil
 }
 
@@ -366,20 +508,57 @@ func (s *authService) RefreshToken(req *RefreshTokenRequest) (*AuthResponse, err
 	}
 
 	return &AuthResponse{
-		User:         user.ToAuthUser(),
-		AccessToken:  tokenPair.AccessToken,
-		RefreshToken: tokenPair.RefreshToken,
-		ExpiresIn:    tokenPair.ExpiresIn,
-		ExpiresAt:    tokenPair.ExpiresAt,
+		User:             user.ToAuthUser(),
+		AccessToken:      tokenPair.AccessToken,
+		RefreshToken:     tokenPair.RefreshToken,
+		ExpiresIn:        tokenPair.ExpiresIn,
+		ExpiresAt:        tokenPair.ExpiresAt,
+		RefreshExpiresAt: session.ExpiresAt,
+		SessionID:        session.ID,
 	}, nil
 }
 
+// evictOldestSessions makes room for a new session when the user is at MaxSessionsPerUser, ending their
+// oldest sessions first. A deleted session's access tokens stop validating too, since tokens are checked
+// against the session store. Failures are logged rather than returned, so the limit never blocks a login.
+func (s *authService) evictOldestSessions(userID string) {
+	if s.config.MaxSessionsPerUser <= 0 {
+		return
+	}
+	sessions, err := s.sessionRepo.GetByUserID(userID)
+	if err != nil {
+		s.logger.Warn("Failed to list sessions for session limit", "error", err, "user_id", userID)
+		return
+	}
+	if len(sessions) < s.config.MaxSessionsPerUser {
+		return
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.Before(sessions[j].CreatedAt)
+	})
+	for _, session := range sessions[:len(sessions)-s.config.MaxSessionsPerUser+1] {
+		if err := s.sessionRepo.Delete(session.ID); err != nil {
+			s.logger.Warn("Failed to evict session over limit", "error", err, "user_id", userID, "session_id", session.ID)
+			continue
+		}
+		s.logger.Info("Evicted oldest session over session limit", "user_id", userID, "session_id", session.ID)
+	}
+}
+
 // Logout invalidates a user session
 func (s *authService) Logout(req *LogoutRequest) error {
 	if err := s.sessionRepo.Delete(req.SessionID); err != nil {
 		return fmt.Errorf("failed to delete session: %w", err)
 	}
 
+	// Deleting the session is not enough: the access token itself stays valid until it expires
+	if req.TokenID != "" {
+		if err := s.tokenDenylist.Deny(req.TokenID, req.TokenExpiresAt); err != nil {
+			return fmt.Errorf("failed to revoke access token: %w", err)
+		}
+	}
+
 	// Publish logout event
 	eventData := events.CreateUserLogoutEventData(req.UserID, req.SessionID)
 	if err := s.eventPublisher.Publish(events.UserLogoutEvent, eventData); err != nil {
@@ -398,6 +577,9 @@ func (s *authService) VerifyEmail(token string) error {
 		}
 		return fmt.Errorf("failed to get user by verification token: %w", err)
 	}
+	if isExpired(user.EmailVerificationExpiresAt, s.now()) {
+		return ErrInvalidVerificationToken
+	}
 
 	// Verify email
 	if err := s.userRepo.VerifyEmail(user.ID); err != nil {
@@ -430,12 +612,18 @@ func (s *authService) ForgotPassword(email string) error {
 		return fmt.Errorf("failed to generate reset token: %w", err)
 	}
 
-	expiresAt := time.Now().Add(1 * time.Hour) // 1 hour
+	expiresAt := s.now().Add(s.config.PasswordResetTTL)
 	if err := s.userRepo.SetPasswordResetToken(user.ID, resetToken, expiresAt); err != nil {
 		return fmt.Errorf("failed to set reset token: %w", err)
 	}
 
-	// TODO: Send password reset email
+	// Password reset is a security email, so it is sent even if the user has
+	// turned off email notifications.
+	eventData := events.CreatePasswordResetRequestedEventData(user.ID, user.Email, resetToken, user.Language, expiresAt)
+	if err := s.eventPublisher.Publish(events.UserPasswordResetRequestedEvent, eventData); err != nil {
+		s.logger.Error("Failed to publish password reset requested event", "error", err, "user_id", user.ID)
+	}
+
 	s.logger.Info("Password reset requested", "user_id", user.ID, "email", email)
 
 	return nil
@@ -450,6 +638,14 @@ func (s *authService) ResetPassword(req *ResetPasswordRequest) error {
 		}
 		return fmt.Errorf("failed to get user by reset token: %w", err)
 	}
+	if isExpired(user.PasswordResetExpiresAt, s.now()) {
+		return ErrInvalidResetToken
+	}
+
+	// Validate new password strength
+	if err := s.passwordMgr.ValidatePassword(req.NewPassword); err != nil {
+		return fmt.Errorf("%w: %w", ErrWeakPassword, err)
+	}
 
 	// Hash new password
 	passwordHash, err := s.passwordMgr.Hash(req.NewPassword)
@@ -472,6 +668,11 @@ func (s *authService) ResetPassword(req *ResetPasswordRequest) error {
 		s.logger.Error("Failed to revoke sessions", "error", err, "user_id", user.ID)
 	}
 
+	// The reset has no way to know which access tokens are out there, so deny all issued so far
+	if err := s.tokenDenylist.DenyIssuedBefore(user.ID, time.Now(), s.config.AccessTokenTTL); err != nil {
+		s.logger.Error("Failed to revoke access tokens", "error", err, "user_id", user.ID)
+	}
+
 	// Publish password changed event
 	eventData := map[string]interface{}{"userId": user.ID}
 	if err := s.eventPublisher.Publish(events.UserPasswordChangedEvent, eventData); err != nil {
@@ -483,33 +684,76 @@ func (s *authService) ResetPassword(req *ResetPasswordRequest) error {
 
 // ValidateToken validates an access token and returns user info
 func (s *authService) ValidateToken(token string) (*models.AuthUser, error) {
+	user, _, err := s.ValidateTokenClaims(token)
+	return user, err
+}
+
+// ValidateTokenClaims validates an access token against its signature, session and the denylist,
+// and returns the user together with the token's claims
+func (s *authService) ValidateTokenClaims(token string) (*models.AuthUser, *jwt.Claims, error) {
 	claims, err := s.jwtMgr.ValidateAccessToken(token)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Check if session exists
 	if claims.SessionID != "" {
 		exists, err := s.sessionRepo.Exists(claims.SessionID)
 		if err != nil {
-			return nil, fmt.Errorf("failed to check session: %w", err)
+			sessionCheckErrors.Add(1)
+			if !s.config.SessionCheckFailOpen {
+				return nil, nil, fmt.Errorf("failed to check session: %w", err)
+			}
+			// The token's signature and expiry are already verified; a revoked session
+			// stays usable until the token expires or the session store is back.
+			sessionCheckFailOpens.Add(1)
+			s.logger.Warn("Session store unavailable, accepting valid token", "session_id", claims.SessionID, "error", err.Error())
+		} else if !exists {
+			return nil, nil, jwt.ErrTokenExpired
 		}
-		if !exists {
-			return nil, jwt.ErrTokenExpired
+	}
+
+	// Tokens revoked by logout or password reset are still signed and unexpired
+	revoked, err := s.isTokenRevoked(claims)
+	if err != nil {
+		// The denylist lives in the session store, so it follows the same fail-open policy
+		sessionCheckErrors.Add(1)
+		if !s.config.SessionCheckFailOpen {
+			return nil, nil, fmt.Errorf("failed to check token denylist: %w", err)
 		}
+		sessionCheckFailOpens.Add(1)
+		s.logger.Warn("Token denylist unavailable, accepting valid token", "user_id", claims.UserID, "error", err.Error())
+	} else if revoked {
+		return nil, nil, jwt.ErrTokenRevoked
 	}
 
 	// Get user to ensure they still exist and are active
 	user, err := s.userRepo.GetByID(claims.UserID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user: %w", err)
+		return nil, nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
 	if !user.CanLogin() {
-		return nil, ErrAccountDisabled
+		return nil, nil, ErrAccountDisabled
+	}
+
+	return user.ToAuthUser(), claims, nil
+}
+
+// isTokenRevoked reports whether an access token was denied by logout, or issued before a password reset
+func (s *authService) isTokenRevoked(claims *jwt.Claims) (bool, error) {
+	if claims.ID != "" {
+		denied, err := s.tokenDenylist.IsDenied(claims.ID)
+		if err != nil || denied {
+			return denied, err
+		}
 	}
 
-	return user.ToAuthUser(), nil
+	cutoff, err := s.tokenDenylist.IssuedBeforeCutoff(claims.UserID)
+	if err != nil || cutoff == nil || claims.IssuedAt == nil {
+		return false, err
+	}
+	return claims.IssuedAt.Time.Before(*cutoff), nil
 }
 
 // RevokeAllSessions revokes all sessions for a user
@@ -522,6 +766,156 @@ func (s *authService) RevokeAllSessions(userID string) error {
 	return nil
 }
 
+// GetProfile returns the user with when they last logged in and how many unexpired sessions they have.
+// A failing session store leaves the count out rather than failing the whole profile.
+func (s *authService) GetProfile(userID string) (*UserProfile, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	profile := &UserProfile{AuthUser: user.ToAuthUser(), LastLoginAt: user.LastLoginAt}
+
+	sessions, err := s.sessionRepo.GetByUserID(userID)
+	if err != nil {
+		s.logger.Warn("Failed to count active sessions", "user_id", userID, "error", err)
+		return profile, nil
+	}
+	now := s.now()
+	active := 0
+	for _, session := range sessions {
+		if now.Before(session.ExpiresAt) {
+			active++
+		}
+	}
+	profile.ActiveSessions = &active
+	return profile, nil
+}
+
+// UpdateProfile changes only the fields set on req and returns the updated user. Every field is
+// validated before anything is written, so a rejected request changes nothing.
+func (s *authService) UpdateProfile(userID string, req *UpdateProfileRequest) (*models.User, error) {
+	fields, err := profileUpdateFields(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(fields) > 0 {
+		if err := s.userRepo.UpdateFields(userID, fields); err != nil {
+			if errors.Is(err, repository.ErrUserNotFound) {
+				return nil, ErrUserNotFound
+			}
+			return nil, fmt.Errorf("failed to update profile: %w", err)
+		}
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if len(fields) > 0 {
+		s.logger.Info("Profile updated", "user_id", userID, "fields", len(fields))
+
+		// Other services keep copies of the language and notification preferences, so tell them what changed
+		eventData := events.CreateUserUpdatedEventData(user.ID, profileEventChanges(user, fields))
+		if err := s.eventPublisher.Publish(events.UserUpdatedEvent, eventData); err != nil {
+			s.logger.Error("Failed to publish user updated event", "error", err, "user_id", user.ID)
+		}
+	}
+	return user, nil
+}
+
+// profileEventFields maps the user columns UpdateProfile writes to their names in the user.updated event
+var profileEventFields = map[string]string{
+	"first_name":          "firstName",
+	"last_name":           "lastName",
+	"avatar":              "avatar",
+	"timezone":            "timezone",
+	"language":            "language",
+	"email_notifications": "emailNotifications",
+	"sms_notifications":   "smsNotifications",
+}
+
+// profileEventChanges converts the columns changed by a profile update to the user.updated event's changes.
+// A removed avatar is sent as nil. Changing either name sends both, so consumers can rebuild the full name.
+func profileEventChanges(user *models.User, fields map[string]interface{}) map[string]interface{} {
+	changes := make(map[string]interface{}, len(fields))
+	for column, value := range fields {
+		if name, ok := profileEventFields[column]; ok {
+			changes[name] = value
+		}
+	}
+	_, firstNameChanged := fields["first_name"]
+	_, lastNameChanged := fields["last_name"]
+	if firstNameChanged || lastNameChanged {
+		changes["firstName"] = user.FirstName
+		changes["lastName"] = user.LastName
+	}
+	return changes
+}
+
+// profileUpdateFields validates the fields set on req and maps them to the user columns they change
+func profileUpdateFields(req *UpdateProfileRequest) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+
+	if req.FirstName != nil {
+		name := strings.TrimSpace(*req.FirstName)
+		if name == "" || len(name) > maxProfileNameLength {
+			return nil, fmt.Errorf("%w: firstName must be 1-%d characters", ErrInvalidProfileField, maxProfileNameLength)
+		}
+		fields["first_name"] = name
+	}
+	if req.LastName != nil {
+		name := strings.TrimSpace(*req.LastName)
+		if name == "" || len(name) > maxProfileNameLength {
+			return nil, fmt.Errorf("%w: lastName must be 1-%d characters", ErrInvalidProfileField, maxProfileNameLength)
+		}
+		fields["last_name"] = name
+	}
+	if req.Avatar != nil {
+		avatar := strings.TrimSpace(*req.Avatar)
+		if avatar == "" {
+			fields["avatar"] = nil
+		} else if u, err := url.Parse(avatar); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("%w: avatar must be an http(s) URL", ErrInvalidProfileField)
+		} else {
+			fields["avatar"] = avatar
+		}
+	}
+	if req.Timezone != nil {
+		if !isValidTimezone(*req.Timezone) {
+			return nil, ErrInvalidTimezone
+		}
+		fields["timezone"] = *req.Timezone
+	}
+	if req.Language != nil {
+		if !languageTag.MatchString(*req.Language) {
+			return nil, ErrInvalidLanguage
+		}
+		fields["language"] = *req.Language
+	}
+	if req.EmailNotifications != nil {
+		fields["email_notifications"] = *req.EmailNotifications
+	}
+	if req.SMSNotifications != nil {
+		fields["sms_notifications"] = *req.SMSNotifications
+	}
+	return fields, nil
+}
+
+// isExpired reports whether a token expiring at expiresAt is no longer valid at now; a missing expiry counts as expired.
+// The repository lookups already filter expired tokens by the database clock; this keeps the service's TTL authoritative.
+func isExpired(expiresAt *time.Time, now time.Time) bool {
+	return expiresAt == nil || !now.Before(*expiresAt)
+}
+
 // generateToken generates a random token
 func (s *authService) generateToken() (string, error) {
 	bytes := make([]byte, 32)
@@ -535,9 +929,9 @@ func (s *authService) generateToken() (string, error) {
 
 // SendPhoneCode sends a verification code to a phone number
 func (s *authService) SendPhoneCode(req *PhoneLoginRequest) error {
-	// Validate phone number format
-	if !isValidPhoneNumber(req.Phone) {
-		return errors.New("invalid phone number format")
+	phone, err := normalizePhoneNumber(req.Phone)
+	if err != nil {
+		return err
 	}
 
 	// Generate 4-digit code
@@ -545,7 +939,7 @@ func (s *authService) SendPhoneCode(req *PhoneLoginRequest) error {
 
 	// Store verification code in Redis
 	verificationCode := &models.VerificationCode{
-		Identifier: req.Phone,
+		Identifier: phone,
 		Code:       code,
 		Type:       "phone",
 		ExpiresAt:  time.Now().Add(10 * time.Minute), // 10 minutes
@@ -559,7 +953,7 @@ func (s *authService) SendPhoneCode(req *PhoneLoginRequest) error {
 	}
 
 	// Log the code (in production, this would send SMS)
-	s.logger.Info("📱 PHONE VERIFICATION CODE", "phone", req.Phone, "code", code)
+	s.logger.Info("📱 PHONE VERIFICATION CODE", "phone", phone, "code", code)
 
 	return nil
 }
@@ -594,6 +988,13 @@ func (s *authService) SendEmailCode(req *EmailLoginRequest) error {
 func (s *authService) VerifyCode(req *VerifyCodeRequest) (*AuthResponse, error) {
 	ctx := context.Background()
 
+	// Phone codes are stored under the normalized number, whatever format it was typed in
+	if !strings.Contains(req.Identifier, "@") {
+		if phone, err := normalizePhoneNumber(req.Identifier); err == nil {
+			req.Identifier = phone
+		}
+	}
+
 	// Get verification code from Redis
 	verificationCode, err := s.verificationRepo.GetCode(ctx, req.Identifier)
 	if err != nil {
@@ -612,14 +1013,15 @@ func (s *authService) VerifyCode(req *VerifyCodeRequest) (*AuthResponse, error)
 	// Check if too many attempts
 	if !verificationCode.CanAttempt() {
 		s.verificationRepo.DeleteCode(ctx, req.Identifier)
-		return nil, errors.New("too many verification attempts")
+		return nil, ErrTooManyVerificationAttempts
 	}
 
 	// Verify code
 	if verificationCode.Code != req.Code {
 		// Increment attempts
 		s.verificationRepo.IncrementAttempts(ctx, req.Identifier)
-		return nil, errors.New("invalid verification code")
+		verificationCode.IncrementAttempts()
+		return nil, &InvalidVerificationCodeError{RemainingAttempts: verificationCode.RemainingAttempts()}
 	}
 
 	// Delete verification code (successful verification)
@@ -661,6 +1063,8 @@ func (s *authService) VerifyCode(req *VerifyCodeRequest) (*AuthResponse, error)
 
 	session.RefreshToken = tokenPair.RefreshToken
 
+	s.evictOldestSessions(user.ID)
+
 	// Save session
 	if err := s.sessionRepo.Create(session); err != nil {
 		s.logger.Warn("Failed to create session", "error", err, "user_id", user.ID)
@@ -677,31 +1081,110 @@ func (s *authService) VerifyCode(req *VerifyCodeRequest) (*AuthResponse, error)
 		s.logger.Error("Failed to publish login event", "error", err, "user_id", user.ID)
 	}
 
+	s.detectNewDevice(user, req.IPAddress, req.UserAgent)
+
 	return &AuthResponse{
-		User:         user.ToAuthUser(),
-		AccessToken:  tokenPair.AccessToken,
-		RefreshToken: tokenPair.RefreshToken,
-		ExpiresIn:    tokenPair.ExpiresIn,
-		ExpiresAt:    tokenPair.ExpiresAt,
+		User:             user.ToAuthUser(),
+		AccessToken:      tokenPair.AccessToken,
+		RefreshToken:     tokenPair.RefreshToken,
+		ExpiresIn:        tokenPair.ExpiresIn,
+		ExpiresAt:        tokenPair.ExpiresAt,
+		RefreshExpiresAt: session.ExpiresAt,
+		SessionID:        session.ID,
 	}, nil
 }
 
 // Helper functions
 
+// detectNewDevice compares a login against the user's recent login history and publishes a
+// user.login.new_device event when the IP address or device has not been seen before.
+// A user's first recorded login is not flagged since there is nothing to compare against.
+func (s *authService) detectNewDevice(user *models.User, ipAddress, userAgent string) {
+	history, err := s.loginHistoryRepo.GetRecentByUserID(user.ID, repository.MaxLoginFingerprintsPerUser)
+	if err != nil {
+		s.logger.Error("Failed to get login history", "error", err, "user_id", user.ID)
+		return
+	}
+
+	if len(history) > 0 {
+		knownIP, knownDevice := false, false
+		for _, fingerprint := range history {
+			if fingerprint.IPAddress == ipAddress {
+				knownIP = true
+			}
+			if fingerprint.UserAgent == userAgent {
+				knownDevice = true
+			}
+		}
+
+		if !knownIP || !knownDevice {
+			s.logger.Warn("Login from new IP address or device", "user_id", user.ID, "ip_address", ipAddress, "new_ip", !knownIP, "new_device", !knownDevice)
+			eventData := events.CreateUserLoginNewDeviceEventData(user.ID, user.Email, ipAddress, userAgent, !knownIP, !knownDevice)
+			if err := s.eventPublisher.Publish(events.UserLoginNewDeviceEvent, eventData); err != nil {
+				s.logger.Error("Failed to publish new device login event", "error", err, "user_id", user.ID)
+			}
+		}
+	}
+
+	if err := s.loginHistoryRepo.Record(user.ID, ipAddress, userAgent, time.Now()); err != nil {
+		s.logger.Error("Failed to record login history", "error", err, "user_id", user.ID)
+	}
+}
+
 // generateVerificationCode generates a 4-digit verification code
 func generateVerificationCode() string {
 	return fmt.Sprintf("%04d", mathrand.Intn(10000))
 }
 
-// isValidPhoneNumber validates phone number format
-func isValidPhoneNumber(phone string) bool {
-	// Simple phone validation - accepts formats like +1234567890, 1234567890, etc.
-	phoneRegex := regexp.MustCompile(`^\+?[1-9]\d{1,14}$`)
-	return phoneRegex.MatchString(phone)
+// e164Digits matches the digits of an E.164 number: a country code that does not start with 0,
+// 7 to 15 digits in total.
+var e164Digits = regexp.MustCompile(`^[1-9]\d{6,14}$`)
+
+// normalizePhoneNumber converts a phone number to canonical E.164 form (e.g. "+15551234567"),
+// so the same number typed differently is stored and looked up as one identifier.
+// Spaces, dashes, dots and parentheses are ignored and a leading "00" is read as "+".
+// The number must include its country code; a leading "+" is optional.
+func normalizePhoneNumber(phone string) (string, error) {
+	digits := strings.TrimSpace(phone)
+	if strings.HasPrefix(digits, "+") {
+		digits = digits[1:]
+	} else if strings.HasPrefix(digits, "00") {
+		digits = digits[2:]
+	}
+	digits = strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "").Replace(digits)
+
+	if !e164Digits.MatchString(digits) {
+		return "", ErrInvalidPhoneNumber
+	}
+	return "+" + digits, nil
+}
+
+// languageTag matches a language code with an optional region, e.g. "en" or "pt-BR"
+var languageTag = regexp.MustCompile(`^[a-z]{2,3}(-[A-Z]{2})?$`)
+
+// maxProfileNameLength caps first and last names set through a profile update
+const maxProfileNameLength = 100
+
+// isValidTimezone reports whether tz is an IANA time zone name (e.g. "Europe/Berlin").
+// "Local" is rejected because it depends on the server's configuration.
+func isValidTimezone(tz string) bool {
+	if tz == "" || tz == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(tz)
+	return err == nil
 }
 
 // findOrCreateUserByIdentifier finds an existing user or creates a new one
 func (s *authService) findOrCreateUserByIdentifier(identifier, identifierType string) (*models.User, error) {
+	if identifierType == "phone" {
+		phone, err := normalizePhoneNumber(identifier)
+		if err != nil {
+			return nil, err
+		}
+		identifier = phone
+	}
+
 	// Try to find existing user
 	user, err := s.userRepo.GetByEmailOrPhone(identifier)
 	if err != nil && !errors.Is(err, repository.ErrUserNotFound) {
@@ -740,7 +1223,8 @@ func (s *authService) findOrCreateUserByIdentifier(identifier, identifierType st
 		newUser.PhoneVerifiedAt = &now
 	}
 
-	// Set a random password hash (user won't use it for magic login)
+	// Set a random password hash (user won't use it for magic login). It is generated, not chosen,
+	// so it is hashed without the password policy or breach check.
 	tempPassword := uuid.New().String()
 	passwordHash, err := s.passwordMgr.Hash(tempPassword)
 	if err != nil {
@@ -756,6 +1240,7 @@ func (s *authService) findOrCreateUserByIdentifier(identifier, identifierType st
 	// Publish user created event
 	eventData := events.CreateUserCreatedEventData(
 		newUser.ID, newUser.Email, newUser.FirstName, newUser.LastName, string(newUser.Role),
+		newUser.Language, newUser.EmailNotifications, newUser.SMSNotifications,
 	)
 	if err := s.eventPublisher.Publish(events.UserCreatedEvent, eventData); err != nil {
 		s.logger.Error("Failed to publish user created event", "error", err, "user_id", newUser.ID)
@@ -764,6 +1249,17 @@ func (s *authService) findOrCreateUserByIdentifier(identifier, identifierType st
 	return newUser, nil
 }
 
+// InvalidVerificationCodeError is returned by VerifyCode for a wrong code. RemainingAttempts is
+// how many more codes the caller may try before the code is locked; the caller already knows how
+// many they have entered, so it gives nothing away.
+type InvalidVerificationCodeError struct {
+	RemainingAttempts int
+}
+
+func (e *InvalidVerificationCodeError) Error() string {
+	return "invalid verification code"
+}
+
 // Service errors
 var (
 	ErrUserAlreadyExists        = errors.New("user already exists")
@@ -773,4 +1269,15 @@ var (
 	ErrInvalidRefreshToken      = errors.New("invalid refresh token")
 	ErrInvalidResetToken        = errors.New("invalid reset token")
 	ErrInvalidVerificationToken = errors.New("invalid verification token")
+	ErrInvalidTimezone          = errors.New("invalid timezone")
+	ErrInvalidLanguage          = errors.New("invalid language")
+	ErrInvalidProfileField      = errors.New("invalid profile field")
+	ErrInvalidPhoneNumber       = errors.New("invalid phone number format")
+	ErrInvalidRole              = errors.New("invalid role")
+	ErrRoleNotAllowed           = errors.New("role not allowed for self-registration")
+	ErrWeakPassword             = errors.New("weak password")
+	ErrBusinessNotFound         = errors.New("business not found")
+	ErrUserNotFound             = errors.New("user not found")
+
+	ErrTooManyVerificationAttempts = errors.New("too many verification attempts")
 )
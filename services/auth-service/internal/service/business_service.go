@@ -0,0 +1,62 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/slotwise/auth-service/internal/models"
+	"github.com/slotwise/auth-service/internal/repository"
+	"github.com/slotwise/auth-service/pkg/logger"
+)
+
+// BusinessService defines the interface for business operations
+type BusinessService interface {
+	GetPublicProfile(id string) (*models.BusinessPublicProfile, error)
+}
+
+// businessService implements BusinessService
+type businessService struct {
+	businessRepo repository.BusinessRepository
+	userRepo     repository.UserRepository
+	logger       logger.Logger
+}
+
+// NewBusinessService creates a new business service
+func NewBusinessService(
+	businessRepo repository.BusinessRepository,
+	userRepo repository.UserRepository,
+	logger logger.Logger,
+) BusinessService {
+	return &businessService{
+		businessRepo: businessRepo,
+		userRepo:     userRepo,
+		logger:       logger,
+	}
+}
+
+// GetPublicProfile returns the public details of a business. The business has no
+// timezone of its own, so the owner's timezone is used.
+func (s *businessService) GetPublicProfile(id string) (*models.BusinessPublicProfile, error) {
+	business, err := s.businessRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrBusinessNotFound) {
+			return nil, ErrBusinessNotFound
+		}
+		return nil, fmt.Errorf("failed to get business: %w", err)
+	}
+
+	profile := &models.BusinessPublicProfile{
+		ID:       business.ID,
+		Name:     business.Name,
+		Timezone: "UTC",
+	}
+
+	owner, err := s.userRepo.GetByID(business.OwnerID)
+	if err != nil {
+		s.logger.Warn("Failed to get business owner, using default timezone", "error", err, "business_id", id)
+	} else if owner.Timezone != "" {
+		profile.Timezone = owner.Timezone
+	}
+
+	return profile, nil
+}
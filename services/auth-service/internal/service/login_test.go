@@ -0,0 +1,92 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/slotwise/auth-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryLoginHistoryRepository keeps login fingerprints in memory.
+type memoryLoginHistoryRepository struct {
+	fingerprints []*models.LoginFingerprint
+}
+
+func (r *memoryLoginHistoryRepository) GetRecentByUserID(string, int) ([]*models.LoginFingerprint, error) {
+	return r.fingerprints, nil
+}
+
+func (r *memoryLoginHistoryRepository) Record(userID, ipAddress, userAgent string, seenAt time.Time) error {
+	r.fingerprints = append(r.fingerprints, &models.LoginFingerprint{UserID: userID, IPAddress: ipAddress, UserAgent: userAgent, FirstSeenAt: seenAt, LastSeenAt: seenAt})
+	return nil
+}
+
+func TestLoginReturnsSessionIDFromToken(t *testing.T) {
+	s, _, sessionRepo := newRegisterTestService(true)
+	s.loginHistoryRepo = &memoryLoginHistoryRepository{}
+	registerReq := newRegisterRequest()
+	_, err := s.Register(registerReq)
+	require.NoError(t, err)
+
+	resp, err := s.Login(&LoginRequest{Email: registerReq.Email, Password: registerReq.Password, IPAddress: "203.0.113.7"})
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.SessionID)
+
+	claims, err := s.jwtMgr.ValidateAccessToken(resp.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, claims.SessionID, resp.SessionID)
+	if assert.Len(t, sessionRepo.sessions, 2) {
+		assert.Equal(t, sessionRepo.sessions[1].ID, resp.SessionID, "The login's own session, not the one from registration")
+	}
+}
+
+func (r *memorySessionRepository) Delete(id string) error {
+	for i, session := range r.sessions {
+		if session.ID == id {
+			r.sessions = append(r.sessions[:i], r.sessions[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func TestLoginOverSessionLimitEvictsOldestSession(t *testing.T) {
+	s, _, sessionRepo := newRegisterTestService(true)
+	s.loginHistoryRepo = &memoryLoginHistoryRepository{}
+	s.config.MaxSessionsPerUser = 3
+	registerReq := newRegisterRequest()
+	registered, err := s.Register(registerReq)
+	require.NoError(t, err)
+	sessionRepo.sessions[0].CreatedAt = time.Now().Add(-time.Hour)
+	login := &LoginRequest{Email: registerReq.Email, Password: registerReq.Password, IPAddress: "203.0.113.7"}
+
+	for i := 0; i < 2; i++ {
+		_, err := s.Login(login)
+		require.NoError(t, err)
+	}
+	require.Len(t, sessionRepo.sessions, 3, "Logins up to the limit keep every session")
+
+	resp, err := s.Login(login)
+	require.NoError(t, err)
+	assert.Len(t, sessionRepo.sessions, 3)
+	exists, _ := sessionRepo.Exists(registered.SessionID)
+	assert.False(t, exists, "The oldest session is evicted")
+	exists, _ = sessionRepo.Exists(resp.SessionID)
+	assert.True(t, exists)
+}
+
+func TestLoginWithoutSessionLimitKeepsAllSessions(t *testing.T) {
+	s, _, sessionRepo := newRegisterTestService(true)
+	s.loginHistoryRepo = &memoryLoginHistoryRepository{}
+	registerReq := newRegisterRequest()
+	_, err := s.Register(registerReq)
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		_, err := s.Login(&LoginRequest{Email: registerReq.Email, Password: registerReq.Password})
+		require.NoError(t, err)
+	}
+	assert.Len(t, sessionRepo.sessions, 6)
+}
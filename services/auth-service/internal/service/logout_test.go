@@ -0,0 +1,100 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/slotwise/auth-service/internal/config"
+	"github.com/slotwise/auth-service/internal/models"
+	"github.com/slotwise/auth-service/internal/repository"
+	"github.com/slotwise/auth-service/pkg/jwt"
+	"github.com/slotwise/auth-service/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryTokenDenylist keeps revoked tokens in memory instead of Redis.
+type memoryTokenDenylist struct {
+	denied  map[string]time.Time
+	cutoffs map[string]time.Time
+}
+
+func (d *memoryTokenDenylist) Deny(jti string, expiresAt time.Time) error {
+	d.denied[jti] = expiresAt
+	return nil
+}
+
+func (d *memoryTokenDenylist) IsDenied(jti string) (bool, error) {
+	expiresAt, ok := d.denied[jti]
+	return ok && time.Now().Before(expiresAt), nil
+}
+
+func (d *memoryTokenDenylist) DenyIssuedBefore(userID string, cutoff time.Time, _ time.Duration) error {
+	d.cutoffs[userID] = cutoff
+	return nil
+}
+
+func (d *memoryTokenDenylist) IssuedBeforeCutoff(userID string) (*time.Time, error) {
+	cutoff, ok := d.cutoffs[userID]
+	if !ok {
+		return nil, nil
+	}
+	return &cutoff, nil
+}
+
+// noopSessionRepository stands in for Redis sessions; logout only deletes one.
+type noopSessionRepository struct {
+	repository.SessionRepository
+}
+
+func (noopSessionRepository) Delete(string) error { return nil }
+
+func newLogoutTestService() (*authService, *memoryTokenDenylist, *models.User) {
+	user := &models.User{ID: "user-1", Email: "client@example.com", Role: models.RoleClient, Status: models.StatusActive, IsEmailVerified: true}
+	denylist := &memoryTokenDenylist{denied: map[string]time.Time{}, cutoffs: map[string]time.Time{}}
+	cfg := config.JWT{Secret: "test-secret", AccessTokenTTL: 15 * time.Minute, RefreshTokenTTL: time.Hour, Issuer: "slotwise-test"}
+	s := &authService{
+		userRepo:       &memoryUserRepository{users: []*models.User{user}},
+		sessionRepo:    noopSessionRepository{},
+		tokenDenylist:  denylist,
+		jwtMgr:         jwt.NewManager(cfg),
+		eventPublisher: noopPublisher{},
+		config:         cfg,
+		logger:         logger.New("error"),
+	}
+	return s, denylist, user
+}
+
+func TestLoggedOutTokenIsRejectedImmediately(t *testing.T) {
+	s, _, user := newLogoutTestService()
+
+	// No session id, so the session check alone would never catch this token
+	tokens, err := s.jwtMgr.GenerateTokenPair(user.ToAuthUser(), "")
+	require.NoError(t, err)
+	_, err = s.ValidateToken(tokens.AccessToken)
+	require.NoError(t, err)
+
+	claims, err := s.jwtMgr.ValidateAccessToken(tokens.AccessToken)
+	require.NoError(t, err)
+	require.NoError(t, s.Logout(&LogoutRequest{
+		UserID:         user.ID,
+		TokenID:        claims.ID,
+		TokenExpiresAt: claims.ExpiresAt.Time,
+	}))
+
+	_, err = s.ValidateToken(tokens.AccessToken)
+	assert.ErrorIs(t, err, jwt.ErrTokenRevoked)
+}
+
+func TestTokensIssuedBeforeCutoffAreRejected(t *testing.T) {
+	s, denylist, user := newLogoutTestService()
+
+	tokens, err := s.jwtMgr.GenerateTokenPair(user.ToAuthUser(), "")
+	require.NoError(t, err)
+
+	// What a password reset records; IssuedAt has second precision, so the cutoff is a second ahead
+	require.NoError(t, denylist.DenyIssuedBefore(user.ID, time.Now().Add(time.Second), time.Minute))
+
+	_, err = s.ValidateToken(tokens.AccessToken)
+	assert.ErrorIs(t, err, jwt.ErrTokenRevoked)
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/slotwise/auth-service/internal/models"
+	"github.com/slotwise/auth-service/internal/repository"
+	"github.com/slotwise/auth-service/pkg/logger"
+	"github.com/slotwise/auth-service/pkg/password"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryUserRepository keeps users in memory; only the methods used by magic login are implemented.
+type memoryUserRepository struct {
+	repository.UserRepository
+	users []*models.User
+}
+
+func (r *memoryUserRepository) Create(user *models.User) error {
+	r.users = append(r.users, user)
+	return nil
+}
+
+func (r *memoryUserRepository) GetByEmailOrPhone(identifier string) (*models.User, error) {
+	for _, user := range r.users {
+		if user.Email == identifier || (user.Phone != nil && *user.Phone == identifier) {
+			return user, nil
+		}
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+func (r *memoryUserRepository) GetByID(id string) (*models.User, error) {
+	for _, user := range r.users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+type noopPublisher struct{}
+
+func (noopPublisher) Publish(string, map[string]interface{}) error { return nil }
+func (noopPublisher) PublishWithCorrelation(string, map[string]interface{}, string, string) error {
+	return nil
+}
+func (noopPublisher) Close() error { return nil }
+
+func TestNormalizePhoneNumber(t *testing.T) {
+	valid := map[string]string{
+		"+15551234567":      "+15551234567",
+		"15551234567":       "+15551234567",
+		"+1 (555) 123-4567": "+15551234567",
+		"1.555.123.4567":    "+15551234567",
+		"0015551234567":     "+15551234567",
+		" +44 20 7946 0958": "+442079460958",
+	}
+	for input, want := range valid {
+		got, err := normalizePhoneNumber(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, want, got, input)
+	}
+
+	for _, input := range []string{"", "+", "12345", "+0 555 123 4567", "555-CALL-NOW", "+1 555 123 4567 890 12", "1+5551234567"} {
+		_, err := normalizePhoneNumber(input)
+		assert.ErrorIs(t, err, ErrInvalidPhoneNumber, input)
+	}
+}
+
+func TestFindOrCreateUserByPhoneResolvesFormatsToOneUser(t *testing.T) {
+	users := &memoryUserRepository{}
+	s := &authService{
+		userRepo:       users,
+		passwordMgr:    password.NewManager(nil),
+		eventPublisher: noopPublisher{},
+		logger:         logger.New("error"),
+	}
+
+	first, err := s.findOrCreateUserByIdentifier("+1 (555) 123-4567", "phone")
+	require.NoError(t, err)
+	require.NotNil(t, first.Phone)
+	assert.Equal(t, "+15551234567", *first.Phone)
+
+	for _, input := range []string{"15551234567", "+15551234567", "1-555-123-4567"} {
+		user, err := s.findOrCreateUserByIdentifier(input, "phone")
+		require.NoError(t, err, input)
+		assert.Equal(t, first.ID, user.ID, input)
+	}
+	assert.Len(t, users.users, 1)
+
+	_, err = s.findOrCreateUserByIdentifier("not a number", "phone")
+	assert.ErrorIs(t, err, ErrInvalidPhoneNumber)
+}
+
+func TestMagicLoginUserSkipsPasswordChecks(t *testing.T) {
+	checker := &countingBreachChecker{}
+	config := password.DefaultConfig()
+	config.BreachChecker = checker
+	s := &authService{
+		userRepo:       &memoryUserRepository{},
+		passwordMgr:    password.NewManager(config),
+		eventPublisher: noopPublisher{},
+		logger:         logger.New("error"),
+	}
+
+	// The generated password needs no policy or breach check, and a breach service outage must not block sign-in
+	user, err := s.findOrCreateUserByIdentifier("+15551234567", "phone")
+	require.NoError(t, err)
+	assert.NotEmpty(t, user.PasswordHash)
+	assert.Empty(t, checker.checked)
+}
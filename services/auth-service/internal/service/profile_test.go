@@ -0,0 +1,242 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/slotwise/auth-service/internal/models"
+	"github.com/slotwise/auth-service/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (r *memorySessionRepository) GetByUserID(userID string) ([]*models.Session, error) {
+	var sessions []*models.Session
+	for _, session := range r.sessions {
+		if session.UserID == userID {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+// UpdateFields applies the profile columns written by UpdateProfile to the stored user.
+func (r *memoryUserRepository) UpdateFields(id string, fields map[string]interface{}) error {
+	user, err := r.GetByID(id)
+	if err != nil {
+		return err
+	}
+	for column, value := range fields {
+		switch column {
+		case "first_name":
+			user.FirstName = value.(string)
+		case "last_name":
+			user.LastName = value.(string)
+		case "avatar":
+			if value == nil {
+				user.Avatar = nil
+			} else {
+				avatar := value.(string)
+				user.Avatar = &avatar
+			}
+		case "timezone":
+			user.Timezone = value.(string)
+		case "language":
+			user.Language = value.(string)
+		case "email_notifications":
+			user.EmailNotifications = value.(bool)
+		case "sms_notifications":
+			user.SMSNotifications = value.(bool)
+		default:
+			return errors.New("unexpected column " + column)
+		}
+	}
+	return nil
+}
+
+// recordingPublisher keeps the events a test publishes.
+type recordingPublisher struct {
+	noopPublisher
+	published []recordedEvent
+}
+
+type recordedEvent struct {
+	eventType string
+	data      map[string]interface{}
+}
+
+func (p *recordingPublisher) Publish(eventType string, data map[string]interface{}) error {
+	p.published = append(p.published, recordedEvent{eventType: eventType, data: data})
+	return nil
+}
+
+// failingSessionListRepository simulates the session store being down when listing a user's sessions.
+type failingSessionListRepository struct {
+	memorySessionRepository
+}
+
+func (r *failingSessionListRepository) GetByUserID(string) ([]*models.Session, error) {
+	return nil, errors.New("dial tcp 127.0.0.1:6379: connect: connection refused")
+}
+
+func TestGetProfileIncludesLastLoginAndActiveSessions(t *testing.T) {
+	s, userRepo, sessionRepo := newRegisterTestService(false)
+	now := time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	lastLogin := now.Add(-2 * time.Hour)
+	userRepo.users = append(userRepo.users, &models.User{ID: "user-1", Email: "client@example.com", Role: models.RoleClient, LastLoginAt: &lastLogin})
+	sessionRepo.sessions = []*models.Session{
+		{ID: "phone", UserID: "user-1", ExpiresAt: now.Add(time.Hour)},
+		{ID: "laptop", UserID: "user-1", ExpiresAt: now.Add(24 * time.Hour)},
+		{ID: "old-tablet", UserID: "user-1", ExpiresAt: now.Add(-time.Minute)},
+		{ID: "someone-else", UserID: "user-2", ExpiresAt: now.Add(time.Hour)},
+	}
+
+	profile, err := s.GetProfile("user-1")
+	require.NoError(t, err)
+	assert.Equal(t, "client@example.com", profile.Email)
+	require.NotNil(t, profile.LastLoginAt)
+	assert.True(t, lastLogin.Equal(*profile.LastLoginAt))
+	require.NotNil(t, profile.ActiveSessions)
+	assert.Equal(t, 2, *profile.ActiveSessions, "Only the user's unexpired sessions count")
+}
+
+func TestGetProfileOmitsSessionCountWhenSessionStoreFails(t *testing.T) {
+	s, userRepo, _ := newRegisterTestService(false)
+	s.sessionRepo = &failingSessionListRepository{}
+	userRepo.users = append(userRepo.users, &models.User{ID: "user-1", Email: "client@example.com", Role: models.RoleClient})
+
+	profile, err := s.GetProfile("user-1")
+	require.NoError(t, err)
+	assert.Nil(t, profile.LastLoginAt, "The user has never logged in")
+	assert.Nil(t, profile.ActiveSessions)
+}
+
+func TestGetProfileUnknownUser(t *testing.T) {
+	s, _, _ := newRegisterTestService(false)
+
+	_, err := s.GetProfile("missing")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
+
+func newProfileUser() *models.User {
+	avatar := "https://cdn.example.com/avatars/user-1.png"
+	return &models.User{
+		ID: "user-1", Email: "client@example.com", FirstName: "Ana", LastName: "Silva", Avatar: &avatar,
+		Timezone: "UTC", Language: "en", EmailNotifications: true, Role: models.RoleClient,
+	}
+}
+
+func TestUpdateProfileTimezoneOnlyKeepsOtherFields(t *testing.T) {
+	s, userRepo, _ := newRegisterTestService(false)
+	userRepo.users = append(userRepo.users, newProfileUser())
+
+	timezone := "Europe/Lisbon"
+	user, err := s.UpdateProfile("user-1", &UpdateProfileRequest{Timezone: &timezone})
+	require.NoError(t, err)
+	assert.Equal(t, "Europe/Lisbon", user.Timezone)
+	require.NotNil(t, user.Avatar, "Sending only timezone must not wipe the avatar")
+	assert.Equal(t, "https://cdn.example.com/avatars/user-1.png", *user.Avatar)
+	assert.Equal(t, "Ana", user.FirstName)
+	assert.Equal(t, "Silva", user.LastName)
+	assert.Equal(t, "en", user.Language)
+	assert.True(t, user.EmailNotifications)
+}
+
+func TestUpdateProfileRejectsInvalidFieldsWithoutWriting(t *testing.T) {
+	s, userRepo, _ := newRegisterTestService(false)
+	userRepo.users = append(userRepo.users, newProfileUser())
+	str := func(v string) *string { return &v }
+
+	cases := []struct {
+		req  *UpdateProfileRequest
+		want error
+	}{
+		{&UpdateProfileRequest{Timezone: str("Mars/Olympus_Mons")}, ErrInvalidTimezone},
+		{&UpdateProfileRequest{Timezone: str("")}, ErrInvalidTimezone},
+		{&UpdateProfileRequest{Language: str("english")}, ErrInvalidLanguage},
+		{&UpdateProfileRequest{FirstName: str("  ")}, ErrInvalidProfileField},
+		{&UpdateProfileRequest{Avatar: str("javascript:alert(1)")}, ErrInvalidProfileField},
+		// A valid field alongside an invalid one is not applied either
+		{&UpdateProfileRequest{LastName: str("Costa"), Language: str("EN_us")}, ErrInvalidLanguage},
+	}
+	for _, tc := range cases {
+		_, err := s.UpdateProfile("user-1", tc.req)
+		assert.ErrorIs(t, err, tc.want)
+	}
+
+	user := userRepo.users[0]
+	assert.Equal(t, "UTC", user.Timezone)
+	assert.Equal(t, "Silva", user.LastName)
+	assert.Equal(t, "en", user.Language)
+}
+
+func TestUpdateProfileSetsAndClearsFields(t *testing.T) {
+	s, userRepo, _ := newRegisterTestService(false)
+	userRepo.users = append(userRepo.users, newProfileUser())
+	language, firstName, avatar, sms := "pt-BR", " Ana Maria ", "", true
+
+	user, err := s.UpdateProfile("user-1", &UpdateProfileRequest{
+		FirstName: &firstName, Language: &language, Avatar: &avatar, SMSNotifications: &sms,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Ana Maria", user.FirstName)
+	assert.Equal(t, "pt-BR", user.Language)
+	assert.Nil(t, user.Avatar, "An empty avatar removes it")
+	assert.True(t, user.SMSNotifications)
+	assert.True(t, user.EmailNotifications)
+}
+
+func TestUpdateProfileUnknownUser(t *testing.T) {
+	s, _, _ := newRegisterTestService(false)
+	timezone := "UTC"
+
+	_, err := s.UpdateProfile("missing", &UpdateProfileRequest{Timezone: &timezone})
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
+
+func TestUpdateProfilePublishesChangedFields(t *testing.T) {
+	s, userRepo, _ := newRegisterTestService(false)
+	publisher := &recordingPublisher{}
+	s.eventPublisher = publisher
+	userRepo.users = append(userRepo.users, newProfileUser())
+	language, avatar, email := "es", "", false
+
+	_, err := s.UpdateProfile("user-1", &UpdateProfileRequest{Language: &language, Avatar: &avatar, EmailNotifications: &email})
+	require.NoError(t, err)
+
+	require.Len(t, publisher.published, 1)
+	assert.Equal(t, events.UserUpdatedEvent, publisher.published[0].eventType)
+	assert.Equal(t, "user-1", publisher.published[0].data["userId"])
+	assert.Equal(t, map[string]interface{}{
+		"language":           "es",
+		"avatar":             nil,
+		"emailNotifications": false,
+	}, publisher.published[0].data["changes"], "Only the fields sent are reported")
+}
+
+func TestUpdateProfileNameChangePublishesFullName(t *testing.T) {
+	s, userRepo, _ := newRegisterTestService(false)
+	publisher := &recordingPublisher{}
+	s.eventPublisher = publisher
+	userRepo.users = append(userRepo.users, newProfileUser())
+	lastName := "Costa"
+
+	_, err := s.UpdateProfile("user-1", &UpdateProfileRequest{LastName: &lastName})
+	require.NoError(t, err)
+
+	require.Len(t, publisher.published, 1)
+	assert.Equal(t, map[string]interface{}{"firstName": "Ana", "lastName": "Costa"}, publisher.published[0].data["changes"])
+}
+
+func TestUpdateProfileWithoutChangesPublishesNothing(t *testing.T) {
+	s, userRepo, _ := newRegisterTestService(false)
+	publisher := &recordingPublisher{}
+	s.eventPublisher = publisher
+	userRepo.users = append(userRepo.users, newProfileUser())
+
+	_, err := s.UpdateProfile("user-1", &UpdateProfileRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, publisher.published)
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/slotwise/auth-service/internal/config"
+	"github.com/slotwise/auth-service/internal/models"
+	"github.com/slotwise/auth-service/internal/repository"
+	"github.com/slotwise/auth-service/pkg/jwt"
+	"github.com/slotwise/auth-service/pkg/logger"
+	"github.com/slotwise/auth-service/pkg/password"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// registerUserRepository adds the lookups and updates used by registration to memoryUserRepository.
+type registerUserRepository struct {
+	memoryUserRepository
+	verificationTokens map[string]string
+}
+
+func (r *registerUserRepository) GetByEmail(email string) (*models.User, error) {
+	for _, user := range r.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+func (r *registerUserRepository) SetEmailVerificationToken(id, token string, _ time.Time) error {
+	r.verificationTokens[id] = token
+	return nil
+}
+
+func (r *registerUserRepository) UpdateLastLogin(string) error { return nil }
+
+// memorySessionRepository records created sessions instead of storing them in Redis.
+type memorySessionRepository struct {
+	repository.SessionRepository
+	sessions []*models.Session
+}
+
+func (r *memorySessionRepository) Create(session *models.Session) error {
+	r.sessions = append(r.sessions, session)
+	return nil
+}
+
+func (r *memorySessionRepository) Exists(sessionID string) (bool, error) {
+	for _, session := range r.sessions {
+		if session.ID == sessionID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func newRegisterTestService(autoLogin bool) (*authService, *registerUserRepository, *memorySessionRepository) {
+	userRepo := &registerUserRepository{verificationTokens: map[string]string{}}
+	sessionRepo := &memorySessionRepository{}
+	cfg := config.JWT{Secret: "test-secret", AccessTokenTTL: 15 * time.Minute, RefreshTokenTTL: time.Hour, Issuer: "slotwise-test"}
+	s := &authService{
+		userRepo:       userRepo,
+		sessionRepo:    sessionRepo,
+		tokenDenylist:  &memoryTokenDenylist{denied: map[string]time.Time{}, cutoffs: map[string]time.Time{}},
+		passwordMgr:    password.NewManager(nil),
+		jwtMgr:         jwt.NewManager(cfg),
+		eventPublisher: noopPublisher{},
+		config:         cfg,
+		selfRegister:   map[models.UserRole]bool{models.RoleClient: true},
+		autoLogin:      autoLogin,
+		now:            time.Now,
+		logger:         logger.New("error"),
+	}
+	return s, userRepo, sessionRepo
+}
+
+func newRegisterRequest() *RegisterRequest {
+	return &RegisterRequest{
+		Email:     "new-client@example.com",
+		Password:  "Sl0twise!Register",
+		FirstName: "New",
+		LastName:  "Client",
+		Timezone:  "Europe/Madrid",
+		IPAddress: "203.0.113.7",
+	}
+}
+
+func TestRegisterRequiresVerificationByDefault(t *testing.T) {
+	s, userRepo, sessionRepo := newRegisterTestService(false)
+
+	resp, err := s.Register(newRegisterRequest())
+	require.NoError(t, err)
+
+	require.Len(t, userRepo.users, 1)
+	assert.Equal(t, models.StatusPendingVerification, userRepo.users[0].Status)
+	assert.Empty(t, resp.AccessToken)
+	assert.Empty(t, resp.RefreshToken)
+	assert.Empty(t, sessionRepo.sessions)
+	assert.NotEmpty(t, userRepo.verificationTokens[resp.User.ID], "A verification email token should be issued")
+}
+
+func TestRegisterWithAutoLoginReturnsTokens(t *testing.T) {
+	s, userRepo, sessionRepo := newRegisterTestService(true)
+
+	resp, err := s.Register(newRegisterRequest())
+	require.NoError(t, err)
+
+	require.Len(t, userRepo.users, 1)
+	assert.Equal(t, models.StatusActive, userRepo.users[0].Status)
+	assert.True(t, userRepo.users[0].IsEmailVerified)
+	assert.NotEmpty(t, resp.AccessToken)
+	assert.NotEmpty(t, resp.RefreshToken)
+	assert.Empty(t, userRepo.verificationTokens)
+	if assert.Len(t, sessionRepo.sessions, 1) {
+		assert.Equal(t, resp.RefreshToken, sessionRepo.sessions[0].RefreshToken)
+		assert.Equal(t, "203.0.113.7", sessionRepo.sessions[0].IPAddress)
+	}
+
+	// The access token is accepted straight away, without verifying the email first
+	authUser, err := s.ValidateToken(resp.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, resp.User.ID, authUser.ID)
+}
+
+// countingBreachChecker reports no breaches and counts the passwords it was asked about.
+type countingBreachChecker struct {
+	checked []string
+}
+
+func (c *countingBreachChecker) IsBreached(pw string) (bool, error) {
+	c.checked = append(c.checked, pw)
+	return false, nil
+}
+
+func TestRegisterChecksPasswordOnce(t *testing.T) {
+	s, userRepo, _ := newRegisterTestService(false)
+	checker := &countingBreachChecker{}
+	config := password.DefaultConfig()
+	config.BreachChecker = checker
+	s.passwordMgr = password.NewManager(config)
+
+	req := newRegisterRequest()
+	_, err := s.Register(req)
+	require.NoError(t, err)
+
+	require.Len(t, userRepo.users, 1)
+	assert.Equal(t, []string{req.Password}, checker.checked)
+}
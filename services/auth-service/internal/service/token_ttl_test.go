@@ -0,0 +1,132 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/slotwise/auth-service/internal/models"
+	"github.com/slotwise/auth-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tokenUserRepository stores emailed tokens on the users. Lookups ignore expiry, which the
+// real repository checks against the database clock, so only the service's check applies.
+type tokenUserRepository struct {
+	registerUserRepository
+}
+
+func (r *tokenUserRepository) SetEmailVerificationToken(id, token string, expiresAt time.Time) error {
+	user, err := r.GetByID(id)
+	if err != nil {
+		return err
+	}
+	r.verificationTokens[id] = token
+	user.EmailVerificationToken, user.EmailVerificationExpiresAt = &token, &expiresAt
+	return nil
+}
+
+func (r *tokenUserRepository) GetByEmailVerificationToken(token string) (*models.User, error) {
+	for _, user := range r.users {
+		if user.EmailVerificationToken != nil && *user.EmailVerificationToken == token {
+			return user, nil
+		}
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+func (r *tokenUserRepository) VerifyEmail(id string) error {
+	user, err := r.GetByID(id)
+	if err != nil {
+		return err
+	}
+	user.IsEmailVerified, user.EmailVerificationToken, user.EmailVerificationExpiresAt = true, nil, nil
+	return nil
+}
+
+func (r *tokenUserRepository) SetPasswordResetToken(id, token string, expiresAt time.Time) error {
+	user, err := r.GetByID(id)
+	if err != nil {
+		return err
+	}
+	user.PasswordResetToken, user.PasswordResetExpiresAt = &token, &expiresAt
+	return nil
+}
+
+func (r *tokenUserRepository) GetByPasswordResetToken(token string) (*models.User, error) {
+	for _, user := range r.users {
+		if user.PasswordResetToken != nil && *user.PasswordResetToken == token {
+			return user, nil
+		}
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+func (r *tokenUserRepository) UpdatePassword(id, passwordHash string) error {
+	user, err := r.GetByID(id)
+	if err != nil {
+		return err
+	}
+	user.PasswordHash = passwordHash
+	return nil
+}
+
+func (r *tokenUserRepository) ClearPasswordResetToken(id string) error {
+	user, err := r.GetByID(id)
+	if err != nil {
+		return err
+	}
+	user.PasswordResetToken, user.PasswordResetExpiresAt = nil, nil
+	return nil
+}
+
+func (r *memorySessionRepository) DeleteByUserID(string) error { return nil }
+
+// newTokenTTLTestService returns a service with short token TTLs whose clock the test moves by hand.
+func newTokenTTLTestService(now *time.Time) (*authService, *tokenUserRepository) {
+	s, _, _ := newRegisterTestService(false)
+	userRepo := &tokenUserRepository{registerUserRepository{verificationTokens: map[string]string{}}}
+	s.userRepo = userRepo
+	s.config.EmailVerificationTTL = 2 * time.Hour
+	s.config.PasswordResetTTL = 30 * time.Minute
+	s.now = func() time.Time { return *now }
+	return s, userRepo
+}
+
+func TestVerificationTokenExpiresAfterConfiguredTTL(t *testing.T) {
+	issuedAt := time.Now()
+	now := issuedAt
+	s, userRepo := newTokenTTLTestService(&now)
+
+	resp, err := s.Register(newRegisterRequest())
+	require.NoError(t, err)
+	token := userRepo.verificationTokens[resp.User.ID]
+	require.NotEmpty(t, token)
+
+	now = issuedAt.Add(2*time.Hour + time.Second)
+	assert.ErrorIs(t, s.VerifyEmail(token), ErrInvalidVerificationToken)
+
+	now = issuedAt.Add(2*time.Hour - time.Second)
+	require.NoError(t, s.VerifyEmail(token))
+	assert.True(t, userRepo.users[0].IsEmailVerified)
+}
+
+func TestResetTokenExpiresAfterConfiguredTTL(t *testing.T) {
+	issuedAt := time.Now()
+	now := issuedAt
+	s, userRepo := newTokenTTLTestService(&now)
+
+	_, err := s.Register(newRegisterRequest())
+	require.NoError(t, err)
+	require.NoError(t, s.ForgotPassword("new-client@example.com"))
+	require.NotNil(t, userRepo.users[0].PasswordResetToken)
+	token := *userRepo.users[0].PasswordResetToken
+
+	now = issuedAt.Add(30*time.Minute + time.Second)
+	err = s.ResetPassword(&ResetPasswordRequest{Token: token, NewPassword: "N3w!Sl0twise-Pass"})
+	assert.ErrorIs(t, err, ErrInvalidResetToken)
+
+	now = issuedAt.Add(30*time.Minute - time.Second)
+	require.NoError(t, s.ResetPassword(&ResetPasswordRequest{Token: token, NewPassword: "N3w!Sl0twise-Pass"}))
+	assert.Nil(t, userRepo.users[0].PasswordResetToken)
+}
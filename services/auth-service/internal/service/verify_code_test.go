@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/slotwise/auth-service/internal/models"
+	"github.com/slotwise/auth-service/internal/repository"
+	"github.com/slotwise/auth-service/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryVerificationRepository keeps verification codes in memory instead of Redis.
+type memoryVerificationRepository struct {
+	codes map[string]*models.VerificationCode
+}
+
+func (r *memoryVerificationRepository) StoreCode(_ context.Context, code *models.VerificationCode) error {
+	stored := *code
+	r.codes[code.Identifier] = &stored
+	return nil
+}
+
+func (r *memoryVerificationRepository) GetCode(_ context.Context, identifier string) (*models.VerificationCode, error) {
+	code, ok := r.codes[identifier]
+	if !ok {
+		return nil, repository.ErrVerificationCodeNotFound
+	}
+	copied := *code
+	return &copied, nil
+}
+
+func (r *memoryVerificationRepository) DeleteCode(_ context.Context, identifier string) error {
+	delete(r.codes, identifier)
+	return nil
+}
+
+func (r *memoryVerificationRepository) IncrementAttempts(_ context.Context, identifier string) error {
+	code, ok := r.codes[identifier]
+	if !ok {
+		return repository.ErrVerificationCodeNotFound
+	}
+	code.IncrementAttempts()
+	return nil
+}
+
+func TestVerifyCodeReportsRemainingAttempts(t *testing.T) {
+	codes := &memoryVerificationRepository{codes: map[string]*models.VerificationCode{}}
+	s := &authService{verificationRepo: codes, logger: logger.New("error")}
+	require.NoError(t, codes.StoreCode(context.Background(), &models.VerificationCode{
+		Identifier: "client@example.com",
+		Code:       "1234",
+		Type:       "email",
+		ExpiresAt:  time.Now().Add(10 * time.Minute),
+	}))
+
+	for want := models.MaxVerificationAttempts - 1; want >= 0; want-- {
+		_, err := s.VerifyCode(&VerifyCodeRequest{Identifier: "client@example.com", Code: "0000"})
+		var invalidCode *InvalidVerificationCodeError
+		require.True(t, errors.As(err, &invalidCode), "expected an invalid code error, got %v", err)
+		assert.Equal(t, want, invalidCode.RemainingAttempts)
+	}
+
+	// Once the attempts are used up even the right code is refused and the code is discarded
+	_, err := s.VerifyCode(&VerifyCodeRequest{Identifier: "client@example.com", Code: "1234"})
+	assert.ErrorIs(t, err, ErrTooManyVerificationAttempts)
+	assert.Empty(t, codes.codes)
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/slotwise/auth-service/pkg/events"
 	"github.com/slotwise/auth-service/pkg/jwt"
 	"github.com/slotwise/auth-service/pkg/logger"
+	"github.com/slotwise/auth-service/pkg/password"
 )
 
 func main() {
@@ -78,24 +79,46 @@ func main() {
 	sessionRepo := repository.NewSessionRepository(redis)
 	businessRepo := repository.NewBusinessRepository(db, appLogger) // Initialize BusinessRepository
 	verificationRepo := repository.NewVerificationRepository(redis) // Initialize VerificationRepository for magic login
+	loginHistoryRepo := repository.NewLoginHistoryRepository(db)
+	tokenDenylist := repository.NewTokenDenylistRepository(redis)
 	appLogger.Info("Repositories initialized")
 
 	// Initialize JWT manager
 	jwtManager := jwt.NewManager(cfg.JWT)
 	appLogger.Info("JWT manager initialized")
 
+	// Initialize password manager with the configured blocklist and breach check
+	passwordConfig := password.DefaultConfig()
+	if cfg.Password.BlocklistPath != "" {
+		blocklist, err := password.LoadBlocklist(cfg.Password.BlocklistPath)
+		if err != nil {
+			appLogger.Fatal("Failed to load password blocklist", "error", err, "path", cfg.Password.BlocklistPath)
+		}
+		passwordConfig.Blocklist = blocklist
+		appLogger.Info("Password blocklist loaded", "entries", len(blocklist))
+	}
+	if cfg.Password.BreachCheckEnabled {
+		passwordConfig.BreachChecker = password.NewRangeBreachChecker(cfg.Password.BreachCheckURL, cfg.Password.BreachCheckTimeout)
+		appLogger.Info("Password breach check enabled", "url", cfg.Password.BreachCheckURL)
+	}
+	passwordMgr := password.NewManager(passwordConfig)
+
 	// Initialize services
-	authService := service.NewAuthService(userRepo, businessRepo, sessionRepo, verificationRepo, eventPublisher, cfg.JWT, appLogger) // Pass all repositories
+	authService := service.NewAuthService(userRepo, businessRepo, sessionRepo, verificationRepo, loginHistoryRepo, tokenDenylist, passwordMgr, eventPublisher, cfg.JWT, cfg.Registration, appLogger) // Pass all repositories
+	businessService := service.NewBusinessService(businessRepo, userRepo, appLogger)
+	adminService := service.NewAdminService(userRepo, eventPublisher, appLogger)
 	appLogger.Info("Services initialized")
 
 	// Setup router with all components
 	routerConfig := router.RouterConfig{
-		DB:          db,
-		Redis:       redis,
-		AuthService: authService,
-		JWTManager:  jwtManager,
-		Config:      cfg,
-		Logger:      appLogger,
+		DB:              db,
+		Redis:           redis,
+		AuthService:     authService,
+		BusinessService: businessService,
+		AdminService:    adminService,
+		JWTManager:      jwtManager,
+		Config:          cfg,
+		Logger:          appLogger,
 	}
 
 	ginRouter := router.SetupRouter(routerConfig)
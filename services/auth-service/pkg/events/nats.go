@@ -245,6 +245,14 @@ const (
 	UserSessionCreatedEvent  = "user.session.created"
 	UserSessionExpiredEvent  = "user.session.expired"
 
+	// UserPasswordResetRequestedEvent asks the notification service to send a password reset email.
+	// It is transactional, so it is sent regardless of the user's notification preferences.
+	UserPasswordResetRequestedEvent = "user.password.reset.requested"
+
+	// UserLoginNewDeviceEvent is published when a user logs in from an IP address or device
+	// not seen in their recent login history.
+	UserLoginNewDeviceEvent = "user.login.new_device"
+
 	// Business events
 	BusinessRegisteredEvent = "business.registered"
 	// Add other business events like BusinessUpdatedEvent, BusinessDeletedEvent etc. as needed
@@ -252,14 +260,30 @@ const (
 
 // Helper functions for creating event data
 
-// CreateUserCreatedEventData creates event data for user creation
-func CreateUserCreatedEventData(userID, email, firstName, lastName, role string) map[string]interface{} {
+// CreateUserCreatedEventData creates event data for user creation.
+// Language and notification preferences are included so other services can honor them.
+func CreateUserCreatedEventData(userID, email, firstName, lastName, role, language string, emailNotifications, smsNotifications bool) map[string]interface{} {
 	return map[string]interface{}{
-		"userId":    userID,
-		"email":     email,
-		"firstName": firstName,
-		"lastName":  lastName,
-		"role":      role,
+		"userId":             userID,
+		"email":              email,
+		"firstName":          firstName,
+		"lastName":           lastName,
+		"role":               role,
+		"language":           language,
+		"emailNotifications": emailNotifications,
+		"smsNotifications":   smsNotifications,
+	}
+}
+
+// CreatePasswordResetRequestedEventData creates event data for a password reset request
+func CreatePasswordResetRequestedEventData(userID, email, resetToken, language string, expiresAt time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"userId":        userID,
+		"email":         email,
+		"resetToken":    resetToken,
+		"language":      language,
+		"expiresAt":     expiresAt.Format(time.RFC3339),
+		"transactional": true,
 	}
 }
 
@@ -296,6 +320,18 @@ func CreateUserLoginEventData(userID, email, ipAddress, userAgent string) map[st
 	}
 }
 
+// CreateUserLoginNewDeviceEventData creates event data for a login from a new IP address or device
+func CreateUserLoginNewDeviceEventData(userID, email, ipAddress, userAgent string, newIP, newDevice bool) map[string]interface{} {
+	return map[string]interface{}{
+		"userId":    userID,
+		"email":     email,
+		"ipAddress": ipAddress,
+		"userAgent": userAgent,
+		"newIp":     newIP,
+		"newDevice": newDevice,
+	}
+}
+
 // CreateBusinessRegisteredEventData creates event data for business registration
 func CreateBusinessRegisteredEventData(businessID, ownerID, businessName string) map[string]interface{} {
 	return map[string]interface{}{
@@ -68,6 +68,7 @@ func (m *Manager) GenerateTokenPair(user *models.AuthUser, sessionID string) (*T
 			ID:        uuid.New().String(),
 			Subject:   user.ID,
 			Issuer:    m.config.Issuer,
+			Audience:  m.audience(),
 			IssuedAt:  jwt.NewNumericDate(now),
 			ExpiresAt: jwt.NewNumericDate(now.Add(m.config.AccessTokenTTL)),
 			NotBefore: jwt.NewNumericDate(now),
@@ -89,6 +90,7 @@ func (m *Manager) GenerateTokenPair(user *models.AuthUser, sessionID string) (*T
 			ID:        uuid.New().String(),
 			Subject:   user.ID,
 			Issuer:    m.config.Issuer,
+			Audience:  m.audience(),
 			IssuedAt:  jwt.NewNumericDate(now),
 			ExpiresAt: jwt.NewNumericDate(now.Add(m.config.RefreshTokenTTL)),
 			NotBefore: jwt.NewNumericDate(now),
@@ -109,15 +111,27 @@ func (m *Manager) GenerateTokenPair(user *models.AuthUser, sessionID string) (*T
 	}, nil
 }
 
+// audience returns the aud claim of issued tokens, or nil when no audience is configured
+func (m *Manager) audience() jwt.ClaimStrings {
+	if m.config.Audience == "" {
+		return nil
+	}
+	return jwt.ClaimStrings{m.config.Audience}
+}
+
 // ValidateToken validates a JWT token and returns the claims
 func (m *Manager) ValidateToken(tokenString string, expectedType TokenType) (*Claims, error) {
+	var options []jwt.ParserOption
+	if m.config.Audience != "" {
+		options = append(options, jwt.WithAudience(m.config.Audience))
+	}
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		// Validate signing method
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 		return []byte(m.config.Secret), nil
-	})
+	}, options...)
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
@@ -126,6 +140,10 @@ func (m *Manager) ValidateToken(tokenString string, expectedType TokenType) (*Cl
 		if errors.Is(err, jwt.ErrTokenNotValidYet) {
 			return nil, ErrTokenNotValidYet
 		}
+		// The audience is the only claim required, so a missing claim is a missing audience
+		if errors.Is(err, jwt.ErrTokenInvalidAudience) || errors.Is(err, jwt.ErrTokenRequiredClaimMissing) {
+			return nil, ErrInvalidAudience
+		}
 		return nil, fmt.Errorf("failed to parse token: %w", err)
 	}
 
@@ -214,9 +232,11 @@ func (m *Manager) RevokeToken(tokenString string) error {
 var (
 	ErrInvalidToken       = errors.New("invalid token")
 	ErrTokenExpired       = errors.New("token expired")
+	ErrTokenRevoked       = errors.New("token revoked")
 	ErrTokenNotValidYet   = errors.New("token not valid yet")
 	ErrInvalidTokenType   = errors.New("invalid token type")
 	ErrInvalidIssuer      = errors.New("invalid token issuer")
+	ErrInvalidAudience    = errors.New("invalid token audience")
 	ErrMissingToken       = errors.New("missing token")
 	ErrInvalidTokenFormat = errors.New("invalid token format")
 )
@@ -0,0 +1,69 @@
+package jwt_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/slotwise/auth-service/internal/config"
+	"github.com/slotwise/auth-service/internal/models"
+	"github.com/slotwise/auth-service/pkg/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testConfig(audience string) config.JWT {
+	return config.JWT{
+		Secret:          "test-secret",
+		AccessTokenTTL:  15 * time.Minute,
+		RefreshTokenTTL: time.Hour,
+		Issuer:          "slotwise-test",
+		Audience:        audience,
+	}
+}
+
+var testUser = &models.AuthUser{ID: "user-1", Email: "user@example.com", Role: "client"}
+
+func TestValidateToken_AcceptsMatchingAudience(t *testing.T) {
+	manager := jwt.NewManager(testConfig("slotwise-staging"))
+	tokens, err := manager.GenerateTokenPair(testUser, "session-1")
+	require.NoError(t, err)
+
+	claims, err := manager.ValidateAccessToken(tokens.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", claims.UserID)
+	assert.Equal(t, []string{"slotwise-staging"}, []string(claims.Audience))
+
+	_, err = manager.ValidateRefreshToken(tokens.RefreshToken)
+	assert.NoError(t, err)
+}
+
+func TestValidateToken_RejectsWrongAudience(t *testing.T) {
+	// Same secret and issuer, minted for another environment
+	tokens, err := jwt.NewManager(testConfig("slotwise-staging")).GenerateTokenPair(testUser, "session-1")
+	require.NoError(t, err)
+	manager := jwt.NewManager(testConfig("slotwise-production"))
+
+	_, err = manager.ValidateAccessToken(tokens.AccessToken)
+	assert.ErrorIs(t, err, jwt.ErrInvalidAudience)
+
+	_, err = manager.ValidateRefreshToken(tokens.RefreshToken)
+	assert.ErrorIs(t, err, jwt.ErrInvalidAudience)
+}
+
+func TestValidateToken_RejectsMissingAudience(t *testing.T) {
+	tokens, err := jwt.NewManager(testConfig("")).GenerateTokenPair(testUser, "session-1")
+	require.NoError(t, err)
+
+	_, err = jwt.NewManager(testConfig("slotwise-production")).ValidateAccessToken(tokens.AccessToken)
+	assert.ErrorIs(t, err, jwt.ErrInvalidAudience)
+}
+
+func TestValidateToken_RejectsWrongIssuer(t *testing.T) {
+	cfg := testConfig("slotwise-production")
+	cfg.Issuer = "someone-else"
+	tokens, err := jwt.NewManager(cfg).GenerateTokenPair(testUser, "session-1")
+	require.NoError(t, err)
+
+	_, err = jwt.NewManager(testConfig("slotwise-production")).ValidateAccessToken(tokens.AccessToken)
+	assert.ErrorIs(t, err, jwt.ErrInvalidIssuer)
+}
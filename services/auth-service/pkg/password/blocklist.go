@@ -0,0 +1,94 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// LoadBlocklist reads a password blocklist file with one password per line.
+// Blank lines and lines starting with '#' are ignored; entries are matched case-insensitively.
+func LoadBlocklist(path string) (map[string]struct{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open password blocklist: %w", err)
+	}
+	defer file.Close()
+
+	blocklist := make(map[string]struct{})
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		blocklist[strings.ToLower(line)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read password blocklist: %w", err)
+	}
+
+	return blocklist, nil
+}
+
+// BreachChecker reports whether a password is known to have been exposed in a breach
+type BreachChecker interface {
+	IsBreached(password string) (bool, error)
+}
+
+// RangeBreachChecker checks passwords against a k-anonymity range API such as
+// "Have I Been Pwned". Only the first 5 hex characters of the SHA-1 hash leave the service.
+type RangeBreachChecker struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewRangeBreachChecker creates a new range API breach checker
+func NewRangeBreachChecker(baseURL string, timeout time.Duration) *RangeBreachChecker {
+	return &RangeBreachChecker{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// IsBreached queries the range API with the hash prefix and looks for the suffix in the response
+func (c *RangeBreachChecker) IsBreached(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/range/%s", c.baseURL, prefix), nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to create breach check request: %w", err)
+	}
+	req.Header.Set("Add-Padding", "true")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("breach check request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("breach check returned status %d", resp.StatusCode)
+	}
+
+	// Each line is "<hash suffix>:<count>"; padded entries have a count of 0
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		hashSuffix, count, found := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if found && strings.EqualFold(hashSuffix, suffix) && count != "0" {
+			return true, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return false, fmt.Errorf("failed to read breach check response: %w", err)
+	}
+
+	return false, nil
+}
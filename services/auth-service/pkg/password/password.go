@@ -19,6 +19,11 @@ type Config struct {
 	Parallelism uint8
 	SaltLength  uint32
 	KeyLength   uint32
+
+	// Blocklist holds additional lowercased passwords to reject on top of the built-in list.
+	Blocklist map[string]struct{}
+	// BreachChecker optionally checks passwords against a known-breach corpus.
+	BreachChecker BreachChecker
 }
 
 // DefaultConfig returns the default configuration for password hashing
@@ -45,12 +50,10 @@ func NewManager(config *Config) *Manager {
 	return &Manager{config: config}
 }
 
-// Hash generates a hash for the given password
+// Hash generates a hash for the given password. It does not check the password, so a user-chosen
+// password is validated once with ValidatePassword first, and generated ones skip the policy and
+// breach check.
 func (m *Manager) Hash(password string) (string, error) {
-	if err := m.ValidatePassword(password); err != nil {
-		return "", err
-	}
-
 	// Generate a random salt
 	salt, err := m.generateSalt()
 	if err != nil {
@@ -138,6 +141,14 @@ func (m *Manager) ValidatePassword(password string) error {
 		return ErrPasswordTooCommon
 	}
 
+	// Check against the breach corpus if configured. Lookup failures are ignored
+	// so an outage of the external API does not block registrations.
+	if m.config.BreachChecker != nil {
+		if breached, err := m.config.BreachChecker.IsBreached(password); err == nil && breached {
+			return ErrPasswordBreached
+		}
+	}
+
 	return nil
 }
 
@@ -234,8 +245,13 @@ func (m *Manager) parseHash(hash string) (*Config, []byte, []byte, error) {
 	return config, salt, hashBytes, nil
 }
 
-// isCommonPassword checks if the password is in a list of common passwords
+// isCommonPassword checks if the password is in the configured blocklist or the built-in list of common passwords
 func (m *Manager) isCommonPassword(password string) bool {
+	lowerPassword := strings.ToLower(password)
+	if _, blocked := m.config.Blocklist[lowerPassword]; blocked {
+		return true
+	}
+
 	// List of common weak passwords
 	commonPasswords := []string{
 		"password", "123456", "123456789", "12345678", "12345",
@@ -246,7 +262,6 @@ func (m *Manager) isCommonPassword(password string) bool {
 		"football", "jesus", "michael", "ninja", "mustang",
 	}
 
-	lowerPassword := strings.ToLower(password)
 	for _, common := range commonPasswords {
 		if lowerPassword == common {
 			return true
@@ -265,6 +280,7 @@ var (
 	ErrPasswordMissingDigit     = errors.New("password must contain at least one digit")
 	ErrPasswordMissingSpecial   = errors.New("password must contain at least one special character")
 	ErrPasswordTooCommon        = errors.New("password is too common")
+	ErrPasswordBreached         = errors.New("password has appeared in a data breach")
 	ErrInvalidHashFormat        = errors.New("invalid hash format")
 	ErrUnsupportedHashType      = errors.New("unsupported hash type")
 	ErrIncompatibleVersion      = errors.New("incompatible hash version")
@@ -0,0 +1,117 @@
+package password_test
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slotwise/auth-service/pkg/password"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeBlocklist(t *testing.T, lines ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "blocklist.txt")
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o600))
+	return path
+}
+
+func TestLoadBlocklist(t *testing.T) {
+	path := writeBlocklist(t, "# leaked passwords", "", "Summer2024!", "  CorrectHorse9$  ")
+
+	blocklist, err := password.LoadBlocklist(path)
+	require.NoError(t, err)
+	assert.Len(t, blocklist, 2)
+	assert.Contains(t, blocklist, "summer2024!")
+	assert.Contains(t, blocklist, "correcthorse9$")
+
+	_, err = password.LoadBlocklist(filepath.Join(t.TempDir(), "missing.txt"))
+	assert.Error(t, err)
+}
+
+func TestValidatePasswordBlocklist(t *testing.T) {
+	blocklist, err := password.LoadBlocklist(writeBlocklist(t, "Summer2024!a"))
+	require.NoError(t, err)
+
+	config := password.DefaultConfig()
+	config.Blocklist = blocklist
+	mgr := password.NewManager(config)
+
+	t.Run("Password on loaded list is rejected", func(t *testing.T) {
+		assert.ErrorIs(t, mgr.ValidatePassword("summer2024!A"), password.ErrPasswordTooCommon)
+	})
+
+	t.Run("Strong unique password passes", func(t *testing.T) {
+		assert.NoError(t, mgr.ValidatePassword("q7#Vt9!mZr2&Lp"))
+	})
+}
+
+func newRangeServer(t *testing.T, breached string) *httptest.Server {
+	t.Helper()
+	sum := sha1.Sum([]byte(breached))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefix := strings.TrimPrefix(r.URL.Path, "/range/")
+		assert.Len(t, prefix, 5)
+		fmt.Fprintln(w, "0018A45C4D1DEF81644B54AB7F969B88D65:0")
+		if prefix == hash[:5] {
+			fmt.Fprintf(w, "%s:42\n", hash[5:])
+		}
+	}))
+}
+
+func TestValidatePasswordBreachCheck(t *testing.T) {
+	server := newRangeServer(t, "Breached2024!")
+	defer server.Close()
+
+	config := password.DefaultConfig()
+	config.BreachChecker = password.NewRangeBreachChecker(server.URL, time.Second)
+	mgr := password.NewManager(config)
+
+	assert.ErrorIs(t, mgr.ValidatePassword("Breached2024!"), password.ErrPasswordBreached)
+	assert.NoError(t, mgr.ValidatePassword("q7#Vt9!mZr2&Lp"))
+}
+
+func TestValidatePasswordBreachCheckUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	config := password.DefaultConfig()
+	config.BreachChecker = password.NewRangeBreachChecker(server.URL, time.Second)
+	mgr := password.NewManager(config)
+
+	// The breach check fails open so registrations are not blocked by an outage
+	assert.NoError(t, mgr.ValidatePassword("q7#Vt9!mZr2&Lp"))
+}
+
+func TestHashDoesNotValidate(t *testing.T) {
+	lookups := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lookups++
+	}))
+	defer server.Close()
+
+	config := password.DefaultConfig()
+	config.BreachChecker = password.NewRangeBreachChecker(server.URL, time.Second)
+	mgr := password.NewManager(config)
+
+	// Validation is the caller's job, so Hash neither applies the policy nor queries the breach corpus
+	hash, err := mgr.Hash("short")
+	require.NoError(t, err)
+	assert.Zero(t, lookups)
+
+	ok, err := mgr.Verify("short", hash)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
@@ -0,0 +1,2 @@
+-- AlterTable
+ALTER TABLE "businesses" ADD COLUMN "availabilityVersion" INTEGER NOT NULL DEFAULT 0;
@@ -29,7 +29,18 @@ const createBusinessSchema = z.object({
   currency: z.string().length(3).default('USD'),
 });
 
-const updateBusinessSchema = createBusinessSchema.partial();
+// Booking policy settings, also sent to the Scheduling Service with business.updated
+const bookingSettingsSchema = z.object({
+  maxBookingsPerDay: z.number().int().min(0).optional(), // 0 means no limit
+  maxBookingsPerCustomerPerDay: z.number().int().min(0).optional(), // 0 means no limit
+  cancellationCutoffHours: z.number().int().min(0).optional(),
+  cancellationFeeWindowHours: z.number().int().min(0).optional(),
+  cancellationFee: z.number().min(0).optional(), // In major units, like service prices
+  allowBackToBack: z.boolean().optional(),
+  minimumNoticeMinutes: z.number().int().min(0).optional(),
+});
+
+const updateBusinessSchema = createBusinessSchema.partial().merge(bookingSettingsSchema);
 
 const businessParamsSchema = z.object({
   id: z.string().cuid(), // General business ID
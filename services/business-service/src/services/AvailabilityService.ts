@@ -60,8 +60,10 @@ export class AvailabilityService {
       }
     }
 
-    // Atomically update availability: delete all existing rules for the business and create new ones
-    await this.prisma.$transaction(async (tx: Prisma.TransactionClient) => {
+    // Atomically update availability: delete all existing rules for the business and create new ones,
+    // bumping the business's availability version. The row update also serializes concurrent changes,
+    // so each snapshot gets its own version in commit order.
+    const version = await this.prisma.$transaction(async (tx: Prisma.TransactionClient) => {
       await tx.availability.deleteMany({
         where: { businessId: businessId },
       });
@@ -76,6 +78,13 @@ export class AvailabilityService {
           })),
         });
       }
+
+      const updated = await tx.business.update({
+        where: { id: businessId },
+        data: { availabilityVersion: { increment: 1 } },
+        select: { availabilityVersion: true },
+      });
+      return updated.availabilityVersion;
     });
 
     // Fetch the created rules with all fields
@@ -88,7 +97,8 @@ export class AvailabilityService {
     try {
       const eventPayload = {
         businessId: businessId,
-        // scheduleId: could be a version or a specific ID if availabilities are versioned
+        // Lets consumers ignore an older snapshot that is delivered after a newer one
+        version,
         rules: fullNewRules.map((rule: Availability) => ({
           dayOfWeek: rule.dayOfWeek,
           startTime: rule.startTime,
@@ -21,7 +21,29 @@ interface CreateBusinessData {
   ownerId: string;
 }
 
-interface UpdateBusinessData {
+// Booking policy kept in the bookingSettings JSON column. Changes are published flat in business.updated,
+// which is how the Scheduling Service learns them.
+export interface BookingSettings {
+  maxBookingsPerDay?: number; // 0 means no limit
+  maxBookingsPerCustomerPerDay?: number; // 0 means no limit
+  cancellationCutoffHours?: number; // Customers cannot cancel within this many hours of the start
+  cancellationFeeWindowHours?: number; // Cancelling within this many hours of the start incurs the fee
+  cancellationFee?: number; // In major units, like service prices
+  allowBackToBack?: boolean; // Whether a booking may start exactly when another ends
+  minimumNoticeMinutes?: number; // How far ahead of its start a booking must be made
+}
+
+const BOOKING_SETTING_KEYS: (keyof BookingSettings)[] = [
+  'maxBookingsPerDay',
+  'maxBookingsPerCustomerPerDay',
+  'cancellationCutoffHours',
+  'cancellationFeeWindowHours',
+  'cancellationFee',
+  'allowBackToBack',
+  'minimumNoticeMinutes',
+];
+
+interface UpdateBusinessData extends BookingSettings {
   name?: string;
   description?: string;
   email?: string;
@@ -92,6 +114,9 @@ export class BusinessService {
         name: business.name,
         subdomain: business.subdomain,
         ownerId: business.ownerId,
+        status: business.status,
+        currency: business.currency,
+        timezone: business.timezone,
       });
 
       logger.info('Business created', { businessId: business.id, subdomain: business.subdomain });
@@ -187,10 +212,17 @@ export class BusinessService {
         throw new Error('Business not found');
       }
 
+      const { columns, bookingSettings } = splitBookingSettings(data);
       const business = await prisma.business.update({ // MODIFIED: this.prisma -> prisma
         where: { id },
         data: {
-          ...data,
+          ...columns,
+          ...(Object.keys(bookingSettings).length > 0 && {
+            bookingSettings: JSON.stringify({
+              ...parseBookingSettings(existingBusiness.bookingSettings),
+              ...bookingSettings,
+            }),
+          }),
           updatedAt: new Date(),
         },
       });
@@ -304,3 +336,29 @@ export class BusinessService {
     }
   }
 }
+
+// splitBookingSettings separates the booking settings in an update from the fields stored in their own columns.
+function splitBookingSettings(data: UpdateBusinessData): {
+  columns: Omit<UpdateBusinessData, keyof BookingSettings>;
+  bookingSettings: BookingSettings;
+} {
+  const columns: UpdateBusinessData = { ...data };
+  const bookingSettings: Record<string, unknown> = {};
+  for (const key of BOOKING_SETTING_KEYS) {
+    if (data[key] !== undefined) {
+      bookingSettings[key] = data[key];
+    }
+    delete columns[key];
+  }
+  return { columns, bookingSettings: bookingSettings as BookingSettings };
+}
+
+// parseBookingSettings reads the bookingSettings column, treating a malformed value as empty.
+export function parseBookingSettings(raw: string): BookingSettings {
+  try {
+    const parsed = JSON.parse(raw);
+    return parsed && typeof parsed === 'object' ? parsed : {};
+  } catch {
+    return {};
+  }
+}
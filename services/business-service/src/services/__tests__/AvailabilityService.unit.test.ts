@@ -21,6 +21,9 @@ jest.mock('../../database/prisma', () => ({
           deleteMany: jest.fn(),
           createMany: jest.fn(),
         },
+        business: {
+          update: jest.fn().mockResolvedValue({ availabilityVersion: 1 }),
+        },
       };
       await callback(mockTx);
       // Make sure the mocked methods within the transaction are returned for assertions if needed
@@ -53,10 +56,13 @@ describe('AvailabilityService', () => {
           deleteMany: jest.fn(),
           createMany: jest.fn(),
         },
+        business: {
+          update: jest.fn().mockResolvedValue({ availabilityVersion: 8 }),
+        },
         // Add other models if they were part of a real transaction
       };
-      await callback(mockTxClient);
       mockPrismaTransaction = mockTxClient; // Store the mock client for assertions
+      return callback(mockTxClient);
     });
   });
 
@@ -98,6 +104,7 @@ describe('AvailabilityService', () => {
       currentPeriodStart: new Date(),
       currentPeriodEnd: new Date(),
       cancelAtPeriodEnd: false,
+      availabilityVersion: 7,
     };
 
     const mockCreatedAvailabilities: Availability[] = availabilityData.rules.map(rule => ({
@@ -134,6 +141,13 @@ describe('AvailabilityService', () => {
         })),
       });
 
+      // The version comes from the business row, bumped in the same transaction as the rules
+      expect(mockPrismaTransaction.business.update).toHaveBeenCalledWith({
+        where: { id: businessId },
+        data: { availabilityVersion: { increment: 1 } },
+        select: { availabilityVersion: true },
+      });
+
       expect(prisma.availability.findMany).toHaveBeenCalledWith({
         where: { businessId: businessId },
         orderBy: [{ dayOfWeek: 'asc' }, { startTime: 'asc' }],
@@ -143,6 +157,7 @@ describe('AvailabilityService', () => {
         'business.availability.updated',
         expect.objectContaining({
           businessId,
+          version: 8,
           rules: mockCreatedAvailabilities.map(r => ({ dayOfWeek: r.dayOfWeek, startTime: r.startTime, endTime: r.endTime})),
         })
       );
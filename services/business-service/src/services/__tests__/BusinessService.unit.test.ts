@@ -8,7 +8,9 @@ jest.mock('../../database/prisma', () => ({
   prisma: {
     business: {
       findUnique: jest.fn(),
+      findFirst: jest.fn(),
       create: jest.fn(),
+      update: jest.fn(),
     },
   },
 }));
@@ -68,6 +70,7 @@ describe('BusinessService', () => {
       currentPeriodStart: new Date(),
       currentPeriodEnd: new Date(),
       cancelAtPeriodEnd: false,
+      availabilityVersion: 0,
     };
 
     it('should create a business and publish an event', async () => {
@@ -109,6 +112,9 @@ describe('BusinessService', () => {
             name: mockCreatedBusiness.name,
             subdomain: mockCreatedBusiness.subdomain,
             ownerId: mockCreatedBusiness.ownerId,
+            status: mockCreatedBusiness.status,
+            currency: mockCreatedBusiness.currency,
+            timezone: mockCreatedBusiness.timezone,
           },
         })
       );
@@ -122,4 +128,119 @@ describe('BusinessService', () => {
       );
     });
   });
+
+  describe('updateBusiness', () => {
+    const existingBusiness = {
+      id: 'biz-id',
+      ownerId: 'user-owner-id',
+      bookingSettings: JSON.stringify({ maxBookingsPerDay: 10 }),
+    };
+
+    it('should store booking settings in bookingSettings and publish them with business.updated', async () => {
+      (prisma.business.findFirst as jest.Mock).mockResolvedValue(existingBusiness);
+      (prisma.business.update as jest.Mock).mockResolvedValue({ ...existingBusiness, name: 'Renamed' });
+
+      await businessService.updateBusiness(
+        'biz-id',
+        { name: 'Renamed', maxBookingsPerCustomerPerDay: 2 },
+        'user-owner-id'
+      );
+
+      expect(prisma.business.update).toHaveBeenCalledWith({
+        where: { id: 'biz-id' },
+        data: {
+          name: 'Renamed',
+          bookingSettings: JSON.stringify({ maxBookingsPerDay: 10, maxBookingsPerCustomerPerDay: 2 }),
+          updatedAt: expect.any(Date),
+        },
+      });
+      expect(natsConnection.publish).toHaveBeenCalledWith(
+        'slotwise.business.updated',
+        expect.objectContaining({
+          type: 'business.updated',
+          data: {
+            businessId: 'biz-id',
+            changes: { name: 'Renamed', maxBookingsPerCustomerPerDay: 2 },
+          },
+        })
+      );
+    });
+
+    it('should store and publish the cancellation policy as booking settings', async () => {
+      (prisma.business.findFirst as jest.Mock).mockResolvedValue(existingBusiness);
+      (prisma.business.update as jest.Mock).mockResolvedValue(existingBusiness);
+
+      const policy = { cancellationCutoffHours: 2, cancellationFeeWindowHours: 24, cancellationFee: 15.5 };
+      await businessService.updateBusiness('biz-id', policy, 'user-owner-id');
+
+      expect(prisma.business.update).toHaveBeenCalledWith({
+        where: { id: 'biz-id' },
+        data: {
+          bookingSettings: JSON.stringify({ maxBookingsPerDay: 10, ...policy }),
+          updatedAt: expect.any(Date),
+        },
+      });
+      expect(natsConnection.publish).toHaveBeenCalledWith(
+        'slotwise.business.updated',
+        expect.objectContaining({
+          type: 'business.updated',
+          data: { businessId: 'biz-id', changes: policy },
+        })
+      );
+    });
+
+    it('should store and publish allowBackToBack as a booking setting', async () => {
+      (prisma.business.findFirst as jest.Mock).mockResolvedValue(existingBusiness);
+      (prisma.business.update as jest.Mock).mockResolvedValue(existingBusiness);
+
+      await businessService.updateBusiness('biz-id', { allowBackToBack: false }, 'user-owner-id');
+
+      expect(prisma.business.update).toHaveBeenCalledWith({
+        where: { id: 'biz-id' },
+        data: {
+          bookingSettings: JSON.stringify({ maxBookingsPerDay: 10, allowBackToBack: false }),
+          updatedAt: expect.any(Date),
+        },
+      });
+      expect(natsConnection.publish).toHaveBeenCalledWith(
+        'slotwise.business.updated',
+        expect.objectContaining({
+          data: { businessId: 'biz-id', changes: { allowBackToBack: false } },
+        })
+      );
+    });
+
+    it('should store and publish minimumNoticeMinutes as a booking setting', async () => {
+      (prisma.business.findFirst as jest.Mock).mockResolvedValue(existingBusiness);
+      (prisma.business.update as jest.Mock).mockResolvedValue(existingBusiness);
+
+      await businessService.updateBusiness('biz-id', { minimumNoticeMinutes: 120 }, 'user-owner-id');
+
+      expect(prisma.business.update).toHaveBeenCalledWith({
+        where: { id: 'biz-id' },
+        data: {
+          bookingSettings: JSON.stringify({ maxBookingsPerDay: 10, minimumNoticeMinutes: 120 }),
+          updatedAt: expect.any(Date),
+        },
+      });
+      expect(natsConnection.publish).toHaveBeenCalledWith(
+        'slotwise.business.updated',
+        expect.objectContaining({
+          data: { businessId: 'biz-id', changes: { minimumNoticeMinutes: 120 } },
+        })
+      );
+    });
+
+    it('should leave bookingSettings alone when no booking setting changes', async () => {
+      (prisma.business.findFirst as jest.Mock).mockResolvedValue(existingBusiness);
+      (prisma.business.update as jest.Mock).mockResolvedValue(existingBusiness);
+
+      await businessService.updateBusiness('biz-id', { name: 'Renamed' }, 'user-owner-id');
+
+      expect(prisma.business.update).toHaveBeenCalledWith({
+        where: { id: 'biz-id' },
+        data: { name: 'Renamed', updatedAt: expect.any(Date) },
+      });
+    });
+  });
 });
@@ -195,6 +195,51 @@ describe('Notification Routes (/api/v1/notifications)', () => {
         const response = await app.inject({ method: 'POST', url: '/api/v1/notifications/schedule', payload });
         expect(response.statusCode).toBe(400); // Zod validation
     });
+
+    it('should schedule one notification for two requests with the same idempotency key', async () => {
+      const payload = {
+        type: 'booking_reminder',
+        recipientEmail: 'test@example.com',
+        templateData: { userName: 'Test User' },
+        scheduledFor: new Date(Date.now() + 24 * 60 * 60 * 1000).toISOString(),
+        bookingId: 'booking-retry',
+      };
+      const headers = { 'idempotency-key': `booking-retry:booking_reminder:${Date.now()}` };
+
+      const first = await app.inject({ method: 'POST', url: '/api/v1/notifications/schedule', payload, headers });
+      const retry = await app.inject({ method: 'POST', url: '/api/v1/notifications/schedule', payload, headers });
+
+      expect(first.statusCode).toBe(201);
+      expect(retry.statusCode).toBe(200);
+      const retryBody = JSON.parse(retry.payload);
+      expect(retryBody.duplicate).toBe(true);
+      expect(retryBody.scheduledNotificationId).toBe(JSON.parse(first.payload).scheduledNotificationId);
+    });
+  });
+
+  describe('DELETE /schedule', () => {
+    it('should cancel the booking\'s pending notifications only once', async () => {
+      const payload = {
+        type: 'booking_reminder',
+        recipientEmail: 'test@example.com',
+        templateData: { userName: 'Test User' },
+        scheduledFor: new Date(Date.now() + 24 * 60 * 60 * 1000).toISOString(),
+        bookingId: 'booking-to-cancel',
+      };
+      await app.inject({ method: 'POST', url: '/api/v1/notifications/schedule', payload });
+
+      const first = await app.inject({ method: 'DELETE', url: '/api/v1/notifications/schedule?bookingId=booking-to-cancel' });
+      const second = await app.inject({ method: 'DELETE', url: '/api/v1/notifications/schedule?bookingId=booking-to-cancel' });
+
+      expect(first.statusCode).toBe(200);
+      expect(JSON.parse(first.payload).cancelled).toBe(1);
+      expect(JSON.parse(second.payload).cancelled).toBe(0);
+    });
+
+    it('should return 400 without a bookingId', async () => {
+      const response = await app.inject({ method: 'DELETE', url: '/api/v1/notifications/schedule' });
+      expect(response.statusCode).toBe(400);
+    });
   });
 
   // Note: Testing the actual scheduler mechanism (setInterval) is more of an integration test.
@@ -60,7 +60,8 @@ interface ScheduledNotification {
   subject?: string; // Optional subject
   scheduledFor: Date;
   bookingId: string; // Or a more generic entityId
-  status: 'pending' | 'processing' | 'sent' | 'failed';
+  idempotencyKey?: string; // Retries with the same key return this notification instead of scheduling another
+  status: 'pending' | 'processing' | 'sent' | 'failed' | 'cancelled';
   createdAt: Date;
 }
 const scheduledNotifications: ScheduledNotification[] = [];
@@ -75,6 +76,7 @@ const scheduleEmailNotificationSchema = z.object({
   subject: z.string().optional(),      // Optional custom subject
   scheduledFor: z.string().datetime(), // ISO 8601 date-time string
   bookingId: z.string().min(1),      // To link the notification to a booking
+  idempotencyKey: z.string().optional(), // Also accepted as the Idempotency-Key header
 });
 
 
@@ -160,11 +162,21 @@ export async function notificationRoutes(fastify: FastifyInstance) {
             templateData: { type: 'object' },
             subject: { type: 'string' },
             scheduledFor: { type: 'string', format: 'date-time' },
-            bookingId: { type: 'string', minLength: 1 }
+            bookingId: { type: 'string', minLength: 1 },
+            idempotencyKey: { type: 'string', minLength: 1 }
           },
           required: ['type', 'recipientEmail', 'templateData', 'scheduledFor', 'bookingId']
         },
         response: {
+          200: {
+            type: 'object',
+            properties: {
+              success: { type: 'boolean' },
+              scheduledNotificationId: { type: 'string' },
+              duplicate: { type: 'boolean' },
+              message: { type: 'string' },
+            },
+          },
           201: {
             type: 'object',
             properties: {
@@ -182,6 +194,22 @@ export async function notificationRoutes(fastify: FastifyInstance) {
     ) => {
       try {
         const { type, recipientEmail, templateData, subject, scheduledFor, bookingId } = request.body;
+        const headerKey = request.headers['idempotency-key'];
+        const idempotencyKey = request.body.idempotencyKey || (typeof headerKey === 'string' ? headerKey : undefined);
+
+        // A retried request returns the notification the first attempt scheduled
+        if (idempotencyKey) {
+          const existing = scheduledNotifications.find(n => n.idempotencyKey === idempotencyKey);
+          if (existing) {
+            logger.info({ idempotencyKey, scheduledNotificationId: existing.id }, 'Duplicate schedule request, returning existing notification');
+            return reply.code(200).send({
+              success: true,
+              scheduledNotificationId: existing.id,
+              duplicate: true,
+              message: `Notification for booking "${existing.bookingId}" was already scheduled.`,
+            });
+          }
+        }
 
         // Basic validation for scheduledFor date (e.g., not in the past)
         if (new Date(scheduledFor) < new Date()) {
@@ -199,6 +227,7 @@ export async function notificationRoutes(fastify: FastifyInstance) {
           subject,
           scheduledFor: new Date(scheduledFor),
           bookingId,
+          idempotencyKey,
           status: 'pending',
           createdAt: new Date(),
         };
@@ -227,6 +256,48 @@ export async function notificationRoutes(fastify: FastifyInstance) {
   );
 
 
+  // Cancel a booking's pending scheduled notifications, e.g. when the booking is cancelled
+  fastify.delete(
+    '/schedule',
+    {
+      schema: {
+        querystring: {
+          type: 'object',
+          properties: {
+            bookingId: { type: 'string', minLength: 1 },
+          },
+          required: ['bookingId'],
+        },
+        response: {
+          200: {
+            type: 'object',
+            properties: {
+              success: { type: 'boolean' },
+              cancelled: { type: 'integer' },
+              message: { type: 'string' },
+            },
+          },
+        },
+      },
+    },
+    async (request: FastifyRequest<{ Querystring: { bookingId: string } }>, reply: FastifyReply) => {
+      const { bookingId } = request.query;
+      let cancelled = 0;
+      for (const job of scheduledNotifications) {
+        if (job.bookingId === bookingId && job.status === 'pending') {
+          job.status = 'cancelled';
+          cancelled++;
+        }
+      }
+      logger.info({ bookingId, cancelled }, 'Cancelled scheduled notifications for booking');
+      return reply.code(200).send({
+        success: true,
+        cancelled,
+        message: `Cancelled ${cancelled} scheduled notification(s) for booking "${bookingId}".`,
+      });
+    }
+  );
+
   // Get notifications (keeping existing GET routes for now, may need adjustment)
   fastify.get(
     '/',
@@ -6,12 +6,16 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/slotwise/scheduling-service/internal/config"
 	// Standardized logger path
 )
 
+// MaxNotificationBatchSize is the maximum number of notifications sent in a single batch request.
+const MaxNotificationBatchSize = 50
+
 // NotificationServiceClient handles communication with the notification service.
 type NotificationServiceClient struct {
 	httpClient *http.Client
@@ -44,6 +48,18 @@ type ScheduleNotificationRequest struct {
 	Subject        *string                `json:"subject,omitempty"` // Optional subject override
 	ScheduledFor   time.Time              `json:"scheduledFor"`      // ISO 8601 format expected by notification service
 	BookingID      string                 `json:"bookingId"`
+	// IdempotencyKey makes retries safe: the notification service schedules at most one notification per key.
+	// When empty, ScheduleNotification derives one from the booking, type and scheduled time.
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
+}
+
+// IdempotencyKeyHeader carries the idempotency key of a schedule request.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// ScheduleIdempotencyKey returns the key identifying one scheduled notification of a booking, so a retried
+// schedule call maps onto the notification the first attempt created. A rescheduled booking gets a new key.
+func ScheduleIdempotencyKey(bookingID, notificationType string, scheduledFor time.Time) string {
+	return fmt.Sprintf("%s:%s:%d", bookingID, notificationType, scheduledFor.Unix())
 }
 
 // NotificationResponse defines the expected response from the notification service.
@@ -52,9 +68,163 @@ type NotificationResponse struct {
 	Message                 string  `json:"message"`
 	MessageID               *string `json:"messageId,omitempty"`               // For send
 	ScheduledNotificationID *string `json:"scheduledNotificationId,omitempty"` // For schedule
+	Duplicate               bool    `json:"duplicate,omitempty"`               // Schedule matched an existing notification by idempotency key
+	Cancelled               int     `json:"cancelled,omitempty"`               // For cancel: scheduled notifications that will no longer be sent
 	Error                   *string `json:"error,omitempty"`
 }
 
+// BatchSendResult reports the outcome of one notification within a batch send.
+// Index refers to the position of the notification in the slice passed to SendBatch.
+type BatchSendResult struct {
+	Index     int     `json:"index"`
+	Success   bool    `json:"success"`
+	MessageID *string `json:"messageId,omitempty"`
+	Error     *string `json:"error,omitempty"`
+}
+
+// batchSendRequest is the payload for the batch send endpoint.
+type batchSendRequest struct {
+	Notifications []SendNotificationRequest `json:"notifications"`
+}
+
+// batchSendResponse defines the expected response from the batch send endpoint.
+type batchSendResponse struct {
+	Success bool              `json:"success"`
+	Message string            `json:"message"`
+	Results []BatchSendResult `json:"results"`
+	Error   *string           `json:"error,omitempty"`
+}
+
+// errBatchEndpointNotFound signals that the notification service does not expose the batch endpoint.
+var errBatchEndpointNotFound = fmt.Errorf("notification batch endpoint not found")
+
+// SendBatch sends notifications in chunks of MaxNotificationBatchSize using the batch endpoint.
+// If the notification service does not support batching (404), it falls back to sending each
+// notification individually. One result is returned per request, in the same order; the error is
+// non-nil if any notification failed.
+func (c *NotificationServiceClient) SendBatch(reqs []SendNotificationRequest) ([]BatchSendResult, error) {
+	if c.baseURL == "" {
+		slog.Warn("NotificationServiceClient: Base URL is not configured. Skipping batch notification.", "count", len(reqs))
+		return nil, fmt.Errorf("notification service URL is not configured")
+	}
+
+	results := make([]BatchSendResult, 0, len(reqs))
+	useBatchEndpoint := true
+	for start := 0; start < len(reqs); start += MaxNotificationBatchSize {
+		end := start + MaxNotificationBatchSize
+		if end > len(reqs) {
+			end = len(reqs)
+		}
+		chunk := reqs[start:end]
+
+		if useBatchEndpoint {
+			chunkResults, err := c.sendBatchChunk(chunk, start)
+			if err == nil {
+				results = append(results, chunkResults...)
+				continue
+			}
+			if err != errBatchEndpointNotFound {
+				errMsg := err.Error()
+				for i := range chunk {
+					results = append(results, BatchSendResult{Index: start + i, Error: &errMsg})
+				}
+				continue
+			}
+			slog.Warn("NotificationServiceClient: Batch endpoint not available, falling back to individual sends", "count", len(reqs)-start)
+			useBatchEndpoint = false
+		}
+
+		for i, req := range chunk {
+			result := BatchSendResult{Index: start + i}
+			resp, err := c.SendNotification(req)
+			if err != nil {
+				errMsg := err.Error()
+				result.Error = &errMsg
+			} else {
+				result.Success = true
+				result.MessageID = resp.MessageID
+			}
+			results = append(results, result)
+		}
+	}
+
+	failed := 0
+	for _, result := range results {
+		if !result.Success {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return results, fmt.Errorf("%d of %d notifications failed", failed, len(reqs))
+	}
+	return results, nil
+}
+
+// sendBatchChunk posts a single chunk to the batch endpoint. offset is the index of the chunk's
+// first notification in the overall batch, used to map per-item results back to the caller's slice.
+func (c *NotificationServiceClient) sendBatchChunk(chunk []SendNotificationRequest, offset int) ([]BatchSendResult, error) {
+	payloadBytes, err := json.Marshal(batchSendRequest{Notifications: chunk})
+	if err != nil {
+		slog.Error("NotificationServiceClient: Failed to marshal batch send request", "error", err, "count", len(chunk))
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/api/v1/notifications/send-batch", c.baseURL)
+	httpReq, err := http.NewRequest("POST", url, bytes.NewBuffer(payloadBytes))
+	if err != nil {
+		slog.Error("NotificationServiceClient: Failed to create HTTP request for batch send", "error", err, "url", url)
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		slog.Error("NotificationServiceClient: HTTP request to batch send notifications failed", "error", err, "url", url)
+		return nil, fmt.Errorf("request to notification service failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errBatchEndpointNotFound
+	}
+
+	var batchResp batchSendResponse
+	if err := json.NewDecoder(resp.Body).Decode(&batchResp); err != nil {
+		slog.Error("NotificationServiceClient: Failed to decode batch send response", "error", err, "status_code", resp.StatusCode)
+		return nil, fmt.Errorf("failed to decode response: %w (status: %d)", err, resp.StatusCode)
+	}
+
+	if resp.StatusCode >= 400 {
+		slog.Error("NotificationServiceClient: Batch send request failed with error code",
+			"status_code", resp.StatusCode, "url", url, "response_message", batchResp.Message, "response_error", batchResp.Error)
+		errMsg := fmt.Sprintf("notification service returned error (status %d)", resp.StatusCode)
+		if batchResp.Error != nil {
+			errMsg = fmt.Sprintf("%s: %s", errMsg, *batchResp.Error)
+		} else if batchResp.Message != "" {
+			errMsg = fmt.Sprintf("%s: %s", errMsg, batchResp.Message)
+		}
+		return nil, fmt.Errorf(errMsg)
+	}
+
+	// Items the service did not report on are treated as failed
+	results := make([]BatchSendResult, len(chunk))
+	for i := range results {
+		missing := "no result returned for notification"
+		results[i] = BatchSendResult{Index: offset + i, Error: &missing}
+	}
+	for _, result := range batchResp.Results {
+		if result.Index < 0 || result.Index >= len(chunk) {
+			continue
+		}
+		i := result.Index
+		result.Index = offset + i
+		results[i] = result
+	}
+
+	slog.Info("NotificationServiceClient: Batch send request completed", "count", len(chunk), "url", url)
+	return results, nil
+}
+
 // SendNotification sends a request to the notification service to dispatch an email immediately.
 func (c *NotificationServiceClient) SendNotification(req SendNotificationRequest) (*NotificationResponse, error) {
 	if c.baseURL == "" {
@@ -112,6 +282,9 @@ func (c *NotificationServiceClient) ScheduleNotification(req ScheduleNotificatio
 		slog.Warn("NotificationServiceClient: Base URL is not configured. Skipping scheduling.", "type", req.Type, "booking_id", req.BookingID)
 		return nil, fmt.Errorf("notification service URL is not configured")
 	}
+	if req.IdempotencyKey == "" {
+		req.IdempotencyKey = ScheduleIdempotencyKey(req.BookingID, req.Type, req.ScheduledFor)
+	}
 
 	payloadBytes, err := json.Marshal(req)
 	if err != nil {
@@ -126,6 +299,7 @@ func (c *NotificationServiceClient) ScheduleNotification(req ScheduleNotificatio
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set(IdempotencyKeyHeader, req.IdempotencyKey)
 	// TODO: Add authentication header if needed
 
 	resp, err := c.httpClient.Do(httpReq)
@@ -153,10 +327,52 @@ func (c *NotificationServiceClient) ScheduleNotification(req ScheduleNotificatio
 		return &notificationResp, fmt.Errorf(errMsg)
 	}
 
+	if notificationResp.Duplicate {
+		slog.Info("NotificationServiceClient: Notification was already scheduled", "type", req.Type, "booking_id", req.BookingID, "idempotency_key", req.IdempotencyKey, "scheduled_id", notificationResp.ScheduledNotificationID)
+		return &notificationResp, nil
+	}
+
 	slog.Info("NotificationServiceClient: Schedule notification request successful", "type", req.Type, "booking_id", req.BookingID, "scheduled_id", notificationResp.ScheduledNotificationID)
 	return &notificationResp, nil
 }
 
+// CancelScheduledNotifications cancels the booking's scheduled notifications that have not been sent yet,
+// returning how many were cancelled.
+func (c *NotificationServiceClient) CancelScheduledNotifications(bookingID string) (int, error) {
+	if c.baseURL == "" {
+		slog.Warn("NotificationServiceClient: Base URL is not configured. Skipping cancellation.", "booking_id", bookingID)
+		return 0, fmt.Errorf("notification service URL is not configured")
+	}
+
+	endpoint := fmt.Sprintf("%s/api/v1/notifications/schedule?bookingId=%s", c.baseURL, url.QueryEscape(bookingID))
+	httpReq, err := http.NewRequest("DELETE", endpoint, nil)
+	if err != nil {
+		slog.Error("NotificationServiceClient: Failed to create HTTP request for cancel", "error", err, "url", endpoint)
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		slog.Error("NotificationServiceClient: HTTP request to cancel scheduled notifications failed", "error", err, "url", endpoint)
+		return 0, fmt.Errorf("request to notification service failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var notificationResp NotificationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&notificationResp); err != nil {
+		slog.Error("NotificationServiceClient: Failed to decode cancel response", "error", err, "status_code", resp.StatusCode)
+		return 0, fmt.Errorf("failed to decode response: %w (status: %d)", err, resp.StatusCode)
+	}
+	if resp.StatusCode >= 400 {
+		slog.Error("NotificationServiceClient: Cancel scheduled notifications request failed with error code",
+			"status_code", resp.StatusCode, "url", endpoint, "response_message", notificationResp.Message)
+		return 0, fmt.Errorf("notification service returned error (status %d): %s", resp.StatusCode, notificationResp.Message)
+	}
+
+	slog.Info("NotificationServiceClient: Scheduled notifications cancelled", "booking_id", bookingID, "cancelled", notificationResp.Cancelled)
+	return notificationResp.Cancelled, nil
+}
+
 // Ensure logger is initialized and available. If not, a placeholder can be used:
 // var logger = struct {
 //   Infow func(msg string, keysAndValues ...interface{})
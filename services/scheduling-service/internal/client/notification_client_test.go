@@ -0,0 +1,194 @@
+package client_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/slotwise/scheduling-service/internal/client"
+	"github.com/slotwise/scheduling-service/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeNotifications(n int) []client.SendNotificationRequest {
+	reqs := make([]client.SendNotificationRequest, n)
+	for i := range reqs {
+		reqs[i] = client.SendNotificationRequest{
+			Type:           "booking_cancellation_customer",
+			RecipientEmail: fmt.Sprintf("customer%d@example.com", i),
+			TemplateData:   map[string]interface{}{"bookingId": fmt.Sprintf("booking-%d", i)},
+		}
+	}
+	return reqs
+}
+
+func TestSendBatch_UsesBatchEndpointAndReportsPartialFailures(t *testing.T) {
+	var mu sync.Mutex
+	batchCalls, singleCalls := 0, 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch r.URL.Path {
+		case "/api/v1/notifications/send-batch":
+			batchCalls++
+			var body struct {
+				Notifications []client.SendNotificationRequest `json:"notifications"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.LessOrEqual(t, len(body.Notifications), client.MaxNotificationBatchSize)
+
+			results := make([]client.BatchSendResult, len(body.Notifications))
+			for i, n := range body.Notifications {
+				results[i] = client.BatchSendResult{Index: i, Success: true}
+				if n.RecipientEmail == "customer7@example.com" {
+					errMsg := "mailbox unavailable"
+					results[i] = client.BatchSendResult{Index: i, Error: &errMsg}
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "results": results})
+		default:
+			singleCalls++
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	c := client.NewNotificationServiceClient(&config.Config{NotificationServiceURL: server.URL})
+	results, err := c.SendBatch(makeNotifications(120))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 of 120 notifications failed")
+	assert.Equal(t, 3, batchCalls, "120 notifications should be sent in 3 batch requests")
+	assert.Equal(t, 0, singleCalls)
+
+	require.Len(t, results, 120)
+	for i, result := range results {
+		assert.Equal(t, i, result.Index)
+		if i == 7 {
+			assert.False(t, result.Success)
+			require.NotNil(t, result.Error)
+			assert.Equal(t, "mailbox unavailable", *result.Error)
+		} else {
+			assert.True(t, result.Success, "notification %d should succeed", i)
+		}
+	}
+}
+
+func TestSendBatch_FallsBackToIndividualSendsOn404(t *testing.T) {
+	var mu sync.Mutex
+	batchCalls, singleCalls := 0, 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch r.URL.Path {
+		case "/api/v1/notifications/send-batch":
+			batchCalls++
+			http.NotFound(w, r)
+		case "/api/v1/notifications/send":
+			singleCalls++
+			var req client.SendNotificationRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			if req.RecipientEmail == "customer1@example.com" {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]interface{}{"success": false, "error": "invalid recipient"})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "messageId": "msg-" + req.RecipientEmail})
+		}
+	}))
+	defer server.Close()
+
+	c := client.NewNotificationServiceClient(&config.Config{NotificationServiceURL: server.URL})
+	results, err := c.SendBatch(makeNotifications(3))
+
+	require.Error(t, err)
+	assert.Equal(t, 1, batchCalls, "batch endpoint should not be retried after a 404")
+	assert.Equal(t, 3, singleCalls)
+
+	require.Len(t, results, 3)
+	assert.True(t, results[0].Success)
+	require.NotNil(t, results[0].MessageID)
+	assert.Equal(t, "msg-customer0@example.com", *results[0].MessageID)
+	assert.False(t, results[1].Success)
+	require.NotNil(t, results[1].Error)
+	assert.Contains(t, *results[1].Error, "invalid recipient")
+	assert.True(t, results[2].Success)
+}
+
+func TestScheduleNotification_RetryReturnsExistingReminder(t *testing.T) {
+	var mu sync.Mutex
+	scheduled := map[string]string{} // idempotency key -> scheduled notification id
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		var body client.ScheduleNotificationRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		key := r.Header.Get(client.IdempotencyKeyHeader)
+		assert.Equal(t, body.IdempotencyKey, key)
+
+		if id, ok := scheduled[key]; ok {
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "scheduledNotificationId": id, "duplicate": true})
+			return
+		}
+		id := fmt.Sprintf("sch-%d", len(scheduled)+1)
+		scheduled[key] = id
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "scheduledNotificationId": id})
+	}))
+	defer server.Close()
+
+	c := client.NewNotificationServiceClient(&config.Config{NotificationServiceURL: server.URL})
+	req := client.ScheduleNotificationRequest{
+		Type:           "booking_reminder",
+		RecipientEmail: "customer@example.com",
+		TemplateData:   map[string]interface{}{"bookingId": "booking-1"},
+		ScheduledFor:   time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC),
+		BookingID:      "booking-1",
+	}
+
+	first, err := c.ScheduleNotification(req)
+	require.NoError(t, err)
+	require.NotNil(t, first.ScheduledNotificationID)
+	assert.False(t, first.Duplicate)
+
+	// A retry after a timeout sends the same request again
+	retry, err := c.ScheduleNotification(req)
+	require.NoError(t, err)
+	require.NotNil(t, retry.ScheduledNotificationID)
+	assert.True(t, retry.Duplicate)
+	assert.Equal(t, *first.ScheduledNotificationID, *retry.ScheduledNotificationID)
+	assert.Len(t, scheduled, 1, "Only one reminder should be scheduled")
+
+	// A rescheduled booking needs a new reminder
+	req.ScheduledFor = req.ScheduledFor.Add(time.Hour)
+	moved, err := c.ScheduleNotification(req)
+	require.NoError(t, err)
+	assert.False(t, moved.Duplicate)
+	assert.Len(t, scheduled, 2)
+}
+
+func TestCancelScheduledNotifications_SendsBookingID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		assert.Equal(t, "/api/v1/notifications/schedule", r.URL.Path)
+		assert.Equal(t, "booking 1", r.URL.Query().Get("bookingId"))
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "cancelled": 2})
+	}))
+	defer server.Close()
+
+	c := client.NewNotificationServiceClient(&config.Config{NotificationServiceURL: server.URL})
+	cancelled, err := c.CancelScheduledNotifications("booking 1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, cancelled)
+}
@@ -1,8 +1,11 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // Config holds all configuration for the scheduling service
@@ -13,7 +16,26 @@ type Config struct {
 	Database               DatabaseConfig
 	Redis                  RedisConfig
 	NATS                   NATSConfig
+	JWT                    JWTConfig
 	NotificationServiceURL string
+	SlotHoldTTL            time.Duration // How long a slot hold lasts before it expires
+	PendingPaymentTimeout  time.Duration // How long a booking may await payment before it is cancelled
+	MaxSlotsPerDay         int           // Most slots returned for one service and day; more are truncated
+	DaypartAfternoonStart  string        // "HH:MM" where slots grouped by daypart move from morning to afternoon
+	DaypartEveningStart    string        // "HH:MM" where slots grouped by daypart move from afternoon to evening
+	AllowedOrigins         []string      // Browser origins allowed to open WebSocket connections in production
+	CORS                   CORSConfig
+	PublicRateLimit        RateLimitConfig
+	Realtime               RealtimeConfig
+	// ExchangeRates holds the value of each currency in a common base currency, used to convert
+	// revenue summaries; empty disables conversion.
+	ExchangeRates map[string]float64
+	// TrustedProxies lists the proxy IPs or CIDRs whose X-Forwarded-For header is believed when
+	// working out a client's IP; empty trusts none, so the connection's address is used.
+	TrustedProxies []string
+	// ServiceAuthTokens are the shared secrets other services send in the X-Service-Token header to call
+	// the internal API. Several may be set to rotate them; with none the internal API rejects every call.
+	ServiceAuthTokens []string
 }
 
 // DatabaseConfig holds database configuration
@@ -31,6 +53,36 @@ type NATSConfig struct {
 	URL string
 }
 
+// JWTConfig holds configuration for validating tokens issued by the auth service
+type JWTConfig struct {
+	Secret   string
+	Issuer   string // Required iss claim; must match the auth service's JWT_ISSUER
+	Audience string // Required aud claim; must match the auth service's JWT_AUDIENCE
+}
+
+// CORSConfig holds the cross-origin policy for browser clients of the HTTP API
+type CORSConfig struct {
+	AllowedOrigins   []string // "*" allows any origin, but never with credentials
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	AllowCredentials bool          // Lets listed origins send cookies and Authorization headers
+	MaxAge           time.Duration // How long browsers may cache a preflight response
+}
+
+// RateLimitConfig holds per-IP limits for the public availability endpoints
+type RateLimitConfig struct {
+	Requests int           // Requests allowed per client IP in each window
+	Window   time.Duration // Length of the counting window
+}
+
+// RealtimeConfig holds limits for the NATS events forwarded to WebSocket clients
+type RealtimeConfig struct {
+	MaxEventBytes     int    // Larger events are rejected as malformed
+	DeadLetterSubject string // NATS subject rejected events are published to; empty disables it
+	// AllowPrivateCallbacks lets callback URLs target private and loopback addresses, for local development
+	AllowPrivateCallbacks bool
+}
+
 // Load loads configuration from environment variables
 func Load() (*Config, error) {
 	port, err := strconv.Atoi(getEnv("PORT", "8080"))
@@ -38,6 +90,56 @@ func Load() (*Config, error) {
 		port = 8080
 	}
 
+	slotHoldTTL, err := time.ParseDuration(getEnv("SLOT_HOLD_TTL", "10m"))
+	if err != nil {
+		slotHoldTTL = 10 * time.Minute
+	}
+
+	pendingPaymentTimeout, err := time.ParseDuration(getEnv("PENDING_PAYMENT_TIMEOUT", "30m"))
+	if err != nil {
+		pendingPaymentTimeout = 30 * time.Minute
+	}
+
+	maxSlotsPerDay, err := strconv.Atoi(getEnv("MAX_SLOTS_PER_DAY", "500"))
+	if err != nil || maxSlotsPerDay <= 0 {
+		maxSlotsPerDay = 500
+	}
+
+	publicRateLimitRequests, err := strconv.Atoi(getEnv("PUBLIC_RATE_LIMIT_REQUESTS", "300"))
+	if err != nil || publicRateLimitRequests <= 0 {
+		publicRateLimitRequests = 300
+	}
+
+	publicRateLimitWindow, err := time.ParseDuration(getEnv("PUBLIC_RATE_LIMIT_WINDOW", "1m"))
+	if err != nil || publicRateLimitWindow <= 0 {
+		publicRateLimitWindow = time.Minute
+	}
+
+	realtimeMaxEventBytes, err := strconv.Atoi(getEnv("REALTIME_MAX_EVENT_BYTES", "65536"))
+	if err != nil || realtimeMaxEventBytes <= 0 {
+		realtimeMaxEventBytes = 65536
+	}
+
+	corsAllowCredentials, err := strconv.ParseBool(getEnv("CORS_ALLOW_CREDENTIALS", "false"))
+	if err != nil {
+		corsAllowCredentials = false
+	}
+
+	realtimeAllowPrivateCallbacks, err := strconv.ParseBool(getEnv("REALTIME_ALLOW_PRIVATE_CALLBACKS", "false"))
+	if err != nil {
+		realtimeAllowPrivateCallbacks = false
+	}
+
+	corsMaxAge, err := time.ParseDuration(getEnv("CORS_MAX_AGE", "1h"))
+	if err != nil || corsMaxAge < 0 {
+		corsMaxAge = time.Hour
+	}
+
+	exchangeRates, err := parseExchangeRates(getEnv("EXCHANGE_RATES", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid EXCHANGE_RATES: %w", err)
+	}
+
 	return &Config{
 		Environment: getEnv("ENVIRONMENT", "development"),
 		Port:        port,
@@ -51,10 +153,70 @@ func Load() (*Config, error) {
 		NATS: NATSConfig{
 			URL: getEnv("NATS_URL", "nats://localhost:4222"),
 		},
+		JWT: JWTConfig{
+			Secret:   getEnv("JWT_SECRET", "your-super-secret-jwt-key-change-in-production"), // Must match the auth service
+			Issuer:   getEnv("JWT_ISSUER", "slotwise-auth-service"),
+			Audience: getEnv("JWT_AUDIENCE", "slotwise-api"),
+		},
 		NotificationServiceURL: getEnv("NOTIFICATION_SERVICE_URL", "http://localhost:8004"), // Default for local dev
+		SlotHoldTTL:            slotHoldTTL,
+		PendingPaymentTimeout:  pendingPaymentTimeout,
+		MaxSlotsPerDay:         maxSlotsPerDay,
+		DaypartAfternoonStart:  getEnv("DAYPART_AFTERNOON_START", "12:00"),
+		DaypartEveningStart:    getEnv("DAYPART_EVENING_START", "17:00"),
+		AllowedOrigins:         splitList(getEnv("ALLOWED_ORIGINS", "")),
+		CORS: CORSConfig{
+			AllowedOrigins:   splitList(getEnv("CORS_ORIGINS", "*")),
+			AllowedMethods:   splitList(getEnv("CORS_ALLOWED_METHODS", "GET,POST,PUT,PATCH,DELETE,OPTIONS")),
+			AllowedHeaders:   splitList(getEnv("CORS_ALLOWED_HEADERS", "Origin,Content-Type,Content-Length,Accept-Encoding,X-CSRF-Token,Authorization")),
+			AllowCredentials: corsAllowCredentials,
+			MaxAge:           corsMaxAge,
+		},
+		PublicRateLimit: RateLimitConfig{
+			Requests: publicRateLimitRequests,
+			Window:   publicRateLimitWindow,
+		},
+		Realtime: RealtimeConfig{
+			MaxEventBytes:         realtimeMaxEventBytes,
+			DeadLetterSubject:     getEnv("REALTIME_DLQ_SUBJECT", ""),
+			AllowPrivateCallbacks: realtimeAllowPrivateCallbacks,
+		},
+		ExchangeRates:     exchangeRates,
+		TrustedProxies:    splitList(getEnv("TRUSTED_PROXIES", "")),
+		ServiceAuthTokens: splitList(getEnv("SERVICE_AUTH_TOKENS", "")),
 	}, nil
 }
 
+// parseExchangeRates parses a comma-separated list of CURRENCY=value pairs, e.g. "USD=1,EUR=1.08".
+// Unlike other settings a malformed rate is an error rather than falling back, since it would misstate revenue.
+func parseExchangeRates(value string) (map[string]float64, error) {
+	rates := make(map[string]float64)
+	for _, item := range splitList(value) {
+		currency, rateStr, ok := strings.Cut(item, "=")
+		currency = strings.ToUpper(strings.TrimSpace(currency))
+		if !ok || currency == "" {
+			return nil, fmt.Errorf("expected CURRENCY=value, got %q", item)
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(rateStr), 64)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("rate for %s must be a positive number, got %q", currency, rateStr)
+		}
+		rates[currency] = rate
+	}
+	return rates, nil
+}
+
+// splitList splits a comma-separated value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // getEnv gets an environment variable with a fallback value
 func getEnv(key, fallback string) string {
 	if value := os.Getenv(key); value != "" {
@@ -26,11 +26,23 @@ func Migrate(db *gorm.DB) error {
 		return fmt.Errorf("failed to create uuid extension: %w", err)
 	}
 
+	// Duplicate rules would block the unique index on availability rules, so keep only the oldest of each
+	if err := removeDuplicateAvailabilityRules(db); err != nil {
+		return fmt.Errorf("failed to remove duplicate availability rules: %w", err)
+	}
+
 	// Auto-migrate models in proper order
 	err := db.AutoMigrate(
+		&models.Business{},
 		&models.ServiceDefinition{},
 		&models.AvailabilityRule{},
+		&models.AvailabilityVersion{},
 		&models.Booking{},
+		&models.OutboxEvent{},
+		&models.BookingStatusHistory{},
+		&models.CustomerPreference{},
+		&models.DailyDigest{},
+		&models.RealtimeCallback{},
 	)
 	if err != nil {
 		return fmt.Errorf("failed to run auto-migrations: %w", err)
@@ -44,6 +56,18 @@ func Migrate(db *gorm.DB) error {
 	return nil
 }
 
+// removeDuplicateAvailabilityRules soft-deletes all but the oldest live rule for each business, day and hours.
+func removeDuplicateAvailabilityRules(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&models.AvailabilityRule{}) {
+		return nil
+	}
+	return db.Exec(`UPDATE availability_rules SET deleted_at = NOW()
+		WHERE deleted_at IS NULL AND id NOT IN (
+			SELECT MIN(id) FROM availability_rules WHERE deleted_at IS NULL
+			GROUP BY business_id, day_of_week, start_time, end_time
+		)`).Error
+}
+
 // createIndexes creates additional indexes for performance
 func createIndexes(db *gorm.DB) error {
 	// Booking indexes for common query patterns
@@ -58,6 +82,9 @@ func createIndexes(db *gorm.DB) error {
 
 		// AvailabilityRule indexes
 		"CREATE INDEX IF NOT EXISTS idx_availability_rules_business_day ON availability_rules(business_id, day_of_week)",
+
+		// OutboxEvent indexes for the relay's pending scan
+		"CREATE INDEX IF NOT EXISTS idx_outbox_events_status_created ON outbox_events(status, created_at)",
 	}
 
 	for _, indexSQL := range indexes {
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/slotwise/scheduling-service/internal/realtime"
+	"github.com/slotwise/scheduling-service/pkg/logger"
+)
+
+// AdminHandler handles operational endpoints restricted to admins.
+type AdminHandler struct {
+	wsManager *realtime.SubscriptionManager
+	logger    *logger.Logger
+}
+
+// NewAdminHandler creates a new AdminHandler.
+// wsManager may be nil when realtime updates are disabled (no NATS connection).
+func NewAdminHandler(wsManager *realtime.SubscriptionManager, logger *logger.Logger) *AdminHandler {
+	return &AdminHandler{
+		wsManager: wsManager,
+		logger:    logger,
+	}
+}
+
+// ListWebSocketClients handles GET /api/v1/admin/ws/clients
+func (h *AdminHandler) ListWebSocketClients(c *gin.Context) {
+	if h.wsManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Realtime updates are not enabled"})
+		return
+	}
+
+	clients := h.wsManager.ListClients()
+	c.JSON(http.StatusOK, gin.H{"data": clients, "total": len(clients), "droppedMessages": h.wsManager.DroppedMessages(), "malformedEvents": h.wsManager.MalformedEvents()})
+}
+
+// DisconnectWebSocketClient handles DELETE /api/v1/admin/ws/clients/:clientId
+func (h *AdminHandler) DisconnectWebSocketClient(c *gin.Context) {
+	if h.wsManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Realtime updates are not enabled"})
+		return
+	}
+
+	clientID := c.Param("clientId")
+	if !h.wsManager.DisconnectClient(clientID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "WebSocket client not found"})
+		return
+	}
+
+	h.logger.InfoContext(c.Request.Context(), "WebSocket client disconnected by admin", "clientId", clientID, "adminId", c.GetString("user_id"))
+	c.Status(http.StatusNoContent)
+}
+
+// RegisterCallbackRequest is the body of PUT /api/v1/admin/ws/callbacks/:businessId.
+type RegisterCallbackRequest struct {
+	URL string `json:"url" binding:"required"`
+}
+
+// ListCallbacks handles GET /api/v1/admin/ws/callbacks
+func (h *AdminHandler) ListCallbacks(c *gin.Context) {
+	if h.wsManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Realtime updates are not enabled"})
+		return
+	}
+
+	callbacks := h.wsManager.ListCallbacks()
+	c.JSON(http.StatusOK, gin.H{"data": callbacks, "total": len(callbacks), "failedDeliveries": h.wsManager.FailedCallbacks()})
+}
+
+// RegisterCallback handles PUT /api/v1/admin/ws/callbacks/:businessId
+// The business's realtime messages are POSTed to the URL as well as sent to its WebSocket clients.
+func (h *AdminHandler) RegisterCallback(c *gin.Context) {
+	if h.wsManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Realtime updates are not enabled"})
+		return
+	}
+
+	var req RegisterCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload: " + err.Error()})
+		return
+	}
+
+	businessID := c.Param("businessId")
+	if err := h.wsManager.RegisterCallback(c.Request.Context(), businessID, req.URL); err != nil {
+		if errors.Is(err, realtime.ErrInvalidCallbackURL) || errors.Is(err, realtime.ErrForbiddenCallbackTarget) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.ErrorContext(c.Request.Context(), "Failed to register realtime callback", "businessId", businessID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register callback"})
+		return
+	}
+
+	h.logger.InfoContext(c.Request.Context(), "Realtime callback registered by admin", "businessId", businessID, "adminId", c.GetString("user_id"))
+	c.JSON(http.StatusOK, realtime.CallbackInfo{BusinessID: businessID, URL: req.URL})
+}
+
+// UnregisterCallback handles DELETE /api/v1/admin/ws/callbacks/:businessId
+func (h *AdminHandler) UnregisterCallback(c *gin.Context) {
+	if h.wsManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Realtime updates are not enabled"})
+		return
+	}
+
+	businessID := c.Param("businessId")
+	removed, err := h.wsManager.UnregisterCallback(c.Request.Context(), businessID)
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to unregister realtime callback", "businessId", businessID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unregister callback"})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Callback not found"})
+		return
+	}
+
+	h.logger.InfoContext(c.Request.Context(), "Realtime callback unregistered by admin", "businessId", businessID, "adminId", c.GetString("user_id"))
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,243 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/websocket"
+	"github.com/slotwise/scheduling-service/internal/handlers"
+	"github.com/slotwise/scheduling-service/internal/middleware"
+	"github.com/slotwise/scheduling-service/internal/realtime"
+	"github.com/slotwise/scheduling-service/pkg/events"
+	"github.com/slotwise/scheduling-service/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+const adminTestJWTSecret = "admin-handler-test-secret"
+
+var adminTestTokens = &middleware.TokenValidator{Secret: adminTestJWTSecret, Issuer: testTokenIssuer, Audience: testTokenAudience}
+
+type AdminHandlerTestSuite struct {
+	suite.Suite
+	Server     *httptest.Server
+	Manager    *realtime.SubscriptionManager
+	TestLogger *logger.Logger
+}
+
+func (suite *AdminHandlerTestSuite) SetupTest() {
+	suite.TestLogger = logger.New("debug")
+	suite.Manager = realtime.NewSubscriptionManager(suite.TestLogger, nil)
+	go suite.Manager.Run()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	wsHandler := handlers.NewWebSocketHandler(suite.Manager, handlers.OriginPolicy{}, adminTestTokens, suite.TestLogger)
+	adminHandler := handlers.NewAdminHandler(suite.Manager, suite.TestLogger)
+
+	router.GET("/ws/availability", wsHandler.HandleConnections)
+	admin := router.Group("/api/v1/admin")
+	admin.Use(middleware.RequireAuth(adminTestTokens), middleware.RequireAdmin())
+	{
+		admin.GET("/ws/clients", adminHandler.ListWebSocketClients)
+		admin.DELETE("/ws/clients/:clientId", adminHandler.DisconnectWebSocketClient)
+		admin.GET("/ws/callbacks", adminHandler.ListCallbacks)
+		admin.PUT("/ws/callbacks/:businessId", adminHandler.RegisterCallback)
+		admin.DELETE("/ws/callbacks/:businessId", adminHandler.UnregisterCallback)
+	}
+
+	suite.Server = httptest.NewServer(router)
+}
+
+func (suite *AdminHandlerTestSuite) TearDownTest() {
+	suite.Server.Close()
+}
+
+func (suite *AdminHandlerTestSuite) signToken(role string) string {
+	claims := middleware.Claims{
+		Role:      role,
+		TokenType: "access",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "user-" + role,
+			Issuer:    testTokenIssuer,
+			Audience:  jwt.ClaimStrings{testTokenAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(adminTestJWTSecret))
+	assert.NoError(suite.T(), err)
+	return token
+}
+
+func (suite *AdminHandlerTestSuite) doRequest(method, path, token string) *http.Response {
+	return suite.doJSONRequest(method, path, token, "")
+}
+
+func (suite *AdminHandlerTestSuite) doJSONRequest(method, path, token, body string) *http.Response {
+	req, err := http.NewRequest(method, suite.Server.URL+path, strings.NewReader(body))
+	assert.NoError(suite.T(), err)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(suite.T(), err)
+	return resp
+}
+
+// connectSubscribedClient opens a WebSocket connection subscribed to businessID and
+// waits until the manager has registered it.
+func (suite *AdminHandlerTestSuite) connectSubscribedClient(businessID string) (*websocket.Conn, realtime.ClientInfo) {
+	t := suite.T()
+	wsURL := "ws" + strings.TrimPrefix(suite.Server.URL, "http") + "/ws/availability"
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	assert.NoError(t, err)
+
+	err = conn.WriteJSON(handlers.SubscriptionMessage{Type: "subscribe", BusinessID: businessID})
+	assert.NoError(t, err)
+
+	var info realtime.ClientInfo
+	assert.Eventually(t, func() bool {
+		for _, c := range suite.Manager.ListClients() {
+			if c.BusinessID == businessID {
+				info = c
+				return true
+			}
+		}
+		return false
+	}, 2*time.Second, 10*time.Millisecond)
+	return conn, info
+}
+
+func (suite *AdminHandlerTestSuite) TestListWebSocketClients() {
+	t := suite.T()
+	conn, info := suite.connectSubscribedClient("biz_ws_admin")
+	defer conn.Close()
+
+	resp := suite.doRequest(http.MethodGet, "/api/v1/admin/ws/clients", suite.signToken(middleware.RoleAdmin))
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Data  []realtime.ClientInfo `json:"data"`
+		Total int                   `json:"total"`
+	}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, 1, body.Total)
+	assert.Len(t, body.Data, 1)
+	assert.Equal(t, info.ID, body.Data[0].ID)
+	assert.Equal(t, "biz_ws_admin", body.Data[0].BusinessID)
+	assert.False(t, body.Data[0].ConnectedAt.IsZero())
+}
+
+func (suite *AdminHandlerTestSuite) TestDisconnectWebSocketClient() {
+	t := suite.T()
+	conn, info := suite.connectSubscribedClient("biz_ws_kick")
+	defer conn.Close()
+
+	resp := suite.doRequest(http.MethodDelete, "/api/v1/admin/ws/clients/"+info.ID, suite.signToken(middleware.RoleAdmin))
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	// The server sends a close frame, so the next read fails
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, _, err := conn.ReadMessage()
+	assert.Error(t, err)
+	assert.False(t, strings.Contains(err.Error(), "timeout"), "expected close, got %v", err)
+
+	assert.Eventually(t, func() bool {
+		return len(suite.Manager.ListClients()) == 0
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// Disconnecting again reports not found
+	resp = suite.doRequest(http.MethodDelete, "/api/v1/admin/ws/clients/"+info.ID, suite.signToken(middleware.RoleAdmin))
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func (suite *AdminHandlerTestSuite) TestRegisteredCallbackReceivesWebSocketMessages() {
+	t := suite.T()
+	received := make(chan []byte, 1)
+	callbackServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		received <- body
+	}))
+	defer callbackServer.Close()
+	// The test server listens on loopback, which callbacks may only target when allowed
+	suite.Manager.AllowPrivateCallbacks = true
+
+	resp := suite.doJSONRequest(http.MethodPut, "/api/v1/admin/ws/callbacks/biz_ws_hook", suite.signToken(middleware.RoleAdmin), `{"url":"`+callbackServer.URL+`"}`)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	conn, _ := suite.connectSubscribedClient("biz_ws_hook")
+	defer conn.Close()
+
+	err := suite.Manager.HandleEvent(events.BookingConfirmedEvent, []byte(`{"businessId":"biz_ws_hook","bookingId":"bkg_hook","status":"CONFIRMED"}`))
+	assert.NoError(t, err)
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, wsMessage, err := conn.ReadMessage()
+	assert.NoError(t, err)
+	select {
+	case callbackBody := <-received:
+		assert.JSONEq(t, string(wsMessage), string(callbackBody))
+		assert.Contains(t, string(callbackBody), `"type":"booking_created"`)
+	case <-time.After(2 * time.Second):
+		t.Fatal("callback was not called")
+	}
+
+	// Once unregistered, the callback is no longer listed
+	resp = suite.doRequest(http.MethodDelete, "/api/v1/admin/ws/callbacks/biz_ws_hook", suite.signToken(middleware.RoleAdmin))
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Empty(t, suite.Manager.ListCallbacks())
+}
+
+func (suite *AdminHandlerTestSuite) TestRegisterCallback_RejectsInvalidURL() {
+	t := suite.T()
+
+	for _, url := range []string{"not a url", "ftp://example.com/hook", "/relative/hook"} {
+		resp := suite.doJSONRequest(http.MethodPut, "/api/v1/admin/ws/callbacks/biz_ws_hook", suite.signToken(middleware.RoleAdmin), `{"url":"`+url+`"}`)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, url)
+	}
+	assert.Empty(t, suite.Manager.ListCallbacks())
+}
+
+func (suite *AdminHandlerTestSuite) TestRegisterCallback_RejectsInternalTargets() {
+	t := suite.T()
+
+	for _, url := range []string{"http://127.0.0.1:8080/hook", "http://169.254.169.254/latest/meta-data/", "http://10.0.0.5/hook"} {
+		resp := suite.doJSONRequest(http.MethodPut, "/api/v1/admin/ws/callbacks/biz_ws_hook", suite.signToken(middleware.RoleAdmin), `{"url":"`+url+`"}`)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, url)
+	}
+	assert.Empty(t, suite.Manager.ListCallbacks())
+}
+
+func (suite *AdminHandlerTestSuite) TestAdminRoutes_RequireAdmin() {
+	t := suite.T()
+
+	resp := suite.doRequest(http.MethodGet, "/api/v1/admin/ws/clients", "")
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp = suite.doRequest(http.MethodGet, "/api/v1/admin/ws/clients", suite.signToken("client"))
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestAdminHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(AdminHandlerTestSuite))
+}
@@ -1,6 +1,8 @@
 package handlers_test
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -9,7 +11,9 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/slotwise/scheduling-service/internal/handlers"
+	"github.com/slotwise/scheduling-service/internal/middleware"
 	"github.com/slotwise/scheduling-service/internal/models"
 	"github.com/slotwise/scheduling-service/internal/repository"
 	"github.com/slotwise/scheduling-service/internal/service"
@@ -20,6 +24,31 @@ import (
 	"gorm.io/gorm"
 )
 
+const availabilityTestJWTSecret = "availability-handler-test-secret"
+
+var availabilityTestTokens = &middleware.TokenValidator{Secret: availabilityTestJWTSecret, Issuer: testTokenIssuer, Audience: testTokenAudience}
+
+// availabilityTestServiceToken is what other services send to call the internal API.
+const availabilityTestServiceToken = "test-service-token"
+
+// signToken returns an access token for userID with role, owning businessID when it is not empty.
+func (suite *AvailabilityHandlerTestSuite) signToken(userID, role, businessID string) string {
+	claims := middleware.Claims{
+		Role:       role,
+		BusinessID: businessID,
+		TokenType:  "access",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userID,
+			Issuer:    testTokenIssuer,
+			Audience:  jwt.ClaimStrings{testTokenAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(availabilityTestJWTSecret))
+	assert.NoError(suite.T(), err)
+	return token
+}
+
 type AvailabilityHandlerTestSuite struct {
 	suite.Suite
 	DB                  *gorm.DB
@@ -39,13 +68,13 @@ func (suite *AvailabilityHandlerTestSuite) SetupSuite() {
 	}
 	suite.DB = db
 
-	err = suite.DB.AutoMigrate(&models.ServiceDefinition{}, &models.AvailabilityRule{}, &models.Booking{}) // Added Booking for bookingRepo
+	err = suite.DB.AutoMigrate(&models.Business{}, &models.ServiceDefinition{}, &models.AvailabilityRule{}, &models.AvailabilityVersion{}, &models.Booking{}) // Added Booking for bookingRepo
 	assert.NoError(suite.T(), err)
 
 	suite.AvailabilityRepo = repository.NewAvailabilityRepository(suite.DB)
 	bookingRepo := repository.NewBookingRepository(suite.DB) // Create BookingRepo
 	// Pass bookingRepo, and nil for CacheRepository and EventPublisher
-	suite.AvailabilityService = service.NewAvailabilityService(suite.AvailabilityRepo, bookingRepo, nil, nil, suite.TestLogger)
+	suite.AvailabilityService = service.NewAvailabilityService(suite.AvailabilityRepo, bookingRepo, nil, nil, 0, 0, nil, nil, suite.TestLogger)
 
 	// Setup router
 	gin.SetMode(gin.TestMode)
@@ -54,6 +83,7 @@ func (suite *AvailabilityHandlerTestSuite) SetupSuite() {
 
 	v1 := router.Group("/api/v1")
 	internal := v1.Group("/internal")
+	internal.Use(middleware.RequireServiceToken([]string{availabilityTestServiceToken}))
 	{
 		internalAvailability := internal.Group("/availability")
 		{
@@ -63,6 +93,13 @@ func (suite *AvailabilityHandlerTestSuite) SetupSuite() {
 	}
 	// Add the public slots route
 	v1.GET("/services/:serviceId/slots", availabilityHandler.GetPublicSlotsForService)
+	v1.GET("/availability/rules", availabilityHandler.ListAvailabilityRules)
+	v1.GET("/availability/rules/:id", availabilityHandler.GetAvailabilityRule)
+	v1.POST("/availability/rules", middleware.RequireAuth(availabilityTestTokens), middleware.RequireBusinessOwner(""), availabilityHandler.CreateAvailabilityRule)
+	v1.PATCH("/availability/rules/:id", middleware.RequireAuth(availabilityTestTokens), middleware.RequireBusinessOwner(""), availabilityHandler.UpdateAvailabilityRule)
+	v1.PUT("/availability/rules/:id/active", middleware.RequireAuth(availabilityTestTokens), middleware.RequireBusinessOwner(""), availabilityHandler.SetAvailabilityRuleActive)
+	v1.DELETE("/availability/rules", middleware.RequireAuth(availabilityTestTokens), middleware.RequireBusinessOwner(""), availabilityHandler.DeleteAvailabilityRulesForDay)
+	v1.PUT("/businesses/:businessId/accepting-bookings", middleware.RequireAuth(availabilityTestTokens), middleware.RequireBusinessOwner("businessId"), availabilityHandler.SetAcceptingBookings)
 	suite.Router = router
 }
 
@@ -74,6 +111,7 @@ func (suite *AvailabilityHandlerTestSuite) TearDownSuite() {
 func (suite *AvailabilityHandlerTestSuite) SetupTest() {
 	suite.DB.Exec("DELETE FROM service_definitions")
 	suite.DB.Exec("DELETE FROM availability_rules")
+	suite.DB.Exec("DELETE FROM availability_versions")
 }
 
 func (suite *AvailabilityHandlerTestSuite) TestGetSlotsForBusinessServiceDate_APISuccess() {
@@ -96,6 +134,7 @@ func (suite *AvailabilityHandlerTestSuite) TestGetSlotsForBusinessServiceDate_AP
 
 	url := fmt.Sprintf("/api/v1/internal/availability/biz_api_test/slots?serviceId=svc_api_test&date=%s", dateStr)
 	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	req.Header.Set(middleware.ServiceTokenHeader, availabilityTestServiceToken)
 	rr := httptest.NewRecorder()
 	suite.Router.ServeHTTP(rr, req)
 
@@ -170,6 +209,7 @@ func (suite *AvailabilityHandlerTestSuite) TestGetSlotsForBusinessServiceDate_AP
 	assert.NoError(t, err)
 
 	assert.NotNil(t, responseBody["slots"])
+	assert.Contains(t, responseBody, "availabilityVersion")
 	slotsData, ok := responseBody["slots"].([]interface{})
 	assert.True(t, ok, "slots should be an array")
 	// Expected: 09:00, 10:00 (09:30 is booked)
@@ -193,6 +233,39 @@ func (suite *AvailabilityHandlerTestSuite) TestGetSlotsForBusinessServiceDate_AP
 
 }
 
+func (suite *AvailabilityHandlerTestSuite) TestGetPublicSlotsForService_GroupedByDaypart() {
+	t := suite.T()
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_api_daypart", BusinessID: "biz_api_daypart", Name: "Daypart Service", DurationMinutes: 30, IsActive: true})
+	suite.DB.Create(&[]models.AvailabilityRule{
+		{BusinessID: "biz_api_daypart", DayOfWeek: models.Monday, StartTime: "09:00", EndTime: "09:30"},
+		{BusinessID: "biz_api_daypart", DayOfWeek: models.Monday, StartTime: "18:00", EndTime: "18:30"},
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/services/svc_api_daypart/slots?date=2024-03-04&businessId=biz_api_daypart&group=daypart", nil)
+	rr := httptest.NewRecorder()
+	suite.Router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var responseBody struct {
+		Slots  []service.APISlot      `json:"slots"`
+		Groups service.SlotsByDaypart `json:"groups"`
+	}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &responseBody))
+	assert.Len(t, responseBody.Slots, 2, "The flat list is still returned")
+	if assert.Len(t, responseBody.Groups.Morning, 1) {
+		assert.Equal(t, 9, responseBody.Groups.Morning[0].StartTime.Hour())
+	}
+	assert.Empty(t, responseBody.Groups.Afternoon)
+	if assert.Len(t, responseBody.Groups.Evening, 1) {
+		assert.Equal(t, 18, responseBody.Groups.Evening[0].StartTime.Hour())
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/services/svc_api_daypart/slots?date=2024-03-04&businessId=biz_api_daypart&group=hour", nil)
+	rr = httptest.NewRecorder()
+	suite.Router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
 func (suite *AvailabilityHandlerTestSuite) TestGetSlotsForBusinessServiceDate_APIServiceNotFound() {
 	t := suite.T()
 	dateStr := "2024-03-04" // Monday
@@ -206,6 +279,7 @@ func (suite *AvailabilityHandlerTestSuite) TestGetSlotsForBusinessServiceDate_AP
 
 	url := fmt.Sprintf("/api/v1/internal/availability/biz_api_nosvc/slots?serviceId=svc_api_nosvc&date=%s", dateStr)
 	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	req.Header.Set(middleware.ServiceTokenHeader, availabilityTestServiceToken)
 	rr := httptest.NewRecorder()
 	suite.Router.ServeHTTP(rr, req)
 
@@ -222,6 +296,7 @@ func (suite *AvailabilityHandlerTestSuite) TestGetSlotsForBusinessServiceDate_AP
 	// Missing date parameter
 	url := "/api/v1/internal/availability/biz_api_badreq/slots?serviceId=svc_api_badreq"
 	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	req.Header.Set(middleware.ServiceTokenHeader, availabilityTestServiceToken)
 	rr := httptest.NewRecorder()
 	suite.Router.ServeHTTP(rr, req)
 	assert.Equal(t, http.StatusBadRequest, rr.Code)
@@ -229,9 +304,230 @@ func (suite *AvailabilityHandlerTestSuite) TestGetSlotsForBusinessServiceDate_AP
 	// Invalid date format
 	url = "/api/v1/internal/availability/biz_api_badreq/slots?serviceId=svc_api_badreq&date=invalid-date"
 	req, _ = http.NewRequest(http.MethodGet, url, nil)
+	req.Header.Set(middleware.ServiceTokenHeader, availabilityTestServiceToken)
 	rr = httptest.NewRecorder()
 	suite.Router.ServeHTTP(rr, req)
 	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	// Without a service token the internal API is refused
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/internal/availability/biz_api_badreq/slots?serviceId=svc_api_badreq&date=2024-03-04", nil)
+	rr = httptest.NewRecorder()
+	suite.Router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+}
+
+func (suite *AvailabilityHandlerTestSuite) TestGetPublicSlotsForService_ConditionalGet() {
+	t := suite.T()
+	suite.DB.Create(&models.ServiceDefinition{
+		ID: "svc_etag", BusinessID: "biz_etag", Name: "ETag Service",
+		DurationMinutes: 30, Price: 1000, Currency: "USD", IsActive: true,
+	})
+	suite.DB.Create(&models.AvailabilityRule{BusinessID: "biz_etag", DayOfWeek: models.Monday, StartTime: "09:00", EndTime: "10:00"})
+
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/services/svc_etag/slots?businessId=biz_etag&date=2024-03-04", nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rr := httptest.NewRecorder()
+		suite.Router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	first := get("")
+	assert.Equal(t, http.StatusOK, first.Code)
+	etag := first.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	unchanged := get(etag)
+	assert.Equal(t, http.StatusNotModified, unchanged.Code)
+	assert.Empty(t, unchanged.Body.String())
+	assert.Equal(t, etag, unchanged.Header().Get("ETag"))
+
+	// Booking a slot bumps the availability version and removes the slot
+	start := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+	_, err := repository.NewBookingRepository(suite.DB).CreateBookingWithOutboxEvents(context.Background(), &models.Booking{
+		BusinessID: "biz_etag", ServiceID: "svc_etag", CustomerID: "cust_etag",
+		StartTime: start, EndTime: start.Add(30 * time.Minute), Status: models.BookingStatusConfirmed,
+	}, func(*models.Booking) []repository.OutboxMessage { return nil })
+	assert.NoError(t, err)
+
+	changed := get(etag)
+	assert.Equal(t, http.StatusOK, changed.Code)
+	assert.NotEqual(t, etag, changed.Header().Get("ETag"))
+	var responseBody struct{ Slots []service.APISlot }
+	assert.NoError(t, json.Unmarshal(changed.Body.Bytes(), &responseBody))
+	assert.Len(t, responseBody.Slots, 1)
+}
+
+func (suite *AvailabilityHandlerTestSuite) TestListAvailabilityRules_OrderedByDay() {
+	t := suite.T()
+	bizID := "biz_api_rules"
+
+	// Seeded out of order, and with an alphabetical order that differs from the calendar one
+	suite.DB.Create(&[]models.AvailabilityRule{
+		{BusinessID: bizID, DayOfWeek: models.Sunday, StartTime: "10:00", EndTime: "14:00"},
+		{BusinessID: bizID, DayOfWeek: models.Monday, StartTime: "13:00", EndTime: "17:00"},
+		{BusinessID: bizID, DayOfWeek: models.Friday, StartTime: "09:00", EndTime: "12:00"},
+		{BusinessID: bizID, DayOfWeek: models.Monday, StartTime: "09:00", EndTime: "12:00"},
+		{BusinessID: "biz_api_rules_other", DayOfWeek: models.Tuesday, StartTime: "09:00", EndTime: "12:00"},
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/availability/rules?businessId="+bizID, nil)
+	rr := httptest.NewRecorder()
+	suite.Router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var responseBody struct {
+		Rules []models.AvailabilityRule `json:"rules"`
+	}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &responseBody))
+
+	var got []string
+	for _, rule := range responseBody.Rules {
+		got = append(got, string(rule.DayOfWeek)+" "+rule.StartTime)
+	}
+	assert.Equal(t, []string{"MONDAY 09:00", "MONDAY 13:00", "FRIDAY 09:00", "SUNDAY 10:00"}, got)
+}
+
+func (suite *AvailabilityHandlerTestSuite) TestListAvailabilityRules_MissingBusinessID() {
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/availability/rules", nil)
+	rr := httptest.NewRecorder()
+	suite.Router.ServeHTTP(rr, req)
+	assert.Equal(suite.T(), http.StatusBadRequest, rr.Code)
+}
+
+func (suite *AvailabilityHandlerTestSuite) TestCreateAvailabilityRule_DuplicateReturnsConflict() {
+	t := suite.T()
+	post := func() *httptest.ResponseRecorder {
+		body := []byte(`{"businessId":"biz_dup_rule","dayOfWeek":"MONDAY","startTime":"09:00","endTime":"17:00"}`)
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/availability/rules", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+suite.signToken("owner_dup_rule", middleware.RoleBusinessOwner, "biz_dup_rule"))
+		rr := httptest.NewRecorder()
+		suite.Router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	assert.Equal(t, http.StatusCreated, post().Code)
+
+	rr := post()
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	var resp map[string]string
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	assert.Equal(t, "An availability rule for this day and time already exists", resp["error"])
+
+	var count int64
+	suite.DB.Model(&models.AvailabilityRule{}).Where("business_id = ?", "biz_dup_rule").Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func (suite *AvailabilityHandlerTestSuite) TestAvailabilityRuleChanges_RequireBusinessOwner() {
+	t := suite.T()
+	send := func(method, url, body, token string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		suite.Router.ServeHTTP(rr, req)
+		return rr
+	}
+	owner := suite.signToken("owner_rules", middleware.RoleBusinessOwner, "biz_owned_rules")
+	otherOwner := suite.signToken("owner_other", middleware.RoleBusinessOwner, "biz_other")
+	customer := suite.signToken("cust_rules", "customer", "")
+	createBody := `{"businessId":"biz_owned_rules","dayOfWeek":"TUESDAY","startTime":"09:00","endTime":"12:00"}`
+
+	assert.Equal(t, http.StatusUnauthorized, send(http.MethodPost, "/api/v1/availability/rules", createBody, "").Code)
+	assert.Equal(t, http.StatusForbidden, send(http.MethodPost, "/api/v1/availability/rules", createBody, customer).Code)
+	assert.Equal(t, http.StatusForbidden, send(http.MethodPost, "/api/v1/availability/rules", createBody, otherOwner).Code)
+
+	rr := send(http.MethodPost, "/api/v1/availability/rules", createBody, owner)
+	if !assert.Equal(t, http.StatusCreated, rr.Code, rr.Body.String()) {
+		return
+	}
+	var created models.AvailabilityRule
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+	assert.Equal(t, "owner_rules", created.UpdatedBy)
+	ruleURL := fmt.Sprintf("/api/v1/availability/rules/%d", created.ID)
+
+	// Rules addressed by ID are checked against the business they belong to
+	assert.Equal(t, http.StatusForbidden, send(http.MethodPatch, ruleURL, `{"endTime":"13:00"}`, otherOwner).Code)
+	assert.Equal(t, http.StatusForbidden, send(http.MethodPut, ruleURL+"/active", `{"active":false}`, otherOwner).Code)
+	assert.Equal(t, http.StatusNotFound, send(http.MethodPatch, "/api/v1/availability/rules/999999", `{"endTime":"13:00"}`, owner).Code)
+
+	admin := suite.signToken("admin_rules", "admin", "")
+	rr = send(http.MethodPatch, ruleURL, `{"endTime":"13:00"}`, admin)
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var stored models.AvailabilityRule
+	suite.DB.First(&stored, created.ID)
+	assert.Equal(t, "13:00", stored.EndTime)
+	assert.Equal(t, "admin_rules", stored.UpdatedBy, "The editor is recorded from the access token")
+
+	assert.Equal(t, http.StatusForbidden, send(http.MethodDelete, "/api/v1/availability/rules?businessId=biz_owned_rules&day=TUESDAY", "", otherOwner).Code)
+	assert.Equal(t, http.StatusOK, send(http.MethodDelete, "/api/v1/availability/rules?businessId=biz_owned_rules&day=TUESDAY", "", owner).Code)
+}
+
+func (suite *AvailabilityHandlerTestSuite) TestGetAvailabilityRule_Found() {
+	t := suite.T()
+	rule := models.AvailabilityRule{BusinessID: "biz_api_rule", DayOfWeek: models.Wednesday, StartTime: "09:00", EndTime: "12:00", BufferMinutes: 10, UpdatedBy: "user_editor"}
+	suite.DB.Create(&rule)
+
+	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/availability/rules/%d", rule.ID), nil)
+	rr := httptest.NewRecorder()
+	suite.Router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var body models.AvailabilityRule
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Equal(t, rule.ID, body.ID)
+	assert.Equal(t, models.Wednesday, body.DayOfWeek)
+	assert.Equal(t, "09:00", body.StartTime)
+	assert.Equal(t, "12:00", body.EndTime)
+	assert.Equal(t, 10, body.BufferMinutes)
+	assert.Equal(t, "user_editor", body.UpdatedBy)
+	assert.False(t, body.UpdatedAt.IsZero())
+	assert.False(t, body.CreatedAt.IsZero())
+}
+
+func (suite *AvailabilityHandlerTestSuite) TestGetAvailabilityRule_NotFound() {
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/availability/rules/999999", nil)
+	rr := httptest.NewRecorder()
+	suite.Router.ServeHTTP(rr, req)
+	assert.Equal(suite.T(), http.StatusNotFound, rr.Code)
+
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/availability/rules/not-a-number", nil)
+	rr = httptest.NewRecorder()
+	suite.Router.ServeHTTP(rr, req)
+	assert.Equal(suite.T(), http.StatusBadRequest, rr.Code)
+}
+
+func (suite *AvailabilityHandlerTestSuite) TestSetAcceptingBookingsAPI() {
+	t := suite.T()
+	suite.DB.Create(&models.Business{ID: "biz_pause_api", Name: "Pause API Shop", Status: "ACTIVE"})
+	send := func(businessID, token string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodPut, "/api/v1/businesses/"+businessID+"/accepting-bookings", bytes.NewBufferString(`{"acceptingBookings": false}`))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		suite.Router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, send("biz_pause_api", "").Code)
+	assert.Equal(t, http.StatusForbidden, send("biz_pause_api", suite.signToken("owner_other", middleware.RoleBusinessOwner, "biz_other")).Code)
+
+	rr := send("biz_pause_api", suite.signToken("owner_pause", middleware.RoleBusinessOwner, "biz_pause_api"))
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var business models.Business
+	suite.DB.First(&business, "id = ?", "biz_pause_api")
+	assert.False(t, business.AcceptingBookings)
+
+	rr = send("biz_pause_unknown", suite.signToken("admin_1", "admin", ""))
+	assert.Equal(t, http.StatusNotFound, rr.Code, rr.Body.String())
 }
 
 func TestAvailabilityHandlerTestSuite(t *testing.T) {
@@ -1,16 +1,21 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/slotwise/scheduling-service/internal/middleware"
 	"github.com/slotwise/scheduling-service/internal/models"
+	"github.com/slotwise/scheduling-service/internal/repository"
 	"github.com/slotwise/scheduling-service/internal/service"
 	"github.com/slotwise/scheduling-service/pkg/logger"
-	// "github.com/slotwise/scheduling-service/internal/middleware" // For CustomerID from context
 )
 
 // BookingHandler handles booking HTTP requests
@@ -26,44 +31,103 @@ func NewBookingHandler(service *service.BookingService, logger *logger.Logger) *
 
 // CreateBookingRequestDTO an DTO for POST /bookings (renamed from CreateBookingRequest to avoid conflict if any)
 type CreateBookingRequestDTO struct {
-	BusinessID string    `json:"businessId" binding:"required"`
-	ServiceID  string    `json:"serviceId" binding:"required"`
-	CustomerID string    `json:"customerId" binding:"required"` // Should ideally come from JWT auth context
-	StartTime  time.Time `json:"startTime" binding:"required"`
+	BusinessID       string                 `json:"businessId" binding:"required"`
+	ServiceID        string                 `json:"serviceId" binding:"required"`
+	CustomerID       string                 `json:"customerId"` // Defaults to the caller; only the business may book for another customer
+	StartTime        time.Time              `json:"startTime" binding:"required"`
+	CustomerLanguage string                 `json:"customerLanguage"` // Optional; localizes notifications when no language is stored for the customer, e.g. for guests. Defaults to "en"
+	Metadata         map[string]interface{} `json:"metadata"`         // Optional custom fields, e.g. {"petName": "Rex"}
+	HoldID           string                 `json:"holdId"`           // Optional, from POST /services/:serviceId/hold
+	Guest            *GuestContactDTO       `json:"guest"`            // Instead of customerId, for someone without an account (e.g. a phone booking); business only
+	DryRun           bool                   `json:"dryRun"`           // Optional, validate without creating the booking, e.g. before collecting payment
+}
+
+// GuestContactDTO holds the contact details of a guest booked without an account
+type GuestContactDTO struct {
+	Name  string `json:"name" binding:"required"`
+	Email string `json:"email" binding:"required,email"` // Confirmation and reminders are sent here
+	Phone string `json:"phone"`
 }
 
 // UpdateBookingStatusRequestDTO is a DTO for PUT /bookings/:bookingId/status
 type UpdateBookingStatusRequestDTO struct {
-	Status models.BookingStatus `json:"status" binding:"required"`
+	Status models.BookingStatus     `json:"status" binding:"required"`
+	Reason *string                  `json:"reason,omitempty"`
+	Scope  models.CancellationScope `json:"scope,omitempty"` // For recurring bookings: OCCURRENCE, FOLLOWING or SERIES
+}
+
+// BulkUpdateBookingStatusRequestDTO is the payload for PUT /api/v1/bookings/status-bulk.
+type BulkUpdateBookingStatusRequestDTO struct {
+	BookingIDs []string             `json:"bookingIds" binding:"required,min=1"`
+	Status     models.BookingStatus `json:"status" binding:"required"`
+	Reason     *string              `json:"reason,omitempty"`
+}
+
+// MaxImportBodyBytes caps the body of a bookings import: about 500 bytes a row at service.MaxBookingImportRows.
+const MaxImportBodyBytes int64 = 512 << 10 // 512 KiB
+
+// ImportBookingsRequestDTO is the payload for POST /api/v1/businesses/:businessId/bookings/import.
+type ImportBookingsRequestDTO struct {
+	Bookings                     []service.ImportBookingRow `json:"bookings" binding:"required,min=1"`
+	SkipHistoricalConflictChecks bool                       `json:"skipHistoricalConflictChecks"` // Import past bookings even if they overlap
 }
 
-// CreateBooking handles POST /api/v1/bookings
+// createBookingTimeout bounds CreateBooking, which may search several days ahead for an alternative slot after a conflict
+const createBookingTimeout = 10 * time.Second
+
+// CreateBooking handles POST /api/v1/bookings for the authenticated customer, or for a guest or another
+// customer when the caller manages the business.
 func (h *BookingHandler) CreateBooking(c *gin.Context) {
 	var req CreateBookingRequestDTO
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Error("Failed to bind CreateBooking request", "error", err)
+		h.logger.ErrorContext(c.Request.Context(), "Failed to bind CreateBooking request", "error", err)
+		if fields := validationErrorDetails(err, &req); fields != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed", "details": fields})
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload: " + err.Error()})
 		return
 	}
 
-	// TODO: In a real app, CustomerID should be extracted from authenticated user context (e.g., JWT claims)
-	// customerID := c.GetString(middleware.ContextKeyCustomerID) // Example if using a middleware
-	// For MVP, we are taking it from request body but this is not secure / ideal.
-	// If req.CustomerID != customerIDFromAuth { return c.Status(http.StatusForbidden) }
+	// Customers book for themselves; only the business can book a guest or another customer, e.g. taking a phone booking
+	userID := c.GetString("user_id")
+	if req.Guest != nil || (req.CustomerID != "" && req.CustomerID != userID) {
+		if !middleware.CanManageBusiness(c, req.BusinessID) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only the business can book for a guest or another customer"})
+			return
+		}
+	} else {
+		req.CustomerID = userID
+	}
 
 	serviceReq := service.CreateBookingRequest{
-		BusinessID: req.BusinessID,
-		ServiceID:  req.ServiceID,
-		CustomerID: req.CustomerID, // Use authenticated customer ID here
-		StartTime:  req.StartTime,
+		BusinessID:       req.BusinessID,
+		ServiceID:        req.ServiceID,
+		CustomerID:       req.CustomerID,
+		StartTime:        req.StartTime,
+		CustomerLanguage: req.CustomerLanguage,
+		Metadata:         req.Metadata,
+		HoldID:           req.HoldID,
+		RequestedBy:      userID,
+		DryRun:           req.DryRun,
 	}
+	if req.Guest != nil {
+		serviceReq.Guest = &service.GuestContact{Name: req.Guest.Name, Email: req.Guest.Email, Phone: req.Guest.Phone}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), createBookingTimeout)
+	defer cancel()
 
-	booking, err := h.service.CreateBooking(c.Request.Context(), serviceReq)
+	booking, err := h.service.CreateBooking(ctx, serviceReq)
 	if err != nil {
-		h.logger.Error("Failed to create booking", "error", err, "request", serviceReq)
-		if strings.Contains(err.Error(), "not available due to a conflict") {
-			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
-		} else if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "does not belong") || strings.Contains(err.Error(), "not active") {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to create booking", "error", err, "request", serviceReq)
+		if errors.Is(err, context.DeadlineExceeded) {
+			c.JSON(http.StatusGatewayTimeout, gin.H{"error": "Timed out creating booking, please try again"})
+		} else if respondSlotUnavailable(c, err) {
+			return
+		} else if strings.Contains(err.Error(), "invalid metadata") || strings.Contains(err.Error(), "invalid booking request") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else if isNotFound(err) {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		} else {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create booking: " + err.Error()})
@@ -71,24 +135,69 @@ func (h *BookingHandler) CreateBooking(c *gin.Context) {
 		return
 	}
 
-	h.logger.Info("Booking created successfully via API", "bookingId", booking.ID)
+	if req.DryRun {
+		// Nothing was created; the body shows the booking as it would be, without an ID
+		c.JSON(http.StatusOK, booking)
+		return
+	}
+
+	h.logger.InfoContext(c.Request.Context(), "Booking created successfully via API", "bookingId", booking.ID)
 	c.JSON(http.StatusCreated, booking)
 }
 
+// respondSlotUnavailable writes the response for a *service.BookingConflictError or *service.SlotUnavailableError
+// and reports whether err was one of them.
+func respondSlotUnavailable(c *gin.Context, err error) bool {
+	var conflictErr *service.BookingConflictError
+	var unavailableErr *service.SlotUnavailableError
+	if errors.As(err, &conflictErr) {
+		// Give the client enough to offer an alternative without another round trip
+		body := gin.H{
+			"error": err.Error(),
+			"code":  conflictErr.Reason(),
+			"conflict": gin.H{
+				"startTime": conflictErr.ConflictStart,
+				"endTime":   conflictErr.ConflictEnd,
+			},
+		}
+		if conflictErr.SuggestedStartTime != nil {
+			body["suggestedStartTime"] = conflictErr.SuggestedStartTime
+		}
+		c.JSON(http.StatusConflict, body)
+		return true
+	}
+	if errors.As(err, &unavailableErr) {
+		c.JSON(slotUnavailableStatus(unavailableErr.Reason), gin.H{"error": err.Error(), "code": unavailableErr.Reason})
+		return true
+	}
+	return false
+}
+
+// slotUnavailableStatus maps why a slot could not be booked to a status code: 409 when the slot is taken
+// or the business paused, 422 when the requested time itself is not bookable.
+func slotUnavailableStatus(reason service.SlotUnavailableReason) int {
+	switch reason {
+	case service.SlotUnavailableOutsideHours, service.SlotUnavailableLeadTime, service.SlotUnavailablePast:
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusConflict
+	}
+}
+
 // GetBookingByID handles GET /api/v1/bookings/:bookingId
 func (h *BookingHandler) GetBookingByID(c *gin.Context) {
 	bookingID := c.Param("bookingId")
 	// TODO: Add authorization check: ensure the requester is the customer or business owner.
 
-	h.logger.Info("Getting booking by ID via API", "bookingId", bookingID)
+	h.logger.InfoContext(c.Request.Context(), "Getting booking by ID via API", "bookingId", bookingID)
 	booking, err := h.service.GetBookingDetails(c.Request.Context(), bookingID)
-	if err != nil {
-		h.logger.Error("Failed to get booking by ID", "bookingId", bookingID, "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve booking: " + err.Error()})
+	if errors.Is(err, repository.ErrBookingNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
 		return
 	}
-	if booking == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to get booking by ID", "bookingId", bookingID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve booking: " + err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, booking)
@@ -98,8 +207,15 @@ func (h *BookingHandler) GetBookingByID(c *gin.Context) {
 func (h *BookingHandler) ListBookings(c *gin.Context) {
 	customerID := c.Query("customerId")
 	businessID := c.Query("businessId")
-	// TODO: Authorization: If customerId is present, ensure it matches authenticated user.
-	// If businessId is present, ensure authenticated user is owner of that business.
+	// Customers list their own bookings; a business's bookings, which q searches by customer, are for its owners
+	if customerID != "" && customerID != c.GetString("user_id") && c.GetString("user_role") != middleware.RoleAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only list your own bookings"})
+		return
+	}
+	if customerID == "" && businessID != "" && !middleware.CanManageBusiness(c, businessID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only list your own business's bookings"})
+		return
+	}
 
 	pageStr := c.DefaultQuery("page", "1")
 	limitStr := c.DefaultQuery("limit", "10")
@@ -115,20 +231,34 @@ func (h *BookingHandler) ListBookings(c *gin.Context) {
 	var err error
 
 	if customerID != "" {
-		h.logger.Info("Listing bookings for customer via API", "customerId", customerID)
-		bookings, total, err = h.service.ListBookingsForCustomer(c.Request.Context(), customerID, limit, offset)
+		h.logger.InfoContext(c.Request.Context(), "Listing bookings for customer via API", "customerId", customerID)
+		// when=upcoming or when=past splits the customer's bookings around the current time
+		timeframe := models.BookingTimeframe(c.Query("when"))
+		if !timeframe.IsValid() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid when: must be upcoming or past"})
+			return
+		}
+		bookings, total, err = h.service.ListBookingsForCustomer(c.Request.Context(), customerID, timeframe, limit, offset)
 
 	} else if businessID != "" {
-		h.logger.Info("Listing bookings for business via API", "businessId", businessID)
-		bookings, total, err = h.service.ListBookingsForBusiness(c.Request.Context(), businessID, limit, offset)
+		h.logger.InfoContext(c.Request.Context(), "Listing bookings for business via API", "businessId", businessID)
+		// status narrows the listing, e.g. status=COMPLETED for finished appointments. It may be
+		// repeated or comma-separated to match any of several statuses: status=CONFIRMED,PENDING_PAYMENT
+		statuses, statusErr := parseBookingStatuses(c.QueryArray("status"))
+		if statusErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": statusErr.Error()})
+			return
+		}
+		// q finds bookings by a fragment of the customer's name or email, or of the service name
+		bookings, total, err = h.service.ListBookingsForBusiness(c.Request.Context(), businessID, statuses, c.Query("q"), limit, offset)
 	} else {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Either customerId or businessId query parameter is required"})
 		return
 	}
 
 	if err != nil {
-		h.logger.Error("Failed to list bookings", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve bookings: " + err.Error()})
+		h.logger.ErrorContext(c.Request.Context(), "Failed to list bookings", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve bookings"})
 		return
 	}
 	
@@ -143,24 +273,154 @@ func (h *BookingHandler) ListBookings(c *gin.Context) {
 	})
 }
 
-// UpdateBookingStatus handles PUT /api/v1/bookings/:bookingId/status
+// parseBookingStatuses collects the statuses from repeated and comma-separated status params,
+// rejecting any that isn't a known booking status.
+func parseBookingStatuses(values []string) ([]models.BookingStatus, error) {
+	var statuses []models.BookingStatus
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			status := models.BookingStatus(strings.ToUpper(part))
+			if !status.IsValid() {
+				return nil, fmt.Errorf("invalid status %q", part)
+			}
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses, nil
+}
+
+// GetRevenueSummary handles GET /api/v1/businesses/:businessId/revenue
+// Query params: from, to (YYYY-MM-DD, both inclusive, UTC), targetCurrency (optional, e.g. USD)
+// The route requires the business's owner or an admin.
+func (h *BookingHandler) GetRevenueSummary(c *gin.Context) {
+	businessID := c.Param("businessId")
+	fromStr := c.Query("from")
+	toStr := c.Query("to")
+	targetCurrency := strings.ToUpper(strings.TrimSpace(c.Query("targetCurrency")))
+
+	if fromStr == "" || toStr == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from and to dates are required (YYYY-MM-DD)"})
+		return
+	}
+	from, err := time.Parse("2006-01-02", fromStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date format, please use YYYY-MM-DD"})
+		return
+	}
+	to, err := time.Parse("2006-01-02", toStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date format, please use YYYY-MM-DD"})
+		return
+	}
+
+	// The service works with a half-open range, so include the whole "to" day
+	summary, err := h.service.RevenueSummary(c.Request.Context(), businessID, from, to.AddDate(0, 0, 1), targetCurrency)
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to get revenue summary", "businessId", businessID, "error", err)
+		if errors.Is(err, service.ErrNoExchangeRate) || strings.Contains(err.Error(), "cannot be after") || strings.Contains(err.Error(), "cannot be empty") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve revenue summary"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// GetDashboardStats handles GET /api/v1/businesses/:businessId/stats: today's and this week's bookings,
+// pending confirmations and this week's cancellations, in the business's timezone.
+// The route requires the business's owner or an admin.
+func (h *BookingHandler) GetDashboardStats(c *gin.Context) {
+	businessID := c.Param("businessId")
+
+	stats, err := h.service.DashboardStats(c.Request.Context(), businessID)
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to get dashboard stats", "businessId", businessID, "error", err)
+		if strings.Contains(err.Error(), "cannot be empty") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve dashboard stats"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// ImportBookings handles POST /api/v1/businesses/:businessId/bookings/import, bringing over bookings from
+// another system. Invalid rows are reported and skipped; the valid ones are stored together.
+// The route requires the business's owner or an admin.
+func (h *BookingHandler) ImportBookings(c *gin.Context) {
+	businessID := c.Param("businessId")
+	var req ImportBookingsRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to bind ImportBookings request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload: " + err.Error()})
+		return
+	}
+
+	h.logger.InfoContext(c.Request.Context(), "Importing bookings via API", "businessId", businessID, "rows", len(req.Bookings))
+	results, err := h.service.ImportBookings(c.Request.Context(), service.ImportBookingsRequest{
+		BusinessID:                   businessID,
+		Rows:                         req.Bookings,
+		SkipHistoricalConflictChecks: req.SkipHistoricalConflictChecks,
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "cannot be empty") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			h.logger.ErrorContext(c.Request.Context(), "Failed to import bookings", "businessId", businessID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import bookings"})
+		}
+		return
+	}
+
+	imported := 0
+	for _, result := range results {
+		if result.Success {
+			imported++
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"results": results, "imported": imported, "failed": len(results) - imported})
+}
+
+// UpdateBookingStatus handles PUT /api/v1/bookings/:bookingId/status for admins, or owners of the booking's business.
 func (h *BookingHandler) UpdateBookingStatus(c *gin.Context) {
 	bookingID := c.Param("bookingId")
-	// TODO: Authorization: Ensure only authorized users (e.g., admin, or business owner for their bookings) can update status.
 
 	var req UpdateBookingStatusRequestDTO
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Error("Failed to bind UpdateBookingStatus request", "error", err)
+		h.logger.ErrorContext(c.Request.Context(), "Failed to bind UpdateBookingStatus request", "error", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload: " + err.Error()})
 		return
 	}
 
-	h.logger.Info("Updating booking status via API", "bookingId", bookingID, "newStatus", req.Status)
-	updatedBooking, err := h.service.UpdateBookingStatus(c.Request.Context(), bookingID, req.Status)
+	h.logger.InfoContext(c.Request.Context(), "Updating booking status via API", "bookingId", bookingID, "newStatus", req.Status)
+	updatedBooking, err := h.service.UpdateBookingStatus(c.Request.Context(), bookingID, service.UpdateBookingStatusRequest{
+		Status:    req.Status,
+		ChangedBy: c.GetString("user_id"),
+		Reason:    req.Reason,
+		Scope:     req.Scope,
+		CanManage: func(businessID string) bool { return middleware.CanManageBusiness(c, businessID) },
+	})
 	if err != nil {
-		h.logger.Error("Failed to update booking status", "bookingId", bookingID, "error", err)
-		if strings.Contains(err.Error(), "not found") {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to update booking status", "bookingId", bookingID, "error", err)
+		var transitionErr *service.StatusTransitionError
+		if errors.Is(err, repository.ErrBookingNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else if errors.Is(err, service.ErrBookingAccessDenied) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You can only change your own business's bookings"})
+		} else if errors.Is(err, service.ErrSeriesCancellationDenied) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		} else if errors.As(err, &transitionErr) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		} else if strings.Contains(err.Error(), "invalid cancellation scope") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		} else {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update booking status: " + err.Error()})
 		}
@@ -170,5 +430,210 @@ func (h *BookingHandler) UpdateBookingStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, updatedBooking)
 }
 
+// BulkUpdateBookingStatus handles PUT /api/v1/bookings/status-bulk, moving several bookings to one status.
+// Each booking is updated on its own, so the response reports success or failure per booking ID.
+// Admins may change any booking; business owners only their own business's, checked booking by booking.
+func (h *BookingHandler) BulkUpdateBookingStatus(c *gin.Context) {
+	var req BulkUpdateBookingStatusRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to bind BulkUpdateBookingStatus request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload: " + err.Error()})
+		return
+	}
+
+	h.logger.InfoContext(c.Request.Context(), "Bulk updating booking statuses via API", "count", len(req.BookingIDs), "newStatus", req.Status)
+	results, err := h.service.UpdateBookingStatuses(c.Request.Context(), req.BookingIDs, service.UpdateBookingStatusRequest{
+		Status:    req.Status,
+		ChangedBy: c.GetString("user_id"),
+		Reason:    req.Reason,
+	}, func(businessID string) bool { return middleware.CanManageBusiness(c, businessID) })
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	succeeded := 0
+	for _, result := range results {
+		if result.Success {
+			succeeded++
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"results": results, "succeeded": succeeded, "failed": len(results) - succeeded})
+}
+
+// CancelBooking handles DELETE /api/v1/bookings/:bookingId, letting a customer cancel their own
+// booking before the business's cancellation cutoff.
+func (h *BookingHandler) CancelBooking(c *gin.Context) {
+	bookingID := c.Param("bookingId")
+	customerID := c.GetString("user_id")
+	if customerID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	h.logger.InfoContext(c.Request.Context(), "Customer cancelling booking via API", "bookingId", bookingID, "customerId", customerID)
+	booking, err := h.service.CancelBookingAsCustomer(c.Request.Context(), bookingID, customerID)
+	if err != nil {
+		var policyErr *service.CancellationPolicyError
+		if errors.As(err, &policyErr) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":                err.Error(),
+				"cancellationDeadline": policyErr.Deadline,
+			})
+			return
+		}
+		h.logger.ErrorContext(c.Request.Context(), "Failed to cancel booking", "bookingId", bookingID, "error", err)
+		if errors.Is(err, repository.ErrBookingNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else if errors.Is(err, service.ErrBookingNotOwned) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You can only cancel your own bookings"})
+		} else if strings.Contains(err.Error(), "cannot be cancelled") {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel booking"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, booking)
+}
+
+// RescheduleBookingRequestDTO is the payload for POST /api/v1/bookings/:bookingId/reschedule.
+type RescheduleBookingRequestDTO struct {
+	StartTime time.Time `json:"startTime" binding:"required"`
+}
+
+// RescheduleBooking handles POST /api/v1/bookings/:bookingId/reschedule, moving a booking to a new start time
+// with the same duration. The booking's customer, the business's owner or an admin may reschedule it.
+func (h *BookingHandler) RescheduleBooking(c *gin.Context) {
+	bookingID := c.Param("bookingId")
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	var req RescheduleBookingRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to bind RescheduleBooking request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload: " + err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), createBookingTimeout)
+	defer cancel()
+
+	booking, err := h.service.RescheduleBooking(ctx, bookingID, req.StartTime, userID, func(businessID string) bool {
+		return middleware.CanManageBusiness(c, businessID)
+	})
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to reschedule booking", "bookingId", bookingID, "error", err)
+		if errors.Is(err, context.DeadlineExceeded) {
+			c.JSON(http.StatusGatewayTimeout, gin.H{"error": "Timed out rescheduling booking, please try again"})
+		} else if respondSlotUnavailable(c, err) {
+			return
+		} else if errors.Is(err, repository.ErrBookingNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+		} else if errors.Is(err, service.ErrBookingNotOwned) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You can only reschedule your own bookings"})
+		} else if strings.Contains(err.Error(), "cannot be rescheduled") {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reschedule booking"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, booking)
+}
+
+// CancelPendingBooking handles DELETE /api/v1/bookings/:bookingId/pending, letting a customer who abandoned
+// payment drop their unpaid booking. Only PENDING_PAYMENT bookings of the caller can be dropped.
+func (h *BookingHandler) CancelPendingBooking(c *gin.Context) {
+	bookingID := c.Param("bookingId")
+	customerID := c.GetString("user_id")
+	if customerID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	booking, err := h.service.CancelPendingBooking(c.Request.Context(), bookingID, customerID)
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to cancel pending booking", "bookingId", bookingID, "error", err)
+		if errors.Is(err, repository.ErrBookingNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else if errors.Is(err, service.ErrBookingNotOwned) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You can only cancel your own bookings"})
+		} else if strings.Contains(err.Error(), "not pending payment") {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel booking"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, booking)
+}
+
+// ResendConfirmation handles POST /api/v1/bookings/:bookingId/resend-confirmation, sending a confirmed
+// booking's confirmation email again. Allowed for the booking's customer and its business.
+func (h *BookingHandler) ResendConfirmation(c *gin.Context) {
+	bookingID := c.Param("bookingId")
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	booking, err := h.service.ResendConfirmation(c.Request.Context(), bookingID, userID, c.GetString("user_business_id"))
+	if err != nil {
+		var limitErr *service.ConfirmationResendLimitError
+		if errors.As(err, &limitErr) {
+			retryAfter := int(math.Ceil(time.Until(limitErr.RetryAt).Seconds()))
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.ErrorContext(c.Request.Context(), "Failed to resend booking confirmation", "bookingId", bookingID, "error", err)
+		if errors.Is(err, repository.ErrBookingNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else if errors.Is(err, service.ErrBookingNotOwned) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You can only resend confirmations of your own bookings"})
+		} else if strings.Contains(err.Error(), "not confirmed") || strings.Contains(err.Error(), "notifications disabled") {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resend confirmation"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"bookingId": booking.ID, "message": "Confirmation resent"})
+}
+
+// GetBookingStatusHistory handles GET /api/v1/bookings/:bookingId/history
+func (h *BookingHandler) GetBookingStatusHistory(c *gin.Context) {
+	bookingID := c.Param("bookingId")
+
+	history, err := h.service.GetBookingStatusHistory(c.Request.Context(), bookingID, func(businessID string) bool {
+		return middleware.CanManageBusiness(c, businessID)
+	})
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to get booking status history", "bookingId", bookingID, "error", err)
+		if errors.Is(err, repository.ErrBookingNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else if errors.Is(err, service.ErrBookingAccessDenied) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You can only view your own business's bookings"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve booking history"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": history})
+}
+
 // The placeholder BookingRepo_INTERNAL_... helper methods are no longer needed and should be removed.
 // They were illustrative and have been replaced by actual methods on BookingService.
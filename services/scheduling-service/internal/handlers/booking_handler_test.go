@@ -5,12 +5,15 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/slotwise/scheduling-service/internal/client"
 	"github.com/slotwise/scheduling-service/internal/handlers"
+	"github.com/slotwise/scheduling-service/internal/middleware"
 	"github.com/slotwise/scheduling-service/internal/models"
 	"github.com/slotwise/scheduling-service/internal/repository"
 	"github.com/slotwise/scheduling-service/internal/service"
@@ -43,6 +46,7 @@ func (m *MockNatsPublisherForHandler) Reset() { m.PublishedEvents = nil }
 type MockNotificationClientForHandler struct {
 	SentNotifications      []client.SendNotificationRequest
 	ScheduledNotifications []client.ScheduleNotificationRequest
+	CancelledBookingIDs    []string // Bookings whose scheduled notifications were cancelled
 }
 
 func (m *MockNotificationClientForHandler) SendNotification(req client.SendNotificationRequest) (*client.NotificationResponse, error) {
@@ -50,14 +54,57 @@ func (m *MockNotificationClientForHandler) SendNotification(req client.SendNotif
 	return &client.NotificationResponse{Success: true}, nil
 }
 
+func (m *MockNotificationClientForHandler) SendBatch(reqs []client.SendNotificationRequest) ([]client.BatchSendResult, error) {
+	results := make([]client.BatchSendResult, len(reqs))
+	for i, req := range reqs {
+		m.SentNotifications = append(m.SentNotifications, req)
+		results[i] = client.BatchSendResult{Index: i, Success: true}
+	}
+	return results, nil
+}
+
 func (m *MockNotificationClientForHandler) ScheduleNotification(req client.ScheduleNotificationRequest) (*client.NotificationResponse, error) {
 	m.ScheduledNotifications = append(m.ScheduledNotifications, req)
 	return &client.NotificationResponse{Success: true}, nil
 }
 
+func (m *MockNotificationClientForHandler) CancelScheduledNotifications(bookingID string) (int, error) {
+	m.CancelledBookingIDs = append(m.CancelledBookingIDs, bookingID)
+	return 0, nil
+}
+
 func (m *MockNotificationClientForHandler) Reset() {
 	m.SentNotifications = nil
 	m.ScheduledNotifications = nil
+	m.CancelledBookingIDs = nil
+}
+
+const bookingTestJWTSecret = "booking-handler-test-secret"
+
+// The issuer and audience the handler tests' tokens carry and their validators require, as the auth service's would.
+const (
+	testTokenIssuer   = "slotwise-auth-service"
+	testTokenAudience = "slotwise-api"
+)
+
+var bookingTestTokens = &middleware.TokenValidator{Secret: bookingTestJWTSecret, Issuer: testTokenIssuer, Audience: testTokenAudience}
+
+// signToken returns an access token for a user with role, owning businessID when it is not empty.
+func (suite *BookingHandlerTestSuite) signToken(role, businessID string) string {
+	claims := middleware.Claims{
+		Role:       role,
+		BusinessID: businessID,
+		TokenType:  "access",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "user-" + role,
+			Issuer:    testTokenIssuer,
+			Audience:  jwt.ClaimStrings{testTokenAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(bookingTestJWTSecret))
+	assert.NoError(suite.T(), err)
+	return token
 }
 
 type BookingHandlerTestSuite struct {
@@ -80,7 +127,7 @@ func (suite *BookingHandlerTestSuite) SetupSuite() {
 	assert.NoError(suite.T(), err)
 	suite.DB = db
 
-	err = suite.DB.AutoMigrate(&models.ServiceDefinition{}, &models.AvailabilityRule{}, &models.Booking{})
+	err = suite.DB.AutoMigrate(&models.Business{}, &models.ServiceDefinition{}, &models.AvailabilityRule{}, &models.AvailabilityVersion{}, &models.Booking{}, &models.OutboxEvent{}, &models.BookingStatusHistory{}, &models.CustomerPreference{})
 	assert.NoError(suite.T(), err)
 
 	suite.BookingRepo = repository.NewBookingRepository(suite.DB)
@@ -89,11 +136,12 @@ func (suite *BookingHandlerTestSuite) SetupSuite() {
 
 	// Services
 	// AvailabilityService needs BookingRepo for conflict check in GetAvailableSlots
-	suite.AvailabilityService = service.NewAvailabilityService(suite.AvailabilityRepo, suite.BookingRepo, nil, suite.MockNatsPub, suite.TestLogger)
+	suite.AvailabilityService = service.NewAvailabilityService(suite.AvailabilityRepo, suite.BookingRepo, nil, nil, 0, 0, suite.MockNatsPub, nil, suite.TestLogger)
 	// BookingService needs AvailabilityRepo (as serviceDefRepo)
 	// Create a mock notification client
 	mockNotificationClient := &MockNotificationClientForHandler{}
-	suite.BookingService = service.NewBookingService(suite.BookingRepo, suite.AvailabilityService, suite.AvailabilityRepo, suite.MockNatsPub, mockNotificationClient, suite.TestLogger)
+	outboxRelay := service.NewOutboxRelay(repository.NewOutboxRepository(suite.DB), suite.MockNatsPub, suite.TestLogger)
+	suite.BookingService = service.NewBookingService(suite.BookingRepo, suite.AvailabilityService, suite.AvailabilityRepo, repository.NewCustomerPreferenceRepository(suite.DB), outboxRelay, suite.MockNatsPub, mockNotificationClient, nil, suite.TestLogger)
 
 	// Router and Handlers
 	gin.SetMode(gin.TestMode)
@@ -105,11 +153,17 @@ func (suite *BookingHandlerTestSuite) SetupSuite() {
 	{
 		b := v1.Group("/bookings")
 		{
-			b.POST("", bookingHandler.CreateBooking)
+			b.POST("", middleware.RequireAuth(bookingTestTokens), bookingHandler.CreateBooking)
 			b.GET("/:bookingId", bookingHandler.GetBookingByID)
-			b.GET("", bookingHandler.ListBookings)
-			b.PUT("/:bookingId/status", bookingHandler.UpdateBookingStatus)
+			b.GET("", middleware.RequireAuth(bookingTestTokens), bookingHandler.ListBookings)
+			b.PUT("/:bookingId/status", middleware.RequireAuth(bookingTestTokens), middleware.RequireBusinessOwner(""), bookingHandler.UpdateBookingStatus)
+			b.PUT("/status-bulk", middleware.RequireAuth(bookingTestTokens), middleware.RequireBusinessOwner(""), bookingHandler.BulkUpdateBookingStatus)
+			b.GET("/:bookingId/history", middleware.RequireAuth(bookingTestTokens), middleware.RequireBusinessOwner(""), bookingHandler.GetBookingStatusHistory)
+			b.POST("/:bookingId/reschedule", middleware.RequireAuth(bookingTestTokens), bookingHandler.RescheduleBooking)
 		}
+		v1.POST("/businesses/:businessId/bookings/import", middleware.RequireAuth(bookingTestTokens), middleware.RequireBusinessOwner("businessId"), middleware.MaxBodyBytes(handlers.MaxImportBodyBytes), bookingHandler.ImportBookings)
+		v1.GET("/businesses/:businessId/stats", middleware.RequireAuth(bookingTestTokens), middleware.RequireBusinessOwner("businessId"), bookingHandler.GetDashboardStats)
+		v1.GET("/businesses/:businessId/revenue", middleware.RequireAuth(bookingTestTokens), middleware.RequireBusinessOwner("businessId"), bookingHandler.GetRevenueSummary)
 		// Example for public slots if also tested here:
 		// v1.GET("/services/:serviceId/slots", availabilityHandler.GetPublicSlotsForService)
 	}
@@ -126,6 +180,9 @@ func (suite *BookingHandlerTestSuite) SetupTest() {
 	suite.DB.Exec("DELETE FROM bookings")
 	suite.DB.Exec("DELETE FROM service_definitions")
 	suite.DB.Exec("DELETE FROM availability_rules")
+	suite.DB.Exec("DELETE FROM availability_versions")
+	suite.DB.Exec("DELETE FROM outbox_events")
+	suite.DB.Exec("DELETE FROM booking_status_history")
 }
 
 func (suite *BookingHandlerTestSuite) TestCreateBookingAPI_Success() {
@@ -133,16 +190,18 @@ func (suite *BookingHandlerTestSuite) TestCreateBookingAPI_Success() {
 	// Seed Service Definition
 	svcDef := models.ServiceDefinition{ID: "s1", BusinessID: "b1", Name: "Svc 1", DurationMinutes: 30, IsActive: true}
 	suite.DB.Create(&svcDef)
+	// 2030-05-01 is a Wednesday
+	suite.DB.Create(&models.AvailabilityRule{BusinessID: "b1", DayOfWeek: models.Wednesday, StartTime: "09:00", EndTime: "17:00"})
 
-	startTime, _ := time.Parse(time.RFC3339, "2024-05-01T10:00:00Z")
+	startTime, _ := time.Parse(time.RFC3339, "2030-05-01T10:00:00Z")
 	payload := handlers.CreateBookingRequestDTO{
-		BusinessID: "b1", ServiceID: "s1", CustomerID: "c1", StartTime: startTime,
+		BusinessID: "b1", ServiceID: "s1", StartTime: startTime, // The customer is the caller
 	}
 	body, _ := json.Marshal(payload)
 
 	req, _ := http.NewRequest(http.MethodPost, "/api/v1/bookings", bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
-	// TODO: Add mock auth header/context if CreateBooking handler expects CustomerID from auth
+	req.Header.Set("Authorization", "Bearer "+suite.signToken("customer", ""))
 
 	rr := httptest.NewRecorder()
 	suite.Router.ServeHTTP(rr, req)
@@ -152,6 +211,7 @@ func (suite *BookingHandlerTestSuite) TestCreateBookingAPI_Success() {
 	err := json.Unmarshal(rr.Body.Bytes(), &bookingResp)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, bookingResp.ID)
+	assert.Equal(t, "user-customer", bookingResp.CustomerID)
 	assert.Equal(t, models.BookingStatusPendingPayment, bookingResp.Status)
 
 	// Check NATS
@@ -163,8 +223,10 @@ func (suite *BookingHandlerTestSuite) TestCreateBookingAPI_Conflict() {
 	t := suite.T()
 	svcDef := models.ServiceDefinition{ID: "s2", BusinessID: "b2", Name: "Svc 2", DurationMinutes: 60, IsActive: true}
 	suite.DB.Create(&svcDef)
+	// 2030-05-01 is a Wednesday
+	suite.DB.Create(&models.AvailabilityRule{BusinessID: "b2", DayOfWeek: models.Wednesday, StartTime: "09:00", EndTime: "17:00"})
 
-	existingStartTime, _ := time.Parse(time.RFC3339, "2024-05-01T11:00:00Z")
+	existingStartTime, _ := time.Parse(time.RFC3339, "2030-05-01T11:00:00Z")
 	existingBooking := models.Booking{
 		// Let BeforeCreate hook generate the UUID
 		BusinessID: "b2", ServiceID: "s2", CustomerID: "c_exist",
@@ -174,11 +236,12 @@ func (suite *BookingHandlerTestSuite) TestCreateBookingAPI_Conflict() {
 
 	conflictStartTime := existingStartTime.Add(30 * time.Minute)
 	payload := handlers.CreateBookingRequestDTO{
-		BusinessID: "b2", ServiceID: "s2", CustomerID: "c_new", StartTime: conflictStartTime,
+		BusinessID: "b2", ServiceID: "s2", StartTime: conflictStartTime,
 	}
 	body, _ := json.Marshal(payload)
 	req, _ := http.NewRequest(http.MethodPost, "/api/v1/bookings", bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+suite.signToken("customer", ""))
 	rr := httptest.NewRecorder()
 	suite.Router.ServeHTTP(rr, req)
 
@@ -186,6 +249,164 @@ func (suite *BookingHandlerTestSuite) TestCreateBookingAPI_Conflict() {
 	assert.Len(t, suite.MockNatsPub.PublishedEvents, 0)
 }
 
+func (suite *BookingHandlerTestSuite) TestCreateBookingAPI_ConflictSuggestsAlternative() {
+	t := suite.T()
+	suite.DB.Create(&models.ServiceDefinition{ID: "s_alt", BusinessID: "b_alt", Name: "Svc Alt", DurationMinutes: 60, IsActive: true})
+	// 2030-05-01 is a Wednesday
+	suite.DB.Create(&models.AvailabilityRule{BusinessID: "b_alt", DayOfWeek: models.Wednesday, StartTime: "09:00", EndTime: "17:00"})
+
+	existingStartTime, _ := time.Parse(time.RFC3339, "2030-05-01T11:00:00Z")
+	suite.DB.Create(&models.Booking{
+		BusinessID: "b_alt", ServiceID: "s_alt", CustomerID: "c_exist",
+		StartTime: existingStartTime, EndTime: existingStartTime.Add(60 * time.Minute), Status: models.BookingStatusConfirmed,
+	})
+
+	payload := handlers.CreateBookingRequestDTO{
+		BusinessID: "b_alt", ServiceID: "s_alt", StartTime: existingStartTime.Add(30 * time.Minute),
+	}
+	body, _ := json.Marshal(payload)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/bookings", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+suite.signToken("customer", ""))
+	rr := httptest.NewRecorder()
+	suite.Router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusConflict, rr.Code)
+
+	var resp struct {
+		Error    string `json:"error"`
+		Conflict struct {
+			StartTime time.Time `json:"startTime"`
+			EndTime   time.Time `json:"endTime"`
+		} `json:"conflict"`
+		SuggestedStartTime *time.Time `json:"suggestedStartTime"`
+	}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Contains(t, resp.Error, "not available due to a conflict")
+	assert.True(t, existingStartTime.Equal(resp.Conflict.StartTime))
+	assert.True(t, existingStartTime.Add(60*time.Minute).Equal(resp.Conflict.EndTime))
+	if assert.NotNil(t, resp.SuggestedStartTime, "409 body should suggest an alternative start time") {
+		assert.True(t, existingStartTime.Add(60*time.Minute).Equal(*resp.SuggestedStartTime), "got %s", resp.SuggestedStartTime)
+	}
+}
+
+func (suite *BookingHandlerTestSuite) TestCreateBookingAPI_UnavailableSlotReturnsReasonCode() {
+	t := suite.T()
+	startTime, _ := time.Parse(time.RFC3339, "2030-05-01T11:00:00Z")
+
+	// Taken: the slot overlaps a confirmed booking
+	suite.DB.Create(&models.ServiceDefinition{ID: "s_taken", BusinessID: "b_taken", Name: "Taken", DurationMinutes: 60, IsActive: true})
+	suite.DB.Create(&models.AvailabilityRule{BusinessID: "b_taken", DayOfWeek: models.Wednesday, StartTime: "09:00", EndTime: "17:00"})
+	suite.DB.Create(&models.Booking{
+		BusinessID: "b_taken", ServiceID: "s_taken", CustomerID: "c_exist",
+		StartTime: startTime, EndTime: startTime.Add(time.Hour), Status: models.BookingStatusConfirmed,
+	})
+
+	// Paused: the business stopped taking bookings
+	suite.DB.Create(&models.ServiceDefinition{ID: "s_paused", BusinessID: "b_paused", Name: "Paused", DurationMinutes: 60, IsActive: true})
+	suite.DB.Save(&models.Business{ID: "b_paused", Name: "Paused Business", AcceptingBookings: true})
+	suite.DB.Model(&models.Business{ID: "b_paused"}).Update("accepting_bookings", false)
+
+	// Closed: the business has no availability on Wednesdays
+	suite.DB.Create(&models.ServiceDefinition{ID: "s_closed", BusinessID: "b_closed", Name: "Closed", DurationMinutes: 60, IsActive: true})
+	suite.DB.Create(&models.AvailabilityRule{BusinessID: "b_closed", DayOfWeek: models.Monday, StartTime: "09:00", EndTime: "17:00"})
+
+	cases := []struct {
+		businessID, serviceID string
+		status                int
+		code                  service.SlotUnavailableReason
+	}{
+		{"b_taken", "s_taken", http.StatusConflict, service.SlotUnavailableConflict},
+		{"b_paused", "s_paused", http.StatusConflict, service.SlotUnavailableBusinessPaused},
+		{"b_closed", "s_closed", http.StatusUnprocessableEntity, service.SlotUnavailableOutsideHours},
+	}
+	for _, tc := range cases {
+		body, _ := json.Marshal(handlers.CreateBookingRequestDTO{BusinessID: tc.businessID, ServiceID: tc.serviceID, StartTime: startTime})
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/bookings", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+suite.signToken("customer", ""))
+		rr := httptest.NewRecorder()
+		suite.Router.ServeHTTP(rr, req)
+
+		assert.Equal(t, tc.status, rr.Code, tc.code)
+		var resp struct {
+			Error string                        `json:"error"`
+			Code  service.SlotUnavailableReason `json:"code"`
+		}
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, tc.code, resp.Code)
+		assert.NotEmpty(t, resp.Error)
+	}
+	assert.Empty(t, suite.MockNatsPub.PublishedEvents)
+}
+
+func (suite *BookingHandlerTestSuite) TestCreateBookingAPI_OnlyTheBusinessBooksForOthers() {
+	t := suite.T()
+	suite.DB.Create(&models.ServiceDefinition{ID: "s_guest", BusinessID: "b_guest", Name: "Svc Guest", DurationMinutes: 30, IsActive: true})
+	// 2030-05-01 is a Wednesday
+	suite.DB.Create(&models.AvailabilityRule{BusinessID: "b_guest", DayOfWeek: models.Wednesday, StartTime: "09:00", EndTime: "17:00"})
+	startTime, _ := time.Parse(time.RFC3339, "2030-05-01T10:00:00Z")
+
+	send := func(token string, payload handlers.CreateBookingRequestDTO) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(payload)
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/bookings", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		suite.Router.ServeHTTP(rr, req)
+		return rr
+	}
+	guest := handlers.CreateBookingRequestDTO{
+		BusinessID: "b_guest", ServiceID: "s_guest", StartTime: startTime,
+		Guest: &handlers.GuestContactDTO{Name: "Walk In", Email: "walkin@example.com"},
+	}
+	forSomeoneElse := handlers.CreateBookingRequestDTO{BusinessID: "b_guest", ServiceID: "s_guest", CustomerID: "c_other", StartTime: startTime}
+
+	assert.Equal(t, http.StatusUnauthorized, send("", guest).Code)
+	assert.Equal(t, http.StatusForbidden, send(suite.signToken("customer", ""), guest).Code, "Customers cannot make guest bookings")
+	assert.Equal(t, http.StatusForbidden, send(suite.signToken("customer", ""), forSomeoneElse).Code, "Customers cannot book for other customers")
+	assert.Equal(t, http.StatusForbidden, send(suite.signToken(middleware.RoleBusinessOwner, "b_someone_else"), guest).Code)
+	assert.Empty(t, suite.MockNatsPub.PublishedEvents)
+
+	rr := send(suite.signToken(middleware.RoleBusinessOwner, "b_guest"), guest)
+	assert.Equal(t, http.StatusCreated, rr.Code)
+}
+
+func (suite *BookingHandlerTestSuite) TestBookingAPIs_UnknownResourcesAreNotFound() {
+	t := suite.T()
+	suite.DB.Create(&models.ServiceDefinition{ID: "s_nf", BusinessID: "b_nf", Name: "Svc NF", DurationMinutes: 30, IsActive: true})
+	suite.DB.Create(&models.ServiceDefinition{ID: "s_nf_retired", BusinessID: "b_nf", Name: "Svc Retired", DurationMinutes: 30, IsActive: false})
+	startTime, _ := time.Parse(time.RFC3339, "2030-05-01T10:00:00Z")
+	customerToken := suite.signToken("customer", "")
+	ownerToken := suite.signToken(middleware.RoleBusinessOwner, "b_nf")
+
+	send := func(method, url, token string, payload interface{}) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(payload)
+		req, _ := http.NewRequest(method, url, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		suite.Router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	for _, payload := range []handlers.CreateBookingRequestDTO{
+		{BusinessID: "b_nf", ServiceID: "s_missing", StartTime: startTime},
+		{BusinessID: "b_nf", ServiceID: "s_nf_retired", StartTime: startTime},
+		{BusinessID: "b_someone_else", ServiceID: "s_nf", StartTime: startTime},
+	} {
+		assert.Equal(t, http.StatusNotFound, send(http.MethodPost, "/api/v1/bookings", customerToken, payload).Code, payload.ServiceID)
+	}
+
+	missing := "00000000-0000-0000-0000-000000000000"
+	rr := send(http.MethodPut, "/api/v1/bookings/"+missing+"/status", ownerToken, handlers.UpdateBookingStatusRequestDTO{Status: models.BookingStatusConfirmed})
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	rr = send(http.MethodGet, "/api/v1/bookings/"+missing+"/history", ownerToken, nil)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
 func (suite *BookingHandlerTestSuite) TestGetBookingByIDAPI() {
 	t := suite.T()
 	startTime, _ := time.Parse(time.RFC3339, "2024-05-01T15:00:00Z")
@@ -207,20 +428,137 @@ func (suite *BookingHandlerTestSuite) TestGetBookingByIDAPI() {
 	assert.Equal(t, bookingID, bookingResp.ID)
 }
 
+func (suite *BookingHandlerTestSuite) TestGetBookingByIDAPI_TimesAreRFC3339UTC() {
+	t := suite.T()
+	// 17:00 in UTC+02:00 is 15:00Z
+	startTime, _ := time.Parse(time.RFC3339, "2024-05-02T17:00:00+02:00")
+	newBooking := models.Booking{
+		BusinessID: "b_utc", ServiceID: "s_utc", CustomerID: "c_utc",
+		StartTime: startTime, EndTime: startTime.Add(60 * time.Minute), Status: models.BookingStatusConfirmed,
+	}
+	suite.DB.Create(&newBooking)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/bookings/"+newBooking.ID, nil)
+	rr := httptest.NewRecorder()
+	suite.Router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var raw map[string]interface{}
+	json.Unmarshal(rr.Body.Bytes(), &raw)
+	assert.Equal(t, "2024-05-02T15:00:00Z", raw["startTime"])
+	assert.Equal(t, "2024-05-02T16:00:00Z", raw["endTime"])
+	createdAt, _ := raw["createdAt"].(string)
+	_, err := time.Parse(time.RFC3339, createdAt)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasSuffix(createdAt, "Z"), "createdAt should be in UTC, got %s", createdAt)
+}
+
+func (suite *BookingHandlerTestSuite) TestGetBookingByIDAPI_ShowsCancellationFeeInsideFeeWindow() {
+	t := suite.T()
+	cutoffHours, feeWindowHours := 2, 24
+	businessFee, serviceFee := int64(1000), int64(1500)
+	suite.DB.Exec("DELETE FROM businesses WHERE id = ?", "b_fee")
+	suite.DB.Create(&models.Business{
+		ID: "b_fee", Name: "Fee Business", AcceptingBookings: true,
+		CancellationCutoffHours: &cutoffHours, CancellationFeeWindowHours: &feeWindowHours, CancellationFee: &businessFee,
+	})
+	suite.DB.Create(&models.ServiceDefinition{
+		ID: "s_fee", BusinessID: "b_fee", Name: "Colour", DurationMinutes: 60, Price: 8000, Currency: "USD", IsActive: true,
+		CancellationFee: &serviceFee,
+	})
+	// Inside the 24 hour fee window but before the 2 hour cutoff
+	startTime := time.Now().Add(5 * time.Hour).Truncate(time.Second)
+	newBooking := models.Booking{
+		BusinessID: "b_fee", ServiceID: "s_fee", CustomerID: "c_fee",
+		StartTime: startTime, EndTime: startTime.Add(60 * time.Minute), Status: models.BookingStatusConfirmed,
+	}
+	suite.DB.Create(&newBooking)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/bookings/"+newBooking.ID, nil)
+	rr := httptest.NewRecorder()
+	suite.Router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var bookingResp models.Booking
+	json.Unmarshal(rr.Body.Bytes(), &bookingResp)
+	policy := bookingResp.CancellationPolicy
+	if assert.NotNil(t, policy) {
+		assert.True(t, policy.CanCancel)
+		assert.True(t, policy.FeeApplies)
+		assert.Equal(t, serviceFee, policy.Fee, "the service's fee should override the business's")
+		assert.Equal(t, "USD", policy.Currency)
+		assert.True(t, policy.CancellableUntil.Equal(startTime.Add(-2*time.Hour)))
+		if assert.NotNil(t, policy.FeeFrom) {
+			assert.True(t, policy.FeeFrom.Equal(startTime.Add(-24*time.Hour)))
+		}
+	}
+}
+
 func (suite *BookingHandlerTestSuite) TestListBookingsAPI_ByCustomer() {
 	t := suite.T()
 	// Seed bookings - let BeforeCreate hook generate UUIDs
-	suite.DB.Create(&models.Booking{CustomerID: "cust_list_api", BusinessID: "b_l_c", ServiceID: "s_l_c", StartTime: time.Now(), EndTime: time.Now().Add(30 * time.Minute), Status: models.BookingStatusConfirmed})
-	suite.DB.Create(&models.Booking{CustomerID: "cust_list_api", BusinessID: "b_l_c", ServiceID: "s_l_c", StartTime: time.Now().Add(time.Hour), EndTime: time.Now().Add(time.Hour + 30*time.Minute), Status: models.BookingStatusPendingPayment})
+	// The customer token's user ID is user-customer
+	suite.DB.Create(&models.Booking{CustomerID: "user-customer", BusinessID: "b_l_c", ServiceID: "s_l_c", StartTime: time.Now(), EndTime: time.Now().Add(30 * time.Minute), Status: models.BookingStatusConfirmed})
+	suite.DB.Create(&models.Booking{CustomerID: "user-customer", BusinessID: "b_l_c", ServiceID: "s_l_c", StartTime: time.Now().Add(time.Hour), EndTime: time.Now().Add(time.Hour + 30*time.Minute), Status: models.BookingStatusPendingPayment})
 	suite.DB.Create(&models.Booking{CustomerID: "cust_other", BusinessID: "b_l_c", ServiceID: "s_l_c", StartTime: time.Now(), EndTime: time.Now().Add(30 * time.Minute), Status: models.BookingStatusConfirmed})
+	customerToken := suite.signToken("customer", "")
 
-	req, _ := http.NewRequest(http.MethodGet, "/api/v1/bookings?customerId=cust_list_api", nil)
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/bookings?customerId=user-customer", nil)
+	req.Header.Set("Authorization", "Bearer "+customerToken)
 	rr := httptest.NewRecorder()
 	suite.Router.ServeHTTP(rr, req)
 	assert.Equal(t, http.StatusOK, rr.Code)
 	var respData struct{ Data []models.Booking }
 	json.Unmarshal(rr.Body.Bytes(), &respData)
 	assert.Len(t, respData.Data, 2)
+
+	// Another customer's bookings are off limits
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/bookings?customerId=cust_other", nil)
+	req.Header.Set("Authorization", "Bearer "+customerToken)
+	rr = httptest.NewRecorder()
+	suite.Router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+}
+
+func (suite *BookingHandlerTestSuite) TestListBookingsAPI_ByBusinessWithMultipleStatuses() {
+	t := suite.T()
+	for _, status := range []models.BookingStatus{models.BookingStatusConfirmed, models.BookingStatusPendingPayment, models.BookingStatusCancelled, models.BookingStatusCompleted} {
+		suite.DB.Create(&models.Booking{CustomerID: "cust_multi", BusinessID: "b_multi_status", ServiceID: "s_multi", StartTime: time.Now(), EndTime: time.Now().Add(30 * time.Minute), Status: status})
+	}
+
+	ownerToken := suite.signToken(middleware.RoleBusinessOwner, "b_multi_status")
+	for _, query := range []string{"status=CONFIRMED,PENDING_PAYMENT", "status=CONFIRMED&status=PENDING_PAYMENT"} {
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/bookings?businessId=b_multi_status&"+query, nil)
+		req.Header.Set("Authorization", "Bearer "+ownerToken)
+		rr := httptest.NewRecorder()
+		suite.Router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code, query)
+		var respData struct{ Data []models.Booking }
+		json.Unmarshal(rr.Body.Bytes(), &respData)
+		if assert.Len(t, respData.Data, 2, query) {
+			statuses := []models.BookingStatus{respData.Data[0].Status, respData.Data[1].Status}
+			assert.ElementsMatch(t, []models.BookingStatus{models.BookingStatusConfirmed, models.BookingStatusPendingPayment}, statuses, query)
+		}
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/bookings?businessId=b_multi_status&status=CONFIRMED,BOGUS", nil)
+	req.Header.Set("Authorization", "Bearer "+ownerToken)
+	rr := httptest.NewRecorder()
+	suite.Router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	// Searching a business's bookings by customer needs its owner
+	for _, token := range []string{suite.signToken("customer", ""), suite.signToken(middleware.RoleBusinessOwner, "b_someone_else")} {
+		req, _ = http.NewRequest(http.MethodGet, "/api/v1/bookings?businessId=b_multi_status&q=cust", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr = httptest.NewRecorder()
+		suite.Router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	}
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/bookings?businessId=b_multi_status&q=cust", nil)
+	rr = httptest.NewRecorder()
+	suite.Router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
 }
 
 func (suite *BookingHandlerTestSuite) TestUpdateBookingStatusAPI() {
@@ -234,10 +572,12 @@ func (suite *BookingHandlerTestSuite) TestUpdateBookingStatusAPI() {
 	suite.DB.Create(&newBooking)
 	bookingID := newBooking.ID // Get the generated UUID
 
+	ownerToken := suite.signToken(middleware.RoleBusinessOwner, "b_upd")
 	payload := handlers.UpdateBookingStatusRequestDTO{Status: models.BookingStatusConfirmed}
 	body, _ := json.Marshal(payload)
 	req, _ := http.NewRequest(http.MethodPut, "/api/v1/bookings/"+bookingID+"/status", bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+ownerToken)
 	rr := httptest.NewRecorder()
 	suite.Router.ServeHTTP(rr, req)
 
@@ -248,6 +588,262 @@ func (suite *BookingHandlerTestSuite) TestUpdateBookingStatusAPI() {
 
 	// Check NATS events (BookingConfirmed and SlotReserved)
 	assert.Len(t, suite.MockNatsPub.PublishedEvents, 2)
+
+	// The transition is recorded in the booking's history
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/bookings/"+bookingID+"/history", nil)
+	req.Header.Set("Authorization", "Bearer "+ownerToken)
+	rr = httptest.NewRecorder()
+	suite.Router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var historyResp struct {
+		Data []models.BookingStatusHistory `json:"data"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &historyResp)
+	if assert.Len(t, historyResp.Data, 1) {
+		assert.Equal(t, models.BookingStatusPendingPayment, historyResp.Data[0].FromStatus)
+		assert.Equal(t, models.BookingStatusConfirmed, historyResp.Data[0].ToStatus)
+		assert.Equal(t, "user-"+middleware.RoleBusinessOwner, historyResp.Data[0].ChangedBy, "The owner is recorded as the actor")
+	}
+}
+
+func (suite *BookingHandlerTestSuite) TestUpdateBookingStatusAPI_RequiresBusinessOwner() {
+	t := suite.T()
+	startTime, _ := time.Parse(time.RFC3339, "2030-05-01T18:00:00Z")
+	booking := models.Booking{
+		BusinessID: "b_upd_own", ServiceID: "s_upd", CustomerID: "c_upd",
+		StartTime: startTime, EndTime: startTime.Add(60 * time.Minute), Status: models.BookingStatusPendingPayment,
+	}
+	suite.DB.Create(&booking)
+
+	send := func(method, path, token string, body []byte) int {
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		suite.Router.ServeHTTP(rr, req)
+		return rr.Code
+	}
+	body, _ := json.Marshal(handlers.UpdateBookingStatusRequestDTO{Status: models.BookingStatusCancelled})
+	statusPath := "/api/v1/bookings/" + booking.ID + "/status"
+	historyPath := "/api/v1/bookings/" + booking.ID + "/history"
+
+	assert.Equal(t, http.StatusUnauthorized, send(http.MethodPut, statusPath, "", body))
+	assert.Equal(t, http.StatusForbidden, send(http.MethodPut, statusPath, suite.signToken("customer", ""), body))
+	assert.Equal(t, http.StatusForbidden, send(http.MethodPut, statusPath, suite.signToken(middleware.RoleBusinessOwner, "b_someone_else"), body))
+	assert.Equal(t, http.StatusUnauthorized, send(http.MethodGet, historyPath, "", nil))
+	assert.Equal(t, http.StatusForbidden, send(http.MethodGet, historyPath, suite.signToken(middleware.RoleBusinessOwner, "b_someone_else"), nil))
+
+	var unchanged models.Booking
+	suite.DB.First(&unchanged, "id = ?", booking.ID)
+	assert.Equal(t, models.BookingStatusPendingPayment, unchanged.Status)
+}
+
+func (suite *BookingHandlerTestSuite) TestBulkUpdateBookingStatusAPI_ReportsPerBooking() {
+	t := suite.T()
+	startTime, _ := time.Parse(time.RFC3339, "2024-05-03T10:00:00Z")
+	newBooking := func(status models.BookingStatus, offset time.Duration) models.Booking {
+		booking := models.Booking{
+			BusinessID: "b_bulk", ServiceID: "s_bulk", CustomerID: "c_bulk",
+			StartTime: startTime.Add(offset), EndTime: startTime.Add(offset + time.Hour), Status: status,
+		}
+		suite.DB.Create(&booking)
+		return booking
+	}
+	pending := newBooking(models.BookingStatusPendingPayment, 0)
+	cancelled := newBooking(models.BookingStatusCancelled, time.Hour)
+	confirmed := newBooking(models.BookingStatusConfirmed, 2*time.Hour)
+	missing := "550e8400-e29b-41d4-a716-4466554400ee"
+
+	payload := handlers.BulkUpdateBookingStatusRequestDTO{
+		BookingIDs: []string{pending.ID, cancelled.ID, confirmed.ID, missing},
+		Status:     models.BookingStatusConfirmed,
+	}
+	body, _ := json.Marshal(payload)
+	req, _ := http.NewRequest(http.MethodPut, "/api/v1/bookings/status-bulk", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+suite.signToken(middleware.RoleBusinessOwner, "b_bulk"))
+	rr := httptest.NewRecorder()
+	suite.Router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var resp struct {
+		Results   map[string]service.BulkStatusResult `json:"results"`
+		Succeeded int                                 `json:"succeeded"`
+		Failed    int                                 `json:"failed"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	assert.Equal(t, 1, resp.Succeeded)
+	assert.Equal(t, 3, resp.Failed)
+	assert.Len(t, resp.Results, 4)
+
+	assert.True(t, resp.Results[pending.ID].Success)
+	assert.Equal(t, models.BookingStatusConfirmed, resp.Results[pending.ID].Status)
+	assert.False(t, resp.Results[cancelled.ID].Success)
+	assert.Contains(t, resp.Results[cancelled.ID].Error, "cannot change from CANCELLED to CONFIRMED")
+	assert.False(t, resp.Results[confirmed.ID].Success)
+	assert.Contains(t, resp.Results[confirmed.ID].Error, "cannot change from CONFIRMED to CONFIRMED")
+	assert.False(t, resp.Results[missing].Success)
+	assert.Contains(t, resp.Results[missing].Error, "not found")
+
+	// Only the valid transition was applied, and it fired its own events
+	var stored models.Booking
+	suite.DB.First(&stored, "id = ?", cancelled.ID)
+	assert.Equal(t, models.BookingStatusCancelled, stored.Status)
+	assert.Len(t, suite.MockNatsPub.PublishedEvents, 2) // BookingConfirmed and SlotReserved for the pending booking
+}
+
+func (suite *BookingHandlerTestSuite) TestBulkUpdateBookingStatusAPI_RequiresBusinessOwner() {
+	t := suite.T()
+	startTime, _ := time.Parse(time.RFC3339, "2024-05-03T10:00:00Z")
+	own := models.Booking{BusinessID: "b_bulk_own", ServiceID: "s_bulk", CustomerID: "c_bulk", StartTime: startTime, EndTime: startTime.Add(time.Hour), Status: models.BookingStatusPendingPayment}
+	other := models.Booking{BusinessID: "b_bulk_other", ServiceID: "s_bulk", CustomerID: "c_bulk", StartTime: startTime, EndTime: startTime.Add(time.Hour), Status: models.BookingStatusPendingPayment}
+	suite.DB.Create(&own)
+	suite.DB.Create(&other)
+
+	send := func(token string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(handlers.BulkUpdateBookingStatusRequestDTO{
+			BookingIDs: []string{own.ID, other.ID},
+			Status:     models.BookingStatusCancelled,
+		})
+		req, _ := http.NewRequest(http.MethodPut, "/api/v1/bookings/status-bulk", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		suite.Router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, send("").Code, "Anonymous callers cannot change bookings")
+	assert.Equal(t, http.StatusForbidden, send(suite.signToken("client", "")).Code, "Customers cannot bulk-change bookings")
+
+	rr := send(suite.signToken(middleware.RoleBusinessOwner, "b_bulk_own"))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var resp struct {
+		Results map[string]service.BulkStatusResult `json:"results"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	assert.True(t, resp.Results[own.ID].Success)
+	assert.False(t, resp.Results[other.ID].Success)
+	assert.Contains(t, resp.Results[other.ID].Error, "forbidden")
+
+	var stored models.Booking
+	suite.DB.First(&stored, "id = ?", other.ID)
+	assert.Equal(t, models.BookingStatusPendingPayment, stored.Status, "Another business's booking is left alone")
+
+	var history models.BookingStatusHistory
+	suite.DB.Where("booking_id = ?", own.ID).First(&history)
+	assert.Equal(t, "user-"+middleware.RoleBusinessOwner, history.ChangedBy, "The owner is recorded as the actor")
+}
+
+func (suite *BookingHandlerTestSuite) TestImportBookingsAPI_RequiresBusinessOwner() {
+	t := suite.T()
+	suite.DB.Create(&models.ServiceDefinition{ID: "s_import_api", BusinessID: "b_import_api", Name: "Cut", DurationMinutes: 30, IsActive: true})
+	send := func(token, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/businesses/b_import_api/bookings/import", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		suite.Router.ServeHTTP(rr, req)
+		return rr
+	}
+	body := `{"bookings":[{"serviceId":"s_import_api","customerId":"c_import","startTime":"2030-01-07T10:00:00Z"}]}`
+
+	assert.Equal(t, http.StatusUnauthorized, send("", body).Code)
+	assert.Equal(t, http.StatusForbidden, send(suite.signToken(middleware.RoleBusinessOwner, "b_someone_else"), body).Code)
+	var count int64
+	suite.DB.Model(&models.Booking{}).Where("business_id = ?", "b_import_api").Count(&count)
+	assert.Zero(t, count, "Rejected imports store nothing")
+
+	oversized := `{"bookings":[{"serviceId":"s_import_api","customerId":"c_import","startTime":"2030-01-07T10:00:00Z","notes":"` +
+		strings.Repeat("x", int(handlers.MaxImportBodyBytes)) + `"}]}`
+	assert.Equal(t, http.StatusRequestEntityTooLarge, send(suite.signToken(middleware.RoleBusinessOwner, "b_import_api"), oversized).Code)
+
+	rr := send(suite.signToken(middleware.RoleBusinessOwner, "b_import_api"), body)
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	suite.DB.Model(&models.Booking{}).Where("business_id = ?", "b_import_api").Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func (suite *BookingHandlerTestSuite) TestGetDashboardStatsAPI_RequiresBusinessOwner() {
+	t := suite.T()
+	send := func(token string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/businesses/b_stats_api/stats", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		suite.Router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, send("").Code)
+	assert.Equal(t, http.StatusForbidden, send(suite.signToken(middleware.RoleBusinessOwner, "b_someone_else")).Code)
+	assert.Equal(t, http.StatusForbidden, send(suite.signToken("customer", "")).Code)
+
+	rr := send(suite.signToken(middleware.RoleBusinessOwner, "b_stats_api"))
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	rr = send(suite.signToken("admin", ""))
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+}
+
+func (suite *BookingHandlerTestSuite) TestGetRevenueSummaryAPI_RequiresBusinessOwner() {
+	t := suite.T()
+	send := func(token string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/businesses/b_revenue_api/revenue?from=2030-01-01&to=2030-01-31", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		suite.Router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, send("").Code)
+	assert.Equal(t, http.StatusForbidden, send(suite.signToken(middleware.RoleBusinessOwner, "b_someone_else")).Code)
+
+	rr := send(suite.signToken(middleware.RoleBusinessOwner, "b_revenue_api"))
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+}
+
+func (suite *BookingHandlerTestSuite) TestRescheduleBookingAPI() {
+	t := suite.T()
+	suite.DB.Create(&models.ServiceDefinition{ID: "s_resched_api", BusinessID: "b_resched_api", Name: "Cut", DurationMinutes: 30, IsActive: true})
+	suite.DB.Create(&models.AvailabilityRule{BusinessID: "b_resched_api", DayOfWeek: models.Monday, StartTime: "09:00", EndTime: "12:00"})
+	start := time.Date(2030, 1, 7, 9, 0, 0, 0, time.UTC)
+	booking := models.Booking{
+		BusinessID: "b_resched_api", ServiceID: "s_resched_api", CustomerID: "user-customer",
+		StartTime: start, EndTime: start.Add(30 * time.Minute), Status: models.BookingStatusConfirmed,
+	}
+	suite.DB.Create(&booking)
+	send := func(token, startTime string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/bookings/"+booking.ID+"/reschedule", strings.NewReader(`{"startTime":"`+startTime+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		suite.Router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, send("", "2030-01-07T10:00:00Z").Code)
+	assert.Equal(t, http.StatusForbidden, send(suite.signToken(middleware.RoleBusinessOwner, "b_someone_else"), "2030-01-07T10:00:00Z").Code)
+
+	rr := send(suite.signToken("customer", ""), "2030-01-07T13:00:00Z")
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code, rr.Body.String())
+	var body map[string]interface{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Equal(t, "OUTSIDE_HOURS", body["code"])
+
+	rr = send(suite.signToken(middleware.RoleBusinessOwner, "b_resched_api"), "2030-01-07T10:00:00Z")
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
 }
 
 func TestBookingHandlerTestSuite(t *testing.T) {
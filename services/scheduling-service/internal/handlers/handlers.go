@@ -1,14 +1,24 @@
 package handlers
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings" // Added import
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/nats-io/nats.go"
 	"github.com/redis/go-redis/v9"
+	"github.com/slotwise/scheduling-service/internal/middleware"
+	"github.com/slotwise/scheduling-service/internal/models"
+	"github.com/slotwise/scheduling-service/internal/repository"
 	"github.com/slotwise/scheduling-service/internal/service"
+	"github.com/slotwise/scheduling-service/pkg/events"
 	"github.com/slotwise/scheduling-service/pkg/logger"
 	"gorm.io/gorm"
 )
@@ -21,47 +31,52 @@ type AvailabilityHandler struct {
 
 // HealthHandler handles health check requests
 type HealthHandler struct {
-	db     *gorm.DB
-	redis  *redis.Client
-	nats   *nats.Conn
-	logger *logger.Logger
+	db                    *gorm.DB
+	redis                 *redis.Client
+	nats                  *nats.Conn
+	subscriptions         SubscriptionStatusReporter // Nil when running without NATS
+	requiredSubscriptions []string
+	logger                *logger.Logger
+}
+
+// Deadlines for read paths that fan out into several queries; a slow database should fail
+// the request rather than tie up the handler until the server's write timeout.
+const (
+	slotsRequestTimeout    = 5 * time.Second
+	calendarRequestTimeout = 10 * time.Second
+	snapshotRequestTimeout = 10 * time.Second
+	alternativesTimeout    = 10 * time.Second
+)
+
+// defaultAlternativesCount is how many alternative slots are suggested when count is not given
+const defaultAlternativesCount = 3
+
+// SubscriptionStatusReporter reports the registration state of event subscriptions
+type SubscriptionStatusReporter interface {
+	SubscriptionStatuses() []events.SubscriptionStatus
 }
 
 // GetBooking handles GET /bookings/:id
 func (h *BookingHandler) GetBooking(c *gin.Context) {
 	id := c.Param("id")
-	h.logger.Info("Getting booking", "id", id)
+	h.logger.InfoContext(c.Request.Context(), "Getting booking", "id", id)
 	c.JSON(http.StatusOK, gin.H{"id": id, "status": "confirmed"})
 }
 
 // UpdateBooking handles PUT /bookings/:id
 func (h *BookingHandler) UpdateBooking(c *gin.Context) {
 	id := c.Param("id")
-	h.logger.Info("Updating booking", "id", id)
+	h.logger.InfoContext(c.Request.Context(), "Updating booking", "id", id)
 	c.JSON(http.StatusOK, gin.H{"message": "Booking updated (stub)"})
 }
 
-// CancelBooking handles DELETE /bookings/:id
-func (h *BookingHandler) CancelBooking(c *gin.Context) {
-	id := c.Param("id")
-	h.logger.Info("Canceling booking", "id", id)
-	c.JSON(http.StatusOK, gin.H{"message": "Booking canceled (stub)"})
-}
-
 // ConfirmBooking handles POST /bookings/:id/confirm
 func (h *BookingHandler) ConfirmBooking(c *gin.Context) {
 	id := c.Param("id")
-	h.logger.Info("Confirming booking", "id", id)
+	h.logger.InfoContext(c.Request.Context(), "Confirming booking", "id", id)
 	c.JSON(http.StatusOK, gin.H{"message": "Booking confirmed (stub)"})
 }
 
-// RescheduleBooking handles POST /bookings/:id/reschedule
-func (h *BookingHandler) RescheduleBooking(c *gin.Context) {
-	id := c.Param("id")
-	h.logger.Info("Rescheduling booking", "id", id)
-	c.JSON(http.StatusOK, gin.H{"message": "Booking rescheduled (stub)"})
-}
-
 // NewAvailabilityHandler creates a new availability handler
 func NewAvailabilityHandler(service *service.AvailabilityService, logger *logger.Logger) *AvailabilityHandler {
 	return &AvailabilityHandler{service: service, logger: logger}
@@ -73,7 +88,7 @@ func (h *AvailabilityHandler) GetAvailability(c *gin.Context) {
 	// This existing GetAvailability might be for a different purpose or can be removed if not used.
 	// For now, let's assume it's distinct or will be deprecated.
 	// To avoid confusion, I'll name the new handler method specifically.
-	h.logger.Info("Getting general availability (stub)")
+	h.logger.InfoContext(c.Request.Context(), "Getting general availability (stub)")
 	c.JSON(http.StatusOK, gin.H{"message": "General availability endpoint (stub)"})
 }
 
@@ -85,24 +100,24 @@ func (h *AvailabilityHandler) GetSlotsForBusinessServiceDate(c *gin.Context) {
 	dateStr := c.Query("date") // Expects YYYY-MM-DD
 
 	if businessID == "" || serviceID == "" || dateStr == "" {
-		h.logger.Error("Missing required parameters for GetSlots", "businessId", businessID, "serviceId", serviceID, "date", dateStr)
+		h.logger.ErrorContext(c.Request.Context(), "Missing required parameters for GetSlots", "businessId", businessID, "serviceId", serviceID, "date", dateStr)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "businessId, serviceId, and date are required query parameters"})
 		return
 	}
 
 	date, err := time.Parse("2006-01-02", dateStr)
 	if err != nil {
-		h.logger.Error("Invalid date format for GetSlots", "dateStr", dateStr, "error", err)
+		h.logger.ErrorContext(c.Request.Context(), "Invalid date format for GetSlots", "dateStr", dateStr, "error", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format, please use YYYY-MM-DD"})
 		return
 	}
 
-	h.logger.Info("Getting specific slots for business/service/date", "businessId", businessID, "serviceId", serviceID, "date", dateStr)
+	h.logger.InfoContext(c.Request.Context(), "Getting specific slots for business/service/date", "businessId", businessID, "serviceId", serviceID, "date", dateStr)
 
-	slots, err := h.service.GetAvailableSlots(c.Request.Context(), businessID, serviceID, date)
+	slots, truncated, err := h.service.GetAvailableSlotsCapped(c.Request.Context(), businessID, serviceID, date)
 	if err != nil {
 		// Error logging is done in the service, here we just map to HTTP response
-		if strings.Contains(err.Error(), "not found") { // Basic error checking, could be more robust
+		if isNotFound(err) {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		} else {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve slots: " + err.Error()})
@@ -115,7 +130,7 @@ func (h *AvailabilityHandler) GetSlotsForBusinessServiceDate(c *gin.Context) {
 		return
 	}
 	
-	c.JSON(http.StatusOK, gin.H{"slots": slots})
+	c.JSON(http.StatusOK, gin.H{"slots": slots, "truncated": truncated})
 }
 
 // GetPublicSlotsForService handles GET /api/v1/services/:serviceId/slots
@@ -124,26 +139,43 @@ func (h *AvailabilityHandler) GetPublicSlotsForService(c *gin.Context) {
 	serviceID := c.Param("serviceId")
 	dateStr := c.Query("date")       // Expects YYYY-MM-DD
 	businessID := c.Query("businessId") // Crucial to scope the service
+	group := c.Query("group")           // Optional: "daypart" also returns the slots bucketed by part of day
 
 	if serviceID == "" || dateStr == "" || businessID == "" {
-		h.logger.Error("Missing required parameters for GetPublicSlotsForService", "serviceId", serviceID, "date", dateStr, "businessId", businessID)
+		h.logger.ErrorContext(c.Request.Context(), "Missing required parameters for GetPublicSlotsForService", "serviceId", serviceID, "date", dateStr, "businessId", businessID)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "serviceId, date, and businessId are required"})
 		return
 	}
+	if group != "" && group != slotGroupDaypart {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group, the only supported value is \"daypart\""})
+		return
+	}
 
 	date, err := time.Parse("2006-01-02", dateStr)
 	if err != nil {
-		h.logger.Error("Invalid date format for GetPublicSlotsForService", "dateStr", dateStr, "error", err)
+		h.logger.ErrorContext(c.Request.Context(), "Invalid date format for GetPublicSlotsForService", "dateStr", dateStr, "error", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format, please use YYYY-MM-DD"})
 		return
 	}
 
-	h.logger.Info("Getting public slots for service/date", "serviceId", serviceID, "date", dateStr, "businessId", businessID)
+	h.logger.InfoContext(c.Request.Context(), "Getting public slots for service/date", "serviceId", serviceID, "date", dateStr, "businessId", businessID)
+
+	// Read the version before the slots: if data changes in between, the client sees an older
+	// version than the slots reflect and refetches, rather than caching stale slots as current.
+	ctx, cancel := context.WithTimeout(c.Request.Context(), slotsRequestTimeout)
+	defer cancel()
+
+	availabilityVersion, err := h.service.GetAvailabilityVersion(ctx, businessID)
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to get availability version", "businessId", businessID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve slots"})
+		return
+	}
 
-	// Note: AvailabilityService.GetAvailableSlots takes businessID, serviceID, date
-	slots, err := h.service.GetAvailableSlots(c.Request.Context(), businessID, serviceID, date)
+	// Note: AvailabilityService.GetAvailableSlotsCapped takes businessID, serviceID, date
+	slots, truncated, err := h.service.GetAvailableSlotsCapped(ctx, businessID, serviceID, date)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "does not belong") || strings.Contains(err.Error(), "not active") {
+		if isNotFound(err) {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		} else {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve slots: " + err.Error()})
@@ -151,12 +183,27 @@ func (h *AvailabilityHandler) GetPublicSlotsForService(c *gin.Context) {
 		return
 	}
 	
+	// Holds change slots without bumping the version (and expire on their own), so the tag also covers the slots
+	etag, err := slotsETag(availabilityVersion, slots, truncated)
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to compute slots ETag", "serviceId", serviceID, "error", err)
+	} else {
+		c.Header("ETag", etag)
+		c.Header("Cache-Control", "no-cache") // Clients may keep the slots but must revalidate them
+		if etagMatches(c.GetHeader("If-None-Match"), etag) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+	}
+
 	// For public view, we might want to simplify the TimeSlot struct or ensure it's what frontend expects.
 	// Current service.APISlot: { StartTime time.Time, EndTime time.Time, Available bool, ConflictReason string }
 	// The API contract requires a "lastUpdated" field in the response.
 	response := gin.H{
-		"slots":       slots, // slots will be an array of APISlot
-		"lastUpdated": time.Now().UTC().Format(time.RFC3339),
+		"slots":               slots, // slots will be an array of APISlot
+		"lastUpdated":         time.Now().UTC().Format(time.RFC3339),
+		"availabilityVersion": availabilityVersion, // Compared against a cached page to detect staleness
+		"truncated":           truncated,           // Set when the rules allow more slots than the per-day maximum
 	}
 
 	if len(slots) == 0 {
@@ -165,32 +212,214 @@ func (h *AvailabilityHandler) GetPublicSlotsForService(c *gin.Context) {
 		// Optionally, include a message if desired, but the primary data is the empty slots array.
 		// response["message"] = "No slots available for the selected service and date."
 	}
+	// The flat list stays for clients that don't group
+	if group == slotGroupDaypart {
+		// Dayparts are the business's morning, afternoon and evening, not the server's
+		loc, err := h.service.BusinessLocation(ctx, businessID)
+		if err != nil {
+			h.logger.ErrorContext(c.Request.Context(), "Failed to get business timezone for dayparts", "businessId", businessID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve slots"})
+			return
+		}
+		response["groups"] = h.service.GroupSlotsByDaypart(slots, loc)
+	}
 
 	c.JSON(http.StatusOK, response)
 }
 
+// slotGroupDaypart groups slots into morning, afternoon and evening.
+const slotGroupDaypart = "daypart"
+
+// slotsETag tags a slots response by the business's availability version and a digest of the slots themselves.
+func slotsETag(availabilityVersion int64, slots []service.APISlot, truncated bool) (string, error) {
+	content, err := json.Marshal(struct {
+		Slots     []service.APISlot
+		Truncated bool
+	}{slots, truncated})
+	if err != nil {
+		return "", err
+	}
+	digest := sha256.Sum256(content)
+	return fmt.Sprintf(`"%d-%x"`, availabilityVersion, digest[:8]), nil
+}
+
+// etagMatches reports whether an If-None-Match header lists etag, comparing weakly as RFC 9110 requires.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// isNotFound reports whether err means the requested service or business does not exist or cannot be used,
+// i.e. it is unknown, inactive, or the service belongs to another business.
+func isNotFound(err error) bool {
+	return errors.Is(err, repository.ErrServiceDefinitionNotFound) || errors.Is(err, service.ErrServiceNotActive) ||
+		errors.Is(err, service.ErrServiceNotInBusiness) || errors.Is(err, service.ErrBusinessNotActive)
+}
+
+// HoldSlotRequestDTO is the payload for POST /api/v1/services/:serviceId/hold
+type HoldSlotRequestDTO struct {
+	BusinessID string    `json:"businessId" binding:"required"`
+	StartTime  time.Time `json:"startTime" binding:"required"`
+}
+
+// HoldSlot handles POST /api/v1/services/:serviceId/hold for the authenticated user.
+// The returned hold ID is passed as holdId when the same user creates the booking.
+func (h *AvailabilityHandler) HoldSlot(c *gin.Context) {
+	serviceID := c.Param("serviceId")
+
+	var req HoldSlotRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to bind HoldSlot request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload: " + err.Error()})
+		return
+	}
+
+	hold, err := h.service.HoldSlot(c.Request.Context(), req.BusinessID, serviceID, c.GetString("user_id"), req.StartTime)
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to hold slot", "serviceId", serviceID, "businessId", req.BusinessID, "startTime", req.StartTime, "error", err)
+		if errors.Is(err, service.ErrTooManyHolds) {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": fmt.Sprintf("You can hold at most %d slots at a business at a time", service.MaxHoldsPerUser)})
+		} else if isNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else if errors.Is(err, service.ErrSlotNotAvailable) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hold slot"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, hold)
+}
+
+// SuggestAlternatives handles GET /api/v1/services/:serviceId/alternatives?businessId=...&startTime=...&count=3
+// Returns the available slots closest to startTime (RFC 3339), so the booking form can offer
+// "closest available" when the chosen slot is gone.
+func (h *AvailabilityHandler) SuggestAlternatives(c *gin.Context) {
+	serviceID := c.Param("serviceId")
+	businessID := c.Query("businessId")
+	startStr := c.Query("startTime")
+	if businessID == "" || startStr == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "businessId and startTime are required query parameters"})
+		return
+	}
+
+	desiredStart, err := time.Parse(time.RFC3339, startStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid startTime, please use RFC 3339 (e.g. 2024-01-15T10:00:00Z)"})
+		return
+	}
+
+	count := defaultAlternativesCount
+	if countStr := c.Query("count"); countStr != "" {
+		count, err = strconv.Atoi(countStr)
+		if err != nil || count < 1 || count > service.MaxAlternativeSuggestions {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("count must be between 1 and %d", service.MaxAlternativeSuggestions)})
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), alternativesTimeout)
+	defer cancel()
+
+	slots, err := h.service.SuggestAlternatives(ctx, businessID, serviceID, desiredStart, count)
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to suggest alternative slots", "serviceId", serviceID, "businessId", businessID, "startTime", desiredStart, "error", err)
+		if isNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to suggest alternative slots"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"slots": slots})
+}
+
+// ListAvailabilityRules handles GET /api/v1/availability/rules?businessId=
+// Rules are returned in day order (Monday first), then by start time, ready for the rule editor.
+func (h *AvailabilityHandler) ListAvailabilityRules(c *gin.Context) {
+	businessID := c.Query("businessId")
+	if businessID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "businessId is a required query parameter"})
+		return
+	}
+
+	rules, err := h.service.ListAvailabilityRules(c.Request.Context(), businessID)
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to list availability rules via service", "businessId", businessID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list availability rules"})
+		return
+	}
+	if rules == nil {
+		rules = []models.AvailabilityRule{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rules": rules})
+}
+
+// canManageRules reports whether the caller may change businessID's availability rules, writing a 403 if not.
+// The rule routes are behind RequireAuth and RequireBusinessOwner, which only check the role; the business
+// comes from the request body, query or the stored rule, so ownership is checked here.
+func (h *AvailabilityHandler) canManageRules(c *gin.Context, businessID string) bool {
+	if middleware.CanManageBusiness(c, businessID) {
+		return true
+	}
+	h.logger.WarnContext(c.Request.Context(), "Refused availability rule change for another business", "businessId", businessID, "userId", c.GetString("user_id"))
+	c.JSON(http.StatusForbidden, gin.H{"error": "You can only manage your own business"})
+	return false
+}
+
+// canManageRule looks up rule ruleID and reports whether the caller may change it, writing a 404 or 403 if not.
+func (h *AvailabilityHandler) canManageRule(c *gin.Context, ruleID uint) bool {
+	rule, err := h.service.GetAvailabilityRule(c.Request.Context(), ruleID)
+	if errors.Is(err, repository.ErrAvailabilityRuleNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return false
+	}
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to get availability rule for authorization", "ruleId", ruleID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve availability rule"})
+		return false
+	}
+	return h.canManageRules(c, rule.BusinessID)
+}
+
 // CreateAvailabilityRule handles POST /api/v1/availability/rules
+// The route requires the business's owner or an admin.
 func (h *AvailabilityHandler) CreateAvailabilityRule(c *gin.Context) {
 	var req service.CreateAvailabilityRuleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Error("Failed to bind JSON for CreateAvailabilityRule", "error", err)
+		h.logger.ErrorContext(c.Request.Context(), "Failed to bind JSON for CreateAvailabilityRule", "error", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload: " + err.Error()})
 		return
 	}
 
 	// Basic validation, more can be added in the service layer
 	if req.BusinessID == "" || req.DayOfWeek == "" || req.StartTime == "" || req.EndTime == "" {
-		h.logger.Warn("Missing required fields for CreateAvailabilityRule", "request", req)
+		h.logger.WarnContext(c.Request.Context(), "Missing required fields for CreateAvailabilityRule", "request", req)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing required fields: businessId, dayOfWeek, startTime, endTime"})
 		return
 	}
+	if !h.canManageRules(c, req.BusinessID) {
+		return
+	}
+
+	req.CreatedBy = c.GetString("user_id")
 
-	h.logger.Info("Attempting to create availability rule", "businessId", req.BusinessID, "day", req.DayOfWeek)
+	h.logger.InfoContext(c.Request.Context(), "Attempting to create availability rule", "businessId", req.BusinessID, "day", req.DayOfWeek)
 
 	rule, err := h.service.CreateAvailabilityRule(c.Request.Context(), req)
 	if err != nil {
-		h.logger.Error("Failed to create availability rule via service", "error", err)
-		if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "must be before") { // crude way to check for validation errors
+		h.logger.ErrorContext(c.Request.Context(), "Failed to create availability rule via service", "error", err)
+		if errors.Is(err, repository.ErrDuplicateAvailabilityRule) {
+			c.JSON(http.StatusConflict, gin.H{"error": "An availability rule for this day and time already exists"})
+		} else if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "must be before") { // crude way to check for validation errors
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		} else {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create availability rule: " + err.Error()})
@@ -198,37 +427,185 @@ func (h *AvailabilityHandler) CreateAvailabilityRule(c *gin.Context) {
 		return
 	}
 
-	h.logger.Info("Availability rule created successfully", "ruleId", rule.ID)
+	h.logger.InfoContext(c.Request.Context(), "Availability rule created successfully", "ruleId", rule.ID)
 	c.JSON(http.StatusCreated, rule)
 }
 
+// HasAvailability handles GET /api/v1/businesses/:businessId/has-availability
+// Query params: date (YYYY-MM-DD, defaults to today)
+func (h *AvailabilityHandler) HasAvailability(c *gin.Context) {
+	businessID := c.Param("businessId")
+	date := time.Now().UTC().Truncate(24 * time.Hour)
+	if dateStr := c.Query("date"); dateStr != "" {
+		parsed, err := time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format, please use YYYY-MM-DD"})
+			return
+		}
+		date = parsed
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), slotsRequestTimeout)
+	defer cancel()
+
+	available, err := h.service.HasAvailability(ctx, businessID, date)
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to check business availability", "businessId", businessID, "error", err)
+		if errors.Is(err, service.ErrBusinessNotActive) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check availability"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"businessId": businessID, "date": date.Format("2006-01-02"), "hasAvailability": available})
+}
+
+// ListBusinessServices handles GET /api/v1/businesses/:businessId/services
+// Returns the business's active services, each flagged with whether it has an open slot in the coming days.
+func (h *AvailabilityHandler) ListBusinessServices(c *gin.Context) {
+	businessID := c.Param("businessId")
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), slotsRequestTimeout)
+	defer cancel()
+
+	services, err := h.service.ListServicesWithAvailability(ctx, businessID)
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to list business services", "businessId", businessID, "error", err)
+		if errors.Is(err, service.ErrBusinessNotActive) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list services"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"businessId": businessID, "services": services})
+}
+
+// SearchBusinesses handles GET /api/v1/businesses?category=...&date=YYYY-MM-DD
+// Lists the businesses offering a service category that have an open slot on the date (today by default).
+func (h *AvailabilityHandler) SearchBusinesses(c *gin.Context) {
+	category := c.Query("category")
+	if strings.TrimSpace(category) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "category is a required query parameter"})
+		return
+	}
+	date := time.Now().UTC().Truncate(24 * time.Hour)
+	if dateStr := c.Query("date"); dateStr != "" {
+		parsed, err := time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format, please use YYYY-MM-DD"})
+			return
+		}
+		date = parsed
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > service.MaxMarketplaceSearchLimit {
+		limit = 10
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), slotsRequestTimeout)
+	defer cancel()
+
+	businesses, hasMore, err := h.service.SearchBusinessesWithAvailability(ctx, category, date, limit, (page-1)*limit)
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to search businesses with availability", "category", category, "error", err)
+		if errors.Is(err, context.DeadlineExceeded) {
+			c.JSON(http.StatusGatewayTimeout, gin.H{"error": "Timed out searching businesses, please narrow the search"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search businesses"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":     businesses,
+		"category": category,
+		"date":     date.Format("2006-01-02"),
+		"pagination": gin.H{
+			"page":    page,
+			"limit":   limit,
+			"hasMore": hasMore,
+		},
+	})
+}
+
+// AvailabilitySnapshotRequest is the body of POST /api/v1/availability/snapshot.
+type AvailabilitySnapshotRequest struct {
+	BusinessIDs []string `json:"businessIds" binding:"required"`
+	Date        string   `json:"date"` // YYYY-MM-DD, defaults to today
+}
+
+// GetAvailabilitySnapshot handles POST /api/v1/availability/snapshot
+// Returns whether each business has an open slot on the date, and its earliest one.
+func (h *AvailabilityHandler) GetAvailabilitySnapshot(c *gin.Context) {
+	var req AvailabilitySnapshotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload: " + err.Error()})
+		return
+	}
+
+	date := time.Now().UTC().Truncate(24 * time.Hour)
+	if req.Date != "" {
+		parsed, err := time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format, please use YYYY-MM-DD"})
+			return
+		}
+		date = parsed
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), snapshotRequestTimeout)
+	defer cancel()
+
+	snapshots, err := h.service.GetAvailabilitySnapshot(ctx, req.BusinessIDs, date)
+	if err != nil {
+		if strings.Contains(err.Error(), "cannot be empty") || strings.Contains(err.Error(), "too many") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			h.logger.ErrorContext(c.Request.Context(), "Failed to build availability snapshot", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build availability snapshot"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"date": date.Format("2006-01-02"), "businesses": snapshots})
+}
+
 // GetBusinessCalendarHandler handles GET /api/v1/businesses/{businessId}/calendar
-// Query params: start, end (YYYY-MM-DD)
+// Query params: start, end (YYYY-MM-DD), granularity (day|week|month, default day)
 func (h *AvailabilityHandler) GetBusinessCalendarHandler(c *gin.Context) {
 	businessID := c.Param("businessId")
 	startDateStr := c.Query("start")
 	endDateStr := c.Query("end")
+	granularity := service.CalendarGranularity(c.DefaultQuery("granularity", string(service.CalendarGranularityDay)))
 
 	if businessID == "" {
-		h.logger.Warn("GetBusinessCalendarHandler called with no businessId")
+		h.logger.WarnContext(c.Request.Context(), "GetBusinessCalendarHandler called with no businessId")
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Business ID is required"})
 		return
 	}
 	if startDateStr == "" || endDateStr == "" {
-		h.logger.Warn("GetBusinessCalendarHandler called without start or end date", "businessId", businessID)
+		h.logger.WarnContext(c.Request.Context(), "GetBusinessCalendarHandler called without start or end date", "businessId", businessID)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Start and end dates are required (YYYY-MM-DD)"})
 		return
 	}
 
 	startDate, err := time.ParseInLocation("2006-01-02", startDateStr, time.Local) // Assuming server local time for date parsing
 	if err != nil {
-		h.logger.Error("Invalid start date format for GetBusinessCalendarHandler", "startDate", startDateStr, "error", err)
+		h.logger.ErrorContext(c.Request.Context(), "Invalid start date format for GetBusinessCalendarHandler", "startDate", startDateStr, "error", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start date format, please use YYYY-MM-DD"})
 		return
 	}
 	endDate, err := time.ParseInLocation("2006-01-02", endDateStr, time.Local) // Assuming server local time
 	if err != nil {
-		h.logger.Error("Invalid end date format for GetBusinessCalendarHandler", "endDate", endDateStr, "error", err)
+		h.logger.ErrorContext(c.Request.Context(), "Invalid end date format for GetBusinessCalendarHandler", "endDate", endDateStr, "error", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end date format, please use YYYY-MM-DD"})
 		return
 	}
@@ -237,16 +614,19 @@ func (h *AvailabilityHandler) GetBusinessCalendarHandler(c *gin.Context) {
 	// The service layer currently handles this by adding 23h59m59s for booking queries.
 	// For the date range itself, startDate and endDate are sufficient.
 
-	h.logger.Info("Getting business calendar", "businessId", businessID, "start", startDateStr, "end", endDateStr)
+	h.logger.InfoContext(c.Request.Context(), "Getting business calendar", "businessId", businessID, "start", startDateStr, "end", endDateStr)
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), calendarRequestTimeout)
+	defer cancel()
 
-	calendarResponse, err := h.service.GetBusinessCalendar(c.Request.Context(), businessID, startDate, endDate)
+	calendarResponse, err := h.service.GetBusinessCalendar(ctx, businessID, startDate, endDate, granularity)
 	if err != nil {
-		h.logger.Error("Failed to get business calendar from service", "businessId", businessID, "error", err)
+		h.logger.ErrorContext(c.Request.Context(), "Failed to get business calendar from service", "businessId", businessID, "error", err)
 		// Distinguish between not found / bad input vs internal errors
-		if strings.Contains(err.Error(), "cannot be after") || strings.Contains(err.Error(), "cannot be empty") {
+		if strings.Contains(err.Error(), "cannot be after") || strings.Contains(err.Error(), "cannot be empty") || strings.Contains(err.Error(), "invalid granularity") {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve business calendar: " + err.Error()})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve business calendar"})
 		}
 		return
 	}
@@ -255,15 +635,202 @@ func (h *AvailabilityHandler) GetBusinessCalendarHandler(c *gin.Context) {
 }
 
 
-// UpdateAvailabilityRule handles PUT /availability/rules/:id
+// SetAcceptingBookingsRequest is the body of a pause/resume bookings request.
+// AcceptingBookings is a pointer so that an explicit false is distinguishable from a missing field.
+type SetAcceptingBookingsRequest struct {
+	AcceptingBookings *bool `json:"acceptingBookings" binding:"required"`
+}
+
+// SetAcceptingBookings handles PUT /api/v1/businesses/:businessId/accepting-bookings
+// Pausing stops new bookings and hides slots; existing bookings and rules are untouched.
+// The route requires the business's owner or an admin.
+func (h *AvailabilityHandler) SetAcceptingBookings(c *gin.Context) {
+	businessID := c.Param("businessId")
+
+	var req SetAcceptingBookingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to bind JSON for SetAcceptingBookings", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload: " + err.Error()})
+		return
+	}
+
+	business, err := h.service.SetAcceptingBookings(c.Request.Context(), businessID, *req.AcceptingBookings)
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to set accepting bookings via service", "businessId", businessID, "error", err)
+		if errors.Is(err, service.ErrBusinessNotActive) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else if strings.Contains(err.Error(), "cannot be empty") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update business"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, business)
+}
+
+// GetAvailabilityRule handles GET /api/v1/availability/rules/:id
+func (h *AvailabilityHandler) GetAvailabilityRule(c *gin.Context) {
+	ruleID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rule ID"})
+		return
+	}
+
+	rule, err := h.service.GetAvailabilityRule(c.Request.Context(), uint(ruleID))
+	if err != nil {
+		if errors.Is(err, repository.ErrAvailabilityRuleNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else {
+			h.logger.ErrorContext(c.Request.Context(), "Failed to get availability rule via service", "ruleId", ruleID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve availability rule"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// UpdateAvailabilityRule handles PATCH /api/v1/availability/rules/:id
+// Only the fields present in the body are changed; the resulting rule is re-validated.
+// The route requires the owner of the rule's business or an admin.
 func (h *AvailabilityHandler) UpdateAvailabilityRule(c *gin.Context) {
-	id := c.Param("id")
-	// TODO: Implement actual update logic
-	// 1. Bind JSON to an update request struct
-	// 2. Call a service method e.g., h.service.UpdateAvailabilityRule(ctx, id, updateReq)
-	// 3. Return updated rule or error
-	h.logger.Info("Updating availability rule (stub)", "id", id)
-	c.JSON(http.StatusOK, gin.H{"message": "Availability rule updated (stub) - NOT IMPLEMENTED", "id": id})
+	ruleID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rule ID"})
+		return
+	}
+	if !h.canManageRule(c, uint(ruleID)) {
+		return
+	}
+
+	var req service.UpdateAvailabilityRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to bind JSON for UpdateAvailabilityRule", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload: " + err.Error()})
+		return
+	}
+
+	req.UpdatedBy = c.GetString("user_id")
+
+	rule, err := h.service.UpdateAvailabilityRule(c.Request.Context(), uint(ruleID), req)
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to update availability rule via service", "ruleId", ruleID, "error", err)
+		if errors.Is(err, repository.ErrAvailabilityRuleNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else if errors.Is(err, repository.ErrDuplicateAvailabilityRule) {
+			c.JSON(http.StatusConflict, gin.H{"error": "An availability rule for this day and time already exists"})
+		} else if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "must be before") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update availability rule"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// SetAvailabilityRuleActiveRequest is the body of PUT /api/v1/availability/rules/:id/active
+type SetAvailabilityRuleActiveRequest struct {
+	Active *bool `json:"active" binding:"required"`
+}
+
+// SetAvailabilityRuleActive handles PUT /api/v1/availability/rules/:id/active
+// Deactivating keeps the rule but stops it producing slots; it can be reactivated later.
+// The route requires the owner of the rule's business or an admin.
+func (h *AvailabilityHandler) SetAvailabilityRuleActive(c *gin.Context) {
+	ruleID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rule ID"})
+		return
+	}
+	if !h.canManageRule(c, uint(ruleID)) {
+		return
+	}
+
+	var req SetAvailabilityRuleActiveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload: " + err.Error()})
+		return
+	}
+
+	rule, err := h.service.SetAvailabilityRuleActive(c.Request.Context(), uint(ruleID), *req.Active, c.GetString("user_id"))
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to set availability rule active state via service", "ruleId", ruleID, "error", err)
+		if errors.Is(err, repository.ErrAvailabilityRuleNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update availability rule"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeleteAvailabilityRulesForDay handles DELETE /api/v1/availability/rules?businessId=&day=
+// All of the business's rules for that day are removed at once. The route requires the business's owner or an admin.
+func (h *AvailabilityHandler) DeleteAvailabilityRulesForDay(c *gin.Context) {
+	businessID := c.Query("businessId")
+	day := c.Query("day")
+	if businessID == "" || day == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "businessId and day are required query parameters"})
+		return
+	}
+	if !h.canManageRules(c, businessID) {
+		return
+	}
+
+	deleted, err := h.service.DeleteRulesForDay(c.Request.Context(), businessID, models.DayOfWeekString(strings.ToUpper(day)))
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			h.logger.ErrorContext(c.Request.Context(), "Failed to delete availability rules for day via service", "businessId", businessID, "day", day, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete availability rules"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deleted": deleted})
+}
+
+// PreviewRuleChangeRequest is the body of POST /api/v1/availability/rules/preview.
+type PreviewRuleChangeRequest struct {
+	BusinessID string                             `json:"businessId" binding:"required"`
+	Rules      []service.ProposedAvailabilityRule `json:"rules"`
+}
+
+// PreviewRuleChange handles POST /api/v1/availability/rules/preview
+// It lists the upcoming bookings that the proposed weekly rules would leave outside opening hours, without saving them.
+// The route requires the business's owner or an admin, since the preview shows the business's bookings.
+func (h *AvailabilityHandler) PreviewRuleChange(c *gin.Context) {
+	var req PreviewRuleChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+	if !h.canManageRules(c, req.BusinessID) {
+		return
+	}
+	for i := range req.Rules {
+		req.Rules[i].DayOfWeek = models.DayOfWeekString(strings.ToUpper(string(req.Rules[i].DayOfWeek)))
+	}
+
+	bookings, err := h.service.PreviewRuleChange(c.Request.Context(), req.BusinessID, req.Rules)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "cannot be empty") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			h.logger.ErrorContext(c.Request.Context(), "Failed to preview availability rule change via service", "businessId", req.BusinessID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to preview availability change"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"businessId": req.BusinessID, "outOfHoursBookings": bookings})
 }
 
 // DeleteAvailabilityRule handles DELETE /availability/rules/:id
@@ -272,7 +839,7 @@ func (h *AvailabilityHandler) DeleteAvailabilityRule(c *gin.Context) {
 	// TODO: Implement actual delete logic
 	// 1. Call a service method e.g., h.service.DeleteAvailabilityRule(ctx, id)
 	// 2. Return success (e.g., 204 No Content) or error
-	h.logger.Info("Deleting availability rule (stub)", "id", id)
+	h.logger.InfoContext(c.Request.Context(), "Deleting availability rule (stub)", "id", id)
 	c.JSON(http.StatusOK, gin.H{"message": "Availability rule deleted (stub) - NOT IMPLEMENTED", "id": id})
 }
 
@@ -282,7 +849,7 @@ func (h *AvailabilityHandler) CreateAvailabilityException(c *gin.Context) {
 	// 1. Bind JSON to a create exception request struct
 	// 2. Call a service method e.g., h.service.CreateAvailabilityException(ctx, createReq)
 	// 3. Return created exception or error
-	h.logger.Info("Creating availability exception (stub)")
+	h.logger.InfoContext(c.Request.Context(), "Creating availability exception (stub)")
 	c.JSON(http.StatusCreated, gin.H{"message": "Availability exception created (stub) - NOT IMPLEMENTED"})
 }
 
@@ -290,7 +857,7 @@ func (h *AvailabilityHandler) CreateAvailabilityException(c *gin.Context) {
 func (h *AvailabilityHandler) UpdateAvailabilityException(c *gin.Context) {
 	id := c.Param("id")
 	// TODO: Implement actual exception update logic
-	h.logger.Info("Updating availability exception (stub)", "id", id)
+	h.logger.InfoContext(c.Request.Context(), "Updating availability exception (stub)", "id", id)
 	c.JSON(http.StatusOK, gin.H{"message": "Availability exception updated (stub) - NOT IMPLEMENTED", "id": id})
 }
 
@@ -298,13 +865,21 @@ func (h *AvailabilityHandler) UpdateAvailabilityException(c *gin.Context) {
 func (h *AvailabilityHandler) DeleteAvailabilityException(c *gin.Context) {
 	id := c.Param("id")
 	// TODO: Implement actual exception delete logic
-	h.logger.Info("Deleting availability exception (stub)", "id", id)
+	h.logger.InfoContext(c.Request.Context(), "Deleting availability exception (stub)", "id", id)
 	c.JSON(http.StatusOK, gin.H{"message": "Availability exception deleted (stub) - NOT IMPLEMENTED", "id": id})
 }
 
-// NewHealthHandler creates a new health handler
-func NewHealthHandler(db *gorm.DB, redis *redis.Client, nats *nats.Conn, logger *logger.Logger) *HealthHandler {
-	return &HealthHandler{db: db, redis: redis, nats: nats, logger: logger}
+// NewHealthHandler creates a new health handler.
+// Readiness fails if any of requiredSubscriptions is not active on subscriptions.
+func NewHealthHandler(db *gorm.DB, redis *redis.Client, nats *nats.Conn, subscriptions SubscriptionStatusReporter, requiredSubscriptions []string, logger *logger.Logger) *HealthHandler {
+	return &HealthHandler{
+		db:                    db,
+		redis:                 redis,
+		nats:                  nats,
+		subscriptions:         subscriptions,
+		requiredSubscriptions: requiredSubscriptions,
+		logger:                logger,
+	}
 }
 
 // Health handles GET /health
@@ -312,10 +887,46 @@ func (h *HealthHandler) Health(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "ok", "service": "scheduling-service"})
 }
 
+// subscriptionHealth is the readiness detail of one event subscription
+type subscriptionHealth struct {
+	Active   bool   `json:"active"`
+	Required bool   `json:"required"`
+	Error    string `json:"error,omitempty"`
+}
+
 // Ready handles GET /health/ready
+// The service is degraded if a required event subscription failed to register or was closed.
 func (h *HealthHandler) Ready(c *gin.Context) {
-	// TODO: Add actual readiness checks
-	c.JSON(http.StatusOK, gin.H{"status": "ready"})
+	// TODO: Add database and Redis readiness checks
+	if h.subscriptions == nil {
+		c.JSON(http.StatusOK, gin.H{"status": "ready"})
+		return
+	}
+
+	subscriptions := make(map[string]subscriptionHealth)
+	for _, status := range h.subscriptions.SubscriptionStatuses() {
+		subscriptions[status.Subject] = subscriptionHealth{Active: status.Active, Error: status.Error}
+	}
+
+	ready := true
+	for _, subject := range h.requiredSubscriptions {
+		sub, ok := subscriptions[subject]
+		if !ok {
+			sub.Error = "not registered"
+		}
+		sub.Required = true
+		subscriptions[subject] = sub
+		if !sub.Active {
+			ready = false
+			h.logger.WarnContext(c.Request.Context(), "Required event subscription is not active", "subject", subject, "error", sub.Error)
+		}
+	}
+
+	if !ready {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "degraded", "subscriptions": subscriptions})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ready", "subscriptions": subscriptions})
 }
 
 // Live handles GET /health/live
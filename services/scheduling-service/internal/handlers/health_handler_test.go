@@ -0,0 +1,145 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/slotwise/scheduling-service/internal/handlers"
+	"github.com/slotwise/scheduling-service/internal/realtime"
+	"github.com/slotwise/scheduling-service/pkg/events"
+	"github.com/slotwise/scheduling-service/pkg/logger"
+	"github.com/slotwise/scheduling-service/pkg/scheduler"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSubscriptionReporter returns fixed subscription statuses
+type fakeSubscriptionReporter []events.SubscriptionStatus
+
+func (f fakeSubscriptionReporter) SubscriptionStatuses() []events.SubscriptionStatus {
+	return f
+}
+
+type readyResponse struct {
+	Status        string `json:"status"`
+	Subscriptions map[string]struct {
+		Active   bool   `json:"active"`
+		Required bool   `json:"required"`
+		Error    string `json:"error"`
+	} `json:"subscriptions"`
+}
+
+func getReady(t *testing.T, reporter handlers.SubscriptionStatusReporter) (int, readyResponse) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	healthHandler := handlers.NewHealthHandler(nil, nil, nil, reporter, []string{"payment.succeeded", "business.service.created"}, logger.New("debug"))
+	router.GET("/health/ready", healthHandler.Ready)
+
+	req, _ := http.NewRequest(http.MethodGet, "/health/ready", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	var resp readyResponse
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	return rr.Code, resp
+}
+
+func TestHealthReady_AllSubscriptionsActive(t *testing.T) {
+	code, resp := getReady(t, fakeSubscriptionReporter{
+		{Subject: "business.service.created", Active: true},
+		{Subject: "payment.succeeded", Active: true},
+		{Subject: "user.created", Active: true},
+	})
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ready", resp.Status)
+	assert.Len(t, resp.Subscriptions, 3)
+	assert.True(t, resp.Subscriptions["payment.succeeded"].Required)
+	assert.False(t, resp.Subscriptions["user.created"].Required)
+}
+
+func TestHealthReady_FailedSubscriptionIsDegraded(t *testing.T) {
+	code, resp := getReady(t, fakeSubscriptionReporter{
+		{Subject: "business.service.created", Active: true},
+		{Subject: "payment.succeeded", Error: "nats: connection closed"},
+	})
+
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "degraded", resp.Status)
+	assert.False(t, resp.Subscriptions["payment.succeeded"].Active)
+	assert.Equal(t, "nats: connection closed", resp.Subscriptions["payment.succeeded"].Error)
+	assert.True(t, resp.Subscriptions["business.service.created"].Active)
+}
+
+func TestHealthReady_MissingRequiredSubscriptionIsDegraded(t *testing.T) {
+	code, resp := getReady(t, fakeSubscriptionReporter{
+		{Subject: "payment.succeeded", Active: true},
+	})
+
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "degraded", resp.Status)
+	assert.Equal(t, "not registered", resp.Subscriptions["business.service.created"].Error)
+}
+
+// fakeJobStatsReporter returns fixed job stats
+type fakeJobStatsReporter []scheduler.JobStats
+
+func (f fakeJobStatsReporter) JobStats() []scheduler.JobStats {
+	return f
+}
+
+func TestMetrics_ReportsJobStats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/metrics", handlers.NewMetricsHandler(fakeJobStatsReporter{
+		{Name: scheduler.JobExpirePendingBookings, Runs: 4, LastProcessed: 2, ProcessedTotal: 7},
+		{Name: scheduler.JobDailyDigests, Stale: true},
+	}, nil, logger.New("debug")).Metrics)
+
+	req, _ := http.NewRequest(http.MethodGet, "/metrics", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var resp struct {
+		Jobs      []scheduler.JobStats `json:"jobs"`
+		StaleJobs int                  `json:"staleJobs"`
+	}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, 1, resp.StaleJobs)
+	if assert.Len(t, resp.Jobs, 2) {
+		assert.Equal(t, int64(7), resp.Jobs[0].ProcessedTotal)
+		assert.Equal(t, 2, resp.Jobs[0].LastProcessed)
+	}
+}
+
+func TestMetrics_ReportsRealtimeCounters(t *testing.T) {
+	manager := realtime.NewSubscriptionManager(logger.New("debug"), nil)
+	slow := &realtime.Client{ID: "slow", Send: make(chan []byte, 1)}
+	manager.RegisterClient(slow, "biz_metrics")
+	for i := 0; i < 3; i++ {
+		manager.SendToBusiness("biz_metrics", []byte(`{"type":"availability_updated"}`))
+	}
+	assert.ErrorIs(t, manager.HandleEvent(events.BookingConfirmedEvent, []byte(`{"businessId":`)), realtime.ErrMalformedEvent)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/metrics", handlers.NewMetricsHandler(fakeJobStatsReporter{}, manager, logger.New("debug")).Metrics)
+
+	req, _ := http.NewRequest(http.MethodGet, "/metrics", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var resp struct {
+		Realtime struct {
+			DroppedMessages int64 `json:"droppedMessages"`
+			MalformedEvents int64 `json:"malformedEvents"`
+		} `json:"realtime"`
+	}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, int64(2), resp.Realtime.DroppedMessages)
+	assert.Equal(t, int64(1), resp.Realtime.MalformedEvents)
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/slotwise/scheduling-service/pkg/logger"
+	"github.com/slotwise/scheduling-service/pkg/scheduler"
+)
+
+// JobStatsReporter reports the runs of background jobs; satisfied by *scheduler.Scheduler.
+type JobStatsReporter interface {
+	JobStats() []scheduler.JobStats
+}
+
+// RealtimeStatsReporter reports the WebSocket hub's message counters; satisfied by *realtime.SubscriptionManager.
+type RealtimeStatsReporter interface {
+	DroppedMessages() int64
+	MalformedEvents() int64
+}
+
+// MetricsHandler serves operational metrics.
+type MetricsHandler struct {
+	jobs      JobStatsReporter
+	realtime  RealtimeStatsReporter
+	startedAt time.Time
+	logger    *logger.Logger
+}
+
+// NewMetricsHandler creates a new MetricsHandler.
+// realtime may be nil when realtime updates are disabled (no NATS connection).
+func NewMetricsHandler(jobs JobStatsReporter, realtime RealtimeStatsReporter, logger *logger.Logger) *MetricsHandler {
+	return &MetricsHandler{
+		jobs:      jobs,
+		realtime:  realtime,
+		startedAt: time.Now(),
+		logger:    logger,
+	}
+}
+
+// Metrics handles GET /metrics
+// Stale jobs are logged on every scrape so log-based alerts fire without polling the endpoint separately.
+func (h *MetricsHandler) Metrics(c *gin.Context) {
+	jobs := h.jobs.JobStats()
+	staleJobs := 0
+	for _, job := range jobs {
+		if job.Stale {
+			staleJobs++
+			h.logger.WarnContext(c.Request.Context(), "Background job is stale", "job", job.Name, "lastRunAt", job.LastRunAt, "runningSince", job.RunningSince)
+		}
+	}
+
+	metrics := gin.H{
+		"service":       "scheduling-service",
+		"uptimeSeconds": time.Since(h.startedAt).Seconds(),
+		"jobs":          jobs,
+		"staleJobs":     staleJobs,
+	}
+	if h.realtime != nil {
+		// Growing counts mean WebSocket clients are missing updates, for drop-rate alerts
+		metrics["realtime"] = gin.H{
+			"droppedMessages": h.realtime.DroppedMessages(),
+			"malformedEvents": h.realtime.MalformedEvents(),
+		}
+	}
+	c.JSON(http.StatusOK, metrics)
+}
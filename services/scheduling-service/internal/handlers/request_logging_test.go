@@ -0,0 +1,48 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/slotwise/scheduling-service/internal/handlers"
+	"github.com/slotwise/scheduling-service/internal/middleware"
+	"github.com/slotwise/scheduling-service/internal/realtime"
+	"github.com/slotwise/scheduling-service/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlerLogLinesCarryRequestID(t *testing.T) {
+	var logs bytes.Buffer
+	testLogger := logger.NewWithWriter("debug", &logs)
+	adminHandler := handlers.NewAdminHandler(realtime.NewSubscriptionManager(testLogger, nil), testLogger)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.RequestID())
+	router.PUT("/callbacks/:businessId", adminHandler.RegisterCallback)
+
+	req := httptest.NewRequest(http.MethodPut, "/callbacks/biz_logs", strings.NewReader(`{"url":"https://93.184.216.34/slotwise"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Request-ID", "req-trace-1")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var handlerLine map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		if entry["msg"] == "Realtime callback registered by admin" {
+			handlerLine = entry
+		}
+	}
+	require.NotNil(t, handlerLine, "The handler should have logged the registration")
+	assert.Equal(t, "req-trace-1", handlerLine["request_id"])
+	assert.Equal(t, "biz_logs", handlerLine["businessId"])
+}
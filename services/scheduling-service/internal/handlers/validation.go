@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// This file is kept identical in the auth and scheduling services. The services are separate Go modules
+// with no shared library, so a change to one copy must be made to the other.
+
+// validationErrorDetails translates binding validation failures into a map of
+// JSON field name to message, so forms can flag every invalid field at once.
+// It returns nil when err is not a validation failure (e.g. malformed JSON).
+func validationErrorDetails(err error, req interface{}) map[string]string {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return nil
+	}
+
+	fields := make(map[string]string, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		fields[jsonFieldPath(req, fieldErr.StructNamespace())] = validationMessage(fieldErr)
+	}
+	return fields
+}
+
+// jsonFieldPath turns a validator namespace such as "CreateBookingRequestDTO.Guest.Email"
+// into the JSON path of the field ("guest.email"), falling back to Go field names
+// where there is no json tag.
+func jsonFieldPath(req interface{}, namespace string) string {
+	parts := strings.Split(namespace, ".")[1:] // Drop the root type name
+	t := reflect.TypeOf(req)
+	names := make([]string, 0, len(parts))
+	for _, part := range parts {
+		goName := part
+		if i := strings.IndexByte(part, '['); i >= 0 {
+			goName = part[:i] // Slice or map elements, e.g. "Items[0]"
+		}
+
+		for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Map) {
+			t = t.Elem()
+		}
+		if t == nil || t.Kind() != reflect.Struct {
+			names = append(names, part)
+			t = nil
+			continue
+		}
+		field, ok := t.FieldByName(goName)
+		if !ok {
+			names = append(names, part)
+			t = nil
+			continue
+		}
+		t = field.Type
+
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			name = goName
+		}
+		names = append(names, name+part[len(goName):])
+	}
+	return strings.Join(names, ".")
+}
+
+// validationMessage describes a single failed binding rule
+func validationMessage(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "min":
+		return fmt.Sprintf("must be at least %s", sizeLimit(fieldErr))
+	case "max":
+		return fmt.Sprintf("must be at most %s", sizeLimit(fieldErr))
+	case "len":
+		return fmt.Sprintf("must be exactly %s", sizeLimit(fieldErr))
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", fieldErr.Param())
+	default:
+		return fmt.Sprintf("failed the %q rule", fieldErr.Tag())
+	}
+}
+
+// sizeLimit phrases the parameter of a min, max or len rule for the kind of field it applies to:
+// a length for strings, a count for collections and a plain value for numbers.
+func sizeLimit(fieldErr validator.FieldError) string {
+	limit := fieldErr.Param()
+	var unit string
+	switch fieldErr.Kind() {
+	case reflect.String:
+		unit = "character"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		unit = "item"
+	default:
+		return limit
+	}
+	if limit != "1" {
+		unit += "s"
+	}
+	return limit + " " + unit
+}
@@ -0,0 +1,40 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/slotwise/scheduling-service/internal/handlers"
+	"github.com/slotwise/scheduling-service/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateBookingReportsEveryInvalidField(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/bookings", handlers.NewBookingHandler(nil, logger.New("error")).CreateBooking)
+
+	req := httptest.NewRequest(http.MethodPost, "/bookings", bytes.NewBufferString(`{"serviceId":"svc-1","guest":{"email":"not-an-email"}}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var resp struct {
+		Error   string            `json:"error"`
+		Details map[string]string `json:"details"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "Request validation failed", resp.Error)
+	assert.Equal(t, map[string]string{
+		"businessId":  "is required",
+		"startTime":   "is required",
+		"guest.name":  "is required",
+		"guest.email": "must be a valid email address",
+	}, resp.Details)
+}
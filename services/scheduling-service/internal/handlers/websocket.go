@@ -1,12 +1,17 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
+	"github.com/slotwise/scheduling-service/internal/middleware"
 	"github.com/slotwise/scheduling-service/internal/realtime"
 	"github.com/slotwise/scheduling-service/pkg/logger"
 )
@@ -22,34 +27,62 @@ const (
 	maxMessageSize = 512
 )
 
+// OriginPolicy controls which browser origins may open WebSocket connections.
+type OriginPolicy struct {
+	AllowedOrigins []string // e.g. "https://app.slotwise.com"
+	Enforce        bool     // Reject origins not in AllowedOrigins; off in development
+}
+
 // WebSocketHandler handles WebSocket connections.
 type WebSocketHandler struct {
-	Upgrader websocket.Upgrader
-	Manager  *realtime.SubscriptionManager
-	Logger   *logger.Logger
+	Upgrader     websocket.Upgrader
+	Manager      *realtime.SubscriptionManager
+	Logger       *logger.Logger
+	originPolicy OriginPolicy
+	tokens       *middleware.TokenValidator // Validates the access tokens of customer subscriptions
 }
 
 // NewWebSocketHandler creates a new WebSocketHandler.
-func NewWebSocketHandler(manager *realtime.SubscriptionManager, logger *logger.Logger) *WebSocketHandler {
-	return &WebSocketHandler{
-		Upgrader: websocket.Upgrader{
-			ReadBufferSize:  1024,
-			WriteBufferSize: 1024,
-			CheckOrigin: func(r *http.Request) bool {
-				// Allow all connections for now. In production, you might want to check r.Header.Get("Origin")
-				// against a whitelist of allowed origins.
-				return true
-			},
-		},
-		Manager: manager,
-		Logger:  logger,
+func NewWebSocketHandler(manager *realtime.SubscriptionManager, originPolicy OriginPolicy, tokens *middleware.TokenValidator, logger *logger.Logger) *WebSocketHandler {
+	h := &WebSocketHandler{
+		Manager:      manager,
+		Logger:       logger,
+		originPolicy: originPolicy,
+		tokens:       tokens,
+	}
+	h.Upgrader = websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin:     h.checkOrigin,
+	}
+	return h
+}
+
+// checkOrigin allows requests whose Origin header is in the allowlist. Requests without an
+// Origin header come from non-browser clients and are not subject to cross-site hijacking.
+func (h *WebSocketHandler) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" || !h.originPolicy.Enforce {
+		return true
+	}
+	origin = strings.TrimSuffix(origin, "/")
+	for _, allowed := range h.originPolicy.AllowedOrigins {
+		if strings.EqualFold(origin, strings.TrimSuffix(allowed, "/")) {
+			return true
+		}
 	}
+	h.Logger.Warn("Rejected WebSocket connection from disallowed origin", "origin", origin, "remoteAddr", r.RemoteAddr)
+	return false
 }
 
 // SubscriptionMessage defines the structure for messages from the client.
 type SubscriptionMessage struct {
 	Type       string `json:"type"`
 	BusinessID string `json:"businessId,omitempty"`
+	// CustomerID and Token subscribe to one customer's bookings ("subscribe_customer").
+	// Browsers cannot set headers on WebSocket connections, so the access token travels in the message.
+	CustomerID string `json:"customerId,omitempty"`
+	Token      string `json:"token,omitempty"`
 	// Add other fields like serviceId, date filters if needed for more granular subscriptions
 }
 
@@ -64,10 +97,11 @@ func (h *WebSocketHandler) HandleConnections(c *gin.Context) {
 	h.Logger.Info("WebSocket connection upgraded")
 
 	client := &realtime.Client{
-		ID:      realtime.GenerateClientID(),
-		Conn:    conn,
-		Send:    make(chan []byte, 256), // Buffered channel
-		Manager: h.Manager,
+		ID:          realtime.GenerateClientID(),
+		Conn:        conn,
+		Send:        make(chan []byte, 256), // Buffered channel
+		ConnectedAt: time.Now().UTC(),
+		Manager:     h.Manager,
 	}
 
 	// Register client with the manager (via manager's register channel)
@@ -136,6 +170,13 @@ func (h *WebSocketHandler) readPump(client *realtime.Client) {
 				h.Logger.Warn("Subscription message missing businessId", "clientId", client.ID)
 				// Optionally send error back to client
 			}
+		case "subscribe_customer":
+			if err := h.authorizeCustomer(context.Background(), msg.Token, msg.CustomerID); err != nil {
+				h.Logger.Warn("Rejected customer subscription", "clientId", client.ID, "customerId", msg.CustomerID, "error", err)
+				h.sendError(client, "Not authorized to subscribe to this customer's bookings")
+				continue
+			}
+			client.Manager.SubscribeCustomer(client, msg.CustomerID)
 		// Handle other message types if needed, e.g., "unsubscribe"
 		default:
 			h.Logger.Info("Unknown message type from client", "clientId", client.ID, "type", msg.Type)
@@ -148,6 +189,31 @@ func (h *WebSocketHandler) readPump(client *realtime.Client) {
 	}
 }
 
+// authorizeCustomer checks that token is a valid access token for customerID; admins may watch any customer.
+func (h *WebSocketHandler) authorizeCustomer(ctx context.Context, token, customerID string) error {
+	if customerID == "" {
+		return errors.New("customerId is required")
+	}
+	claims, err := h.tokens.ParseAccessToken(ctx, token)
+	if err != nil {
+		return err
+	}
+	if claims.Subject != customerID && claims.Role != middleware.RoleAdmin {
+		return fmt.Errorf("token subject %s does not match customer", claims.Subject)
+	}
+	return nil
+}
+
+// sendError sends an error message to the client.
+func (h *WebSocketHandler) sendError(client *realtime.Client, message string) {
+	data, err := json.Marshal(realtime.WebSocketMessage{Type: "error", Payload: gin.H{"message": message}})
+	if err != nil {
+		h.Logger.Error("Failed to marshal WebSocket error message", "clientId", client.ID, "error", err)
+		return
+	}
+	client.Manager.SendToClient(client, data)
+}
+
 // writePump pumps messages from the hub to the WebSocket connection.
 // A goroutine running writePump is started for each connection. The
 // application ensures that there is at most one writer to a connection by
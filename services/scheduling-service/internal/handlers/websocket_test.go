@@ -0,0 +1,127 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/websocket"
+	"github.com/slotwise/scheduling-service/internal/handlers"
+	"github.com/slotwise/scheduling-service/internal/middleware"
+	"github.com/slotwise/scheduling-service/internal/realtime"
+	"github.com/slotwise/scheduling-service/pkg/events"
+	"github.com/slotwise/scheduling-service/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func checkOrigin(policy handlers.OriginPolicy, origin string) bool {
+	h := handlers.NewWebSocketHandler(nil, policy, &middleware.TokenValidator{}, logger.New("debug"))
+	req := httptest.NewRequest("GET", "/ws/availability", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	return h.Upgrader.CheckOrigin(req)
+}
+
+func TestWebSocketCheckOrigin(t *testing.T) {
+	production := handlers.OriginPolicy{AllowedOrigins: []string{"https://app.slotwise.com"}, Enforce: true}
+
+	t.Run("Allowed origin is accepted", func(t *testing.T) {
+		assert.True(t, checkOrigin(production, "https://app.slotwise.com"))
+		assert.True(t, checkOrigin(production, "https://APP.slotwise.com/"))
+	})
+
+	t.Run("Disallowed origin is rejected in production", func(t *testing.T) {
+		assert.False(t, checkOrigin(production, "https://evil.example.com"))
+		assert.False(t, checkOrigin(production, "http://app.slotwise.com"))
+	})
+
+	t.Run("Request without origin is accepted", func(t *testing.T) {
+		assert.True(t, checkOrigin(production, ""))
+	})
+
+	t.Run("Development allows any origin", func(t *testing.T) {
+		development := handlers.OriginPolicy{AllowedOrigins: []string{"https://app.slotwise.com"}}
+		assert.True(t, checkOrigin(development, "http://localhost:3000"))
+	})
+}
+
+func TestWebSocketCustomerSubscription(t *testing.T) {
+	const secret = "websocket-test-secret"
+	manager := realtime.NewSubscriptionManager(logger.New("debug"), nil)
+	go manager.Run()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/ws/availability", handlers.NewWebSocketHandler(manager, handlers.OriginPolicy{}, &middleware.TokenValidator{Secret: secret, Issuer: testTokenIssuer, Audience: testTokenAudience}, logger.New("debug")).HandleConnections)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	signToken := func(subject string) string {
+		claims := middleware.Claims{
+			Role:      "client",
+			TokenType: "access",
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject:   subject,
+				Issuer:    testTokenIssuer,
+				Audience:  jwt.ClaimStrings{testTokenAudience},
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			},
+		}
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+		require.NoError(t, err)
+		return token
+	}
+	connect := func(customerID, token string) *websocket.Conn {
+		conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws/availability", nil)
+		require.NoError(t, err)
+		require.NoError(t, conn.WriteJSON(handlers.SubscriptionMessage{Type: "subscribe_customer", CustomerID: customerID, Token: token}))
+		return conn
+	}
+	readMessage := func(conn *websocket.Conn) realtime.WebSocketMessage {
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+		var msg realtime.WebSocketMessage
+		require.NoError(t, conn.ReadJSON(&msg))
+		return msg
+	}
+
+	t.Run("Customer receives booking_updated when their booking is confirmed", func(t *testing.T) {
+		conn := connect("cust_ws", signToken("cust_ws"))
+		defer conn.Close()
+		assert.Eventually(t, func() bool {
+			for _, c := range manager.ListClients() {
+				if c.CustomerID == "cust_ws" {
+					return true
+				}
+			}
+			return false
+		}, 2*time.Second, 10*time.Millisecond)
+
+		event, err := json.Marshal(map[string]interface{}{"bookingId": "bkg_ws", "businessId": "biz_ws", "customerId": "cust_ws", "newStatus": "CONFIRMED"})
+		require.NoError(t, err)
+		require.NoError(t, manager.HandleEvent(events.BookingConfirmedEvent, event))
+
+		msg := readMessage(conn)
+		assert.Equal(t, "booking_updated", msg.Type)
+		payload, ok := msg.Payload.(map[string]interface{})
+		require.True(t, ok)
+		assert.Equal(t, "bkg_ws", payload["bookingId"])
+		assert.Equal(t, "CONFIRMED", payload["newStatus"])
+	})
+
+	t.Run("Token for another customer is rejected", func(t *testing.T) {
+		conn := connect("cust_victim", signToken("cust_other"))
+		defer conn.Close()
+
+		msg := readMessage(conn)
+		assert.Equal(t, "error", msg.Type)
+		for _, c := range manager.ListClients() {
+			assert.NotEqual(t, "cust_victim", c.CustomerID)
+		}
+	})
+}
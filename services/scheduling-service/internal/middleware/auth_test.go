@@ -0,0 +1,152 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/slotwise/scheduling-service/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	authTestSecret   = "auth-middleware-test-secret"
+	authTestIssuer   = "slotwise-auth-service"
+	authTestAudience = "slotwise-api"
+)
+
+var authTestTokens = &middleware.TokenValidator{Secret: authTestSecret, Issuer: authTestIssuer, Audience: authTestAudience}
+
+func signAuthTestToken(t *testing.T, role, businessID string) string {
+	return signAuthTestClaims(t, middleware.Claims{Role: role, BusinessID: businessID, TokenType: "access", RegisteredClaims: authTestRegisteredClaims("user-" + role)})
+}
+
+// authTestRegisteredClaims returns the registered claims of an unexpired access token the auth service would issue.
+func authTestRegisteredClaims(subject string) jwt.RegisteredClaims {
+	now := time.Now()
+	return jwt.RegisteredClaims{
+		ID:        "jti-" + subject,
+		Subject:   subject,
+		Issuer:    authTestIssuer,
+		Audience:  jwt.ClaimStrings{authTestAudience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+	}
+}
+
+func signAuthTestClaims(t *testing.T, claims middleware.Claims) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(authTestSecret))
+	require.NoError(t, err)
+	return token
+}
+
+// fakeDenylist revokes the token IDs it lists, or fails every check when err is set.
+type fakeDenylist struct {
+	revoked map[string]bool
+	err     error
+}
+
+func (d *fakeDenylist) IsRevoked(_ context.Context, tokenID, _ string, _ time.Time) (bool, error) {
+	return d.revoked[tokenID], d.err
+}
+
+func newOwnerRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	ok := func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"ok": true}) }
+	router.GET("/businesses/:businessId/stats", middleware.RequireAuth(authTestTokens), middleware.RequireBusinessOwner("businessId"), ok)
+	router.PUT("/bookings/status-bulk", middleware.RequireAuth(authTestTokens), middleware.RequireBusinessOwner(""), ok)
+	return router
+}
+
+func TestRequireBusinessOwner(t *testing.T) {
+	router := newOwnerRouter()
+	request := func(method, path, token string) int {
+		req := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		want   int
+	}{
+		{"Unauthenticated bulk update", http.MethodPut, "/bookings/status-bulk", "", http.StatusUnauthorized},
+		{"Customer bulk update", http.MethodPut, "/bookings/status-bulk", signAuthTestToken(t, "client", ""), http.StatusForbidden},
+		{"Owner bulk update", http.MethodPut, "/bookings/status-bulk", signAuthTestToken(t, middleware.RoleBusinessOwner, "biz_1"), http.StatusOK},
+		{"Unauthenticated stats", http.MethodGet, "/businesses/biz_1/stats", "", http.StatusUnauthorized},
+		{"Owner of the business", http.MethodGet, "/businesses/biz_1/stats", signAuthTestToken(t, middleware.RoleBusinessOwner, "biz_1"), http.StatusOK},
+		{"Owner of another business", http.MethodGet, "/businesses/biz_1/stats", signAuthTestToken(t, middleware.RoleBusinessOwner, "biz_2"), http.StatusForbidden},
+		{"Owner without a business", http.MethodGet, "/businesses/biz_1/stats", signAuthTestToken(t, middleware.RoleBusinessOwner, ""), http.StatusForbidden},
+		{"Admin", http.MethodGet, "/businesses/biz_1/stats", signAuthTestToken(t, middleware.RoleAdmin, ""), http.StatusOK},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, request(tc.method, tc.path, tc.token))
+		})
+	}
+}
+
+func TestParseAccessToken_ChecksIssuerAndAudience(t *testing.T) {
+	valid := middleware.Claims{Role: "client", TokenType: "access", RegisteredClaims: authTestRegisteredClaims("user-1")}
+	claims, err := authTestTokens.ParseAccessToken(context.Background(), signAuthTestClaims(t, valid))
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", claims.Subject)
+
+	wrongIssuer := valid
+	wrongIssuer.Issuer = "someone-else"
+	_, err = authTestTokens.ParseAccessToken(context.Background(), signAuthTestClaims(t, wrongIssuer))
+	assert.ErrorIs(t, err, jwt.ErrTokenInvalidIssuer)
+
+	wrongAudience := valid
+	wrongAudience.Audience = jwt.ClaimStrings{"staging-api"}
+	_, err = authTestTokens.ParseAccessToken(context.Background(), signAuthTestClaims(t, wrongAudience))
+	assert.ErrorIs(t, err, jwt.ErrTokenInvalidAudience)
+
+	noAudience := valid
+	noAudience.Audience = nil
+	_, err = authTestTokens.ParseAccessToken(context.Background(), signAuthTestClaims(t, noAudience))
+	assert.Error(t, err)
+
+	refresh := valid
+	refresh.TokenType = "refresh"
+	_, err = authTestTokens.ParseAccessToken(context.Background(), signAuthTestClaims(t, refresh))
+	assert.Error(t, err)
+}
+
+func TestRequireAuth_RejectsRevokedTokens(t *testing.T) {
+	denylist := &fakeDenylist{revoked: map[string]bool{"jti-user-revoked": true}}
+	validator := &middleware.TokenValidator{Secret: authTestSecret, Issuer: authTestIssuer, Audience: authTestAudience, Denylist: denylist}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/me", middleware.RequireAuth(validator), func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"userId": c.GetString("user_id")}) })
+	request := func(subject string) int {
+		token := signAuthTestClaims(t, middleware.Claims{Role: "client", TokenType: "access", RegisteredClaims: authTestRegisteredClaims(subject)})
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	assert.Equal(t, http.StatusOK, request("user-active"))
+	assert.Equal(t, http.StatusUnauthorized, request("user-revoked"))
+
+	// A denylist that cannot be read refuses the token rather than letting a revoked one through
+	denylist.err = errors.New("redis unavailable")
+	assert.Equal(t, http.StatusServiceUnavailable, request("user-active"))
+}
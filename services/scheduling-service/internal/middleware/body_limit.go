@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultMaxBodyBytes is the request body limit applied to every route.
+// The largest legitimate payloads (bookings with metadata, availability rules) are a few KB.
+const DefaultMaxBodyBytes int64 = 1 << 20 // 1 MiB
+
+// MaxBodyBytes rejects requests whose body is larger than limit with 413 Request Entity Too Large.
+// The body is read up front so that chunked requests without a Content-Length are caught too,
+// and handlers never see a truncated payload.
+func MaxBodyBytes(limit int64) gin.HandlerFunc {
+	tooLarge := fmt.Sprintf("Request body exceeds the limit of %d bytes", limit)
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > limit {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": tooLarge})
+			return
+		}
+		if c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, limit+1))
+		c.Request.Body.Close()
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+			return
+		}
+		if int64(len(body)) > limit {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": tooLarge})
+			return
+		}
+
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Next()
+	}
+}
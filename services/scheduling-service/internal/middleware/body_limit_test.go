@@ -0,0 +1,52 @@
+package middleware_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/slotwise/scheduling-service/internal/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func newBodyLimitRouter(limit int64) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.MaxBodyBytes(limit))
+	router.POST("/api/v1/bookings", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusCreated, string(body))
+	})
+	return router
+}
+
+func TestMaxBodyBytes(t *testing.T) {
+	router := newBodyLimitRouter(16)
+
+	t.Run("Oversized body is rejected with 413", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/bookings", strings.NewReader(strings.Repeat("x", 17)))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	})
+
+	t.Run("Oversized body without Content-Length is rejected with 413", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/bookings", strings.NewReader(strings.Repeat("x", 17)))
+		req.ContentLength = -1
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	})
+
+	t.Run("Body at the limit reaches the handler intact", func(t *testing.T) {
+		body := strings.Repeat("x", 16)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/bookings", strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		assert.Equal(t, body, rr.Body.String())
+	})
+}
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CORSConfig holds the cross-origin policy for browser clients
+type CORSConfig struct {
+	AllowOrigins     []string // Origins allowed to call the API; "*" allows any
+	AllowMethods     []string
+	AllowHeaders     []string
+	AllowCredentials bool          // Lets listed origins send cookies and Authorization; never applies to "*"
+	MaxAge           time.Duration // How long browsers may cache a preflight response; 0 omits the header
+}
+
+// DefaultCORSConfig returns a permissive policy without credentials
+func DefaultCORSConfig() CORSConfig {
+	return CORSConfig{
+		AllowOrigins: []string{"*"},
+		AllowMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions},
+		AllowHeaders: []string{"Origin", "Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization"},
+		MaxAge:       time.Hour,
+	}
+}
+
+// CORS creates a gin middleware for CORS
+// Credentialed requests are only allowed from explicitly listed origins, which are echoed back since
+// browsers reject a wildcard origin on them. Preflight requests are answered with 204, or 403 when
+// the origin is not allowed.
+func CORS(config CORSConfig) gin.HandlerFunc {
+	wildcard := false
+	listed := make(map[string]bool, len(config.AllowOrigins))
+	for _, origin := range config.AllowOrigins {
+		if origin == "*" {
+			wildcard = true
+		} else {
+			listed[strings.TrimRight(origin, "/")] = true
+		}
+	}
+	allowMethods := strings.Join(config.AllowMethods, ", ")
+	allowHeaders := strings.Join(config.AllowHeaders, ", ")
+	maxAge := strconv.Itoa(int(config.MaxAge.Seconds()))
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			// Not a cross-origin request
+			c.Next()
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Origin")
+		allowed := true
+		switch {
+		case listed[origin]:
+			c.Header("Access-Control-Allow-Origin", origin)
+			if config.AllowCredentials {
+				c.Header("Access-Control-Allow-Credentials", "true")
+			}
+		case wildcard:
+			c.Header("Access-Control-Allow-Origin", "*")
+		default:
+			allowed = false
+		}
+
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		if !preflight {
+			c.Next()
+			return
+		}
+		if !allowed {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+		c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+		c.Header("Access-Control-Allow-Methods", allowMethods)
+		c.Header("Access-Control-Allow-Headers", allowHeaders)
+		if config.MaxAge > 0 {
+			c.Header("Access-Control-Max-Age", maxAge)
+		}
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,93 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/slotwise/scheduling-service/internal/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+const appOrigin = "https://app.slotwise.com"
+
+func newCORSRouter(config middleware.CORSConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.CORS(config))
+	router.POST("/api/v1/bookings", func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+	return router
+}
+
+func preflightRequest(origin string) *http.Request {
+	req := httptest.NewRequest(http.MethodOptions, "/api/v1/bookings", nil)
+	req.Header.Set("Origin", origin)
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	req.Header.Set("Access-Control-Request-Headers", "Content-Type, Authorization")
+	return req
+}
+
+func TestCORS(t *testing.T) {
+	config := middleware.DefaultCORSConfig()
+	config.AllowOrigins = []string{appOrigin}
+	config.AllowCredentials = true
+	config.MaxAge = 10 * time.Minute
+	router := newCORSRouter(config)
+
+	t.Run("Preflight for a credentialed request from a listed origin", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, preflightRequest(appOrigin))
+
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+		assert.Equal(t, appOrigin, rr.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "true", rr.Header().Get("Access-Control-Allow-Credentials"))
+		assert.Contains(t, rr.Header().Get("Access-Control-Allow-Methods"), http.MethodPost)
+		assert.Contains(t, rr.Header().Get("Access-Control-Allow-Headers"), "Authorization")
+		assert.Equal(t, "600", rr.Header().Get("Access-Control-Max-Age"))
+		assert.Contains(t, rr.Header().Values("Vary"), "Origin")
+	})
+
+	t.Run("Actual request from a listed origin carries credentials headers", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/bookings", nil)
+		req.Header.Set("Origin", appOrigin)
+		req.Header.Set("Cookie", "session=abc")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		assert.Equal(t, appOrigin, rr.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "true", rr.Header().Get("Access-Control-Allow-Credentials"))
+	})
+
+	t.Run("Preflight from an unlisted origin is rejected", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, preflightRequest("https://evil.example.com"))
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		assert.Empty(t, rr.Header().Get("Access-Control-Allow-Origin"))
+		assert.Empty(t, rr.Header().Get("Access-Control-Allow-Credentials"))
+	})
+
+	t.Run("Wildcard origin never allows credentials", func(t *testing.T) {
+		wildcard := middleware.DefaultCORSConfig()
+		wildcard.AllowCredentials = true
+		rr := httptest.NewRecorder()
+		newCORSRouter(wildcard).ServeHTTP(rr, preflightRequest(appOrigin))
+
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+		assert.Equal(t, "*", rr.Header().Get("Access-Control-Allow-Origin"))
+		assert.Empty(t, rr.Header().Get("Access-Control-Allow-Credentials"))
+	})
+
+	t.Run("Same-origin requests get no CORS headers", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/v1/bookings", nil))
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		assert.Empty(t, rr.Header().Get("Access-Control-Allow-Origin"))
+	})
+}
@@ -15,28 +15,14 @@ func Logger(logger *logger.Logger) gin.HandlerFunc {
 			"status", param.StatusCode,
 			"latency", param.Latency,
 			"ip", param.ClientIP,
+			"request_id", param.Keys["request_id"],
 		)
 		return ""
 	})
 }
 
-// CORS creates a gin middleware for CORS
-func CORS() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
-
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
-
-		c.Next()
-	}
-}
-
-// RequestID creates a gin middleware for request ID
+// RequestID creates a gin middleware for request ID. The ID is echoed in the X-Request-ID response header
+// and attached to the request context for logging.
 func RequestID() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		requestID := c.GetHeader("X-Request-ID")
@@ -45,6 +31,8 @@ func RequestID() gin.HandlerFunc {
 		}
 		c.Header("X-Request-ID", requestID)
 		c.Set("request_id", requestID)
+		// Handlers and services log with the request context, so their lines carry the ID too
+		c.Request = c.Request.WithContext(logger.WithRequestID(c.Request.Context(), requestID))
 		c.Next()
 	}
 }
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rateLimitWindow counts the requests one client made in the current window
+type rateLimitWindow struct {
+	start time.Time
+	count int
+}
+
+// ipRateLimiter is a fixed-window request counter keyed by client IP
+type ipRateLimiter struct {
+	mu        sync.Mutex
+	limit     int
+	window    time.Duration
+	clients   map[string]*rateLimitWindow
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// allow records a request from ip and reports whether it is within the limit,
+// how many requests remain in the window and when the window resets.
+func (l *ipRateLimiter) allow(ip string) (bool, int, time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	w, ok := l.clients[ip]
+	if !ok || now.Sub(w.start) >= l.window {
+		w = &rateLimitWindow{start: now}
+		l.clients[ip] = w
+	}
+	resetAt := w.start.Add(l.window)
+	if w.count >= l.limit {
+		return false, 0, resetAt
+	}
+	w.count++
+	return true, l.limit - w.count, resetAt
+}
+
+// sweep drops expired windows so idle clients don't accumulate in memory
+func (l *ipRateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.window {
+		return
+	}
+	for ip, w := range l.clients {
+		if now.Sub(w.start) >= l.window {
+			delete(l.clients, ip)
+		}
+	}
+	l.lastSweep = now
+}
+
+// RateLimit allows each client IP at most limit requests per window and answers
+// anything beyond that with 429 Too Many Requests and a Retry-After header.
+// Counters are kept in memory, so the limit applies per service instance. The client IP is
+// gin's ClientIP, so the engine's trusted proxies must be set or X-Forwarded-For can be spoofed.
+func RateLimit(limit int, window time.Duration) gin.HandlerFunc {
+	limiter := &ipRateLimiter{
+		limit:   limit,
+		window:  window,
+		clients: make(map[string]*rateLimitWindow),
+		now:     time.Now,
+	}
+	return func(c *gin.Context) {
+		allowed, remaining, resetAt := limiter.allow(c.ClientIP())
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		c.Header("X-RateLimit-Reset", strconv.FormatInt(resetAt.Unix(), 10))
+
+		if !allowed {
+			retryAfter := int(math.Ceil(resetAt.Sub(limiter.now()).Seconds()))
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests, please try again later"})
+			return
+		}
+		c.Next()
+	}
+}
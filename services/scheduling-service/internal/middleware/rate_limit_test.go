@@ -0,0 +1,108 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/slotwise/scheduling-service/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRateLimitRouter(limit int, window time.Duration, trustedProxies ...string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	// As in main, only the configured proxies are trusted; none by default
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		panic(err)
+	}
+	router.GET("/api/v1/services/:serviceId/slots", middleware.RateLimit(limit, window), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"slots": []string{}})
+	})
+	return router
+}
+
+func slotsRequest(remoteAddr string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/services/svc-1/slots?date=2030-01-07", nil)
+	req.RemoteAddr = remoteAddr
+	return req
+}
+
+func TestRateLimit(t *testing.T) {
+	t.Run("Requests beyond the limit get 429 with Retry-After", func(t *testing.T) {
+		router := newRateLimitRouter(3, time.Minute)
+
+		for i := 0; i < 3; i++ {
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, slotsRequest("203.0.113.7:40000"))
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, strconv.Itoa(2-i), rr.Header().Get("X-RateLimit-Remaining"))
+		}
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, slotsRequest("203.0.113.7:40001"))
+		assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+		retryAfter, err := strconv.Atoi(rr.Header().Get("Retry-After"))
+		require.NoError(t, err)
+		assert.True(t, retryAfter >= 1 && retryAfter <= 60, "Retry-After should fall within the window, got %d", retryAfter)
+	})
+
+	t.Run("A spoofed X-Forwarded-For does not reset the budget", func(t *testing.T) {
+		router := newRateLimitRouter(1, time.Minute)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, slotsRequest("203.0.113.7:40000"))
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		req := slotsRequest("203.0.113.7:40001")
+		req.Header.Set("X-Forwarded-For", "198.51.100.99")
+		rr = httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	})
+
+	t.Run("X-Forwarded-For from a trusted proxy identifies the client", func(t *testing.T) {
+		router := newRateLimitRouter(1, time.Minute, "10.0.0.0/8")
+
+		for _, client := range []string{"203.0.113.7", "198.51.100.2"} {
+			req := slotsRequest("10.0.0.5:40000")
+			req.Header.Set("X-Forwarded-For", client)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+			assert.Equal(t, http.StatusOK, rr.Code, "Clients behind the proxy have their own budgets")
+		}
+	})
+
+	t.Run("Each client IP has its own budget", func(t *testing.T) {
+		router := newRateLimitRouter(1, time.Minute)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, slotsRequest("203.0.113.7:40000"))
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		rr = httptest.NewRecorder()
+		router.ServeHTTP(rr, slotsRequest("198.51.100.2:40000"))
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("The budget is restored once the window passes", func(t *testing.T) {
+		router := newRateLimitRouter(1, 50*time.Millisecond)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, slotsRequest("203.0.113.7:40000"))
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		rr = httptest.NewRecorder()
+		router.ServeHTTP(rr, slotsRequest("203.0.113.7:40000"))
+		require.Equal(t, http.StatusTooManyRequests, rr.Code)
+
+		time.Sleep(60 * time.Millisecond)
+		rr = httptest.NewRecorder()
+		router.ServeHTTP(rr, slotsRequest("203.0.113.7:40000"))
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ServiceTokenHeader carries the shared secret of a calling service, as for the auth service's service-only endpoints.
+const ServiceTokenHeader = "X-Service-Token"
+
+// RequireServiceToken rejects requests that don't carry one of tokens in the X-Service-Token header.
+// With no tokens configured every request is rejected, so internal endpoints are never left open.
+func RequireServiceToken(tokens []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provided := c.GetHeader(ServiceTokenHeader)
+		if provided == "" || !matchesAnyToken(provided, tokens) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "A valid service token is required"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// matchesAnyToken compares provided against every token in constant time, so timing reveals neither which nor how much matched.
+func matchesAnyToken(provided string, tokens []string) bool {
+	matched := 0
+	for _, token := range tokens {
+		if token != "" {
+			matched |= subtle.ConstantTimeCompare([]byte(provided), []byte(token))
+		}
+	}
+	return matched == 1
+}
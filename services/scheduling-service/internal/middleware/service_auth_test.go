@@ -0,0 +1,36 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/slotwise/scheduling-service/internal/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireServiceToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	send := func(tokens []string, provided string) int {
+		router := gin.New()
+		router.GET("/api/v1/internal/availability/:businessId/slots", middleware.RequireServiceToken(tokens), func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"slots": []string{}})
+		})
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/internal/availability/biz-1/slots", nil)
+		if provided != "" {
+			req.Header.Set(middleware.ServiceTokenHeader, provided)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	tokens := []string{"old-token", "new-token"}
+	assert.Equal(t, http.StatusOK, send(tokens, "old-token"))
+	assert.Equal(t, http.StatusOK, send(tokens, "new-token"), "either token works while rotating")
+	assert.Equal(t, http.StatusUnauthorized, send(tokens, "wrong-token"))
+	assert.Equal(t, http.StatusUnauthorized, send(tokens, ""))
+	assert.Equal(t, http.StatusUnauthorized, send(nil, ""), "without tokens configured every call is refused")
+	assert.Equal(t, http.StatusUnauthorized, send([]string{""}, ""))
+}
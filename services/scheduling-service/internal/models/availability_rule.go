@@ -22,18 +22,34 @@ const (
 	Sunday    DayOfWeekString = "SUNDAY"
 )
 
+// IsValid reports whether d is one of the known day constants.
+func (d DayOfWeekString) IsValid() bool {
+	switch d {
+	case Monday, Tuesday, Wednesday, Thursday, Friday, Saturday, Sunday:
+		return true
+	}
+	return false
+}
+
 // AvailabilityRule stores the processed availability rules for a business.
 // These are used by the Scheduling Service to determine open time slots.
+// A business can have only one live rule for the same day and hours (idx_availability_rules_unique).
 type AvailabilityRule struct {
 	ID         uint            `gorm:"primaryKey;autoIncrement" json:"id"`
-	BusinessID string          `gorm:"index:idx_availability_business_day,priority:1;type:varchar(255);not null" json:"businessId"`
-	DayOfWeek  DayOfWeekString `gorm:"index:idx_availability_business_day,priority:2;type:varchar(10);not null" json:"dayOfWeek"`   // e.g., "MONDAY", "TUESDAY"
-	StartTime  string          `gorm:"type:varchar(5);not null" json:"startTime"` // "HH:MM" format, e.g., "09:00"
-	EndTime    string          `gorm:"type:varchar(5);not null" json:"endTime"`   // "HH:MM" format, e.g., "17:00"
-	BufferMinutes int          `gorm:"default:0" json:"bufferMinutes"` // Buffer time in minutes after a service
+	BusinessID string          `gorm:"index:idx_availability_business_day,priority:1;uniqueIndex:idx_availability_rules_unique,priority:1,where:deleted_at IS NULL;type:varchar(255);not null" json:"businessId"`
+	DayOfWeek  DayOfWeekString `gorm:"index:idx_availability_business_day,priority:2;uniqueIndex:idx_availability_rules_unique,priority:2;type:varchar(10);not null" json:"dayOfWeek"`   // e.g., "MONDAY", "TUESDAY"
+	StartTime  string          `gorm:"uniqueIndex:idx_availability_rules_unique,priority:3;type:varchar(5);not null" json:"startTime"` // "HH:MM" format, e.g., "09:00"
+	EndTime    string          `gorm:"uniqueIndex:idx_availability_rules_unique,priority:4;type:varchar(5);not null" json:"endTime"`   // "HH:MM" format, e.g., "17:00"
+	BufferBeforeMinutes int    `gorm:"not null;default:0" json:"bufferBeforeMinutes"` // Setup time in minutes kept free before each appointment
+	BufferAfterMinutes  int    `gorm:"column:buffer_minutes;default:0" json:"bufferAfterMinutes"` // Cleanup time in minutes kept free after each appointment
+	// Deprecated: BufferMinutes is the old name of BufferAfterMinutes, kept for API clients that still send or read it.
+	// It is not stored: BeforeSave copies it into an unset BufferAfterMinutes and AfterFind mirrors BufferAfterMinutes back.
+	BufferMinutes int          `gorm:"-" json:"bufferMinutes"`
+	Active     bool            `gorm:"not null;default:true" json:"active"` // Inactive rules are kept but produce no slots, e.g. seasonal hours
 
 	CreatedAt time.Time      `json:"createdAt"`
 	UpdatedAt time.Time      `json:"updatedAt"`
+	UpdatedBy string         `gorm:"type:varchar(255)" json:"updatedBy,omitempty"` // User ID of the last editor, empty for rules synced from events
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
@@ -41,3 +57,27 @@ type AvailabilityRule struct {
 func (AvailabilityRule) TableName() string {
 	return "availability_rules"
 }
+
+// Buffers returns the time kept free before and after each appointment under the rule.
+func (r *AvailabilityRule) Buffers() (before, after time.Duration) {
+	afterMinutes := r.BufferAfterMinutes
+	if afterMinutes == 0 {
+		afterMinutes = r.BufferMinutes
+	}
+	return time.Duration(r.BufferBeforeMinutes) * time.Minute, time.Duration(afterMinutes) * time.Minute
+}
+
+// BeforeSave stores the deprecated BufferMinutes as the after buffer when only it was set.
+func (r *AvailabilityRule) BeforeSave(tx *gorm.DB) error {
+	if r.BufferAfterMinutes == 0 {
+		r.BufferAfterMinutes = r.BufferMinutes
+	}
+	r.BufferMinutes = r.BufferAfterMinutes
+	return nil
+}
+
+// AfterFind fills the deprecated BufferMinutes so it keeps reading back as the after buffer.
+func (r *AvailabilityRule) AfterFind(tx *gorm.DB) error {
+	r.BufferMinutes = r.BufferAfterMinutes
+	return nil
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+)
+
+// AvailabilityVersion tracks how fresh a business's availability is.
+// Version is the newest availability snapshot applied: availability events carry a monotonic version so
+// that an older snapshot delivered late is ignored. Revision is bumped whenever the business's rules or
+// bookings change, so clients can tell whether slots they cached are stale (see BumpAvailabilityVersion).
+type AvailabilityVersion struct {
+	BusinessID string    `gorm:"primaryKey;type:varchar(255)" json:"businessId"`
+	Version    int64     `gorm:"not null" json:"version"`
+	Revision   int64     `gorm:"not null;default:0" json:"revision"`
+	UpdatedAt  time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"updatedAt"`
+}
+
+// TableName explicitly sets the table name.
+func (AvailabilityVersion) TableName() string {
+	return "availability_versions"
+}
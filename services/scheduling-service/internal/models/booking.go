@@ -1,11 +1,15 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+// DefaultLanguage is used for notifications when the customer's language is unknown.
+const DefaultLanguage = "en"
+
 // BookingStatus defines the possible statuses of a booking.
 type BookingStatus string
 
@@ -14,7 +18,61 @@ const (
 	BookingStatusConfirmed      BookingStatus = "CONFIRMED"       // Confirmed after payment or if no payment needed
 	BookingStatusCancelled      BookingStatus = "CANCELLED"       // Cancelled by user or system
 	BookingStatusCompleted      BookingStatus = "COMPLETED"       // Service delivered
-	// Potentially add: BookingStatusNoShow, BookingStatusRescheduled etc.
+	BookingStatusNoShow         BookingStatus = "NO_SHOW"         // Customer did not turn up; not counted as revenue
+	// Potentially add: BookingStatusRescheduled etc.
+)
+
+// IsValid reports whether s is one of the known booking statuses.
+func (s BookingStatus) IsValid() bool {
+	switch s {
+	case BookingStatusPendingPayment, BookingStatusConfirmed, BookingStatusCancelled, BookingStatusCompleted, BookingStatusNoShow:
+		return true
+	}
+	return false
+}
+
+// bookingTransitions lists the statuses each status may move to. Cancelled, completed and no-show
+// bookings are final.
+var bookingTransitions = map[BookingStatus][]BookingStatus{
+	BookingStatusPendingPayment: {BookingStatusConfirmed, BookingStatusCancelled},
+	BookingStatusConfirmed:      {BookingStatusCancelled, BookingStatusCompleted, BookingStatusNoShow},
+}
+
+// CanTransitionTo reports whether a booking in status s may be moved to next.
+func (s BookingStatus) CanTransitionTo(next BookingStatus) bool {
+	for _, allowed := range bookingTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// BookingTimeframe selects a customer's bookings by whether they are still ahead of them.
+type BookingTimeframe string
+
+const (
+	BookingTimeframeAll      BookingTimeframe = ""         // Every booking, latest first
+	BookingTimeframeUpcoming BookingTimeframe = "upcoming" // Bookings that have not ended yet, soonest first
+	BookingTimeframePast     BookingTimeframe = "past"     // Bookings that have ended, latest first
+)
+
+// IsValid reports whether t is one of the known timeframes.
+func (t BookingTimeframe) IsValid() bool {
+	switch t {
+	case BookingTimeframeAll, BookingTimeframeUpcoming, BookingTimeframePast:
+		return true
+	}
+	return false
+}
+
+// CancellationScope selects which occurrences of a recurring series a cancellation applies to.
+type CancellationScope string
+
+const (
+	CancellationScopeOccurrence CancellationScope = "OCCURRENCE" // Only the given booking (default)
+	CancellationScopeFollowing  CancellationScope = "FOLLOWING"  // The given booking and every later occurrence
+	CancellationScopeSeries     CancellationScope = "SERIES"     // Every occurrence in the series
 )
 
 // Booking represents a booking made by a customer for a service.
@@ -27,12 +85,23 @@ type Booking struct {
 	EndTime         time.Time     `gorm:"index;not null" json:"endTime"`
 	Status          BookingStatus `gorm:"type:varchar(50);not null;default:'PENDING_PAYMENT'" json:"status"`
 	PaymentIntentID *string       `gorm:"type:varchar(255);index" json:"paymentIntentId,omitempty"` // For Stripe or other payment integration
+	SeriesID        *string       `gorm:"type:varchar(255);index" json:"seriesId,omitempty"`        // Shared by the occurrences of a recurring booking
 
 	// Additional booking metadata
 	Notes       *string `gorm:"type:text" json:"notes,omitempty"`
 	ClientNotes *string `gorm:"type:text" json:"clientNotes,omitempty"`
-	TotalAmount *int64  `gorm:"type:bigint" json:"totalAmount,omitempty"` // Amount in cents
+	TotalAmount *int64  `gorm:"type:bigint" json:"totalAmount,omitempty"` // Service price in minor units at booking time
 	Currency    string  `gorm:"type:varchar(3);default:'USD'" json:"currency"`
+	Metadata    JSONMap `gorm:"type:jsonb" json:"metadata,omitempty"` // Business-specific custom fields, e.g. pet name
+
+	// CustomerLanguage is the customer's preferred language (ISO 639-1), used to localize notifications
+	CustomerLanguage string `gorm:"type:varchar(10);default:'en'" json:"customerLanguage"`
+
+	// Contact details of a guest without an account, e.g. a booking taken over the phone.
+	// Guest bookings have no CustomerID; notifications go to GuestEmail.
+	GuestName  *string `gorm:"type:varchar(255)" json:"guestName,omitempty"`
+	GuestEmail *string `gorm:"type:varchar(255)" json:"guestEmail,omitempty"`
+	GuestPhone *string `gorm:"type:varchar(50)" json:"guestPhone,omitempty"`
 
 	// Timestamps
 	CreatedAt time.Time      `gorm:"default:CURRENT_TIMESTAMP" json:"createdAt"`
@@ -40,8 +109,41 @@ type Booking struct {
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 
 	// Runtime fields (not stored in database)
-	ServiceName  string `gorm:"-" json:"serviceName,omitempty"`
-	CustomerName string `gorm:"-" json:"customerName,omitempty"`
+	ServiceName       string `gorm:"-" json:"serviceName,omitempty"`
+	ServiceColor      string `gorm:"-" json:"serviceColor,omitempty"`
+	ServiceShortLabel string `gorm:"-" json:"serviceShortLabel,omitempty"`
+	CustomerName      string `gorm:"-" json:"customerName,omitempty"`
+
+	// CancellationPolicy is filled in on booking details of active bookings
+	CancellationPolicy *CancellationPolicy `gorm:"-" json:"cancellationPolicy,omitempty"`
+}
+
+// CancellationPolicy describes what cancelling a booking would entail. It is informational: cancelling
+// does not charge the fee.
+type CancellationPolicy struct {
+	CancellableUntil time.Time  `json:"cancellableUntil"`  // Customers can cancel until the business's cutoff
+	CanCancel        bool       `json:"canCancel"`         // Whether the cutoff is still ahead
+	Fee              int64      `json:"fee"`               // In minor units; 0 when cancelling is free
+	Currency         string     `json:"currency"`          // The booking's currency
+	FeeFrom          *time.Time `json:"feeFrom,omitempty"` // Cancelling from this time on incurs Fee; nil without a fee
+	FeeApplies       bool       `json:"feeApplies"`        // Whether cancelling now would incur Fee
+}
+
+// IsGuest reports whether the booking was made for a guest rather than a customer account.
+func (booking *Booking) IsGuest() bool {
+	return booking.GuestEmail != nil
+}
+
+// MarshalJSON renders the booking's timestamps as RFC3339 in UTC, whatever location
+// they were loaded or built in, so every response uses the same "Z" offset.
+func (booking Booking) MarshalJSON() ([]byte, error) {
+	type bookingJSON Booking // Drops this method to avoid recursion
+	out := bookingJSON(booking)
+	out.StartTime = out.StartTime.UTC()
+	out.EndTime = out.EndTime.UTC()
+	out.CreatedAt = out.CreatedAt.UTC()
+	out.UpdatedAt = out.UpdatedAt.UTC()
+	return json.Marshal(out)
 }
 
 // BeforeCreate hook for additional validation before creating a booking
@@ -57,6 +159,10 @@ func (booking *Booking) BeforeCreate(tx *gorm.DB) (err error) {
 		booking.Status = BookingStatusPendingPayment
 	}
 
+	if booking.CustomerLanguage == "" {
+		booking.CustomerLanguage = DefaultLanguage
+	}
+
 	return nil
 }
 
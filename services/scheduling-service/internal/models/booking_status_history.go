@@ -0,0 +1,31 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// BookingStatusHistory records a single status transition of a booking.
+// One row is written for every status change, giving businesses an audit trail.
+type BookingStatusHistory struct {
+	ID         uint          `gorm:"primaryKey" json:"id"`
+	BookingID  string        `gorm:"index;type:uuid;not null" json:"bookingId"`
+	FromStatus BookingStatus `gorm:"type:varchar(50);not null" json:"fromStatus"`
+	ToStatus   BookingStatus `gorm:"type:varchar(50);not null" json:"toStatus"`
+	ChangedBy  string        `gorm:"type:varchar(255)" json:"changedBy,omitempty"` // User ID of the actor, empty for system changes
+	Reason     *string       `gorm:"type:text" json:"reason,omitempty"`
+	CreatedAt  time.Time     `gorm:"default:CURRENT_TIMESTAMP" json:"createdAt"`
+}
+
+// MarshalJSON renders CreatedAt as RFC3339 in UTC, matching Booking.
+func (history BookingStatusHistory) MarshalJSON() ([]byte, error) {
+	type historyJSON BookingStatusHistory // Drops this method to avoid recursion
+	out := historyJSON(history)
+	out.CreatedAt = out.CreatedAt.UTC()
+	return json.Marshal(out)
+}
+
+// TableName explicitly sets the table name.
+func (BookingStatusHistory) TableName() string {
+	return "booking_status_history"
+}
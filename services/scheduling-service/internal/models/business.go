@@ -0,0 +1,132 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BusinessStatusSuspended marks a business that may not take bookings.
+const BusinessStatusSuspended = "SUSPENDED"
+
+// DefaultCancellationCutoffHours applies to businesses that have not set their own cutoff.
+const DefaultCancellationCutoffHours = 24
+
+// Business is a local copy of a business's existence and status. The business service owns
+// businesses; this table is kept in sync from its business events. Deleted businesses are soft-deleted.
+type Business struct {
+	ID     string `gorm:"primaryKey;type:varchar(255)" json:"id"` // Business ID in the business service
+	Name   string `gorm:"type:varchar(255)" json:"name"`
+	Status string `gorm:"type:varchar(50)" json:"status"` // e.g. "PENDING_SETUP", "ACTIVE"; empty if never reported
+
+	// AcceptingBookings is owned by this service, not synced from business events.
+	// When false the business is paused: no slots are offered and new bookings are rejected.
+	AcceptingBookings bool `gorm:"not null;default:true" json:"acceptingBookings"`
+
+	// Currency is the business's pricing currency. All of its services must be priced in it so revenue
+	// can be summed; empty until set by a business event or adopted from the first service.
+	Currency string `gorm:"type:varchar(10)" json:"currency,omitempty"`
+
+	// CancellationCutoffHours is how long before a booking starts customers may still cancel it.
+	// Nil means DefaultCancellationCutoffHours.
+	CancellationCutoffHours *int `json:"cancellationCutoffHours,omitempty"`
+
+	// CancellationFeeWindowHours is how long before a booking starts cancelling it incurs the cancellation
+	// fee. Nil means cancelling is free up to the cutoff.
+	CancellationFeeWindowHours *int `json:"cancellationFeeWindowHours,omitempty"`
+	// CancellationFee is the fee in minor units of the business's currency for cancelling inside the fee window. A service may set its own.
+	CancellationFee *int64 `gorm:"type:bigint" json:"cancellationFee,omitempty"`
+
+	// MinimumNoticeMinutes is how long before a booking starts it must be made. Nil means it may be made
+	// up to the start time.
+	MinimumNoticeMinutes *int `json:"minimumNoticeMinutes,omitempty"`
+
+	// MaxBookingsPerDay caps the pending and confirmed bookings starting on one of the business's calendar
+	// days. Nil or zero is unlimited.
+	MaxBookingsPerDay *int `json:"maxBookingsPerDay,omitempty"`
+	// MaxBookingsPerCustomerPerDay caps how many of those one customer may hold. Guest bookings are not
+	// counted. Nil or zero is unlimited.
+	MaxBookingsPerCustomerPerDay *int `json:"maxBookingsPerCustomerPerDay,omitempty"`
+
+	// AllowBackToBack lets a booking start exactly when another ends. When false, bookings that merely
+	// touch conflict, so the business always gets a gap between appointments even without rule buffers.
+	AllowBackToBack bool `gorm:"not null;default:true" json:"allowBackToBack"`
+
+	// Timezone is the business's IANA timezone, e.g. "America/New_York"; empty until reported by a business event.
+	Timezone string `gorm:"type:varchar(64)" json:"timezone,omitempty"`
+
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// IsActive reports whether the business may serve slots.
+func (b *Business) IsActive() bool {
+	return !b.DeletedAt.Valid && b.Status != BusinessStatusSuspended
+}
+
+// BackToBackAllowed reports whether a booking may start exactly when another ends. A nil business allows it.
+func (b *Business) BackToBackAllowed() bool {
+	return b == nil || b.AllowBackToBack
+}
+
+// CancellationCutoff returns how long before a booking's start customers stop being able to cancel it.
+// A nil business uses the default.
+func (b *Business) CancellationCutoff() time.Duration {
+	hours := DefaultCancellationCutoffHours
+	if b != nil && b.CancellationCutoffHours != nil {
+		hours = *b.CancellationCutoffHours
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// MinimumNotice returns how long before a booking's start it must be made. A nil business requires none.
+func (b *Business) MinimumNotice() time.Duration {
+	if b == nil || b.MinimumNoticeMinutes == nil {
+		return 0
+	}
+	return time.Duration(*b.MinimumNoticeMinutes) * time.Minute
+}
+
+// CancellationPolicyFor returns the cancellation terms of a booking of service as they stand at now.
+// The service's cancellation fee, if set, overrides the business's. A nil business or service uses the defaults.
+func (b *Business) CancellationPolicyFor(booking *Booking, service *ServiceDefinition, now time.Time) CancellationPolicy {
+	policy := CancellationPolicy{
+		CancellableUntil: booking.StartTime.Add(-b.CancellationCutoff()).UTC(),
+		Currency:         booking.Currency,
+	}
+	policy.CanCancel = now.Before(policy.CancellableUntil)
+	if b == nil || b.CancellationFeeWindowHours == nil {
+		return policy
+	}
+
+	fee := b.CancellationFee
+	if service != nil && service.CancellationFee != nil {
+		fee = service.CancellationFee
+	}
+	if fee == nil || *fee <= 0 {
+		return policy
+	}
+	feeFrom := booking.StartTime.Add(-time.Duration(*b.CancellationFeeWindowHours) * time.Hour).UTC()
+	policy.Fee = *fee
+	policy.FeeFrom = &feeFrom
+	policy.FeeApplies = policy.CanCancel && !now.Before(feeFrom)
+	return policy
+}
+
+// Location returns the business's timezone, falling back to UTC when it is unset or unknown.
+func (b *Business) Location() *time.Location {
+	if b == nil || b.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(b.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// TableName explicitly sets the table name.
+func (Business) TableName() string {
+	return "businesses"
+}
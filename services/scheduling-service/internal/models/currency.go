@@ -0,0 +1,28 @@
+package models
+
+import (
+	"math"
+	"strings"
+)
+
+// currencyExponents holds the ISO 4217 currencies whose minor unit is not a hundredth of the major unit.
+var currencyExponents = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+// CurrencyExponent returns how many decimal places currency's minor unit has, e.g. 2 for USD (cents)
+// and 0 for JPY. Unknown currencies are assumed to have 2.
+func CurrencyExponent(currency string) int {
+	if exponent, ok := currencyExponents[strings.ToUpper(strings.TrimSpace(currency))]; ok {
+		return exponent
+	}
+	return 2
+}
+
+// ToMinorUnits converts an amount in major units of currency, as events carry prices, into the minor units
+// amounts are stored in: 12.5 USD is 1250, while 1250 JPY stays 1250.
+func ToMinorUnits(amount float64, currency string) int64 {
+	return int64(math.Round(amount * math.Pow10(CurrencyExponent(currency))))
+}
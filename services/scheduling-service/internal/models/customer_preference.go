@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+// CustomerPreference is a local copy of a customer's contact details and notification
+// preferences. The auth service owns users; this table is kept in sync from its user events.
+type CustomerPreference struct {
+	CustomerID         string    `gorm:"primaryKey;type:varchar(255)" json:"customerId"` // User ID in the auth service
+	Email              string    `gorm:"type:varchar(255)" json:"email"`
+	Name               string    `gorm:"type:varchar(255)" json:"name"` // First and last name, for finding a customer's bookings
+	Language           string    `gorm:"type:varchar(10);default:'en'" json:"language"`
+	EmailNotifications bool      `gorm:"not null" json:"emailNotifications"`
+	SMSNotifications   bool      `gorm:"not null" json:"smsNotifications"`
+	UpdatedAt          time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"updatedAt"`
+}
+
+// TableName explicitly sets the table name.
+func (CustomerPreference) TableName() string {
+	return "customer_preferences"
+}
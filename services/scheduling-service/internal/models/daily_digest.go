@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+// DailyDigest records that a business's daily digest was issued for one local date,
+// so the digest job emits at most one digest per business per day.
+type DailyDigest struct {
+	BusinessID string    `gorm:"primaryKey;type:varchar(255)" json:"businessId"`
+	Date       string    `gorm:"primaryKey;type:varchar(10)" json:"date"` // "YYYY-MM-DD" in the business's timezone
+	CreatedAt  time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"createdAt"`
+}
+
+// TableName explicitly sets the table name.
+func (DailyDigest) TableName() string {
+	return "daily_digests"
+}
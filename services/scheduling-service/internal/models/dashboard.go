@@ -0,0 +1,9 @@
+package models
+
+// DashboardCounts are the headline booking numbers shown on a business's dashboard.
+type DashboardCounts struct {
+	TodayBookings        int64 `json:"todayBookings"`        // Starting today, cancelled bookings excluded
+	WeekBookings         int64 `json:"weekBookings"`         // Starting this week (Monday to Sunday), cancelled bookings excluded
+	PendingConfirmations int64 `json:"pendingConfirmations"` // Upcoming bookings still awaiting payment
+	Cancellations        int64 `json:"cancellations"`        // Cancelled bookings that were due to start this week
+}
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// JSONMap is a free-form JSON object stored in a jsonb column.
+type JSONMap map[string]interface{}
+
+// Value implements driver.Valuer.
+func (m JSONMap) Value() (driver.Value, error) {
+	if m == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling JSONMap: %w", err)
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner.
+func (m *JSONMap) Scan(value interface{}) error {
+	if value == nil {
+		*m = nil
+		return nil
+	}
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type for JSONMap: %T", value)
+	}
+	return json.Unmarshal(data, m)
+}
+
+// Supported metadata field types.
+const (
+	MetadataFieldTypeString  = "string"
+	MetadataFieldTypeNumber  = "number"
+	MetadataFieldTypeBoolean = "boolean"
+)
+
+// MetadataFieldSchema describes one custom field a service collects at booking time, e.g. "petName".
+type MetadataFieldSchema struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"` // One of the MetadataFieldType constants
+	Required bool   `json:"required"`
+}
+
+// MetadataSchema defines the custom booking fields of a service.
+type MetadataSchema struct {
+	Fields       []MetadataFieldSchema `json:"fields"`
+	AllowUnknown bool                  `json:"allowUnknown"` // Accept fields not listed in Fields
+}
+
+// Value implements driver.Valuer.
+func (s MetadataSchema) Value() (driver.Value, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling MetadataSchema: %w", err)
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner.
+func (s *MetadataSchema) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type for MetadataSchema: %T", value)
+	}
+	return json.Unmarshal(data, s)
+}
+
+// Validate checks booking metadata against the schema.
+func (s *MetadataSchema) Validate(metadata map[string]interface{}) error {
+	known := make(map[string]bool, len(s.Fields))
+	for _, field := range s.Fields {
+		known[field.Name] = true
+
+		value, ok := metadata[field.Name]
+		if !ok || value == nil {
+			if field.Required {
+				return fmt.Errorf("invalid metadata: field %q is required", field.Name)
+			}
+			continue
+		}
+
+		var valid bool
+		switch field.Type {
+		case MetadataFieldTypeString:
+			_, valid = value.(string)
+		case MetadataFieldTypeNumber:
+			_, valid = value.(float64) // encoding/json decodes all numbers as float64
+		case MetadataFieldTypeBoolean:
+			_, valid = value.(bool)
+		default:
+			valid = true // Unknown types are not enforced
+		}
+		if !valid {
+			return fmt.Errorf("invalid metadata: field %q must be a %s", field.Name, field.Type)
+		}
+	}
+
+	if !s.AllowUnknown {
+		for name := range metadata {
+			if !known[name] {
+				return fmt.Errorf("invalid metadata: unknown field %q", name)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+)
+
+// OutboxEventStatus defines the delivery state of an outbox event.
+type OutboxEventStatus string
+
+const (
+	OutboxEventStatusPending OutboxEventStatus = "PENDING" // Written with the aggregate, not yet published
+	OutboxEventStatusSent    OutboxEventStatus = "SENT"    // Successfully published to NATS
+	OutboxEventStatusParked  OutboxEventStatus = "PARKED"  // Given up on after MaxAttempts failures; kept for inspection and not retried
+)
+
+// DefaultOutboxMaxAttempts is how many publishes of an event are tried before it is parked: about an hour
+// of relay runs, so a broker outage delays events rather than parking them.
+const DefaultOutboxMaxAttempts = 360
+
+// OutboxEvent is an event persisted in the same transaction as the change that produced it.
+// A background relay publishes pending rows, which gives at-least-once delivery even if the
+// process dies between committing the change and publishing the event. Rows that keep failing
+// are parked so they stop being retried and holding up the relay.
+type OutboxEvent struct {
+	ID          string            `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	AggregateID string            `gorm:"index;type:varchar(255);not null" json:"aggregateId"` // e.g. the booking ID
+	Subject     string            `gorm:"type:varchar(255);not null" json:"subject"`
+	Payload     string            `gorm:"type:jsonb;not null" json:"payload"`
+	Status      OutboxEventStatus `gorm:"index;type:varchar(20);not null;default:'PENDING'" json:"status"`
+	Attempts    int               `gorm:"not null;default:0" json:"attempts"`
+	MaxAttempts int               `gorm:"not null;default:360" json:"maxAttempts"` // Failed attempts after which the event is parked
+	LastError   *string           `gorm:"type:text" json:"lastError,omitempty"`
+	CreatedAt   time.Time         `gorm:"default:CURRENT_TIMESTAMP" json:"createdAt"`
+	SentAt      *time.Time        `json:"sentAt,omitempty"`
+	ParkedAt    *time.Time        `json:"parkedAt,omitempty"`
+}
+
+// TableName explicitly sets the table name.
+func (OutboxEvent) TableName() string {
+	return "outbox_events"
+}
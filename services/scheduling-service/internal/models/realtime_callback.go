@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+)
+
+// RealtimeCallback is a business's registered HTTP callback for realtime messages. Registrations are
+// stored so they survive restarts; the realtime manager keeps them in memory for delivery.
+type RealtimeCallback struct {
+	BusinessID string    `gorm:"primaryKey;type:varchar(255)" json:"businessId"`
+	URL        string    `gorm:"type:text;not null" json:"url"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// TableName explicitly sets the table name.
+func (RealtimeCallback) TableName() string {
+	return "realtime_callbacks"
+}
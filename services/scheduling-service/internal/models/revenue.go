@@ -0,0 +1,25 @@
+package models
+
+// ServiceRevenue is the revenue earned by one service in one currency.
+type ServiceRevenue struct {
+	ServiceID    string `json:"serviceId"`
+	ServiceName  string `gorm:"-" json:"serviceName,omitempty"`
+	Currency     string `json:"currency"`
+	TotalAmount  int64  `json:"totalAmount"` // In minor units, e.g. cents
+	BookingCount int64  `json:"bookingCount"`
+}
+
+// CurrencyRevenue is the revenue earned across all services in one currency.
+type CurrencyRevenue struct {
+	Currency     string `json:"currency"`
+	TotalAmount  int64  `json:"totalAmount"` // In minor units, e.g. cents
+	BookingCount int64  `json:"bookingCount"`
+}
+
+// ConvertedRevenue is the revenue earned across all currencies, converted into one currency.
+type ConvertedRevenue struct {
+	Currency     string             `json:"currency"`
+	TotalAmount  int64              `json:"totalAmount"` // In Currency's minor units; each currency's total is rounded to the minor unit before being added
+	BookingCount int64              `json:"bookingCount"`
+	Rates        map[string]float64 `json:"rates"` // Rate applied to each source currency
+}
@@ -13,15 +13,41 @@ type ServiceDefinition struct {
 	Name            string    `gorm:"type:varchar(255);not null" json:"name"`
 	Description     string    `gorm:"type:text" json:"description"`
 	DurationMinutes int       `gorm:"not null" json:"durationMinutes"` // Duration in minutes
-	Price           int64     `gorm:"not null" json:"price"`           // Price in cents to avoid floating point issues
+	Price           int64     `gorm:"not null" json:"price"`           // Price in the currency's minor units (see CurrencyExponent) to avoid floating point issues
 	Currency        string    `gorm:"type:varchar(10);not null" json:"currency"` // e.g., "USD"
 	IsActive        bool      `gorm:"default:true" json:"isActive"`
+	// Category is the business-defined kind of service, e.g. "massage"; marketplace searches match on it
+	Category string `gorm:"index;type:varchar(100)" json:"category,omitempty"`
+	// MetadataSchema optionally defines the custom fields collected when booking this service
+	MetadataSchema *MetadataSchema `gorm:"type:jsonb" json:"metadataSchema,omitempty"`
+	// RequiresPayment is false for free services whose bookings are confirmed immediately; nil means true
+	RequiresPayment *bool `gorm:"default:true" json:"requiresPayment"`
+	// Optional display metadata so calendar UIs can tell services apart without hardcoding colors
+	Color      string `gorm:"type:varchar(7)" json:"color,omitempty"`       // Hex color, e.g. "#4F46E5"
+	ShortLabel string `gorm:"type:varchar(16)" json:"shortLabel,omitempty"` // Abbreviation for narrow calendar cells
+	// CancellationFee overrides the business's cancellation fee for this service, in minor units
+	CancellationFee *int64 `gorm:"type:bigint" json:"cancellationFee,omitempty"`
+	// Capacity is how many customers can book the same slot, e.g. the places in a group class; 1 for one-to-one services
+	Capacity int `gorm:"not null;default:1" json:"capacity"`
 
 	CreatedAt time.Time      `json:"createdAt"`
 	UpdatedAt time.Time      `json:"updatedAt"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
+// PaymentRequired reports whether bookings of this service wait for payment before being confirmed.
+func (s *ServiceDefinition) PaymentRequired() bool {
+	return s.RequiresPayment == nil || *s.RequiresPayment
+}
+
+// SlotCapacity returns how many bookings a slot of this service takes, treating a missing capacity as 1.
+func (s *ServiceDefinition) SlotCapacity() int {
+	if s.Capacity < 1 {
+		return 1
+	}
+	return s.Capacity
+}
+
 // TableName explicitly sets the table name.
 func (ServiceDefinition) TableName() string {
 	return "service_definitions"
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// SlotHold is a short-lived reservation of a slot while the customer completes checkout.
+// Holds live in Redis and disappear when they expire or the booking is created.
+type SlotHold struct {
+	ID         string    `json:"id"`
+	BusinessID string    `json:"businessId"`
+	ServiceID  string    `json:"serviceId"`
+	UserID     string    `json:"userId"` // The user who made the hold; only they can book with it
+	StartTime  time.Time `json:"startTime"`
+	EndTime    time.Time `json:"endTime"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
+
+// Overlaps reports whether the hold covers any part of [start, end).
+func (h SlotHold) Overlaps(start, end time.Time) bool {
+	return h.StartTime.Before(end) && h.EndTime.After(start)
+}
+
+// MarshalJSON renders the hold's times as RFC3339 in UTC, like Booking.
+func (h SlotHold) MarshalJSON() ([]byte, error) {
+	type holdJSON SlotHold // Drops this method to avoid recursion
+	out := holdJSON(h)
+	out.StartTime = out.StartTime.UTC()
+	out.EndTime = out.EndTime.UTC()
+	out.ExpiresAt = out.ExpiresAt.UTC()
+	return json.Marshal(out)
+}
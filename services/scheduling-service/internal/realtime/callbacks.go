@@ -0,0 +1,223 @@
+package realtime
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"syscall"
+	"time"
+)
+
+const (
+	// callbackTimeout bounds each POST to a callback URL, so a slow integrator cannot pile up deliveries.
+	callbackTimeout = 5 * time.Second
+	// callbackLookupTimeout bounds resolving a callback URL's host when it is registered.
+	callbackLookupTimeout = 2 * time.Second
+	// maxConcurrentCallbacks bounds the callback POSTs in flight across all businesses. Deliveries past it
+	// are dropped and counted as failed rather than queued, like messages for a WebSocket client that is behind.
+	maxConcurrentCallbacks = 32
+)
+
+// ErrInvalidCallbackURL is returned by RegisterCallback for URLs that are not absolute http(s) URLs.
+var ErrInvalidCallbackURL = errors.New("invalid callback URL")
+
+// ErrForbiddenCallbackTarget is returned by RegisterCallback, and fails deliveries, for callback URLs that
+// resolve to loopback, private, link-local (including cloud metadata) or other internal addresses.
+var ErrForbiddenCallbackTarget = errors.New("callback URL targets an internal address")
+
+// carrierGradeNAT is the shared address space of RFC 6598, internal to providers like the private ranges.
+var carrierGradeNAT = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// CallbackStore persists callback registrations so they survive restarts; satisfied by
+// *repository.RealtimeCallbackRepository.
+type CallbackStore interface {
+	SaveCallback(ctx context.Context, businessID, callbackURL string) error
+	DeleteCallback(ctx context.Context, businessID string) error
+	ListCallbacks(ctx context.Context) (map[string]string, error)
+}
+
+// CallbackInfo is a registered HTTP callback, used by the admin API.
+type CallbackInfo struct {
+	BusinessID string `json:"businessId"`
+	URL        string `json:"url"`
+}
+
+// LoadCallbacks replaces the registered callbacks with those in CallbackStore. Call it once at startup,
+// before events are handled; without a store it does nothing.
+func (m *SubscriptionManager) LoadCallbacks(ctx context.Context) error {
+	if m.CallbackStore == nil {
+		return nil
+	}
+	callbacks, err := m.CallbackStore.ListCallbacks(ctx)
+	if err != nil {
+		return fmt.Errorf("load realtime callbacks: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.callbacks = callbacks
+	m.Logger.Info("Realtime callbacks loaded", "count", len(callbacks))
+	return nil
+}
+
+// RegisterCallback has every message sent to a business's WebSocket clients also POSTed to callbackURL,
+// for integrators that cannot hold a WebSocket. A business has at most one callback; registering again replaces it.
+// Unless AllowPrivateCallbacks is set, URLs whose host resolves to an internal address are rejected.
+func (m *SubscriptionManager) RegisterCallback(ctx context.Context, businessID, callbackURL string) error {
+	parsed, err := url.Parse(callbackURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%w: %q must be an absolute http or https URL", ErrInvalidCallbackURL, callbackURL)
+	}
+	if !m.AllowPrivateCallbacks {
+		if err := checkCallbackHost(ctx, parsed.Hostname()); err != nil {
+			return err
+		}
+	}
+
+	if m.CallbackStore != nil {
+		if err := m.CallbackStore.SaveCallback(ctx, businessID, callbackURL); err != nil {
+			return fmt.Errorf("save realtime callback: %w", err)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.callbacks[businessID] = callbackURL
+	m.Logger.Info("Realtime callback registered", "businessId", businessID, "url", callbackURL)
+	return nil
+}
+
+// UnregisterCallback stops HTTP delivery for a business. Returns false if it had no callback.
+func (m *SubscriptionManager) UnregisterCallback(ctx context.Context, businessID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.callbacks[businessID]; !ok {
+		return false, nil
+	}
+	if m.CallbackStore != nil {
+		if err := m.CallbackStore.DeleteCallback(ctx, businessID); err != nil {
+			return false, fmt.Errorf("delete realtime callback: %w", err)
+		}
+	}
+	delete(m.callbacks, businessID)
+	m.Logger.Info("Realtime callback unregistered", "businessId", businessID)
+	return true, nil
+}
+
+// ListCallbacks returns the registered callbacks ordered by business ID.
+func (m *SubscriptionManager) ListCallbacks() []CallbackInfo {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	callbacks := make([]CallbackInfo, 0, len(m.callbacks))
+	for businessID, callbackURL := range m.callbacks {
+		callbacks = append(callbacks, CallbackInfo{BusinessID: businessID, URL: callbackURL})
+	}
+	sort.Slice(callbacks, func(i, j int) bool {
+		return callbacks[i].BusinessID < callbacks[j].BusinessID
+	})
+	return callbacks
+}
+
+// FailedCallbacks returns how many callback deliveries have failed or been dropped since startup.
+func (m *SubscriptionManager) FailedCallbacks() int64 {
+	return m.failedCallbacks.Load()
+}
+
+// dispatchCallback posts a message to a business's callback in the background, so a slow callback never
+// holds up WebSocket clients. With maxConcurrentCallbacks posts already in flight the message is dropped.
+func (m *SubscriptionManager) dispatchCallback(businessID, callbackURL string, message []byte) {
+	select {
+	case m.callbackSlots <- struct{}{}:
+		go func() {
+			defer func() { <-m.callbackSlots }()
+			m.postCallback(businessID, callbackURL, message)
+		}()
+	default:
+		m.failedCallbacks.Add(1)
+		m.Logger.Warn("Realtime callback dropped, too many deliveries in flight", "businessId", businessID, "url", callbackURL)
+	}
+}
+
+// postCallback delivers one message to a business's callback URL. Deliveries are best effort like
+// WebSocket sends: a failed POST is counted and logged, not retried.
+func (m *SubscriptionManager) postCallback(businessID, callbackURL string, message []byte) {
+	resp, err := m.callbackClient().Post(callbackURL, "application/json", bytes.NewReader(message))
+	if err != nil {
+		m.failedCallbacks.Add(1)
+		m.Logger.Warn("Realtime callback delivery failed", "businessId", businessID, "url", callbackURL, "error", err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		m.failedCallbacks.Add(1)
+		m.Logger.Warn("Realtime callback rejected", "businessId", businessID, "url", callbackURL, "status", resp.StatusCode)
+		return
+	}
+	m.Logger.Debug("Realtime callback delivered", "businessId", businessID, "url", callbackURL)
+}
+
+// callbackClient returns CallbackClient, or else a client with callbackTimeout whose connections may only
+// reach public addresses unless AllowPrivateCallbacks is set. The address is checked when each connection
+// is made, so a host that resolves differently after registration, or a redirect, cannot reach inside.
+func (m *SubscriptionManager) callbackClient() *http.Client {
+	if m.CallbackClient != nil {
+		return m.CallbackClient
+	}
+	m.defaultCallbackClientOnce.Do(func() {
+		dialer := &net.Dialer{Timeout: callbackTimeout}
+		if !m.AllowPrivateCallbacks {
+			dialer.Control = func(network, address string, _ syscall.RawConn) error {
+				host, _, err := net.SplitHostPort(address)
+				if err != nil {
+					return err
+				}
+				if ip := net.ParseIP(host); ip == nil || isInternalIP(ip) {
+					return fmt.Errorf("%w: %s", ErrForbiddenCallbackTarget, host)
+				}
+				return nil
+			}
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = nil // A proxy would make the connection for us, out of reach of the dialer's check
+		transport.DialContext = dialer.DialContext
+		m.defaultCallbackClient = &http.Client{Timeout: callbackTimeout, Transport: transport}
+	})
+	return m.defaultCallbackClient
+}
+
+// checkCallbackHost rejects a callback host that is, or resolves to, an internal address.
+func checkCallbackHost(ctx context.Context, host string) error {
+	if ip := net.ParseIP(host); ip != nil {
+		if isInternalIP(ip) {
+			return fmt.Errorf("%w: %s", ErrForbiddenCallbackTarget, host)
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, callbackLookupTimeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		return fmt.Errorf("%w: host %q does not resolve", ErrInvalidCallbackURL, host)
+	}
+	for _, addr := range addrs {
+		if isInternalIP(addr.IP) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrForbiddenCallbackTarget, host, addr.IP)
+		}
+	}
+	return nil
+}
+
+// isInternalIP reports whether ip is not a public unicast address: loopback, private, link-local (which
+// holds the cloud metadata endpoint 169.254.169.254), carrier-grade NAT, multicast or unspecified.
+func isInternalIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() || carrierGradeNAT.Contains(ip)
+}
@@ -0,0 +1,138 @@
+package realtime_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/slotwise/scheduling-service/internal/realtime"
+	"github.com/slotwise/scheduling-service/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryCallbackStore is a CallbackStore kept in a map.
+type memoryCallbackStore struct {
+	mu        sync.Mutex
+	callbacks map[string]string
+}
+
+func newMemoryCallbackStore() *memoryCallbackStore {
+	return &memoryCallbackStore{callbacks: make(map[string]string)}
+}
+
+func (s *memoryCallbackStore) SaveCallback(_ context.Context, businessID, callbackURL string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.callbacks[businessID] = callbackURL
+	return nil
+}
+
+func (s *memoryCallbackStore) DeleteCallback(_ context.Context, businessID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.callbacks, businessID)
+	return nil
+}
+
+func (s *memoryCallbackStore) ListCallbacks(_ context.Context) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	callbacks := make(map[string]string, len(s.callbacks))
+	for businessID, callbackURL := range s.callbacks {
+		callbacks[businessID] = callbackURL
+	}
+	return callbacks, nil
+}
+
+func TestRegisterCallback_RejectsInternalTargets(t *testing.T) {
+	manager := realtime.NewSubscriptionManager(logger.New("debug"), nil)
+
+	for _, callbackURL := range []string{
+		"http://127.0.0.1:8080/hook",
+		"http://localhost/hook",
+		"http://[::1]/hook",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://10.0.0.5/hook",
+		"http://192.168.1.10/hook",
+		"http://100.64.0.1/hook",
+		"http://0.0.0.0/hook",
+	} {
+		err := manager.RegisterCallback(context.Background(), "biz_ws", callbackURL)
+		assert.ErrorIs(t, err, realtime.ErrForbiddenCallbackTarget, callbackURL)
+	}
+	assert.Empty(t, manager.ListCallbacks())
+
+	// Public addresses are accepted
+	require.NoError(t, manager.RegisterCallback(context.Background(), "biz_ws", "https://93.184.216.34/hook"))
+	assert.Len(t, manager.ListCallbacks(), 1)
+}
+
+func TestRegisterCallback_PersistsRegistrations(t *testing.T) {
+	store := newMemoryCallbackStore()
+	manager := realtime.NewSubscriptionManager(logger.New("debug"), nil)
+	manager.CallbackStore = store
+
+	require.NoError(t, manager.RegisterCallback(context.Background(), "biz_a", "https://93.184.216.34/a"))
+	require.NoError(t, manager.RegisterCallback(context.Background(), "biz_b", "https://93.184.216.34/b"))
+	removed, err := manager.UnregisterCallback(context.Background(), "biz_b")
+	require.NoError(t, err)
+	assert.True(t, removed)
+
+	// A restarted manager picks the registrations back up from the store
+	restarted := realtime.NewSubscriptionManager(logger.New("debug"), nil)
+	restarted.CallbackStore = store
+	require.NoError(t, restarted.LoadCallbacks(context.Background()))
+	assert.Equal(t, []realtime.CallbackInfo{{BusinessID: "biz_a", URL: "https://93.184.216.34/a"}}, restarted.ListCallbacks())
+}
+
+func TestSendToBusiness_BlocksInternalCallbacksAtDelivery(t *testing.T) {
+	var calls atomic.Int64
+	callbackServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer callbackServer.Close()
+
+	// A loopback URL that got into the store some other way is still refused when connecting
+	store := newMemoryCallbackStore()
+	store.callbacks["biz_ws"] = callbackServer.URL
+	manager := realtime.NewSubscriptionManager(logger.New("debug"), nil)
+	manager.CallbackStore = store
+	require.NoError(t, manager.LoadCallbacks(context.Background()))
+
+	manager.SendToBusiness("biz_ws", []byte(`{"type":"availability_updated"}`))
+
+	assert.Eventually(t, func() bool {
+		return manager.FailedCallbacks() == 1
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, int64(0), calls.Load())
+}
+
+func TestSendToBusiness_BoundsCallbacksInFlight(t *testing.T) {
+	var calls atomic.Int64
+	release := make(chan struct{})
+	callbackServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		<-release
+	}))
+	defer callbackServer.Close()
+
+	manager := realtime.NewSubscriptionManager(logger.New("debug"), nil)
+	manager.AllowPrivateCallbacks = true
+	require.NoError(t, manager.RegisterCallback(context.Background(), "biz_ws", callbackServer.URL))
+
+	// 32 deliveries may be in flight; with the callback stuck the other 8 are dropped
+	for i := 0; i < 40; i++ {
+		manager.SendToBusiness("biz_ws", []byte(`{"type":"availability_updated"}`))
+	}
+	assert.Equal(t, int64(8), manager.FailedCallbacks())
+	assert.Eventually(t, func() bool {
+		return calls.Load() == 32
+	}, 2*time.Second, 10*time.Millisecond)
+
+	close(release)
+}
@@ -1,7 +1,12 @@
 package realtime
 
 import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"encoding/json"
@@ -21,8 +26,32 @@ const (
 	pingPeriod = (pongWait * 9) / 10
 	// Maximum message size allowed from peer.
 	maxMessageSize = 512
+	// A client's dropped messages are logged on the first drop and then once per this many,
+	// so a stuck client cannot flood the logs.
+	dropLogSampleRate = 100
+	// DefaultMaxEventBytes is the largest NATS event payload handled when MaxEventBytes is not set.
+	DefaultMaxEventBytes = 64 * 1024
+	// Raw payloads of rejected events are cut to this many bytes in logs.
+	maxLoggedPayloadBytes = 256
 )
 
+// ErrMalformedEvent is returned by HandleEvent for events that are too large, not JSON, or missing their businessId.
+var ErrMalformedEvent = errors.New("malformed event")
+
+// EventPublisher publishes rejected events to the dead-letter subject; satisfied by *events.Publisher.
+type EventPublisher interface {
+	Publish(subject string, data interface{}) error
+}
+
+// DeadLetter is published to the dead-letter subject for every rejected event.
+type DeadLetter struct {
+	Subject    string    `json:"subject"` // Subject the event arrived on
+	Reason     string    `json:"reason"`
+	Payload    string    `json:"payload,omitempty"` // Raw event; omitted for oversized events
+	Size       int       `json:"size"`
+	RejectedAt time.Time `json:"rejectedAt"`
+}
+
 // Client is a middleman between the websocket connection and the hub.
 type Client struct {
 	ID string
@@ -33,8 +62,28 @@ type Client struct {
 	// BusinessID this client is subscribed to for targeted updates.
 	// A client might subscribe to one specific business's updates.
 	BusinessID string
+	// CustomerID is set once the client subscribes to a customer's own bookings.
+	CustomerID string
+	// ConnectedAt is when the WebSocket connection was upgraded.
+	ConnectedAt time.Time
 	// Reference to the manager.
 	Manager *SubscriptionManager
+	// Messages dropped because Send was full; updated under the manager's read lock, hence atomic.
+	dropped atomic.Int64
+}
+
+// DroppedMessages returns how many messages were dropped for this client because its send buffer was full.
+func (c *Client) DroppedMessages() int64 {
+	return c.dropped.Load()
+}
+
+// ClientInfo is a read-only snapshot of a connected client, used by the admin API.
+type ClientInfo struct {
+	ID              string    `json:"id"`
+	BusinessID      string    `json:"businessId"`
+	CustomerID      string    `json:"customerId,omitempty"`
+	ConnectedAt     time.Time `json:"connectedAt"`
+	DroppedMessages int64     `json:"droppedMessages"` // A growing count marks a slow client worth disconnecting
 }
 
 // SubscriptionManager maintains the set of active clients and broadcasts messages.
@@ -49,24 +98,54 @@ type SubscriptionManager struct {
 	unregister chan *Client
 	// Subscriptions: businessID -> set of clients.
 	subscriptions map[string]map[*Client]bool
+	// Customer subscriptions: customerID -> set of clients watching that customer's bookings.
+	customerSubscriptions map[string]map[*Client]bool
 	// Logger
 	Logger *logger.Logger
 	// NATS Event Subscriber
 	Subscriber *events.Subscriber // Added field
 	// Mutex for protecting concurrent access to clients and subscriptions maps.
 	mu sync.RWMutex
+	// Total messages dropped across all clients since startup.
+	droppedMessages atomic.Int64
+	// MaxEventBytes caps the size of NATS events handled; 0 means DefaultMaxEventBytes.
+	MaxEventBytes int
+	// DeadLetterPublisher and DeadLetterSubject route rejected events for inspection; nil or empty disables it.
+	DeadLetterPublisher EventPublisher
+	DeadLetterSubject   string
+	// Events rejected as malformed since startup.
+	malformedEvents atomic.Int64
+	// HTTP callbacks: businessID -> URL that business messages are also POSTed to.
+	callbacks map[string]string
+	// CallbackClient posts to callback URLs; nil uses a client with callbackTimeout that only connects
+	// to public addresses.
+	CallbackClient *http.Client
+	// CallbackStore persists callback registrations; nil keeps them in memory only.
+	CallbackStore CallbackStore
+	// AllowPrivateCallbacks lets callbacks target loopback and private addresses, for local development.
+	AllowPrivateCallbacks bool
+	// Callback deliveries that failed or were dropped since startup.
+	failedCallbacks atomic.Int64
+	// One slot per callback POST in flight, at most maxConcurrentCallbacks.
+	callbackSlots chan struct{}
+	// Client used when CallbackClient is nil, built on first use.
+	defaultCallbackClient     *http.Client
+	defaultCallbackClientOnce sync.Once
 }
 
 // NewSubscriptionManager creates a new SubscriptionManager.
 func NewSubscriptionManager(logger *logger.Logger, subscriber *events.Subscriber) *SubscriptionManager { // Added subscriber
 	return &SubscriptionManager{
-		broadcast:     make(chan []byte),
-		register:      make(chan *Client),
-		unregister:    make(chan *Client),
-		clients:       make(map[*Client]bool),
-		subscriptions: make(map[string]map[*Client]bool),
-		Logger:        logger,
-		Subscriber:    subscriber, // Store subscriber
+		broadcast:             make(chan []byte),
+		register:              make(chan *Client),
+		unregister:            make(chan *Client),
+		clients:               make(map[*Client]bool),
+		subscriptions:         make(map[string]map[*Client]bool),
+		customerSubscriptions: make(map[string]map[*Client]bool),
+		callbacks:             make(map[string]string),
+		callbackSlots:         make(chan struct{}, maxConcurrentCallbacks),
+		Logger:                logger,
+		Subscriber:            subscriber, // Store subscriber
 	}
 }
 
@@ -102,6 +181,12 @@ func (m *SubscriptionManager) Run() {
 						m.Logger.Info("Client unregistered from business", "clientId", client.ID, "businessId", businessID)
 					}
 				}
+				if client.CustomerID != "" {
+					delete(m.customerSubscriptions[client.CustomerID], client)
+					if len(m.customerSubscriptions[client.CustomerID]) == 0 {
+						delete(m.customerSubscriptions, client.CustomerID)
+					}
+				}
 				m.Logger.Info("Client unregistered", "clientId", client.ID)
 			}
 			m.mu.Unlock()
@@ -141,6 +226,27 @@ func (m *SubscriptionManager) RegisterClient(client *Client, businessID string)
 	m.Logger.Info("Client subscribed to business", "clientId", client.ID, "businessId", businessID)
 }
 
+// SubscribeCustomer subscribes a client to status changes of one customer's bookings.
+// The caller must have authorized the client for customerID.
+func (m *SubscriptionManager) SubscribeCustomer(client *Client, customerID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if client.CustomerID != "" && client.CustomerID != customerID {
+		delete(m.customerSubscriptions[client.CustomerID], client)
+		if len(m.customerSubscriptions[client.CustomerID]) == 0 {
+			delete(m.customerSubscriptions, client.CustomerID)
+		}
+	}
+	client.CustomerID = customerID
+
+	if _, ok := m.customerSubscriptions[customerID]; !ok {
+		m.customerSubscriptions[customerID] = make(map[*Client]bool)
+	}
+	m.customerSubscriptions[customerID][client] = true
+	m.Logger.Info("Client subscribed to customer bookings", "clientId", client.ID, "customerId", customerID)
+}
+
 // UnregisterClient removes a client from all its subscriptions and the manager.
 // This is typically called when a client disconnects.
 func (m *SubscriptionManager) UnregisterClient(client *Client) {
@@ -149,31 +255,117 @@ func (m *SubscriptionManager) UnregisterClient(client *Client) {
 	m.unregister <- client
 }
 
-// SendToBusiness sends a message to all clients subscribed to a specific businessID.
+// ListClients returns a snapshot of all registered clients, oldest connection first.
+func (m *SubscriptionManager) ListClients() []ClientInfo {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	clients := make([]ClientInfo, 0, len(m.clients))
+	for client := range m.clients {
+		clients = append(clients, ClientInfo{
+			ID:              client.ID,
+			BusinessID:      client.BusinessID,
+			CustomerID:      client.CustomerID,
+			ConnectedAt:     client.ConnectedAt,
+			DroppedMessages: client.DroppedMessages(),
+		})
+	}
+	sort.Slice(clients, func(i, j int) bool {
+		return clients[i].ConnectedAt.Before(clients[j].ConnectedAt)
+	})
+	return clients
+}
+
+// DisconnectClient force-disconnects the client with the given ID.
+// The client is unregistered, which closes its send channel; the write pump then
+// sends a close frame and closes the connection. Returns false if no such client exists.
+func (m *SubscriptionManager) DisconnectClient(clientID string) bool {
+	m.mu.RLock()
+	var target *Client
+	for client := range m.clients {
+		if client.ID == clientID {
+			target = client
+			break
+		}
+	}
+	m.mu.RUnlock()
+
+	if target == nil {
+		return false
+	}
+
+	m.Logger.Info("Force-disconnecting client", "clientId", clientID, "businessId", target.BusinessID)
+	m.UnregisterClient(target)
+	return true
+}
+
+// SendToBusiness sends a message to all clients subscribed to a specific businessID,
+// and POSTs it to the business's callback URL if one is registered.
 func (m *SubscriptionManager) SendToBusiness(businessID string, message []byte) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
+	if callbackURL, ok := m.callbacks[businessID]; ok {
+		m.dispatchCallback(businessID, callbackURL, message)
+	}
+
 	if subscribers, ok := m.subscriptions[businessID]; ok {
 		m.Logger.Info("Sending message to business", "businessId", businessID, "numSubscribers", len(subscribers))
 		for client := range subscribers {
-			// Non-blocking send: if client's send buffer is full, drop the message for this client.
-			// This prevents one slow client from blocking message delivery to others.
-			select {
-			case client.Send <- message:
-				m.Logger.Debug("Message sent to client", "clientId", client.ID, "businessId", businessID)
-			default:
-				// Client's channel is full. We might log this, or decide to unregister
-				// the client if this happens too often, assuming it's unresponsive.
-				// For now, just log it. The writePump for the client will handle closing if writes fail.
-				m.Logger.Warn("Client send channel full, message dropped", "clientId", client.ID, "businessId", businessID)
-			}
+			m.deliver(client, message)
 		}
 	} else {
 		m.Logger.Info("No subscribers for business to send message", "businessId", businessID)
 	}
 }
 
+// SendToCustomer sends a message to all clients subscribed to a customer's bookings.
+func (m *SubscriptionManager) SendToCustomer(customerID string, message []byte) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for client := range m.customerSubscriptions[customerID] {
+		m.deliver(client, message)
+	}
+}
+
+// SendToClient sends a message to a single client, unless it has already been unregistered.
+func (m *SubscriptionManager) SendToClient(client *Client, message []byte) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.clients[client] {
+		m.deliver(client, message)
+	}
+}
+
+// deliver queues a message for a client without blocking; the caller holds at least the read lock.
+func (m *SubscriptionManager) deliver(client *Client, message []byte) {
+	// Non-blocking send: if client's send buffer is full, drop the message for this client.
+	// This prevents one slow client from blocking message delivery to others.
+	select {
+	case client.Send <- message:
+		m.Logger.Debug("Message sent to client", "clientId", client.ID, "businessId", client.BusinessID, "customerId", client.CustomerID)
+	default:
+		// Client's channel is full. The drop is counted so slow clients show up in the
+		// admin client list and can be disconnected there.
+		m.droppedMessages.Add(1)
+		if dropped := client.dropped.Add(1); dropped%dropLogSampleRate == 1 {
+			m.Logger.Warn("Client send channel full, message dropped", "clientId", client.ID, "businessId", client.BusinessID, "customerId", client.CustomerID, "droppedForClient", dropped)
+		}
+	}
+}
+
+// DroppedMessages returns how many messages have been dropped across all clients because their send buffers were full.
+func (m *SubscriptionManager) DroppedMessages() int64 {
+	return m.droppedMessages.Load()
+}
+
+// MalformedEvents returns how many NATS events have been rejected as malformed since startup.
+func (m *SubscriptionManager) MalformedEvents() int64 {
+	return m.malformedEvents.Load()
+}
+
 // Helper function to generate unique client IDs
 func GenerateClientID() string {
 	return uuid.New().String()
@@ -185,23 +377,84 @@ type WebSocketMessage struct {
 	Payload interface{} `json:"payload"`
 }
 
-// handleBookingEvent processes booking-related NATS events and forwards them to relevant WebSocket clients.
-func (m *SubscriptionManager) handleBookingEvent(data []byte, eventType string) {
-	m.Logger.Info("Handling booking event via NATS", "eventType", eventType, "dataLength", len(data))
+// HandleEvent forwards a NATS event received on subject to the WebSocket clients of its business.
+// Events that are too large, not JSON objects, or missing their businessId are counted, sent to the
+// dead-letter subject if one is configured, and reported with ErrMalformedEvent.
+func (m *SubscriptionManager) HandleEvent(subject string, data []byte) error {
+	eventData, businessID, err := m.decodeEvent(data)
+	if err != nil {
+		m.rejectEvent(subject, data, err)
+		return fmt.Errorf("%w on %s: %v", ErrMalformedEvent, subject, err)
+	}
+
+	switch subject {
+	case events.BookingConfirmedEvent:
+		// Aligning with problem description: NATS BookingConfirmedEvent maps to WS "booking_created" type.
+		return m.handleBookingEvent(businessID, eventData, "booking_created")
+	case events.BookingCancelledEvent:
+		return m.handleBookingEvent(businessID, eventData, "booking_cancelled")
+	case events.AvailabilityRuleUpdatedEvent:
+		return m.handleAvailabilityRuleEvent(businessID, eventData)
+	default:
+		return fmt.Errorf("no realtime handler for subject %s", subject)
+	}
+}
+
+// decodeEvent checks an event's size and decodes it, returning its businessId.
+func (m *SubscriptionManager) decodeEvent(data []byte) (map[string]interface{}, string, error) {
+	if maxBytes := m.maxEventBytes(); len(data) > maxBytes {
+		return nil, "", fmt.Errorf("payload of %d bytes exceeds the %d byte limit", len(data), maxBytes)
+	}
+
 	var eventData map[string]interface{}
 	if err := json.Unmarshal(data, &eventData); err != nil {
-		m.Logger.Error("Failed to unmarshal booking event data from NATS", "eventType", eventType, "error", err, "rawData", string(data))
-		return
+		return nil, "", fmt.Errorf("invalid JSON: %w", err)
+	}
+	if eventData == nil {
+		return nil, "", errors.New("payload is not a JSON object")
 	}
-
 	businessID, ok := eventData["businessId"].(string)
-	if !ok {
-		m.Logger.Error("businessId missing or not a string in booking event data", "eventType", eventType, "eventData", eventData)
+	if !ok || businessID == "" {
+		return nil, "", errors.New("businessId missing or not a string")
+	}
+	return eventData, businessID, nil
+}
+
+// maxEventBytes returns the configured event size limit or the default.
+func (m *SubscriptionManager) maxEventBytes() int {
+	if m.MaxEventBytes <= 0 {
+		return DefaultMaxEventBytes
+	}
+	return m.MaxEventBytes
+}
+
+// rejectEvent counts a malformed event and routes it to the dead-letter subject, if configured.
+func (m *SubscriptionManager) rejectEvent(subject string, data []byte, reason error) {
+	m.malformedEvents.Add(1)
+	logged := data
+	if len(logged) > maxLoggedPayloadBytes {
+		logged = logged[:maxLoggedPayloadBytes]
+	}
+	m.Logger.Warn("Rejected malformed realtime event", "subject", subject, "reason", reason, "size", len(data), "rawData", string(logged))
+
+	if m.DeadLetterPublisher == nil || m.DeadLetterSubject == "" {
 		return
 	}
+	deadLetter := DeadLetter{Subject: subject, Reason: reason.Error(), Size: len(data), RejectedAt: time.Now().UTC()}
+	if len(data) <= m.maxEventBytes() {
+		deadLetter.Payload = string(data)
+	}
+	if err := m.DeadLetterPublisher.Publish(m.DeadLetterSubject, deadLetter); err != nil {
+		m.Logger.Error("Failed to publish rejected realtime event to dead-letter subject", "subject", subject, "deadLetterSubject", m.DeadLetterSubject, "error", err)
+	}
+}
 
+// handleBookingEvent forwards a booking event to the business's WebSocket clients and,
+// as a "booking_updated" message, to the clients watching the booking's customer.
+func (m *SubscriptionManager) handleBookingEvent(businessID string, eventData map[string]interface{}, eventType string) error {
 	// Construct WebSocket message payload
 	// Example: {"bookingId": "...", "serviceId": "...", "startTime": "...", "endTime": "...", "status": "..."}
+	// Confirmations of new bookings also carry "capacity" and "remainingCapacity", the places left in the slot.
 	// Ensure all necessary fields are present in eventData from the publisher
 	wsPayload := eventData // Use the whole event data as payload for now, can be more specific
 
@@ -212,29 +465,27 @@ func (m *SubscriptionManager) handleBookingEvent(data []byte, eventType string)
 
 	jsonMessage, err := json.Marshal(wsMessage)
 	if err != nil {
-		m.Logger.Error("Failed to marshal WebSocket message for booking event", "eventType", eventType, "error", err)
-		return
+		return fmt.Errorf("marshal WebSocket message for %s: %w", eventType, err)
 	}
 
 	m.Logger.Info("Sending booking update to business via WebSocket", "businessId", businessID, "eventType", eventType)
 	m.SendToBusiness(businessID, jsonMessage)
-}
 
-// handleAvailabilityRuleEvent processes availability rule update NATS events.
-func (m *SubscriptionManager) handleAvailabilityRuleEvent(data []byte) {
-	m.Logger.Info("Handling availability rule event via NATS", "dataLength", len(data))
-	var eventData map[string]interface{}
-	if err := json.Unmarshal(data, &eventData); err != nil {
-		m.Logger.Error("Failed to unmarshal availability rule event data from NATS", "error", err, "rawData", string(data))
-		return
+	// Guest bookings have no customer to notify
+	customerID, _ := eventData["customerId"].(string)
+	if customerID == "" {
+		return nil
 	}
-
-	businessID, ok := eventData["businessId"].(string)
-	if !ok {
-		m.Logger.Error("businessId missing or not a string in availability rule event", "eventData", eventData)
-		return
+	customerMessage, err := json.Marshal(WebSocketMessage{Type: "booking_updated", Payload: wsPayload})
+	if err != nil {
+		return fmt.Errorf("marshal WebSocket message for customer booking update: %w", err)
 	}
+	m.SendToCustomer(customerID, customerMessage)
+	return nil
+}
 
+// handleAvailabilityRuleEvent tells the business's WebSocket clients that its availability changed.
+func (m *SubscriptionManager) handleAvailabilityRuleEvent(businessID string, eventData map[string]interface{}) error {
 	// Default message if not provided in event
 	messageText := "Availability rules have been updated. Please refresh."
 	if msg, found := eventData["message"].(string); found && msg != "" {
@@ -252,12 +503,12 @@ func (m *SubscriptionManager) handleAvailabilityRuleEvent(data []byte) {
 
 	jsonMessage, err := json.Marshal(wsMessage)
 	if err != nil {
-		m.Logger.Error("Failed to marshal WebSocket message for availability rule event", "error", err)
-		return
+		return fmt.Errorf("marshal WebSocket message for availability update: %w", err)
 	}
 
 	m.Logger.Info("Sending availability update to business via WebSocket", "businessId", businessID)
 	m.SendToBusiness(businessID, jsonMessage)
+	return nil
 }
 
 // StartEventSubscriptions sets up NATS subscriptions for the SubscriptionManager.
@@ -268,41 +519,15 @@ func (m *SubscriptionManager) StartEventSubscriptions() {
 	}
 	m.Logger.Info("Starting NATS event subscriptions for SubscriptionManager")
 
-	err := m.Subscriber.Subscribe(events.BookingConfirmedEvent, func(data []byte) error {
-		// NATS handler func expects error return, but our internal handler doesn't. Adapt if needed.
-		// Aligning with problem description: NATS BookingConfirmedEvent maps to WS "booking_created" type.
-		m.handleBookingEvent(data, "booking_created")
-		return nil
-	})
-	if err != nil {
-		m.Logger.Error("Failed to subscribe to NATS BookingConfirmedEvent", "error", err)
-	} else {
-		m.Logger.Info("Subscribed to NATS BookingConfirmedEvent")
-	}
-
-	err = m.Subscriber.Subscribe(events.BookingCancelledEvent, func(data []byte) error {
-		// For cancellations, "booking_updated" or "booking_cancelled" are suitable.
-		// Let's use "booking_updated" for generic status changes, or keep "booking_cancelled" if specific.
-		// Sticking to "booking_cancelled" for now as it's specific and clear.
-		m.handleBookingEvent(data, "booking_cancelled")
-		return nil
-	})
-	if err != nil {
-		m.Logger.Error("Failed to subscribe to NATS BookingCancelledEvent", "error", err)
-	} else {
-		m.Logger.Info("Subscribed to NATS BookingCancelledEvent")
-	}
-
-	// The comment block below is now addressed by the change above for BookingConfirmedEvent.
-	// A generic "booking_updated" could be used for other status changes if needed.
-
-	err = m.Subscriber.Subscribe(events.AvailabilityRuleUpdatedEvent, func(data []byte) error {
-		m.handleAvailabilityRuleEvent(data)
-		return nil
-	})
-	if err != nil {
-		m.Logger.Error("Failed to subscribe to NATS AvailabilityRuleUpdatedEvent", "error", err)
-	} else {
-		m.Logger.Info("Subscribed to NATS AvailabilityRuleUpdatedEvent")
+	for _, subject := range []string{events.BookingConfirmedEvent, events.BookingCancelledEvent, events.AvailabilityRuleUpdatedEvent} {
+		subject := subject
+		err := m.Subscriber.Subscribe(subject, func(data []byte) error {
+			return m.HandleEvent(subject, data)
+		})
+		if err != nil {
+			m.Logger.Error("Failed to subscribe to NATS event", "subject", subject, "error", err)
+		} else {
+			m.Logger.Info("Subscribed to NATS event", "subject", subject)
+		}
 	}
 }
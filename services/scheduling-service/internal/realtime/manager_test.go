@@ -0,0 +1,113 @@
+package realtime_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/slotwise/scheduling-service/internal/realtime"
+	"github.com/slotwise/scheduling-service/pkg/events"
+	"github.com/slotwise/scheduling-service/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingPublisher records what is published to it.
+type recordingPublisher struct {
+	subjects []string
+	messages []interface{}
+}
+
+func (p *recordingPublisher) Publish(subject string, data interface{}) error {
+	p.subjects = append(p.subjects, subject)
+	p.messages = append(p.messages, data)
+	return nil
+}
+
+func TestSendToBusiness_CountsDroppedMessages(t *testing.T) {
+	manager := realtime.NewSubscriptionManager(logger.New("debug"), nil)
+	slow := &realtime.Client{ID: "slow", Send: make(chan []byte, 1)}
+	healthy := &realtime.Client{ID: "healthy", Send: make(chan []byte, 10)}
+	manager.RegisterClient(slow, "biz_ws")
+	manager.RegisterClient(healthy, "biz_ws")
+
+	for i := 0; i < 4; i++ {
+		manager.SendToBusiness("biz_ws", []byte(`{"type":"availability_updated"}`))
+	}
+
+	// The slow client's buffer holds one message; the other three are dropped
+	assert.Len(t, slow.Send, 1)
+	assert.Equal(t, int64(3), slow.DroppedMessages())
+	assert.Len(t, healthy.Send, 4)
+	assert.Equal(t, int64(0), healthy.DroppedMessages())
+	assert.Equal(t, int64(3), manager.DroppedMessages())
+}
+
+func TestHandleEvent_RejectsMalformedEvents(t *testing.T) {
+	manager := realtime.NewSubscriptionManager(logger.New("debug"), nil)
+	manager.MaxEventBytes = 1024
+	deadLetters := &recordingPublisher{}
+	manager.DeadLetterPublisher = deadLetters
+	manager.DeadLetterSubject = "realtime.dlq"
+	client := &realtime.Client{ID: "ws", Send: make(chan []byte, 10)}
+	manager.RegisterClient(client, "biz_ws")
+
+	malformed := map[string][]byte{
+		"missing businessId": []byte(`{"bookingId":"bkg_1","status":"CONFIRMED"}`),
+		"invalid JSON":       []byte(`{"businessId":`),
+		"not an object":      []byte(`null`),
+		"too large":          []byte(`{"businessId":"biz_ws","notes":"` + strings.Repeat("x", 2048) + `"}`),
+	}
+	for name, data := range malformed {
+		assert.NotPanics(t, func() {
+			err := manager.HandleEvent(events.BookingConfirmedEvent, data)
+			assert.ErrorIs(t, err, realtime.ErrMalformedEvent, name)
+		}, name)
+	}
+
+	assert.Equal(t, int64(len(malformed)), manager.MalformedEvents())
+	assert.Len(t, client.Send, 0, "malformed events must not reach clients")
+	require.Len(t, deadLetters.messages, len(malformed))
+	for i, message := range deadLetters.messages {
+		assert.Equal(t, "realtime.dlq", deadLetters.subjects[i])
+		deadLetter, ok := message.(realtime.DeadLetter)
+		require.True(t, ok)
+		assert.Equal(t, events.BookingConfirmedEvent, deadLetter.Subject)
+		if deadLetter.Size > 1024 {
+			assert.Empty(t, deadLetter.Payload, "oversized payloads are not republished")
+		} else {
+			assert.NotEmpty(t, deadLetter.Payload)
+		}
+	}
+}
+
+func TestHandleEvent_ForwardsValidEvents(t *testing.T) {
+	manager := realtime.NewSubscriptionManager(logger.New("debug"), nil)
+	client := &realtime.Client{ID: "ws", Send: make(chan []byte, 10)}
+	manager.RegisterClient(client, "biz_ws")
+
+	err := manager.HandleEvent(events.AvailabilityRuleUpdatedEvent, []byte(`{"businessId":"biz_ws"}`))
+	require.NoError(t, err)
+
+	require.Len(t, client.Send, 1)
+	assert.Contains(t, string(<-client.Send), `"type":"availability_updated"`)
+	assert.Equal(t, int64(0), manager.MalformedEvents())
+}
+
+func TestHandleEvent_BookingCreatedCarriesRemainingCapacity(t *testing.T) {
+	manager := realtime.NewSubscriptionManager(logger.New("debug"), nil)
+	client := &realtime.Client{ID: "ws", Send: make(chan []byte, 10)}
+	manager.RegisterClient(client, "biz_ws")
+
+	event := []byte(`{"bookingId":"bkg_group","businessId":"biz_ws","serviceId":"svc_group","newStatus":"CONFIRMED","capacity":3,"remainingCapacity":1}`)
+	require.NoError(t, manager.HandleEvent(events.BookingConfirmedEvent, event))
+
+	require.Len(t, client.Send, 1)
+	var msg realtime.WebSocketMessage
+	require.NoError(t, json.Unmarshal(<-client.Send, &msg))
+	assert.Equal(t, "booking_created", msg.Type)
+	payload, ok := msg.Payload.(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, float64(3), payload["capacity"])
+	assert.Equal(t, float64(1), payload["remainingCapacity"])
+}
@@ -2,7 +2,10 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/slotwise/scheduling-service/internal/models"
@@ -19,6 +22,12 @@ func NewBookingRepository(db *gorm.DB) *BookingRepository {
 	return &BookingRepository{db: db}
 }
 
+// WithTx returns a copy of the repository that runs its queries in tx, e.g. the transaction
+// AvailabilityRepository.WithBusinessLock passes to fn. Its transactional methods then run as savepoints of tx.
+func (r *BookingRepository) WithTx(tx *gorm.DB) *BookingRepository {
+	return &BookingRepository{db: tx}
+}
+
 // CreateBooking creates a new booking record in the database.
 func (r *BookingRepository) CreateBooking(ctx context.Context, booking *models.Booking) error {
 	if err := r.db.WithContext(ctx).Create(booking).Error; err != nil {
@@ -27,30 +36,207 @@ func (r *BookingRepository) CreateBooking(ctx context.Context, booking *models.B
 	return nil
 }
 
-// GetBookingByID retrieves a booking by its ID.
+// ImportBookings inserts bookings of one business in batches of batchSize within a single transaction,
+// so an import is either stored completely or not at all. The business's availability version is bumped
+// since imported bookings may take slots.
+func (r *BookingRepository) ImportBookings(ctx context.Context, businessID string, bookings []*models.Booking, batchSize int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := BumpAvailabilityVersion(tx, businessID); err != nil {
+			return err
+		}
+		if err := tx.CreateInBatches(bookings, batchSize).Error; err != nil {
+			return fmt.Errorf("error importing bookings: %w", err)
+		}
+		return nil
+	})
+}
+
+// OutboxMessage is an event to record in the outbox together with a booking.
+type OutboxMessage struct {
+	Subject string
+	Payload map[string]interface{}
+}
+
+// CreateBookingWithOutboxEvent creates a booking and its outbox event in a single transaction.
+// buildPayload is called after the insert so the payload can reference the generated booking ID.
+func (r *BookingRepository) CreateBookingWithOutboxEvent(
+	ctx context.Context,
+	booking *models.Booking,
+	subject string,
+	buildPayload func(*models.Booking) map[string]interface{},
+) (*models.OutboxEvent, error) {
+	outboxEvents, err := r.CreateBookingWithOutboxEvents(ctx, booking, func(b *models.Booking) []OutboxMessage {
+		return []OutboxMessage{{Subject: subject, Payload: buildPayload(b)}}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return outboxEvents[0], nil
+}
+
+// CreateBookingWithOutboxEvents creates a booking and several outbox events in a single transaction.
+// The events are returned in the order buildMessages lists them.
+func (r *BookingRepository) CreateBookingWithOutboxEvents(
+	ctx context.Context,
+	booking *models.Booking,
+	buildMessages func(*models.Booking) []OutboxMessage,
+) ([]*models.OutboxEvent, error) {
+	return r.CreateBookingWithinQuota(ctx, booking, BookingQuota{}, buildMessages)
+}
+
+// ErrBookingNotActive is returned by RescheduleBooking when the booking does not exist or is no longer
+// pending payment or confirmed.
+var ErrBookingNotActive = errors.New("booking not found or no longer active")
+
+// ErrBookingQuotaExceeded is returned when a booking would take a customer or business past its BookingQuota.
+var ErrBookingQuotaExceeded = errors.New("booking quota exceeded")
+
+// activeBookingStatuses are the statuses that hold on to their slot and so count against quotas.
+var activeBookingStatuses = []models.BookingStatus{models.BookingStatusConfirmed, models.BookingStatusPendingPayment}
+
+// BookingQuota limits the active bookings starting in [From, To) that a new booking may join.
+// A zero limit is unlimited.
+type BookingQuota struct {
+	From           time.Time
+	To             time.Time
+	MaxPerCustomer int64 // Active bookings of the booking's customer with its business; guest bookings are not counted
+	MaxPerBusiness int64 // Active bookings of the booking's business
+}
+
+// CreateBookingWithinQuota is CreateBookingWithOutboxEvents that first checks the quota in the same transaction.
+// The business row is locked before counting, so concurrent bookings for a business are counted one at a time
+// and cannot all pass a check that only one of them fits.
+func (r *BookingRepository) CreateBookingWithinQuota(
+	ctx context.Context,
+	booking *models.Booking,
+	quota BookingQuota,
+	buildMessages func(*models.Booking) []OutboxMessage,
+) ([]*models.OutboxEvent, error) {
+	var outboxEvents []*models.OutboxEvent
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Bumping the version takes the business's availability version row lock, held until commit
+		if err := BumpAvailabilityVersion(tx, booking.BusinessID); err != nil {
+			return err
+		}
+		if err := checkBookingQuota(tx, booking, quota); err != nil {
+			return err
+		}
+		if err := tx.Create(booking).Error; err != nil {
+			return fmt.Errorf("error creating booking: %w", err)
+		}
+
+		for _, msg := range buildMessages(booking) {
+			evt, err := newOutboxEvent(booking.ID, msg.Subject, msg.Payload)
+			if err != nil {
+				return err
+			}
+			if err := tx.Create(evt).Error; err != nil {
+				return fmt.Errorf("error creating outbox event for booking %s: %w", booking.ID, err)
+			}
+			outboxEvents = append(outboxEvents, evt)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return outboxEvents, nil
+}
+
+// CheckBookingQuota returns ErrBookingQuotaExceeded if booking would not fit in quota, without creating it.
+// Without the business lock the counts may change before a booking is made, so this only validates;
+// CreateBookingWithinQuota is what enforces the quota.
+func (r *BookingRepository) CheckBookingQuota(ctx context.Context, booking *models.Booking, quota BookingQuota) error {
+	return checkBookingQuota(r.db.WithContext(ctx), booking, quota)
+}
+
+// checkBookingQuota returns ErrBookingQuotaExceeded if booking does not fit in quota.
+func checkBookingQuota(tx *gorm.DB, booking *models.Booking, quota BookingQuota) error {
+	if quota.MaxPerBusiness > 0 {
+		count, err := CountActiveBusinessBookings(tx, booking.BusinessID, quota.From, quota.To)
+		if err != nil {
+			return err
+		}
+		if count >= quota.MaxPerBusiness {
+			return fmt.Errorf("%w: business %s already has %d active bookings", ErrBookingQuotaExceeded, booking.BusinessID, count)
+		}
+	}
+	if quota.MaxPerCustomer > 0 && booking.CustomerID != "" {
+		count, err := CountActiveCustomerBookings(tx, booking.BusinessID, booking.CustomerID, quota.From, quota.To)
+		if err != nil {
+			return err
+		}
+		if count >= quota.MaxPerCustomer {
+			return fmt.Errorf("%w: customer %s already has %d active bookings with business %s", ErrBookingQuotaExceeded, booking.CustomerID, count, booking.BusinessID)
+		}
+	}
+	return nil
+}
+
+// CountActiveBusinessBookings counts the pending and confirmed bookings of a business starting in [from, to).
+// For quota checks, call it inside the booking transaction after locking the business, as CreateBookingWithinQuota does.
+func CountActiveBusinessBookings(tx *gorm.DB, businessID string, from, to time.Time) (int64, error) {
+	var count int64
+	err := tx.Model(&models.Booking{}).
+		Where("business_id = ? AND status IN ? AND start_time >= ? AND start_time < ?", businessID, activeBookingStatuses, from, to).
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("error counting active bookings for business %s: %w", businessID, err)
+	}
+	return count, nil
+}
+
+// CountActiveCustomerBookings counts a customer's pending and confirmed bookings with a business starting in [from, to).
+// For quota checks, call it inside the booking transaction after locking the business, as CreateBookingWithinQuota does.
+func CountActiveCustomerBookings(tx *gorm.DB, businessID, customerID string, from, to time.Time) (int64, error) {
+	var count int64
+	err := tx.Model(&models.Booking{}).
+		Where("business_id = ? AND customer_id = ? AND status IN ? AND start_time >= ? AND start_time < ?", businessID, customerID, activeBookingStatuses, from, to).
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("error counting active bookings for customer %s: %w", customerID, err)
+	}
+	return count, nil
+}
+
+// ErrBookingNotFound is returned when no booking has the requested ID.
+var ErrBookingNotFound = errors.New("booking not found")
+
+// GetBookingByID retrieves a booking by its ID. Returns ErrBookingNotFound if there is none.
 func (r *BookingRepository) GetBookingByID(ctx context.Context, bookingID string) (*models.Booking, error) {
 	var booking models.Booking
 	if err := r.db.WithContext(ctx).First(&booking, "id = ?", bookingID).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, nil // Return nil, nil if not found
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("booking %s: %w", bookingID, ErrBookingNotFound)
 		}
 		return nil, fmt.Errorf("error fetching booking %s: %w", bookingID, err)
 	}
 	return &booking, nil
 }
 
-// GetBookingsByCustomerID retrieves all bookings for a given customer, with pagination.
-func (r *BookingRepository) GetBookingsByCustomerID(ctx context.Context, customerID string, limit, offset int) ([]models.Booking, int64, error) {
+// GetBookingsByCustomerID retrieves a customer's bookings in timeframe relative to now, with pagination.
+// Upcoming bookings are ordered soonest first; past and all bookings latest first.
+func (r *BookingRepository) GetBookingsByCustomerID(ctx context.Context, customerID string, timeframe models.BookingTimeframe, now time.Time, limit, offset int) ([]models.Booking, int64, error) {
 	var bookings []models.Booking
 	var total int64
 
-	if err := r.db.WithContext(ctx).Model(&models.Booking{}).Where("customer_id = ?", customerID).Count(&total).Error; err != nil {
+	// A booking in progress has not ended, so it is still upcoming
+	query := r.db.WithContext(ctx).Model(&models.Booking{}).Where("customer_id = ?", customerID)
+	order := "start_time desc"
+	switch timeframe {
+	case models.BookingTimeframeUpcoming:
+		query = query.Where("end_time > ?", now)
+		order = "start_time asc"
+	case models.BookingTimeframePast:
+		query = query.Where("end_time <= ?", now)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, fmt.Errorf("error counting customer bookings: %w", err)
 	}
 
-	if err := r.db.WithContext(ctx).
-		Where("customer_id = ?", customerID).
-		Order("start_time desc").
+	if err := query.
+		Order(order).
 		Limit(limit).
 		Offset(offset).
 		Find(&bookings).Error; err != nil {
@@ -59,17 +245,61 @@ func (r *BookingRepository) GetBookingsByCustomerID(ctx context.Context, custome
 	return bookings, total, nil
 }
 
+// GetActiveSeriesBookings retrieves the pending and confirmed occurrences of a recurring series, earliest first.
+// If from is not nil, only occurrences starting at or after it are returned.
+func (r *BookingRepository) GetActiveSeriesBookings(ctx context.Context, seriesID string, from *time.Time) ([]models.Booking, error) {
+	var bookings []models.Booking
+	query := r.db.WithContext(ctx).
+		Where("series_id = ? AND status IN ?", seriesID, []models.BookingStatus{models.BookingStatusPendingPayment, models.BookingStatusConfirmed})
+	if from != nil {
+		query = query.Where("start_time >= ?", *from)
+	}
+	if err := query.Order("start_time asc").Find(&bookings).Error; err != nil {
+		return nil, fmt.Errorf("error fetching bookings of series %s: %w", seriesID, err)
+	}
+	return bookings, nil
+}
+
+// GetPendingBookingsCreatedBefore retrieves up to limit bookings still awaiting payment
+// that were created before the cutoff, oldest first.
+func (r *BookingRepository) GetPendingBookingsCreatedBefore(ctx context.Context, cutoff time.Time, limit int) ([]models.Booking, error) {
+	var bookings []models.Booking
+	if err := r.db.WithContext(ctx).
+		Where("status = ? AND created_at < ?", models.BookingStatusPendingPayment, cutoff).
+		Order("created_at asc").
+		Limit(limit).
+		Find(&bookings).Error; err != nil {
+		return nil, fmt.Errorf("error fetching pending bookings created before %s: %w", cutoff, err)
+	}
+	return bookings, nil
+}
+
 // GetBookingsByBusinessID retrieves all bookings for a given business, with pagination.
-func (r *BookingRepository) GetBookingsByBusinessID(ctx context.Context, businessID string, limit, offset int) ([]models.Booking, int64, error) {
+// Only bookings in one of statuses are returned; if statuses is empty, bookings of every status are.
+// A non-empty search keeps bookings whose customer name or email, or service name, contains it, ignoring case.
+func (r *BookingRepository) GetBookingsByBusinessID(ctx context.Context, businessID string, statuses []models.BookingStatus, search string, limit, offset int) ([]models.Booking, int64, error) {
 	var bookings []models.Booking
 	var total int64
 
-	if err := r.db.WithContext(ctx).Model(&models.Booking{}).Where("business_id = ?", businessID).Count(&total).Error; err != nil {
+	query := r.db.WithContext(ctx).Model(&models.Booking{}).Where("business_id = ?", businessID)
+	if len(statuses) > 0 {
+		query = query.Where("status IN ?", statuses)
+	}
+	if search = strings.TrimSpace(search); search != "" {
+		pattern := "%" + likeEscaper.Replace(search) + "%"
+		query = query.Where(
+			"guest_name ILIKE @pattern OR guest_email ILIKE @pattern"+
+				" OR customer_id IN (SELECT customer_id FROM customer_preferences WHERE name ILIKE @pattern OR email ILIKE @pattern)"+
+				" OR service_id IN (SELECT id FROM service_definitions WHERE business_id = @businessID AND name ILIKE @pattern)",
+			sql.Named("pattern", pattern), sql.Named("businessID", businessID),
+		)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, fmt.Errorf("error counting business bookings: %w", err)
 	}
-	
-	if err := r.db.WithContext(ctx).
-		Where("business_id = ?", businessID).
+
+	if err := query.
 		Order("start_time desc").
 		Limit(limit).
 		Offset(offset).
@@ -79,6 +309,53 @@ func (r *BookingRepository) GetBookingsByBusinessID(ctx context.Context, busines
 	return bookings, total, nil
 }
 
+// likeEscaper escapes the LIKE wildcards in user input so they match literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// GetRevenueByService sums the price snapshots of COMPLETED bookings starting in [from, to),
+// grouped by service and currency.
+func (r *BookingRepository) GetRevenueByService(ctx context.Context, businessID string, from, to time.Time) ([]models.ServiceRevenue, error) {
+	var revenue []models.ServiceRevenue
+	err := r.db.WithContext(ctx).
+		Model(&models.Booking{}).
+		Select("service_id, currency, COALESCE(SUM(total_amount), 0) AS total_amount, COUNT(*) AS booking_count").
+		Where("business_id = ?", businessID).
+		Where("status = ?", models.BookingStatusCompleted).
+		Where("start_time >= ? AND start_time < ?", from, to).
+		Group("service_id, currency").
+		Order("service_id, currency").
+		Scan(&revenue).Error
+	if err != nil {
+		return nil, fmt.Errorf("error summing revenue for business %s: %w", businessID, err)
+	}
+	return revenue, nil
+}
+
+// GetDashboardCounts counts a business's bookings for its dashboard in one query: non-cancelled bookings
+// starting in [dayStart, dayEnd) and [weekStart, weekEnd), PENDING_PAYMENT bookings starting at or after now,
+// and CANCELLED bookings that were due to start in [weekStart, weekEnd). The day must lie within the week.
+func (r *BookingRepository) GetDashboardCounts(ctx context.Context, businessID string, dayStart, dayEnd, weekStart, weekEnd, now time.Time) (models.DashboardCounts, error) {
+	var counts models.DashboardCounts
+	err := r.db.WithContext(ctx).
+		Model(&models.Booking{}).
+		Select("COUNT(*) FILTER (WHERE status <> ? AND start_time >= ? AND start_time < ?) AS today_bookings, "+
+			"COUNT(*) FILTER (WHERE status <> ? AND start_time >= ? AND start_time < ?) AS week_bookings, "+
+			"COUNT(*) FILTER (WHERE status = ? AND start_time >= ?) AS pending_confirmations, "+
+			"COUNT(*) FILTER (WHERE status = ? AND start_time >= ? AND start_time < ?) AS cancellations",
+			models.BookingStatusCancelled, dayStart, dayEnd,
+			models.BookingStatusCancelled, weekStart, weekEnd,
+			models.BookingStatusPendingPayment, now,
+			models.BookingStatusCancelled, weekStart, weekEnd).
+		Where("business_id = ?", businessID).
+		Where("(start_time >= ? AND start_time < ?) OR (status = ? AND start_time >= ?)",
+			weekStart, weekEnd, models.BookingStatusPendingPayment, now).
+		Scan(&counts).Error
+	if err != nil {
+		return models.DashboardCounts{}, fmt.Errorf("error counting dashboard bookings for business %s: %w", businessID, err)
+	}
+	return counts, nil
+}
+
 // UpdateBookingStatus updates the status of a specific booking.
 func (r *BookingRepository) UpdateBookingStatus(ctx context.Context, bookingID string, newStatus models.BookingStatus) error {
 	result := r.db.WithContext(ctx).Model(&models.Booking{}).Where("id = ?", bookingID).Update("status", newStatus)
@@ -91,11 +368,111 @@ func (r *BookingRepository) UpdateBookingStatus(ctx context.Context, bookingID s
 	return nil
 }
 
+// UpdateBookingStatusWithHistory updates the status of a booking and records the transition
+// in booking_status_history within the same transaction. The given outbox events are written
+// in that transaction too, so they exist if and only if the status change does.
+func (r *BookingRepository) UpdateBookingStatusWithHistory(ctx context.Context, bookingID string, fromStatus, newStatus models.BookingStatus, changedBy string, reason *string, messages []OutboxMessage) ([]*models.OutboxEvent, error) {
+	var outboxEvents []*models.OutboxEvent
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Booking{}).Where("id = ?", bookingID).Update("status", newStatus)
+		if result.Error != nil {
+			return fmt.Errorf("error updating booking status for %s: %w", bookingID, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("booking %s not found for status update", bookingID)
+		}
+
+		var businessID string
+		if err := tx.Model(&models.Booking{}).Where("id = ?", bookingID).Pluck("business_id", &businessID).Error; err != nil {
+			return fmt.Errorf("error fetching business for booking %s: %w", bookingID, err)
+		}
+		if err := BumpAvailabilityVersion(tx, businessID); err != nil {
+			return err
+		}
+
+		history := &models.BookingStatusHistory{
+			BookingID:  bookingID,
+			FromStatus: fromStatus,
+			ToStatus:   newStatus,
+			ChangedBy:  changedBy,
+			Reason:     reason,
+		}
+		if err := tx.Create(history).Error; err != nil {
+			return fmt.Errorf("error recording status history for booking %s: %w", bookingID, err)
+		}
+
+		for _, msg := range messages {
+			evt, err := newOutboxEvent(bookingID, msg.Subject, msg.Payload)
+			if err != nil {
+				return err
+			}
+			if err := tx.Create(evt).Error; err != nil {
+				return fmt.Errorf("error creating outbox event for booking %s: %w", bookingID, err)
+			}
+			outboxEvents = append(outboxEvents, evt)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return outboxEvents, nil
+}
+
+// RescheduleBooking moves a pending or confirmed booking to [startTime, endTime) and writes the given outbox
+// events in the same transaction; it returns ErrBookingNotActive for any other booking. Run it in the business
+// lock's transaction (see AvailabilityRepository.WithBusinessLock), which checks the new time and bumps the
+// availability version.
+func (r *BookingRepository) RescheduleBooking(ctx context.Context, bookingID string, startTime, endTime time.Time, messages []OutboxMessage) ([]*models.OutboxEvent, error) {
+	var outboxEvents []*models.OutboxEvent
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Booking{}).Where("id = ? AND status IN ?", bookingID, activeBookingStatuses).Updates(map[string]interface{}{
+			"start_time": startTime,
+			"end_time":   endTime,
+		})
+		if result.Error != nil {
+			return fmt.Errorf("error rescheduling booking %s: %w", bookingID, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrBookingNotActive
+		}
+
+		for _, msg := range messages {
+			evt, err := newOutboxEvent(bookingID, msg.Subject, msg.Payload)
+			if err != nil {
+				return err
+			}
+			if err := tx.Create(evt).Error; err != nil {
+				return fmt.Errorf("error creating outbox event for booking %s: %w", bookingID, err)
+			}
+			outboxEvents = append(outboxEvents, evt)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return outboxEvents, nil
+}
+
+// GetBookingStatusHistory retrieves the status transitions of a booking, oldest first.
+func (r *BookingRepository) GetBookingStatusHistory(ctx context.Context, bookingID string) ([]models.BookingStatusHistory, error) {
+	var history []models.BookingStatusHistory
+	if err := r.db.WithContext(ctx).
+		Where("booking_id = ?", bookingID).
+		Order("id asc").
+		Find(&history).Error; err != nil {
+		return nil, fmt.Errorf("error fetching status history for booking %s: %w", bookingID, err)
+	}
+	return history, nil
+}
+
 // FindConflictingBookings retrieves bookings that conflict with the given time range for a specific business and service.
 // It checks for bookings that are either 'CONFIRMED' or 'PENDING_PAYMENT'.
 // A conflict exists if:
 // (ExistingStartTime < ProposedEndTime) AND (ExistingEndTime > ProposedStartTime)
-func (r *BookingRepository) FindConflictingBookings(ctx context.Context, businessID string, serviceID string, proposedStartTime time.Time, proposedEndTime time.Time) ([]models.Booking, error) {
+// If excludeBookingID is not empty, that booking is ignored, so a booking being moved does not conflict with itself.
+func (r *BookingRepository) FindConflictingBookings(ctx context.Context, businessID string, serviceID string, proposedStartTime time.Time, proposedEndTime time.Time, excludeBookingID string) ([]models.Booking, error) {
 	var conflictingBookings []models.Booking
 	
 	// Define statuses that are considered conflicting
@@ -104,7 +481,12 @@ func (r *BookingRepository) FindConflictingBookings(ctx context.Context, busines
 		models.BookingStatusPendingPayment,
 	}
 
-	err := r.db.WithContext(ctx).
+	query := r.db.WithContext(ctx)
+	if excludeBookingID != "" {
+		query = query.Where("id <> ?", excludeBookingID)
+	}
+
+	err := query.
 		Where("business_id = ?", businessID).
 		// Where("service_id = ?", serviceID). // Conflicts should ideally be for the business, not just specific service, unless services can overlap. For now, let's scope to business.
 		// If a business can have multiple services at the same time (e.g. different staff), then service_id might not be needed here,
@@ -121,6 +503,26 @@ func (r *BookingRepository) FindConflictingBookings(ctx context.Context, busines
 	return conflictingBookings, nil
 }
 
+// FindAdjacentBookings retrieves the 'CONFIRMED' or 'PENDING_PAYMENT' bookings of a business that end exactly
+// when the given time range starts or start exactly when it ends, for businesses that forbid back-to-back bookings.
+// If excludeBookingID is not empty, that booking is ignored, as in FindConflictingBookings.
+func (r *BookingRepository) FindAdjacentBookings(ctx context.Context, businessID string, startTime time.Time, endTime time.Time, excludeBookingID string) ([]models.Booking, error) {
+	var adjacentBookings []models.Booking
+	query := r.db.WithContext(ctx)
+	if excludeBookingID != "" {
+		query = query.Where("id <> ?", excludeBookingID)
+	}
+	err := query.
+		Where("business_id = ?", businessID).
+		Where("status IN (?)", []models.BookingStatus{models.BookingStatusConfirmed, models.BookingStatusPendingPayment}).
+		Where("end_time = ? OR start_time = ?", startTime, endTime).
+		Find(&adjacentBookings).Error
+	if err != nil {
+		return nil, fmt.Errorf("error finding adjacent bookings for business %s: %w", businessID, err)
+	}
+	return adjacentBookings, nil
+}
+
 // GetBookingsForBusinessByDateRangeAndStatuses fetches all bookings for a given businessID
 // that are active between startDate (inclusive) and endDate (exclusive)
 // and match one of the provided statuses.
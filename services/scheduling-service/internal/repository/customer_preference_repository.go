@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/slotwise/scheduling-service/internal/models"
+	"gorm.io/gorm"
+)
+
+// CustomerPreferenceRepository handles customer notification preference data operations
+type CustomerPreferenceRepository struct {
+	db *gorm.DB
+}
+
+// NewCustomerPreferenceRepository creates a new customer preference repository
+func NewCustomerPreferenceRepository(db *gorm.DB) *CustomerPreferenceRepository {
+	return &CustomerPreferenceRepository{db: db}
+}
+
+// GetByCustomerID retrieves the preferences of a customer.
+func (r *CustomerPreferenceRepository) GetByCustomerID(ctx context.Context, customerID string) (*models.CustomerPreference, error) {
+	var pref models.CustomerPreference
+	if err := r.db.WithContext(ctx).First(&pref, "customer_id = ?", customerID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil // Return nil, nil if not found
+		}
+		return nil, fmt.Errorf("error fetching preferences for customer %s: %w", customerID, err)
+	}
+	return &pref, nil
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/slotwise/scheduling-service/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OutboxRepository handles transactional outbox data operations
+type OutboxRepository struct {
+	db *gorm.DB
+}
+
+// NewOutboxRepository creates a new outbox repository
+func NewOutboxRepository(db *gorm.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+// newOutboxEvent builds a pending outbox row for the given subject and payload.
+func newOutboxEvent(aggregateID, subject string, payload interface{}) (*models.OutboxEvent, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling outbox payload for %s: %w", subject, err)
+	}
+	return &models.OutboxEvent{
+		AggregateID: aggregateID,
+		Subject:     subject,
+		Payload:     string(data),
+		Status:      models.OutboxEventStatusPending,
+		MaxAttempts: models.DefaultOutboxMaxAttempts,
+	}, nil
+}
+
+// HasDailyDigest reports whether the business's digest for date has already been recorded.
+func (r *OutboxRepository) HasDailyDigest(ctx context.Context, businessID, date string) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&models.DailyDigest{}).
+		Where("business_id = ? AND date = ?", businessID, date).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("error checking daily digest for business %s: %w", businessID, err)
+	}
+	return count > 0, nil
+}
+
+// RecordDailyDigest marks the business's digest for date as issued and writes its outbox event
+// in the same transaction. It returns nil, nil if the digest for that date was already recorded.
+func (r *OutboxRepository) RecordDailyDigest(ctx context.Context, businessID, date string, message OutboxMessage) (*models.OutboxEvent, error) {
+	var outboxEvent *models.OutboxEvent
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.DailyDigest{BusinessID: businessID, Date: date})
+		if result.Error != nil {
+			return fmt.Errorf("error recording daily digest for business %s: %w", businessID, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return nil // Another run already issued it
+		}
+
+		event, err := newOutboxEvent(businessID, message.Subject, message.Payload)
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(event).Error; err != nil {
+			return fmt.Errorf("error creating outbox event for daily digest of business %s: %w", businessID, err)
+		}
+		outboxEvent = event
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return outboxEvent, nil
+}
+
+// GetPendingEvents retrieves up to limit pending outbox events, oldest first.
+func (r *OutboxRepository) GetPendingEvents(ctx context.Context, limit int) ([]models.OutboxEvent, error) {
+	var outboxEvents []models.OutboxEvent
+	if err := r.db.WithContext(ctx).
+		Where("status = ?", models.OutboxEventStatusPending).
+		Order("created_at asc").
+		Limit(limit).
+		Find(&outboxEvents).Error; err != nil {
+		return nil, fmt.Errorf("error fetching pending outbox events: %w", err)
+	}
+	return outboxEvents, nil
+}
+
+// GetEventsByAggregateID retrieves all outbox events recorded for an aggregate (e.g. a booking).
+func (r *OutboxRepository) GetEventsByAggregateID(ctx context.Context, aggregateID string) ([]models.OutboxEvent, error) {
+	var outboxEvents []models.OutboxEvent
+	if err := r.db.WithContext(ctx).
+		Where("aggregate_id = ?", aggregateID).
+		Order("created_at asc").
+		Find(&outboxEvents).Error; err != nil {
+		return nil, fmt.Errorf("error fetching outbox events for %s: %w", aggregateID, err)
+	}
+	return outboxEvents, nil
+}
+
+// MarkEventSent marks an outbox event as published.
+func (r *OutboxRepository) MarkEventSent(ctx context.Context, eventID string) error {
+	now := time.Now().UTC()
+	result := r.db.WithContext(ctx).Model(&models.OutboxEvent{}).
+		Where("id = ?", eventID).
+		Updates(map[string]interface{}{
+			"status":   models.OutboxEventStatusSent,
+			"sent_at":  now,
+			"attempts": gorm.Expr("attempts + 1"),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("error marking outbox event %s as sent: %w", eventID, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("outbox event %s not found", eventID)
+	}
+	return nil
+}
+
+// MarkEventFailed records a failed publish attempt. The event stays pending for the next relay run unless
+// this was its last allowed attempt, in which case it is parked. It reports whether the event was parked.
+func (r *OutboxRepository) MarkEventFailed(ctx context.Context, eventID string, publishErr error) (bool, error) {
+	lastError := publishErr.Error()
+	if err := r.db.WithContext(ctx).Model(&models.OutboxEvent{}).
+		Where("id = ? AND status = ?", eventID, models.OutboxEventStatusPending).
+		Updates(map[string]interface{}{
+			"attempts":   gorm.Expr("attempts + 1"),
+			"last_error": lastError,
+			"status":     gorm.Expr("CASE WHEN attempts + 1 >= max_attempts THEN ? ELSE status END", models.OutboxEventStatusParked),
+			"parked_at":  gorm.Expr("CASE WHEN attempts + 1 >= max_attempts THEN ? ELSE parked_at END", time.Now().UTC()),
+		}).Error; err != nil {
+		return false, fmt.Errorf("error recording failed attempt for outbox event %s: %w", eventID, err)
+	}
+
+	var event models.OutboxEvent
+	if err := r.db.WithContext(ctx).Select("status").First(&event, "id = ?", eventID).Error; err != nil {
+		return false, fmt.Errorf("error reading status of outbox event %s: %w", eventID, err)
+	}
+	return event.Status == models.OutboxEventStatusParked, nil
+}
+
+// ParkEvent parks a pending outbox event that can never be published, such as one with a corrupt payload,
+// without waiting for it to use up its attempts.
+func (r *OutboxRepository) ParkEvent(ctx context.Context, eventID string, reason error) error {
+	lastError := reason.Error()
+	if err := r.db.WithContext(ctx).Model(&models.OutboxEvent{}).
+		Where("id = ? AND status = ?", eventID, models.OutboxEventStatusPending).
+		Updates(map[string]interface{}{
+			"status":     models.OutboxEventStatusParked,
+			"parked_at":  time.Now().UTC(),
+			"last_error": lastError,
+		}).Error; err != nil {
+		return fmt.Errorf("error parking outbox event %s: %w", eventID, err)
+	}
+	return nil
+}
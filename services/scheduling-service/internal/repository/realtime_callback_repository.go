@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/slotwise/scheduling-service/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RealtimeCallbackRepository persists realtime callback registrations
+type RealtimeCallbackRepository struct {
+	db *gorm.DB
+}
+
+// NewRealtimeCallbackRepository creates a new realtime callback repository
+func NewRealtimeCallbackRepository(db *gorm.DB) *RealtimeCallbackRepository {
+	return &RealtimeCallbackRepository{db: db}
+}
+
+// SaveCallback stores the business's callback URL, replacing any previous one.
+func (r *RealtimeCallbackRepository) SaveCallback(ctx context.Context, businessID, callbackURL string) error {
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "business_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"url", "updated_at"}),
+	}).Create(&models.RealtimeCallback{BusinessID: businessID, URL: callbackURL}).Error
+	if err != nil {
+		return fmt.Errorf("error saving realtime callback for business %s: %w", businessID, err)
+	}
+	return nil
+}
+
+// DeleteCallback removes the business's callback. Deleting a missing one is not an error.
+func (r *RealtimeCallbackRepository) DeleteCallback(ctx context.Context, businessID string) error {
+	if err := r.db.WithContext(ctx).Delete(&models.RealtimeCallback{}, "business_id = ?", businessID).Error; err != nil {
+		return fmt.Errorf("error deleting realtime callback for business %s: %w", businessID, err)
+	}
+	return nil
+}
+
+// ListCallbacks returns every stored callback URL keyed by business ID.
+func (r *RealtimeCallbackRepository) ListCallbacks(ctx context.Context) (map[string]string, error) {
+	var rows []models.RealtimeCallback
+	if err := r.db.WithContext(ctx).Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("error listing realtime callbacks: %w", err)
+	}
+	callbacks := make(map[string]string, len(rows))
+	for _, row := range rows {
+		callbacks[row.BusinessID] = row.URL
+	}
+	return callbacks, nil
+}
@@ -2,10 +2,13 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	// "time" // No longer needed here if Booking struct is removed
 	"fmt" // For fmt.Errorf
@@ -28,28 +31,187 @@ func NewAvailabilityRepository(db *gorm.DB) *AvailabilityRepository {
 	return &AvailabilityRepository{db: db}
 }
 
-// GetServiceDefinition retrieves a single service definition by its ID.
+// ErrServiceDefinitionNotFound is returned when no service definition has the requested ID.
+var ErrServiceDefinitionNotFound = errors.New("service definition not found")
+
+// GetServiceDefinition retrieves a single service definition by its ID. Returns ErrServiceDefinitionNotFound if there is none.
 func (r *AvailabilityRepository) GetServiceDefinition(ctx context.Context, serviceID string) (*models.ServiceDefinition, error) {
 	var serviceDef models.ServiceDefinition
 	if err := r.db.WithContext(ctx).First(&serviceDef, "id = ?", serviceID).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, nil // Return nil, nil if not found, service layer can handle "not found" error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("service definition %s: %w", serviceID, ErrServiceDefinitionNotFound)
 		}
 		return nil, fmt.Errorf("error fetching service definition %s: %w", serviceID, err)
 	}
 	return &serviceDef, nil
 }
 
-// GetAvailabilityRulesFiltered retrieves availability rules for a given business.
-// If dayOfWeek is empty, it fetches all rules for the business, ordered by day_of_week then start_time.
+// GetActiveServiceDefinitionsForBusiness returns a business's active services, shortest first.
+func (r *AvailabilityRepository) GetActiveServiceDefinitionsForBusiness(ctx context.Context, businessID string) ([]models.ServiceDefinition, error) {
+	var serviceDefs []models.ServiceDefinition
+	err := r.db.WithContext(ctx).
+		Where("business_id = ? AND is_active = ?", businessID, true).
+		Order("duration_minutes ASC").
+		Find(&serviceDefs).Error
+	if err != nil {
+		return nil, fmt.Errorf("error fetching active services for business %s: %w", businessID, err)
+	}
+	return serviceDefs, nil
+}
+
+// FindBusinessIDsOfferingCategory returns the IDs of businesses with an active service in category,
+// matched case-insensitively, in ID order.
+func (r *AvailabilityRepository) FindBusinessIDsOfferingCategory(ctx context.Context, category string) ([]string, error) {
+	var businessIDs []string
+	err := r.db.WithContext(ctx).Model(&models.ServiceDefinition{}).
+		Distinct("business_id").
+		Where("LOWER(category) = LOWER(?) AND is_active = ?", category, true).
+		Order("business_id ASC").
+		Pluck("business_id", &businessIDs).Error
+	if err != nil {
+		return nil, fmt.Errorf("error finding businesses offering category %q: %w", category, err)
+	}
+	return businessIDs, nil
+}
+
+// GetServiceDefinitionsByIDs retrieves the given service definitions, including deleted ones,
+// so past bookings of a removed service can still be displayed. Unknown IDs are skipped.
+func (r *AvailabilityRepository) GetServiceDefinitionsByIDs(ctx context.Context, serviceIDs []string) ([]models.ServiceDefinition, error) {
+	var serviceDefs []models.ServiceDefinition
+	if len(serviceIDs) == 0 {
+		return serviceDefs, nil
+	}
+	err := r.db.WithContext(ctx).Unscoped().Where("id IN ?", serviceIDs).Find(&serviceDefs).Error
+	if err != nil {
+		return nil, fmt.Errorf("error fetching service definitions: %w", err)
+	}
+	return serviceDefs, nil
+}
+
+// GetBusinessByID retrieves the local copy of a business, including one that has been deleted.
+// It returns nil, nil if the business has never been synced.
+func (r *AvailabilityRepository) GetBusinessByID(ctx context.Context, businessID string) (*models.Business, error) {
+	var business models.Business
+	if err := r.db.WithContext(ctx).Unscoped().First(&business, "id = ?", businessID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error fetching business %s: %w", businessID, err)
+	}
+	return &business, nil
+}
+
+// ListActiveBusinesses retrieves all businesses that are neither deleted nor suspended.
+func (r *AvailabilityRepository) ListActiveBusinesses(ctx context.Context) ([]models.Business, error) {
+	var businesses []models.Business
+	err := r.db.WithContext(ctx).Where("status <> ?", models.BusinessStatusSuspended).Order("id asc").Find(&businesses).Error
+	if err != nil {
+		return nil, fmt.Errorf("error listing active businesses: %w", err)
+	}
+	return businesses, nil
+}
+
+// SetBusinessAcceptingBookings pauses or resumes new bookings for a business and bumps its
+// availability version in the same transaction, since pausing hides every slot.
+// It returns nil, nil if the business is unknown or has been deleted.
+func (r *AvailabilityRepository) SetBusinessAcceptingBookings(ctx context.Context, businessID string, accepting bool) (*models.Business, error) {
+	var business *models.Business
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Business{}).Where("id = ?", businessID).Update("accepting_bookings", accepting)
+		if result.Error != nil {
+			return fmt.Errorf("error updating business %s: %w", businessID, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		if err := BumpAvailabilityVersion(tx, businessID); err != nil {
+			return err
+		}
+		var err error
+		business, err = r.WithTx(tx).GetBusinessByID(ctx, businessID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return business, nil
+}
+
+// BumpAvailabilityVersion increments the availability revision of a business in its availability_versions
+// row, creating the row on the business's first change. Call it inside the transaction that changes the
+// business's rules or bookings so the version and the change commit together. The row stays locked until
+// that transaction ends, which serialises changes to the business's availability.
+func BumpAvailabilityVersion(tx *gorm.DB, businessID string) error {
+	err := tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "business_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"revision": gorm.Expr("availability_versions.revision + 1"),
+		}),
+	}).Create(&models.AvailabilityVersion{BusinessID: businessID, Revision: 1}).Error
+	if err != nil {
+		return fmt.Errorf("error bumping availability version for business %s: %w", businessID, err)
+	}
+	return nil
+}
+
+// GetAvailabilityVersion returns the availability revision of a business, 0 if its rules and bookings never changed.
+func (r *AvailabilityRepository) GetAvailabilityVersion(ctx context.Context, businessID string) (int64, error) {
+	var version models.AvailabilityVersion
+	if err := r.db.WithContext(ctx).First(&version, "business_id = ?", businessID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("error fetching availability version for business %s: %w", businessID, err)
+	}
+	return version.Revision, nil
+}
+
+// WithBusinessLock runs fn in a transaction holding the business's availability version row lock, the lock
+// booking transactions take when they bump the availability version. Callers that check a slot and then reserve it do both inside
+// fn, reading and writing through tx (see WithTx), so neither another reservation nor a booking can take the
+// slot in between. The version is bumped as well, since what fn reserves changes the business's availability.
+// An error from fn rolls the transaction back.
+func (r *AvailabilityRepository) WithBusinessLock(ctx context.Context, businessID string, fn func(tx *gorm.DB) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := BumpAvailabilityVersion(tx, businessID); err != nil {
+			return err
+		}
+		return fn(tx)
+	})
+}
+
+// WithTx returns a copy of the repository that runs its queries in tx, e.g. the transaction WithBusinessLock passes to fn.
+func (r *AvailabilityRepository) WithTx(tx *gorm.DB) *AvailabilityRepository {
+	return &AvailabilityRepository{db: tx}
+}
+
+// dayOfWeekOrder sorts day_of_week in calendar order (Monday first) rather than alphabetically.
+const dayOfWeekOrder = "CASE day_of_week WHEN 'MONDAY' THEN 1 WHEN 'TUESDAY' THEN 2 WHEN 'WEDNESDAY' THEN 3 " +
+	"WHEN 'THURSDAY' THEN 4 WHEN 'FRIDAY' THEN 5 WHEN 'SATURDAY' THEN 6 WHEN 'SUNDAY' THEN 7 END"
+
+// GetAvailabilityRulesFiltered retrieves availability rules for a given business, active or not.
+// If dayOfWeek is empty, it fetches all rules for the business, ordered Monday to Sunday then by start_time.
 // Otherwise, it filters by businessID AND dayOfWeek, ordered by start_time.
 func (r *AvailabilityRepository) GetAvailabilityRulesFiltered(ctx context.Context, businessID string, dayOfWeek models.DayOfWeekString) ([]models.AvailabilityRule, error) {
+	return r.getAvailabilityRules(ctx, businessID, dayOfWeek, false)
+}
+
+// GetActiveAvailabilityRules is GetAvailabilityRulesFiltered without deactivated rules,
+// i.e. only the rules that produce slots.
+func (r *AvailabilityRepository) GetActiveAvailabilityRules(ctx context.Context, businessID string, dayOfWeek models.DayOfWeekString) ([]models.AvailabilityRule, error) {
+	return r.getAvailabilityRules(ctx, businessID, dayOfWeek, true)
+}
+
+func (r *AvailabilityRepository) getAvailabilityRules(ctx context.Context, businessID string, dayOfWeek models.DayOfWeekString, activeOnly bool) ([]models.AvailabilityRule, error) {
 	var rules []models.AvailabilityRule
 	query := r.db.WithContext(ctx).Where("business_id = ?", businessID)
+	if activeOnly {
+		query = query.Where("active = ?", true)
+	}
 
 	if dayOfWeek == "" {
-		// Fetch all rules for the business, order by day_of_week, then start_time
-		query = query.Order("day_of_week asc").Order("start_time asc")
+		// Fetch all rules for the business, order by day of the week, then start_time
+		query = query.Order(dayOfWeekOrder).Order("start_time asc")
 	} else {
 		// Fetch rules for a specific day_of_week, order by start_time
 		query = query.Where("day_of_week = ?", dayOfWeek).Order("start_time asc")
@@ -65,13 +227,80 @@ func (r *AvailabilityRepository) GetAvailabilityRulesFiltered(ctx context.Contex
 	return rules, nil
 }
 
+// ErrDuplicateAvailabilityRule is returned when a business already has a rule for the same day and hours.
+var ErrDuplicateAvailabilityRule = errors.New("availability rule already exists")
+
+// uniqueViolationCode is the Postgres error code for a unique constraint violation.
+const uniqueViolationCode = "23505"
+
+// isUniqueViolation reports whether err is a Postgres unique constraint violation.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode
+}
+
 // CreateAvailabilityRule persists a new AvailabilityRule to the database.
+// A rule duplicating a live one fails with ErrDuplicateAvailabilityRule.
 func (r *AvailabilityRepository) CreateAvailabilityRule(ctx context.Context, rule *models.AvailabilityRule) error {
-	if err := r.db.WithContext(ctx).Create(rule).Error; err != nil {
-		// TODO: Consider checking for specific DB errors, like unique constraint violations if applicable
-		return fmt.Errorf("error creating availability rule for business %s on %s: %w", rule.BusinessID, rule.DayOfWeek, err)
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(rule).Error; err != nil {
+			if isUniqueViolation(err) {
+				return fmt.Errorf("rule for business %s on %s %s-%s: %w", rule.BusinessID, rule.DayOfWeek, rule.StartTime, rule.EndTime, ErrDuplicateAvailabilityRule)
+			}
+			return fmt.Errorf("error creating availability rule for business %s on %s: %w", rule.BusinessID, rule.DayOfWeek, err)
+		}
+		return BumpAvailabilityVersion(tx, rule.BusinessID)
+	})
+}
+
+// ErrAvailabilityRuleNotFound is returned when no availability rule has the requested ID.
+var ErrAvailabilityRuleNotFound = errors.New("availability rule not found")
+
+// GetAvailabilityRuleByID retrieves a single availability rule by its ID. Returns ErrAvailabilityRuleNotFound if there is none.
+func (r *AvailabilityRepository) GetAvailabilityRuleByID(ctx context.Context, ruleID uint) (*models.AvailabilityRule, error) {
+	var rule models.AvailabilityRule
+	if err := r.db.WithContext(ctx).First(&rule, ruleID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("availability rule %d: %w", ruleID, ErrAvailabilityRuleNotFound)
+		}
+		return nil, fmt.Errorf("error fetching availability rule %d: %w", ruleID, err)
 	}
-	return nil
+	return &rule, nil
+}
+
+// UpdateAvailabilityRule saves all fields of an existing AvailabilityRule.
+// A change that makes it duplicate another live rule fails with ErrDuplicateAvailabilityRule.
+func (r *AvailabilityRepository) UpdateAvailabilityRule(ctx context.Context, rule *models.AvailabilityRule) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(rule).Error; err != nil {
+			if isUniqueViolation(err) {
+				return fmt.Errorf("rule %d on %s %s-%s: %w", rule.ID, rule.DayOfWeek, rule.StartTime, rule.EndTime, ErrDuplicateAvailabilityRule)
+			}
+			return fmt.Errorf("error updating availability rule %d: %w", rule.ID, err)
+		}
+		return BumpAvailabilityVersion(tx, rule.BusinessID)
+	})
+}
+
+// DeleteAvailabilityRulesForDay deletes all of a business's rules for one day in a single transaction
+// and returns how many were removed.
+func (r *AvailabilityRepository) DeleteAvailabilityRulesForDay(ctx context.Context, businessID string, dayOfWeek models.DayOfWeekString) (int64, error) {
+	var deleted int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("business_id = ? AND day_of_week = ?", businessID, dayOfWeek).Delete(&models.AvailabilityRule{})
+		if result.Error != nil {
+			return fmt.Errorf("error deleting availability rules for business %s on %s: %w", businessID, dayOfWeek, result.Error)
+		}
+		deleted = result.RowsAffected
+		if deleted == 0 {
+			return nil
+		}
+		return BumpAvailabilityVersion(tx, businessID)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return deleted, nil
 }
 
 // NewCacheRepository creates a new cache repository
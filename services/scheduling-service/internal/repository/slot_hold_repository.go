@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/slotwise/scheduling-service/internal/models"
+)
+
+// slotHoldKeyPrefix namespaces slot hold keys; the full key is slot_hold:{businessID}:{startUnix}
+const slotHoldKeyPrefix = "slot_hold"
+
+// slotHoldIndexPrefix namespaces the per-business hold indexes; the full key is slot_holds:{businessID}.
+// An index is a sorted set of the business's hold keys scored by their expiry in Unix milliseconds, so
+// reading a business's holds never scans the keyspace.
+const slotHoldIndexPrefix = "slot_holds"
+
+// createSlotHoldScript stores a hold unless the slot is already held and adds it to the business's index,
+// which lives as long as its longest hold.
+var createSlotHoldScript = redis.NewScript(`
+if not redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	return 0
+end
+redis.call("ZADD", KEYS[2], ARGV[3], KEYS[1])
+if redis.call("PTTL", KEYS[2]) < tonumber(ARGV[2]) then
+	redis.call("PEXPIRE", KEYS[2], ARGV[2])
+end
+return 1
+`)
+
+// releaseSlotHoldScript deletes a hold only if it still belongs to the given hold ID,
+// so a late release cannot remove a newer hold on the same slot.
+var releaseSlotHoldScript = redis.NewScript(`
+local value = redis.call("GET", KEYS[1])
+if not value then
+	return 0
+end
+if cjson.decode(value)["id"] == ARGV[1] then
+	redis.call("ZREM", KEYS[2], KEYS[1])
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// SlotHoldRepository stores slot holds in Redis with a TTL
+type SlotHoldRepository struct {
+	client *redis.Client
+}
+
+// NewSlotHoldRepository creates a new slot hold repository
+func NewSlotHoldRepository(client *redis.Client) *SlotHoldRepository {
+	return &SlotHoldRepository{client: client}
+}
+
+func slotHoldKey(businessID string, startTime time.Time) string {
+	return fmt.Sprintf("%s:%s:%d", slotHoldKeyPrefix, businessID, startTime.Unix())
+}
+
+func slotHoldIndexKey(businessID string) string {
+	return fmt.Sprintf("%s:%s", slotHoldIndexPrefix, businessID)
+}
+
+// Create stores the hold unless the slot is already held. It returns false if another hold exists.
+func (r *SlotHoldRepository) Create(ctx context.Context, hold *models.SlotHold, ttl time.Duration) (bool, error) {
+	if r == nil || r.client == nil {
+		return false, fmt.Errorf("slot holds are unavailable without Redis")
+	}
+	value, err := json.Marshal(hold)
+	if err != nil {
+		return false, fmt.Errorf("error encoding slot hold: %w", err)
+	}
+	keys := []string{slotHoldKey(hold.BusinessID, hold.StartTime), slotHoldIndexKey(hold.BusinessID)}
+	created, err := createSlotHoldScript.Run(ctx, r.client, keys, value, ttl.Milliseconds(), time.Now().Add(ttl).UnixMilli()).Int()
+	if err != nil {
+		return false, fmt.Errorf("error creating slot hold for business %s: %w", hold.BusinessID, err)
+	}
+	return created == 1, nil
+}
+
+// GetByBusinessID retrieves all unexpired holds of a business.
+func (r *SlotHoldRepository) GetByBusinessID(ctx context.Context, businessID string) ([]models.SlotHold, error) {
+	// Handle nil Redis client (development mode): nothing can be held
+	if r == nil || r.client == nil {
+		return nil, nil
+	}
+
+	// Drop index entries of holds that have expired; MGET below skips any that expire in between
+	indexKey := slotHoldIndexKey(businessID)
+	if err := r.client.ZRemRangeByScore(ctx, indexKey, "-inf", strconv.FormatInt(time.Now().UnixMilli(), 10)).Err(); err != nil {
+		return nil, fmt.Errorf("error pruning slot holds for business %s: %w", businessID, err)
+	}
+	keys, err := r.client.ZRange(ctx, indexKey, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("error listing slot holds for business %s: %w", businessID, err)
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	values, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("error fetching slot holds for business %s: %w", businessID, err)
+	}
+	holds := make([]models.SlotHold, 0, len(values))
+	for _, value := range values {
+		raw, ok := value.(string)
+		if !ok {
+			continue // Expired since it was indexed
+		}
+		var hold models.SlotHold
+		if err := json.Unmarshal([]byte(raw), &hold); err != nil {
+			return nil, fmt.Errorf("error decoding slot hold for business %s: %w", businessID, err)
+		}
+		holds = append(holds, hold)
+	}
+	return holds, nil
+}
+
+// Release deletes the hold unless the slot has since been held again. Releasing an expired hold is not an error.
+func (r *SlotHoldRepository) Release(ctx context.Context, hold *models.SlotHold) error {
+	if r == nil || r.client == nil {
+		return nil
+	}
+	if err := releaseSlotHoldScript.Run(ctx, r.client, []string{slotHoldKey(hold.BusinessID, hold.StartTime), slotHoldIndexKey(hold.BusinessID)}, hold.ID).Err(); err != nil {
+		return fmt.Errorf("error releasing slot hold %s: %w", hold.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// The auth service writes these keys when it revokes access tokens; the formats must match its
+// token denylist repository.
+const (
+	deniedTokenKeyPrefix        = "denied_token"         // denied_token:{jti}, set on logout
+	deniedTokensBeforeKeyPrefix = "denied_tokens_before" // denied_tokens_before:{userID}, Unix seconds, set on password reset
+)
+
+// TokenDenylistRepository reads the access token denylist the auth service keeps in the shared Redis,
+// so tokens revoked there stop working here before they expire.
+type TokenDenylistRepository struct {
+	client *redis.Client
+}
+
+// NewTokenDenylistRepository creates a new token denylist repository
+func NewTokenDenylistRepository(client *redis.Client) *TokenDenylistRepository {
+	return &TokenDenylistRepository{client: client}
+}
+
+// IsRevoked reports whether the access token with the given ID was denied, or belongs to a user whose
+// tokens issued before a cutoff were all revoked. A token without an issue time is only checked by ID.
+func (r *TokenDenylistRepository) IsRevoked(ctx context.Context, tokenID, userID string, issuedAt time.Time) (bool, error) {
+	// Handle nil Redis client (development mode)
+	if r.client == nil {
+		return false, nil
+	}
+
+	if tokenID != "" {
+		denied, err := r.client.Exists(ctx, fmt.Sprintf("%s:%s", deniedTokenKeyPrefix, tokenID)).Result()
+		if err != nil {
+			return false, fmt.Errorf("error checking token denylist: %w", err)
+		}
+		if denied > 0 {
+			return true, nil
+		}
+	}
+
+	if userID == "" || issuedAt.IsZero() {
+		return false, nil
+	}
+	value, err := r.client.Get(ctx, fmt.Sprintf("%s:%s", deniedTokensBeforeKeyPrefix, userID)).Result()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error getting token cutoff for user %s: %w", userID, err)
+	}
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return false, fmt.Errorf("error parsing token cutoff for user %s: %w", userID, err)
+	}
+	return issuedAt.Before(time.Unix(seconds, 0)), nil
+}
@@ -2,12 +2,16 @@ package service_test
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/slotwise/scheduling-service/internal/models"
 	"github.com/slotwise/scheduling-service/internal/repository"
 	"github.com/slotwise/scheduling-service/internal/service"
+	"github.com/slotwise/scheduling-service/internal/subscribers"
+	"github.com/slotwise/scheduling-service/pkg/clock"
+	"github.com/slotwise/scheduling-service/pkg/events"
 	"github.com/slotwise/scheduling-service/pkg/logger"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
@@ -33,7 +37,7 @@ func (suite *AvailabilityServiceTestSuite) SetupSuite() {
 	}
 	suite.DB = db
 
-	err = suite.DB.AutoMigrate(&models.ServiceDefinition{}, &models.AvailabilityRule{})
+	err = suite.DB.AutoMigrate(&models.Business{}, &models.ServiceDefinition{}, &models.AvailabilityRule{}, &models.AvailabilityVersion{})
 	assert.NoError(suite.T(), err)
 
 	suite.AvailabilityRepo = repository.NewAvailabilityRepository(suite.DB)
@@ -41,7 +45,12 @@ func (suite *AvailabilityServiceTestSuite) SetupSuite() {
 	// GetAvailableSlots now uses BookingRepo.
 	bookingRepo := repository.NewBookingRepository(suite.DB) // Create BookingRepo for AvailabilityService
 	// Provide nil for CacheRepository and events.Publisher as per constructor
-	suite.AvailabilityService = service.NewAvailabilityService(suite.AvailabilityRepo, bookingRepo, nil, nil, suite.TestLogger)
+	suite.AvailabilityService = service.NewAvailabilityService(suite.AvailabilityRepo, bookingRepo, nil, nil, 0, 0, nil, nil, suite.TestLogger)
+}
+
+// newAvailabilityServiceAt returns an AvailabilityService whose clock is stopped at now.
+func (suite *AvailabilityServiceTestSuite) newAvailabilityServiceAt(now time.Time) *service.AvailabilityService {
+	return service.NewAvailabilityService(suite.AvailabilityRepo, repository.NewBookingRepository(suite.DB), nil, nil, 0, 0, nil, clock.NewFake(now), suite.TestLogger)
 }
 
 func (suite *AvailabilityServiceTestSuite) TearDownSuite() {
@@ -52,7 +61,9 @@ func (suite *AvailabilityServiceTestSuite) TearDownSuite() {
 func (suite *AvailabilityServiceTestSuite) SetupTest() {
 	suite.DB.Exec("DELETE FROM service_definitions")
 	suite.DB.Exec("DELETE FROM availability_rules")
+	suite.DB.Exec("DELETE FROM availability_versions")
 	suite.DB.Exec("DELETE FROM bookings") // Clean bookings as well
+	suite.DB.Exec("DELETE FROM businesses")
 }
 
 func (suite *AvailabilityServiceTestSuite) TestGetAvailableSlots_SimpleCase() {
@@ -94,6 +105,165 @@ func (suite *AvailabilityServiceTestSuite) TestGetAvailableSlots_SimpleCase() {
 	}
 }
 
+func (suite *AvailabilityServiceTestSuite) TestGetAvailableSlots_FollowsBusinessTimezoneChange() {
+	t := suite.T()
+	ctx := context.Background()
+	suite.DB.Create(&models.Business{ID: "biz_tz", Name: "Zoned", AcceptingBookings: true, Timezone: "Europe/Madrid"})
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_tz", BusinessID: "biz_tz", Name: "Zoned Service", DurationMinutes: 60, IsActive: true})
+	suite.DB.Create(&models.AvailabilityRule{BusinessID: "biz_tz", DayOfWeek: models.Monday, StartTime: "09:00", EndTime: "10:00"})
+	testDate, _ := time.Parse("2006-01-02", "2024-03-04") // A Monday
+
+	slots, err := suite.AvailabilityService.GetAvailableSlots(ctx, "biz_tz", "svc_tz", testDate)
+	assert.NoError(t, err)
+	if assert.Len(t, slots, 1) {
+		// 09:00 in Madrid (CET, UTC+1)
+		assert.True(t, slots[0].StartTime.Equal(time.Date(2024, 3, 4, 8, 0, 0, 0, time.UTC)), "got %s", slots[0].StartTime)
+	}
+
+	handlers := subscribers.NewNatsEventHandlers(suite.DB, suite.TestLogger)
+	updated := []byte(`{"id":"evt-tz","type":"business.updated","data":{"businessId":"biz_tz","changes":{"timezone":"America/New_York"}}}`)
+	assert.NoError(t, handlers.HandleBusinessUpdated(updated))
+
+	version, err := suite.AvailabilityService.GetAvailabilityVersion(ctx, "biz_tz")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), version, "Slots cached before the change should be marked stale")
+
+	slots, err = suite.AvailabilityService.GetAvailableSlots(ctx, "biz_tz", "svc_tz", testDate)
+	assert.NoError(t, err)
+	if assert.Len(t, slots, 1) {
+		// 09:00 in New York (EST, UTC-5)
+		assert.True(t, slots[0].StartTime.Equal(time.Date(2024, 3, 4, 14, 0, 0, 0, time.UTC)), "got %s", slots[0].StartTime)
+	}
+}
+
+func (suite *AvailabilityServiceTestSuite) TestGetAvailableSlots_DeletedBusiness() {
+	t := suite.T()
+	ctx := context.Background()
+
+	// The business was deleted but its service and rules were left behind
+	suite.DB.Create(&models.ServiceDefinition{
+		ID: "svc_orphan", BusinessID: "biz_deleted", Name: "Orphaned Service",
+		DurationMinutes: 30, Price: 1000, Currency: "USD", IsActive: true,
+	})
+	suite.DB.Create(&models.AvailabilityRule{BusinessID: "biz_deleted", DayOfWeek: models.Monday, StartTime: "09:00", EndTime: "10:00"})
+	business := models.Business{ID: "biz_deleted", Name: "Closed Shop", Status: "ACTIVE"}
+	suite.DB.Create(&business)
+	suite.DB.Delete(&business)
+
+	monday, _ := time.Parse("2006-01-02", "2024-03-04")
+	slots, err := suite.AvailabilityService.GetAvailableSlots(ctx, "biz_deleted", "svc_orphan", monday)
+	assert.Nil(t, slots)
+	assert.ErrorIs(t, err, service.ErrBusinessNotActive)
+	assert.ErrorContains(t, err, "biz_deleted has been deleted")
+}
+
+func (suite *AvailabilityServiceTestSuite) TestGetAvailableSlots_SuspendedBusiness() {
+	t := suite.T()
+	ctx := context.Background()
+
+	suite.DB.Create(&models.ServiceDefinition{
+		ID: "svc_suspended", BusinessID: "biz_suspended", Name: "Suspended Service",
+		DurationMinutes: 30, Price: 1000, Currency: "USD", IsActive: true,
+	})
+	suite.DB.Create(&models.AvailabilityRule{BusinessID: "biz_suspended", DayOfWeek: models.Monday, StartTime: "09:00", EndTime: "10:00"})
+	suite.DB.Create(&models.Business{ID: "biz_suspended", Name: "Suspended Shop", Status: models.BusinessStatusSuspended})
+
+	monday, _ := time.Parse("2006-01-02", "2024-03-04")
+	slots, err := suite.AvailabilityService.GetAvailableSlots(ctx, "biz_suspended", "svc_suspended", monday)
+	assert.Nil(t, slots)
+	assert.ErrorIs(t, err, service.ErrBusinessNotActive)
+}
+
+func (suite *AvailabilityServiceTestSuite) TestGetAvailableSlots_PausedBusinessKeepsCalendar() {
+	t := suite.T()
+	ctx := context.Background()
+
+	suite.DB.Create(&models.ServiceDefinition{
+		ID: "svc_paused", BusinessID: "biz_paused", Name: "Paused Service",
+		DurationMinutes: 30, Price: 1000, Currency: "USD", IsActive: true,
+	})
+	suite.DB.Create(&models.AvailabilityRule{BusinessID: "biz_paused", DayOfWeek: models.Monday, StartTime: "09:00", EndTime: "10:00"})
+	suite.DB.Create(&models.Business{ID: "biz_paused", Name: "Vacation Shop", Status: "ACTIVE"})
+
+	monday, _ := time.Parse("2006-01-02", "2024-03-04")
+	bookingStart := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+	suite.DB.Create(&models.Booking{
+		BusinessID: "biz_paused", ServiceID: "svc_paused", CustomerID: "cust_paused",
+		StartTime: bookingStart, EndTime: bookingStart.Add(30 * time.Minute), Status: models.BookingStatusConfirmed,
+	})
+	calendarBefore, err := suite.AvailabilityService.GetBusinessCalendar(ctx, "biz_paused", monday, monday, service.CalendarGranularityDay)
+	assert.NoError(t, err)
+
+	business, err := suite.AvailabilityService.SetAcceptingBookings(ctx, "biz_paused", false)
+	assert.NoError(t, err)
+	assert.False(t, business.AcceptingBookings)
+	assert.Equal(t, "ACTIVE", business.Status)
+
+	slots, err := suite.AvailabilityService.GetAvailableSlots(ctx, "biz_paused", "svc_paused", monday)
+	assert.NoError(t, err)
+	assert.Empty(t, slots)
+
+	// The owner can still see the existing schedule
+	calendarAfter, err := suite.AvailabilityService.GetBusinessCalendar(ctx, "biz_paused", monday, monday, service.CalendarGranularityDay)
+	assert.NoError(t, err)
+	assert.Equal(t, calendarBefore.Days, calendarAfter.Days)
+
+	var ruleCount int64
+	suite.DB.Model(&models.AvailabilityRule{}).Where("business_id = ?", "biz_paused").Count(&ruleCount)
+	assert.Equal(t, int64(1), ruleCount)
+
+	_, err = suite.AvailabilityService.SetAcceptingBookings(ctx, "biz_paused", true)
+	assert.NoError(t, err)
+	slots, err = suite.AvailabilityService.GetAvailableSlots(ctx, "biz_paused", "svc_paused", monday)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, slots)
+}
+
+func (suite *AvailabilityServiceTestSuite) TestSetAcceptingBookings_DeletedBusiness() {
+	t := suite.T()
+	ctx := context.Background()
+
+	business := models.Business{ID: "biz_gone", Name: "Gone Shop", Status: "ACTIVE"}
+	suite.DB.Create(&business)
+	suite.DB.Delete(&business)
+
+	result, err := suite.AvailabilityService.SetAcceptingBookings(ctx, "biz_gone", false)
+	assert.Nil(t, result)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "not found")
+	}
+}
+
+func (suite *AvailabilityServiceTestSuite) TestSetAcceptingBookings_UnknownBusiness() {
+	t := suite.T()
+	ctx := context.Background()
+
+	result, err := suite.AvailabilityService.SetAcceptingBookings(ctx, "biz_never_synced", false)
+	assert.Nil(t, result)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "not found")
+	}
+	var count int64
+	suite.DB.Model(&models.Business{}).Unscoped().Where("id = ?", "biz_never_synced").Count(&count)
+	assert.Zero(t, count, "No placeholder business is created")
+	suite.DB.Model(&models.AvailabilityVersion{}).Where("business_id = ?", "biz_never_synced").Count(&count)
+	assert.Zero(t, count, "The version is not bumped")
+}
+
+func (suite *AvailabilityServiceTestSuite) TestSetAcceptingBookings_BumpsAvailabilityVersion() {
+	t := suite.T()
+	ctx := context.Background()
+	suite.DB.Create(&models.Business{ID: "biz_pause_version", Name: "Versioned Shop", Status: "ACTIVE"})
+	suite.DB.Create(&models.AvailabilityVersion{BusinessID: "biz_pause_version", Revision: 3})
+
+	business, err := suite.AvailabilityService.SetAcceptingBookings(ctx, "biz_pause_version", false)
+	assert.NoError(t, err)
+	assert.False(t, business.AcceptingBookings)
+	version, err := suite.AvailabilityService.GetAvailabilityVersion(ctx, "biz_pause_version")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), version)
+}
+
 func (suite *AvailabilityServiceTestSuite) TestGetAvailableSlots_NoRulesForDay() {
 	t := suite.T()
 	ctx := context.Background()
@@ -129,6 +299,56 @@ func (suite *AvailabilityServiceTestSuite) TestGetAvailableSlots_ServiceNotFitti
 	assert.Len(t, slots, 0, "Service duration (61m) should not fit in 1-hour window")
 }
 
+func (suite *AvailabilityServiceTestSuite) TestGetAvailableSlots_ZeroDurationServiceHasNoSlots() {
+	t := suite.T()
+	ctx := context.Background()
+	// Stored directly, as the subscriber would reject it
+	suite.DB.Create(&models.ServiceDefinition{
+		ID: "svc_zero", BusinessID: "biz_zero", Name: "Zero Service",
+		DurationMinutes: 0, IsActive: true,
+	})
+	suite.DB.Create(&models.AvailabilityRule{BusinessID: "biz_zero", DayOfWeek: models.Monday, StartTime: "09:00", EndTime: "17:00"})
+
+	monday, _ := time.Parse("2006-01-02", "2024-03-04")
+	slots, err := suite.AvailabilityService.GetAvailableSlots(ctx, "biz_zero", "svc_zero", monday)
+	assert.NoError(t, err)
+	assert.Empty(t, slots)
+}
+
+func (suite *AvailabilityServiceTestSuite) TestGetAvailableSlotsCapped_TruncatesPathologicalRule() {
+	t := suite.T()
+	ctx := context.Background()
+	availabilityService := service.NewAvailabilityService(suite.AvailabilityRepo, repository.NewBookingRepository(suite.DB), nil, nil, 0, 100, nil, nil, suite.TestLogger)
+
+	suite.DB.Create(&models.ServiceDefinition{
+		ID: "svc_tiny", BusinessID: "biz_allday", Name: "One Minute Service",
+		DurationMinutes: 1, IsActive: true,
+	})
+	// A whole-day rule with 1-minute slots would otherwise yield 1439 slots
+	suite.DB.Create(&models.AvailabilityRule{
+		BusinessID: "biz_allday", DayOfWeek: models.Monday, StartTime: "00:00", EndTime: "23:59",
+	})
+
+	monday, _ := time.Parse("2006-01-02", "2024-03-04")
+	slots, truncated, err := availabilityService.GetAvailableSlotsCapped(ctx, "biz_allday", "svc_tiny", monday)
+	assert.NoError(t, err)
+	assert.True(t, truncated)
+	if assert.Len(t, slots, 100) {
+		assert.Equal(t, time.Date(2024, 3, 4, 0, 0, 0, 0, monday.Location()), slots[0].StartTime)
+		assert.Equal(t, time.Date(2024, 3, 4, 1, 39, 0, 0, monday.Location()), slots[99].StartTime)
+	}
+
+	// A day that fits under the cap is not flagged
+	suite.DB.Create(&models.AvailabilityRule{
+		BusinessID: "biz_allday", DayOfWeek: models.Tuesday, StartTime: "09:00", EndTime: "10:00",
+	})
+	tuesday, _ := time.Parse("2006-01-02", "2024-03-05")
+	slots, truncated, err = availabilityService.GetAvailableSlotsCapped(ctx, "biz_allday", "svc_tiny", tuesday)
+	assert.NoError(t, err)
+	assert.False(t, truncated)
+	assert.Len(t, slots, 60)
+}
+
 func (suite *AvailabilityServiceTestSuite) TestGetAvailableSlots_MultipleRulesOnSameDay() {
 	t := suite.T()
 	ctx := context.Background()
@@ -149,6 +369,46 @@ func (suite *AvailabilityServiceTestSuite) TestGetAvailableSlots_MultipleRulesOn
 	assert.Len(t, slots, 3, "Should find 3 slots across two rules for Thursday")
 }
 
+func (suite *AvailabilityServiceTestSuite) TestGetAvailableSlots_BackToBackSetting() {
+	t := suite.T()
+	ctx := context.Background()
+	suite.DB.Create(&models.Business{ID: "biz_b2b", Name: "Adjacent", AcceptingBookings: true})
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_b2b", BusinessID: "biz_b2b", Name: "Trim", DurationMinutes: 30, IsActive: true})
+	suite.DB.Create(&models.AvailabilityRule{BusinessID: "biz_b2b", DayOfWeek: models.Monday, StartTime: "09:00", EndTime: "11:00"})
+	suite.DB.Create(&models.Booking{
+		BusinessID: "biz_b2b", ServiceID: "svc_b2b", CustomerID: "cust_b2b",
+		StartTime: time.Date(2024, 3, 4, 9, 30, 0, 0, time.UTC), EndTime: time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC), Status: models.BookingStatusConfirmed,
+	})
+	testDate, _ := time.Parse("2006-01-02", "2024-03-04") // A Monday
+
+	// Allowed by default: the slots either side of the booking touch it
+	slots, err := suite.AvailabilityService.GetAvailableSlots(ctx, "biz_b2b", "svc_b2b", testDate)
+	assert.NoError(t, err)
+	if assert.Len(t, slots, 3) {
+		assert.Equal(t, time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC), slots[0].StartTime)
+		assert.Equal(t, time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC), slots[1].StartTime)
+		assert.Equal(t, time.Date(2024, 3, 4, 10, 30, 0, 0, time.UTC), slots[2].StartTime)
+	}
+
+	handlers := subscribers.NewNatsEventHandlers(suite.DB, suite.TestLogger)
+	updated := []byte(`{"id":"evt-b2b","type":"business.updated","data":{"businessId":"biz_b2b","changes":{"allowBackToBack":false}}}`)
+	assert.NoError(t, handlers.HandleBusinessUpdated(updated))
+
+	var business models.Business
+	assert.NoError(t, suite.DB.First(&business, "id = ?", "biz_b2b").Error)
+	assert.False(t, business.AllowBackToBack)
+	version, err := suite.AvailabilityService.GetAvailabilityVersion(ctx, "biz_b2b")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), version, "Slots cached before the change should be marked stale")
+
+	// Forbidden: only the slot with a gap before it remains
+	slots, err = suite.AvailabilityService.GetAvailableSlots(ctx, "biz_b2b", "svc_b2b", testDate)
+	assert.NoError(t, err)
+	if assert.Len(t, slots, 1) {
+		assert.Equal(t, time.Date(2024, 3, 4, 10, 30, 0, 0, time.UTC), slots[0].StartTime)
+	}
+}
+
 func (suite *AvailabilityServiceTestSuite) TestGetAvailableSlots_ServiceInactive() {
 	t := suite.T()
 	ctx := context.Background()
@@ -222,10 +482,564 @@ func (suite *AvailabilityServiceTestSuite) TestGetAvailableSlots_ServiceWrongBus
 	assert.Len(t, slots, 0)
 }
 
+func (suite *AvailabilityServiceTestSuite) TestGetBusinessCalendar_ListsServiceColors() {
+	t := suite.T()
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_cal_color", BusinessID: "biz_cal_color", Name: "Colour Treatment", DurationMinutes: 90, Currency: "USD", IsActive: true, Color: "#4F46E5", ShortLabel: "Colour"})
+
+	monday, _ := time.Parse("2006-01-02", "2024-03-04")
+	calendar, err := suite.AvailabilityService.GetBusinessCalendar(context.Background(), "biz_cal_color", monday, monday, service.CalendarGranularityDay)
+	assert.NoError(t, err)
+	assert.Equal(t, []service.CalendarService{{ID: "svc_cal_color", Name: "Colour Treatment", Color: "#4F46E5", ShortLabel: "Colour"}}, calendar.Services)
+}
+
+func (suite *AvailabilityServiceTestSuite) TestGetBusinessCalendar_WeeklyRollupsSumDays() {
+	t := suite.T()
+	suite.seedWeekdayMornings()
+
+	// Friday 2024-03-01 (ISO week 9) through Sunday 2024-03-10 (ISO week 10)
+	start, _ := time.Parse("2006-01-02", "2024-03-01")
+	end, _ := time.Parse("2006-01-02", "2024-03-10")
+	calendar, err := suite.AvailabilityService.GetBusinessCalendar(context.Background(), "biz_alt", start, end, service.CalendarGranularityWeek)
+	assert.NoError(t, err)
+	assert.Equal(t, service.CalendarGranularityWeek, calendar.Granularity)
+	assert.Len(t, calendar.Days, 10)
+
+	// Six 30 minute slots each weekday morning; the Wednesday booking covers two of them
+	assert.Equal(t, []service.CalendarPeriodSummary{
+		{Period: "2024-W09", StartDate: "2024-03-01", EndDate: "2024-03-03", TotalSlots: 6, BookedSlots: 0, AvailableSlots: 6, Utilization: 0},
+		{Period: "2024-W10", StartDate: "2024-03-04", EndDate: "2024-03-10", TotalSlots: 30, BookedSlots: 2, AvailableSlots: 28, Utilization: 2.0 / 30},
+	}, calendar.Periods)
+
+	for _, period := range calendar.Periods {
+		var total, booked int
+		for _, day := range calendar.Days {
+			if day.Date >= period.StartDate && day.Date <= period.EndDate {
+				total += day.TotalSlots
+				booked += day.BookedSlots
+			}
+		}
+		assert.Equal(t, total, period.TotalSlots, period.Period)
+		assert.Equal(t, booked, period.BookedSlots, period.Period)
+	}
+}
+
+func (suite *AvailabilityServiceTestSuite) TestGetBusinessCalendar_InvalidGranularity() {
+	monday, _ := time.Parse("2006-01-02", "2024-03-04")
+	_, err := suite.AvailabilityService.GetBusinessCalendar(context.Background(), "biz_alt", monday, monday, "quarter")
+	assert.ErrorContains(suite.T(), err, "invalid granularity")
+}
+
+// --- HasAvailability Tests ---
+func (suite *AvailabilityServiceTestSuite) seedAnyServiceBusiness() {
+	suite.DB.Create(&models.Business{ID: "biz_any", Name: "Any Service Shop", Status: "ACTIVE"})
+	suite.DB.Create(&[]models.ServiceDefinition{
+		// Too long to fit the only opening, so it never has a slot
+		{ID: "svc_any_long", BusinessID: "biz_any", Name: "Long", DurationMinutes: 90, Price: 1000, Currency: "USD", IsActive: true},
+		{ID: "svc_any_short", BusinessID: "biz_any", Name: "Short", DurationMinutes: 30, Price: 1000, Currency: "USD", IsActive: true},
+	})
+	suite.DB.Create(&models.AvailabilityRule{BusinessID: "biz_any", DayOfWeek: models.Monday, StartTime: "09:00", EndTime: "10:00"})
+}
+
+func (suite *AvailabilityServiceTestSuite) TestHasAvailability_OneServiceHasSlot() {
+	t := suite.T()
+	suite.seedAnyServiceBusiness()
+
+	monday, _ := time.Parse("2006-01-02", "2024-03-04")
+	available, err := suite.newAvailabilityServiceAt(monday.AddDate(0, 0, -3)).HasAvailability(context.Background(), "biz_any", monday)
+	assert.NoError(t, err)
+	assert.True(t, available)
+}
+
+func (suite *AvailabilityServiceTestSuite) TestHasAvailability_SkipsSlotsThatCannotBeBooked() {
+	t := suite.T()
+	ctx := context.Background()
+	suite.seedAnyServiceBusiness()
+	monday, _ := time.Parse("2006-01-02", "2024-03-04")
+
+	// At 09:10 the 09:00 slot has started, but 09:30 is still open
+	availabilityService := suite.newAvailabilityServiceAt(monday.Add(9*time.Hour + 10*time.Minute))
+	available, err := availabilityService.HasAvailability(ctx, "biz_any", monday)
+	assert.NoError(t, err)
+	assert.True(t, available)
+
+	// With 30 minutes' notice 09:30 is too soon as well
+	suite.DB.Model(&models.Business{}).Where("id = ?", "biz_any").Update("minimum_notice_minutes", 30)
+	available, err = availabilityService.HasAvailability(ctx, "biz_any", monday)
+	assert.NoError(t, err)
+	assert.False(t, available)
+
+	// The day after, Monday is over
+	available, err = suite.newAvailabilityServiceAt(monday.AddDate(0, 0, 1)).HasAvailability(ctx, "biz_any", monday)
+	assert.NoError(t, err)
+	assert.False(t, available)
+}
+
+func (suite *AvailabilityServiceTestSuite) TestHasAvailability_FullyBookedOrClosed() {
+	t := suite.T()
+	ctx := context.Background()
+	suite.seedAnyServiceBusiness()
+
+	bookingStart := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+	suite.DB.Create(&models.Booking{
+		BusinessID: "biz_any", ServiceID: "svc_any_short", CustomerID: "cust_any",
+		StartTime: bookingStart, EndTime: bookingStart.Add(60 * time.Minute), Status: models.BookingStatusConfirmed,
+	})
+
+	monday, _ := time.Parse("2006-01-02", "2024-03-04")
+	availabilityService := suite.newAvailabilityServiceAt(monday.AddDate(0, 0, -3))
+	available, err := availabilityService.HasAvailability(ctx, "biz_any", monday)
+	assert.NoError(t, err)
+	assert.False(t, available, "every slot on Monday is booked")
+
+	tuesday, _ := time.Parse("2006-01-02", "2024-03-05")
+	available, err = availabilityService.HasAvailability(ctx, "biz_any", tuesday)
+	assert.NoError(t, err)
+	assert.False(t, available, "the business has no rules on Tuesday")
+}
+
+// --- SuggestAlternatives Tests ---
+func (suite *AvailabilityServiceTestSuite) seedWeekdayMornings() {
+	suite.DB.Create(&models.Business{ID: "biz_alt", Name: "Alternatives Shop", Status: "ACTIVE"})
+	suite.DB.Create(&models.ServiceDefinition{
+		ID: "svc_alt", BusinessID: "biz_alt", Name: "Hour", DurationMinutes: 60, Price: 1000, Currency: "USD", IsActive: true,
+	})
+	for _, day := range []models.DayOfWeekString{models.Monday, models.Tuesday, models.Wednesday, models.Thursday, models.Friday} {
+		suite.DB.Create(&models.AvailabilityRule{BusinessID: "biz_alt", DayOfWeek: day, StartTime: "09:00", EndTime: "12:00"})
+	}
+	// The slot the customer wanted is already booked
+	taken := time.Date(2024, 3, 6, 10, 0, 0, 0, time.UTC) // Wednesday
+	suite.DB.Create(&models.Booking{
+		BusinessID: "biz_alt", ServiceID: "svc_alt", CustomerID: "cust_alt",
+		StartTime: taken, EndTime: taken.Add(time.Hour), Status: models.BookingStatusConfirmed,
+	})
+}
+
+func (suite *AvailabilityServiceTestSuite) TestListServicesWithAvailability_FlagsFullyBookedServices() {
+	t := suite.T()
+	suite.DB.Create(&models.Business{ID: "biz_menu", Name: "Menu", AcceptingBookings: true})
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_menu_long", BusinessID: "biz_menu", Name: "Full Treatment", DurationMinutes: 60, IsActive: true})
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_menu_short", BusinessID: "biz_menu", Name: "Quick Trim", DurationMinutes: 30, IsActive: true})
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_menu_retired", BusinessID: "biz_menu", Name: "Retired", DurationMinutes: 30, IsActive: false})
+	// Open one hour a week, and half of it is booked: only the short service still fits before next Monday
+	suite.DB.Create(&models.AvailabilityRule{BusinessID: "biz_menu", DayOfWeek: models.Monday, StartTime: "09:00", EndTime: "10:00"})
+	suite.DB.Create(&models.Booking{
+		BusinessID: "biz_menu", ServiceID: "svc_menu_short", CustomerID: "cust_menu",
+		StartTime: time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC), EndTime: time.Date(2024, 3, 4, 9, 30, 0, 0, time.UTC), Status: models.BookingStatusConfirmed,
+	})
+	now := time.Date(2024, 3, 4, 8, 0, 0, 0, time.UTC) // Monday morning
+	availabilityService := service.NewAvailabilityService(suite.AvailabilityRepo, repository.NewBookingRepository(suite.DB), nil, nil, 0, 0, nil, clock.NewFake(now), suite.TestLogger)
+
+	services, err := availabilityService.ListServicesWithAvailability(context.Background(), "biz_menu")
+	assert.NoError(t, err)
+
+	flags := map[string]bool{}
+	for _, svc := range services {
+		flags[svc.ID] = svc.HasUpcomingAvailability
+	}
+	assert.Equal(t, map[string]bool{"svc_menu_long": false, "svc_menu_short": true}, flags, "Inactive services are not listed")
+
+	// With two hours' notice the 09:30 slot is too soon to book, and next Monday is past the look-ahead
+	suite.DB.Model(&models.Business{}).Where("id = ?", "biz_menu").Update("minimum_notice_minutes", 120)
+	services, err = availabilityService.ListServicesWithAvailability(context.Background(), "biz_menu")
+	assert.NoError(t, err)
+	for _, svc := range services {
+		assert.False(t, svc.HasUpcomingAvailability, svc.ID)
+	}
+}
+
+func (suite *AvailabilityServiceTestSuite) TestSuggestAlternatives_NearestOnBothSides() {
+	t := suite.T()
+	suite.seedWeekdayMornings()
+	now := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	availabilityService := service.NewAvailabilityService(suite.AvailabilityRepo, repository.NewBookingRepository(suite.DB), nil, nil, 0, 0, nil, clock.NewFake(now), suite.TestLogger)
+
+	desired := time.Date(2024, 3, 6, 10, 0, 0, 0, time.UTC)
+	slots, err := availabilityService.SuggestAlternatives(context.Background(), "biz_alt", "svc_alt", desired, 4)
+	assert.NoError(t, err)
+
+	var starts []time.Time
+	for _, slot := range slots {
+		starts = append(starts, slot.StartTime)
+	}
+	assert.Equal(t, []time.Time{
+		time.Date(2024, 3, 6, 9, 0, 0, 0, time.UTC),  // 1h before
+		time.Date(2024, 3, 6, 11, 0, 0, 0, time.UTC), // 1h after
+		time.Date(2024, 3, 5, 11, 0, 0, 0, time.UTC), // 23h before
+		time.Date(2024, 3, 7, 9, 0, 0, 0, time.UTC),  // 23h after
+	}, starts)
+}
+
+func (suite *AvailabilityServiceTestSuite) TestSuggestAlternatives_SkipsPastSlots() {
+	t := suite.T()
+	suite.seedWeekdayMornings()
+	now := time.Date(2024, 3, 6, 9, 30, 0, 0, time.UTC)
+	availabilityService := service.NewAvailabilityService(suite.AvailabilityRepo, repository.NewBookingRepository(suite.DB), nil, nil, 0, 0, nil, clock.NewFake(now), suite.TestLogger)
+
+	desired := time.Date(2024, 3, 6, 10, 0, 0, 0, time.UTC)
+	slots, err := availabilityService.SuggestAlternatives(context.Background(), "biz_alt", "svc_alt", desired, 2)
+	assert.NoError(t, err)
+	if assert.Len(t, slots, 2) {
+		assert.Equal(t, time.Date(2024, 3, 6, 11, 0, 0, 0, time.UTC), slots[0].StartTime)
+		assert.Equal(t, time.Date(2024, 3, 7, 9, 0, 0, 0, time.UTC), slots[1].StartTime)
+	}
+}
+
+// --- PreviewRuleChange Tests ---
+func (suite *AvailabilityServiceTestSuite) TestPreviewRuleChange_FlagsBookingsOutsideNarrowedHours() {
+	t := suite.T()
+	ctx := context.Background()
+	now := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	availabilityService := service.NewAvailabilityService(suite.AvailabilityRepo, repository.NewBookingRepository(suite.DB), nil, nil, 0, 0, nil, clock.NewFake(now), suite.TestLogger)
+
+	suite.DB.Create(&models.AvailabilityRule{BusinessID: "biz_preview", DayOfWeek: models.Monday, StartTime: "09:00", EndTime: "17:00"})
+	monday := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	edge := models.Booking{
+		BusinessID: "biz_preview", ServiceID: "svc_preview", CustomerID: "cust_edge",
+		StartTime: monday.Add(9 * time.Hour), EndTime: monday.Add(9*time.Hour + 30*time.Minute), Status: models.BookingStatusConfirmed,
+	}
+	midday := models.Booking{
+		BusinessID: "biz_preview", ServiceID: "svc_preview", CustomerID: "cust_midday",
+		StartTime: monday.Add(12 * time.Hour), EndTime: monday.Add(12*time.Hour + 30*time.Minute), Status: models.BookingStatusConfirmed,
+	}
+	suite.DB.Create(&edge)
+	suite.DB.Create(&midday)
+
+	// Opening an hour later on Mondays leaves the 09:00 booking out of hours
+	outOfHours, err := availabilityService.PreviewRuleChange(ctx, "biz_preview", []service.ProposedAvailabilityRule{
+		{DayOfWeek: models.Monday, StartTime: "10:00", EndTime: "17:00"},
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, outOfHours, 1) {
+		assert.Equal(t, edge.ID, outOfHours[0].ID)
+	}
+
+	// Closing Mondays entirely flags both
+	outOfHours, err = availabilityService.PreviewRuleChange(ctx, "biz_preview", []service.ProposedAvailabilityRule{
+		{DayOfWeek: models.Tuesday, StartTime: "09:00", EndTime: "17:00"},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, outOfHours, 2)
+
+	// Nothing is persisted
+	rules, err := suite.AvailabilityRepo.GetActiveAvailabilityRules(ctx, "biz_preview", models.Monday)
+	assert.NoError(t, err)
+	if assert.Len(t, rules, 1) {
+		assert.Equal(t, "09:00", rules[0].StartTime)
+	}
+
+	_, err = availabilityService.PreviewRuleChange(ctx, "biz_preview", []service.ProposedAvailabilityRule{
+		{DayOfWeek: models.Monday, StartTime: "17:00", EndTime: "10:00"},
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "invalid")
+	}
+}
+
+// --- GetAvailabilitySnapshot Tests ---
+func (suite *AvailabilityServiceTestSuite) TestGetAvailabilitySnapshot_ConcurrentLookups() {
+	t := suite.T()
+	ctx := context.Background()
+
+	// More businesses than workers; every third one is open from an hour that depends on its index
+	var businessIDs []string
+	for i := 0; i < 20; i++ {
+		businessID := fmt.Sprintf("biz_snap_%02d", i)
+		businessIDs = append(businessIDs, businessID)
+		suite.DB.Create(&models.Business{ID: businessID, Name: businessID, Status: "ACTIVE"})
+		suite.DB.Create(&models.ServiceDefinition{
+			ID: "svc_" + businessID, BusinessID: businessID, Name: "Snapshot Service",
+			DurationMinutes: 30, Price: 1000, Currency: "USD", IsActive: true,
+		})
+		if i%3 == 0 {
+			start := fmt.Sprintf("%02d:00", 8+i/3)
+			end := fmt.Sprintf("%02d:00", 9+i/3)
+			suite.DB.Create(&models.AvailabilityRule{BusinessID: businessID, DayOfWeek: models.Monday, StartTime: start, EndTime: end})
+		}
+	}
+	suite.DB.Create(&models.Business{ID: "biz_snap_suspended", Name: "Suspended", Status: models.BusinessStatusSuspended})
+	businessIDs = append(businessIDs, "biz_snap_suspended")
+
+	monday, _ := time.Parse("2006-01-02", "2024-03-04")
+	snapshots, err := suite.newAvailabilityServiceAt(monday.AddDate(0, 0, -3)).GetAvailabilitySnapshot(ctx, businessIDs, monday)
+	assert.NoError(t, err)
+	assert.Len(t, snapshots, len(businessIDs))
+
+	for i, snapshot := range snapshots[:20] {
+		assert.Equal(t, businessIDs[i], snapshot.BusinessID)
+		assert.Empty(t, snapshot.Error)
+		if i%3 == 0 {
+			assert.True(t, snapshot.HasAvailability, snapshot.BusinessID)
+			if assert.NotNil(t, snapshot.NextSlot, snapshot.BusinessID) {
+				assert.Equal(t, time.Date(2024, 3, 4, 8+i/3, 0, 0, 0, time.UTC), snapshot.NextSlot.StartTime)
+			}
+		} else {
+			assert.False(t, snapshot.HasAvailability, snapshot.BusinessID)
+			assert.Nil(t, snapshot.NextSlot, snapshot.BusinessID)
+		}
+	}
+	suspended := snapshots[20]
+	assert.Equal(t, "biz_snap_suspended", suspended.BusinessID)
+	assert.False(t, suspended.HasAvailability)
+	assert.Equal(t, service.SnapshotErrorBusinessNotActive, suspended.Error)
+}
+
+func (suite *AvailabilityServiceTestSuite) TestGetAvailabilitySnapshot_TooManyBusinesses() {
+	businessIDs := make([]string, service.MaxSnapshotBusinesses+1)
+	for i := range businessIDs {
+		businessIDs[i] = fmt.Sprintf("biz_%d", i)
+	}
+	_, err := suite.AvailabilityService.GetAvailabilitySnapshot(context.Background(), businessIDs, time.Now())
+	assert.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "too many")
+}
+
+// --- Rule time normalization Tests ---
+func (suite *AvailabilityServiceTestSuite) TestCreateAvailabilityRule_NormalizesTimes() {
+	t := suite.T()
+	ctx := context.Background()
+
+	padded, err := suite.AvailabilityService.CreateAvailabilityRule(ctx, service.CreateAvailabilityRuleRequest{
+		BusinessID: "biz_norm", DayOfWeek: models.Monday, StartTime: "09:00", EndTime: "10:00",
+	})
+	assert.NoError(t, err)
+	// "9:00" >= "10:00" as strings, so this used to be rejected
+	unpadded, err := suite.AvailabilityService.CreateAvailabilityRule(ctx, service.CreateAvailabilityRuleRequest{
+		BusinessID: "biz_norm", DayOfWeek: models.Tuesday, StartTime: "9:00", EndTime: "10:00",
+	})
+	assert.NoError(t, err)
+
+	var dbRule models.AvailabilityRule
+	suite.DB.First(&dbRule, unpadded.ID)
+	assert.Equal(t, padded.StartTime, dbRule.StartTime)
+	assert.Equal(t, "09:00", dbRule.StartTime)
+	assert.Equal(t, "10:00", dbRule.EndTime)
+}
+
+func (suite *AvailabilityServiceTestSuite) TestCreateAvailabilityRule_ComparesTimesChronologically() {
+	// "10:00" < "9:30" as strings, but the rule would end before it starts
+	_, err := suite.AvailabilityService.CreateAvailabilityRule(context.Background(), service.CreateAvailabilityRuleRequest{
+		BusinessID: "biz_norm", DayOfWeek: models.Monday, StartTime: "10:00", EndTime: "9:30",
+	})
+	assert.ErrorContains(suite.T(), err, "startTime (10:00) must be before endTime (09:30)")
+
+	var ruleCount int64
+	suite.DB.Model(&models.AvailabilityRule{}).Where("business_id = ?", "biz_norm").Count(&ruleCount)
+	assert.Equal(suite.T(), int64(0), ruleCount)
+}
+
+func (suite *AvailabilityServiceTestSuite) TestAvailabilityRule_DuplicateIsSentinel() {
+	t := suite.T()
+	ctx := context.Background()
+	req := service.CreateAvailabilityRuleRequest{BusinessID: "biz_dup", DayOfWeek: models.Monday, StartTime: "09:00", EndTime: "12:00"}
+
+	_, err := suite.AvailabilityService.CreateAvailabilityRule(ctx, req)
+	assert.NoError(t, err)
+	_, err = suite.AvailabilityService.CreateAvailabilityRule(ctx, req)
+	assert.ErrorIs(t, err, repository.ErrDuplicateAvailabilityRule)
+
+	other, err := suite.AvailabilityService.CreateAvailabilityRule(ctx, service.CreateAvailabilityRuleRequest{
+		BusinessID: "biz_dup", DayOfWeek: models.Monday, StartTime: "13:00", EndTime: "17:00",
+	})
+	assert.NoError(t, err)
+	startTime, endTime := "09:00", "12:00"
+	_, err = suite.AvailabilityService.UpdateAvailabilityRule(ctx, other.ID, service.UpdateAvailabilityRuleRequest{StartTime: &startTime, EndTime: &endTime})
+	assert.ErrorIs(t, err, repository.ErrDuplicateAvailabilityRule)
+}
+
+func (suite *AvailabilityServiceTestSuite) TestUpdateAvailabilityRule_NormalizesTimes() {
+	t := suite.T()
+	rule := suite.seedRuleForUpdate()
+
+	startTime := "8:30"
+	updated, err := suite.AvailabilityService.UpdateAvailabilityRule(context.Background(), rule.ID, service.UpdateAvailabilityRuleRequest{StartTime: &startTime})
+	assert.NoError(t, err)
+	assert.Equal(t, "08:30", updated.StartTime)
+}
+
+// --- UpdateAvailabilityRule Tests ---
+func (suite *AvailabilityServiceTestSuite) seedRuleForUpdate() models.AvailabilityRule {
+	rule := models.AvailabilityRule{BusinessID: "biz_patch", DayOfWeek: models.Tuesday, StartTime: "09:00", EndTime: "12:00", BufferMinutes: 5}
+	suite.DB.Create(&rule)
+	return rule
+}
+
+func (suite *AvailabilityServiceTestSuite) TestUpdateAvailabilityRule_OnlyBufferMinutes() {
+	t := suite.T()
+	rule := suite.seedRuleForUpdate()
+
+	buffer := 15
+	updated, err := suite.AvailabilityService.UpdateAvailabilityRule(context.Background(), rule.ID, service.UpdateAvailabilityRuleRequest{BufferMinutes: &buffer})
+	assert.NoError(t, err)
+	assert.Equal(t, 15, updated.BufferMinutes)
+
+	var dbRule models.AvailabilityRule
+	suite.DB.First(&dbRule, rule.ID)
+	assert.Equal(t, 15, dbRule.BufferMinutes)
+	assert.Equal(t, models.Tuesday, dbRule.DayOfWeek)
+	assert.Equal(t, "09:00", dbRule.StartTime)
+	assert.Equal(t, "12:00", dbRule.EndTime)
+}
+
+func (suite *AvailabilityServiceTestSuite) TestUpdateAvailabilityRule_OnlyEndTime() {
+	t := suite.T()
+	rule := suite.seedRuleForUpdate()
+
+	endTime := "17:30"
+	updated, err := suite.AvailabilityService.UpdateAvailabilityRule(context.Background(), rule.ID, service.UpdateAvailabilityRuleRequest{EndTime: &endTime})
+	assert.NoError(t, err)
+	assert.Equal(t, "17:30", updated.EndTime)
+
+	var dbRule models.AvailabilityRule
+	suite.DB.First(&dbRule, rule.ID)
+	assert.Equal(t, "17:30", dbRule.EndTime)
+	assert.Equal(t, "09:00", dbRule.StartTime)
+	assert.Equal(t, 5, dbRule.BufferMinutes)
+	assert.Equal(t, models.Tuesday, dbRule.DayOfWeek)
+}
+
+func (suite *AvailabilityServiceTestSuite) TestUpdateAvailabilityRule_MergedRuleInvalid() {
+	t := suite.T()
+	rule := suite.seedRuleForUpdate()
+
+	// New end time is valid on its own but before the existing start time
+	endTime := "08:00"
+	_, err := suite.AvailabilityService.UpdateAvailabilityRule(context.Background(), rule.ID, service.UpdateAvailabilityRuleRequest{EndTime: &endTime})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "must be before")
+
+	var dbRule models.AvailabilityRule
+	suite.DB.First(&dbRule, rule.ID)
+	assert.Equal(t, "12:00", dbRule.EndTime, "rule must be unchanged after a failed update")
+}
+
+func (suite *AvailabilityServiceTestSuite) TestUpdateAvailabilityRule_RecordsActorAndTimestamp() {
+	t := suite.T()
+	rule := suite.seedRuleForUpdate()
+	assert.Empty(t, rule.UpdatedBy)
+
+	// Ensure the new UpdatedAt is distinguishable from the seeded one
+	time.Sleep(10 * time.Millisecond)
+	buffer := 20
+	updated, err := suite.AvailabilityService.UpdateAvailabilityRule(context.Background(), rule.ID, service.UpdateAvailabilityRuleRequest{BufferMinutes: &buffer, UpdatedBy: "user_editor"})
+	assert.NoError(t, err)
+	assert.Equal(t, "user_editor", updated.UpdatedBy)
+
+	var dbRule models.AvailabilityRule
+	suite.DB.First(&dbRule, rule.ID)
+	assert.Equal(t, "user_editor", dbRule.UpdatedBy)
+	assert.True(t, dbRule.UpdatedAt.After(rule.UpdatedAt), "UpdatedAt must advance on update")
+	assert.WithinDuration(t, rule.CreatedAt, dbRule.CreatedAt, time.Millisecond, "CreatedAt must not change on update")
+}
+
+func (suite *AvailabilityServiceTestSuite) TestUpdateAvailabilityRule_NotFound() {
+	buffer := 10
+	_, err := suite.AvailabilityService.UpdateAvailabilityRule(context.Background(), 999999, service.UpdateAvailabilityRuleRequest{BufferMinutes: &buffer})
+	assert.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "not found")
+}
+
+func (suite *AvailabilityServiceTestSuite) TestSetAvailabilityRuleActive_DeactivateAndReactivate() {
+	t := suite.T()
+	ctx := context.Background()
+	suite.DB.Create(&models.ServiceDefinition{
+		ID: "svc_seasonal", BusinessID: "biz_seasonal", Name: "Seasonal Service",
+		DurationMinutes: 30, IsActive: true,
+	})
+	rule := models.AvailabilityRule{BusinessID: "biz_seasonal", DayOfWeek: models.Monday, StartTime: "09:00", EndTime: "10:00"}
+	suite.DB.Create(&rule)
+	assert.True(t, rule.Active, "New rules are active by default")
+
+	monday, _ := time.Parse("2006-01-02", "2024-03-04")
+	slots, err := suite.AvailabilityService.GetAvailableSlots(ctx, "biz_seasonal", "svc_seasonal", monday)
+	assert.NoError(t, err)
+	assert.Len(t, slots, 2)
+
+	deactivated, err := suite.AvailabilityService.SetAvailabilityRuleActive(ctx, rule.ID, false, "owner-1")
+	assert.NoError(t, err)
+	assert.False(t, deactivated.Active)
+
+	slots, err = suite.AvailabilityService.GetAvailableSlots(ctx, "biz_seasonal", "svc_seasonal", monday)
+	assert.NoError(t, err)
+	assert.Empty(t, slots, "A deactivated rule should produce no slots")
+
+	// The rule is kept and still listed
+	stored, err := suite.AvailabilityService.GetAvailabilityRule(ctx, rule.ID)
+	assert.NoError(t, err)
+	assert.False(t, stored.Active)
+	assert.Equal(t, "owner-1", stored.UpdatedBy)
+	listed, err := suite.AvailabilityService.ListAvailabilityRules(ctx, "biz_seasonal")
+	assert.NoError(t, err)
+	assert.Len(t, listed, 1)
+
+	_, err = suite.AvailabilityService.SetAvailabilityRuleActive(ctx, rule.ID, true, "owner-1")
+	assert.NoError(t, err)
+	slots, err = suite.AvailabilityService.GetAvailableSlots(ctx, "biz_seasonal", "svc_seasonal", monday)
+	assert.NoError(t, err)
+	assert.Len(t, slots, 2)
+}
+
+func (suite *AvailabilityServiceTestSuite) TestSetAvailabilityRuleActive_NotFound() {
+	_, err := suite.AvailabilityService.SetAvailabilityRuleActive(context.Background(), 999999, false, "")
+	assert.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "not found")
+}
+
 func TestAvailabilityServiceTestSuite(t *testing.T) {
 	suite.Run(t, new(AvailabilityServiceTestSuite))
 }
 
+func (suite *AvailabilityServiceTestSuite) TestDeleteRulesForDay_ClearsSunday() {
+	t := suite.T()
+	ctx := context.Background()
+	publisher := NewMockEventPublisher()
+	availabilityService := service.NewAvailabilityService(suite.AvailabilityRepo, repository.NewBookingRepository(suite.DB), nil, nil, 0, 0, publisher, nil, suite.TestLogger)
+
+	suite.DB.Create(&models.ServiceDefinition{
+		ID: "svc_sunday", BusinessID: "biz_sunday", Name: "Weekend Service",
+		DurationMinutes: 30, Price: 1000, Currency: "USD", IsActive: true,
+	})
+	suite.DB.Create(&[]models.AvailabilityRule{
+		{BusinessID: "biz_sunday", DayOfWeek: models.Sunday, StartTime: "09:00", EndTime: "11:00"},
+		{BusinessID: "biz_sunday", DayOfWeek: models.Sunday, StartTime: "13:00", EndTime: "15:00"},
+		{BusinessID: "biz_sunday", DayOfWeek: models.Monday, StartTime: "09:00", EndTime: "11:00"},
+	})
+
+	sunday, _ := time.Parse("2006-01-02", "2024-03-10")
+	monday, _ := time.Parse("2006-01-02", "2024-03-11")
+	slots, err := availabilityService.GetAvailableSlots(ctx, "biz_sunday", "svc_sunday", sunday)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, slots)
+
+	deleted, err := availabilityService.DeleteRulesForDay(ctx, "biz_sunday", models.Sunday)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+	if assert.Len(t, publisher.PublishedEvents, 1) {
+		assert.Equal(t, events.AvailabilityRuleUpdatedEvent, publisher.PublishedEvents[0].Subject)
+	}
+
+	slots, err = availabilityService.GetAvailableSlots(ctx, "biz_sunday", "svc_sunday", sunday)
+	assert.NoError(t, err)
+	assert.Empty(t, slots)
+
+	// Other days keep their rules
+	slots, err = availabilityService.GetAvailableSlots(ctx, "biz_sunday", "svc_sunday", monday)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, slots)
+
+	// Clearing an already empty day is a no-op
+	deleted, err = availabilityService.DeleteRulesForDay(ctx, "biz_sunday", models.Sunday)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), deleted)
+	assert.Len(t, publisher.PublishedEvents, 1)
+}
+
+func (suite *AvailabilityServiceTestSuite) TestDeleteRulesForDay_InvalidDay() {
+	_, err := suite.AvailabilityService.DeleteRulesForDay(context.Background(), "biz_sunday", "FUNDAY")
+	if assert.Error(suite.T(), err) {
+		assert.Contains(suite.T(), err.Error(), "invalid dayOfWeek")
+	}
+}
+
 func (suite *AvailabilityServiceTestSuite) TestGetAvailableSlots_WithExistingBookings() {
 	t := suite.T()
 	ctx := context.Background()
@@ -293,3 +1107,125 @@ func (suite *AvailabilityServiceTestSuite) TestGetAvailableSlots_WithExistingBoo
 		assert.True(t, found0930, "09:30 slot should be available")
 	}
 }
+
+// bufferedSlotStarts returns the slot starts of a 30-minute service under a 09:00-12:00 Monday rule with
+// the given buffers, around a booking from 10:30 to 11:00.
+func (suite *AvailabilityServiceTestSuite) bufferedSlotStarts(businessID string, before, after int) []string {
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_" + businessID, BusinessID: businessID, Name: "Grooming", DurationMinutes: 30, IsActive: true})
+	suite.DB.Create(&models.AvailabilityRule{BusinessID: businessID, DayOfWeek: models.Monday, StartTime: "09:00", EndTime: "12:00", BufferBeforeMinutes: before, BufferAfterMinutes: after})
+	bookingStart, _ := time.Parse(time.RFC3339, "2024-03-04T10:30:00Z")
+	suite.DB.Create(&models.Booking{
+		BusinessID: businessID, ServiceID: "svc_" + businessID, CustomerID: "cust_buffer",
+		StartTime: bookingStart, EndTime: bookingStart.Add(30 * time.Minute), Status: models.BookingStatusConfirmed,
+	})
+
+	testDate, _ := time.Parse("2006-01-02", "2024-03-04") // Monday
+	slots, err := suite.AvailabilityService.GetAvailableSlots(context.Background(), businessID, "svc_"+businessID, testDate)
+	suite.Require().NoError(err)
+	starts := make([]string, 0, len(slots))
+	for _, slot := range slots {
+		starts = append(starts, slot.StartTime.Format("15:04"))
+	}
+	return starts
+}
+
+func (suite *AvailabilityServiceTestSuite) TestGetAvailableSlots_BufferBeforeOnly() {
+	// Setup happens after opening, so the first slot starts at 09:15; 10:00 would need setup during the booking
+	assert.Equal(suite.T(), []string{"09:15", "11:30"}, suite.bufferedSlotStarts("biz_buffer_before", 15, 0))
+}
+
+func (suite *AvailabilityServiceTestSuite) TestGetAvailableSlots_BufferAfterOnly() {
+	// 09:45 ends with cleanup exactly when the booking starts; 11:15 starts after the booking's cleanup
+	assert.Equal(suite.T(), []string{"09:00", "09:45", "11:15"}, suite.bufferedSlotStarts("biz_buffer_after", 0, 15))
+}
+
+func (suite *AvailabilityServiceTestSuite) TestGetAvailableSlots_BufferBeforeAndAfter() {
+	assert.Equal(suite.T(), []string{"09:05", "11:20"}, suite.bufferedSlotStarts("biz_buffer_both", 5, 10))
+}
+
+func (suite *AvailabilityServiceTestSuite) TestRepositoryNotFoundSentinels() {
+	t := suite.T()
+	ctx := context.Background()
+
+	serviceDef, err := suite.AvailabilityRepo.GetServiceDefinition(ctx, "svc_missing")
+	assert.Nil(t, serviceDef)
+	assert.ErrorIs(t, err, repository.ErrServiceDefinitionNotFound)
+	rule, err := suite.AvailabilityRepo.GetAvailabilityRuleByID(ctx, 999999)
+	assert.Nil(t, rule)
+	assert.ErrorIs(t, err, repository.ErrAvailabilityRuleNotFound)
+	_, err = suite.AvailabilityService.GetAvailabilityRule(ctx, 999999)
+	assert.ErrorIs(t, err, repository.ErrAvailabilityRuleNotFound, "Services pass the sentinel through")
+
+	// Failed queries are not missing records
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = suite.AvailabilityRepo.GetServiceDefinition(cancelled, "svc_missing")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, repository.ErrServiceDefinitionNotFound)
+	_, err = suite.AvailabilityRepo.GetAvailabilityRuleByID(cancelled, 999999)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, repository.ErrAvailabilityRuleNotFound)
+}
+
+func (suite *AvailabilityServiceTestSuite) TestSearchBusinessesWithAvailability_ExcludesBusinessesWithoutSlots() {
+	t := suite.T()
+	ctx := context.Background()
+	testDate, _ := time.Parse("2006-01-02", "2024-03-04") // A Monday
+	availabilityService := suite.newAvailabilityServiceAt(testDate.AddDate(0, 0, -3))
+	for _, biz := range []struct {
+		id, category string
+		hasRule      bool
+	}{
+		{"biz_mkt_a", "Massage", true},
+		{"biz_mkt_b", "massage", false}, // No hours that day
+		{"biz_mkt_c", "Massage", true},  // Fully booked below
+		{"biz_mkt_d", "Haircut", true},  // Other category
+		{"biz_mkt_e", "Massage", true},
+		{"biz_mkt_f", "Massage", true}, // Suspended below
+	} {
+		suite.DB.Create(&models.ServiceDefinition{ID: "svc_" + biz.id, BusinessID: biz.id, Name: "Service", DurationMinutes: 60, Currency: "USD", IsActive: true, Category: biz.category})
+		if biz.hasRule {
+			suite.DB.Create(&models.AvailabilityRule{BusinessID: biz.id, DayOfWeek: models.Monday, StartTime: "09:00", EndTime: "10:00"})
+		}
+	}
+	suite.DB.Create(&models.Booking{
+		BusinessID: "biz_mkt_c", ServiceID: "svc_biz_mkt_c", CustomerID: "cust_mkt",
+		StartTime: testDate.Add(9 * time.Hour), EndTime: testDate.Add(10 * time.Hour), Status: models.BookingStatusConfirmed,
+	})
+	suite.DB.Create(&models.Business{ID: "biz_mkt_e", Name: "Evening Spa", AcceptingBookings: true})
+	suite.DB.Create(&models.Business{ID: "biz_mkt_f", Name: "Closed Spa", AcceptingBookings: true, Status: models.BusinessStatusSuspended})
+
+	results, hasMore, err := availabilityService.SearchBusinessesWithAvailability(ctx, "MASSAGE", testDate, 10, 0)
+	assert.NoError(t, err)
+	assert.False(t, hasMore)
+	assert.Equal(t, []service.MarketplaceBusiness{{BusinessID: "biz_mkt_a"}, {BusinessID: "biz_mkt_e", Name: "Evening Spa"}}, results)
+
+	firstPage, hasMore, err := availabilityService.SearchBusinessesWithAvailability(ctx, "massage", testDate, 1, 0)
+	assert.NoError(t, err)
+	assert.True(t, hasMore)
+	assert.Equal(t, []service.MarketplaceBusiness{{BusinessID: "biz_mkt_a"}}, firstPage)
+	secondPage, hasMore, err := availabilityService.SearchBusinessesWithAvailability(ctx, "massage", testDate, 1, 1)
+	assert.NoError(t, err)
+	assert.False(t, hasMore)
+	assert.Equal(t, []service.MarketplaceBusiness{{BusinessID: "biz_mkt_e", Name: "Evening Spa"}}, secondPage)
+}
+
+func (suite *AvailabilityServiceTestSuite) TestCreateAvailabilityRule_BufferMinutesIsAfterAlias() {
+	t := suite.T()
+	rule, err := suite.AvailabilityService.CreateAvailabilityRule(context.Background(), service.CreateAvailabilityRuleRequest{
+		BusinessID: "biz_buffer_alias", DayOfWeek: models.Friday, StartTime: "09:00", EndTime: "12:00", BufferMinutes: 20,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 20, rule.BufferAfterMinutes)
+	assert.Equal(t, 0, rule.BufferBeforeMinutes)
+
+	var dbRule models.AvailabilityRule
+	suite.DB.First(&dbRule, rule.ID)
+	assert.Equal(t, 20, dbRule.BufferAfterMinutes)
+	assert.Equal(t, 20, dbRule.BufferMinutes, "The deprecated field still reads back as the after buffer")
+
+	_, err = suite.AvailabilityService.CreateAvailabilityRule(context.Background(), service.CreateAvailabilityRuleRequest{
+		BusinessID: "biz_buffer_alias", DayOfWeek: models.Saturday, StartTime: "09:00", EndTime: "12:00", BufferBeforeMinutes: -5,
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/slotwise/scheduling-service/internal/models"
+	"github.com/slotwise/scheduling-service/internal/repository"
+	"gorm.io/gorm"
+)
+
+// MaxBookingImportRows is the most bookings one ImportBookings call may import.
+const MaxBookingImportRows = 1000
+
+// bookingImportBatchSize is how many bookings are inserted per statement during an import.
+const bookingImportBatchSize = 100
+
+// ImportBookingRow is one booking carried over from another system.
+type ImportBookingRow struct {
+	ServiceID  string                 `json:"serviceId"`
+	CustomerID string                 `json:"customerId"`
+	Guest      *GuestContact          `json:"guest,omitempty"` // Instead of CustomerID
+	StartTime  time.Time              `json:"startTime"`
+	EndTime    *time.Time             `json:"endTime,omitempty"` // Defaults to the service's duration after StartTime
+	Status     models.BookingStatus   `json:"status,omitempty"`  // Defaults to CONFIRMED
+	Notes      *string                `json:"notes,omitempty"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// ImportBookingsRequest is a batch of bookings to import for one business.
+type ImportBookingsRequest struct {
+	BusinessID string             `json:"businessId"`
+	Rows       []ImportBookingRow `json:"rows"`
+	// SkipHistoricalConflictChecks imports bookings that have already ended even if they overlap
+	// others, since the old system's history need not follow our one-booking-at-a-time rule.
+	SkipHistoricalConflictChecks bool `json:"skipHistoricalConflictChecks"`
+}
+
+// BookingImportResult is the outcome of one row of an import.
+type BookingImportResult struct {
+	Row       int    `json:"row"` // Index of the row in the request
+	Success   bool   `json:"success"`
+	BookingID string `json:"bookingId,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ImportBookings validates each row and stores the valid ones in a single transaction, returning one result
+// per row in request order. Rows are checked for conflicts with existing bookings and with each other, keeping
+// the availability rule's buffers clear, except historical ones when SkipHistoricalConflictChecks is set. Imported bookings publish no events and send no
+// notifications, so customers are not told again about bookings they made in the old system.
+func (s *BookingService) ImportBookings(ctx context.Context, req ImportBookingsRequest) ([]BookingImportResult, error) {
+	if req.BusinessID == "" {
+		return nil, fmt.Errorf("businessID cannot be empty")
+	}
+	if len(req.Rows) == 0 {
+		return nil, fmt.Errorf("invalid import: no rows given")
+	}
+	if len(req.Rows) > MaxBookingImportRows {
+		return nil, fmt.Errorf("invalid import: at most %d rows can be imported at once", MaxBookingImportRows)
+	}
+
+	s.logger.InfoContext(ctx, "Importing bookings", "businessID", req.BusinessID, "rows", len(req.Rows))
+
+	serviceIDs := make([]string, 0, len(req.Rows))
+	for _, row := range req.Rows {
+		serviceIDs = append(serviceIDs, row.ServiceID)
+	}
+	serviceDefs, err := s.serviceDefRepo.GetServiceDefinitionsByIDs(ctx, serviceIDs)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to get service definitions for import", "businessID", req.BusinessID, "error", err)
+		return nil, fmt.Errorf("could not get service definitions: %w", err)
+	}
+	servicesByID := make(map[string]*models.ServiceDefinition, len(serviceDefs))
+	for i := range serviceDefs {
+		servicesByID[serviceDefs[i].ID] = &serviceDefs[i]
+	}
+
+	business, err := s.serviceDefRepo.GetBusinessByID(ctx, req.BusinessID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to get business for import", "businessID", req.BusinessID, "error", err)
+		return nil, fmt.Errorf("failed to retrieve business details: %w", err)
+	}
+
+	now := s.clock.Now()
+	results := make([]BookingImportResult, len(req.Rows))
+	bookings := make([]*models.Booking, len(req.Rows))
+	for i, row := range req.Rows {
+		results[i].Row = i
+		booking, err := s.buildImportedBooking(req.BusinessID, row, servicesByID[row.ServiceID], now)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		bookings[i] = booking
+	}
+
+	// Check the rows for conflicts and store them under the business lock, as CreateBooking does, so a
+	// booking or hold made meanwhile cannot take a slot an imported booking was checked against.
+	var accepted []*models.Booking
+	var acceptedRows []int
+	err = s.serviceDefRepo.WithBusinessLock(ctx, req.BusinessID, func(tx *gorm.DB) error {
+		availabilityRepo := s.serviceDefRepo.WithTx(tx)
+		bookingRepo := s.bookingRepo.WithTx(tx)
+		for i, booking := range bookings {
+			if booking == nil {
+				continue
+			}
+			historical := !booking.EndTime.After(now)
+			if occupiesSlot(booking.Status) && (!historical || !req.SkipHistoricalConflictChecks) {
+				conflict, err := s.findImportConflict(ctx, availabilityRepo, bookingRepo, business, booking, accepted)
+				if err != nil {
+					return err
+				}
+				if conflict != "" {
+					results[i].Error = conflict
+					continue
+				}
+			}
+			accepted = append(accepted, booking)
+			acceptedRows = append(acceptedRows, i)
+		}
+		if len(accepted) == 0 {
+			return nil
+		}
+		if err := bookingRepo.ImportBookings(ctx, req.BusinessID, accepted, bookingImportBatchSize); err != nil {
+			return fmt.Errorf("could not import bookings: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to store imported bookings", "businessID", req.BusinessID, "error", err)
+		return nil, err
+	}
+	for j, i := range acceptedRows {
+		results[i].Success = true
+		results[i].BookingID = accepted[j].ID
+	}
+
+	s.logger.InfoContext(ctx, "Bookings imported", "businessID", req.BusinessID, "imported", len(accepted), "failed", len(req.Rows)-len(accepted))
+	return results, nil
+}
+
+// buildImportedBooking validates one import row against its service and turns it into a booking.
+func (s *BookingService) buildImportedBooking(businessID string, row ImportBookingRow, serviceDef *models.ServiceDefinition, now time.Time) (*models.Booking, error) {
+	if serviceDef == nil || serviceDef.BusinessID != businessID {
+		return nil, fmt.Errorf("service %s not found for business %s", row.ServiceID, businessID)
+	}
+	if err := validateBookingCustomer(CreateBookingRequest{CustomerID: row.CustomerID, Guest: row.Guest}); err != nil {
+		return nil, err
+	}
+	if row.StartTime.IsZero() {
+		return nil, fmt.Errorf("invalid booking row: startTime is required")
+	}
+	endTime := row.StartTime.Add(time.Duration(serviceDef.DurationMinutes) * time.Minute)
+	if row.EndTime != nil {
+		endTime = *row.EndTime
+	}
+	if !endTime.After(row.StartTime) {
+		return nil, fmt.Errorf("invalid booking row: endTime must be after startTime")
+	}
+	if endTime.Sub(row.StartTime) > 24*time.Hour {
+		return nil, fmt.Errorf("invalid booking row: bookings cannot last more than 24 hours")
+	}
+
+	status := row.Status
+	if status == "" {
+		status = models.BookingStatusConfirmed
+	}
+	if !status.IsValid() {
+		return nil, fmt.Errorf("invalid booking row: unknown status %q", status)
+	}
+	historical := !endTime.After(now)
+	if !historical && (status == models.BookingStatusCompleted || status == models.BookingStatusNoShow) {
+		return nil, fmt.Errorf("invalid booking row: a booking that has not ended cannot be %s", status)
+	}
+	if !historical && serviceDef.DeletedAt.Valid {
+		return nil, fmt.Errorf("service %s has been deleted and cannot take new bookings", row.ServiceID)
+	}
+
+	booking := &models.Booking{
+		BusinessID:       businessID,
+		ServiceID:        row.ServiceID,
+		CustomerID:       row.CustomerID,
+		StartTime:        row.StartTime,
+		EndTime:          endTime,
+		Status:           status,
+		Notes:            row.Notes,
+		TotalAmount:      &serviceDef.Price,
+		Currency:         serviceDef.Currency,
+		Metadata:         row.Metadata,
+		CustomerLanguage: models.DefaultLanguage,
+	}
+	if row.Guest != nil {
+		name, email := strings.TrimSpace(row.Guest.Name), strings.TrimSpace(row.Guest.Email)
+		booking.GuestName, booking.GuestEmail = &name, &email
+		if phone := strings.TrimSpace(row.Guest.Phone); phone != "" {
+			booking.GuestPhone = &phone
+		}
+	}
+	return booking, nil
+}
+
+// findImportConflict describes what booking conflicts with, either an active booking of its business that is
+// stored or one accepted earlier in the same import, keeping the rule's buffers clear around it as CreateBooking
+// does. It returns "" when there is no conflict, and an error only when the check itself fails. It reads through
+// the given repositories so ImportBookings can run it in the business lock's transaction.
+func (s *BookingService) findImportConflict(ctx context.Context, availabilityRepo *repository.AvailabilityRepository, bookingRepo *repository.BookingRepository, business *models.Business, booking *models.Booking, accepted []*models.Booking) (string, error) {
+	padding, err := bufferPaddingAt(ctx, availabilityRepo, business, booking.BusinessID, booking.StartTime)
+	if err != nil {
+		return "", fmt.Errorf("error checking for booking conflicts: %w", err)
+	}
+	start, end := booking.StartTime.Add(-padding), booking.EndTime.Add(padding)
+	for _, other := range accepted {
+		if occupiesSlot(other.Status) && other.StartTime.Before(end) && other.EndTime.After(start) {
+			return fmt.Sprintf("booking conflicts with another imported booking from %s to %s", other.StartTime.UTC().Format(time.RFC3339), other.EndTime.UTC().Format(time.RFC3339)), nil
+		}
+	}
+	conflicts, err := bookingRepo.FindConflictingBookings(ctx, booking.BusinessID, booking.ServiceID, start, end, "")
+	if err != nil {
+		return "", fmt.Errorf("error checking for booking conflicts: %w", err)
+	}
+	if len(conflicts) > 0 {
+		return fmt.Sprintf("booking conflicts with existing booking %s", conflicts[0].ID), nil
+	}
+	return "", nil
+}
+
+// occupiesSlot reports whether a booking in status takes its time slot.
+func occupiesSlot(status models.BookingStatus) bool {
+	return status == models.BookingStatusConfirmed || status == models.BookingStatusPendingPayment
+}
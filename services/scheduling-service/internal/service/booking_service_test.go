@@ -2,7 +2,10 @@ package service_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
@@ -10,6 +13,7 @@ import (
 	"github.com/slotwise/scheduling-service/internal/models"
 	"github.com/slotwise/scheduling-service/internal/repository"
 	"github.com/slotwise/scheduling-service/internal/service"
+	"github.com/slotwise/scheduling-service/pkg/clock"
 	"github.com/slotwise/scheduling-service/pkg/events"
 	"github.com/slotwise/scheduling-service/pkg/logger"
 	"github.com/stretchr/testify/assert"
@@ -40,10 +44,32 @@ func (m *MockEventPublisher) Reset() {
 	m.PublishedEvents = nil
 }
 
+// FailingEventPublisher simulates NATS being unavailable
+type FailingEventPublisher struct{}
+
+func (f *FailingEventPublisher) Publish(subject string, data interface{}) error {
+	return errors.New("nats: connection closed")
+}
+
+// SubjectFailingEventPublisher fails publishes for one subject and records the rest
+type SubjectFailingEventPublisher struct {
+	MockEventPublisher
+	FailSubject string
+}
+
+func (p *SubjectFailingEventPublisher) Publish(subject string, data interface{}) error {
+	if subject == p.FailSubject {
+		return errors.New("nats: timeout")
+	}
+	return p.MockEventPublisher.Publish(subject, data)
+}
+
 // MockNotificationClient for BookingService tests
 type MockNotificationClient struct {
 	SentNotifications      []client.SendNotificationRequest
 	ScheduledNotifications []client.ScheduleNotificationRequest
+	CancelledBookingIDs    []string // Bookings whose scheduled notifications were cancelled
+	BatchSizes             []int    // Number of notifications in each SendBatch call
 }
 
 func (m *MockNotificationClient) SendNotification(req client.SendNotificationRequest) (*client.NotificationResponse, error) {
@@ -51,14 +77,31 @@ func (m *MockNotificationClient) SendNotification(req client.SendNotificationReq
 	return &client.NotificationResponse{Success: true}, nil
 }
 
+func (m *MockNotificationClient) SendBatch(reqs []client.SendNotificationRequest) ([]client.BatchSendResult, error) {
+	m.BatchSizes = append(m.BatchSizes, len(reqs))
+	results := make([]client.BatchSendResult, len(reqs))
+	for i, req := range reqs {
+		m.SentNotifications = append(m.SentNotifications, req)
+		results[i] = client.BatchSendResult{Index: i, Success: true}
+	}
+	return results, nil
+}
+
 func (m *MockNotificationClient) ScheduleNotification(req client.ScheduleNotificationRequest) (*client.NotificationResponse, error) {
 	m.ScheduledNotifications = append(m.ScheduledNotifications, req)
 	return &client.NotificationResponse{Success: true}, nil
 }
 
+func (m *MockNotificationClient) CancelScheduledNotifications(bookingID string) (int, error) {
+	m.CancelledBookingIDs = append(m.CancelledBookingIDs, bookingID)
+	return 0, nil
+}
+
 func (m *MockNotificationClient) Reset() {
 	m.SentNotifications = nil
 	m.ScheduledNotifications = nil
+	m.CancelledBookingIDs = nil
+	m.BatchSizes = nil
 }
 
 type BookingServiceTestSuite struct {
@@ -67,8 +110,11 @@ type BookingServiceTestSuite struct {
 	BookingService    *service.BookingService
 	BookingRepo       *repository.BookingRepository
 	AvailabilityRepo  *repository.AvailabilityRepository // For service definitions
+	OutboxRepo        *repository.OutboxRepository
+	OutboxRelay       *service.OutboxRelay
 	TestLogger        *logger.Logger
 	MockNatsPublisher *MockEventPublisher
+	MockNotifier      *MockNotificationClient
 }
 
 func (suite *BookingServiceTestSuite) SetupSuite() {
@@ -87,25 +133,30 @@ func (suite *BookingServiceTestSuite) SetupSuite() {
 	}
 	suite.DB = db
 
-	err = suite.DB.AutoMigrate(&models.ServiceDefinition{}, &models.AvailabilityRule{}, &models.Booking{})
+	err = suite.DB.AutoMigrate(&models.Business{}, &models.ServiceDefinition{}, &models.AvailabilityRule{}, &models.AvailabilityVersion{}, &models.Booking{}, &models.OutboxEvent{}, &models.BookingStatusHistory{}, &models.CustomerPreference{})
 	assert.NoError(suite.T(), err)
 
 	suite.BookingRepo = repository.NewBookingRepository(suite.DB)
 	suite.AvailabilityRepo = repository.NewAvailabilityRepository(suite.DB)
+	suite.OutboxRepo = repository.NewOutboxRepository(suite.DB)
 	suite.MockNatsPublisher = NewMockEventPublisher()
+	suite.OutboxRelay = service.NewOutboxRelay(suite.OutboxRepo, suite.MockNatsPublisher, suite.TestLogger)
 
 	// Initialize AvailabilityService (mocked or minimal if not directly used by BookingService's core logic being tested)
 	// For CreateBooking, BookingService needs to fetch ServiceDefinition, so AvailabilityRepo is used as serviceDefRepo.
 	// An actual AvailabilityService instance isn't strictly needed if we directly use AvailabilityRepo for setup.
 	// Create a mock notification client
-	mockNotificationClient := &MockNotificationClient{}
+	suite.MockNotifier = &MockNotificationClient{}
 
 	suite.BookingService = service.NewBookingService(
 		suite.BookingRepo,
 		nil,                    // No direct call to AvailabilityService methods in BookingService yet
 		suite.AvailabilityRepo, // Passed as the serviceDefRepo
+		repository.NewCustomerPreferenceRepository(suite.DB),
+		suite.OutboxRelay,
 		suite.MockNatsPublisher,
-		mockNotificationClient, // Add the missing notification client parameter
+		suite.MockNotifier, // Add the missing notification client parameter
+		nil,
 		suite.TestLogger,
 	)
 }
@@ -117,9 +168,23 @@ func (suite *BookingServiceTestSuite) TearDownSuite() {
 
 func (suite *BookingServiceTestSuite) SetupTest() {
 	suite.MockNatsPublisher.Reset()
+	suite.MockNotifier.Reset()
 	suite.DB.Exec("DELETE FROM bookings")
 	suite.DB.Exec("DELETE FROM service_definitions")
-	// No need to delete availability_rules for these specific tests yet
+	suite.DB.Exec("DELETE FROM outbox_events")
+	suite.DB.Exec("DELETE FROM booking_status_history")
+	suite.DB.Exec("DELETE FROM customer_preferences")
+	suite.DB.Exec("DELETE FROM businesses")
+	suite.DB.Exec("DELETE FROM availability_rules")
+	suite.DB.Exec("DELETE FROM availability_versions")
+}
+
+// openAllWeek gives businessID an 08:00-20:00 availability rule on every day, so bookings in the daytime
+// are within its hours.
+func (suite *BookingServiceTestSuite) openAllWeek(businessID string) {
+	for _, day := range []models.DayOfWeekString{models.Monday, models.Tuesday, models.Wednesday, models.Thursday, models.Friday, models.Saturday, models.Sunday} {
+		suite.DB.Create(&models.AvailabilityRule{BusinessID: businessID, DayOfWeek: day, StartTime: "08:00", EndTime: "20:00"})
+	}
 }
 
 // --- CreateBooking Tests ---
@@ -130,8 +195,9 @@ func (suite *BookingServiceTestSuite) TestCreateBooking_Success_NoConflict() {
 	// Seed Service Definition
 	svcDef := models.ServiceDefinition{ID: "svc1", BusinessID: "biz1", Name: "Service 1", DurationMinutes: 60, IsActive: true}
 	suite.DB.Create(&svcDef)
+	suite.openAllWeek("biz1")
 
-	startTime, _ := time.Parse(time.RFC3339, "2024-04-01T10:00:00Z")
+	startTime, _ := time.Parse(time.RFC3339, "2030-04-01T10:00:00Z")
 	req := service.CreateBookingRequest{
 		BusinessID: "biz1", ServiceID: "svc1", CustomerID: "cust1", StartTime: startTime,
 	}
@@ -157,15 +223,428 @@ func (suite *BookingServiceTestSuite) TestCreateBooking_Success_NoConflict() {
 	assert.Equal(t, booking.ID, eventData["bookingId"])
 }
 
+func (suite *BookingServiceTestSuite) TestCreateBooking_DryRunValidatesWithoutPersisting() {
+	t := suite.T()
+	ctx := context.Background()
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_dry", BusinessID: "biz_dry", Name: "Consultation", DurationMinutes: 45, Price: 6000, Currency: "EUR", IsActive: true})
+	suite.openAllWeek("biz_dry")
+
+	startTime, _ := time.Parse(time.RFC3339, "2030-04-01T10:00:00Z")
+	req := service.CreateBookingRequest{BusinessID: "biz_dry", ServiceID: "svc_dry", CustomerID: "cust_dry", StartTime: startTime, DryRun: true}
+
+	booking, err := suite.BookingService.CreateBooking(ctx, req)
+	assert.NoError(t, err)
+	if assert.NotNil(t, booking) {
+		assert.Empty(t, booking.ID)
+		assert.Equal(t, startTime.Add(45*time.Minute), booking.EndTime)
+		if assert.NotNil(t, booking.TotalAmount) {
+			assert.Equal(t, int64(6000), *booking.TotalAmount)
+		}
+		assert.Equal(t, "EUR", booking.Currency)
+	}
+
+	var bookingCount, outboxCount int64
+	suite.DB.Model(&models.Booking{}).Count(&bookingCount)
+	suite.DB.Model(&models.OutboxEvent{}).Count(&outboxCount)
+	assert.Zero(t, bookingCount)
+	assert.Zero(t, outboxCount)
+	assert.Empty(t, suite.MockNatsPublisher.PublishedEvents)
+
+	// Checks still run: a dry run against a taken slot reports the conflict
+	req.DryRun = false
+	_, err = suite.BookingService.CreateBooking(ctx, req)
+	assert.NoError(t, err)
+	req.DryRun = true
+	_, err = suite.BookingService.CreateBooking(ctx, req)
+	var conflictErr *service.BookingConflictError
+	assert.ErrorAs(t, err, &conflictErr)
+}
+
+func (suite *BookingServiceTestSuite) TestCreateBookingWithinQuota_ConcurrentBookingsStayWithinQuota() {
+	t := suite.T()
+	ctx := context.Background()
+	day := time.Date(2024, 4, 2, 0, 0, 0, 0, time.UTC)
+	quota := repository.BookingQuota{From: day, To: day.Add(24 * time.Hour), MaxPerBusiness: 3}
+	noMessages := func(*models.Booking) []repository.OutboxMessage { return nil }
+
+	// Every booking is at a different time, so only the quota can turn them away
+	const attempts = 10
+	var wg sync.WaitGroup
+	errs := make([]error, attempts)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			start := day.Add(time.Duration(8+i) * time.Hour)
+			booking := &models.Booking{
+				BusinessID: "biz_quota", ServiceID: "svc_quota", CustomerID: fmt.Sprintf("cust_quota_%d", i),
+				StartTime: start, EndTime: start.Add(30 * time.Minute), Status: models.BookingStatusConfirmed,
+			}
+			_, errs[i] = suite.BookingRepo.CreateBookingWithinQuota(ctx, booking, quota, noMessages)
+		}(i)
+	}
+	wg.Wait()
+
+	created := 0
+	for _, err := range errs {
+		if err == nil {
+			created++
+		} else {
+			assert.ErrorIs(t, err, repository.ErrBookingQuotaExceeded)
+		}
+	}
+	assert.Equal(t, 3, created)
+
+	count, err := repository.CountActiveBusinessBookings(suite.DB, "biz_quota", quota.From, quota.To)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+}
+
+func (suite *BookingServiceTestSuite) TestCreateBookingWithinQuota_PerCustomer() {
+	t := suite.T()
+	ctx := context.Background()
+	day := time.Date(2024, 4, 2, 0, 0, 0, 0, time.UTC)
+	quota := repository.BookingQuota{From: day, To: day.Add(24 * time.Hour), MaxPerCustomer: 1}
+	noMessages := func(*models.Booking) []repository.OutboxMessage { return nil }
+	newBooking := func(customerID string, hour int, status models.BookingStatus) *models.Booking {
+		start := day.Add(time.Duration(hour) * time.Hour)
+		return &models.Booking{BusinessID: "biz_cust_quota", ServiceID: "svc1", CustomerID: customerID, StartTime: start, EndTime: start.Add(time.Hour), Status: status}
+	}
+
+	// Cancelled bookings don't count
+	suite.DB.Create(newBooking("cust_a", 8, models.BookingStatusCancelled))
+
+	_, err := suite.BookingRepo.CreateBookingWithinQuota(ctx, newBooking("cust_a", 9, models.BookingStatusConfirmed), quota, noMessages)
+	assert.NoError(t, err)
+	_, err = suite.BookingRepo.CreateBookingWithinQuota(ctx, newBooking("cust_a", 10, models.BookingStatusPendingPayment), quota, noMessages)
+	assert.ErrorIs(t, err, repository.ErrBookingQuotaExceeded)
+	_, err = suite.BookingRepo.CreateBookingWithinQuota(ctx, newBooking("cust_b", 10, models.BookingStatusConfirmed), quota, noMessages)
+	assert.NoError(t, err)
+}
+
+func (suite *BookingServiceTestSuite) TestCreateBooking_RequestedEventIncludesServiceDetails() {
+	t := suite.T()
+	svcDef := models.ServiceDefinition{ID: "svc_details", BusinessID: "biz_details", Name: "Deep Tissue Massage", DurationMinutes: 45, Price: 7500, Currency: "EUR", IsActive: true}
+	suite.DB.Create(&svcDef)
+	suite.openAllWeek("biz_details")
+
+	startTime, _ := time.Parse(time.RFC3339, "2030-04-01T10:00:00Z")
+	_, err := suite.BookingService.CreateBooking(context.Background(), service.CreateBookingRequest{
+		BusinessID: "biz_details", ServiceID: "svc_details", CustomerID: "cust_details", StartTime: startTime,
+	})
+	assert.NoError(t, err)
+
+	if assert.Len(t, suite.MockNatsPublisher.PublishedEvents, 1) {
+		event := suite.MockNatsPublisher.PublishedEvents[0]
+		assert.Equal(t, events.BookingRequestedEvent, event.Subject)
+		eventData, ok := event.Data.(map[string]interface{})
+		assert.True(t, ok)
+		// The payload round-trips through the outbox as JSON, so numbers arrive as float64
+		assert.Equal(t, "Deep Tissue Massage", eventData["serviceName"])
+		assert.Equal(t, float64(45), eventData["durationMinutes"])
+		assert.Equal(t, float64(7500), eventData["price"])
+		assert.Equal(t, "EUR", eventData["currency"])
+	}
+}
+
+func (suite *BookingServiceTestSuite) TestCreateBooking_GuestStoresContactAndConfirms() {
+	t := suite.T()
+	ctx := context.Background()
+	noPayment := false
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_guest", BusinessID: "biz_guest", Name: "Haircut", DurationMinutes: 30, IsActive: true, RequiresPayment: &noPayment})
+	suite.openAllWeek("biz_guest")
+
+	startTime := time.Date(2030, 4, 3, 10, 0, 0, 0, time.UTC)
+	booking, err := suite.BookingService.CreateBooking(ctx, service.CreateBookingRequest{
+		BusinessID: "biz_guest", ServiceID: "svc_guest", StartTime: startTime,
+		Guest: &service.GuestContact{Name: " Jane Roe ", Email: "Jane.Roe@Example.com", Phone: "+15551234567"},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, models.BookingStatusConfirmed, booking.Status)
+
+	var stored models.Booking
+	assert.NoError(t, suite.DB.First(&stored, "id = ?", booking.ID).Error)
+	assert.Empty(t, stored.CustomerID)
+	if assert.True(t, stored.IsGuest()) {
+		assert.Equal(t, "Jane Roe", *stored.GuestName)
+		assert.Equal(t, "jane.roe@example.com", *stored.GuestEmail)
+		assert.Equal(t, "+15551234567", *stored.GuestPhone)
+	}
+
+	// The confirmation event carries the guest's contact details for the notification service
+	var confirmed map[string]interface{}
+	for _, event := range suite.MockNatsPublisher.PublishedEvents {
+		if event.Subject == events.BookingConfirmedEvent {
+			confirmed, _ = event.Data.(map[string]interface{})
+		}
+	}
+	if assert.NotNil(t, confirmed) {
+		assert.Equal(t, map[string]interface{}{"name": "Jane Roe", "email": "jane.roe@example.com", "phone": "+15551234567"}, confirmed["customer"])
+	}
+}
+
+func (suite *BookingServiceTestSuite) TestCreateBooking_GroupClassSharesSlotUntilFull() {
+	t := suite.T()
+	ctx := context.Background()
+	noPayment := false
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_group", BusinessID: "biz_group", Name: "Spin Class", DurationMinutes: 45, IsActive: true, RequiresPayment: &noPayment, Capacity: 3})
+	suite.openAllWeek("biz_group")
+
+	startTime := time.Date(2030, 4, 3, 10, 0, 0, 0, time.UTC)
+	book := func(customerID string) (*models.Booking, error) {
+		return suite.BookingService.CreateBooking(ctx, service.CreateBookingRequest{
+			BusinessID: "biz_group", ServiceID: "svc_group", CustomerID: customerID, StartTime: startTime,
+		})
+	}
+	lastConfirmed := func() map[string]interface{} {
+		var confirmed map[string]interface{}
+		for _, event := range suite.MockNatsPublisher.PublishedEvents {
+			if event.Subject == events.BookingConfirmedEvent {
+				confirmed, _ = event.Data.(map[string]interface{})
+			}
+		}
+		return confirmed
+	}
+
+	// Each booking takes a place, and its confirmation carries the places left for realtime clients
+	for i, customerID := range []string{"cust_group_1", "cust_group_2", "cust_group_3"} {
+		_, err := book(customerID)
+		if !assert.NoError(t, err) {
+			return
+		}
+		if confirmed := lastConfirmed(); assert.NotNil(t, confirmed) {
+			assert.EqualValues(t, 3, confirmed["capacity"])
+			assert.EqualValues(t, 2-i, confirmed["remainingCapacity"])
+		}
+	}
+
+	_, err := book("cust_group_4")
+	var unavailable *service.SlotUnavailableError
+	if assert.ErrorAs(t, err, &unavailable) {
+		assert.Equal(t, service.SlotUnavailableCapacityFull, unavailable.Reason)
+	}
+
+	// Overlapping the class at a different time is still a conflict
+	_, err = suite.BookingService.CreateBooking(ctx, service.CreateBookingRequest{
+		BusinessID: "biz_group", ServiceID: "svc_group", CustomerID: "cust_group_5", StartTime: startTime.Add(15 * time.Minute),
+	})
+	var conflict *service.BookingConflictError
+	assert.ErrorAs(t, err, &conflict)
+}
+
+// seedReschedulableBooking creates a business open 09:00-17:00 on Mondays and a confirmed 10:00-11:00
+// booking for cust_resched on Monday 2030-01-07.
+func (suite *BookingServiceTestSuite) seedReschedulableBooking() *models.Booking {
+	suite.DB.Create(&models.Business{ID: "biz_resched", Name: "Reschedule Shop", Status: "ACTIVE", AcceptingBookings: true})
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_resched", BusinessID: "biz_resched", Name: "Massage", DurationMinutes: 60, IsActive: true})
+	suite.DB.Create(&models.AvailabilityRule{BusinessID: "biz_resched", DayOfWeek: models.Monday, StartTime: "09:00", EndTime: "17:00"})
+	start := time.Date(2030, 1, 7, 10, 0, 0, 0, time.UTC)
+	booking := &models.Booking{
+		BusinessID: "biz_resched", ServiceID: "svc_resched", CustomerID: "cust_resched",
+		StartTime: start, EndTime: start.Add(time.Hour), Status: models.BookingStatusConfirmed,
+	}
+	suite.DB.Create(booking)
+	return booking
+}
+
+func (suite *BookingServiceTestSuite) TestRescheduleBooking_OutsideAvailabilityRejected() {
+	t := suite.T()
+	ctx := context.Background()
+	booking := suite.seedReschedulableBooking()
+	noManager := func(string) bool { return false }
+
+	// Nothing is booked in the evening, but the business is closed then
+	_, err := suite.BookingService.RescheduleBooking(ctx, booking.ID, time.Date(2030, 1, 7, 18, 0, 0, 0, time.UTC), "cust_resched", noManager)
+	var unavailable *service.SlotUnavailableError
+	if assert.ErrorAs(t, err, &unavailable) {
+		assert.Equal(t, service.SlotUnavailableOutsideHours, unavailable.Reason)
+	}
+	// Running past closing time is outside the rule as well
+	_, err = suite.BookingService.RescheduleBooking(ctx, booking.ID, time.Date(2030, 1, 7, 16, 30, 0, 0, time.UTC), "cust_resched", noManager)
+	assert.ErrorAs(t, err, &unavailable)
+
+	var stored models.Booking
+	suite.DB.First(&stored, "id = ?", booking.ID)
+	assert.True(t, booking.StartTime.Equal(stored.StartTime), "A rejected reschedule leaves the booking where it was")
+	assert.Empty(t, suite.MockNatsPublisher.PublishedEvents)
+}
+
+func (suite *BookingServiceTestSuite) TestRescheduleBooking_MovesBooking() {
+	t := suite.T()
+	ctx := context.Background()
+	booking := suite.seedReschedulableBooking()
+	newStart := time.Date(2030, 1, 7, 14, 0, 0, 0, time.UTC)
+
+	_, err := suite.BookingService.RescheduleBooking(ctx, booking.ID, newStart, "cust_intruder", func(string) bool { return false })
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "does not belong")
+	}
+
+	moved, err := suite.BookingService.RescheduleBooking(ctx, booking.ID, newStart, "cust_resched", func(string) bool { return false })
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, newStart.Equal(moved.StartTime))
+	assert.True(t, newStart.Add(time.Hour).Equal(moved.EndTime), "The booking keeps its duration")
+
+	var stored models.Booking
+	suite.DB.First(&stored, "id = ?", booking.ID)
+	assert.True(t, newStart.Equal(stored.StartTime))
+	if assert.Len(t, suite.MockNatsPublisher.PublishedEvents, 1) {
+		assert.Equal(t, events.BookingRescheduledEvent, suite.MockNatsPublisher.PublishedEvents[0].Subject)
+	}
+}
+
+func (suite *BookingServiceTestSuite) TestRescheduleBooking_MovesReminder() {
+	t := suite.T()
+	ctx := context.Background()
+	booking := suite.seedReschedulableBooking()
+	newStart := time.Date(2030, 1, 7, 14, 0, 0, 0, time.UTC)
+
+	_, err := suite.BookingService.RescheduleBooking(ctx, booking.ID, newStart, "cust_resched", func(string) bool { return false })
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []string{booking.ID}, suite.MockNotifier.CancelledBookingIDs, "The reminder for the old time is cancelled")
+	if assert.Len(t, suite.MockNotifier.ScheduledNotifications, 1) {
+		reminder := suite.MockNotifier.ScheduledNotifications[0]
+		assert.Equal(t, "booking_reminder", reminder.Type)
+		assert.True(t, newStart.Add(-24*time.Hour).Equal(reminder.ScheduledFor))
+	}
+}
+
+func (suite *BookingServiceTestSuite) TestRescheduleBooking_RespectsDailyQuotaOfNewDay() {
+	t := suite.T()
+	ctx := context.Background()
+	booking := suite.seedReschedulableBooking()
+	maxPerDay := 1
+	suite.DB.Model(&models.Business{}).Where("id = ?", "biz_resched").Update("max_bookings_per_day", maxPerDay)
+	suite.DB.Create(&models.AvailabilityRule{BusinessID: "biz_resched", DayOfWeek: models.Tuesday, StartTime: "09:00", EndTime: "17:00"})
+	// Tuesday already has its one booking
+	tuesday := time.Date(2030, 1, 8, 10, 0, 0, 0, time.UTC)
+	suite.DB.Create(&models.Booking{
+		BusinessID: "biz_resched", ServiceID: "svc_resched", CustomerID: "cust_other",
+		StartTime: tuesday, EndTime: tuesday.Add(time.Hour), Status: models.BookingStatusConfirmed,
+	})
+	customerOnly := func(string) bool { return false }
+
+	_, err := suite.BookingService.RescheduleBooking(ctx, booking.ID, tuesday.Add(3*time.Hour), "cust_resched", customerOnly)
+	var unavailable *service.SlotUnavailableError
+	if assert.ErrorAs(t, err, &unavailable) {
+		assert.Equal(t, service.SlotUnavailableQuotaReached, unavailable.Reason)
+	}
+
+	// Moving within Monday, where the booking is already counted, is fine
+	_, err = suite.BookingService.RescheduleBooking(ctx, booking.ID, booking.StartTime.Add(3*time.Hour), "cust_resched", customerOnly)
+	assert.NoError(t, err)
+}
+
+func (suite *BookingServiceTestSuite) TestRescheduleBooking_CancelledBookingNotMoved() {
+	t := suite.T()
+	ctx := context.Background()
+	booking := suite.seedReschedulableBooking()
+	suite.DB.Model(&models.Booking{}).Where("id = ?", booking.ID).Update("status", models.BookingStatusCancelled)
+
+	_, err := suite.BookingService.RescheduleBooking(ctx, booking.ID, booking.StartTime.Add(3*time.Hour), "cust_resched", func(string) bool { return false })
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "cannot be rescheduled")
+	}
+
+	// The repository refuses too, for a cancellation that lands between the check and the update
+	_, err = suite.BookingRepo.RescheduleBooking(ctx, booking.ID, booking.StartTime.Add(3*time.Hour), booking.EndTime.Add(3*time.Hour), nil)
+	assert.ErrorIs(t, err, repository.ErrBookingNotActive)
+
+	var stored models.Booking
+	suite.DB.First(&stored, "id = ?", booking.ID)
+	assert.True(t, booking.StartTime.Equal(stored.StartTime))
+}
+
+func (suite *BookingServiceTestSuite) TestRescheduleBooking_NoBackToBackIgnoresOwnSlot() {
+	t := suite.T()
+	ctx := context.Background()
+	booking := suite.seedReschedulableBooking()
+	suite.DB.Model(&models.Business{}).Where("id = ?", "biz_resched").Update("allow_back_to_back", false)
+	customerOnly := func(string) bool { return false }
+
+	// Moving 10 minutes later overlaps the booking's own range, which must not count against it
+	moved, err := suite.BookingService.RescheduleBooking(ctx, booking.ID, booking.StartTime.Add(10*time.Minute), "cust_resched", customerOnly)
+	if !assert.NoError(t, err) {
+		return
+	}
+	// Starting exactly when its current slot ends would be back to back with itself
+	_, err = suite.BookingService.RescheduleBooking(ctx, booking.ID, moved.EndTime, "cust_resched", customerOnly)
+	assert.NoError(t, err)
+
+	// Another booking still may not be back to back with it
+	var stored models.Booking
+	suite.DB.First(&stored, "id = ?", booking.ID)
+	_, err = suite.BookingService.CreateBooking(ctx, service.CreateBookingRequest{
+		BusinessID: "biz_resched", ServiceID: "svc_resched", CustomerID: "cust_other", StartTime: stored.EndTime,
+	})
+	var conflict *service.BookingConflictError
+	assert.ErrorAs(t, err, &conflict)
+}
+
+func (suite *BookingServiceTestSuite) TestUpdateBookingStatus_GuestNotificationsGoToGuestEmail() {
+	t := suite.T()
+	ctx := context.Background()
+	guestName, guestEmail := "Sam Guest", "sam@example.com"
+	startTime := time.Now().Add(48 * time.Hour)
+	booking := models.Booking{
+		ID: "550e8400-e29b-41d4-a716-446655440a01", BusinessID: "biz_guest", ServiceID: "svc_guest",
+		StartTime: startTime, EndTime: startTime.Add(30 * time.Minute), Status: models.BookingStatusPendingPayment,
+		GuestName: &guestName, GuestEmail: &guestEmail,
+	}
+	suite.DB.Create(&booking)
+
+	_, err := suite.BookingService.UpdateBookingStatus(ctx, booking.ID, service.UpdateBookingStatusRequest{Status: models.BookingStatusConfirmed})
+	assert.NoError(t, err)
+
+	if assert.NotEmpty(t, suite.MockNotifier.SentNotifications) {
+		confirmation := suite.MockNotifier.SentNotifications[0]
+		assert.Equal(t, "booking_confirmation", confirmation.Type)
+		assert.Equal(t, guestEmail, confirmation.RecipientEmail)
+		assert.Equal(t, guestName, confirmation.TemplateData["userName"])
+	}
+	if assert.Len(t, suite.MockNotifier.ScheduledNotifications, 1) {
+		reminder := suite.MockNotifier.ScheduledNotifications[0]
+		assert.Equal(t, guestEmail, reminder.RecipientEmail)
+		assert.Equal(t, client.ScheduleIdempotencyKey(booking.ID, "booking_reminder", reminder.ScheduledFor), reminder.IdempotencyKey)
+	}
+}
+
+func (suite *BookingServiceTestSuite) TestCreateBooking_RequiresCustomerOrGuest() {
+	t := suite.T()
+	ctx := context.Background()
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_who", BusinessID: "biz_who", Name: "Service", DurationMinutes: 30, IsActive: true})
+	startTime := time.Now().Add(24 * time.Hour)
+
+	for _, req := range []service.CreateBookingRequest{
+		{BusinessID: "biz_who", ServiceID: "svc_who", StartTime: startTime},
+		{BusinessID: "biz_who", ServiceID: "svc_who", StartTime: startTime, CustomerID: "cust_who", Guest: &service.GuestContact{Name: "Both", Email: "both@example.com"}},
+		{BusinessID: "biz_who", ServiceID: "svc_who", StartTime: startTime, Guest: &service.GuestContact{Name: "No Email"}},
+	} {
+		booking, err := suite.BookingService.CreateBooking(ctx, req)
+		assert.Nil(t, booking)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "invalid booking request")
+		}
+	}
+}
+
 func (suite *BookingServiceTestSuite) TestCreateBooking_Conflict() {
 	t := suite.T()
 	ctx := context.Background()
 
 	svcDef := models.ServiceDefinition{ID: "svc2", BusinessID: "biz2", Name: "Service 2", DurationMinutes: 60, IsActive: true}
 	suite.DB.Create(&svcDef)
+	suite.openAllWeek("biz2")
 
 	// Seed an existing confirmed booking
-	existingStartTime, _ := time.Parse(time.RFC3339, "2024-04-01T11:00:00Z")
+	existingStartTime, _ := time.Parse(time.RFC3339, "2030-04-01T11:00:00Z")
 	existingBooking := models.Booking{
 		ID: "550e8400-e29b-41d4-a716-446655440001", BusinessID: "biz2", ServiceID: "svc2", CustomerID: "cust_exist",
 		StartTime: existingStartTime, EndTime: existingStartTime.Add(60 * time.Minute), Status: models.BookingStatusConfirmed,
@@ -185,122 +664,1655 @@ func (suite *BookingServiceTestSuite) TestCreateBooking_Conflict() {
 	assert.Len(t, suite.MockNatsPublisher.PublishedEvents, 0) // No event on failure
 }
 
-func (suite *BookingServiceTestSuite) TestCreateBooking_BackToBack_NoConflict() {
+func (suite *BookingServiceTestSuite) TestCreateBooking_PausedBusinessRejected() {
 	t := suite.T()
 	ctx := context.Background()
-	svcDef := models.ServiceDefinition{ID: "svc3", BusinessID: "biz3", Name: "Service 3", DurationMinutes: 30, IsActive: true}
-	suite.DB.Create(&svcDef)
 
-	existingStartTime, _ := time.Parse(time.RFC3339, "2024-04-01T14:00:00Z")
-	existingBooking := models.Booking{
-		ID: "550e8400-e29b-41d4-a716-446655440003", BusinessID: "biz3", ServiceID: "svc3", CustomerID: "cust_b2b_1",
-		StartTime: existingStartTime, EndTime: existingStartTime.Add(30 * time.Minute), Status: models.BookingStatusConfirmed,
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_pause", BusinessID: "biz_pause", Name: "Paused Service", DurationMinutes: 60, IsActive: true})
+	suite.openAllWeek("biz_pause")
+	existingStart, _ := time.Parse(time.RFC3339, "2030-04-01T09:00:00Z")
+	existing := models.Booking{
+		BusinessID: "biz_pause", ServiceID: "svc_pause", CustomerID: "cust_before",
+		StartTime: existingStart, EndTime: existingStart.Add(time.Hour), Status: models.BookingStatusConfirmed,
 	}
-	suite.DB.Create(&existingBooking)
+	suite.DB.Create(&existing)
+	_, err := suite.AvailabilityRepo.SetBusinessAcceptingBookings(ctx, "biz_pause", false)
+	assert.NoError(t, err)
 
-	// New booking starts exactly when the previous one ends
-	newStartTime := existingBooking.EndTime
-	req := service.CreateBookingRequest{
-		BusinessID: "biz3", ServiceID: "svc3", CustomerID: "cust_b2b_2", StartTime: newStartTime,
+	startTime, _ := time.Parse(time.RFC3339, "2030-04-01T11:00:00Z")
+	booking, err := suite.BookingService.CreateBooking(ctx, service.CreateBookingRequest{
+		BusinessID: "biz_pause", ServiceID: "svc_pause", CustomerID: "cust_after", StartTime: startTime,
+	})
+	assert.Nil(t, booking)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "business biz_pause is not accepting new bookings")
+		var unavailableErr *service.SlotUnavailableError
+		if assert.ErrorAs(t, err, &unavailableErr) {
+			assert.Equal(t, service.SlotUnavailableBusinessPaused, unavailableErr.Reason)
+		}
 	}
-	booking, err := suite.BookingService.CreateBooking(ctx, req)
+	assert.Empty(t, suite.MockNatsPublisher.PublishedEvents)
+
+	// Existing bookings are untouched
+	bookings, total, err := suite.BookingService.ListBookingsForBusiness(ctx, "biz_pause", nil, "", 10, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	if assert.Len(t, bookings, 1) {
+		assert.Equal(t, existing.ID, bookings[0].ID)
+		assert.Equal(t, models.BookingStatusConfirmed, bookings[0].Status)
+	}
+
+	// Resuming allows new bookings again
+	_, err = suite.AvailabilityRepo.SetBusinessAcceptingBookings(ctx, "biz_pause", true)
+	assert.NoError(t, err)
+	booking, err = suite.BookingService.CreateBooking(ctx, service.CreateBookingRequest{
+		BusinessID: "biz_pause", ServiceID: "svc_pause", CustomerID: "cust_after", StartTime: startTime,
+	})
 	assert.NoError(t, err)
 	assert.NotNil(t, booking)
-	assert.Equal(t, models.BookingStatusPendingPayment, booking.Status)
-	assert.Len(t, suite.MockNatsPublisher.PublishedEvents, 1)
 }
 
-// --- UpdateBookingStatus Tests ---
-func (suite *BookingServiceTestSuite) TestUpdateBookingStatus_Confirm() {
+func (suite *BookingServiceTestSuite) TestCreateBooking_InactiveBusinessRejected() {
 	t := suite.T()
 	ctx := context.Background()
-	startTime := time.Now().Add(time.Hour)
-	bookingToConfirm := models.Booking{
-		ID: "550e8400-e29b-41d4-a716-446655440004", BusinessID: "biz_confirm", ServiceID: "svc_confirm", CustomerID: "cust_confirm",
-		StartTime: startTime, EndTime: startTime.Add(60 * time.Minute), Status: models.BookingStatusPendingPayment,
-	}
-	suite.DB.Create(&bookingToConfirm)
-
-	updatedBooking, err := suite.BookingService.UpdateBookingStatus(ctx, bookingToConfirm.ID, models.BookingStatusConfirmed)
-	assert.NoError(t, err)
-	assert.NotNil(t, updatedBooking)
-	assert.Equal(t, models.BookingStatusConfirmed, updatedBooking.Status)
+	startTime, _ := time.Parse(time.RFC3339, "2030-04-01T11:00:00Z")
 
-	// Verify DB
-	var dbBooking models.Booking
-	suite.DB.First(&dbBooking, "id = ?", bookingToConfirm.ID)
-	assert.Equal(t, models.BookingStatusConfirmed, dbBooking.Status)
+	// One business is suspended, the other deleted while its service remains
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_suspended", BusinessID: "biz_suspended", Name: "Suspended", DurationMinutes: 60, IsActive: true})
+	suite.DB.Create(&models.Business{ID: "biz_suspended", Name: "Suspended Shop", Status: models.BusinessStatusSuspended, AcceptingBookings: true})
+	suite.openAllWeek("biz_suspended")
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_deleted", BusinessID: "biz_deleted", Name: "Deleted", DurationMinutes: 60, IsActive: true})
+	suite.DB.Create(&models.Business{ID: "biz_deleted", Name: "Deleted Shop", Status: "ACTIVE", AcceptingBookings: true})
+	suite.DB.Delete(&models.Business{ID: "biz_deleted"})
+	suite.openAllWeek("biz_deleted")
 
-	// Verify NATS events (BookingConfirmed and SlotReserved)
-	assert.Len(t, suite.MockNatsPublisher.PublishedEvents, 2)
-	foundConfirmed, foundReserved := false, false
-	for _, event := range suite.MockNatsPublisher.PublishedEvents {
-		if event.Subject == events.BookingConfirmedEvent {
-			foundConfirmed = true
-		}
-		if event.Subject == events.SlotReservedEvent {
-			foundReserved = true
+	for _, tc := range []struct{ businessID, serviceID string }{{"biz_suspended", "svc_suspended"}, {"biz_deleted", "svc_deleted"}} {
+		booking, err := suite.BookingService.CreateBooking(ctx, service.CreateBookingRequest{
+			BusinessID: tc.businessID, ServiceID: tc.serviceID, CustomerID: "cust_inactive", StartTime: startTime,
+		})
+		assert.Nil(t, booking, tc.businessID)
+		if assert.Error(t, err, tc.businessID) {
+			assert.Contains(t, err.Error(), "not active")
 		}
 	}
-	assert.True(t, foundConfirmed, "BookingConfirmedEvent not published")
-	assert.True(t, foundReserved, "SlotReservedEvent not published")
+
+	var count int64
+	suite.DB.Model(&models.Booking{}).Where("customer_id = ?", "cust_inactive").Count(&count)
+	assert.Zero(t, count)
+	assert.Empty(t, suite.MockNatsPublisher.PublishedEvents)
 }
 
-func (suite *BookingServiceTestSuite) TestUpdateBookingStatus_Cancel() {
+func (suite *BookingServiceTestSuite) TestCreateBooking_DailyQuotaRejectsNextBooking() {
 	t := suite.T()
 	ctx := context.Background()
-	startTime := time.Now().Add(2 * time.Hour)
-	bookingToCancel := models.Booking{
-		ID: "550e8400-e29b-41d4-a716-446655440002", BusinessID: "biz_cancel", ServiceID: "svc_cancel", CustomerID: "cust_cancel",
-		StartTime: startTime, EndTime: startTime.Add(60 * time.Minute), Status: models.BookingStatusConfirmed,
+	maxPerDay, maxPerCustomer := 3, 2
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_quota_day", BusinessID: "biz_quota_day", Name: "Quota", DurationMinutes: 30, IsActive: true})
+	suite.DB.Create(&models.Business{ID: "biz_quota_day", Name: "Busy Shop", Status: "ACTIVE", AcceptingBookings: true, MaxBookingsPerDay: &maxPerDay, MaxBookingsPerCustomerPerDay: &maxPerCustomer})
+	suite.openAllWeek("biz_quota_day")
+	book := func(customerID, start string) (*models.Booking, error) {
+		startTime, _ := time.Parse(time.RFC3339, start)
+		return suite.BookingService.CreateBooking(ctx, service.CreateBookingRequest{
+			BusinessID: "biz_quota_day", ServiceID: "svc_quota_day", CustomerID: customerID, StartTime: startTime,
+		})
 	}
-	suite.DB.Create(&bookingToCancel)
 
-	updatedBooking, err := suite.BookingService.UpdateBookingStatus(ctx, bookingToCancel.ID, models.BookingStatusCancelled)
+	_, err := book("cust_quota_a", "2030-04-01T09:00:00Z")
+	assert.NoError(t, err)
+	_, err = book("cust_quota_a", "2030-04-01T10:00:00Z")
 	assert.NoError(t, err)
-	assert.NotNil(t, updatedBooking)
-	assert.Equal(t, models.BookingStatusCancelled, updatedBooking.Status)
 
-	// Verify NATS event
-	assert.Len(t, suite.MockNatsPublisher.PublishedEvents, 1)
-	assert.Equal(t, events.BookingCancelledEvent, suite.MockNatsPublisher.PublishedEvents[0].Subject)
-}
+	// The customer's third booking that day goes past their limit
+	booking, err := book("cust_quota_a", "2030-04-01T11:00:00Z")
+	assert.Nil(t, booking)
+	var unavailableErr *service.SlotUnavailableError
+	if assert.ErrorAs(t, err, &unavailableErr) {
+		assert.Equal(t, service.SlotUnavailableQuotaReached, unavailableErr.Reason)
+	}
 
-// --- ListBookings Tests ---
-func (suite *BookingServiceTestSuite) TestListBookingsForCustomer() {
-	t := suite.T()
-	ctx := context.Background()
-	// Seed bookings for different customers
-	suite.DB.Create(&models.Booking{ID: "550e8400-e29b-41d4-a716-446655440005", CustomerID: "cust1_list", BusinessID: "biz_c_list", ServiceID: "svc_c_list", StartTime: time.Now(), EndTime: time.Now().Add(time.Hour), Status: models.BookingStatusConfirmed})
-	suite.DB.Create(&models.Booking{ID: "550e8400-e29b-41d4-a716-446655440006", CustomerID: "cust1_list", BusinessID: "biz_c_list", ServiceID: "svc_c_list", StartTime: time.Now().Add(2 * time.Hour), EndTime: time.Now().Add(3 * time.Hour), Status: models.BookingStatusConfirmed})
-	suite.DB.Create(&models.Booking{ID: "550e8400-e29b-41d4-a716-446655440007", CustomerID: "cust2_list", BusinessID: "biz_c_list", ServiceID: "svc_c_list", StartTime: time.Now(), EndTime: time.Now().Add(time.Hour), Status: models.BookingStatusConfirmed})
+	// A dry run reports the same, before the customer is sent to payment
+	startTime, _ := time.Parse(time.RFC3339, "2030-04-01T11:00:00Z")
+	booking, err = suite.BookingService.CreateBooking(ctx, service.CreateBookingRequest{
+		BusinessID: "biz_quota_day", ServiceID: "svc_quota_day", CustomerID: "cust_quota_a", StartTime: startTime, DryRun: true,
+	})
+	assert.Nil(t, booking)
+	if assert.ErrorAs(t, err, &unavailableErr) {
+		assert.Equal(t, service.SlotUnavailableQuotaReached, unavailableErr.Reason)
+	}
 
-	bookings, total, err := suite.BookingService.ListBookingsForCustomer(ctx, "cust1_list", 10, 0)
+	// Another customer takes the business's last booking of the day, and booking N+1 is rejected
+	_, err = book("cust_quota_b", "2030-04-01T11:00:00Z")
 	assert.NoError(t, err)
-	assert.Equal(t, int64(2), total)
-	assert.Len(t, bookings, 2)
+	booking, err = book("cust_quota_c", "2030-04-01T12:00:00Z")
+	assert.Nil(t, booking)
+	if assert.ErrorAs(t, err, &unavailableErr) {
+		assert.Equal(t, service.SlotUnavailableQuotaReached, unavailableErr.Reason)
+	}
 
-	bookingsPage2, total2, err := suite.BookingService.ListBookingsForCustomer(ctx, "cust1_list", 1, 1)
+	// The next day has its own quota
+	_, err = book("cust_quota_c", "2030-04-02T12:00:00Z")
 	assert.NoError(t, err)
-	assert.Equal(t, int64(2), total2)
-	assert.Len(t, bookingsPage2, 1)
 
+	var count int64
+	suite.DB.Model(&models.Booking{}).Where("business_id = ?", "biz_quota_day").Count(&count)
+	assert.Equal(t, int64(4), count)
 }
 
-func (suite *BookingServiceTestSuite) TestListBookingsForBusiness() {
+func (suite *BookingServiceTestSuite) TestCreateBooking_BumpsAvailabilityVersion() {
 	t := suite.T()
 	ctx := context.Background()
-	// Seed bookings for different businesses
-	suite.DB.Create(&models.Booking{ID: "550e8400-e29b-41d4-a716-446655440008", BusinessID: "biz1_list", CustomerID: "cust_b_list", ServiceID: "svc_b_list", StartTime: time.Now(), EndTime: time.Now().Add(time.Hour), Status: models.BookingStatusConfirmed})
-	suite.DB.Create(&models.Booking{ID: "550e8400-e29b-41d4-a716-446655440009", BusinessID: "biz1_list", CustomerID: "cust_b_list", ServiceID: "svc_b_list", StartTime: time.Now().Add(2 * time.Hour), EndTime: time.Now().Add(3 * time.Hour), Status: models.BookingStatusPendingPayment})
-	suite.DB.Create(&models.Booking{ID: "550e8400-e29b-41d4-a716-44665544000a", BusinessID: "biz2_list", CustomerID: "cust_b_list", ServiceID: "svc_b_list", StartTime: time.Now(), EndTime: time.Now().Add(time.Hour), Status: models.BookingStatusConfirmed})
 
-	bookings, total, err := suite.BookingService.ListBookingsForBusiness(ctx, "biz1_list", 10, 0)
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_version", BusinessID: "biz_version", Name: "Versioned Service", DurationMinutes: 60, IsActive: true})
+	suite.openAllWeek("biz_version")
+	suite.DB.Create(&models.Business{ID: "biz_version", Name: "Versioned Shop", Status: "ACTIVE"})
+
+	versionOf := func() int64 {
+		version, err := suite.AvailabilityRepo.GetAvailabilityVersion(ctx, "biz_version")
+		assert.NoError(t, err)
+		return version
+	}
+	before := versionOf()
+
+	startTime, _ := time.Parse(time.RFC3339, "2030-04-01T10:00:00Z")
+	booking, err := suite.BookingService.CreateBooking(ctx, service.CreateBookingRequest{
+		BusinessID: "biz_version", ServiceID: "svc_version", CustomerID: "cust_version", StartTime: startTime,
+	})
 	assert.NoError(t, err)
-	assert.Equal(t, int64(2), total)
-	assert.Len(t, bookings, 2)
+	afterCreate := versionOf()
+	assert.Greater(t, afterCreate, before)
+
+	// Cancelling frees the slot, so the version moves again
+	_, err = suite.BookingService.UpdateBookingStatus(ctx, booking.ID, service.UpdateBookingStatusRequest{Status: models.BookingStatusCancelled})
+	assert.NoError(t, err)
+	assert.Greater(t, versionOf(), afterCreate)
 }
 
-func TestBookingServiceTestSuite(t *testing.T) {
-	suite.Run(t, new(BookingServiceTestSuite))
+func (suite *BookingServiceTestSuite) TestCreateBooking_BackToBack_NoConflict() {
+	t := suite.T()
+	ctx := context.Background()
+	svcDef := models.ServiceDefinition{ID: "svc3", BusinessID: "biz3", Name: "Service 3", DurationMinutes: 30, IsActive: true}
+	suite.DB.Create(&svcDef)
+	suite.openAllWeek("biz3")
+
+	existingStartTime, _ := time.Parse(time.RFC3339, "2030-04-01T14:00:00Z")
+	existingBooking := models.Booking{
+		ID: "550e8400-e29b-41d4-a716-446655440003", BusinessID: "biz3", ServiceID: "svc3", CustomerID: "cust_b2b_1",
+		StartTime: existingStartTime, EndTime: existingStartTime.Add(30 * time.Minute), Status: models.BookingStatusConfirmed,
+	}
+	suite.DB.Create(&existingBooking)
+
+	// New booking starts exactly when the previous one ends
+	newStartTime := existingBooking.EndTime
+	req := service.CreateBookingRequest{
+		BusinessID: "biz3", ServiceID: "svc3", CustomerID: "cust_b2b_2", StartTime: newStartTime,
+	}
+	booking, err := suite.BookingService.CreateBooking(ctx, req)
+	assert.NoError(t, err)
+	assert.NotNil(t, booking)
+	assert.Equal(t, models.BookingStatusPendingPayment, booking.Status)
+	assert.Len(t, suite.MockNatsPublisher.PublishedEvents, 1)
+}
+
+func (suite *BookingServiceTestSuite) TestCreateBooking_BackToBackForbiddenByBusiness() {
+	t := suite.T()
+	ctx := context.Background()
+	suite.DB.Create(&models.Business{ID: "biz_no_b2b", Name: "Spaced Out", AcceptingBookings: true})
+	suite.DB.Model(&models.Business{}).Where("id = ?", "biz_no_b2b").Update("allow_back_to_back", false)
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_no_b2b", BusinessID: "biz_no_b2b", Name: "Massage", DurationMinutes: 30, IsActive: true})
+	suite.openAllWeek("biz_no_b2b")
+
+	existingStart, _ := time.Parse(time.RFC3339, "2030-04-01T14:00:00Z")
+	suite.DB.Create(&models.Booking{
+		BusinessID: "biz_no_b2b", ServiceID: "svc_no_b2b", CustomerID: "cust_no_b2b_1",
+		StartTime: existingStart, EndTime: existingStart.Add(30 * time.Minute), Status: models.BookingStatusConfirmed,
+	})
+
+	// Starting as the existing booking ends, or ending as it starts, both conflict
+	for _, start := range []time.Time{existingStart.Add(30 * time.Minute), existingStart.Add(-30 * time.Minute)} {
+		_, err := suite.BookingService.CreateBooking(ctx, service.CreateBookingRequest{
+			BusinessID: "biz_no_b2b", ServiceID: "svc_no_b2b", CustomerID: "cust_no_b2b_2", StartTime: start,
+		})
+		var conflict *service.BookingConflictError
+		assert.ErrorAs(t, err, &conflict, "start %s", start)
+	}
+
+	// Any gap at all is enough
+	booking, err := suite.BookingService.CreateBooking(ctx, service.CreateBookingRequest{
+		BusinessID: "biz_no_b2b", ServiceID: "svc_no_b2b", CustomerID: "cust_no_b2b_2", StartTime: existingStart.Add(35 * time.Minute),
+	})
+	assert.NoError(t, err)
+	assert.NotNil(t, booking)
+}
+
+func (suite *BookingServiceTestSuite) TestCreateBooking_ConflictsWithinRuleBuffers() {
+	t := suite.T()
+	ctx := context.Background()
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_buffered", BusinessID: "biz_buffered", Name: "Grooming", DurationMinutes: 30, IsActive: true})
+	// 2030-04-01 is a Monday
+	suite.DB.Create(&models.AvailabilityRule{BusinessID: "biz_buffered", DayOfWeek: models.Monday, StartTime: "09:00", EndTime: "17:00", BufferBeforeMinutes: 5, BufferAfterMinutes: 10})
+
+	existingStart, _ := time.Parse(time.RFC3339, "2030-04-01T14:00:00Z")
+	suite.DB.Create(&models.Booking{
+		BusinessID: "biz_buffered", ServiceID: "svc_buffered", CustomerID: "cust_buffered_1",
+		StartTime: existingStart, EndTime: existingStart.Add(30 * time.Minute), Status: models.BookingStatusConfirmed,
+	})
+
+	// Back to back leaves no room for the first booking's cleanup and the second one's setup
+	_, err := suite.BookingService.CreateBooking(ctx, service.CreateBookingRequest{
+		BusinessID: "biz_buffered", ServiceID: "svc_buffered", CustomerID: "cust_buffered_2", StartTime: existingStart.Add(30 * time.Minute),
+	})
+	var conflict *service.BookingConflictError
+	assert.ErrorAs(t, err, &conflict)
+
+	booking, err := suite.BookingService.CreateBooking(ctx, service.CreateBookingRequest{
+		BusinessID: "biz_buffered", ServiceID: "svc_buffered", CustomerID: "cust_buffered_2", StartTime: existingStart.Add(45 * time.Minute),
+	})
+	assert.NoError(t, err)
+	assert.NotNil(t, booking)
+}
+
+// newBookingServiceAt returns a booking service whose clock is fixed at now.
+func (suite *BookingServiceTestSuite) newBookingServiceAt(now time.Time) *service.BookingService {
+	return service.NewBookingService(suite.BookingRepo, nil, suite.AvailabilityRepo, repository.NewCustomerPreferenceRepository(suite.DB), suite.OutboxRelay, suite.MockNatsPublisher, suite.MockNotifier, clock.NewFake(now), suite.TestLogger)
+}
+
+func (suite *BookingServiceTestSuite) TestCreateBooking_UnbookableTimesRejected() {
+	t := suite.T()
+	ctx := context.Background()
+	notice := 120
+	suite.DB.Create(&models.Business{ID: "biz_times", Name: "Timely", Status: "ACTIVE", AcceptingBookings: true, MinimumNoticeMinutes: &notice})
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_times", BusinessID: "biz_times", Name: "Haircut", DurationMinutes: 30, IsActive: true})
+	// Open Mondays 09:00-17:00; 2030-04-01 is a Monday
+	suite.DB.Create(&models.AvailabilityRule{BusinessID: "biz_times", DayOfWeek: models.Monday, StartTime: "09:00", EndTime: "17:00"})
+	now := time.Date(2030, 4, 1, 10, 0, 0, 0, time.UTC)
+	bookingService := suite.newBookingServiceAt(now)
+
+	cases := []struct {
+		start  time.Time
+		reason service.SlotUnavailableReason
+	}{
+		{now.Add(-time.Hour), service.SlotUnavailablePast},
+		{now.Add(90 * time.Minute), service.SlotUnavailableLeadTime},
+		{time.Date(2030, 4, 1, 17, 0, 0, 0, time.UTC), service.SlotUnavailableOutsideHours},
+		{time.Date(2030, 4, 1, 16, 45, 0, 0, time.UTC), service.SlotUnavailableOutsideHours}, // Runs past closing
+		{time.Date(2030, 4, 2, 10, 0, 0, 0, time.UTC), service.SlotUnavailableOutsideHours},  // Closed on Tuesdays
+	}
+	for _, tc := range cases {
+		for _, dryRun := range []bool{true, false} {
+			booking, err := bookingService.CreateBooking(ctx, service.CreateBookingRequest{
+				BusinessID: "biz_times", ServiceID: "svc_times", CustomerID: "cust_times", StartTime: tc.start, DryRun: dryRun,
+			})
+			assert.Nil(t, booking)
+			var unavailable *service.SlotUnavailableError
+			if assert.ErrorAs(t, err, &unavailable, "start %s, dry run %t", tc.start, dryRun) {
+				assert.Equal(t, tc.reason, unavailable.Reason, "start %s, dry run %t", tc.start, dryRun)
+			}
+		}
+	}
+	var count int64
+	suite.DB.Model(&models.Booking{}).Where("business_id = ?", "biz_times").Count(&count)
+	assert.Zero(t, count)
+	assert.Empty(t, suite.MockNatsPublisher.PublishedEvents)
+
+	// Exactly the minimum notice ahead and within hours is fine
+	booking, err := bookingService.CreateBooking(ctx, service.CreateBookingRequest{
+		BusinessID: "biz_times", ServiceID: "svc_times", CustomerID: "cust_times", StartTime: now.Add(2 * time.Hour),
+	})
+	assert.NoError(t, err)
+	assert.NotNil(t, booking)
+}
+
+// --- Metadata Tests ---
+func (suite *BookingServiceTestSuite) TestCreateBooking_StoresArbitraryMetadata() {
+	t := suite.T()
+	ctx := context.Background()
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_meta", BusinessID: "biz_meta", Name: "Metadata Service", DurationMinutes: 30, IsActive: true})
+	suite.openAllWeek("biz_meta")
+
+	startTime, _ := time.Parse(time.RFC3339, "2030-04-03T10:00:00Z")
+	booking, err := suite.BookingService.CreateBooking(ctx, service.CreateBookingRequest{
+		BusinessID: "biz_meta", ServiceID: "svc_meta", CustomerID: "cust_meta", StartTime: startTime,
+		Metadata: map[string]interface{}{"petName": "Rex", "visits": float64(3), "firstTime": false},
+	})
+	assert.NoError(t, err)
+
+	var dbBooking models.Booking
+	assert.NoError(t, suite.DB.First(&dbBooking, "id = ?", booking.ID).Error)
+	assert.Equal(t, "Rex", dbBooking.Metadata["petName"])
+	assert.Equal(t, float64(3), dbBooking.Metadata["visits"])
+	assert.Equal(t, false, dbBooking.Metadata["firstTime"])
+
+	assert.Len(t, suite.MockNatsPublisher.PublishedEvents, 1)
+	eventData, ok := suite.MockNatsPublisher.PublishedEvents[0].Data.(map[string]interface{})
+	assert.True(t, ok)
+	assert.NotNil(t, eventData["metadata"])
+}
+
+func (suite *BookingServiceTestSuite) TestCreateBooking_RejectsMetadataFailingSchema() {
+	t := suite.T()
+	ctx := context.Background()
+	suite.DB.Create(&models.ServiceDefinition{
+		ID: "svc_schema", BusinessID: "biz_schema", Name: "Grooming", DurationMinutes: 30, IsActive: true,
+		MetadataSchema: &models.MetadataSchema{Fields: []models.MetadataFieldSchema{
+			{Name: "petName", Type: models.MetadataFieldTypeString, Required: true},
+		}},
+	})
+	suite.openAllWeek("biz_schema")
+
+	startTime, _ := time.Parse(time.RFC3339, "2030-04-03T11:00:00Z")
+	cases := map[string]map[string]interface{}{
+		"missing required field": nil,
+		"wrong field type":       {"petName": float64(7)},
+		"unknown field":          {"petName": "Rex", "color": "brown"},
+	}
+	for name, metadata := range cases {
+		booking, err := suite.BookingService.CreateBooking(ctx, service.CreateBookingRequest{
+			BusinessID: "biz_schema", ServiceID: "svc_schema", CustomerID: "cust_schema", StartTime: startTime,
+			Metadata: metadata,
+		})
+		assert.Error(t, err, name)
+		assert.Nil(t, booking, name)
+		if err != nil {
+			assert.Contains(t, err.Error(), "invalid metadata", name)
+		}
+	}
+	assert.Len(t, suite.MockNatsPublisher.PublishedEvents, 0)
+
+	booking, err := suite.BookingService.CreateBooking(ctx, service.CreateBookingRequest{
+		BusinessID: "biz_schema", ServiceID: "svc_schema", CustomerID: "cust_schema", StartTime: startTime,
+		Metadata: map[string]interface{}{"petName": "Rex"},
+	})
+	assert.NoError(t, err)
+	assert.NotNil(t, booking)
+}
+
+func (suite *BookingServiceTestSuite) TestFindConflictingBookings_ExcludesMovedBooking() {
+	t := suite.T()
+	ctx := context.Background()
+	startTime, _ := time.Parse(time.RFC3339, "2024-04-03T10:00:00Z")
+	booking := models.Booking{
+		BusinessID: "biz_move", ServiceID: "svc_move", CustomerID: "cust_move",
+		StartTime: startTime, EndTime: startTime.Add(60 * time.Minute), Status: models.BookingStatusConfirmed,
+	}
+	suite.DB.Create(&booking)
+
+	// Moving the booking 10 minutes later overlaps its own current range
+	newStart := startTime.Add(10 * time.Minute)
+	newEnd := newStart.Add(60 * time.Minute)
+
+	conflicts, err := suite.BookingRepo.FindConflictingBookings(ctx, "biz_move", "svc_move", newStart, newEnd, "")
+	assert.NoError(t, err)
+	assert.Len(t, conflicts, 1, "Without exclusion the booking conflicts with itself")
+
+	conflicts, err = suite.BookingRepo.FindConflictingBookings(ctx, "biz_move", "svc_move", newStart, newEnd, booking.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, conflicts, "The booking being moved must not block itself")
+
+	// Other bookings in the new range still conflict
+	other := models.Booking{
+		BusinessID: "biz_move", ServiceID: "svc_move", CustomerID: "cust_other",
+		StartTime: startTime.Add(60 * time.Minute), EndTime: startTime.Add(90 * time.Minute), Status: models.BookingStatusConfirmed,
+	}
+	suite.DB.Create(&other)
+	conflicts, err = suite.BookingRepo.FindConflictingBookings(ctx, "biz_move", "svc_move", newStart, newEnd, booking.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, conflicts, 1) {
+		assert.Equal(t, other.ID, conflicts[0].ID)
+	}
+}
+
+func (suite *BookingServiceTestSuite) TestFindAdjacentBookings_ExcludesMovedBooking() {
+	t := suite.T()
+	ctx := context.Background()
+	startTime := time.Date(2030, 4, 3, 10, 0, 0, 0, time.UTC)
+	booking := models.Booking{
+		BusinessID: "biz_move", ServiceID: "svc_move", CustomerID: "cust_move",
+		StartTime: startTime, EndTime: startTime.Add(60 * time.Minute), Status: models.BookingStatusConfirmed,
+	}
+	suite.DB.Create(&booking)
+
+	// Moving the booking to start as it currently ends touches its own range
+	newStart := booking.EndTime
+	adjacent, err := suite.BookingRepo.FindAdjacentBookings(ctx, "biz_move", newStart, newStart.Add(time.Hour), "")
+	assert.NoError(t, err)
+	assert.Len(t, adjacent, 1)
+
+	adjacent, err = suite.BookingRepo.FindAdjacentBookings(ctx, "biz_move", newStart, newStart.Add(time.Hour), booking.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, adjacent)
+}
+
+// --- Outbox Tests ---
+func (suite *BookingServiceTestSuite) TestCreateBooking_WritesSentOutboxEvent() {
+	t := suite.T()
+	ctx := context.Background()
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_outbox", BusinessID: "biz_outbox", Name: "Outbox Service", DurationMinutes: 30, IsActive: true})
+	suite.openAllWeek("biz_outbox")
+
+	startTime, _ := time.Parse(time.RFC3339, "2030-04-02T10:00:00Z")
+	booking, err := suite.BookingService.CreateBooking(ctx, service.CreateBookingRequest{
+		BusinessID: "biz_outbox", ServiceID: "svc_outbox", CustomerID: "cust_outbox", StartTime: startTime,
+	})
+	assert.NoError(t, err)
+
+	outboxEvents, err := suite.OutboxRepo.GetEventsByAggregateID(ctx, booking.ID)
+	assert.NoError(t, err)
+	assert.Len(t, outboxEvents, 1)
+	assert.Equal(t, events.BookingRequestedEvent, outboxEvents[0].Subject)
+	assert.Equal(t, models.OutboxEventStatusSent, outboxEvents[0].Status)
+	assert.NotNil(t, outboxEvents[0].SentAt)
+}
+
+func (suite *BookingServiceTestSuite) TestCreateBooking_PublishFails_RelayPublishesPendingEvent() {
+	t := suite.T()
+	ctx := context.Background()
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_relay", BusinessID: "biz_relay", Name: "Relay Service", DurationMinutes: 30, IsActive: true})
+	suite.openAllWeek("biz_relay")
+
+	// NATS is down while the booking is created
+	failingPublisher := &FailingEventPublisher{}
+	offlineBookingService := service.NewBookingService(
+		suite.BookingRepo,
+		nil,
+		suite.AvailabilityRepo,
+		repository.NewCustomerPreferenceRepository(suite.DB),
+		service.NewOutboxRelay(suite.OutboxRepo, failingPublisher, suite.TestLogger),
+		failingPublisher,
+		&MockNotificationClient{},
+		nil,
+		suite.TestLogger,
+	)
+
+	startTime, _ := time.Parse(time.RFC3339, "2030-04-02T12:00:00Z")
+	booking, err := offlineBookingService.CreateBooking(ctx, service.CreateBookingRequest{
+		BusinessID: "biz_relay", ServiceID: "svc_relay", CustomerID: "cust_relay", StartTime: startTime,
+	})
+	assert.NoError(t, err, "booking must still be committed when publishing fails")
+
+	outboxEvents, err := suite.OutboxRepo.GetEventsByAggregateID(ctx, booking.ID)
+	assert.NoError(t, err)
+	assert.Len(t, outboxEvents, 1)
+	assert.Equal(t, models.OutboxEventStatusPending, outboxEvents[0].Status)
+	assert.Equal(t, 1, outboxEvents[0].Attempts)
+
+	// NATS is back; the relay publishes the pending event
+	sent, err := suite.OutboxRelay.RelayPendingEvents(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	assert.Len(t, suite.MockNatsPublisher.PublishedEvents, 1)
+	assert.Equal(t, events.BookingRequestedEvent, suite.MockNatsPublisher.PublishedEvents[0].Subject)
+	eventData, ok := suite.MockNatsPublisher.PublishedEvents[0].Data.(map[string]interface{})
+	assert.True(t, ok)
+	assert.Equal(t, booking.ID, eventData["bookingId"])
+
+	outboxEvents, err = suite.OutboxRepo.GetEventsByAggregateID(ctx, booking.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.OutboxEventStatusSent, outboxEvents[0].Status)
+
+	// A second run has nothing left to publish
+	sent, err = suite.OutboxRelay.RelayPendingEvents(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, sent)
+}
+
+func (suite *BookingServiceTestSuite) TestRelayPendingEvents_ParksEventsThatKeepFailing() {
+	t := suite.T()
+	ctx := context.Background()
+	offlineRelay := service.NewOutboxRelay(suite.OutboxRepo, &FailingEventPublisher{}, suite.TestLogger)
+	failing := models.OutboxEvent{AggregateID: "bkg_park", Subject: events.BookingRequestedEvent, Payload: `{"bookingId":"bkg_park"}`, Status: models.OutboxEventStatusPending, MaxAttempts: 2}
+	suite.DB.Create(&failing)
+
+	// The first failure leaves the event pending, the second uses up its attempts
+	_, err := offlineRelay.RelayPendingEvents(ctx)
+	assert.NoError(t, err)
+	outboxEvents, _ := suite.OutboxRepo.GetEventsByAggregateID(ctx, "bkg_park")
+	assert.Equal(t, models.OutboxEventStatusPending, outboxEvents[0].Status)
+	assert.Nil(t, outboxEvents[0].ParkedAt)
+
+	_, err = offlineRelay.RelayPendingEvents(ctx)
+	assert.NoError(t, err)
+	outboxEvents, _ = suite.OutboxRepo.GetEventsByAggregateID(ctx, "bkg_park")
+	assert.Equal(t, models.OutboxEventStatusParked, outboxEvents[0].Status)
+	assert.Equal(t, 2, outboxEvents[0].Attempts)
+	assert.NotNil(t, outboxEvents[0].ParkedAt)
+	if assert.NotNil(t, outboxEvents[0].LastError) {
+		assert.Contains(t, *outboxEvents[0].LastError, "connection closed")
+	}
+
+	// Parked events are not retried, even once NATS is back
+	sent, err := suite.OutboxRelay.RelayPendingEvents(ctx)
+	assert.NoError(t, err)
+	assert.Zero(t, sent)
+	assert.Empty(t, suite.MockNatsPublisher.PublishedEvents)
+}
+
+func (suite *BookingServiceTestSuite) TestRelayPendingEvents_ParksUnpublishablePayloadAtOnce() {
+	t := suite.T()
+	ctx := context.Background()
+	// Valid JSON, but not the object every event payload is
+	suite.DB.Create(&models.OutboxEvent{AggregateID: "bkg_bad_payload", Subject: events.BookingRequestedEvent, Payload: `[1,2]`, Status: models.OutboxEventStatusPending, MaxAttempts: models.DefaultOutboxMaxAttempts})
+	suite.DB.Create(&models.OutboxEvent{AggregateID: "bkg_good_payload", Subject: events.BookingRequestedEvent, Payload: `{"bookingId":"bkg_good_payload"}`, Status: models.OutboxEventStatusPending, MaxAttempts: models.DefaultOutboxMaxAttempts})
+
+	sent, err := suite.OutboxRelay.RelayPendingEvents(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+
+	outboxEvents, _ := suite.OutboxRepo.GetEventsByAggregateID(ctx, "bkg_bad_payload")
+	assert.Equal(t, models.OutboxEventStatusParked, outboxEvents[0].Status)
+	pending, err := suite.OutboxRepo.GetPendingEvents(ctx, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+// --- UpdateBookingStatus Tests ---
+func (suite *BookingServiceTestSuite) TestUpdateBookingStatus_Confirm() {
+	t := suite.T()
+	ctx := context.Background()
+	startTime := time.Now().Add(time.Hour)
+	bookingToConfirm := models.Booking{
+		ID: "550e8400-e29b-41d4-a716-446655440004", BusinessID: "biz_confirm", ServiceID: "svc_confirm", CustomerID: "cust_confirm",
+		StartTime: startTime, EndTime: startTime.Add(60 * time.Minute), Status: models.BookingStatusPendingPayment,
+	}
+	suite.DB.Create(&bookingToConfirm)
+
+	updatedBooking, err := suite.BookingService.UpdateBookingStatus(ctx, bookingToConfirm.ID, service.UpdateBookingStatusRequest{Status: models.BookingStatusConfirmed})
+	assert.NoError(t, err)
+	assert.NotNil(t, updatedBooking)
+	assert.Equal(t, models.BookingStatusConfirmed, updatedBooking.Status)
+
+	// Verify DB
+	var dbBooking models.Booking
+	suite.DB.First(&dbBooking, "id = ?", bookingToConfirm.ID)
+	assert.Equal(t, models.BookingStatusConfirmed, dbBooking.Status)
+
+	// Verify NATS events (BookingConfirmed and SlotReserved)
+	assert.Len(t, suite.MockNatsPublisher.PublishedEvents, 2)
+	foundConfirmed, foundReserved := false, false
+	for _, event := range suite.MockNatsPublisher.PublishedEvents {
+		if event.Subject == events.BookingConfirmedEvent {
+			foundConfirmed = true
+		}
+		if event.Subject == events.SlotReservedEvent {
+			foundReserved = true
+		}
+	}
+	assert.True(t, foundConfirmed, "BookingConfirmedEvent not published")
+	assert.True(t, foundReserved, "SlotReservedEvent not published")
+}
+
+func (suite *BookingServiceTestSuite) TestUpdateBookingStatus_Confirm_PartialPublishFailureIsRetried() {
+	t := suite.T()
+	ctx := context.Background()
+	startTime := time.Now().Add(time.Hour)
+	booking := models.Booking{
+		ID: "550e8400-e29b-41d4-a716-446655440011", BusinessID: "biz_partial", ServiceID: "svc_partial", CustomerID: "cust_partial",
+		StartTime: startTime, EndTime: startTime.Add(60 * time.Minute), Status: models.BookingStatusPendingPayment,
+	}
+	suite.DB.Create(&booking)
+
+	// booking.confirmed goes out but slot.reserved fails
+	flakyPublisher := &SubjectFailingEventPublisher{FailSubject: events.SlotReservedEvent}
+	flakyBookingService := service.NewBookingService(
+		suite.BookingRepo,
+		nil,
+		suite.AvailabilityRepo,
+		repository.NewCustomerPreferenceRepository(suite.DB),
+		service.NewOutboxRelay(suite.OutboxRepo, flakyPublisher, suite.TestLogger),
+		flakyPublisher,
+		&MockNotificationClient{},
+		nil,
+		suite.TestLogger,
+	)
+	_, err := flakyBookingService.UpdateBookingStatus(ctx, booking.ID, service.UpdateBookingStatusRequest{Status: models.BookingStatusConfirmed})
+	assert.NoError(t, err)
+	if assert.Len(t, flakyPublisher.PublishedEvents, 1) {
+		assert.Equal(t, events.BookingConfirmedEvent, flakyPublisher.PublishedEvents[0].Subject)
+	}
+
+	// The failed event is kept in the outbox for the relay
+	outboxEvents, err := suite.OutboxRepo.GetEventsByAggregateID(ctx, booking.ID)
+	assert.NoError(t, err)
+	statusBySubject := map[string]models.OutboxEventStatus{}
+	for _, evt := range outboxEvents {
+		statusBySubject[evt.Subject] = evt.Status
+		if evt.Subject == events.SlotReservedEvent {
+			assert.Equal(t, 1, evt.Attempts)
+		}
+	}
+	assert.Equal(t, models.OutboxEventStatusSent, statusBySubject[events.BookingConfirmedEvent])
+	assert.Equal(t, models.OutboxEventStatusPending, statusBySubject[events.SlotReservedEvent])
+
+	// Once NATS recovers the relay publishes only the missing event
+	sent, err := suite.OutboxRelay.RelayPendingEvents(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	if assert.Len(t, suite.MockNatsPublisher.PublishedEvents, 1) {
+		assert.Equal(t, events.SlotReservedEvent, suite.MockNatsPublisher.PublishedEvents[0].Subject)
+	}
+}
+
+func (suite *BookingServiceTestSuite) TestUpdateBookingStatus_Cancel() {
+	t := suite.T()
+	ctx := context.Background()
+	startTime := time.Now().Add(2 * time.Hour)
+	bookingToCancel := models.Booking{
+		ID: "550e8400-e29b-41d4-a716-446655440002", BusinessID: "biz_cancel", ServiceID: "svc_cancel", CustomerID: "cust_cancel",
+		StartTime: startTime, EndTime: startTime.Add(60 * time.Minute), Status: models.BookingStatusConfirmed,
+	}
+	suite.DB.Create(&bookingToCancel)
+
+	updatedBooking, err := suite.BookingService.UpdateBookingStatus(ctx, bookingToCancel.ID, service.UpdateBookingStatusRequest{Status: models.BookingStatusCancelled})
+	assert.NoError(t, err)
+	assert.NotNil(t, updatedBooking)
+	assert.Equal(t, models.BookingStatusCancelled, updatedBooking.Status)
+
+	// Verify NATS event
+	assert.Len(t, suite.MockNatsPublisher.PublishedEvents, 1)
+	assert.Equal(t, events.BookingCancelledEvent, suite.MockNatsPublisher.PublishedEvents[0].Subject)
+}
+
+// --- Customer Cancellation Tests ---
+
+func (suite *BookingServiceTestSuite) seedCustomerBooking(id string, startsIn time.Duration) models.Booking {
+	startTime := time.Now().Add(startsIn)
+	booking := models.Booking{
+		ID: id, BusinessID: "biz_policy", ServiceID: "svc_policy", CustomerID: "cust_policy",
+		StartTime: startTime, EndTime: startTime.Add(60 * time.Minute), Status: models.BookingStatusConfirmed,
+	}
+	assert.NoError(suite.T(), suite.DB.Create(&booking).Error)
+	return booking
+}
+
+func (suite *BookingServiceTestSuite) TestCancelBookingAsCustomer_WithinWindow() {
+	t := suite.T()
+	booking := suite.seedCustomerBooking("550e8400-e29b-41d4-a716-446655440010", 48*time.Hour)
+
+	cancelled, err := suite.BookingService.CancelBookingAsCustomer(context.Background(), booking.ID, "cust_policy")
+	assert.NoError(t, err)
+	if assert.NotNil(t, cancelled) {
+		assert.Equal(t, models.BookingStatusCancelled, cancelled.Status)
+	}
+
+	if assert.Len(t, suite.MockNatsPublisher.PublishedEvents, 1) {
+		event := suite.MockNatsPublisher.PublishedEvents[0]
+		assert.Equal(t, events.BookingCancelledEvent, event.Subject)
+		eventData, ok := event.Data.(map[string]interface{})
+		assert.True(t, ok)
+		assert.Equal(t, "customer", eventData["reason"])
+	}
+}
+
+func (suite *BookingServiceTestSuite) TestCancelBookingAsCustomer_PastCutoffRejected() {
+	t := suite.T()
+	booking := suite.seedCustomerBooking("550e8400-e29b-41d4-a716-446655440011", 2*time.Hour)
+
+	_, err := suite.BookingService.CancelBookingAsCustomer(context.Background(), booking.ID, "cust_policy")
+	var policyErr *service.CancellationPolicyError
+	if assert.ErrorAs(t, err, &policyErr) {
+		assert.WithinDuration(t, booking.StartTime.Add(-models.DefaultCancellationCutoffHours*time.Hour), policyErr.Deadline, time.Second)
+	}
+
+	stored, err := suite.BookingRepo.GetBookingByID(context.Background(), booking.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.BookingStatusConfirmed, stored.Status)
+	assert.Empty(t, suite.MockNatsPublisher.PublishedEvents)
+}
+
+func (suite *BookingServiceTestSuite) TestCancelBookingAsCustomer_UsesBusinessCutoff() {
+	t := suite.T()
+	cutoff := 1
+	assert.NoError(t, suite.DB.Create(&models.Business{ID: "biz_policy", CancellationCutoffHours: &cutoff}).Error)
+	booking := suite.seedCustomerBooking("550e8400-e29b-41d4-a716-446655440012", 2*time.Hour)
+
+	cancelled, err := suite.BookingService.CancelBookingAsCustomer(context.Background(), booking.ID, "cust_policy")
+	assert.NoError(t, err)
+	if assert.NotNil(t, cancelled) {
+		assert.Equal(t, models.BookingStatusCancelled, cancelled.Status)
+	}
+}
+
+func (suite *BookingServiceTestSuite) TestCancelBookingAsCustomer_OtherCustomerForbidden() {
+	t := suite.T()
+	booking := suite.seedCustomerBooking("550e8400-e29b-41d4-a716-446655440013", 48*time.Hour)
+
+	_, err := suite.BookingService.CancelBookingAsCustomer(context.Background(), booking.ID, "someone_else")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "does not belong")
+	}
+}
+
+func (suite *BookingServiceTestSuite) TestCancelPendingBooking() {
+	t := suite.T()
+	confirmed := suite.seedCustomerBooking("550e8400-e29b-41d4-a716-446655440014", 48*time.Hour)
+	pending := suite.seedCustomerBooking("550e8400-e29b-41d4-a716-446655440015", 72*time.Hour)
+	assert.NoError(t, suite.DB.Model(&pending).Update("status", models.BookingStatusPendingPayment).Error)
+
+	_, err := suite.BookingService.CancelPendingBooking(context.Background(), confirmed.ID, "cust_policy")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "not pending payment")
+	}
+	_, err = suite.BookingService.CancelPendingBooking(context.Background(), pending.ID, "someone_else")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "does not belong")
+	}
+	assert.Empty(t, suite.MockNatsPublisher.PublishedEvents)
+
+	cancelled, err := suite.BookingService.CancelPendingBooking(context.Background(), pending.ID, "cust_policy")
+	assert.NoError(t, err)
+	if assert.NotNil(t, cancelled) {
+		assert.Equal(t, models.BookingStatusCancelled, cancelled.Status)
+	}
+	assert.Contains(t, suite.MockNotifier.CancelledBookingIDs, pending.ID)
+	if assert.Len(t, suite.MockNatsPublisher.PublishedEvents, 1) {
+		eventData, ok := suite.MockNatsPublisher.PublishedEvents[0].Data.(map[string]interface{})
+		assert.True(t, ok)
+		assert.Equal(t, "payment_abandoned", eventData["reason"])
+	}
+}
+
+// --- Recurring Series Cancellation Tests ---
+
+// seedSeries creates four weekly confirmed occurrences of one series and returns them earliest first
+func (suite *BookingServiceTestSuite) seedSeries(seriesID string) []models.Booking {
+	firstStart := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+	occurrences := make([]models.Booking, 4)
+	for i := range occurrences {
+		start := firstStart.Add(time.Duration(i) * 7 * 24 * time.Hour)
+		occurrences[i] = models.Booking{
+			BusinessID: "biz_series", ServiceID: "svc_series", CustomerID: "cust_series", SeriesID: &seriesID,
+			StartTime: start, EndTime: start.Add(time.Hour), Status: models.BookingStatusConfirmed,
+		}
+		suite.DB.Create(&occurrences[i])
+	}
+	return occurrences
+}
+
+func (suite *BookingServiceTestSuite) seriesStatuses(occurrences []models.Booking) []models.BookingStatus {
+	statuses := make([]models.BookingStatus, len(occurrences))
+	for i, occurrence := range occurrences {
+		var dbBooking models.Booking
+		suite.DB.First(&dbBooking, "id = ?", occurrence.ID)
+		statuses[i] = dbBooking.Status
+	}
+	return statuses
+}
+
+func (suite *BookingServiceTestSuite) cancelledEventBookingIDs() []string {
+	var ids []string
+	for _, event := range suite.MockNatsPublisher.PublishedEvents {
+		if event.Subject == events.BookingCancelledEvent {
+			ids = append(ids, event.Data.(map[string]interface{})["bookingId"].(string))
+		}
+	}
+	return ids
+}
+
+// managesSeriesBusiness stands in for the owner of biz_series making a status change.
+func managesSeriesBusiness(businessID string) bool {
+	return businessID == "biz_series"
+}
+
+func (suite *BookingServiceTestSuite) TestUpdateBookingStatus_CancelSingleOccurrence() {
+	t := suite.T()
+	occurrences := suite.seedSeries("series_single")
+
+	cancelled, err := suite.BookingService.UpdateBookingStatus(context.Background(), occurrences[1].ID, service.UpdateBookingStatusRequest{
+		Status: models.BookingStatusCancelled, Scope: models.CancellationScopeOccurrence,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, models.BookingStatusCancelled, cancelled.Status)
+
+	assert.Equal(t, []models.BookingStatus{
+		models.BookingStatusConfirmed, models.BookingStatusCancelled, models.BookingStatusConfirmed, models.BookingStatusConfirmed,
+	}, suite.seriesStatuses(occurrences))
+	assert.Equal(t, []string{occurrences[1].ID}, suite.cancelledEventBookingIDs())
+}
+
+func (suite *BookingServiceTestSuite) TestUpdateBookingStatus_CancelThisAndFollowing() {
+	t := suite.T()
+	occurrences := suite.seedSeries("series_following")
+
+	cancelled, err := suite.BookingService.UpdateBookingStatus(context.Background(), occurrences[1].ID, service.UpdateBookingStatusRequest{
+		Status: models.BookingStatusCancelled, Scope: models.CancellationScopeFollowing, CanManage: managesSeriesBusiness,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, occurrences[1].ID, cancelled.ID)
+	assert.Equal(t, models.BookingStatusCancelled, cancelled.Status)
+
+	assert.Equal(t, []models.BookingStatus{
+		models.BookingStatusConfirmed, models.BookingStatusCancelled, models.BookingStatusCancelled, models.BookingStatusCancelled,
+	}, suite.seriesStatuses(occurrences))
+	assert.Equal(t, []string{occurrences[1].ID, occurrences[2].ID, occurrences[3].ID}, suite.cancelledEventBookingIDs())
+	assert.Equal(t, []int{3}, suite.MockNotifier.BatchSizes, "The occurrences' cancellation emails go out in one batch")
+	assert.Len(t, suite.MockNotifier.SentNotifications, 3)
+}
+
+func (suite *BookingServiceTestSuite) TestUpdateBookingStatus_CancelEntireSeries() {
+	t := suite.T()
+	occurrences := suite.seedSeries("series_all")
+	// An occurrence already cancelled is left alone
+	suite.DB.Model(&models.Booking{}).Where("id = ?", occurrences[3].ID).Update("status", models.BookingStatusCancelled)
+
+	_, err := suite.BookingService.UpdateBookingStatus(context.Background(), occurrences[2].ID, service.UpdateBookingStatusRequest{
+		Status: models.BookingStatusCancelled, Scope: models.CancellationScopeSeries, CanManage: managesSeriesBusiness,
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []models.BookingStatus{
+		models.BookingStatusCancelled, models.BookingStatusCancelled, models.BookingStatusCancelled, models.BookingStatusCancelled,
+	}, suite.seriesStatuses(occurrences))
+	assert.Equal(t, []string{occurrences[0].ID, occurrences[1].ID, occurrences[2].ID}, suite.cancelledEventBookingIDs())
+}
+
+func (suite *BookingServiceTestSuite) TestUpdateBookingStatus_SeriesScopeRequiresSeries() {
+	t := suite.T()
+	startTime := time.Now().Add(24 * time.Hour)
+	booking := models.Booking{
+		BusinessID: "biz_series", ServiceID: "svc_series", CustomerID: "cust_single",
+		StartTime: startTime, EndTime: startTime.Add(time.Hour), Status: models.BookingStatusConfirmed,
+	}
+	suite.DB.Create(&booking)
+
+	_, err := suite.BookingService.UpdateBookingStatus(context.Background(), booking.ID, service.UpdateBookingStatusRequest{
+		Status: models.BookingStatusCancelled, Scope: models.CancellationScopeSeries, CanManage: managesSeriesBusiness,
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "not part of a series")
+	}
+	assert.Empty(t, suite.cancelledEventBookingIDs())
+}
+
+func (suite *BookingServiceTestSuite) TestUpdateBookingStatus_SeriesScopeRequiresBusinessOrCustomer() {
+	t := suite.T()
+	occurrences := suite.seedSeries("series_denied")
+	notManaged := func(string) bool { return false }
+
+	for _, req := range []service.UpdateBookingStatusRequest{
+		{Status: models.BookingStatusCancelled, Scope: models.CancellationScopeSeries},                                                    // System change
+		{Status: models.BookingStatusCancelled, Scope: models.CancellationScopeSeries, ChangedBy: "cust_other", CanManage: notManaged},    // Someone else
+		{Status: models.BookingStatusCancelled, Scope: models.CancellationScopeFollowing, ChangedBy: "cust_other", CanManage: notManaged}, // Someone else
+	} {
+		_, err := suite.BookingService.UpdateBookingStatus(context.Background(), occurrences[0].ID, req)
+		assert.ErrorIs(t, err, service.ErrSeriesCancellationDenied)
+	}
+	assert.Empty(t, suite.cancelledEventBookingIDs())
+
+	// The series' own customer may cancel it
+	_, err := suite.BookingService.UpdateBookingStatus(context.Background(), occurrences[2].ID, service.UpdateBookingStatusRequest{
+		Status: models.BookingStatusCancelled, Scope: models.CancellationScopeFollowing, ChangedBy: "cust_series",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.BookingStatus{
+		models.BookingStatusConfirmed, models.BookingStatusConfirmed, models.BookingStatusCancelled, models.BookingStatusCancelled,
+	}, suite.seriesStatuses(occurrences))
+}
+
+func (suite *BookingServiceTestSuite) TestUpdateBookingStatus_PassesCustomerLanguage() {
+	t := suite.T()
+	ctx := context.Background()
+	startTime := time.Now().Add(48 * time.Hour)
+	booking := models.Booking{
+		ID: "550e8400-e29b-41d4-a716-44665544000c", BusinessID: "biz_lang", ServiceID: "svc_lang", CustomerID: "cust_lang",
+		StartTime: startTime, EndTime: startTime.Add(60 * time.Minute), Status: models.BookingStatusPendingPayment,
+		CustomerLanguage: "es",
+	}
+	suite.DB.Create(&booking)
+
+	_, err := suite.BookingService.UpdateBookingStatus(ctx, booking.ID, service.UpdateBookingStatusRequest{Status: models.BookingStatusConfirmed})
+	assert.NoError(t, err)
+
+	// Customer confirmation and reminder use the customer's language, the business copy the default
+	assert.Len(t, suite.MockNotifier.SentNotifications, 2)
+	assert.Equal(t, "es", suite.MockNotifier.SentNotifications[0].TemplateData["language"])
+	assert.Equal(t, models.DefaultLanguage, suite.MockNotifier.SentNotifications[1].TemplateData["language"])
+	if assert.Len(t, suite.MockNotifier.ScheduledNotifications, 1) {
+		assert.Equal(t, "es", suite.MockNotifier.ScheduledNotifications[0].TemplateData["language"])
+	}
+}
+
+func (suite *BookingServiceTestSuite) TestUpdateBookingStatus_StoredLanguageWinsOverRequest() {
+	t := suite.T()
+	ctx := context.Background()
+	suite.DB.Create(&models.CustomerPreference{CustomerID: "cust_lang_pref", Email: "pref-lang@example.com", Language: "de", EmailNotifications: true})
+
+	startTime := time.Now().Add(48 * time.Hour)
+	booking := models.Booking{
+		ID: "550e8400-e29b-41d4-a716-446655440010", BusinessID: "biz_lang_pref", ServiceID: "svc_lang_pref", CustomerID: "cust_lang_pref",
+		StartTime: startTime, EndTime: startTime.Add(60 * time.Minute), Status: models.BookingStatusPendingPayment,
+		CustomerLanguage: "es", // Sent with the booking request, older than the stored preference
+	}
+	suite.DB.Create(&booking)
+
+	_, err := suite.BookingService.UpdateBookingStatus(ctx, booking.ID, service.UpdateBookingStatusRequest{Status: models.BookingStatusConfirmed})
+	assert.NoError(t, err)
+
+	if assert.NotEmpty(t, suite.MockNotifier.SentNotifications) {
+		assert.Equal(t, "pref-lang@example.com", suite.MockNotifier.SentNotifications[0].RecipientEmail)
+		assert.Equal(t, "de", suite.MockNotifier.SentNotifications[0].TemplateData["language"])
+	}
+	if assert.Len(t, suite.MockNotifier.ScheduledNotifications, 1) {
+		assert.Equal(t, "de", suite.MockNotifier.ScheduledNotifications[0].TemplateData["language"])
+	}
+}
+
+func (suite *BookingServiceTestSuite) TestUpdateBookingStatus_DefaultsLanguageToEnglish() {
+	t := suite.T()
+	ctx := context.Background()
+	startTime := time.Now().Add(4 * time.Hour)
+	booking := models.Booking{
+		ID: "550e8400-e29b-41d4-a716-44665544000d", BusinessID: "biz_lang_default", ServiceID: "svc_lang_default", CustomerID: "cust_lang_default",
+		StartTime: startTime, EndTime: startTime.Add(60 * time.Minute), Status: models.BookingStatusConfirmed,
+	}
+	suite.DB.Create(&booking)
+
+	_, err := suite.BookingService.UpdateBookingStatus(ctx, booking.ID, service.UpdateBookingStatusRequest{Status: models.BookingStatusCancelled})
+	assert.NoError(t, err)
+
+	if assert.Len(t, suite.MockNotifier.SentNotifications, 1) {
+		assert.Equal(t, "en", suite.MockNotifier.SentNotifications[0].TemplateData["language"])
+	}
+}
+
+func (suite *BookingServiceTestSuite) TestUpdateBookingStatus_EmailNotificationsDisabled() {
+	t := suite.T()
+	ctx := context.Background()
+	suite.DB.Create(&models.CustomerPreference{CustomerID: "cust_quiet", Email: "quiet@example.com", Language: "en"}) // EmailNotifications false
+
+	startTime := time.Now().Add(48 * time.Hour)
+	booking := models.Booking{
+		ID: "550e8400-e29b-41d4-a716-44665544000e", BusinessID: "biz_quiet", ServiceID: "svc_quiet", CustomerID: "cust_quiet",
+		StartTime: startTime, EndTime: startTime.Add(60 * time.Minute), Status: models.BookingStatusPendingPayment,
+	}
+	suite.DB.Create(&booking)
+
+	_, err := suite.BookingService.UpdateBookingStatus(ctx, booking.ID, service.UpdateBookingStatusRequest{Status: models.BookingStatusConfirmed})
+	assert.NoError(t, err)
+
+	// Only the business copy is sent; the customer confirmation and reminder are suppressed
+	if assert.Len(t, suite.MockNotifier.SentNotifications, 1) {
+		assert.Equal(t, "business@example.com", suite.MockNotifier.SentNotifications[0].RecipientEmail)
+	}
+	assert.Empty(t, suite.MockNotifier.ScheduledNotifications)
+}
+
+func (suite *BookingServiceTestSuite) TestUpdateBookingStatus_UsesStoredCustomerEmail() {
+	t := suite.T()
+	ctx := context.Background()
+	suite.DB.Create(&models.CustomerPreference{CustomerID: "cust_pref", Email: "pref@example.com", Language: "en", EmailNotifications: true})
+
+	startTime := time.Now().Add(5 * time.Hour)
+	booking := models.Booking{
+		ID: "550e8400-e29b-41d4-a716-44665544000f", BusinessID: "biz_pref", ServiceID: "svc_pref", CustomerID: "cust_pref",
+		StartTime: startTime, EndTime: startTime.Add(60 * time.Minute), Status: models.BookingStatusConfirmed,
+	}
+	suite.DB.Create(&booking)
+
+	_, err := suite.BookingService.UpdateBookingStatus(ctx, booking.ID, service.UpdateBookingStatusRequest{Status: models.BookingStatusCancelled})
+	assert.NoError(t, err)
+
+	if assert.Len(t, suite.MockNotifier.SentNotifications, 1) {
+		assert.Equal(t, "pref@example.com", suite.MockNotifier.SentNotifications[0].RecipientEmail)
+	}
+}
+
+func (suite *BookingServiceTestSuite) TestUpdateBookingStatus_RecordsHistory() {
+	t := suite.T()
+	ctx := context.Background()
+	startTime := time.Now().Add(3 * time.Hour)
+	booking := models.Booking{
+		ID: "550e8400-e29b-41d4-a716-44665544000b", BusinessID: "biz_history", ServiceID: "svc_history", CustomerID: "cust_history",
+		StartTime: startTime, EndTime: startTime.Add(60 * time.Minute), Status: models.BookingStatusPendingPayment,
+	}
+	suite.DB.Create(&booking)
+
+	_, err := suite.BookingService.UpdateBookingStatus(ctx, booking.ID, service.UpdateBookingStatusRequest{
+		Status: models.BookingStatusConfirmed, ChangedBy: "owner_history",
+	})
+	assert.NoError(t, err)
+
+	reason := "Customer requested cancellation"
+	_, err = suite.BookingService.UpdateBookingStatus(ctx, booking.ID, service.UpdateBookingStatusRequest{
+		Status: models.BookingStatusCancelled, ChangedBy: "cust_history", Reason: &reason,
+	})
+	assert.NoError(t, err)
+
+	history, err := suite.BookingService.GetBookingStatusHistory(ctx, booking.ID, func(string) bool { return true })
+	assert.NoError(t, err)
+	assert.Len(t, history, 2)
+
+	assert.Equal(t, models.BookingStatusPendingPayment, history[0].FromStatus)
+	assert.Equal(t, models.BookingStatusConfirmed, history[0].ToStatus)
+	assert.Equal(t, "owner_history", history[0].ChangedBy)
+	assert.Nil(t, history[0].Reason)
+
+	assert.Equal(t, models.BookingStatusConfirmed, history[1].FromStatus)
+	assert.Equal(t, models.BookingStatusCancelled, history[1].ToStatus)
+	assert.Equal(t, "cust_history", history[1].ChangedBy)
+	if assert.NotNil(t, history[1].Reason) {
+		assert.Equal(t, reason, *history[1].Reason)
+	}
+	assert.False(t, history[1].CreatedAt.Before(history[0].CreatedAt))
+}
+
+func (suite *BookingServiceTestSuite) TestGetBookingStatusHistory_NotFound() {
+	_, err := suite.BookingService.GetBookingStatusHistory(context.Background(), "550e8400-e29b-41d4-a716-4466554400ff", func(string) bool { return true })
+	assert.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "not found")
+}
+
+// --- ListBookings Tests ---
+func (suite *BookingServiceTestSuite) TestListBookingsForCustomer() {
+	t := suite.T()
+	ctx := context.Background()
+	// Seed bookings for different customers
+	suite.DB.Create(&models.Booking{ID: "550e8400-e29b-41d4-a716-446655440005", CustomerID: "cust1_list", BusinessID: "biz_c_list", ServiceID: "svc_c_list", StartTime: time.Now(), EndTime: time.Now().Add(time.Hour), Status: models.BookingStatusConfirmed})
+	suite.DB.Create(&models.Booking{ID: "550e8400-e29b-41d4-a716-446655440006", CustomerID: "cust1_list", BusinessID: "biz_c_list", ServiceID: "svc_c_list", StartTime: time.Now().Add(2 * time.Hour), EndTime: time.Now().Add(3 * time.Hour), Status: models.BookingStatusConfirmed})
+	suite.DB.Create(&models.Booking{ID: "550e8400-e29b-41d4-a716-446655440007", CustomerID: "cust2_list", BusinessID: "biz_c_list", ServiceID: "svc_c_list", StartTime: time.Now(), EndTime: time.Now().Add(time.Hour), Status: models.BookingStatusConfirmed})
+
+	bookings, total, err := suite.BookingService.ListBookingsForCustomer(ctx, "cust1_list", models.BookingTimeframeAll, 10, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Len(t, bookings, 2)
+
+	bookingsPage2, total2, err := suite.BookingService.ListBookingsForCustomer(ctx, "cust1_list", models.BookingTimeframeAll, 1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), total2)
+	assert.Len(t, bookingsPage2, 1)
+
+}
+
+func (suite *BookingServiceTestSuite) TestListBookingsForCustomer_SplitsUpcomingAndPast() {
+	t := suite.T()
+	ctx := context.Background()
+	now, _ := time.Parse(time.RFC3339, "2024-06-10T12:00:00Z")
+	bookingService := service.NewBookingService(suite.BookingRepo, nil, suite.AvailabilityRepo, repository.NewCustomerPreferenceRepository(suite.DB), suite.OutboxRelay, suite.MockNatsPublisher, &MockNotificationClient{}, clock.NewFake(now), suite.TestLogger)
+
+	seed := func(id string, start time.Time) {
+		suite.DB.Create(&models.Booking{ID: id, CustomerID: "cust_when", BusinessID: "biz_when", ServiceID: "svc_when", StartTime: start, EndTime: start.Add(time.Hour), Status: models.BookingStatusConfirmed})
+	}
+	seed("550e8400-e29b-41d4-a716-446655440a01", now.Add(-48*time.Hour))   // Long past
+	seed("550e8400-e29b-41d4-a716-446655440a02", now.Add(-time.Hour))      // Ended exactly now
+	seed("550e8400-e29b-41d4-a716-446655440a03", now.Add(-30*time.Minute)) // In progress
+	seed("550e8400-e29b-41d4-a716-446655440a04", now.Add(72*time.Hour))    // Later
+	seed("550e8400-e29b-41d4-a716-446655440a05", now.Add(24*time.Hour))    // Next
+	suite.DB.Create(&models.Booking{ID: "550e8400-e29b-41d4-a716-446655440a06", CustomerID: "cust_other", BusinessID: "biz_when", ServiceID: "svc_when", StartTime: now.Add(time.Hour), EndTime: now.Add(2 * time.Hour), Status: models.BookingStatusConfirmed})
+
+	ids := func(bookings []models.Booking) []string {
+		var result []string
+		for _, b := range bookings {
+			result = append(result, b.ID)
+		}
+		return result
+	}
+
+	upcoming, total, err := bookingService.ListBookingsForCustomer(ctx, "cust_when", models.BookingTimeframeUpcoming, 10, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	assert.Equal(t, []string{
+		"550e8400-e29b-41d4-a716-446655440a03",
+		"550e8400-e29b-41d4-a716-446655440a05",
+		"550e8400-e29b-41d4-a716-446655440a04",
+	}, ids(upcoming), "upcoming bookings should be soonest first, including the one in progress")
+
+	past, total, err := bookingService.ListBookingsForCustomer(ctx, "cust_when", models.BookingTimeframePast, 10, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, []string{
+		"550e8400-e29b-41d4-a716-446655440a02",
+		"550e8400-e29b-41d4-a716-446655440a01",
+	}, ids(past), "past bookings should be latest first")
+
+	firstUpcoming, total, err := bookingService.ListBookingsForCustomer(ctx, "cust_when", models.BookingTimeframeUpcoming, 1, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	assert.Equal(t, []string{"550e8400-e29b-41d4-a716-446655440a03"}, ids(firstUpcoming))
+}
+
+func (suite *BookingServiceTestSuite) TestListBookingsForBusiness() {
+	t := suite.T()
+	ctx := context.Background()
+	// Seed bookings for different businesses
+	suite.DB.Create(&models.Booking{ID: "550e8400-e29b-41d4-a716-446655440008", BusinessID: "biz1_list", CustomerID: "cust_b_list", ServiceID: "svc_b_list", StartTime: time.Now(), EndTime: time.Now().Add(time.Hour), Status: models.BookingStatusConfirmed})
+	suite.DB.Create(&models.Booking{ID: "550e8400-e29b-41d4-a716-446655440009", BusinessID: "biz1_list", CustomerID: "cust_b_list", ServiceID: "svc_b_list", StartTime: time.Now().Add(2 * time.Hour), EndTime: time.Now().Add(3 * time.Hour), Status: models.BookingStatusPendingPayment})
+	suite.DB.Create(&models.Booking{ID: "550e8400-e29b-41d4-a716-44665544000a", BusinessID: "biz2_list", CustomerID: "cust_b_list", ServiceID: "svc_b_list", StartTime: time.Now(), EndTime: time.Now().Add(time.Hour), Status: models.BookingStatusConfirmed})
+
+	bookings, total, err := suite.BookingService.ListBookingsForBusiness(ctx, "biz1_list", nil, "", 10, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Len(t, bookings, 2)
+}
+
+func (suite *BookingServiceTestSuite) TestGetBookingByID_MissingBookingReturnsSentinel() {
+	t := suite.T()
+	missingID := "550e8400-e29b-41d4-a716-446655440c99"
+
+	booking, err := suite.BookingRepo.GetBookingByID(context.Background(), missingID)
+	assert.Nil(t, booking)
+	assert.ErrorIs(t, err, repository.ErrBookingNotFound)
+
+	_, err = suite.BookingService.GetBookingDetails(context.Background(), missingID)
+	assert.ErrorIs(t, err, repository.ErrBookingNotFound, "Services pass the sentinel through")
+
+	// A failed query is not a missing booking
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = suite.BookingRepo.GetBookingByID(ctx, missingID)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, repository.ErrBookingNotFound)
+}
+
+func (suite *BookingServiceTestSuite) TestBookingResponsesIncludeServiceDisplay() {
+	t := suite.T()
+	ctx := context.Background()
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_color", BusinessID: "biz_color", Name: "Colour Treatment", DurationMinutes: 90, Currency: "USD", IsActive: true, Color: "#4F46E5", ShortLabel: "Colour"})
+	booking := models.Booking{
+		ID: "550e8400-e29b-41d4-a716-446655440c01", BusinessID: "biz_color", ServiceID: "svc_color", CustomerID: "cust_color",
+		StartTime: time.Now().Add(time.Hour), EndTime: time.Now().Add(150 * time.Minute), Status: models.BookingStatusConfirmed,
+	}
+	suite.DB.Create(&booking)
+
+	details, err := suite.BookingService.GetBookingDetails(ctx, booking.ID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, details) {
+		assert.Equal(t, "Colour Treatment", details.ServiceName)
+		assert.Equal(t, "#4F46E5", details.ServiceColor)
+		assert.Equal(t, "Colour", details.ServiceShortLabel)
+	}
+
+	bookings, _, err := suite.BookingService.ListBookingsForBusiness(ctx, "biz_color", nil, "", 10, 0)
+	assert.NoError(t, err)
+	if assert.Len(t, bookings, 1) {
+		assert.Equal(t, "#4F46E5", bookings[0].ServiceColor)
+	}
+}
+
+func (suite *BookingServiceTestSuite) TestListBookingsForBusiness_FiltersByStatus() {
+	t := suite.T()
+	ctx := context.Background()
+	suite.DB.Create(&models.Booking{BusinessID: "biz_status_list", CustomerID: "cust1", ServiceID: "svc1", StartTime: time.Now(), EndTime: time.Now().Add(time.Hour), Status: models.BookingStatusCompleted})
+	suite.DB.Create(&models.Booking{BusinessID: "biz_status_list", CustomerID: "cust2", ServiceID: "svc1", StartTime: time.Now(), EndTime: time.Now().Add(time.Hour), Status: models.BookingStatusCancelled})
+
+	bookings, total, err := suite.BookingService.ListBookingsForBusiness(ctx, "biz_status_list", []models.BookingStatus{models.BookingStatusCompleted}, "", 10, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	if assert.Len(t, bookings, 1) {
+		assert.Equal(t, models.BookingStatusCompleted, bookings[0].Status)
+	}
+}
+
+func (suite *BookingServiceTestSuite) TestListBookingsForBusiness_SearchesCustomerAndService() {
+	t := suite.T()
+	ctx := context.Background()
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_search_cut", BusinessID: "biz_search", Name: "Haircut", DurationMinutes: 30, IsActive: true})
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_search_dye", BusinessID: "biz_search", Name: "Colour", DurationMinutes: 30, IsActive: true})
+	suite.DB.Create(&models.CustomerPreference{CustomerID: "cust_search_anna", Email: "anna@example.com", Name: "Anna Smith"})
+	suite.DB.Create(&models.CustomerPreference{CustomerID: "cust_search_bob", Email: "bob@example.com", Name: "Bob Jones"})
+	guestName, guestEmail := "Joanna Guest", "guest@example.com"
+	start := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	newBooking := func(businessID, customerID, serviceID string) *models.Booking {
+		return &models.Booking{BusinessID: businessID, CustomerID: customerID, ServiceID: serviceID, StartTime: start, EndTime: start.Add(30 * time.Minute), Status: models.BookingStatusConfirmed}
+	}
+	anna := newBooking("biz_search", "cust_search_anna", "svc_search_dye")
+	bob := newBooking("biz_search", "cust_search_bob", "svc_search_cut")
+	guest := newBooking("biz_search", "", "svc_search_dye")
+	guest.GuestName, guest.GuestEmail = &guestName, &guestEmail
+	otherBusiness := newBooking("biz_search_other", "cust_search_anna", "svc_other")
+	for _, b := range []*models.Booking{anna, bob, guest, otherBusiness} {
+		suite.DB.Create(b)
+	}
+
+	search := func(q string) []string {
+		bookings, total, err := suite.BookingService.ListBookingsForBusiness(ctx, "biz_search", nil, q, 10, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(len(bookings)), total)
+		var ids []string
+		for _, b := range bookings {
+			ids = append(ids, b.ID)
+		}
+		return ids
+	}
+
+	assert.ElementsMatch(t, []string{anna.ID, guest.ID}, search("ANN"), "Customer and guest names match case-insensitively")
+	assert.ElementsMatch(t, []string{bob.ID}, search("bob@"), "Customer emails match")
+	assert.ElementsMatch(t, []string{bob.ID}, search("hairc"), "Service names match")
+	assert.Empty(t, search("%"), "Wildcards are matched literally")
+	assert.Len(t, search(""), 3)
+}
+
+// --- Confirmation Policy Tests ---
+func (suite *BookingServiceTestSuite) TestCreateBooking_PaymentRequiredStaysPending() {
+	t := suite.T()
+	ctx := context.Background()
+	requiresPayment := true
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_paid", BusinessID: "biz_policy", Name: "Paid", DurationMinutes: 30, IsActive: true, RequiresPayment: &requiresPayment})
+	suite.openAllWeek("biz_policy")
+
+	startTime, _ := time.Parse(time.RFC3339, "2030-04-08T10:00:00Z")
+	booking, err := suite.BookingService.CreateBooking(ctx, service.CreateBookingRequest{
+		BusinessID: "biz_policy", ServiceID: "svc_paid", CustomerID: "cust_policy", StartTime: startTime,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, models.BookingStatusPendingPayment, booking.Status)
+
+	if assert.Len(t, suite.MockNatsPublisher.PublishedEvents, 1) {
+		assert.Equal(t, events.BookingRequestedEvent, suite.MockNatsPublisher.PublishedEvents[0].Subject)
+	}
+}
+
+func (suite *BookingServiceTestSuite) TestCreateBooking_NoPaymentRequiredConfirmsImmediately() {
+	t := suite.T()
+	ctx := context.Background()
+	requiresPayment := false
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_free", BusinessID: "biz_policy", Name: "Free", DurationMinutes: 30, IsActive: true, RequiresPayment: &requiresPayment})
+	suite.openAllWeek("biz_policy")
+
+	startTime, _ := time.Parse(time.RFC3339, "2030-04-08T11:00:00Z")
+	booking, err := suite.BookingService.CreateBooking(ctx, service.CreateBookingRequest{
+		BusinessID: "biz_policy", ServiceID: "svc_free", CustomerID: "cust_policy", StartTime: startTime,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, models.BookingStatusConfirmed, booking.Status)
+
+	var dbBooking models.Booking
+	assert.NoError(t, suite.DB.First(&dbBooking, "id = ?", booking.ID).Error)
+	assert.Equal(t, models.BookingStatusConfirmed, dbBooking.Status)
+
+	if assert.Len(t, suite.MockNatsPublisher.PublishedEvents, 2) {
+		assert.Equal(t, events.BookingConfirmedEvent, suite.MockNatsPublisher.PublishedEvents[0].Subject)
+		assert.Equal(t, events.SlotReservedEvent, suite.MockNatsPublisher.PublishedEvents[1].Subject)
+		for _, event := range suite.MockNatsPublisher.PublishedEvents {
+			eventData, ok := event.Data.(map[string]interface{})
+			assert.True(t, ok)
+			assert.Equal(t, booking.ID, eventData["bookingId"])
+		}
+	}
+
+	outboxEvents, err := suite.OutboxRepo.GetEventsByAggregateID(ctx, booking.ID)
+	assert.NoError(t, err)
+	assert.Len(t, outboxEvents, 2)
+}
+
+func (suite *BookingServiceTestSuite) TestCreateBooking_AutoConfirmedSendsConfirmationAndReminder() {
+	t := suite.T()
+	ctx := context.Background()
+	requiresPayment := false
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_auto", BusinessID: "biz_auto", Name: "Consultation", DurationMinutes: 30, IsActive: true, RequiresPayment: &requiresPayment})
+	suite.DB.Create(&models.CustomerPreference{CustomerID: "cust_auto", Email: "auto@example.com", Language: "en", EmailNotifications: true})
+	suite.openAllWeek("biz_auto")
+
+	startTime, _ := time.Parse(time.RFC3339, "2030-04-03T10:00:00Z")
+	booking, err := suite.BookingService.CreateBooking(ctx, service.CreateBookingRequest{
+		BusinessID: "biz_auto", ServiceID: "svc_auto", CustomerID: "cust_auto", StartTime: startTime,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, models.BookingStatusConfirmed, booking.Status)
+
+	// Same side effects as a PENDING_PAYMENT booking confirmed later: customer and business copies plus the reminder
+	if assert.Len(t, suite.MockNotifier.SentNotifications, 2) {
+		assert.Equal(t, "booking_confirmation", suite.MockNotifier.SentNotifications[0].Type)
+		assert.Equal(t, "auto@example.com", suite.MockNotifier.SentNotifications[0].RecipientEmail)
+		assert.Equal(t, "business@example.com", suite.MockNotifier.SentNotifications[1].RecipientEmail)
+	}
+	if assert.Len(t, suite.MockNotifier.ScheduledNotifications, 1) {
+		reminder := suite.MockNotifier.ScheduledNotifications[0]
+		assert.Equal(t, "booking_reminder", reminder.Type)
+		assert.Equal(t, "auto@example.com", reminder.RecipientEmail)
+		assert.Equal(t, booking.ID, reminder.BookingID)
+		assert.Equal(t, startTime.Add(-24*time.Hour), reminder.ScheduledFor.UTC())
+	}
+
+	// A dry run confirms nothing, so it sends nothing
+	suite.MockNotifier.Reset()
+	_, err = suite.BookingService.CreateBooking(ctx, service.CreateBookingRequest{
+		BusinessID: "biz_auto", ServiceID: "svc_auto", CustomerID: "cust_auto", StartTime: startTime.Add(time.Hour), DryRun: true,
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, suite.MockNotifier.SentNotifications)
+	assert.Empty(t, suite.MockNotifier.ScheduledNotifications)
+}
+
+// --- Import Tests ---
+func (suite *BookingServiceTestSuite) TestImportBookings_ReportsPerRowOutcomes() {
+	t := suite.T()
+	ctx := context.Background()
+	now, _ := time.Parse(time.RFC3339, "2024-06-10T12:00:00Z")
+	bookingService := service.NewBookingService(suite.BookingRepo, nil, suite.AvailabilityRepo, repository.NewCustomerPreferenceRepository(suite.DB), suite.OutboxRelay, suite.MockNatsPublisher, suite.MockNotifier, clock.NewFake(now), suite.TestLogger)
+
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_import", BusinessID: "biz_import", Name: "Import Service", DurationMinutes: 60, Price: 2500, Currency: "EUR", IsActive: true})
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_other_biz", BusinessID: "biz_other", Name: "Other Service", DurationMinutes: 60, IsActive: true})
+	past, _ := time.Parse(time.RFC3339, "2024-05-06T10:00:00Z")
+	future, _ := time.Parse(time.RFC3339, "2024-06-17T10:00:00Z")
+	// An existing booking overlapping the historical rows below
+	suite.DB.Create(&models.Booking{BusinessID: "biz_import", ServiceID: "svc_import", CustomerID: "cust_existing", StartTime: past, EndTime: past.Add(time.Hour), Status: models.BookingStatusConfirmed})
+
+	earlyEnd := future.Add(-time.Minute)
+	guest := &service.GuestContact{Name: "Old Guest", Email: "old@example.com"}
+	rows := []service.ImportBookingRow{
+		{ServiceID: "svc_import", CustomerID: "cust_a", StartTime: future},                                                            // 0: upcoming, imported
+		{ServiceID: "svc_missing", CustomerID: "cust_a", StartTime: future.Add(4 * time.Hour)},                                        // 1: unknown service
+		{ServiceID: "svc_other_biz", CustomerID: "cust_a", StartTime: future.Add(4 * time.Hour)},                                      // 2: another business's service
+		{ServiceID: "svc_import", CustomerID: "cust_a", StartTime: future, EndTime: &earlyEnd},                                        // 3: ends before it starts
+		{ServiceID: "svc_import", CustomerID: "cust_b", StartTime: future.Add(30 * time.Minute)},                                      // 4: overlaps row 0
+		{ServiceID: "svc_import", Guest: guest, StartTime: past, Status: models.BookingStatusCompleted},                               // 5: completed in the past
+		{ServiceID: "svc_import", CustomerID: "cust_c", StartTime: past.Add(30 * time.Minute)},                                        // 6: overlaps the existing booking, but historical
+		{ServiceID: "svc_import", CustomerID: "cust_d", StartTime: future.Add(24 * time.Hour), Status: models.BookingStatusCompleted}, // 7: completed before it happened
+		{ServiceID: "svc_import", StartTime: future.Add(48 * time.Hour)},                                                              // 8: no customer or guest
+	}
+
+	results, err := bookingService.ImportBookings(ctx, service.ImportBookingsRequest{BusinessID: "biz_import", Rows: rows, SkipHistoricalConflictChecks: true})
+	assert.NoError(t, err)
+	if !assert.Len(t, results, len(rows)) {
+		return
+	}
+	for i, result := range results {
+		assert.Equal(t, i, result.Row)
+	}
+	for _, i := range []int{0, 5, 6} {
+		assert.True(t, results[i].Success, "row %d should be imported: %s", i, results[i].Error)
+		assert.NotEmpty(t, results[i].BookingID)
+	}
+	assert.Contains(t, results[1].Error, "not found")
+	assert.Contains(t, results[2].Error, "not found")
+	assert.Contains(t, results[3].Error, "endTime must be after startTime")
+	assert.Contains(t, results[4].Error, "conflicts with another imported booking")
+	assert.Contains(t, results[7].Error, "cannot be COMPLETED")
+	assert.Contains(t, results[8].Error, "customerId or guest")
+
+	var imported models.Booking
+	assert.NoError(t, suite.DB.First(&imported, "id = ?", results[0].BookingID).Error)
+	assert.Equal(t, models.BookingStatusConfirmed, imported.Status)
+	assert.Equal(t, future.Add(time.Hour), imported.EndTime.UTC())
+	if assert.NotNil(t, imported.TotalAmount) {
+		assert.Equal(t, int64(2500), *imported.TotalAmount)
+	}
+	var guestBooking models.Booking
+	assert.NoError(t, suite.DB.First(&guestBooking, "id = ?", results[5].BookingID).Error)
+	assert.True(t, guestBooking.IsGuest())
+
+	var count int64
+	suite.DB.Model(&models.Booking{}).Where("business_id = ?", "biz_import").Count(&count)
+	assert.Equal(t, int64(4), count, "The existing booking plus three imported")
+	assert.Empty(t, suite.MockNatsPublisher.PublishedEvents, "Imported bookings publish no events")
+	assert.Empty(t, suite.MockNotifier.SentNotifications)
+
+	// Without the flag historical rows are conflict-checked like upcoming ones
+	results, err = bookingService.ImportBookings(ctx, service.ImportBookingsRequest{BusinessID: "biz_import", Rows: []service.ImportBookingRow{
+		{ServiceID: "svc_import", CustomerID: "cust_e", StartTime: past.Add(15 * time.Minute)},
+	}})
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.False(t, results[0].Success)
+		assert.Contains(t, results[0].Error, "conflicts with existing booking")
+	}
+}
+
+func (suite *BookingServiceTestSuite) TestImportBookings_KeepsBuffersClear() {
+	t := suite.T()
+	ctx := context.Background()
+	now, _ := time.Parse(time.RFC3339, "2024-06-10T12:00:00Z")
+	bookingService := service.NewBookingService(suite.BookingRepo, nil, suite.AvailabilityRepo, repository.NewCustomerPreferenceRepository(suite.DB), suite.OutboxRelay, suite.MockNatsPublisher, suite.MockNotifier, clock.NewFake(now), suite.TestLogger)
+
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_import_buf", BusinessID: "biz_import_buf", Name: "Import Service", DurationMinutes: 60, IsActive: true})
+	suite.DB.Create(&models.AvailabilityRule{BusinessID: "biz_import_buf", DayOfWeek: models.Monday, StartTime: "08:00", EndTime: "20:00", BufferAfterMinutes: 15})
+	existing, _ := time.Parse(time.RFC3339, "2024-06-17T10:00:00Z") // A Monday
+	suite.DB.Create(&models.Booking{BusinessID: "biz_import_buf", ServiceID: "svc_import_buf", CustomerID: "cust_existing", StartTime: existing, EndTime: existing.Add(time.Hour), Status: models.BookingStatusConfirmed})
+
+	results, err := bookingService.ImportBookings(ctx, service.ImportBookingsRequest{BusinessID: "biz_import_buf", Rows: []service.ImportBookingRow{
+		{ServiceID: "svc_import_buf", CustomerID: "cust_a", StartTime: existing.Add(65 * time.Minute)},  // 0: inside the existing booking's buffer
+		{ServiceID: "svc_import_buf", CustomerID: "cust_b", StartTime: existing.Add(3 * time.Hour)},     // 1: clear, imported
+		{ServiceID: "svc_import_buf", CustomerID: "cust_c", StartTime: existing.Add(250 * time.Minute)}, // 2: inside row 1's buffer
+	}})
+	assert.NoError(t, err)
+	if assert.Len(t, results, 3) {
+		assert.Contains(t, results[0].Error, "conflicts with existing booking")
+		assert.True(t, results[1].Success, results[1].Error)
+		assert.Contains(t, results[2].Error, "conflicts with another imported booking")
+	}
+}
+
+// --- Revenue Tests ---
+func (suite *BookingServiceTestSuite) TestCreateBooking_SnapshotsServicePrice() {
+	t := suite.T()
+	ctx := context.Background()
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_price", BusinessID: "biz_price", Name: "Haircut", DurationMinutes: 30, Price: 2500, Currency: "EUR", IsActive: true})
+	suite.openAllWeek("biz_price")
+
+	startTime, _ := time.Parse(time.RFC3339, "2030-04-05T10:00:00Z")
+	booking, err := suite.BookingService.CreateBooking(ctx, service.CreateBookingRequest{
+		BusinessID: "biz_price", ServiceID: "svc_price", CustomerID: "cust_price", StartTime: startTime,
+	})
+	assert.NoError(t, err)
+
+	// Changing the service price afterwards must not change the booking
+	suite.DB.Model(&models.ServiceDefinition{}).Where("id = ?", "svc_price").Update("price", 4000)
+
+	var dbBooking models.Booking
+	assert.NoError(t, suite.DB.First(&dbBooking, "id = ?", booking.ID).Error)
+	if assert.NotNil(t, dbBooking.TotalAmount) {
+		assert.Equal(t, int64(2500), *dbBooking.TotalAmount)
+	}
+	assert.Equal(t, "EUR", dbBooking.Currency)
+}
+
+func (suite *BookingServiceTestSuite) TestRevenueSummary() {
+	t := suite.T()
+	ctx := context.Background()
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_rev_cut", BusinessID: "biz_rev", Name: "Haircut", DurationMinutes: 30, Price: 2000, Currency: "USD", IsActive: true})
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_rev_color", BusinessID: "biz_rev", Name: "Colour", DurationMinutes: 60, Price: 5000, Currency: "EUR", IsActive: true})
+
+	weekStart, _ := time.Parse(time.RFC3339, "2024-05-06T00:00:00Z")
+	seed := func(serviceID, currency string, amount int64, status models.BookingStatus, start time.Time) {
+		suite.DB.Create(&models.Booking{
+			BusinessID: "biz_rev", ServiceID: serviceID, CustomerID: "cust_rev",
+			StartTime: start, EndTime: start.Add(30 * time.Minute), Status: status,
+			TotalAmount: &amount, Currency: currency,
+		})
+	}
+	seed("svc_rev_cut", "USD", 2000, models.BookingStatusCompleted, weekStart.Add(10*time.Hour))
+	seed("svc_rev_cut", "USD", 1800, models.BookingStatusCompleted, weekStart.Add(34*time.Hour)) // Price snapshot from before a change
+	seed("svc_rev_cut", "EUR", 1900, models.BookingStatusCompleted, weekStart.Add(58*time.Hour))
+	seed("svc_rev_color", "EUR", 5000, models.BookingStatusCompleted, weekStart.Add(60*time.Hour))
+	seed("svc_rev_cut", "USD", 2000, models.BookingStatusCancelled, weekStart.Add(12*time.Hour))
+	seed("svc_rev_cut", "USD", 2000, models.BookingStatusNoShow, weekStart.Add(14*time.Hour))
+	seed("svc_rev_cut", "USD", 2000, models.BookingStatusConfirmed, weekStart.Add(16*time.Hour))
+	seed("svc_rev_cut", "USD", 2000, models.BookingStatusCompleted, weekStart.Add(-2*time.Hour)) // Previous week
+
+	summary, err := suite.BookingService.RevenueSummary(ctx, "biz_rev", weekStart, weekStart.AddDate(0, 0, 7), "")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []models.CurrencyRevenue{
+		{Currency: "EUR", TotalAmount: 6900, BookingCount: 2},
+		{Currency: "USD", TotalAmount: 3800, BookingCount: 2},
+	}, summary.Totals)
+	assert.Equal(t, []models.ServiceRevenue{
+		{ServiceID: "svc_rev_color", ServiceName: "Colour", Currency: "EUR", TotalAmount: 5000, BookingCount: 1},
+		{ServiceID: "svc_rev_cut", ServiceName: "Haircut", Currency: "EUR", TotalAmount: 1900, BookingCount: 1},
+		{ServiceID: "svc_rev_cut", ServiceName: "Haircut", Currency: "USD", TotalAmount: 3800, BookingCount: 2},
+	}, summary.Services)
+
+	assert.Nil(t, summary.Converted)
+
+	_, err = suite.BookingService.RevenueSummary(ctx, "biz_rev", weekStart, weekStart, "")
+	assert.Error(t, err)
+}
+
+func (suite *BookingServiceTestSuite) TestRevenueSummary_ConvertsToTargetCurrency() {
+	t := suite.T()
+	ctx := context.Background()
+	weekStart, _ := time.Parse(time.RFC3339, "2024-05-06T00:00:00Z")
+	seed := func(currency string, amount int64, start time.Time) {
+		suite.DB.Create(&models.Booking{
+			BusinessID: "biz_fx", ServiceID: "svc_fx", CustomerID: "cust_fx",
+			StartTime: start, EndTime: start.Add(30 * time.Minute), Status: models.BookingStatusCompleted,
+			TotalAmount: &amount, Currency: currency,
+		})
+	}
+	seed("USD", 2000, weekStart.Add(10*time.Hour))
+	seed("USD", 1800, weekStart.Add(34*time.Hour))
+	seed("EUR", 6900, weekStart.Add(58*time.Hour))
+	seed("JPY", 1500, weekStart.Add(82*time.Hour))
+
+	_, err := suite.BookingService.RevenueSummary(ctx, "biz_fx", weekStart, weekStart.AddDate(0, 0, 7), "USD")
+	assert.ErrorIs(t, err, service.ErrNoExchangeRate, "Conversion needs a rate source")
+
+	suite.BookingService.SetExchangeRateSource(service.StaticExchangeRates{"USD": 1, "EUR": 1.1, "JPY": 0.0067})
+	defer suite.BookingService.SetExchangeRateSource(nil)
+
+	summary, err := suite.BookingService.RevenueSummary(ctx, "biz_fx", weekStart, weekStart.AddDate(0, 0, 7), "USD")
+	assert.NoError(t, err)
+	assert.Equal(t, []models.CurrencyRevenue{
+		{Currency: "EUR", TotalAmount: 6900, BookingCount: 1},
+		{Currency: "JPY", TotalAmount: 1500, BookingCount: 1},
+		{Currency: "USD", TotalAmount: 3800, BookingCount: 2},
+	}, summary.Totals, "The per-currency breakdown is kept")
+	if assert.NotNil(t, summary.Converted) {
+		assert.Equal(t, "USD", summary.Converted.Currency)
+		// 1500 yen, which has no minor unit, is 10.05 dollars
+		assert.Equal(t, int64(7590+1005+3800), summary.Converted.TotalAmount)
+		assert.Equal(t, int64(4), summary.Converted.BookingCount)
+		assert.Equal(t, map[string]float64{"EUR": 1.1, "JPY": 0.0067, "USD": 1}, summary.Converted.Rates)
+	}
+
+	_, err = suite.BookingService.RevenueSummary(ctx, "biz_fx", weekStart, weekStart.AddDate(0, 0, 7), "GBP")
+	assert.ErrorIs(t, err, service.ErrNoExchangeRate)
+}
+
+func (suite *BookingServiceTestSuite) TestDashboardStats() {
+	t := suite.T()
+	ctx := context.Background()
+	assert.NoError(t, suite.DB.Create(&models.Business{ID: "biz_dash", Name: "Dash Salon", Timezone: "America/New_York"}).Error)
+
+	// Tuesday 22:30 in New York, already Wednesday in UTC
+	now, _ := time.Parse(time.RFC3339, "2024-05-08T02:30:00Z")
+	bookingService := service.NewBookingService(suite.BookingRepo, nil, suite.AvailabilityRepo, repository.NewCustomerPreferenceRepository(suite.DB), suite.OutboxRelay, suite.MockNatsPublisher, suite.MockNotifier, clock.NewFake(now), suite.TestLogger)
+	seed := func(businessID string, status models.BookingStatus, startUTC string) {
+		start, _ := time.Parse(time.RFC3339, startUTC)
+		suite.DB.Create(&models.Booking{
+			BusinessID: businessID, ServiceID: "svc_dash", CustomerID: "cust_dash",
+			StartTime: start, EndTime: start.Add(30 * time.Minute), Status: status,
+		})
+	}
+	seed("biz_dash", models.BookingStatusConfirmed, "2024-05-07T14:00:00Z")      // Today
+	seed("biz_dash", models.BookingStatusPendingPayment, "2024-05-08T03:00:00Z") // Later today, awaiting payment
+	seed("biz_dash", models.BookingStatusPendingPayment, "2024-05-07T12:00:00Z") // Earlier today, no longer pending confirmation
+	seed("biz_dash", models.BookingStatusCancelled, "2024-05-07T16:00:00Z")      // Cancelled today
+	seed("biz_dash", models.BookingStatusCompleted, "2024-05-06T13:00:00Z")      // Monday
+	seed("biz_dash", models.BookingStatusConfirmed, "2024-05-08T14:00:00Z")      // Wednesday: "today" only in UTC
+	seed("biz_dash", models.BookingStatusPendingPayment, "2024-05-20T14:00:00Z") // A later week, awaiting payment
+	seed("biz_dash", models.BookingStatusConfirmed, "2024-05-06T03:00:00Z")      // Sunday 23:00 local, previous week
+	seed("biz_dash", models.BookingStatusCancelled, "2024-05-13T05:00:00Z")      // Next week
+	seed("biz_dash_other", models.BookingStatusConfirmed, "2024-05-07T14:00:00Z")
+
+	stats, err := bookingService.DashboardStats(ctx, "biz_dash")
+	assert.NoError(t, err)
+	if assert.NotNil(t, stats) {
+		assert.Equal(t, "America/New_York", stats.Timezone)
+		assert.Equal(t, "2024-05-07", stats.Date, "Today is measured in the business timezone")
+		assert.Equal(t, "2024-05-06", stats.WeekStart)
+		assert.Equal(t, models.DashboardCounts{
+			TodayBookings:        3,
+			WeekBookings:         5,
+			PendingConfirmations: 2,
+			Cancellations:        1,
+		}, stats.DashboardCounts)
+	}
+
+	// A business that never reported a timezone counts in UTC
+	stats, err = bookingService.DashboardStats(ctx, "biz_dash_other")
+	assert.NoError(t, err)
+	if assert.NotNil(t, stats) {
+		assert.Equal(t, "UTC", stats.Timezone)
+		assert.Equal(t, "2024-05-08", stats.Date)
+		assert.Equal(t, models.DashboardCounts{WeekBookings: 1}, stats.DashboardCounts)
+	}
+
+	_, err = bookingService.DashboardStats(ctx, "")
+	assert.Error(t, err)
+}
+
+func TestBookingServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(BookingServiceTestSuite))
+}
+
+// --- ResendConfirmation Tests ---
+func (suite *BookingServiceTestSuite) TestResendConfirmation_SendsAgainForConfirmedBooking() {
+	t := suite.T()
+	ctx := context.Background()
+	now, _ := time.Parse(time.RFC3339, "2024-04-01T09:00:00Z")
+	fakeClock := clock.NewFake(now)
+	notifier := &MockNotificationClient{}
+	bookingService := service.NewBookingService(
+		suite.BookingRepo,
+		nil,
+		suite.AvailabilityRepo,
+		repository.NewCustomerPreferenceRepository(suite.DB),
+		suite.OutboxRelay,
+		suite.MockNatsPublisher,
+		notifier,
+		fakeClock,
+		suite.TestLogger,
+	)
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_resend", BusinessID: "biz_resend", Name: "Resend Service", DurationMinutes: 30, IsActive: true})
+	suite.DB.Create(&models.CustomerPreference{CustomerID: "cust_resend", Email: "resend@example.com", EmailNotifications: true})
+	booking := models.Booking{
+		BusinessID: "biz_resend", ServiceID: "svc_resend", CustomerID: "cust_resend",
+		StartTime: now.Add(48 * time.Hour), EndTime: now.Add(48*time.Hour + 30*time.Minute), Status: models.BookingStatusConfirmed,
+	}
+	suite.DB.Create(&booking)
+
+	_, err := bookingService.ResendConfirmation(ctx, booking.ID, "cust_resend", "")
+	assert.NoError(t, err)
+	if assert.Len(t, notifier.SentNotifications, 1) {
+		assert.Equal(t, "booking_confirmation", notifier.SentNotifications[0].Type)
+		assert.Equal(t, "resend@example.com", notifier.SentNotifications[0].RecipientEmail)
+		assert.Equal(t, booking.ID, notifier.SentNotifications[0].TemplateData["bookingId"])
+	}
+
+	// A second request straight away is rate limited, even from the business
+	_, err = bookingService.ResendConfirmation(ctx, booking.ID, "owner_resend", "biz_resend")
+	var limitErr *service.ConfirmationResendLimitError
+	if assert.ErrorAs(t, err, &limitErr) {
+		assert.Equal(t, now.Add(service.ConfirmationResendInterval), limitErr.RetryAt)
+	}
+	assert.Len(t, notifier.SentNotifications, 1)
+
+	// Once the interval has passed, the business can resend it
+	fakeClock.Advance(service.ConfirmationResendInterval)
+	_, err = bookingService.ResendConfirmation(ctx, booking.ID, "owner_resend", "biz_resend")
+	assert.NoError(t, err)
+	assert.Len(t, notifier.SentNotifications, 2)
+}
+
+func (suite *BookingServiceTestSuite) TestResendConfirmation_RejectsUnconfirmedBookings() {
+	t := suite.T()
+	ctx := context.Background()
+	startTime, _ := time.Parse(time.RFC3339, "2030-04-01T09:00:00Z")
+	for _, status := range []models.BookingStatus{models.BookingStatusPendingPayment, models.BookingStatusCancelled} {
+		booking := models.Booking{
+			BusinessID: "biz_resend", ServiceID: "svc_resend", CustomerID: "cust_resend",
+			StartTime: startTime, EndTime: startTime.Add(30 * time.Minute), Status: status,
+		}
+		suite.DB.Create(&booking)
+
+		_, err := suite.BookingService.ResendConfirmation(ctx, booking.ID, "cust_resend", "")
+		if assert.Error(t, err, "status %s", status) {
+			assert.Contains(t, err.Error(), "not confirmed")
+		}
+	}
+	assert.Empty(t, suite.MockNotifier.SentNotifications)
+}
+
+func (suite *BookingServiceTestSuite) TestResendConfirmation_RejectsOtherUsers() {
+	t := suite.T()
+	startTime, _ := time.Parse(time.RFC3339, "2030-04-01T09:00:00Z")
+	booking := models.Booking{
+		BusinessID: "biz_resend", ServiceID: "svc_resend", CustomerID: "cust_resend",
+		StartTime: startTime, EndTime: startTime.Add(30 * time.Minute), Status: models.BookingStatusConfirmed,
+	}
+	suite.DB.Create(&booking)
+
+	_, err := suite.BookingService.ResendConfirmation(context.Background(), booking.ID, "someone_else", "other_biz")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "does not belong")
+	}
+	assert.Empty(t, suite.MockNotifier.SentNotifications)
 }
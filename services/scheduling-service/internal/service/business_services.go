@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/slotwise/scheduling-service/internal/models"
+)
+
+// upcomingAvailabilityDays is how many days, today included, ListServicesWithAvailability looks ahead for open slots.
+const upcomingAvailabilityDays = 7
+
+// ServiceWithAvailability is an active service annotated with whether it can still be booked soon.
+type ServiceWithAvailability struct {
+	models.ServiceDefinition
+	HasUpcomingAvailability bool `json:"hasUpcomingAvailability"`
+}
+
+// ListServicesWithAvailability returns the business's active services, each flagged with whether it has an
+// open slot it can still be booked into in the next upcomingAvailabilityDays days. Rules, bookings and holds are loaded once for all
+// services, and each service stops being checked at its first open slot.
+func (s *AvailabilityService) ListServicesWithAvailability(ctx context.Context, businessID string) ([]ServiceWithAvailability, error) {
+	if businessID == "" {
+		return nil, fmt.Errorf("businessID cannot be empty")
+	}
+
+	business, err := s.availabilityRepo.GetBusinessByID(ctx, businessID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get business %s: %w", businessID, err)
+	}
+	if business != nil && !business.IsActive() {
+		return nil, fmt.Errorf("%w: %s", ErrBusinessNotActive, businessID)
+	}
+
+	serviceDefs, err := s.availabilityRepo.GetActiveServiceDefinitionsForBusiness(ctx, businessID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get services for business %s: %w", businessID, err)
+	}
+	services := make([]ServiceWithAvailability, len(serviceDefs))
+	for i := range serviceDefs {
+		services[i].ServiceDefinition = serviceDefs[i]
+	}
+	if len(services) == 0 || (business != nil && !business.AcceptingBookings) {
+		return services, nil
+	}
+
+	now := s.clock.Now()
+	notBefore := earliestBookableStart(business, now)
+	localNow := now.In(business.Location())
+	today := time.Date(localNow.Year(), localNow.Month(), localNow.Day(), 0, 0, 0, 0, localNow.Location())
+	lookAheadEnd := today.AddDate(0, 0, upcomingAvailabilityDays)
+	relevantBookingStatuses := []models.BookingStatus{models.BookingStatusConfirmed, models.BookingStatusPendingPayment}
+	existingBookings, err := s.bookingRepo.GetBookingsForBusinessByDateRangeAndStatuses(ctx, businessID, today, lookAheadEnd, relevantBookingStatuses)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch existing bookings: %w", err)
+	}
+	holds, err := s.slotHoldRepo.GetByBusinessID(ctx, businessID)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch slot holds: %w", err)
+	}
+
+	remaining := len(services)
+	for day := 0; day < upcomingAvailabilityDays && remaining > 0; day++ {
+		date := today.AddDate(0, 0, day)
+		dayOfWeek := models.DayOfWeekString(strings.ToUpper(date.Weekday().String()))
+		rules, err := s.availabilityRepo.GetActiveAvailabilityRules(ctx, businessID, dayOfWeek)
+		if err != nil {
+			return nil, fmt.Errorf("could not get availability rules for %s on %s: %w", businessID, dayOfWeek, err)
+		}
+		if len(rules) == 0 {
+			continue
+		}
+
+		for i := range services {
+			if services[i].HasUpcomingAvailability || services[i].DurationMinutes <= 0 {
+				continue
+			}
+			// Slots that have started or are inside the minimum notice cannot be booked, so they do not count
+			if len(s.generateSlots(date, rules, &services[i].ServiceDefinition, existingBookings, holds, business.BackToBackAllowed(), notBefore, 1)) > 0 {
+				services[i].HasUpcomingAvailability = true
+				remaining--
+			}
+		}
+	}
+	return services, nil
+}
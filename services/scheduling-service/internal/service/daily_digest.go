@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/slotwise/scheduling-service/internal/models"
+	"github.com/slotwise/scheduling-service/internal/repository"
+	"github.com/slotwise/scheduling-service/pkg/clock"
+	"github.com/slotwise/scheduling-service/pkg/events"
+	"github.com/slotwise/scheduling-service/pkg/logger"
+)
+
+// DailyDigestHour is the local hour from which a business's daily digest is sent.
+const DailyDigestHour = 7
+
+// DailyDigestService publishes a morning summary of each business's bookings for the day,
+// so owners get one email instead of a notification per booking.
+type DailyDigestService struct {
+	availabilityRepo *repository.AvailabilityRepository
+	bookingRepo      *repository.BookingRepository
+	outboxRepo       *repository.OutboxRepository
+	outboxRelay      *OutboxRelay
+	clock            clock.Clock
+	logger           *logger.Logger
+}
+
+// NewDailyDigestService creates a new daily digest service. A nil clock uses the system clock.
+func NewDailyDigestService(
+	availabilityRepo *repository.AvailabilityRepository,
+	bookingRepo *repository.BookingRepository,
+	outboxRepo *repository.OutboxRepository,
+	outboxRelay *OutboxRelay,
+	clk clock.Clock,
+	logger *logger.Logger,
+) *DailyDigestService {
+	return &DailyDigestService{
+		availabilityRepo: availabilityRepo,
+		bookingRepo:      bookingRepo,
+		outboxRepo:       outboxRepo,
+		outboxRelay:      outboxRelay,
+		clock:            clock.OrReal(clk),
+		logger:           logger,
+	}
+}
+
+// SendDailyDigests issues a business.daily_digest event for every active business whose local
+// morning has begun and which has not had today's digest yet. It returns how many were issued.
+// A business that fails is logged and retried on the next run without holding up the others.
+func (s *DailyDigestService) SendDailyDigests(ctx context.Context) (int, error) {
+	businesses, err := s.availabilityRepo.ListActiveBusinesses(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list businesses for daily digests: %w", err)
+	}
+
+	now := s.clock.Now()
+	sent := 0
+	for i := range businesses {
+		business := &businesses[i]
+		localNow := now.In(business.Location())
+		if localNow.Hour() < DailyDigestHour {
+			continue
+		}
+
+		outboxEvent, err := s.recordDigest(ctx, business, localNow)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to record daily digest", "businessId", business.ID, "error", err)
+			continue
+		}
+		if outboxEvent == nil {
+			continue // Already issued today
+		}
+		sent++
+
+		// Publish right away; if this fails the outbox relay retries in the background
+		if err := s.outboxRelay.publish(ctx, outboxEvent); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to publish daily digest, left pending in outbox", "businessId", business.ID, "error", err)
+		}
+	}
+
+	if sent > 0 {
+		s.logger.InfoContext(ctx, "Daily digests issued", "count", sent)
+	}
+	return sent, nil
+}
+
+// recordDigest builds the digest of the business's bookings on the local day of localNow and records it.
+// It returns nil, nil if that day's digest was already recorded.
+func (s *DailyDigestService) recordDigest(ctx context.Context, business *models.Business, localNow time.Time) (*models.OutboxEvent, error) {
+	date := localNow.Format("2006-01-02")
+	issued, err := s.outboxRepo.HasDailyDigest(ctx, business.ID, date)
+	if err != nil || issued {
+		return nil, err
+	}
+
+	dayStart := time.Date(localNow.Year(), localNow.Month(), localNow.Day(), 0, 0, 0, 0, localNow.Location())
+	dayEnd := dayStart.AddDate(0, 0, 1)
+	statuses := []models.BookingStatus{models.BookingStatusConfirmed, models.BookingStatusPendingPayment}
+	bookings, err := s.bookingRepo.GetBookingsForBusinessByDateRangeAndStatuses(ctx, business.ID, dayStart, dayEnd, statuses)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bookings for daily digest: %w", err)
+	}
+
+	summaries := make([]map[string]interface{}, 0, len(bookings))
+	for i := range bookings {
+		b := &bookings[i]
+		summary := map[string]interface{}{
+			"bookingId":  b.ID,
+			"serviceId":  b.ServiceID,
+			"customerId": b.CustomerID,
+			"startTime":  b.StartTime.Format(time.RFC3339),
+			"endTime":    b.EndTime.Format(time.RFC3339),
+			"status":     string(b.Status),
+		}
+		if b.IsGuest() {
+			summary["customer"] = guestCustomerPayload(b)
+		}
+		summaries = append(summaries, summary)
+	}
+
+	payload := map[string]interface{}{
+		"businessId":   business.ID,
+		"businessName": business.Name,
+		"date":         date,
+		"timezone":     localNow.Location().String(),
+		"bookingCount": len(bookings),
+		"bookings":     summaries,
+	}
+	return s.outboxRepo.RecordDailyDigest(ctx, business.ID, date, repository.OutboxMessage{Subject: events.BusinessDailyDigestEvent, Payload: payload})
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"fmt"
+	"time"
+)
+
+// DaypartBoundaries splits a day into morning, afternoon and evening, in minutes since midnight of the
+// business's local day. Morning is everything before AfternoonStart, evening everything from EveningStart.
+type DaypartBoundaries struct {
+	AfternoonStart int
+	EveningStart   int
+}
+
+// DefaultDaypartBoundaries starts the afternoon at 12:00 and the evening at 17:00.
+var DefaultDaypartBoundaries = DaypartBoundaries{AfternoonStart: 12 * 60, EveningStart: 17 * 60}
+
+// NewDaypartBoundaries parses "HH:MM" start times of the afternoon and evening.
+func NewDaypartBoundaries(afternoonStart, eveningStart string) (DaypartBoundaries, error) {
+	_, afternoon, err := normalizeHHMM(afternoonStart)
+	if err != nil {
+		return DaypartBoundaries{}, fmt.Errorf("invalid afternoon start: %w", err)
+	}
+	_, evening, err := normalizeHHMM(eveningStart)
+	if err != nil {
+		return DaypartBoundaries{}, fmt.Errorf("invalid evening start: %w", err)
+	}
+	if afternoon >= evening {
+		return DaypartBoundaries{}, fmt.Errorf("invalid dayparts: afternoon start (%s) must be before evening start (%s)", afternoonStart, eveningStart)
+	}
+	return DaypartBoundaries{AfternoonStart: afternoon, EveningStart: evening}, nil
+}
+
+// SlotsByDaypart holds slots bucketed by the part of the day they start in.
+type SlotsByDaypart struct {
+	Morning   []APISlot `json:"morning"`
+	Afternoon []APISlot `json:"afternoon"`
+	Evening   []APISlot `json:"evening"`
+}
+
+// SetDaypartBoundaries sets where GroupSlotsByDaypart splits the day.
+func (s *AvailabilityService) SetDaypartBoundaries(boundaries DaypartBoundaries) {
+	s.dayparts = boundaries
+}
+
+// GroupSlotsByDaypart buckets slots by their start time in loc, the business's timezone, keeping their order
+// within each bucket.
+func (s *AvailabilityService) GroupSlotsByDaypart(slots []APISlot, loc *time.Location) SlotsByDaypart {
+	grouped := SlotsByDaypart{Morning: []APISlot{}, Afternoon: []APISlot{}, Evening: []APISlot{}}
+	for _, slot := range slots {
+		local := slot.StartTime.In(loc)
+		minutes := local.Hour()*60 + local.Minute()
+		switch {
+		case minutes < s.dayparts.AfternoonStart:
+			grouped.Morning = append(grouped.Morning, slot)
+		case minutes < s.dayparts.EveningStart:
+			grouped.Afternoon = append(grouped.Afternoon, slot)
+		default:
+			grouped.Evening = append(grouped.Evening, slot)
+		}
+	}
+	return grouped
+}
@@ -0,0 +1,71 @@
+package service_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/slotwise/scheduling-service/internal/models"
+	"github.com/slotwise/scheduling-service/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupSlotsByDaypart(t *testing.T) {
+	slotAt := func(hour, minute int) service.APISlot {
+		start := time.Date(2024, 3, 4, hour, minute, 0, 0, time.UTC)
+		return service.APISlot{StartTime: start, EndTime: start.Add(30 * time.Minute), Available: true}
+	}
+	slots := []service.APISlot{slotAt(9, 0), slotAt(11, 30), slotAt(12, 0), slotAt(16, 30), slotAt(18, 0)}
+	availabilityService := service.NewAvailabilityService(nil, nil, nil, nil, 0, 0, nil, nil, nil)
+
+	grouped := availabilityService.GroupSlotsByDaypart(slots, time.UTC)
+	assert.Equal(t, []service.APISlot{slotAt(9, 0), slotAt(11, 30)}, grouped.Morning)
+	assert.Equal(t, []service.APISlot{slotAt(12, 0), slotAt(16, 30)}, grouped.Afternoon)
+	assert.Equal(t, []service.APISlot{slotAt(18, 0)}, grouped.Evening)
+
+	// Boundaries are configurable
+	boundaries, err := service.NewDaypartBoundaries("11:00", "18:30")
+	require.NoError(t, err)
+	availabilityService.SetDaypartBoundaries(boundaries)
+	grouped = availabilityService.GroupSlotsByDaypart(slots, time.UTC)
+	assert.Equal(t, []service.APISlot{slotAt(9, 0)}, grouped.Morning)
+	assert.Equal(t, []service.APISlot{slotAt(11, 30), slotAt(12, 0), slotAt(16, 30), slotAt(18, 0)}, grouped.Afternoon)
+	assert.Empty(t, grouped.Evening)
+	assert.NotNil(t, grouped.Evening, "Empty buckets are still arrays in JSON")
+
+	_, err = service.NewDaypartBoundaries("17:00", "12:00")
+	assert.Error(t, err)
+}
+
+func TestGroupSlotsByDaypart_UsesBusinessTimezone(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	availabilityService := service.NewAvailabilityService(nil, nil, nil, nil, 0, 0, nil, nil, nil)
+
+	// 16:00 UTC is 11:00 in New York, still the morning there; 18:00 UTC is 13:00
+	morning := service.APISlot{StartTime: time.Date(2024, 3, 4, 16, 0, 0, 0, time.UTC), EndTime: time.Date(2024, 3, 4, 16, 30, 0, 0, time.UTC), Available: true}
+	afternoon := service.APISlot{StartTime: time.Date(2024, 3, 4, 18, 0, 0, 0, time.UTC), EndTime: time.Date(2024, 3, 4, 18, 30, 0, 0, time.UTC), Available: true}
+
+	grouped := availabilityService.GroupSlotsByDaypart([]service.APISlot{morning, afternoon}, newYork)
+	assert.Equal(t, []service.APISlot{morning}, grouped.Morning)
+	assert.Equal(t, []service.APISlot{afternoon}, grouped.Afternoon)
+	assert.Empty(t, grouped.Evening)
+}
+
+func TestAPISlot_MarshalsTimesInUTC(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	start := time.Date(2024, 3, 4, 11, 0, 0, 0, newYork)
+	slot := service.APISlot{StartTime: start, EndTime: start.Add(30 * time.Minute), Available: true}
+
+	body, err := json.Marshal(slot)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"startTime":"2024-03-04T16:00:00Z","endTime":"2024-03-04T16:30:00Z","available":true}`, string(body))
+
+	hold := models.SlotHold{ID: "hold-1", StartTime: start, EndTime: start.Add(30 * time.Minute), ExpiresAt: start.Add(-time.Hour)}
+	body, err = json.Marshal(hold)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"startTime":"2024-03-04T16:00:00Z"`)
+	assert.Contains(t, string(body), `"expiresAt":"2024-03-04T15:00:00Z"`)
+}
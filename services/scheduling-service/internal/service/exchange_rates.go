@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/slotwise/scheduling-service/internal/models"
+)
+
+// ErrNoExchangeRate is returned when an amount cannot be converted into the requested currency.
+var ErrNoExchangeRate = errors.New("no exchange rate")
+
+// ExchangeRateSource provides the rates used to convert revenue into a single currency.
+type ExchangeRateSource interface {
+	// Rate returns how many units of currency to one unit of currency from is worth.
+	Rate(ctx context.Context, from, to string) (float64, error)
+}
+
+// StaticExchangeRates is an ExchangeRateSource backed by a fixed table holding the value of each
+// currency in a common base currency, e.g. {"USD": 1, "EUR": 1.08}.
+type StaticExchangeRates map[string]float64
+
+// Rate implements ExchangeRateSource.
+func (r StaticExchangeRates) Rate(_ context.Context, from, to string) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+	fromValue, ok := r[from]
+	if !ok {
+		return 0, fmt.Errorf("%w for %s", ErrNoExchangeRate, from)
+	}
+	toValue, ok := r[to]
+	if !ok {
+		return 0, fmt.Errorf("%w for %s", ErrNoExchangeRate, to)
+	}
+	return fromValue / toValue, nil
+}
+
+// SetExchangeRateSource sets the source of the rates RevenueSummary converts totals with.
+// Without one, revenue can only be summarized per currency.
+func (s *BookingService) SetExchangeRateSource(source ExchangeRateSource) {
+	s.exchangeRates = source
+}
+
+// convertRevenue converts per-currency totals into target and adds them up.
+// Totals are in each currency's minor units, so a rate between currencies with different numbers of decimal
+// places is scaled to match; each converted total is rounded to the nearest minor unit of target before being added.
+func convertRevenue(ctx context.Context, source ExchangeRateSource, totals []models.CurrencyRevenue, target string) (*models.ConvertedRevenue, error) {
+	if source == nil {
+		return nil, fmt.Errorf("%w: currency conversion is not configured", ErrNoExchangeRate)
+	}
+
+	converted := &models.ConvertedRevenue{Currency: target, Rates: map[string]float64{}}
+	for _, total := range totals {
+		rate, err := source.Rate(ctx, total.Currency, target)
+		if err != nil {
+			return nil, fmt.Errorf("error converting %s to %s: %w", total.Currency, target, err)
+		}
+		converted.Rates[total.Currency] = rate
+		scale := math.Pow10(models.CurrencyExponent(target) - models.CurrencyExponent(total.Currency))
+		converted.TotalAmount += int64(math.Round(float64(total.TotalAmount) * rate * scale))
+		converted.BookingCount += total.BookingCount
+	}
+	return converted, nil
+}
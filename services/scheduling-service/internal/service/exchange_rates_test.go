@@ -0,0 +1,29 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/slotwise/scheduling-service/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaticExchangeRates_Rate(t *testing.T) {
+	rates := service.StaticExchangeRates{"USD": 1, "EUR": 1.1, "GBP": 1.25}
+	ctx := context.Background()
+
+	rate, err := rates.Rate(ctx, "EUR", "USD")
+	assert.NoError(t, err)
+	assert.InDelta(t, 1.1, rate, 1e-9)
+
+	rate, err = rates.Rate(ctx, "GBP", "EUR")
+	assert.NoError(t, err)
+	assert.InDelta(t, 1.25/1.1, rate, 1e-9, "Rates between non-base currencies go through the base")
+
+	rate, err = rates.Rate(ctx, "JPY", "JPY")
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0, rate, "A currency converts to itself without a table entry")
+
+	_, err = rates.Rate(ctx, "JPY", "USD")
+	assert.ErrorIs(t, err, service.ErrNoExchangeRate)
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaxMarketplaceSearchLimit caps how many businesses one marketplace search page may return.
+const MaxMarketplaceSearchLimit = 50
+
+// MarketplaceBusiness is a business returned by a marketplace search.
+type MarketplaceBusiness struct {
+	BusinessID string `json:"businessId"`
+	Name       string `json:"name,omitempty"` // Empty until reported by a business event
+}
+
+// SearchBusinessesWithAvailability returns, in business ID order, the businesses offering an active service
+// in category that have at least one open slot on date, skipping the first offset matches. hasMore reports
+// whether matches remain past this page. Availability is looked up with HasAvailability, business by
+// business, and the search stops as soon as the page is full.
+func (s *AvailabilityService) SearchBusinessesWithAvailability(ctx context.Context, category string, date time.Time, limit, offset int) ([]MarketplaceBusiness, bool, error) {
+	category = strings.TrimSpace(category)
+	if category == "" {
+		return nil, false, fmt.Errorf("category cannot be empty")
+	}
+	if limit <= 0 || limit > MaxMarketplaceSearchLimit {
+		return nil, false, fmt.Errorf("invalid limit: must be between 1 and %d", MaxMarketplaceSearchLimit)
+	}
+	if offset < 0 {
+		return nil, false, fmt.Errorf("invalid offset: cannot be negative")
+	}
+
+	businessIDs, err := s.availabilityRepo.FindBusinessIDsOfferingCategory(ctx, category)
+	if err != nil {
+		return nil, false, fmt.Errorf("could not search businesses: %w", err)
+	}
+
+	results := []MarketplaceBusiness{}
+	skipped := 0
+	for _, businessID := range businessIDs {
+		available, err := s.HasAvailability(ctx, businessID, date)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, false, ctx.Err()
+			}
+			// Suspended or deleted businesses are not listed; neither is one that cannot be checked right now
+			s.logger.DebugContext(ctx, "Skipping business in marketplace search", "businessId", businessID, "error", err)
+			continue
+		}
+		if !available {
+			continue
+		}
+		if skipped < offset {
+			skipped++
+			continue
+		}
+		if len(results) == limit {
+			return results, true, nil
+		}
+
+		match := MarketplaceBusiness{BusinessID: businessID}
+		if business, err := s.availabilityRepo.GetBusinessByID(ctx, businessID); err == nil && business != nil {
+			match.Name = business.Name
+		}
+		results = append(results, match)
+	}
+	return results, false, nil
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/slotwise/scheduling-service/internal/models"
+	"github.com/slotwise/scheduling-service/internal/repository"
+	"github.com/slotwise/scheduling-service/pkg/logger"
+)
+
+// DefaultOutboxRelayBatchSize is the number of pending events published per relay run.
+const DefaultOutboxRelayBatchSize = 100
+
+// OutboxRelay publishes pending outbox events and marks them as sent.
+// Events are published at least once; consumers must tolerate duplicates.
+type OutboxRelay struct {
+	outboxRepo     *repository.OutboxRepository
+	eventPublisher EventPublisher
+	batchSize      int
+	logger         *logger.Logger
+}
+
+// NewOutboxRelay creates a new outbox relay
+func NewOutboxRelay(outboxRepo *repository.OutboxRepository, eventPublisher EventPublisher, logger *logger.Logger) *OutboxRelay {
+	return &OutboxRelay{
+		outboxRepo:     outboxRepo,
+		eventPublisher: eventPublisher,
+		batchSize:      DefaultOutboxRelayBatchSize,
+		logger:         logger,
+	}
+}
+
+// RelayPendingEvents publishes one batch of pending outbox events and returns how many were sent.
+// Events that fail to publish stay pending and are retried on the next run, until they run out of
+// attempts and are parked.
+func (r *OutboxRelay) RelayPendingEvents(ctx context.Context) (int, error) {
+	pending, err := r.outboxRepo.GetPendingEvents(ctx, r.batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load pending outbox events: %w", err)
+	}
+
+	sent := 0
+	for i := range pending {
+		if err := r.publish(ctx, &pending[i]); err != nil {
+			r.logger.Error("Failed to relay outbox event", "outboxEventId", pending[i].ID, "subject", pending[i].Subject, "error", err)
+			continue
+		}
+		sent++
+	}
+
+	if len(pending) > 0 {
+		r.logger.Info("Outbox relay run completed", "pending", len(pending), "sent", sent)
+	}
+	return sent, nil
+}
+
+// publish sends a single outbox event and records the outcome.
+func (r *OutboxRelay) publish(ctx context.Context, outboxEvent *models.OutboxEvent) error {
+	var payload map[string]interface{}
+	if err := json.Unmarshal([]byte(outboxEvent.Payload), &payload); err != nil {
+		// Retrying cannot fix the payload, so park it now rather than let it fill every batch
+		err = fmt.Errorf("invalid outbox payload: %w", err)
+		if errPark := r.outboxRepo.ParkEvent(ctx, outboxEvent.ID, err); errPark != nil {
+			r.logger.Error("Failed to park outbox event", "outboxEventId", outboxEvent.ID, "error", errPark)
+		} else {
+			r.logger.Error("Parked outbox event with an invalid payload", "outboxEventId", outboxEvent.ID, "subject", outboxEvent.Subject)
+		}
+		return err
+	}
+
+	if err := r.eventPublisher.Publish(outboxEvent.Subject, payload); err != nil {
+		parked, errMark := r.outboxRepo.MarkEventFailed(ctx, outboxEvent.ID, err)
+		if errMark != nil {
+			r.logger.Error("Failed to record outbox publish failure", "outboxEventId", outboxEvent.ID, "error", errMark)
+		} else if parked {
+			r.logger.Error("Parked outbox event after its last publish attempt", "outboxEventId", outboxEvent.ID, "subject", outboxEvent.Subject, "error", err)
+		}
+		return err
+	}
+
+	if err := r.outboxRepo.MarkEventSent(ctx, outboxEvent.ID); err != nil {
+		// The event went out but will be published again on the next run.
+		return fmt.Errorf("event published but not marked as sent: %w", err)
+	}
+	return nil
+}
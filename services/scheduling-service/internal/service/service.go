@@ -2,16 +2,24 @@ package service
 
 import (
 	"context"
-	"fmt"     // Added import
+	"encoding/json"
+	"errors"
+	"fmt" // Added import
+	"net/mail"
+	"sort"
 	"strconv" // Added import
 	"strings" // Added import
+	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/slotwise/scheduling-service/internal/client"
 	"github.com/slotwise/scheduling-service/internal/models" // Added import
 	"github.com/slotwise/scheduling-service/internal/repository"
+	"github.com/slotwise/scheduling-service/pkg/clock"
 	"github.com/slotwise/scheduling-service/pkg/events"
 	"github.com/slotwise/scheduling-service/pkg/logger"
+	"gorm.io/gorm"
 )
 
 // BookingService handles booking business logic
@@ -19,16 +27,25 @@ type BookingService struct {
 	bookingRepo         *repository.BookingRepository // Changed field name for clarity
 	availabilityService *AvailabilityService
 	serviceDefRepo      *repository.AvailabilityRepository // To get service definitions (duration)
-	eventPublisher      EventPublisher                     // Interface
-	notificationClient  NotificationSender                 // Interface for notification client
+	customerPrefRepo    *repository.CustomerPreferenceRepository
+	outboxRelay         *OutboxRelay       // Publishes events written to the transactional outbox
+	eventPublisher      EventPublisher     // Interface
+	notificationClient  NotificationSender // Interface for notification client
+	exchangeRates       ExchangeRateSource // Converts revenue summaries into one currency; nil disables conversion
+	clock               clock.Clock
 	logger              *logger.Logger
+
+	resendMu            sync.Mutex
+	confirmationResends map[string]time.Time // Booking ID -> last time its confirmation was resent
 }
 
 // NotificationSender defines an interface for sending notifications.
 // This allows for using the actual NotificationServiceClient or a mock.
 type NotificationSender interface {
 	SendNotification(req client.SendNotificationRequest) (*client.NotificationResponse, error)
+	SendBatch(reqs []client.SendNotificationRequest) ([]client.BatchSendResult, error)
 	ScheduleNotification(req client.ScheduleNotificationRequest) (*client.NotificationResponse, error)
+	CancelScheduledNotifications(bookingID string) (int, error)
 }
 
 // AvailabilityService handles availability business logic
@@ -36,10 +53,48 @@ type AvailabilityService struct {
 	availabilityRepo *repository.AvailabilityRepository // Renamed from 'repo'
 	bookingRepo      *repository.BookingRepository      // Added for conflict checking in GetAvailableSlots
 	cacheRepo        *repository.CacheRepository
+	slotHoldRepo     *repository.SlotHoldRepository // Short-lived holds on slots during checkout
+	slotHoldTTL      time.Duration
+	maxSlotsPerDay   int // Cap on slots generated for one service and day
+	dayparts         DaypartBoundaries
 	eventPublisher   EventPublisher // Interface
+	clock            clock.Clock
 	logger           *logger.Logger
 }
 
+// DefaultSlotHoldTTL is how long a slot stays held when no TTL is configured
+const DefaultSlotHoldTTL = 10 * time.Minute
+
+// MaxHoldsPerUser caps the unexpired holds one user may have at a business, so holds cannot be used to
+// block out a business's calendar.
+const MaxHoldsPerUser = 3
+
+// ErrTooManyHolds is returned by HoldSlot when the user already has MaxHoldsPerUser holds at the business.
+var ErrTooManyHolds = errors.New("too many slots held")
+
+// ErrSlotNotAvailable is returned by HoldSlot for a start time that is not one of the service's open slots.
+var ErrSlotNotAvailable = errors.New("requested time slot is not available")
+
+// ErrBusinessNotActive is returned for a business that is unknown, suspended or deleted.
+var ErrBusinessNotActive = errors.New("business not found or is not active")
+
+// ErrServiceNotActive is returned for a service that has been deactivated.
+var ErrServiceNotActive = errors.New("service not found or is not active")
+
+// ErrServiceNotInBusiness is returned when a service is used with a business it does not belong to.
+var ErrServiceNotInBusiness = errors.New("service does not belong to business")
+
+// ErrBookingNotOwned is returned when the requester is not the booking's customer, nor its business where
+// that is allowed.
+var ErrBookingNotOwned = errors.New("booking does not belong to the requester")
+
+// DefaultMaxSlotsPerDay caps the slots generated for one service and day when no limit is configured.
+// It guards against a misconfigured rule, e.g. 00:00-23:59 with a 1-minute service, producing a huge response.
+const DefaultMaxSlotsPerDay = 500
+
+// expiryBatchSize is the number of pending bookings expired per run
+const expiryBatchSize = 100
+
 // EventPublisher defines the interface for publishing events.
 // This allows for pkg/events.Publisher or a mock to be used.
 type EventPublisher interface {
@@ -51,473 +106,2163 @@ func NewBookingService(
 	bookingRepo *repository.BookingRepository,
 	availabilityService *AvailabilityService,
 	serviceDefRepo *repository.AvailabilityRepository, // For fetching service definitions
+	customerPrefRepo *repository.CustomerPreferenceRepository, // For customer contact details and notification preferences
+	outboxRelay *OutboxRelay, // For publishing events committed with bookings
 	eventPublisher EventPublisher, // Interface
 	notificationClient NotificationSender, // Use the interface here
+	clk clock.Clock, // Falls back to the system clock when nil
 	logger *logger.Logger,
 ) *BookingService {
 	return &BookingService{
 		bookingRepo:         bookingRepo,
 		availabilityService: availabilityService,
 		serviceDefRepo:      serviceDefRepo,
+		customerPrefRepo:    customerPrefRepo,
+		outboxRelay:         outboxRelay,
 		eventPublisher:      eventPublisher,
 		notificationClient:  notificationClient, // Initialize the field
+		clock:               clock.OrReal(clk),
 		logger:              logger,
+		confirmationResends: make(map[string]time.Time),
 	}
 }
 
 // CreateBookingRequest defines the input for creating a booking
 type CreateBookingRequest struct {
-	BusinessID string    `json:"businessId"`
-	ServiceID  string    `json:"serviceId"`
-	CustomerID string    `json:"customerId"`
-	StartTime  time.Time `json:"startTime"`
+	BusinessID       string                 `json:"businessId"`
+	ServiceID        string                 `json:"serviceId"`
+	CustomerID       string                 `json:"customerId"`
+	StartTime        time.Time              `json:"startTime"`
+	CustomerLanguage string                 `json:"customerLanguage"` // Notification language when none is stored for the customer, e.g. for guests; defaults to "en" when empty
+	Metadata         map[string]interface{} `json:"metadata"`         // Custom fields, validated against the service's metadata schema if set
+	HoldID           string                 `json:"holdId"`           // Hold returned by HoldSlot; lets the holder book the held slot
+	RequestedBy      string                 `json:"requestedBy"`      // User making the request; HoldID is only honoured for this user's holds
+	Guest            *GuestContact          `json:"guest"`            // Set instead of CustomerID when booking for someone without an account
+	DryRun           bool                   `json:"dryRun"`           // Run every check and return the would-be booking without saving it or emitting events
 }
 
-// CreateBooking creates a new booking
-func (s *BookingService) CreateBooking(ctx context.Context, req CreateBookingRequest) (*models.Booking, error) {
-	s.logger.Info("Attempting to create booking", "serviceId", req.ServiceID, "customerId", req.CustomerID, "startTime", req.StartTime)
+// GuestContact holds the contact details of a guest booked without an account.
+type GuestContact struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Phone string `json:"phone"` // Optional
+}
 
-	// 1. Get ServiceDefinition for duration and to verify service
-	serviceDef, err := s.serviceDefRepo.GetServiceDefinition(ctx, req.ServiceID)
-	if err != nil {
-		s.logger.Error("Failed to get service definition for booking", "serviceId", req.ServiceID, "error", err)
-		return nil, fmt.Errorf("failed to retrieve service details: %w", err)
+// validateBookingCustomer checks that a booking is for exactly one of a customer account or a guest.
+func validateBookingCustomer(req CreateBookingRequest) error {
+	if req.Guest == nil {
+		if req.CustomerID == "" {
+			return fmt.Errorf("invalid booking request: customerId or guest contact details are required")
+		}
+		return nil
 	}
-	if serviceDef == nil {
-		s.logger.Warn("Service definition not found for booking", "serviceId", req.ServiceID)
-		return nil, fmt.Errorf("service with ID %s not found", req.ServiceID)
+	if req.CustomerID != "" {
+		return fmt.Errorf("invalid booking request: give either customerId or guest contact details, not both")
 	}
-	if serviceDef.BusinessID != req.BusinessID {
-		s.logger.Warn("Service business ID mismatch", "serviceBusinessID", serviceDef.BusinessID, "requestBusinessID", req.BusinessID)
-		return nil, fmt.Errorf("service does not belong to the specified business")
+	if strings.TrimSpace(req.Guest.Name) == "" {
+		return fmt.Errorf("invalid booking request: guest name is required")
 	}
-	if !serviceDef.IsActive {
-		s.logger.Warn("Attempt to book inactive service", "serviceId", req.ServiceID)
-		return nil, fmt.Errorf("service %s is not active", req.ServiceID)
+	if _, err := mail.ParseAddress(strings.TrimSpace(req.Guest.Email)); err != nil {
+		return fmt.Errorf("invalid booking request: guest email is not valid")
 	}
+	return nil
+}
 
-	endTime := req.StartTime.Add(time.Duration(serviceDef.DurationMinutes) * time.Minute)
+// maxSuggestionDays is how far ahead CreateBooking looks for an alternative slot after a conflict
+const maxSuggestionDays = 7
+
+// SlotUnavailableReason is a machine-readable code for why CreateBooking or RescheduleBooking could not book
+// the requested slot.
+type SlotUnavailableReason string
+
+const (
+	SlotUnavailableConflict       SlotUnavailableReason = "CONFLICT"        // Overlaps a booking or another customer's hold
+	SlotUnavailableOutsideHours   SlotUnavailableReason = "OUTSIDE_HOURS"   // Outside the business's availability rules
+	SlotUnavailableLeadTime       SlotUnavailableReason = "LEAD_TIME"       // Starts sooner than the business accepts bookings
+	SlotUnavailablePast           SlotUnavailableReason = "PAST"            // Starts in the past
+	SlotUnavailableCapacityFull   SlotUnavailableReason = "CAPACITY_FULL"   // The slot has no capacity left
+	SlotUnavailableBusinessPaused SlotUnavailableReason = "BUSINESS_PAUSED" // The business is not accepting new bookings
+	SlotUnavailableQuotaReached   SlotUnavailableReason = "QUOTA_REACHED"   // The day's booking limit for the business or customer is reached
+)
+
+// SlotUnavailableError is returned by CreateBooking and RescheduleBooking when the requested slot cannot be booked.
+// Conflicts are reported as the more detailed BookingConflictError instead.
+type SlotUnavailableError struct {
+	Reason  SlotUnavailableReason
+	Message string
+}
+
+func (e *SlotUnavailableError) Error() string {
+	return e.Message
+}
+
+// BookingConflictError is returned by CreateBooking when the requested slot is taken.
+// It carries the occupied time range and, if one was found, the next available start time.
+type BookingConflictError struct {
+	ConflictStart      time.Time
+	ConflictEnd        time.Time
+	Held               bool       // The slot is held by another customer rather than booked
+	SuggestedStartTime *time.Time // Nil when no alternative slot was found
+}
+
+// Reason returns the SlotUnavailableReason of a conflict.
+func (e *BookingConflictError) Reason() SlotUnavailableReason {
+	return SlotUnavailableConflict
+}
+
+func (e *BookingConflictError) Error() string {
+	if e.Held {
+		return "requested time slot is not available due to a conflict with a held slot"
+	}
+	return "requested time slot is not available due to a conflict"
+}
 
-	// 2. Conflict Detection
-	conflictingBookings, err := s.bookingRepo.FindConflictingBookings(ctx, req.BusinessID, req.ServiceID, req.StartTime, endTime)
+// bufferPaddingAt returns how far other appointments must stay from one starting at start: the before and
+// after buffers of the active rule start falls in, added up, so neither appointment's setup or cleanup
+// overlaps the other's. It is zero when start is outside every rule.
+func bufferPaddingAt(ctx context.Context, availabilityRepo *repository.AvailabilityRepository, business *models.Business, businessID string, start time.Time) (time.Duration, error) {
+	local := start.In(business.Location())
+	dayOfWeek := models.DayOfWeekString(strings.ToUpper(local.Weekday().String()))
+	rules, err := availabilityRepo.GetActiveAvailabilityRules(ctx, businessID, dayOfWeek)
 	if err != nil {
-		s.logger.Error("Error checking for conflicting bookings", "serviceId", req.ServiceID, "startTime", req.StartTime, "error", err)
-		return nil, fmt.Errorf("error checking for booking conflicts: %w", err)
+		return 0, err
 	}
-	if len(conflictingBookings) > 0 {
-		s.logger.Warn("Booking conflict detected", "serviceId", req.ServiceID, "startTime", req.StartTime, "conflicts", len(conflictingBookings))
-		return nil, fmt.Errorf("requested time slot is not available due to a conflict")
+
+	minutes := local.Hour()*60 + local.Minute()
+	for i := range rules {
+		_, ruleStart, errStart := normalizeHHMM(rules[i].StartTime)
+		_, ruleEnd, errEnd := normalizeHHMM(rules[i].EndTime)
+		if errStart != nil || errEnd != nil {
+			continue
+		}
+		if minutes >= ruleStart && minutes < ruleEnd {
+			before, after := rules[i].Buffers()
+			return before + after, nil
+		}
 	}
+	return 0, nil
+}
 
-	// 3. Create Booking record
-	newBooking := &models.Booking{
-		// ID will be set by BeforeCreate hook
-		BusinessID: req.BusinessID,
-		ServiceID:  req.ServiceID,
-		CustomerID: req.CustomerID,
-		StartTime:  req.StartTime,
-		EndTime:    endTime,
-		Status:     models.BookingStatusPendingPayment, // Initial status, can be changed based on payment flow
-	}
-
-	if err := s.bookingRepo.CreateBooking(ctx, newBooking); err != nil {
-		s.logger.Error("Failed to create booking in database", "error", err)
-		return nil, fmt.Errorf("failed to save booking: %w", err)
-	}
-	s.logger.Info("Booking record created successfully", "bookingId", newBooking.ID)
-
-	// 4. Publish NATS event: booking.requested
-	eventPayload := map[string]interface{}{
-		"bookingId":  newBooking.ID,
-		"customerId": newBooking.CustomerID,
-		"serviceId":  newBooking.ServiceID,
-		"businessId": newBooking.BusinessID,
-		"startTime":  newBooking.StartTime.Format(time.RFC3339),
-		"endTime":    newBooking.EndTime.Format(time.RFC3339),
-		"status":     string(newBooking.Status),
-	}
-	if err := s.eventPublisher.Publish(events.BookingRequestedEvent, eventPayload); err != nil {
-		s.logger.Error("Failed to publish booking.requested event", "bookingId", newBooking.ID, "error", err)
-		// Continue even if event publishing fails, booking is already in DB.
-	} else {
-		s.logger.Info("Published booking.requested event", "bookingId", newBooking.ID)
+// checkWithinAvailability returns an OUTSIDE_HOURS *SlotUnavailableError unless [start, end) lies within one of
+// the business's active availability rules on start's day, in the business's timezone, after the rule's before
+// buffer as in slot generation.
+func checkWithinAvailability(ctx context.Context, availabilityRepo *repository.AvailabilityRepository, business *models.Business, businessID string, start, end time.Time) error {
+	local := start.In(business.Location())
+	dayOfWeek := models.DayOfWeekString(strings.ToUpper(local.Weekday().String()))
+	rules, err := availabilityRepo.GetActiveAvailabilityRules(ctx, businessID, dayOfWeek)
+	if err != nil {
+		return fmt.Errorf("error loading availability rules for business %s: %w", businessID, err)
 	}
 
-	// If no payment is required, we might move to Confirmed and publish slot.reserved here.
-	// For now, assuming payment comes next or manual confirmation.
+	for i := range rules {
+		_, ruleStart, errStart := normalizeHHMM(rules[i].StartTime)
+		_, ruleEnd, errEnd := normalizeHHMM(rules[i].EndTime)
+		if errStart != nil || errEnd != nil {
+			continue
+		}
+		before, _ := rules[i].Buffers()
+		opens := time.Date(local.Year(), local.Month(), local.Day(), ruleStart/60, ruleStart%60, 0, 0, local.Location()).Add(before)
+		closes := time.Date(local.Year(), local.Month(), local.Day(), ruleEnd/60, ruleEnd%60, 0, 0, local.Location())
+		if !start.Before(opens) && !end.After(closes) {
+			return nil
+		}
+	}
+	return &SlotUnavailableError{Reason: SlotUnavailableOutsideHours, Message: "requested time is outside the business's availability"}
+}
 
-	return newBooking, nil
+// earliestBookableStart returns the earliest start a booking made with the business at now may have: its
+// minimum notice from now, which is now when it requires none. checkBookableTime enforces the same bound.
+func earliestBookableStart(business *models.Business, now time.Time) time.Time {
+	return now.Add(business.MinimumNotice())
 }
 
-// GetBookingDetails retrieves a booking by its ID.
-func (s *BookingService) GetBookingDetails(ctx context.Context, bookingID string) (*models.Booking, error) {
-	booking, err := s.bookingRepo.GetBookingByID(ctx, bookingID)
-	if err != nil {
-		s.logger.Error("Error fetching booking details from repo", "bookingId", bookingID, "error", err)
-		return nil, fmt.Errorf("repository error fetching booking: %w", err)
+// checkBookableTime returns a PAST, LEAD_TIME or OUTSIDE_HOURS *SlotUnavailableError unless a booking for
+// [start, end) may be made at now: it must start after now, at least the business's minimum notice ahead,
+// and lie within the business's availability.
+func checkBookableTime(ctx context.Context, availabilityRepo *repository.AvailabilityRepository, business *models.Business, businessID string, start, end, now time.Time) error {
+	if start.Before(now) {
+		return &SlotUnavailableError{Reason: SlotUnavailablePast, Message: "requested time is in the past"}
 	}
-	if booking == nil {
-		s.logger.Info("Booking not found", "bookingId", bookingID)
-		return nil, nil // Or return a specific "not found" error
+	if notice := business.MinimumNotice(); start.Before(now.Add(notice)) {
+		return &SlotUnavailableError{Reason: SlotUnavailableLeadTime, Message: fmt.Sprintf("bookings must be made at least %s in advance", notice)}
 	}
-	return booking, nil
+	return checkWithinAvailability(ctx, availabilityRepo, business, businessID, start, end)
 }
 
-// UpdateBookingStatusRequest defines the input for updating a booking's status.
-type UpdateBookingStatusRequest struct {
-	Status models.BookingStatus `json:"status"`
-	// Potentially add other fields like cancellationReason, etc.
-}
+// CreateBooking creates a new booking.
+// With req.DryRun set it only validates the request, returning the unsaved booking it would create.
+func (s *BookingService) CreateBooking(ctx context.Context, req CreateBookingRequest) (*models.Booking, error) {
+	s.logger.InfoContext(ctx, "Attempting to create booking", "serviceId", req.ServiceID, "customerId", req.CustomerID, "guest", req.Guest != nil, "startTime", req.StartTime)
 
-// UpdateBookingStatus changes the status of a booking.
-func (s *BookingService) UpdateBookingStatus(ctx context.Context, bookingID string, newStatus models.BookingStatus) (*models.Booking, error) { // Add import for client "github.com/slotwise-app/services/scheduling-service/internal/client"
-	s.logger.Info("Updating booking status", "bookingId", bookingID, "newStatus", newStatus)
+	if err := validateBookingCustomer(req); err != nil {
+		return nil, err
+	}
 
-	// Validate newStatus if necessary (e.g., allowed transitions)
-	// For MVP, direct update.
+	// 1. Get ServiceDefinition for duration and to verify service
+	serviceDef, err := s.serviceDefRepo.GetServiceDefinition(ctx, req.ServiceID)
+	if errors.Is(err, repository.ErrServiceDefinitionNotFound) {
+		s.logger.WarnContext(ctx, "Service definition not found for booking", "serviceId", req.ServiceID)
+		return nil, err
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to get service definition for booking", "serviceId", req.ServiceID, "error", err)
+		return nil, fmt.Errorf("failed to retrieve service details: %w", err)
+	}
+	if serviceDef.BusinessID != req.BusinessID {
+		s.logger.WarnContext(ctx, "Service business ID mismatch", "serviceBusinessID", serviceDef.BusinessID, "requestBusinessID", req.BusinessID)
+		return nil, fmt.Errorf("%w: service %s, business %s", ErrServiceNotInBusiness, req.ServiceID, req.BusinessID)
+	}
 
-	booking, err := s.bookingRepo.GetBookingByID(ctx, bookingID)
+	business, err := s.serviceDefRepo.GetBusinessByID(ctx, req.BusinessID)
 	if err != nil {
-		s.logger.Error("Failed to get booking for status update", "bookingId", bookingID, "error", err)
-		return nil, fmt.Errorf("failed to retrieve booking %s: %w", bookingID, err)
+		s.logger.ErrorContext(ctx, "Failed to get business for booking", "businessId", req.BusinessID, "error", err)
+		return nil, fmt.Errorf("failed to retrieve business details: %w", err)
+	}
+	if business != nil && !business.IsActive() {
+		s.logger.WarnContext(ctx, "Attempt to book with an inactive business", "businessId", req.BusinessID, "status", business.Status)
+		return nil, fmt.Errorf("%w: %s", ErrBusinessNotActive, req.BusinessID)
 	}
-	if booking == nil {
-		s.logger.Warn("Booking not found for status update", "bookingId", bookingID)
-		return nil, fmt.Errorf("booking %s not found", bookingID)
+	if business != nil && !business.AcceptingBookings {
+		s.logger.WarnContext(ctx, "Attempt to book with a business that has paused bookings", "businessId", req.BusinessID)
+		return nil, &SlotUnavailableError{Reason: SlotUnavailableBusinessPaused, Message: fmt.Sprintf("business %s is not accepting new bookings", req.BusinessID)}
+	}
+	if !serviceDef.IsActive {
+		s.logger.WarnContext(ctx, "Attempt to book inactive service", "serviceId", req.ServiceID)
+		return nil, fmt.Errorf("%w: %s", ErrServiceNotActive, req.ServiceID)
 	}
 
-	// TODO: Add logic to check if status transition is valid, e.g. cannot confirm a cancelled booking.
-	// oldStatus := booking.Status
+	if serviceDef.MetadataSchema != nil {
+		if err := serviceDef.MetadataSchema.Validate(req.Metadata); err != nil {
+			s.logger.WarnContext(ctx, "Booking metadata failed validation", "serviceId", req.ServiceID, "error", err)
+			return nil, err
+		}
+	}
 
-	// Fetch service definition for service name and duration (needed for notifications)
-	var serviceName, businessName string
-	var customerEmail string = "customer@example.com" // Placeholder
-	var businessEmail string = "business@example.com" // Placeholder for business copy
+	endTime := req.StartTime.Add(time.Duration(serviceDef.DurationMinutes) * time.Minute)
 
-	if booking.ServiceID != "" {
-		serviceDef, errService := s.serviceDefRepo.GetServiceDefinition(ctx, booking.ServiceID)
-		if errService == nil && serviceDef != nil {
-			serviceName = serviceDef.Name
-			// Potentially fetch Business Name via businessId from serviceDef or booking.BusinessID
-			// For now, using placeholder:
-			businessName = fmt.Sprintf("Business %s", booking.BusinessID)
-		} else {
-			s.logger.Warn("Could not fetch service details for notification data", "bookingId", bookingID, "serviceId", booking.ServiceID, "error", errService)
-			serviceName = "Unknown Service"
-			businessName = fmt.Sprintf("Business %s", booking.BusinessID)
+	// 2. Build the booking record. Services that do not require payment are confirmed straight away.
+	status := models.BookingStatusPendingPayment
+	if !serviceDef.PaymentRequired() {
+		status = models.BookingStatusConfirmed
+	}
+	newBooking := &models.Booking{
+		// ID will be set by BeforeCreate hook
+		BusinessID:       req.BusinessID,
+		ServiceID:        req.ServiceID,
+		CustomerID:       req.CustomerID,
+		StartTime:        req.StartTime,
+		EndTime:          endTime,
+		Status:           status,
+		CustomerLanguage: req.CustomerLanguage,
+		Metadata:         req.Metadata,
+		TotalAmount:      &serviceDef.Price, // Snapshot so later price changes do not alter past revenue
+		Currency:         serviceDef.Currency,
+	}
+	if req.Guest != nil {
+		name := strings.TrimSpace(req.Guest.Name)
+		email := strings.ToLower(strings.TrimSpace(req.Guest.Email))
+		newBooking.GuestName = &name
+		newBooking.GuestEmail = &email
+		if phone := strings.TrimSpace(req.Guest.Phone); phone != "" {
+			newBooking.GuestPhone = &phone
 		}
 	}
-	// TODO: Fetch actual customer email and business email/details
-	// customer, errCust := s.customerRepo.GetCustomer(ctx, booking.CustomerID)
-	// if errCust == nil && customer != nil { customerEmail = customer.Email }
-	// businessDetails, errBiz := s.businessRepo.GetBusiness(ctx, booking.BusinessID)
-	// if errBiz == nil && businessDetails != nil { businessName = businessDetails.Name; businessEmail = businessDetails.NotificationEmailOrDefault() }
 
-	if err := s.bookingRepo.UpdateBookingStatus(ctx, bookingID, newStatus); err != nil {
-		s.logger.Error("Failed to update booking status in database", "bookingId", bookingID, "error", err)
-		return nil, fmt.Errorf("failed to update status for booking %s: %w", bookingID, err)
+	if req.DryRun {
+		if err := checkBookableTime(ctx, s.serviceDefRepo, business, req.BusinessID, req.StartTime, endTime, s.clock.Now()); err != nil {
+			return nil, err
+		}
+		if _, _, err := s.checkSlotFree(ctx, s.serviceDefRepo, s.bookingRepo, business, serviceDef, req, endTime, ""); err != nil {
+			return nil, s.withSuggestion(ctx, req, err)
+		}
+		if err := s.checkDailyQuotas(ctx, s.bookingRepo, business, newBooking); err != nil {
+			return nil, err
+		}
+		s.logger.InfoContext(ctx, "Dry-run booking passed validation", "serviceId", req.ServiceID, "startTime", req.StartTime, "status", newBooking.Status)
+		return newBooking, nil
 	}
 
-	booking.Status = newStatus     // Update status in the fetched object for return
-	booking.UpdatedAt = time.Now() // Should be handled by GORM hooks ideally, or manually set
+	// 3. Check the slot and persist the booking together with its outbox events under the business lock, the
+	// lock CheckAndReserve takes for holds, so a concurrent booking or hold cannot take the slot in between.
+	// The events are only lost if the transaction is rolled back, in which case there is no booking either.
+	var ownHold *models.SlotHold
+	var outboxEvents []*models.OutboxEvent
+	err = s.serviceDefRepo.WithBusinessLock(ctx, req.BusinessID, func(tx *gorm.DB) error {
+		availabilityRepo := s.serviceDefRepo.WithTx(tx)
+		bookingRepo := s.bookingRepo.WithTx(tx)
+		if err := checkBookableTime(ctx, availabilityRepo, business, req.BusinessID, req.StartTime, endTime, s.clock.Now()); err != nil {
+			return err
+		}
+		var placesTaken int
+		var err error
+		ownHold, placesTaken, err = s.checkSlotFree(ctx, availabilityRepo, bookingRepo, business, serviceDef, req, endTime, "")
+		if err != nil {
+			return err
+		}
+		outboxEvents, err = bookingRepo.CreateBookingWithinQuota(ctx, newBooking, bookingQuota(business, req.StartTime), func(b *models.Booking) []repository.OutboxMessage {
+			return bookingCreatedMessages(b, serviceDef, serviceDef.SlotCapacity()-placesTaken-1)
+		})
+		if errors.Is(err, repository.ErrBookingQuotaExceeded) {
+			s.logger.WarnContext(ctx, "Booking rejected by the business's daily quota", "businessId", req.BusinessID, "customerId", req.CustomerID, "error", err)
+			return errQuotaReached()
+		}
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to create booking in database", "error", err)
+			return fmt.Errorf("failed to save booking: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, s.withSuggestion(ctx, req, err)
+	}
+	s.logger.InfoContext(ctx, "Booking record created successfully", "bookingId", newBooking.ID, "status", newBooking.Status)
 
-	// Publish NATS events based on status change
-	var eventSubject string
-	eventPayload := map[string]interface{}{
-		"bookingId":  booking.ID,
-		"customerId": booking.CustomerID,
-		"serviceId":  booking.ServiceID,
-		"businessId": booking.BusinessID,
-		"newStatus":  string(newStatus),
-		"startTime":  booking.StartTime.Format(time.RFC3339),
-		"endTime":    booking.EndTime.Format(time.RFC3339),
+	if ownHold != nil {
+		// The booking now occupies the slot; a failed release only means the hold lingers until its TTL
+		if err := s.availabilityService.slotHoldRepo.Release(ctx, ownHold); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to release slot hold after booking", "holdId", ownHold.ID, "bookingId", newBooking.ID, "error", err)
+		}
 	}
 
-	// ---- Notification Logic ----
-	if s.notificationClient != nil {
-		commonTemplateData := map[string]interface{}{
-			"userName":     fmt.Sprintf("Customer %s", booking.CustomerID), // Placeholder
-			"businessName": businessName,
-			"serviceName":  serviceName,
-			"bookingId":    booking.ID,
-			"bookingDate":  booking.StartTime.Format("January 2, 2006"),
-			"bookingTime":  booking.StartTime.Format("3:04 PM"),
-			"duration":     booking.EndTime.Sub(booking.StartTime).Minutes(),
-			// "resourceName": // If applicable
-			// "notes": booking.Notes, // If applicable
+	// 4. Try to publish right away; if this fails the outbox relay retries in the background.
+	for _, outboxEvent := range outboxEvents {
+		if err := s.outboxRelay.publish(ctx, outboxEvent); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to publish event, left pending in outbox", "subject", outboxEvent.Subject, "bookingId", newBooking.ID, "error", err)
+		} else {
+			s.logger.InfoContext(ctx, "Published event", "subject", outboxEvent.Subject, "bookingId", newBooking.ID)
 		}
+	}
 
-		switch newStatus {
-		case models.BookingStatusConfirmed:
-			eventSubject = events.BookingConfirmedEvent
-			// Also publish slot.reserved (existing logic)
-			slotReservedPayload := map[string]interface{}{
-				"bookingId": booking.ID, "serviceId": booking.ServiceID, "businessId": booking.BusinessID,
-				"startTime": booking.StartTime.Format(time.RFC3339), "endTime": booking.EndTime.Format(time.RFC3339),
-			}
-			if errPub := s.eventPublisher.Publish(events.SlotReservedEvent, slotReservedPayload); errPub != nil {
-				s.logger.Error("Failed to publish slot.reserved event", "bookingId", booking.ID, "error", errPub)
-			} else {
-				s.logger.Info("Published slot.reserved event", "bookingId", booking.ID)
-			}
+	// 5. A booking confirmed on creation gets the same emails and reminder as one confirmed later.
+	if newBooking.Status == models.BookingStatusConfirmed && s.notificationClient != nil {
+		serviceName, businessName := s.notificationNames(ctx, newBooking)
+		contact := s.customerNotificationContact(ctx, newBooking)
+		s.sendConfirmedNotifications(ctx, newBooking, bookingTemplateData(newBooking, serviceName, businessName, contact.language), serviceName, contact.email, contact.emailEnabled)
+	}
 
-			// 1. Send Booking Confirmation to Customer
-			customerConfirmationReq := client.SendNotificationRequest{
-				Type:           "booking_confirmation",
-				RecipientEmail: customerEmail, // Placeholder
-				TemplateData:   commonTemplateData,
-			}
-			_, err := s.notificationClient.SendNotification(customerConfirmationReq)
-			if err != nil {
-				s.logger.Error("Failed to send booking confirmation to customer", "bookingId", booking.ID, "error", err)
-				// Non-critical, log and continue
-			}
+	return newBooking, nil
+}
 
-			// 2. Send Booking Confirmation to Business (optional, if configured)
-			// Assuming businessEmail is fetched or configured
-			businessConfirmationReq := client.SendNotificationRequest{
-				Type:           "booking_confirmation", // Could be a different template like "new_booking_alert"
-				RecipientEmail: businessEmail,          // Placeholder
-				TemplateData:   commonTemplateData,     // Might need different data for business
-				Subject:        func(s string) *string { return &s }(fmt.Sprintf("New Booking Confirmed: %s for %s", serviceName, commonTemplateData["userName"])),
+// checkSlotFree checks that no booking or other customer's hold overlaps the requested slot, keeping the rule's
+// buffers clear around it as slot generation does, and that none touches it unless the business allows back to
+// back bookings. Bookings of a group service that share the exact slot take a place in it instead of conflicting
+// (see sharesSlot); a slot with every place taken is CAPACITY_FULL. The booking excludeBookingID, the one being
+// rescheduled, is ignored so it does not conflict with itself. It reads through the given repositories so
+// CreateBooking can run it in the business lock's transaction. It returns the caller's own hold on the slot, if
+// any, and how many places are already taken. Conflicts are *BookingConflictError without a suggested start;
+// withSuggestion adds one once the lock is released.
+func (s *BookingService) checkSlotFree(ctx context.Context, availabilityRepo *repository.AvailabilityRepository, bookingRepo *repository.BookingRepository, business *models.Business, serviceDef *models.ServiceDefinition, req CreateBookingRequest, endTime time.Time, excludeBookingID string) (*models.SlotHold, int, error) {
+	padding, err := bufferPaddingAt(ctx, availabilityRepo, business, req.BusinessID, req.StartTime)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Error loading availability rules for booking buffers", "businessId", req.BusinessID, "error", err)
+		return nil, 0, fmt.Errorf("error checking for booking conflicts: %w", err)
+	}
+	conflictingBookings, err := bookingRepo.FindConflictingBookings(ctx, req.BusinessID, req.ServiceID, req.StartTime.Add(-padding), endTime.Add(padding), excludeBookingID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Error checking for conflicting bookings", "serviceId", req.ServiceID, "startTime", req.StartTime, "error", err)
+		return nil, 0, fmt.Errorf("error checking for booking conflicts: %w", err)
+	}
+	if !business.BackToBackAllowed() {
+		// Bookings that merely touch this one conflict too
+		adjacent, err := bookingRepo.FindAdjacentBookings(ctx, req.BusinessID, req.StartTime, endTime, excludeBookingID)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Error checking for adjacent bookings", "serviceId", req.ServiceID, "startTime", req.StartTime, "error", err)
+			return nil, 0, fmt.Errorf("error checking for booking conflicts: %w", err)
+		}
+		conflictingBookings = append(conflictingBookings, adjacent...)
+	}
+	placesTaken := 0
+	conflicts := conflictingBookings[:0]
+	for _, b := range conflictingBookings {
+		if sharesSlot(b, serviceDef, req.StartTime, endTime) {
+			placesTaken++
+		} else {
+			conflicts = append(conflicts, b)
+		}
+	}
+	conflictingBookings = conflicts
+	if len(conflictingBookings) > 0 {
+		s.logger.WarnContext(ctx, "Booking conflict detected", "serviceId", req.ServiceID, "startTime", req.StartTime, "conflicts", len(conflictingBookings))
+		conflict := &BookingConflictError{ConflictStart: conflictingBookings[0].StartTime, ConflictEnd: conflictingBookings[0].EndTime}
+		for _, b := range conflictingBookings[1:] {
+			if b.StartTime.Before(conflict.ConflictStart) {
+				conflict.ConflictStart = b.StartTime
 			}
-			_, err = s.notificationClient.SendNotification(businessConfirmationReq)
-			if err != nil {
-				s.logger.Error("Failed to send booking confirmation to business", "bookingId", booking.ID, "error", err)
+			if b.EndTime.After(conflict.ConflictEnd) {
+				conflict.ConflictEnd = b.EndTime
 			}
+		}
+		return nil, 0, conflict
+	}
 
-			// 3. Schedule Booking Reminder for Customer
-			// Example: 24 hours before booking.StartTime
-			reminderTime := booking.StartTime.Add(-24 * time.Hour)
-			// Ensure reminderTime is in the future
-			if reminderTime.After(time.Now()) {
-				scheduleReq := client.ScheduleNotificationRequest{
-					Type:           "booking_reminder",
-					RecipientEmail: customerEmail, // Placeholder
-					TemplateData:   commonTemplateData,
-					ScheduledFor:   reminderTime,
-					BookingID:      booking.ID,
-				}
-				_, err = s.notificationClient.ScheduleNotification(scheduleReq)
-				if err != nil {
-					s.logger.Error("Failed to schedule booking reminder", "bookingId", booking.ID, "error", err)
+	// Slots held by other customers count as taken; the caller's own hold is released once booked
+	var ownHold *models.SlotHold
+	if s.availabilityService != nil {
+		holds, err := s.availabilityService.slotHoldRepo.GetByBusinessID(ctx, req.BusinessID)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Error checking for slot holds", "businessId", req.BusinessID, "error", err)
+			return nil, 0, fmt.Errorf("error checking for booking conflicts: %w", err)
+		}
+		for i := range holds {
+			if req.HoldID != "" && holds[i].ID == req.HoldID && holds[i].Overlaps(req.StartTime, endTime) {
+				if holds[i].UserID == req.RequestedBy {
+					ownHold = &holds[i]
+					continue
 				}
-			} else {
-				s.logger.Info("Booking reminder time is in the past, not scheduling.", "bookingId", booking.ID, "reminderTime", reminderTime)
+				// Another user's hold ID does not unlock their slot
+				s.logger.WarnContext(ctx, "Hold belongs to another user", "holdId", holds[i].ID, "requestedBy", req.RequestedBy)
+				return nil, 0, &BookingConflictError{ConflictStart: holds[i].StartTime, ConflictEnd: holds[i].EndTime, Held: true}
 			}
+			if !holds[i].Overlaps(req.StartTime.Add(-padding), endTime.Add(padding)) {
+				continue
+			}
+			s.logger.WarnContext(ctx, "Requested slot is held by another customer", "serviceId", req.ServiceID, "startTime", req.StartTime, "holdId", holds[i].ID)
+			return nil, 0, &BookingConflictError{ConflictStart: holds[i].StartTime, ConflictEnd: holds[i].EndTime, Held: true}
+		}
+	}
+	if placesTaken >= serviceDef.SlotCapacity() {
+		s.logger.WarnContext(ctx, "Requested group slot is full", "serviceId", req.ServiceID, "startTime", req.StartTime, "capacity", serviceDef.SlotCapacity())
+		return nil, 0, &SlotUnavailableError{Reason: SlotUnavailableCapacityFull, Message: "requested time slot has no places left"}
+	}
+	return ownHold, placesTaken, nil
+}
 
-		case models.BookingStatusCancelled:
-			eventSubject = events.BookingCancelledEvent
-			// eventPayload["reason"] = "..." // Add reason if available
-
-			cancellationTemplateData := commonTemplateData
-			// cancellationTemplateData["cancellationReason"] = "Your reason here" // If available
+// sharesSlot reports whether booking b takes a place in the slot [start, end) of serviceDef rather than
+// conflicting with it: a service with more than one place per slot, e.g. a group class, is booked by several
+// customers for exactly the same times. Other overlaps are conflicts, as for one-to-one services.
+func sharesSlot(b models.Booking, serviceDef *models.ServiceDefinition, start, end time.Time) bool {
+	return serviceDef.SlotCapacity() > 1 && b.ServiceID == serviceDef.ID && b.StartTime.Equal(start) && b.EndTime.Equal(end)
+}
 
-			// Send Booking Cancellation to Customer
-			customerCancellationReq := client.SendNotificationRequest{
-				Type:           "booking_cancellation",
-				RecipientEmail: customerEmail, // Placeholder
-				TemplateData:   cancellationTemplateData,
-			}
-			_, err := s.notificationClient.SendNotification(customerCancellationReq)
-			if err != nil {
-				s.logger.Error("Failed to send booking cancellation to customer", "bookingId", booking.ID, "error", err)
-			}
-			// Optionally, notify business about cancellation
+// withSuggestion fills in the next available start of a *BookingConflictError and returns err.
+func (s *BookingService) withSuggestion(ctx context.Context, req CreateBookingRequest, err error) error {
+	var conflict *BookingConflictError
+	if errors.As(err, &conflict) {
+		conflict.SuggestedStartTime = s.suggestAlternativeStart(ctx, req)
+	}
+	return err
+}
 
-		default:
-			s.logger.Info("No specific NATS event or notification for status update", "bookingId", booking.ID, "newStatus", newStatus)
+// bookingCreatedMessages lists the events announcing a new booking.
+// Pending bookings emit booking.requested, carrying the service details notification templates need;
+// auto-confirmed bookings emit the same events as a confirmation. Both carry the service's capacity and
+// the places left in the slot now that b has taken one, so realtime clients can update their counters.
+func bookingCreatedMessages(b *models.Booking, serviceDef *models.ServiceDefinition, remainingCapacity int) []repository.OutboxMessage {
+	if b.Status != models.BookingStatusConfirmed {
+		payload := map[string]interface{}{
+			"bookingId":  b.ID,
+			"customerId": b.CustomerID,
+			"serviceId":  b.ServiceID,
+			"businessId": b.BusinessID,
+			"startTime":  b.StartTime.Format(time.RFC3339),
+			"endTime":    b.EndTime.Format(time.RFC3339),
+			"status":     string(b.Status),
+			"metadata":   b.Metadata,
+
+			"serviceName":     serviceDef.Name,
+			"durationMinutes": serviceDef.DurationMinutes,
+			"price":           serviceDef.Price,
+			"currency":        serviceDef.Currency,
+
+			"capacity":          serviceDef.SlotCapacity(),
+			"remainingCapacity": remainingCapacity,
 		}
+		if b.IsGuest() {
+			payload["customer"] = guestCustomerPayload(b)
+		}
+		return []repository.OutboxMessage{{Subject: events.BookingRequestedEvent, Payload: payload}}
 	}
-	// ---- End Notification Logic ----
+	messages := bookingStatusMessages(b, nil)
+	for _, message := range messages {
+		if message.Subject == events.BookingConfirmedEvent {
+			message.Payload["capacity"] = serviceDef.SlotCapacity()
+			message.Payload["remainingCapacity"] = remainingCapacity
+		}
+	}
+	return messages
+}
 
-	if eventSubject != "" {
-		if errPub := s.eventPublisher.Publish(eventSubject, eventPayload); errPub != nil {
-			s.logger.Error("Failed to publish booking status event", "subject", eventSubject, "bookingId", booking.ID, "error", errPub)
-		} else {
-			s.logger.Info("Published booking status event", "subject", eventSubject, "bookingId", booking.ID)
+// guestCustomerPayload describes a guest in the "customer" shape notification consumers read contact details from.
+func guestCustomerPayload(b *models.Booking) map[string]interface{} {
+	customer := map[string]interface{}{"email": *b.GuestEmail}
+	if b.GuestName != nil {
+		customer["name"] = *b.GuestName
+	}
+	if b.GuestPhone != nil {
+		customer["phone"] = *b.GuestPhone
+	}
+	return customer
+}
+
+// bookingStatusMessages lists the events announcing that a booking moved to its current status.
+// Confirmation emits booking.confirmed and slot.reserved, cancellation booking.cancelled; other statuses emit nothing.
+func bookingStatusMessages(b *models.Booking, reason *string) []repository.OutboxMessage {
+	statusPayload := map[string]interface{}{
+		"bookingId":  b.ID,
+		"customerId": b.CustomerID,
+		"serviceId":  b.ServiceID,
+		"businessId": b.BusinessID,
+		"newStatus":  string(b.Status),
+		"startTime":  b.StartTime.Format(time.RFC3339),
+		"endTime":    b.EndTime.Format(time.RFC3339),
+	}
+	if reason != nil {
+		statusPayload["reason"] = *reason
+	}
+	if b.IsGuest() {
+		statusPayload["customer"] = guestCustomerPayload(b)
+	}
+
+	switch b.Status {
+	case models.BookingStatusConfirmed:
+		return []repository.OutboxMessage{
+			{Subject: events.BookingConfirmedEvent, Payload: statusPayload},
+			{
+				Subject: events.SlotReservedEvent,
+				Payload: map[string]interface{}{
+					"bookingId":  b.ID,
+					"serviceId":  b.ServiceID,
+					"businessId": b.BusinessID,
+					"startTime":  b.StartTime.Format(time.RFC3339),
+					"endTime":    b.EndTime.Format(time.RFC3339),
+				},
+			},
 		}
+	case models.BookingStatusCancelled:
+		return []repository.OutboxMessage{{Subject: events.BookingCancelledEvent, Payload: statusPayload}}
+	}
+	return nil
+}
+
+// suggestAlternativeStart returns the first available slot starting at or after the requested time,
+// looking up to maxSuggestionDays ahead. It returns nil if there is none or it cannot be determined.
+func (s *BookingService) suggestAlternativeStart(ctx context.Context, req CreateBookingRequest) *time.Time {
+	if s.availabilityService == nil {
+		return nil
 	}
+	slot, err := s.availabilityService.NextAvailableSlot(ctx, req.BusinessID, req.ServiceID, req.StartTime, maxSuggestionDays)
+	if err != nil {
+		s.logger.WarnContext(ctx, "Could not look up an alternative slot", "serviceId", req.ServiceID, "startTime", req.StartTime, "error", err)
+		return nil
+	}
+	if slot == nil {
+		return nil
+	}
+	return &slot.StartTime
+}
 
+// GetBookingDetails retrieves a booking by its ID.
+func (s *BookingService) GetBookingDetails(ctx context.Context, bookingID string) (*models.Booking, error) {
+	booking, err := s.bookingRepo.GetBookingByID(ctx, bookingID)
+	if errors.Is(err, repository.ErrBookingNotFound) {
+		s.logger.InfoContext(ctx, "Booking not found", "bookingId", bookingID)
+		return nil, err
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Error fetching booking details from repo", "bookingId", bookingID, "error", err)
+		return nil, fmt.Errorf("repository error fetching booking: %w", err)
+	}
+	s.attachServiceDisplay(ctx, booking)
+	s.attachCancellationPolicy(ctx, booking)
 	return booking, nil
 }
 
-// ListBookingsForCustomer retrieves bookings for a specific customer with pagination.
-func (s *BookingService) ListBookingsForCustomer(ctx context.Context, customerID string, limit, offset int) ([]models.Booking, int64, error) {
-	s.logger.Info("Listing bookings for customer", "customerId", customerID, "limit", limit, "offset", offset)
-	bookings, total, err := s.bookingRepo.GetBookingsByCustomerID(ctx, customerID, limit, offset)
+// attachCancellationPolicy fills in the cancellation terms of an active booking, so customers see the
+// cutoff and any fee before cancelling. Like the service display fields it is presentation only, so a
+// failed lookup is logged and leaves the policy out.
+func (s *BookingService) attachCancellationPolicy(ctx context.Context, booking *models.Booking) {
+	switch booking.Status {
+	case models.BookingStatusPendingPayment, models.BookingStatusConfirmed:
+	default:
+		return
+	}
+	business, err := s.serviceDefRepo.GetBusinessByID(ctx, booking.BusinessID)
 	if err != nil {
-		s.logger.Error("Error listing customer bookings from repo", "customerId", customerID, "error", err)
-		return nil, 0, fmt.Errorf("repository error listing customer bookings: %w", err)
+		s.logger.WarnContext(ctx, "Could not fetch business for cancellation policy", "bookingId", booking.ID, "error", err)
+		return
 	}
-	return bookings, total, nil
+	serviceDef, err := s.serviceDefRepo.GetServiceDefinition(ctx, booking.ServiceID)
+	if err != nil {
+		s.logger.WarnContext(ctx, "Could not fetch service for cancellation policy", "bookingId", booking.ID, "error", err)
+		return
+	}
+	policy := business.CancellationPolicyFor(booking, serviceDef, s.clock.Now())
+	booking.CancellationPolicy = &policy
 }
 
-// ListBookingsForBusiness retrieves bookings for a specific business with pagination.
-func (s *BookingService) ListBookingsForBusiness(ctx context.Context, businessID string, limit, offset int) ([]models.Booking, int64, error) {
-	s.logger.Info("Listing bookings for business", "businessId", businessID, "limit", limit, "offset", offset)
-	bookings, total, err := s.bookingRepo.GetBookingsByBusinessID(ctx, businessID, limit, offset)
+// attachServiceDisplay fills in the runtime service name, color and short label of the bookings.
+// They are presentation only, so a failed lookup is logged and leaves them empty.
+func (s *BookingService) attachServiceDisplay(ctx context.Context, bookings ...*models.Booking) {
+	if len(bookings) == 0 {
+		return
+	}
+	serviceIDs := make([]string, 0, len(bookings))
+	for _, b := range bookings {
+		serviceIDs = append(serviceIDs, b.ServiceID)
+	}
+	serviceDefs, err := s.serviceDefRepo.GetServiceDefinitionsByIDs(ctx, serviceIDs)
 	if err != nil {
-		s.logger.Error("Error listing business bookings from repo", "businessId", businessID, "error", err)
-		return nil, 0, fmt.Errorf("repository error listing business bookings: %w", err)
+		s.logger.WarnContext(ctx, "Could not fetch service details for bookings", "error", err)
+		return
+	}
+
+	byID := make(map[string]*models.ServiceDefinition, len(serviceDefs))
+	for i := range serviceDefs {
+		byID[serviceDefs[i].ID] = &serviceDefs[i]
+	}
+	for _, b := range bookings {
+		if serviceDef, ok := byID[b.ServiceID]; ok {
+			b.ServiceName = serviceDef.Name
+			b.ServiceColor = serviceDef.Color
+			b.ServiceShortLabel = serviceDef.ShortLabel
+		}
 	}
-	return bookings, total, nil
 }
 
-// HandlePaymentSucceeded handles payment success events (stub)
-func (s *BookingService) HandlePaymentSucceeded(data []byte) error {
-	// Example: Update booking status to Confirmed
-	// bookingId := ... // extract from data
-	// s.UpdateBookingStatus(context.Background(), bookingId, models.BookingStatusConfirmed)
-	s.logger.Info("Handling payment succeeded event (stub)")
-	return nil
+// bookingPointers returns pointers into bookings, for helpers that fill in fields in place.
+func bookingPointers(bookings []models.Booking) []*models.Booking {
+	pointers := make([]*models.Booking, len(bookings))
+	for i := range bookings {
+		pointers[i] = &bookings[i]
+	}
+	return pointers
 }
 
-// HandlePaymentFailed handles payment failure events (stub)
-func (s *BookingService) HandlePaymentFailed(data []byte) error {
-	// Example: Update booking status to Cancelled or PaymentFailed
-	// bookingId := ... // extract from data
-	// s.UpdateBookingStatus(context.Background(), bookingId, models.BookingStatusCancelled)
-	s.logger.Info("Handling payment failed event (stub)")
-	return nil
+// CancellationPolicyError is returned by CancelBookingAsCustomer when the business's
+// cancellation cutoff for the booking has already passed.
+type CancellationPolicyError struct {
+	Deadline time.Time // Last moment the customer could have cancelled
 }
 
-// NewAvailabilityService creates a new availability service
-func NewAvailabilityService(
-	availabilityRepo *repository.AvailabilityRepository,
-	bookingRepo *repository.BookingRepository, // Added
-	cacheRepo *repository.CacheRepository,
-	eventPublisher EventPublisher, // Interface
-	logger *logger.Logger,
-) *AvailabilityService {
-	return &AvailabilityService{
-		availabilityRepo: availabilityRepo,
-		bookingRepo:      bookingRepo, // Added
-		cacheRepo:        cacheRepo,
-		eventPublisher:   eventPublisher,
-		logger:           logger,
-	}
+func (e *CancellationPolicyError) Error() string {
+	return fmt.Sprintf("booking can no longer be cancelled: the cancellation deadline was %s", e.Deadline.Format(time.RFC3339))
 }
 
-// APISlot represents a single time slot for API responses
-type APISlot struct {
-	StartTime      time.Time `json:"startTime"`
-	EndTime        time.Time `json:"endTime"`
-	Available      bool      `json:"available"`
-	ConflictReason string    `json:"conflictReason,omitempty"`
+// customerCancellationReason is recorded on bookings cancelled by their own customer.
+const customerCancellationReason = "customer"
+
+// CancelBookingAsCustomer cancels a booking on behalf of its customer. Customers may only cancel
+// their own active bookings, and only before the business's cancellation cutoff.
+func (s *BookingService) CancelBookingAsCustomer(ctx context.Context, bookingID, customerID string) (*models.Booking, error) {
+	booking, err := s.bookingRepo.GetBookingByID(ctx, bookingID)
+	if errors.Is(err, repository.ErrBookingNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve booking %s: %w", bookingID, err)
+	}
+	if booking.CustomerID != customerID {
+		return nil, fmt.Errorf("booking %s: %w", bookingID, ErrBookingNotOwned)
+	}
+	switch booking.Status {
+	case models.BookingStatusPendingPayment, models.BookingStatusConfirmed:
+	default:
+		return nil, fmt.Errorf("booking %s cannot be cancelled in status %s", bookingID, booking.Status)
+	}
+
+	business, err := s.serviceDefRepo.GetBusinessByID(ctx, booking.BusinessID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching business %s: %w", booking.BusinessID, err)
+	}
+	deadline := booking.StartTime.Add(-business.CancellationCutoff())
+	if !s.clock.Now().Before(deadline) {
+		s.logger.InfoContext(ctx, "Customer cancellation rejected by policy", "bookingId", bookingID, "deadline", deadline)
+		return nil, &CancellationPolicyError{Deadline: deadline}
+	}
+
+	reason := customerCancellationReason
+	return s.UpdateBookingStatus(ctx, bookingID, UpdateBookingStatusRequest{
+		Status:    models.BookingStatusCancelled,
+		ChangedBy: customerID,
+		Reason:    &reason,
+	})
 }
 
-// GetAvailableSlots gets available time slots
-func (s *AvailabilityService) GetAvailableSlots(ctx context.Context, businessID string, serviceID string, dateToSchedule time.Time) ([]APISlot, error) {
-	s.logger.Info("Getting available slots", "businessID", businessID, "serviceID", serviceID, "date", dateToSchedule.Format("2006-01-02"))
+// RescheduleBooking moves an active booking to newStart, keeping its duration. The booking's customer may move it,
+// and so may anyone canManage allows for its business. The new time must be bookable, free and within the
+// business's daily quotas, checked as CreateBooking does under the business lock; the booking itself is left
+// out of the conflict check, so it can move to a time overlapping its current one. A confirmed booking's
+// reminder moves with it.
+func (s *BookingService) RescheduleBooking(ctx context.Context, bookingID string, newStart time.Time, requesterID string, canManage func(businessID string) bool) (*models.Booking, error) {
+	booking, err := s.bookingRepo.GetBookingByID(ctx, bookingID)
+	if errors.Is(err, repository.ErrBookingNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve booking %s: %w", bookingID, err)
+	}
+	if (booking.CustomerID == "" || booking.CustomerID != requesterID) && !canManage(booking.BusinessID) {
+		return nil, fmt.Errorf("booking %s: %w", bookingID, ErrBookingNotOwned)
+	}
+	switch booking.Status {
+	case models.BookingStatusPendingPayment, models.BookingStatusConfirmed:
+	default:
+		return nil, fmt.Errorf("booking %s cannot be rescheduled in status %s", bookingID, booking.Status)
+	}
 
-	// 1. Get Service Definition to find duration
-	serviceDef, err := s.availabilityRepo.GetServiceDefinition(ctx, serviceID) // Use injected availabilityRepo
+	serviceDef, err := s.serviceDefRepo.GetServiceDefinition(ctx, booking.ServiceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve service details: %w", err)
+	}
+	business, err := s.serviceDefRepo.GetBusinessByID(ctx, booking.BusinessID)
 	if err != nil {
-		s.logger.Error("Failed to get service definition", "serviceID", serviceID, "error", err)
-		return nil, fmt.Errorf("service definition for %s not found: %w", serviceID, err)
+		return nil, fmt.Errorf("failed to retrieve business details: %w", err)
+	}
+
+	req := CreateBookingRequest{BusinessID: booking.BusinessID, ServiceID: booking.ServiceID, CustomerID: booking.CustomerID, StartTime: newStart}
+	var newEnd time.Time
+	var outboxEvents []*models.OutboxEvent
+	err = s.serviceDefRepo.WithBusinessLock(ctx, booking.BusinessID, func(tx *gorm.DB) error {
+		availabilityRepo := s.serviceDefRepo.WithTx(tx)
+		bookingRepo := s.bookingRepo.WithTx(tx)
+		// Read again under the lock: a concurrent cancellation or reschedule may have changed the booking since
+		locked, err := bookingRepo.GetBookingByID(ctx, bookingID)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve booking %s: %w", bookingID, err)
+		}
+		if locked.Status != models.BookingStatusPendingPayment && locked.Status != models.BookingStatusConfirmed {
+			return fmt.Errorf("booking %s cannot be rescheduled in status %s", bookingID, locked.Status)
+		}
+		booking = locked
+		newEnd = newStart.Add(booking.EndTime.Sub(booking.StartTime))
+
+		if err := checkBookableTime(ctx, availabilityRepo, business, booking.BusinessID, newStart, newEnd, s.clock.Now()); err != nil {
+			return err
+		}
+		if _, _, err := s.checkSlotFree(ctx, availabilityRepo, bookingRepo, business, serviceDef, req, newEnd, booking.ID); err != nil {
+			return err
+		}
+		// A move within the same business-local day leaves the day's counts as they are
+		if quota := bookingQuota(business, newStart); booking.StartTime.Before(quota.From) || !booking.StartTime.Before(quota.To) {
+			moved := *booking
+			moved.StartTime, moved.EndTime = newStart, newEnd
+			if err := s.checkDailyQuotas(ctx, bookingRepo, business, &moved); err != nil {
+				return err
+			}
+		}
+		outboxEvents, err = bookingRepo.RescheduleBooking(ctx, booking.ID, newStart, newEnd, []repository.OutboxMessage{{
+			Subject: events.BookingRescheduledEvent,
+			Payload: map[string]interface{}{
+				"bookingId":         booking.ID,
+				"customerId":        booking.CustomerID,
+				"serviceId":         booking.ServiceID,
+				"businessId":        booking.BusinessID,
+				"previousStartTime": booking.StartTime.Format(time.RFC3339),
+				"previousEndTime":   booking.EndTime.Format(time.RFC3339),
+				"startTime":         newStart.Format(time.RFC3339),
+				"endTime":           newEnd.Format(time.RFC3339),
+				"rescheduledBy":     requesterID,
+			},
+		}})
+		if errors.Is(err, repository.ErrBookingNotActive) {
+			return fmt.Errorf("booking %s cannot be rescheduled: %w", bookingID, err)
+		}
+		return err
+	})
+	if err != nil {
+		s.logger.WarnContext(ctx, "Booking could not be rescheduled", "bookingId", bookingID, "startTime", newStart, "error", err)
+		return nil, s.withSuggestion(ctx, req, err)
+	}
+	s.logger.InfoContext(ctx, "Booking rescheduled", "bookingId", bookingID, "from", booking.StartTime, "to", newStart, "rescheduledBy", requesterID)
+
+	for _, outboxEvent := range outboxEvents {
+		if err := s.outboxRelay.publish(ctx, outboxEvent); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to publish reschedule event, left pending in outbox", "subject", outboxEvent.Subject, "bookingId", bookingID, "error", err)
+		}
+	}
+
+	booking.StartTime, booking.EndTime = newStart, newEnd
+
+	// The reminder for the old time must not go out; a pending booking gets its reminder once confirmed
+	if booking.Status == models.BookingStatusConfirmed && s.notificationClient != nil {
+		if _, err := s.notificationClient.CancelScheduledNotifications(booking.ID); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to cancel scheduled notifications of rescheduled booking", "bookingId", booking.ID, "error", err)
+		}
+		serviceName, businessName := s.notificationNames(ctx, booking)
+		contact := s.customerNotificationContact(ctx, booking)
+		s.scheduleReminder(ctx, booking, bookingTemplateData(booking, serviceName, businessName, contact.language), contact.email, contact.emailEnabled)
+	}
+	return booking, nil
+}
+
+// abandonedPaymentReason is recorded on pending bookings dropped by their customer before paying.
+const abandonedPaymentReason = "payment_abandoned"
+
+// CancelPendingBooking cancels a booking its customer has not paid for yet, freeing the slot straight away.
+// Unlike CancelBookingAsCustomer it only accepts PENDING_PAYMENT bookings and ignores the cancellation cutoff,
+// since nothing was committed to yet.
+func (s *BookingService) CancelPendingBooking(ctx context.Context, bookingID, customerID string) (*models.Booking, error) {
+	booking, err := s.bookingRepo.GetBookingByID(ctx, bookingID)
+	if errors.Is(err, repository.ErrBookingNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve booking %s: %w", bookingID, err)
+	}
+	if booking.CustomerID != customerID {
+		return nil, fmt.Errorf("booking %s: %w", bookingID, ErrBookingNotOwned)
+	}
+	if booking.Status != models.BookingStatusPendingPayment {
+		return nil, fmt.Errorf("booking %s is not pending payment: status is %s", bookingID, booking.Status)
+	}
+
+	s.logger.InfoContext(ctx, "Customer dropping unpaid booking", "bookingId", bookingID, "customerId", customerID)
+	reason := abandonedPaymentReason
+	return s.UpdateBookingStatus(ctx, bookingID, UpdateBookingStatusRequest{
+		Status:    models.BookingStatusCancelled,
+		ChangedBy: customerID,
+		Reason:    &reason,
+	})
+}
+
+// ConfirmationResendInterval is the minimum time between two resends of one booking's confirmation.
+const ConfirmationResendInterval = 5 * time.Minute
+
+// ConfirmationResendLimitError is returned by ResendConfirmation when the booking's confirmation
+// was resent less than ConfirmationResendInterval ago.
+type ConfirmationResendLimitError struct {
+	RetryAt time.Time // When the confirmation may be resent again
+}
+
+func (e *ConfirmationResendLimitError) Error() string {
+	return fmt.Sprintf("confirmation was resent too recently: try again after %s", e.RetryAt.Format(time.RFC3339))
+}
+
+// ResendConfirmation sends a confirmed booking's confirmation to its customer again, e.g. when the
+// original email was lost. Only the booking's customer or its business may ask, and each booking's
+// confirmation is resent at most once per ConfirmationResendInterval. The limit is kept in memory,
+// so it applies per service instance.
+func (s *BookingService) ResendConfirmation(ctx context.Context, bookingID, requesterID, requesterBusinessID string) (*models.Booking, error) {
+	booking, err := s.bookingRepo.GetBookingByID(ctx, bookingID)
+	if errors.Is(err, repository.ErrBookingNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve booking %s: %w", bookingID, err)
+	}
+	isCustomer := requesterID != "" && booking.CustomerID == requesterID
+	isBusiness := requesterBusinessID != "" && booking.BusinessID == requesterBusinessID
+	if !isCustomer && !isBusiness {
+		return nil, fmt.Errorf("booking %s: %w", bookingID, ErrBookingNotOwned)
+	}
+	if booking.Status != models.BookingStatusConfirmed {
+		return nil, fmt.Errorf("booking %s is not confirmed: status is %s", bookingID, booking.Status)
+	}
+	if s.notificationClient == nil {
+		return nil, fmt.Errorf("notifications are not configured")
+	}
+
+	contact := s.customerNotificationContact(ctx, booking)
+	if !contact.emailEnabled {
+		return nil, fmt.Errorf("customer of booking %s has email notifications disabled", bookingID)
+	}
+
+	// Reserve the slot before sending so concurrent requests can't both get through
+	now := s.clock.Now()
+	s.resendMu.Lock()
+	if last, ok := s.confirmationResends[bookingID]; ok && now.Sub(last) < ConfirmationResendInterval {
+		s.resendMu.Unlock()
+		return nil, &ConfirmationResendLimitError{RetryAt: last.Add(ConfirmationResendInterval)}
+	}
+	for id, last := range s.confirmationResends {
+		if now.Sub(last) >= ConfirmationResendInterval {
+			delete(s.confirmationResends, id) // Expired, so no longer limits anything
+		}
+	}
+	previous, hadPrevious := s.confirmationResends[bookingID]
+	s.confirmationResends[bookingID] = now
+	s.resendMu.Unlock()
+
+	serviceName, businessName := s.notificationNames(ctx, booking)
+	_, err = s.notificationClient.SendNotification(client.SendNotificationRequest{
+		Type:           "booking_confirmation",
+		RecipientEmail: contact.email,
+		TemplateData:   bookingTemplateData(booking, serviceName, businessName, contact.language),
+	})
+	if err != nil {
+		// Give the slot back so the customer can retry straight away
+		s.resendMu.Lock()
+		if hadPrevious {
+			s.confirmationResends[bookingID] = previous
+		} else {
+			delete(s.confirmationResends, bookingID)
+		}
+		s.resendMu.Unlock()
+		s.logger.ErrorContext(ctx, "Failed to resend booking confirmation", "bookingId", bookingID, "error", err)
+		return nil, fmt.Errorf("failed to resend confirmation for booking %s: %w", bookingID, err)
+	}
+
+	s.logger.InfoContext(ctx, "Booking confirmation resent", "bookingId", bookingID, "requestedBy", requesterID)
+	return booking, nil
+}
+
+// StatusTransitionError is returned by UpdateBookingStatus when the booking's current status cannot
+// move to the requested one, e.g. confirming a cancelled booking.
+type StatusTransitionError struct {
+	From models.BookingStatus
+	To   models.BookingStatus
+}
+
+func (e *StatusTransitionError) Error() string {
+	return fmt.Sprintf("booking status cannot change from %s to %s", e.From, e.To)
+}
+
+// MaxBulkStatusUpdates is the most bookings one UpdateBookingStatuses call may change.
+const MaxBulkStatusUpdates = 100
+
+// BulkStatusResult is the outcome of one booking in a bulk status update.
+type BulkStatusResult struct {
+	Success bool                 `json:"success"`
+	Status  models.BookingStatus `json:"status,omitempty"` // The booking's new status, on success
+	Error   string               `json:"error,omitempty"`
+}
+
+// UpdateBookingStatuses moves each of bookingIDs to req.Status independently, as UpdateBookingStatus
+// does, so one booking's invalid transition does not stop the others and each change fires its own
+// events. The emails of all the changes go out together once every booking is done. Results are keyed by booking ID. canManage is asked about each booking's business before its
+// transition; bookings of a business it rejects are reported as forbidden and left unchanged.
+func (s *BookingService) UpdateBookingStatuses(ctx context.Context, bookingIDs []string, req UpdateBookingStatusRequest, canManage func(businessID string) bool) (map[string]BulkStatusResult, error) {
+	if len(bookingIDs) > MaxBulkStatusUpdates {
+		return nil, fmt.Errorf("too many bookings: at most %d can be updated at once", MaxBulkStatusUpdates)
+	}
+	if !req.Status.IsValid() {
+		return nil, fmt.Errorf("invalid status %q", req.Status)
+	}
+
+	s.logger.InfoContext(ctx, "Updating booking statuses in bulk", "count", len(bookingIDs), "newStatus", req.Status, "changedBy", req.ChangedBy)
+	results := make(map[string]BulkStatusResult, len(bookingIDs))
+	batch := &notificationBatch{}
+	defer s.flushNotifications(ctx, batch)
+	for _, bookingID := range bookingIDs {
+		if _, seen := results[bookingID]; seen {
+			continue
+		}
+		booking, err := s.bookingRepo.GetBookingByID(ctx, bookingID)
+		if err != nil {
+			results[bookingID] = BulkStatusResult{Error: err.Error()}
+			continue
+		}
+		if !canManage(booking.BusinessID) {
+			s.logger.WarnContext(ctx, "Bulk status update of another business's booking refused", "bookingId", bookingID, "changedBy", req.ChangedBy)
+			results[bookingID] = BulkStatusResult{Error: fmt.Sprintf("booking %s does not belong to the requester's business: forbidden", bookingID)}
+			continue
+		}
+		updated, err := s.updateBookingStatus(ctx, bookingID, req, batch)
+		if err != nil {
+			results[bookingID] = BulkStatusResult{Error: err.Error()}
+			continue
+		}
+		results[bookingID] = BulkStatusResult{Success: true, Status: updated.Status}
+	}
+	return results, nil
+}
+
+// ErrBookingAccessDenied is returned when the requester may not see or change a booking of another business.
+var ErrBookingAccessDenied = errors.New("booking does not belong to the requester's business")
+
+// ErrSeriesCancellationDenied is returned when a cancellation scoped beyond one occurrence is requested by
+// anyone but the business or the booking's customer.
+var ErrSeriesCancellationDenied = errors.New("only the business or the booking's customer can cancel other occurrences of a series")
+
+// UpdateBookingStatusRequest defines the input for updating a booking's status.
+type UpdateBookingStatusRequest struct {
+	Status    models.BookingStatus `json:"status"`
+	ChangedBy string               `json:"changedBy,omitempty"` // User ID of the actor; empty for system-initiated changes
+	Reason    *string              `json:"reason,omitempty"`
+	// Scope applies a cancellation to other occurrences of the booking's series; empty means this occurrence only
+	Scope models.CancellationScope `json:"scope,omitempty"`
+	// CanManage reports whether the requester manages a business; set for API requests, nil for system changes
+	CanManage func(businessID string) bool `json:"-"`
+}
+
+// UpdateBookingStatus changes the status of a booking and records the transition in its status history.
+func (s *BookingService) UpdateBookingStatus(ctx context.Context, bookingID string, req UpdateBookingStatusRequest) (*models.Booking, error) {
+	return s.updateBookingStatus(ctx, bookingID, req, nil)
+}
+
+// updateBookingStatus is UpdateBookingStatus, adding the emails it sends to batch instead when batch is not nil.
+func (s *BookingService) updateBookingStatus(ctx context.Context, bookingID string, req UpdateBookingStatusRequest, batch *notificationBatch) (*models.Booking, error) {
+	newStatus := req.Status
+	s.logger.InfoContext(ctx, "Updating booking status", "bookingId", bookingID, "newStatus", newStatus, "changedBy", req.ChangedBy)
+
+	// Validate newStatus if necessary (e.g., allowed transitions)
+	// For MVP, direct update.
+
+	booking, err := s.bookingRepo.GetBookingByID(ctx, bookingID)
+	if errors.Is(err, repository.ErrBookingNotFound) {
+		s.logger.WarnContext(ctx, "Booking not found for status update", "bookingId", bookingID)
+		return nil, err
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to get booking for status update", "bookingId", bookingID, "error", err)
+		return nil, fmt.Errorf("failed to retrieve booking %s: %w", bookingID, err)
+	}
+	if req.CanManage != nil && !req.CanManage(booking.BusinessID) {
+		s.logger.WarnContext(ctx, "Status update of another business's booking refused", "bookingId", bookingID, "changedBy", req.ChangedBy)
+		return nil, ErrBookingAccessDenied
+	}
+
+	switch req.Scope {
+	case "", models.CancellationScopeOccurrence:
+	case models.CancellationScopeFollowing, models.CancellationScopeSeries:
+		if newStatus != models.BookingStatusCancelled {
+			return nil, fmt.Errorf("invalid cancellation scope: %s only applies to cancellations", req.Scope)
+		}
+		// Cancelling occurrences other than this one is never a system change
+		managed := req.CanManage != nil && req.CanManage(booking.BusinessID)
+		if !managed && (req.ChangedBy == "" || req.ChangedBy != booking.CustomerID) {
+			s.logger.WarnContext(ctx, "Series cancellation refused", "bookingId", bookingID, "scope", req.Scope, "changedBy", req.ChangedBy)
+			return nil, ErrSeriesCancellationDenied
+		}
+		return s.cancelSeries(ctx, booking, req, batch)
+	default:
+		return nil, fmt.Errorf("invalid cancellation scope %q", req.Scope)
+	}
+
+	if !booking.Status.CanTransitionTo(newStatus) {
+		s.logger.WarnContext(ctx, "Rejected booking status transition", "bookingId", bookingID, "from", booking.Status, "to", newStatus)
+		return nil, &StatusTransitionError{From: booking.Status, To: newStatus}
+	}
+	oldStatus := booking.Status
+
+	// Fetch service definition for service name and duration (needed for notifications)
+	serviceName, businessName := s.notificationNames(ctx, booking)
+	contact := s.customerNotificationContact(ctx, booking)
+
+	// The status change and its events are committed together and then published through the outbox,
+	// so a failed publish is retried by the relay instead of leaving consumers with half the events.
+	booking.Status = newStatus
+	outboxEvents, err := s.bookingRepo.UpdateBookingStatusWithHistory(ctx, bookingID, oldStatus, newStatus, req.ChangedBy, req.Reason, bookingStatusMessages(booking, req.Reason))
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to update booking status in database", "bookingId", bookingID, "error", err)
+		return nil, fmt.Errorf("failed to update status for booking %s: %w", bookingID, err)
+	}
+
+	booking.UpdatedAt = s.clock.Now() // Should be handled by GORM hooks ideally, or manually set
+
+	for _, outboxEvent := range outboxEvents {
+		if err := s.outboxRelay.publish(ctx, outboxEvent); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to publish booking status event, left pending in outbox", "subject", outboxEvent.Subject, "bookingId", booking.ID, "error", err)
+		} else {
+			s.logger.InfoContext(ctx, "Published booking status event", "subject", outboxEvent.Subject, "bookingId", booking.ID)
+		}
+	}
+
+	// ---- Notification Logic ----
+	if s.notificationClient != nil {
+		commonTemplateData := bookingTemplateData(booking, serviceName, businessName, contact.language)
+
+		switch newStatus {
+		case models.BookingStatusConfirmed:
+			s.sendConfirmedNotifications(ctx, booking, commonTemplateData, serviceName, contact.email, contact.emailEnabled)
+
+		case models.BookingStatusCancelled:
+			cancellationTemplateData := commonTemplateData
+			if req.Reason != nil {
+				cancellationTemplateData["cancellationReason"] = *req.Reason
+			}
+
+			// Send Booking Cancellation to Customer
+			if contact.emailEnabled {
+				customerCancellationReq := client.SendNotificationRequest{
+					Type:           "booking_cancellation",
+					RecipientEmail: contact.email,
+					TemplateData:   cancellationTemplateData,
+				}
+				if batch != nil {
+					batch.add(booking.ID, customerCancellationReq)
+				} else if _, err := s.notificationClient.SendNotification(customerCancellationReq); err != nil {
+					s.logger.ErrorContext(ctx, "Failed to send booking cancellation to customer", "bookingId", booking.ID, "error", err)
+				}
+			} else {
+				s.logger.InfoContext(ctx, "Customer has email notifications disabled, suppressing booking cancellation", "bookingId", booking.ID, "customerId", booking.CustomerID)
+			}
+			// Reminders for a booking that will not happen must not go out
+			if _, err := s.notificationClient.CancelScheduledNotifications(booking.ID); err != nil {
+				s.logger.ErrorContext(ctx, "Failed to cancel scheduled notifications", "bookingId", booking.ID, "error", err)
+			}
+			// Optionally, notify business about cancellation
+
+		default:
+			s.logger.InfoContext(ctx, "No notification for status update", "bookingId", booking.ID, "newStatus", newStatus)
+		}
+	}
+	// ---- End Notification Logic ----
+
+	return booking, nil
+}
+
+// sendConfirmedNotifications sends the confirmation emails for a newly confirmed booking and schedules its
+// reminder, whether it was confirmed on creation or later.
+func (s *BookingService) sendConfirmedNotifications(ctx context.Context, booking *models.Booking, commonTemplateData map[string]interface{}, serviceName, customerEmail string, customerEmailEnabled bool) {
+	var businessEmail string = "business@example.com" // Placeholder for business copy
+
+	// TODO: Fetch actual business email/details
+	// businessDetails, errBiz := s.businessRepo.GetBusiness(ctx, booking.BusinessID)
+	// if errBiz == nil && businessDetails != nil { businessName = businessDetails.Name; businessEmail = businessDetails.NotificationEmailOrDefault() }
+
+	// 1. Send Booking Confirmation to Customer
+	if customerEmailEnabled {
+		customerConfirmationReq := client.SendNotificationRequest{
+			Type:           "booking_confirmation",
+			RecipientEmail: customerEmail,
+			TemplateData:   commonTemplateData,
+		}
+		_, err := s.notificationClient.SendNotification(customerConfirmationReq)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to send booking confirmation to customer", "bookingId", booking.ID, "error", err)
+			// Non-critical, log and continue
+		}
+	} else {
+		s.logger.InfoContext(ctx, "Customer has email notifications disabled, suppressing booking confirmation", "bookingId", booking.ID, "customerId", booking.CustomerID)
+	}
+
+	// 2. Send Booking Confirmation to Business (optional, if configured)
+	// Assuming businessEmail is fetched or configured
+	// The business's language is not known here, so its copy uses the default language
+	businessTemplateData := make(map[string]interface{}, len(commonTemplateData))
+	for k, v := range commonTemplateData {
+		businessTemplateData[k] = v
+	}
+	businessTemplateData["language"] = models.DefaultLanguage
+	businessConfirmationReq := client.SendNotificationRequest{
+		Type:           "booking_confirmation", // Could be a different template like "new_booking_alert"
+		RecipientEmail: businessEmail,          // Placeholder
+		TemplateData:   businessTemplateData,   // Might need different data for business
+		Subject:        func(s string) *string { return &s }(fmt.Sprintf("New Booking Confirmed: %s for %s", serviceName, commonTemplateData["userName"])),
+	}
+	_, err := s.notificationClient.SendNotification(businessConfirmationReq)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to send booking confirmation to business", "bookingId", booking.ID, "error", err)
+	}
+
+	// 3. Schedule Booking Reminder for Customer
+	s.scheduleReminder(ctx, booking, commonTemplateData, customerEmail, customerEmailEnabled)
+}
+
+// scheduleReminder schedules the customer's reminder 24 hours before a confirmed booking, unless that is
+// already past or the customer turned email notifications off.
+func (s *BookingService) scheduleReminder(ctx context.Context, booking *models.Booking, templateData map[string]interface{}, customerEmail string, customerEmailEnabled bool) {
+	reminderTime := booking.StartTime.Add(-24 * time.Hour)
+	// Ensure reminderTime is in the future
+	if !customerEmailEnabled {
+		s.logger.InfoContext(ctx, "Customer has email notifications disabled, suppressing booking reminder", "bookingId", booking.ID, "customerId", booking.CustomerID)
+	} else if reminderTime.After(s.clock.Now()) {
+		scheduleReq := client.ScheduleNotificationRequest{
+			Type:           "booking_reminder",
+			RecipientEmail: customerEmail,
+			TemplateData:   templateData,
+			ScheduledFor:   reminderTime,
+			BookingID:      booking.ID,
+			IdempotencyKey: client.ScheduleIdempotencyKey(booking.ID, "booking_reminder", reminderTime),
+		}
+		if _, err := s.notificationClient.ScheduleNotification(scheduleReq); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to schedule booking reminder", "bookingId", booking.ID, "error", err)
+		}
+	} else {
+		s.logger.InfoContext(ctx, "Booking reminder time is in the past, not scheduling.", "bookingId", booking.ID, "reminderTime", reminderTime)
+	}
+}
+
+// notificationNames returns the service and business names shown in a booking's notifications.
+func (s *BookingService) notificationNames(ctx context.Context, booking *models.Booking) (serviceName, businessName string) {
+	if booking.ServiceID == "" {
+		return "", ""
+	}
+	// Potentially fetch Business Name via businessId from serviceDef or booking.BusinessID
+	// For now, using placeholder:
+	businessName = fmt.Sprintf("Business %s", booking.BusinessID)
+	serviceDef, err := s.serviceDefRepo.GetServiceDefinition(ctx, booking.ServiceID)
+	if err != nil {
+		s.logger.WarnContext(ctx, "Could not fetch service details for notification data", "bookingId", booking.ID, "serviceId", booking.ServiceID, "error", err)
+		return "Unknown Service", businessName
+	}
+	return serviceDef.Name, businessName
+}
+
+// customerContact is where and how a booking's customer notifications are sent.
+type customerContact struct {
+	email        string
+	language     string // ISO 639-1 code of the notification templates
+	emailEnabled bool   // Whether the customer accepts email notifications
+}
+
+// customerNotificationContact returns the contact details a booking's customer notifications use.
+// Customer contact details and notification preferences are synced from the Auth Service, and the
+// stored language wins over the one given with the booking request.
+// Customers without a stored preference are treated as opted in.
+// Guests have no account or stored preferences; they gave their email and language for this booking.
+func (s *BookingService) customerNotificationContact(ctx context.Context, booking *models.Booking) customerContact {
+	contact := customerContact{email: "customer@example.com", language: booking.CustomerLanguage, emailEnabled: true} // Placeholder email
+	if booking.IsGuest() {
+		contact.email = *booking.GuestEmail
+	} else {
+		customerPref, err := s.customerPrefRepo.GetByCustomerID(ctx, booking.CustomerID)
+		if err != nil {
+			s.logger.WarnContext(ctx, "Could not fetch customer notification preferences", "bookingId", booking.ID, "customerId", booking.CustomerID, "error", err)
+		} else if customerPref != nil {
+			if customerPref.Email != "" {
+				contact.email = customerPref.Email
+			}
+			if customerPref.Language != "" {
+				contact.language = customerPref.Language
+			}
+			contact.emailEnabled = customerPref.EmailNotifications
+		}
+	}
+	if contact.language == "" {
+		contact.language = models.DefaultLanguage
+	}
+	return contact
+}
+
+// bookingTemplateData builds the template data shared by a booking's notifications in the given language.
+func bookingTemplateData(booking *models.Booking, serviceName, businessName, language string) map[string]interface{} {
+	// The language lets the notification service pick a localized template
+	templateData := map[string]interface{}{
+		"language":     language,
+		"userName":     fmt.Sprintf("Customer %s", booking.CustomerID), // Placeholder
+		"businessName": businessName,
+		"serviceName":  serviceName,
+		"bookingId":    booking.ID,
+		"bookingDate":  booking.StartTime.Format("January 2, 2006"),
+		"bookingTime":  booking.StartTime.Format("3:04 PM"),
+		"duration":     booking.EndTime.Sub(booking.StartTime).Minutes(),
+		// "resourceName": // If applicable
+		// "notes": booking.Notes, // If applicable
+	}
+	if booking.GuestName != nil {
+		templateData["userName"] = *booking.GuestName
+	}
+	return templateData
+}
+
+// cancelSeries cancels the occurrences of the booking's series selected by req.Scope. Each occurrence
+// is cancelled on its own, so it gets its own history entry, events and notifications; the emails are
+// added to batch, or sent together at the end when batch is nil. It returns the given booking after cancellation.
+func (s *BookingService) cancelSeries(ctx context.Context, booking *models.Booking, req UpdateBookingStatusRequest, batch *notificationBatch) (*models.Booking, error) {
+	if booking.SeriesID == nil {
+		return nil, fmt.Errorf("invalid cancellation scope: booking %s is not part of a series", booking.ID)
+	}
+
+	var from *time.Time
+	if req.Scope == models.CancellationScopeFollowing {
+		from = &booking.StartTime
+	}
+	occurrences, err := s.bookingRepo.GetActiveSeriesBookings(ctx, *booking.SeriesID, from)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to get series bookings", "seriesId", *booking.SeriesID, "error", err)
+		return nil, fmt.Errorf("failed to retrieve series %s: %w", *booking.SeriesID, err)
+	}
+	s.logger.InfoContext(ctx, "Cancelling series occurrences", "seriesId", *booking.SeriesID, "scope", req.Scope, "occurrences", len(occurrences))
+
+	if batch == nil {
+		batch = &notificationBatch{}
+		defer s.flushNotifications(ctx, batch)
+	}
+	occurrenceReq := req
+	occurrenceReq.Scope = models.CancellationScopeOccurrence
+	cancelled := booking
+	failed := 0
+	for _, occurrence := range occurrences {
+		updated, err := s.updateBookingStatus(ctx, occurrence.ID, occurrenceReq, batch)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to cancel series occurrence", "seriesId", *booking.SeriesID, "bookingId", occurrence.ID, "error", err)
+			failed++
+			continue
+		}
+		if updated.ID == booking.ID {
+			cancelled = updated
+		}
+	}
+	if failed > 0 {
+		return nil, fmt.Errorf("failed to cancel %d of %d occurrences of series %s", failed, len(occurrences), *booking.SeriesID)
+	}
+	return cancelled, nil
+}
+
+// notificationBatch collects the emails of a change to many bookings, so they are sent in a few SendBatch
+// requests instead of one request per booking.
+type notificationBatch struct {
+	reqs       []client.SendNotificationRequest
+	bookingIDs []string // The booking each request is about
+}
+
+func (b *notificationBatch) add(bookingID string, req client.SendNotificationRequest) {
+	b.reqs = append(b.reqs, req)
+	b.bookingIDs = append(b.bookingIDs, bookingID)
+}
+
+// flushNotifications sends the emails collected in batch, logging each one that failed.
+func (s *BookingService) flushNotifications(ctx context.Context, batch *notificationBatch) {
+	if s.notificationClient == nil || len(batch.reqs) == 0 {
+		return
+	}
+	results, err := s.notificationClient.SendBatch(batch.reqs)
+	if err != nil && len(results) == 0 {
+		s.logger.ErrorContext(ctx, "Failed to send batched notifications", "count", len(batch.reqs), "error", err)
+		return
+	}
+	for _, result := range results {
+		if result.Success || result.Index < 0 || result.Index >= len(batch.reqs) {
+			continue
+		}
+		var reason string
+		if result.Error != nil {
+			reason = *result.Error
+		}
+		s.logger.ErrorContext(ctx, "Failed to send batched notification", "type", batch.reqs[result.Index].Type, "bookingId", batch.bookingIDs[result.Index], "error", reason)
+	}
+	s.logger.InfoContext(ctx, "Sent batched notifications", "count", len(batch.reqs))
+}
+
+// GetBookingStatusHistory returns the status transitions of a booking, oldest first, if canManage accepts
+// the booking's business.
+func (s *BookingService) GetBookingStatusHistory(ctx context.Context, bookingID string, canManage func(businessID string) bool) ([]models.BookingStatusHistory, error) {
+	booking, err := s.bookingRepo.GetBookingByID(ctx, bookingID)
+	if err != nil {
+		if errors.Is(err, repository.ErrBookingNotFound) {
+			return nil, err
+		}
+		s.logger.ErrorContext(ctx, "Failed to get booking for status history", "bookingId", bookingID, "error", err)
+		return nil, fmt.Errorf("failed to retrieve booking %s: %w", bookingID, err)
+	}
+	if !canManage(booking.BusinessID) {
+		return nil, ErrBookingAccessDenied
+	}
+
+	history, err := s.bookingRepo.GetBookingStatusHistory(ctx, bookingID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to get booking status history", "bookingId", bookingID, "error", err)
+		return nil, fmt.Errorf("repository error fetching status history: %w", err)
+	}
+	return history, nil
+}
+
+// ListBookingsForCustomer retrieves bookings for a specific customer with pagination.
+// A timeframe other than BookingTimeframeAll lists only upcoming or only past bookings as of now.
+func (s *BookingService) ListBookingsForCustomer(ctx context.Context, customerID string, timeframe models.BookingTimeframe, limit, offset int) ([]models.Booking, int64, error) {
+	s.logger.InfoContext(ctx, "Listing bookings for customer", "customerId", customerID, "timeframe", timeframe, "limit", limit, "offset", offset)
+	bookings, total, err := s.bookingRepo.GetBookingsByCustomerID(ctx, customerID, timeframe, s.clock.Now(), limit, offset)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Error listing customer bookings from repo", "customerId", customerID, "error", err)
+		return nil, 0, fmt.Errorf("repository error listing customer bookings: %w", err)
+	}
+	s.attachServiceDisplay(ctx, bookingPointers(bookings)...)
+	return bookings, total, nil
+}
+
+// ListBookingsForBusiness retrieves bookings for a specific business with pagination.
+// Bookings in any of statuses are listed; no statuses lists bookings of every status.
+// A non-empty search narrows the list to bookings whose customer or service matches it.
+func (s *BookingService) ListBookingsForBusiness(ctx context.Context, businessID string, statuses []models.BookingStatus, search string, limit, offset int) ([]models.Booking, int64, error) {
+	s.logger.InfoContext(ctx, "Listing bookings for business", "businessId", businessID, "statuses", statuses, "search", search, "limit", limit, "offset", offset)
+	bookings, total, err := s.bookingRepo.GetBookingsByBusinessID(ctx, businessID, statuses, search, limit, offset)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Error listing business bookings from repo", "businessId", businessID, "error", err)
+		return nil, 0, fmt.Errorf("repository error listing business bookings: %w", err)
+	}
+	s.attachServiceDisplay(ctx, bookingPointers(bookings)...)
+	return bookings, total, nil
+}
+
+// RevenueSummary is the revenue of a business over a period, from COMPLETED bookings only
+type RevenueSummary struct {
+	BusinessID string                   `json:"businessId"`
+	From       time.Time                `json:"from"`
+	To         time.Time                `json:"to"`
+	Services   []models.ServiceRevenue  `json:"services"`
+	Totals     []models.CurrencyRevenue `json:"totals"` // One entry per currency; amounts in different currencies are never added
+	// Converted adds up Totals in the requested target currency; nil when no target currency was requested.
+	Converted *models.ConvertedRevenue `json:"converted,omitempty"`
+}
+
+// RevenueSummary sums the prices of COMPLETED bookings starting in [from, to), per service and per currency.
+// Cancelled and no-show bookings are not counted. A non-empty targetCurrency also converts the totals
+// into that currency with the configured ExchangeRateSource; an unconvertible currency returns ErrNoExchangeRate.
+func (s *BookingService) RevenueSummary(ctx context.Context, businessID string, from, to time.Time, targetCurrency string) (*RevenueSummary, error) {
+	if businessID == "" {
+		return nil, fmt.Errorf("businessID cannot be empty")
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("from date %s cannot be after to date %s", from.Format(time.RFC3339), to.Format(time.RFC3339))
+	}
+
+	s.logger.InfoContext(ctx, "Calculating revenue summary", "businessId", businessID, "from", from, "to", to)
+	services, err := s.bookingRepo.GetRevenueByService(ctx, businessID, from, to)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Error summing revenue from repo", "businessId", businessID, "error", err)
+		return nil, fmt.Errorf("repository error summing revenue: %w", err)
+	}
+
+	summary := &RevenueSummary{
+		BusinessID: businessID,
+		From:       from,
+		To:         to,
+		Services:   services,
+		Totals:     []models.CurrencyRevenue{},
+	}
+	if summary.Services == nil {
+		summary.Services = []models.ServiceRevenue{}
+	}
+
+	totalsByCurrency := make(map[string]int)
+	for i := range summary.Services {
+		line := &summary.Services[i]
+		if serviceDef, err := s.serviceDefRepo.GetServiceDefinition(ctx, line.ServiceID); err == nil {
+			line.ServiceName = serviceDef.Name
+		}
+
+		idx, ok := totalsByCurrency[line.Currency]
+		if !ok {
+			idx = len(summary.Totals)
+			totalsByCurrency[line.Currency] = idx
+			summary.Totals = append(summary.Totals, models.CurrencyRevenue{Currency: line.Currency})
+		}
+		summary.Totals[idx].TotalAmount += line.TotalAmount
+		summary.Totals[idx].BookingCount += line.BookingCount
+	}
+	sort.Slice(summary.Totals, func(i, j int) bool { return summary.Totals[i].Currency < summary.Totals[j].Currency })
+
+	if targetCurrency != "" {
+		summary.Converted, err = convertRevenue(ctx, s.exchangeRates, summary.Totals, targetCurrency)
+		if err != nil {
+			s.logger.WarnContext(ctx, "Cannot convert revenue summary", "businessId", businessID, "targetCurrency", targetCurrency, "error", err)
+			return nil, err
+		}
+	}
+
+	return summary, nil
+}
+
+// DashboardStats are the headline booking numbers for a business's dashboard
+type DashboardStats struct {
+	BusinessID string `json:"businessId"`
+	Timezone   string `json:"timezone"`  // The business timezone "today" and "this week" are measured in
+	Date       string `json:"date"`      // Today in the business timezone, YYYY-MM-DD
+	WeekStart  string `json:"weekStart"` // Monday of the current week, YYYY-MM-DD
+	models.DashboardCounts
+}
+
+// DashboardStats counts a business's bookings for today, for this week (Monday to Sunday), upcoming bookings
+// still awaiting payment, and this week's cancellations. Today and the week are calendar days in the business's
+// timezone at the service clock's current time; a business that never reported a timezone uses UTC.
+func (s *BookingService) DashboardStats(ctx context.Context, businessID string) (*DashboardStats, error) {
+	if businessID == "" {
+		return nil, fmt.Errorf("businessID cannot be empty")
+	}
+
+	business, err := s.serviceDefRepo.GetBusinessByID(ctx, businessID)
+	if err != nil {
+		return nil, fmt.Errorf("could not load business %s: %w", businessID, err)
+	}
+	now := s.clock.Now()
+	localNow := now.In(business.Location())
+	dayStart := time.Date(localNow.Year(), localNow.Month(), localNow.Day(), 0, 0, 0, 0, localNow.Location())
+	// time.Weekday counts from Sunday; shift so the week starts on Monday
+	weekStart := dayStart.AddDate(0, 0, -((int(dayStart.Weekday()) + 6) % 7))
+
+	counts, err := s.bookingRepo.GetDashboardCounts(ctx, businessID, dayStart, dayStart.AddDate(0, 0, 1), weekStart, weekStart.AddDate(0, 0, 7), now)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Error counting dashboard bookings from repo", "businessId", businessID, "error", err)
+		return nil, fmt.Errorf("repository error counting dashboard bookings: %w", err)
+	}
+
+	return &DashboardStats{
+		BusinessID:      businessID,
+		Timezone:        localNow.Location().String(),
+		Date:            dayStart.Format("2006-01-02"),
+		WeekStart:       weekStart.Format("2006-01-02"),
+		DashboardCounts: counts,
+	}, nil
+}
+
+// ExpirePendingBookings cancels bookings still awaiting payment that were created before cutoff,
+// releasing their slots. It returns how many bookings were expired.
+func (s *BookingService) ExpirePendingBookings(ctx context.Context, cutoff time.Time) (int, error) {
+	stale, err := s.bookingRepo.GetPendingBookingsCreatedBefore(ctx, cutoff, expiryBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("error loading pending bookings to expire: %w", err)
+	}
+
+	reason := "Payment was not received in time"
+	expired := 0
+	for _, booking := range stale {
+		if _, err := s.UpdateBookingStatus(ctx, booking.ID, UpdateBookingStatusRequest{
+			Status:    models.BookingStatusCancelled,
+			ChangedBy: "system",
+			Reason:    &reason,
+		}); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to expire pending booking", "bookingId", booking.ID, "error", err)
+			continue
+		}
+		expired++
+	}
+
+	if len(stale) > 0 {
+		s.logger.InfoContext(ctx, "Expired pending bookings", "found", len(stale), "expired", expired, "cutoff", cutoff)
+	}
+	return expired, nil
+}
+
+// HandlePaymentSucceeded handles payment success events (stub)
+func (s *BookingService) HandlePaymentSucceeded(data []byte) error {
+	// Example: Update booking status to Confirmed
+	// bookingId := ... // extract from data
+	// s.UpdateBookingStatus(context.Background(), bookingId, UpdateBookingStatusRequest{Status: models.BookingStatusConfirmed})
+	s.logger.Info("Handling payment succeeded event (stub)")
+	return nil
+}
+
+// HandlePaymentFailed handles payment failure events (stub)
+func (s *BookingService) HandlePaymentFailed(data []byte) error {
+	// Example: Update booking status to Cancelled or PaymentFailed
+	// bookingId := ... // extract from data
+	// s.UpdateBookingStatus(context.Background(), bookingId, UpdateBookingStatusRequest{Status: models.BookingStatusCancelled})
+	s.logger.Info("Handling payment failed event (stub)")
+	return nil
+}
+
+// NewAvailabilityService creates a new availability service
+func NewAvailabilityService(
+	availabilityRepo *repository.AvailabilityRepository,
+	bookingRepo *repository.BookingRepository, // Added
+	cacheRepo *repository.CacheRepository,
+	slotHoldRepo *repository.SlotHoldRepository,
+	slotHoldTTL time.Duration, // Falls back to DefaultSlotHoldTTL when zero
+	maxSlotsPerDay int, // Falls back to DefaultMaxSlotsPerDay when zero
+	eventPublisher EventPublisher, // Interface
+	clk clock.Clock, // Falls back to the system clock when nil
+	logger *logger.Logger,
+) *AvailabilityService {
+	if slotHoldTTL <= 0 {
+		slotHoldTTL = DefaultSlotHoldTTL
+	}
+	if maxSlotsPerDay <= 0 {
+		maxSlotsPerDay = DefaultMaxSlotsPerDay
+	}
+	return &AvailabilityService{
+		availabilityRepo: availabilityRepo,
+		bookingRepo:      bookingRepo, // Added
+		cacheRepo:        cacheRepo,
+		slotHoldRepo:     slotHoldRepo,
+		slotHoldTTL:      slotHoldTTL,
+		maxSlotsPerDay:   maxSlotsPerDay,
+		dayparts:         DefaultDaypartBoundaries,
+		eventPublisher:   eventPublisher,
+		clock:            clock.OrReal(clk),
+		logger:           logger,
+	}
+}
+
+// APISlot represents a single time slot for API responses
+type APISlot struct {
+	StartTime      time.Time `json:"startTime"`
+	EndTime        time.Time `json:"endTime"`
+	Available      bool      `json:"available"`
+	ConflictReason string    `json:"conflictReason,omitempty"`
+	// RemainingCapacity is the number of places left in a group service's slot; it is omitted for one-to-one services
+	RemainingCapacity int `json:"remainingCapacity,omitempty"`
+}
+
+// MarshalJSON renders the slot's times as RFC3339 in UTC, like models.Booking, rather than in the
+// business's timezone they are generated in.
+func (slot APISlot) MarshalJSON() ([]byte, error) {
+	type slotJSON APISlot // Drops this method to avoid recursion
+	out := slotJSON(slot)
+	out.StartTime = out.StartTime.UTC()
+	out.EndTime = out.EndTime.UTC()
+	return json.Marshal(out)
+}
+
+// GetAvailableSlots gets available time slots, at most the configured per-day maximum
+func (s *AvailabilityService) GetAvailableSlots(ctx context.Context, businessID string, serviceID string, dateToSchedule time.Time) ([]APISlot, error) {
+	slots, _, err := s.GetAvailableSlotsCapped(ctx, businessID, serviceID, dateToSchedule)
+	return slots, err
+}
+
+// GetAvailableSlotsCapped is GetAvailableSlots that also reports whether the slots were truncated,
+// i.e. the rules allowed more slots than the configured per-day maximum and only the first ones are returned.
+func (s *AvailabilityService) GetAvailableSlotsCapped(ctx context.Context, businessID string, serviceID string, dateToSchedule time.Time) ([]APISlot, bool, error) {
+	s.logger.InfoContext(ctx, "Getting available slots", "businessID", businessID, "serviceID", serviceID, "date", dateToSchedule.Format("2006-01-02"))
+
+	// 1. Get Service Definition to find duration
+	serviceDef, err := s.availabilityRepo.GetServiceDefinition(ctx, serviceID) // Use injected availabilityRepo
+	if errors.Is(err, repository.ErrServiceDefinitionNotFound) {
+		s.logger.WarnContext(ctx, "Service definition not found", "serviceID", serviceID)
+		return nil, false, err
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to get service definition", "serviceID", serviceID, "error", err)
+		return nil, false, fmt.Errorf("failed to retrieve service definition %s: %w", serviceID, err)
+	}
+
+	// 2. Check if service is active
+	if !serviceDef.IsActive {
+		s.logger.WarnContext(ctx, "Service definition is not active", "serviceID", serviceID)
+		return nil, false, fmt.Errorf("%w: %s", ErrServiceNotActive, serviceID)
+	}
+	if serviceDef.BusinessID != businessID {
+		s.logger.ErrorContext(ctx, "Service definition does not belong to the given business", "serviceID", serviceID, "serviceBusinessID", serviceDef.BusinessID, "queryBusinessID", businessID)
+		return nil, false, fmt.Errorf("%w: service %s, business %s", ErrServiceNotInBusiness, serviceID, businessID)
+	}
+
+	// A service can outlive its business if the deletion reached us first
+	business, err := s.availabilityRepo.GetBusinessByID(ctx, businessID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to get business", "businessID", businessID, "error", err)
+		return nil, false, fmt.Errorf("could not get business %s: %w", businessID, err)
+	}
+	if business != nil && business.DeletedAt.Valid {
+		s.logger.WarnContext(ctx, "Slots requested for a deleted business", "businessID", businessID, "serviceID", serviceID)
+		return nil, false, fmt.Errorf("%w: %s has been deleted", ErrBusinessNotActive, businessID)
+	}
+	if business != nil && !business.IsActive() {
+		s.logger.WarnContext(ctx, "Slots requested for an inactive business", "businessID", businessID, "status", business.Status)
+		return nil, false, fmt.Errorf("%w: %s", ErrBusinessNotActive, businessID)
+	}
+	if business != nil && !business.AcceptingBookings {
+		s.logger.InfoContext(ctx, "Business has paused bookings, returning no slots", "businessID", businessID)
+		return []APISlot{}, false, nil
+	}
+	dateToSchedule = s.businessLocalDate(business, dateToSchedule)
+
+	// 2. Determine DayOfWeek for the given date
+	dayOfWeekToSchedule := models.DayOfWeekString(dateToSchedule.Weekday().String()) // time.Weekday.String() returns "Monday", "Tuesday" etc.
+	// Our DayOfWeekString enum is "MONDAY", "TUESDAY". Need to convert.
+	dayOfWeekToSchedule = models.DayOfWeekString(strings.ToUpper(dateToSchedule.Weekday().String()))
+
+	// 3. Get Availability Rules for that business and day
+	rules, err := s.availabilityRepo.GetActiveAvailabilityRules(ctx, businessID, dayOfWeekToSchedule) // Use injected availabilityRepo
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to get availability rules", "businessID", businessID, "dayOfWeek", dayOfWeekToSchedule, "error", err)
+		return nil, false, fmt.Errorf("could not get availability rules for %s on %s: %w", businessID, dayOfWeekToSchedule, err)
+	}
+
+	if len(rules) == 0 {
+		s.logger.InfoContext(ctx, "No availability rules found for business", "businessID", businessID, "dayOfWeek", dayOfWeekToSchedule)
+		return []APISlot{}, false, nil // No rules means no slots
+	}
+
+	// 4. Fetch existing bookings for the day for conflict checking
+	// Define the start and end of the day for fetching bookings
+	dayStart := time.Date(dateToSchedule.Year(), dateToSchedule.Month(), dateToSchedule.Day(), 0, 0, 0, 0, dateToSchedule.Location())
+	dayEnd := dayStart.Add(24 * time.Hour)
+
+	// Fetch relevant bookings that are CONFIRMED or PENDING_PAYMENT
+	relevantBookingStatuses := []models.BookingStatus{models.BookingStatusConfirmed, models.BookingStatusPendingPayment}
+	existingBookings, err := s.bookingRepo.GetBookingsForBusinessByDateRangeAndStatuses(ctx, businessID, dayStart, dayEnd, relevantBookingStatuses)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to fetch existing bookings for conflict checking", "businessID", businessID, "date", dateToSchedule.Format("2006-01-02"), "error", err)
+		return nil, false, fmt.Errorf("could not fetch existing bookings: %w", err)
+	}
+
+	// Held slots are hidden until the hold is released or expires
+	holds, err := s.slotHoldRepo.GetByBusinessID(ctx, businessID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to fetch slot holds for conflict checking", "businessID", businessID, "error", err)
+		return nil, false, fmt.Errorf("could not fetch slot holds: %w", err)
+	}
+
+	// Generate one slot past the cap to tell whether anything was cut off
+	generatedSlots := s.generateSlots(dateToSchedule, rules, serviceDef, existingBookings, holds, business.BackToBackAllowed(), time.Time{}, s.maxSlotsPerDay+1)
+	truncated := len(generatedSlots) > s.maxSlotsPerDay
+	if truncated {
+		generatedSlots = generatedSlots[:s.maxSlotsPerDay]
+		s.logger.WarnContext(ctx, "Slot generation hit the per-day maximum, truncating", "max", s.maxSlotsPerDay, "businessID", businessID, "serviceID", serviceID, "date", dateToSchedule.Format("2006-01-02"))
+	}
+
+	s.logger.InfoContext(ctx, "Generated available slots", "count", len(generatedSlots), "businessID", businessID, "serviceID", serviceID, "date", dateToSchedule.Format("2006-01-02"))
+	return generatedSlots, truncated, nil
+}
+
+// businessLocalDate returns midnight of date's calendar day in the business's timezone, since availability
+// rules are in the business's local time. Businesses that never reported a timezone use date as given.
+func (s *AvailabilityService) businessLocalDate(business *models.Business, date time.Time) time.Time {
+	if business == nil || business.Timezone == "" {
+		return date
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, business.Location())
+}
+
+// errQuotaReached is returned for a booking that would go past the business's daily booking limits.
+func errQuotaReached() *SlotUnavailableError {
+	return &SlotUnavailableError{Reason: SlotUnavailableQuotaReached, Message: "no more bookings can be made with this business on that day"}
+}
+
+// checkDailyQuotas returns a QUOTA_REACHED SlotUnavailableError if booking would go past the business's daily
+// booking limits. It only validates; CreateBookingWithinQuota enforces the limits under the business lock.
+func (s *BookingService) checkDailyQuotas(ctx context.Context, bookingRepo *repository.BookingRepository, business *models.Business, booking *models.Booking) error {
+	err := bookingRepo.CheckBookingQuota(ctx, booking, bookingQuota(business, booking.StartTime))
+	if errors.Is(err, repository.ErrBookingQuotaExceeded) {
+		s.logger.WarnContext(ctx, "Booking would exceed the business's daily quota", "businessId", booking.BusinessID, "customerId", booking.CustomerID, "error", err)
+		return errQuotaReached()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check booking quota: %w", err)
+	}
+	return nil
+}
+
+// bookingQuota returns the business's daily booking limits for the business-local day a booking starting at
+// start falls on. A nil business has none.
+func bookingQuota(business *models.Business, start time.Time) repository.BookingQuota {
+	if business == nil {
+		return repository.BookingQuota{}
+	}
+	local := start.In(business.Location())
+	from := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+	quota := repository.BookingQuota{From: from, To: from.AddDate(0, 0, 1)}
+	if business.MaxBookingsPerDay != nil {
+		quota.MaxPerBusiness = int64(*business.MaxBookingsPerDay)
+	}
+	if business.MaxBookingsPerCustomerPerDay != nil {
+		quota.MaxPerCustomer = int64(*business.MaxBookingsPerCustomerPerDay)
+	}
+	return quota
+}
+
+// slotDate returns the date, in the form GetAvailableSlots takes it, of the business's calendar day on which
+// a slot starting at start falls.
+func slotDate(business *models.Business, start time.Time) time.Time {
+	local := start.In(business.Location())
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// generateSlots lays out slots of the given duration over the rules for dateToSchedule and drops those
+// overlapping a booking or hold, counting the rule's buffers on both sides. Setup before the first slot
+// happens within the rule's hours, while cleanup after the last one may run past them.
+// Unless allowBackToBack is set, slots that start when a booking ends or end when one starts are dropped too,
+// and unless notBefore is zero so are slots starting before it. It stops after limit slots, or generates all
+// of them when limit is 0.
+func (s *AvailabilityService) generateSlots(dateToSchedule time.Time, rules []models.AvailabilityRule, serviceDef *models.ServiceDefinition, existingBookings []models.Booking, holds []models.SlotHold, allowBackToBack bool, notBefore time.Time, limit int) []APISlot {
+	var generatedSlots []APISlot
+	serviceDuration := time.Duration(serviceDef.DurationMinutes) * time.Minute
+	if serviceDuration <= 0 {
+		// Slots would never advance; such services are rejected on sync, but rows may predate that
+		s.logger.Error("Service duration is not positive, generating no slots", "durationMinutes", serviceDef.DurationMinutes)
+		return nil
+	}
+
+	for _, rule := range rules {
+		ruleStartTimeStr := rule.StartTime
+		ruleEndTimeStr := rule.EndTime
+		loc := dateToSchedule.Location()
+
+		stH, stM, errSt := parseHHMM(ruleStartTimeStr)
+		if errSt != nil {
+			s.logger.Error("Invalid rule start time format", "ruleId", rule.ID, "startTime", ruleStartTimeStr, "error", errSt)
+			continue
+		}
+		etH, etM, errEt := parseHHMM(ruleEndTimeStr)
+		if errEt != nil {
+			s.logger.Error("Invalid rule end time format", "ruleId", rule.ID, "endTime", ruleEndTimeStr, "error", errEt)
+			continue
+		}
+
+		periodStart := time.Date(dateToSchedule.Year(), dateToSchedule.Month(), dateToSchedule.Day(), stH, stM, 0, 0, loc)
+		periodEnd := time.Date(dateToSchedule.Year(), dateToSchedule.Month(), dateToSchedule.Day(), etH, etM, 0, 0, loc)
+		bufferBefore, bufferAfter := rule.Buffers()
+		// A slot's setup and cleanup must not overlap a neighbour's, so it keeps both buffers clear on each side
+		padding := bufferBefore + bufferAfter
+
+		currentPotentialSlotStart := periodStart.Add(bufferBefore)
+		for {
+			slotActualEnd := currentPotentialSlotStart.Add(serviceDuration)
+			if slotActualEnd.After(periodEnd) {
+				break
+			}
+			paddedStart, paddedEnd := currentPotentialSlotStart.Add(-padding), slotActualEnd.Add(padding)
+
+			// Slots starting before notBefore can no longer be booked
+			isConflict := currentPotentialSlotStart.Before(notBefore)
+			placesTaken := 0
+			// Check for conflicts with existing bookings; those sharing a group slot only take a place in it
+			for _, booking := range existingBookings {
+				if isConflict {
+					break
+				}
+				if sharesSlot(booking, serviceDef, currentPotentialSlotStart, slotActualEnd) {
+					placesTaken++
+					continue
+				}
+				// Check if [paddedStart, paddedEnd) overlaps with [booking.StartTime, booking.EndTime)
+				if paddedStart.Before(booking.EndTime) && paddedEnd.After(booking.StartTime) {
+					isConflict = true
+					s.logger.Debug("Slot conflict detected", "slotStart", currentPotentialSlotStart, "slotEnd", slotActualEnd, "bookingID", booking.ID)
+					break
+				}
+				if !allowBackToBack && (currentPotentialSlotStart.Equal(booking.EndTime) || slotActualEnd.Equal(booking.StartTime)) {
+					isConflict = true
+					s.logger.Debug("Slot is back to back with a booking", "slotStart", currentPotentialSlotStart, "slotEnd", slotActualEnd, "bookingID", booking.ID)
+					break
+				}
+			}
+			if placesTaken >= serviceDef.SlotCapacity() {
+				isConflict = true
+			}
+			for _, hold := range holds {
+				if isConflict {
+					break
+				}
+				if hold.Overlaps(paddedStart, paddedEnd) {
+					isConflict = true
+					s.logger.Debug("Slot is held", "slotStart", currentPotentialSlotStart, "slotEnd", slotActualEnd, "holdID", hold.ID)
+				}
+			}
+
+			if !isConflict {
+				slot := APISlot{
+					StartTime: currentPotentialSlotStart,
+					EndTime:   slotActualEnd,
+					Available: true, // By definition, if we're adding it, it's available
+				}
+				if serviceDef.SlotCapacity() > 1 {
+					slot.RemainingCapacity = serviceDef.SlotCapacity() - placesTaken
+				}
+				generatedSlots = append(generatedSlots, slot)
+				if limit > 0 && len(generatedSlots) >= limit {
+					return generatedSlots
+				}
+			}
+
+			// Advance to the next potential slot start time, leaving this slot's cleanup and the next one's setup
+			nextSlotStart := slotActualEnd.Add(padding)
+			if !nextSlotStart.After(currentPotentialSlotStart) {
+				s.logger.Error("Slot start did not advance, stopping rule", "ruleId", rule.ID, "bufferBeforeMinutes", rule.BufferBeforeMinutes, "bufferAfterMinutes", rule.BufferAfterMinutes)
+				break
+			}
+			currentPotentialSlotStart = nextSlotStart
+		}
+	}
+	return generatedSlots
+}
+
+// NextAvailableSlot returns the first available slot that starts at or after the given time,
+// searching at most days days including the day of after. It returns nil if none is found.
+func (s *AvailabilityService) NextAvailableSlot(ctx context.Context, businessID string, serviceID string, after time.Time, days int) (*APISlot, error) {
+	for day := 0; day < days; day++ {
+		slots, err := s.GetAvailableSlots(ctx, businessID, serviceID, after.AddDate(0, 0, day))
+		if err != nil {
+			return nil, err
+		}
+		for i := range slots {
+			if !slots[i].StartTime.Before(after) {
+				return &slots[i], nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// MaxAlternativeSuggestions caps how many slots SuggestAlternatives returns.
+const MaxAlternativeSuggestions = 10
+
+// SuggestAlternatives returns up to count available slots nearest to desiredStart, before or after it,
+// ordered by proximity (earlier first on ties). Slots in the past are skipped, and the search widens
+// one day at a time on both sides up to maxSuggestionDays days away.
+func (s *AvailabilityService) SuggestAlternatives(ctx context.Context, businessID string, serviceID string, desiredStart time.Time, count int) ([]APISlot, error) {
+	if count <= 0 {
+		return []APISlot{}, nil
+	}
+	count = min(count, MaxAlternativeSuggestions)
+
+	now := s.clock.Now()
+	distance := func(slot APISlot) time.Duration {
+		d := slot.StartTime.Sub(desiredStart)
+		if d < 0 {
+			return -d
+		}
+		return d
+	}
+
+	candidates := []APISlot{}
+	for ring := 0; ring <= maxSuggestionDays; ring++ {
+		offsets := []int{ring}
+		if ring > 0 {
+			offsets = []int{-ring, ring}
+		}
+		for _, offset := range offsets {
+			date := desiredStart.AddDate(0, 0, offset)
+			dayStart := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+			if !dayStart.Add(24 * time.Hour).After(now) {
+				continue // The whole day is over
+			}
+			slots, err := s.GetAvailableSlots(ctx, businessID, serviceID, date)
+			if err != nil {
+				return nil, err
+			}
+			for _, slot := range slots {
+				if !slot.StartTime.Before(now) {
+					candidates = append(candidates, slot)
+				}
+			}
+		}
+
+		sort.SliceStable(candidates, func(i, j int) bool {
+			di, dj := distance(candidates[i]), distance(candidates[j])
+			if di != dj {
+				return di < dj
+			}
+			return candidates[i].StartTime.Before(candidates[j].StartTime)
+		})
+		// Days not searched yet are more than ring days away, so nothing there can be closer
+		if len(candidates) >= count && distance(candidates[count-1]) <= time.Duration(ring)*24*time.Hour {
+			break
+		}
+	}
+
+	if len(candidates) > count {
+		candidates = candidates[:count]
+	}
+	return candidates, nil
+}
+
+// HasAvailability reports whether any active service of the business has at least one open slot on date
+// that can still be booked.
+// Rules, bookings and holds are loaded once and the search stops at the first open slot.
+func (s *AvailabilityService) HasAvailability(ctx context.Context, businessID string, date time.Time) (bool, error) {
+	slot, err := s.openSlotOnDate(ctx, businessID, date, false)
+	if err != nil {
+		return false, err
+	}
+	return slot != nil, nil
+}
+
+const (
+	// MaxSnapshotBusinesses caps how many businesses one availability snapshot may cover.
+	MaxSnapshotBusinesses = 50
+	// snapshotWorkers bounds how many businesses are looked up at once.
+	snapshotWorkers = 8
+)
+
+// Codes reported in AvailabilitySnapshot.Error.
+const (
+	SnapshotErrorBusinessNotActive = "BUSINESS_NOT_ACTIVE" // The business is suspended or deleted
+	SnapshotErrorLookupFailed      = "LOOKUP_FAILED"       // The business's availability could not be read
+)
+
+// AvailabilitySnapshot is one business's availability on a day.
+type AvailabilitySnapshot struct {
+	BusinessID      string   `json:"businessId"`
+	HasAvailability bool     `json:"hasAvailability"`
+	NextSlot        *APISlot `json:"nextSlot,omitempty"` // Earliest open slot across the business's services
+	Error           string   `json:"error,omitempty"`    // A SnapshotError code, set when this business could not be looked up
+}
+
+// GetAvailabilitySnapshot looks up the availability of several businesses on date concurrently.
+// Results are in the order of businessIDs; a failed lookup is reported on its entry rather than failing the call.
+func (s *AvailabilityService) GetAvailabilitySnapshot(ctx context.Context, businessIDs []string, date time.Time) ([]AvailabilitySnapshot, error) {
+	if len(businessIDs) == 0 {
+		return nil, fmt.Errorf("businessIds cannot be empty")
 	}
-	if serviceDef == nil {
-		s.logger.Warn("Service definition not found", "serviceID", serviceID)
-		return nil, fmt.Errorf("service definition %s not found", serviceID)
+	if len(businessIDs) > MaxSnapshotBusinesses {
+		return nil, fmt.Errorf("too many businessIds: at most %d are allowed", MaxSnapshotBusinesses)
 	}
 
-	// 2. Check if service is active
-	if !serviceDef.IsActive {
-		s.logger.Warn("Service definition is not active", "serviceID", serviceID)
-		return nil, fmt.Errorf("service %s not found or is not active", serviceID)
+	snapshots := make([]AvailabilitySnapshot, len(businessIDs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(snapshotWorkers, len(businessIDs)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				snapshot := AvailabilitySnapshot{BusinessID: businessIDs[i]}
+				slot, err := s.openSlotOnDate(ctx, businessIDs[i], date, true)
+				if errors.Is(err, ErrBusinessNotActive) {
+					snapshot.Error = SnapshotErrorBusinessNotActive
+				} else if err != nil {
+					s.logger.WarnContext(ctx, "Availability snapshot lookup failed", "businessID", businessIDs[i], "error", err)
+					snapshot.Error = SnapshotErrorLookupFailed
+				} else if slot != nil {
+					snapshot.HasAvailability = true
+					snapshot.NextSlot = slot
+				}
+				snapshots[i] = snapshot
+			}
+		}()
 	}
-	if serviceDef.BusinessID != businessID {
-		s.logger.Error("Service definition does not belong to the given business", "serviceID", serviceID, "serviceBusinessID", serviceDef.BusinessID, "queryBusinessID", businessID)
-		return nil, fmt.Errorf("service %s does not belong to business %s", serviceID, businessID)
+	for i := range businessIDs {
+		jobs <- i
 	}
+	close(jobs)
+	wg.Wait()
 
-	// 2. Determine DayOfWeek for the given date
-	dayOfWeekToSchedule := models.DayOfWeekString(dateToSchedule.Weekday().String()) // time.Weekday.String() returns "Monday", "Tuesday" etc.
-	// Our DayOfWeekString enum is "MONDAY", "TUESDAY". Need to convert.
-	dayOfWeekToSchedule = models.DayOfWeekString(strings.ToUpper(dateToSchedule.Weekday().String()))
+	return snapshots, nil
+}
 
-	// 3. Get Availability Rules for that business and day
-	rules, err := s.availabilityRepo.GetAvailabilityRulesFiltered(ctx, businessID, dayOfWeekToSchedule) // Use injected availabilityRepo
+// openSlotOnDate returns an open slot of any active service of the business on date, or nil if there is none.
+// Only slots that can still be booked count: those already started or inside the business's minimum notice
+// are skipped. With earliest set it checks every service and returns the earliest slot; otherwise it returns
+// the first one found.
+func (s *AvailabilityService) openSlotOnDate(ctx context.Context, businessID string, date time.Time, earliest bool) (*APISlot, error) {
+	business, err := s.availabilityRepo.GetBusinessByID(ctx, businessID)
 	if err != nil {
-		s.logger.Error("Failed to get availability rules", "businessID", businessID, "dayOfWeek", dayOfWeekToSchedule, "error", err)
-		return nil, fmt.Errorf("could not get availability rules for %s on %s: %w", businessID, dayOfWeekToSchedule, err)
+		return nil, fmt.Errorf("could not get business %s: %w", businessID, err)
+	}
+	if business != nil && !business.IsActive() {
+		return nil, fmt.Errorf("%w: %s", ErrBusinessNotActive, businessID)
 	}
+	if business != nil && !business.AcceptingBookings {
+		return nil, nil
+	}
+	date = s.businessLocalDate(business, date)
 
+	dayOfWeek := models.DayOfWeekString(strings.ToUpper(date.Weekday().String()))
+	rules, err := s.availabilityRepo.GetActiveAvailabilityRules(ctx, businessID, dayOfWeek)
+	if err != nil {
+		return nil, fmt.Errorf("could not get availability rules for %s on %s: %w", businessID, dayOfWeek, err)
+	}
 	if len(rules) == 0 {
-		s.logger.Info("No availability rules found for business", "businessID", businessID, "dayOfWeek", dayOfWeekToSchedule)
-		return []APISlot{}, nil // No rules means no slots
+		return nil, nil
 	}
 
-	// 4. Fetch existing bookings for the day for conflict checking
-	// Define the start and end of the day for fetching bookings
-	dayStart := time.Date(dateToSchedule.Year(), dateToSchedule.Month(), dateToSchedule.Day(), 0, 0, 0, 0, dateToSchedule.Location())
-	dayEnd := dayStart.Add(24 * time.Hour)
+	serviceDefs, err := s.availabilityRepo.GetActiveServiceDefinitionsForBusiness(ctx, businessID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get services for business %s: %w", businessID, err)
+	}
+	if len(serviceDefs) == 0 {
+		return nil, nil
+	}
 
-	// Fetch relevant bookings that are CONFIRMED or PENDING_PAYMENT
+	dayStart := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
 	relevantBookingStatuses := []models.BookingStatus{models.BookingStatusConfirmed, models.BookingStatusPendingPayment}
-	existingBookings, err := s.bookingRepo.GetBookingsForBusinessByDateRangeAndStatuses(ctx, businessID, dayStart, dayEnd, relevantBookingStatuses)
+	existingBookings, err := s.bookingRepo.GetBookingsForBusinessByDateRangeAndStatuses(ctx, businessID, dayStart, dayStart.Add(24*time.Hour), relevantBookingStatuses)
 	if err != nil {
-		s.logger.Error("Failed to fetch existing bookings for conflict checking", "businessID", businessID, "date", dateToSchedule.Format("2006-01-02"), "error", err)
 		return nil, fmt.Errorf("could not fetch existing bookings: %w", err)
 	}
+	holds, err := s.slotHoldRepo.GetByBusinessID(ctx, businessID)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch slot holds: %w", err)
+	}
 
-	var generatedSlots []APISlot
-	serviceDuration := time.Duration(serviceDef.DurationMinutes) * time.Minute
-
-	for _, rule := range rules {
-		ruleStartTimeStr := rule.StartTime
-		ruleEndTimeStr := rule.EndTime
-		loc := dateToSchedule.Location()
-
-		stH, stM, errSt := parseHHMM(ruleStartTimeStr)
-		if errSt != nil {
-			s.logger.Error("Invalid rule start time format", "ruleId", rule.ID, "startTime", ruleStartTimeStr, "error", errSt)
+	notBefore := earliestBookableStart(business, s.clock.Now())
+	var found *APISlot
+	for _, serviceDef := range serviceDefs {
+		if serviceDef.DurationMinutes <= 0 {
 			continue
 		}
-		etH, etM, errEt := parseHHMM(ruleEndTimeStr)
-		if errEt != nil {
-			s.logger.Error("Invalid rule end time format", "ruleId", rule.ID, "endTime", ruleEndTimeStr, "error", errEt)
+		// Rules come ordered by start time, so a service's first generated slot is its earliest
+		slots := s.generateSlots(date, rules, &serviceDef, existingBookings, holds, business.BackToBackAllowed(), notBefore, 1)
+		if len(slots) == 0 {
 			continue
 		}
+		if !earliest {
+			return &slots[0], nil
+		}
+		if found == nil || slots[0].StartTime.Before(found.StartTime) {
+			found = &slots[0]
+		}
+	}
+	return found, nil
+}
 
-		periodStart := time.Date(dateToSchedule.Year(), dateToSchedule.Month(), dateToSchedule.Day(), stH, stM, 0, 0, loc)
-		periodEnd := time.Date(dateToSchedule.Year(), dateToSchedule.Month(), dateToSchedule.Day(), etH, etM, 0, 0, loc)
-		bufferDuration := time.Duration(rule.BufferMinutes) * time.Minute
+// HoldSlot reserves an available slot for userID for a short time so it cannot be taken while the customer
+// pays. The hold expires on its own; pass its ID as CreateBookingRequest.HoldID, with the same user as
+// RequestedBy, to book the held slot.
+func (s *AvailabilityService) HoldSlot(ctx context.Context, businessID string, serviceID string, userID string, start time.Time) (*models.SlotHold, error) {
+	return s.CheckAndReserve(ctx, businessID, serviceID, userID, start)
+}
 
-		currentPotentialSlotStart := periodStart
-		for {
-			slotActualEnd := currentPotentialSlotStart.Add(serviceDuration)
-			if slotActualEnd.After(periodEnd) {
-				break
+// CheckAndReserve checks that start is an open slot of the service and holds it as one step. Both happen
+// under the business lock (see WithBusinessLock), so concurrent reservations of the last open slot, or of overlapping slots,
+// are settled one at a time and exactly one succeeds; checking first and holding in a separate call would
+// let several callers see the slot open. A business takes one appointment at a time, so any overlapping
+// booking or hold leaves a slot no capacity, except that a group service's slot stays open to bookings of
+// the same slot until its places are taken. A hold always takes the whole slot.
+func (s *AvailabilityService) CheckAndReserve(ctx context.Context, businessID string, serviceID string, userID string, start time.Time) (*models.SlotHold, error) {
+	s.logger.InfoContext(ctx, "Holding slot", "businessID", businessID, "serviceID", serviceID, "userID", userID, "startTime", start)
+
+	var hold *models.SlotHold
+	err := s.availabilityRepo.WithBusinessLock(ctx, businessID, func(tx *gorm.DB) error {
+		var err error
+		hold, err = s.withTx(tx).reserveSlot(ctx, businessID, serviceID, userID, start)
+		return err
+	})
+	if err != nil {
+		if hold != nil {
+			// The hold was written but the lock's transaction did not commit; do not leave the slot taken
+			if releaseErr := s.slotHoldRepo.Release(ctx, hold); releaseErr != nil {
+				s.logger.ErrorContext(ctx, "Failed to release slot hold after failed reservation", "holdID", hold.ID, "error", releaseErr)
 			}
+		}
+		return nil, err
+	}
+	s.logger.InfoContext(ctx, "Slot held", "holdID", hold.ID, "expiresAt", hold.ExpiresAt)
+	return hold, nil
+}
 
-			// Check for conflicts with existing bookings
-			isConflict := false
-			for _, booking := range existingBookings {
-				// Check if [currentPotentialSlotStart, slotActualEnd) overlaps with [booking.StartTime, booking.EndTime)
-				if currentPotentialSlotStart.Before(booking.EndTime) && slotActualEnd.After(booking.StartTime) {
-					isConflict = true
-					s.logger.Debug("Slot conflict detected", "slotStart", currentPotentialSlotStart, "slotEnd", slotActualEnd, "bookingID", booking.ID)
-					break
-				}
-			}
+// withTx returns a copy of the service whose database reads and writes run in tx.
+func (s *AvailabilityService) withTx(tx *gorm.DB) *AvailabilityService {
+	scoped := *s
+	scoped.availabilityRepo = s.availabilityRepo.WithTx(tx)
+	scoped.bookingRepo = s.bookingRepo.WithTx(tx)
+	return &scoped
+}
 
-			if !isConflict {
-				generatedSlots = append(generatedSlots, APISlot{
-					StartTime: currentPotentialSlotStart,
-					EndTime:   slotActualEnd,
-					Available: true, // By definition, if we're adding it, it's available
-				})
-			}
+// reserveSlot holds start for userID if GetAvailableSlots offers it and the user is under MaxHoldsPerUser.
+// Call it on a service scoped to the business lock's transaction, as CheckAndReserve does.
+func (s *AvailabilityService) reserveSlot(ctx context.Context, businessID string, serviceID string, userID string, start time.Time) (*models.SlotHold, error) {
+	business, err := s.availabilityRepo.GetBusinessByID(ctx, businessID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to get business", "businessID", businessID, "error", err)
+		return nil, fmt.Errorf("could not get business %s: %w", businessID, err)
+	}
+
+	// Counted under the business lock, so concurrent holds by the same user cannot pass the cap together
+	holds, err := s.slotHoldRepo.GetByBusinessID(ctx, businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get slot holds: %w", err)
+	}
+	userHolds := 0
+	for _, h := range holds {
+		if h.UserID == userID {
+			userHolds++
+		}
+	}
+	if userHolds >= MaxHoldsPerUser {
+		s.logger.WarnContext(ctx, "User already holds too many slots", "businessID", businessID, "userID", userID, "holds", userHolds)
+		return nil, ErrTooManyHolds
+	}
 
-			// Advance to the next potential slot start time, including buffer
-			currentPotentialSlotStart = slotActualEnd.Add(bufferDuration)
+	// Only slots that GetAvailableSlots would offer can be held; this also rejects booked and held slots
+	slots, err := s.GetAvailableSlots(ctx, businessID, serviceID, slotDate(business, start))
+	if err != nil {
+		return nil, err
+	}
+	var slot *APISlot
+	for i := range slots {
+		if slots[i].StartTime.Equal(start) {
+			slot = &slots[i]
+			break
 		}
 	}
+	if slot == nil {
+		s.logger.WarnContext(ctx, "Requested slot is not available to hold", "businessID", businessID, "serviceID", serviceID, "startTime", start)
+		return nil, ErrSlotNotAvailable
+	}
 
-	s.logger.Info("Generated available slots", "count", len(generatedSlots), "businessID", businessID, "serviceID", serviceID, "date", dateToSchedule.Format("2006-01-02"))
-	return generatedSlots, nil
+	hold := &models.SlotHold{
+		ID:         uuid.New().String(),
+		BusinessID: businessID,
+		ServiceID:  serviceID,
+		UserID:     userID,
+		StartTime:  slot.StartTime,
+		EndTime:    slot.EndTime,
+		ExpiresAt:  s.clock.Now().Add(s.slotHoldTTL),
+	}
+	// SETNX settles concurrent holds on the same start time; only one caller wins
+	created, err := s.slotHoldRepo.Create(ctx, hold, s.slotHoldTTL)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to create slot hold", "businessID", businessID, "startTime", start, "error", err)
+		return nil, fmt.Errorf("failed to hold slot: %w", err)
+	}
+	if !created {
+		s.logger.WarnContext(ctx, "Slot was held concurrently by another customer", "businessID", businessID, "startTime", start)
+		return nil, ErrSlotNotAvailable
+	}
+	return hold, nil
 }
 
 // parseHHMM is a helper to parse "HH:MM" string to hours and minutes
@@ -540,6 +2285,16 @@ func parseHHMM(timeStr string) (int, int, error) {
 	return hour, minute, nil
 }
 
+// normalizeHHMM parses an "H:MM" or "HH:MM" time and returns it zero-padded as "HH:MM"
+// together with its minutes since midnight.
+func normalizeHHMM(timeStr string) (string, int, error) {
+	hour, minute, err := parseHHMM(strings.TrimSpace(timeStr))
+	if err != nil {
+		return "", 0, err
+	}
+	return fmt.Sprintf("%02d:%02d", hour, minute), hour*60 + minute, nil
+}
+
 // HandleServiceUpdated handles service update events (stub)
 func (s *AvailabilityService) HandleServiceUpdated(data []byte) error {
 	// This handler is for the "service.updated" event.
@@ -555,73 +2310,420 @@ func (s *AvailabilityService) HandleServiceUpdated(data []byte) error {
 	return nil
 }
 
+// GetAvailabilityVersion returns the current availability version of a business.
+// The version changes whenever the business's rules or bookings do; it is 0 for a business with no recorded changes.
+func (s *AvailabilityService) GetAvailabilityVersion(ctx context.Context, businessID string) (int64, error) {
+	version, err := s.availabilityRepo.GetAvailabilityVersion(ctx, businessID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to get availability version", "businessID", businessID, "error", err)
+		return 0, fmt.Errorf("could not get availability version for %s: %w", businessID, err)
+	}
+	return version, nil
+}
+
+// BusinessLocation returns the timezone of a business, UTC for one that is unknown or never reported one.
+func (s *AvailabilityService) BusinessLocation(ctx context.Context, businessID string) (*time.Location, error) {
+	business, err := s.availabilityRepo.GetBusinessByID(ctx, businessID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get business %s: %w", businessID, err)
+	}
+	return business.Location(), nil
+}
+
+// SetAcceptingBookings pauses or resumes new bookings for a business.
+// Existing bookings and availability rules are left untouched. Businesses are created by
+// the business service, so an unknown or deleted business is reported as not found.
+func (s *AvailabilityService) SetAcceptingBookings(ctx context.Context, businessID string, accepting bool) (*models.Business, error) {
+	if businessID == "" {
+		return nil, fmt.Errorf("businessID cannot be empty")
+	}
+
+	business, err := s.availabilityRepo.SetBusinessAcceptingBookings(ctx, businessID, accepting)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to update accepting bookings", "businessID", businessID, "error", err)
+		return nil, fmt.Errorf("could not update business %s: %w", businessID, err)
+	}
+	if business == nil {
+		return nil, fmt.Errorf("%w: %s", ErrBusinessNotActive, businessID)
+	}
+
+	s.logger.InfoContext(ctx, "Updated accepting bookings", "businessID", businessID, "acceptingBookings", accepting)
+	return business, nil
+}
+
+// ListAvailabilityRules returns all availability rules of a business, ordered Monday to Sunday and by start time within a day.
+func (s *AvailabilityService) ListAvailabilityRules(ctx context.Context, businessID string) ([]models.AvailabilityRule, error) {
+	if businessID == "" {
+		return nil, fmt.Errorf("businessID cannot be empty")
+	}
+
+	rules, err := s.availabilityRepo.GetAvailabilityRulesFiltered(ctx, businessID, "")
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list availability rules", "businessID", businessID, "error", err)
+		return nil, fmt.Errorf("could not get availability rules for %s: %w", businessID, err)
+	}
+	return rules, nil
+}
+
+// GetAvailabilityRule returns a single availability rule, including who last edited it and when.
+func (s *AvailabilityService) GetAvailabilityRule(ctx context.Context, ruleID uint) (*models.AvailabilityRule, error) {
+	rule, err := s.availabilityRepo.GetAvailabilityRuleByID(ctx, ruleID)
+	if errors.Is(err, repository.ErrAvailabilityRuleNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to get availability rule", "ruleId", ruleID, "error", err)
+		return nil, fmt.Errorf("failed to retrieve availability rule %d: %w", ruleID, err)
+	}
+	return rule, nil
+}
+
 // CreateAvailabilityRuleRequest defines the input for creating an availability rule.
 type CreateAvailabilityRuleRequest struct {
-	BusinessID    string                 `json:"businessId"`
-	DayOfWeek     models.DayOfWeekString `json:"dayOfWeek"`
-	StartTime     string                 `json:"startTime"` // "HH:MM"
-	EndTime       string                 `json:"endTime"`   // "HH:MM"
-	BufferMinutes int                    `json:"bufferMinutes"`
+	BusinessID string                 `json:"businessId"`
+	DayOfWeek  models.DayOfWeekString `json:"dayOfWeek"`
+	StartTime  string                 `json:"startTime"` // "HH:MM"
+	EndTime    string                 `json:"endTime"`   // "HH:MM"
+	// BufferBeforeMinutes and BufferAfterMinutes are the setup and cleanup time kept free around each appointment.
+	BufferBeforeMinutes int `json:"bufferBeforeMinutes"`
+	BufferAfterMinutes  int `json:"bufferAfterMinutes"`
+	// Deprecated: BufferMinutes is the old name of BufferAfterMinutes, used only when BufferAfterMinutes is unset.
+	BufferMinutes int    `json:"bufferMinutes"`
+	CreatedBy     string `json:"-"` // User ID of the actor, set by the handler
 }
 
 // CreateAvailabilityRule creates a new availability rule for a business.
 func (s *AvailabilityService) CreateAvailabilityRule(ctx context.Context, req CreateAvailabilityRuleRequest) (*models.AvailabilityRule, error) {
-	s.logger.Info("Creating availability rule", "businessID", req.BusinessID, "day", req.DayOfWeek)
+	s.logger.InfoContext(ctx, "Creating availability rule", "businessID", req.BusinessID, "day", req.DayOfWeek)
 
-	// TODO: Add validation for StartTime < EndTime, valid HH:MM format, valid DayOfWeek enum, etc.
-	// For example, using parseHHMM to validate time formats.
-	_, _, errSt := parseHHMM(req.StartTime)
-	if errSt != nil {
-		s.logger.Error("Invalid StartTime format for new rule", "startTime", req.StartTime, "error", errSt)
-		return nil, fmt.Errorf("invalid startTime format: %w", errSt)
+	startTime, endTime, err := s.normalizeRuleTimes(req.StartTime, req.EndTime)
+	if err != nil {
+		return nil, err
 	}
-	_, _, errEt := parseHHMM(req.EndTime)
-	if errEt != nil {
-		s.logger.Error("Invalid EndTime format for new rule", "endTime", req.EndTime, "error", errEt)
-		return nil, fmt.Errorf("invalid endTime format: %w", errEt)
+
+	bufferAfter := req.BufferAfterMinutes
+	if bufferAfter == 0 {
+		bufferAfter = req.BufferMinutes
 	}
-	// Basic validation: StartTime must be before EndTime
-	if req.StartTime >= req.EndTime {
-		s.logger.Warn("Rule creation failed: startTime must be before endTime", "startTime", req.StartTime, "endTime", req.EndTime)
-		return nil, fmt.Errorf("startTime (%s) must be before endTime (%s)", req.StartTime, req.EndTime)
+	if req.BufferBeforeMinutes < 0 || bufferAfter < 0 {
+		return nil, fmt.Errorf("invalid buffer: bufferBeforeMinutes and bufferAfterMinutes must not be negative")
 	}
 
 	rule := &models.AvailabilityRule{
-		BusinessID:    req.BusinessID,
-		DayOfWeek:     req.DayOfWeek,
-		StartTime:     req.StartTime,
-		EndTime:       req.EndTime,
-		BufferMinutes: req.BufferMinutes,
+		BusinessID:          req.BusinessID,
+		DayOfWeek:           req.DayOfWeek,
+		StartTime:           startTime,
+		EndTime:             endTime,
+		BufferBeforeMinutes: req.BufferBeforeMinutes,
+		BufferAfterMinutes:  bufferAfter,
+		BufferMinutes:       bufferAfter,
+		UpdatedBy:           req.CreatedBy,
 	}
 
 	if err := s.availabilityRepo.CreateAvailabilityRule(ctx, rule); err != nil {
-		s.logger.Error("Failed to create availability rule in repository", "error", err)
+		s.logger.ErrorContext(ctx, "Failed to create availability rule in repository", "error", err)
 		return nil, fmt.Errorf("could not save availability rule: %w", err)
 	}
 
-	s.logger.Info("Availability rule created successfully", "ruleId", rule.ID)
+	s.logger.InfoContext(ctx, "Availability rule created successfully", "ruleId", rule.ID)
 
 	// Publish NATS event for availability rule update
-	eventPayload := map[string]interface{}{
-		"businessId":    req.BusinessID,
-		"ruleId":        rule.ID, // Send the ID of the created rule
-		"dayOfWeek":     req.DayOfWeek,
-		"startTime":     req.StartTime,
-		"endTime":       req.EndTime,
-		"bufferMinutes": req.BufferMinutes,
-		// Add a generic message or let subscriber decide
-		"message": "Availability rule has been created/updated.",
-	}
-	if err := s.eventPublisher.Publish(events.AvailabilityRuleUpdatedEvent, eventPayload); err != nil {
-		s.logger.Error("Failed to publish AvailabilityRuleUpdatedEvent", "ruleId", rule.ID, "businessId", req.BusinessID, "error", err)
-		// Non-fatal error, rule is created, but real-time update might not happen.
-	} else {
-		s.logger.Info("Published AvailabilityRuleUpdatedEvent", "ruleId", rule.ID, "businessId", req.BusinessID)
+	if s.eventPublisher != nil {
+		eventPayload := map[string]interface{}{
+			"businessId":          req.BusinessID,
+			"ruleId":              rule.ID, // Send the ID of the created rule
+			"dayOfWeek":           req.DayOfWeek,
+			"startTime":           rule.StartTime,
+			"endTime":             rule.EndTime,
+			"bufferMinutes":       rule.BufferAfterMinutes, // Deprecated alias of bufferAfterMinutes
+			"bufferBeforeMinutes": rule.BufferBeforeMinutes,
+			"bufferAfterMinutes":  rule.BufferAfterMinutes,
+			// Add a generic message or let subscriber decide
+			"message": "Availability rule has been created/updated.",
+		}
+		if err := s.eventPublisher.Publish(events.AvailabilityRuleUpdatedEvent, eventPayload); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to publish AvailabilityRuleUpdatedEvent", "ruleId", rule.ID, "businessId", req.BusinessID, "error", err)
+			// Non-fatal error, rule is created, but real-time update might not happen.
+		} else {
+			s.logger.InfoContext(ctx, "Published AvailabilityRuleUpdatedEvent", "ruleId", rule.ID, "businessId", req.BusinessID)
+		}
+	}
+
+	return rule, nil
+}
+
+// normalizeRuleTimes checks that start and end are valid "HH:MM" times and that start is before end.
+// It returns both times zero-padded, so "9:00" is stored as "09:00" and rules sort correctly by start_time.
+func (s *AvailabilityService) normalizeRuleTimes(startTime, endTime string) (string, string, error) {
+	normalizedStart, startMinutes, err := normalizeHHMM(startTime)
+	if err != nil {
+		s.logger.Error("Invalid StartTime format for rule", "startTime", startTime, "error", err)
+		return "", "", fmt.Errorf("invalid startTime format: %w", err)
+	}
+	normalizedEnd, endMinutes, err := normalizeHHMM(endTime)
+	if err != nil {
+		s.logger.Error("Invalid EndTime format for rule", "endTime", endTime, "error", err)
+		return "", "", fmt.Errorf("invalid endTime format: %w", err)
+	}
+	// Compare minutes since midnight; "9:00" >= "10:00" as strings
+	if startMinutes >= endMinutes {
+		s.logger.Warn("Rule validation failed: startTime must be before endTime", "startTime", normalizedStart, "endTime", normalizedEnd)
+		return "", "", fmt.Errorf("startTime (%s) must be before endTime (%s)", normalizedStart, normalizedEnd)
+	}
+	return normalizedStart, normalizedEnd, nil
+}
+
+// UpdateAvailabilityRuleRequest defines a partial update of an availability rule.
+// Nil fields are left unchanged.
+type UpdateAvailabilityRuleRequest struct {
+	DayOfWeek           *models.DayOfWeekString `json:"dayOfWeek,omitempty"`
+	StartTime           *string                 `json:"startTime,omitempty"` // "HH:MM"
+	EndTime             *string                 `json:"endTime,omitempty"`   // "HH:MM"
+	BufferBeforeMinutes *int                    `json:"bufferBeforeMinutes,omitempty"`
+	BufferAfterMinutes  *int                    `json:"bufferAfterMinutes,omitempty"`
+	// Deprecated: BufferMinutes is the old name of BufferAfterMinutes, used only when BufferAfterMinutes is nil.
+	BufferMinutes *int   `json:"bufferMinutes,omitempty"`
+	UpdatedBy     string `json:"-"` // User ID of the actor, set by the handler
+}
+
+// UpdateAvailabilityRule applies the provided fields to an existing rule and re-validates the result.
+func (s *AvailabilityService) UpdateAvailabilityRule(ctx context.Context, ruleID uint, req UpdateAvailabilityRuleRequest) (*models.AvailabilityRule, error) {
+	s.logger.InfoContext(ctx, "Updating availability rule", "ruleId", ruleID)
+
+	rule, err := s.availabilityRepo.GetAvailabilityRuleByID(ctx, ruleID)
+	if errors.Is(err, repository.ErrAvailabilityRuleNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to get availability rule for update", "ruleId", ruleID, "error", err)
+		return nil, fmt.Errorf("failed to retrieve availability rule %d: %w", ruleID, err)
+	}
+
+	if req.DayOfWeek != nil {
+		if !req.DayOfWeek.IsValid() {
+			return nil, fmt.Errorf("invalid dayOfWeek: %s", *req.DayOfWeek)
+		}
+		rule.DayOfWeek = *req.DayOfWeek
+	}
+	if req.StartTime != nil {
+		rule.StartTime = *req.StartTime
+	}
+	if req.EndTime != nil {
+		rule.EndTime = *req.EndTime
+	}
+	if req.BufferBeforeMinutes != nil {
+		if *req.BufferBeforeMinutes < 0 {
+			return nil, fmt.Errorf("invalid bufferBeforeMinutes: must not be negative")
+		}
+		rule.BufferBeforeMinutes = *req.BufferBeforeMinutes
+	}
+	bufferAfter := req.BufferAfterMinutes
+	if bufferAfter == nil {
+		bufferAfter = req.BufferMinutes
+	}
+	if bufferAfter != nil {
+		if *bufferAfter < 0 {
+			return nil, fmt.Errorf("invalid bufferAfterMinutes: must not be negative")
+		}
+		// Set both so BeforeSave does not restore the old value when the buffer is cleared
+		rule.BufferAfterMinutes = *bufferAfter
+		rule.BufferMinutes = *bufferAfter
+	}
+
+	// Validate the merged rule, not just the patched fields
+	startTime, endTime, err := s.normalizeRuleTimes(rule.StartTime, rule.EndTime)
+	if err != nil {
+		return nil, err
+	}
+	rule.StartTime, rule.EndTime = startTime, endTime
+	rule.UpdatedBy = req.UpdatedBy
+
+	if err := s.availabilityRepo.UpdateAvailabilityRule(ctx, rule); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to update availability rule in repository", "ruleId", ruleID, "error", err)
+		return nil, fmt.Errorf("could not save availability rule: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, "Availability rule updated successfully", "ruleId", rule.ID)
+
+	if s.eventPublisher != nil {
+		eventPayload := map[string]interface{}{
+			"businessId":          rule.BusinessID,
+			"ruleId":              rule.ID,
+			"dayOfWeek":           rule.DayOfWeek,
+			"startTime":           rule.StartTime,
+			"endTime":             rule.EndTime,
+			"bufferMinutes":       rule.BufferAfterMinutes, // Deprecated alias of bufferAfterMinutes
+			"bufferBeforeMinutes": rule.BufferBeforeMinutes,
+			"bufferAfterMinutes":  rule.BufferAfterMinutes,
+			"message":             "Availability rule has been created/updated.",
+		}
+		if err := s.eventPublisher.Publish(events.AvailabilityRuleUpdatedEvent, eventPayload); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to publish AvailabilityRuleUpdatedEvent", "ruleId", rule.ID, "businessId", rule.BusinessID, "error", err)
+		}
+	}
+
+	return rule, nil
+}
+
+// SetAvailabilityRuleActive activates or deactivates a rule. A deactivated rule is kept, so seasonal hours
+// can be switched off and back on without re-entering them, but it produces no slots until reactivated.
+func (s *AvailabilityService) SetAvailabilityRuleActive(ctx context.Context, ruleID uint, active bool, updatedBy string) (*models.AvailabilityRule, error) {
+	s.logger.InfoContext(ctx, "Setting availability rule active state", "ruleId", ruleID, "active", active)
+
+	rule, err := s.availabilityRepo.GetAvailabilityRuleByID(ctx, ruleID)
+	if errors.Is(err, repository.ErrAvailabilityRuleNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to get availability rule for activation", "ruleId", ruleID, "error", err)
+		return nil, fmt.Errorf("failed to retrieve availability rule %d: %w", ruleID, err)
+	}
+	if rule.Active == active {
+		return rule, nil
+	}
+
+	rule.Active = active
+	rule.UpdatedBy = updatedBy
+	if err := s.availabilityRepo.UpdateAvailabilityRule(ctx, rule); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to save availability rule active state", "ruleId", ruleID, "error", err)
+		return nil, fmt.Errorf("could not save availability rule: %w", err)
+	}
+
+	if s.eventPublisher != nil {
+		eventPayload := map[string]interface{}{
+			"businessId":          rule.BusinessID,
+			"ruleId":              rule.ID,
+			"dayOfWeek":           rule.DayOfWeek,
+			"startTime":           rule.StartTime,
+			"endTime":             rule.EndTime,
+			"bufferMinutes":       rule.BufferAfterMinutes, // Deprecated alias of bufferAfterMinutes
+			"bufferBeforeMinutes": rule.BufferBeforeMinutes,
+			"bufferAfterMinutes":  rule.BufferAfterMinutes,
+			"active":              rule.Active,
+			"message":             "Availability rule has been created/updated.",
+		}
+		if err := s.eventPublisher.Publish(events.AvailabilityRuleUpdatedEvent, eventPayload); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to publish AvailabilityRuleUpdatedEvent", "ruleId", rule.ID, "businessId", rule.BusinessID, "error", err)
+		}
 	}
 
 	return rule, nil
 }
 
+// DeleteRulesForDay removes all of a business's availability rules for one day, e.g. when it stops opening on Sundays.
+// It returns how many rules were removed; a single AvailabilityRuleUpdatedEvent is published if any were.
+func (s *AvailabilityService) DeleteRulesForDay(ctx context.Context, businessID string, dayOfWeek models.DayOfWeekString) (int64, error) {
+	s.logger.InfoContext(ctx, "Deleting availability rules for day", "businessID", businessID, "day", dayOfWeek)
+
+	if businessID == "" {
+		return 0, fmt.Errorf("businessID cannot be empty")
+	}
+	if !dayOfWeek.IsValid() {
+		return 0, fmt.Errorf("invalid dayOfWeek: %s", dayOfWeek)
+	}
+
+	deleted, err := s.availabilityRepo.DeleteAvailabilityRulesForDay(ctx, businessID, dayOfWeek)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to delete availability rules in repository", "businessID", businessID, "day", dayOfWeek, "error", err)
+		return 0, fmt.Errorf("could not delete availability rules: %w", err)
+	}
+	if deleted == 0 {
+		return 0, nil
+	}
+
+	s.logger.InfoContext(ctx, "Availability rules deleted successfully", "businessID", businessID, "day", dayOfWeek, "deleted", deleted)
+
+	if s.eventPublisher != nil {
+		eventPayload := map[string]interface{}{
+			"businessId":   businessID,
+			"dayOfWeek":    dayOfWeek,
+			"deletedRules": deleted,
+			"message":      "Availability rules for the day have been removed.",
+		}
+		if err := s.eventPublisher.Publish(events.AvailabilityRuleUpdatedEvent, eventPayload); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to publish AvailabilityRuleUpdatedEvent", "businessId", businessID, "day", dayOfWeek, "error", err)
+		}
+	}
+
+	return deleted, nil
+}
+
+// ProposedAvailabilityRule is one opening window of a weekly schedule being previewed.
+type ProposedAvailabilityRule struct {
+	DayOfWeek models.DayOfWeekString `json:"dayOfWeek"`
+	StartTime string                 `json:"startTime"` // "HH:MM"
+	EndTime   string                 `json:"endTime"`   // "HH:MM"
+}
+
+// rulePreviewHorizon is how far ahead PreviewRuleChange looks for bookings.
+const rulePreviewHorizon = 365 * 24 * time.Hour
+
+// PreviewRuleChange reports which upcoming bookings would fall outside opening hours if proposedRules
+// replaced the business's current rules; days without a proposed rule count as closed. Nothing is saved.
+func (s *AvailabilityService) PreviewRuleChange(ctx context.Context, businessID string, proposedRules []ProposedAvailabilityRule) ([]models.Booking, error) {
+	if businessID == "" {
+		return nil, fmt.Errorf("businessID cannot be empty")
+	}
+
+	// Opening windows per day, in minutes since midnight
+	windows := make(map[models.DayOfWeekString][][2]int)
+	for _, rule := range proposedRules {
+		if !rule.DayOfWeek.IsValid() {
+			return nil, fmt.Errorf("invalid dayOfWeek: %s", rule.DayOfWeek)
+		}
+		_, startMinutes, err := normalizeHHMM(rule.StartTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid startTime format: %w", err)
+		}
+		_, endMinutes, err := normalizeHHMM(rule.EndTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid endTime format: %w", err)
+		}
+		if startMinutes >= endMinutes {
+			return nil, fmt.Errorf("invalid rule: startTime (%s) must be before endTime (%s)", rule.StartTime, rule.EndTime)
+		}
+		windows[rule.DayOfWeek] = append(windows[rule.DayOfWeek], [2]int{startMinutes, endMinutes})
+	}
+
+	business, err := s.availabilityRepo.GetBusinessByID(ctx, businessID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to get business", "businessID", businessID, "error", err)
+		return nil, fmt.Errorf("could not get business %s: %w", businessID, err)
+	}
+	loc := business.Location()
+
+	now := s.clock.Now()
+	relevantBookingStatuses := []models.BookingStatus{models.BookingStatusConfirmed, models.BookingStatusPendingPayment}
+	bookings, err := s.bookingRepo.GetBookingsForBusinessByDateRangeAndStatuses(ctx, businessID, now, now.Add(rulePreviewHorizon), relevantBookingStatuses)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to fetch bookings for rule preview", "businessID", businessID, "error", err)
+		return nil, fmt.Errorf("could not fetch existing bookings: %w", err)
+	}
+
+	outOfHours := []models.Booking{}
+	for _, booking := range bookings {
+		start := booking.StartTime.In(loc)
+		end := booking.EndTime.In(loc)
+		dayOfWeek := models.DayOfWeekString(strings.ToUpper(start.Weekday().String()))
+		startMinutes := start.Hour()*60 + start.Minute()
+		endMinutes := int(end.Sub(start).Minutes()) + startMinutes
+
+		inHours := false
+		for _, window := range windows[dayOfWeek] {
+			if startMinutes >= window[0] && endMinutes <= window[1] {
+				inHours = true
+				break
+			}
+		}
+		if !inHours {
+			outOfHours = append(outOfHours, booking)
+		}
+	}
+
+	s.logger.InfoContext(ctx, "Previewed availability rule change", "businessID", businessID, "bookings", len(bookings), "outOfHours", len(outOfHours))
+	return outOfHours, nil
+}
+
 // GetBusinessCalendarRequest defines the input for fetching the business calendar.
 // (This is a placeholder, actual params might be businessID, startDate, endDate directly in method signature)
 type GetBusinessCalendarRequest struct {
@@ -630,26 +2732,110 @@ type GetBusinessCalendarRequest struct {
 	EndDate    time.Time `json:"endDate"`
 }
 
+// CalendarGranularity selects the period the business calendar is rolled up by.
+type CalendarGranularity string
+
+const (
+	CalendarGranularityDay   CalendarGranularity = "day"
+	CalendarGranularityWeek  CalendarGranularity = "week" // ISO weeks, Monday to Sunday
+	CalendarGranularityMonth CalendarGranularity = "month"
+)
+
 // DailyCalendarSlotSummary provides a summary of slots for a specific day.
 type DailyCalendarSlotSummary struct {
-	Date           string `json:"date"` // "YYYY-MM-DD"
-	TotalSlots     int    `json:"totalSlots"`
-	BookedSlots    int    `json:"bookedSlots"`
-	AvailableSlots int    `json:"availableSlots"` // TotalSlots - BookedSlots (considering only whole slot bookings)
+	Date           string  `json:"date"` // "YYYY-MM-DD"
+	TotalSlots     int     `json:"totalSlots"`
+	BookedSlots    int     `json:"bookedSlots"`
+	AvailableSlots int     `json:"availableSlots"` // TotalSlots - BookedSlots (considering only whole slot bookings)
+	Utilization    float64 `json:"utilization"`    // BookedSlots / TotalSlots, 0 when there are no slots
+}
+
+// CalendarPeriodSummary rolls up the daily summaries of one ISO week or calendar month.
+type CalendarPeriodSummary struct {
+	Period         string  `json:"period"`    // "2024-W10" for weeks, "2024-03" for months
+	StartDate      string  `json:"startDate"` // First day of the period within the requested range
+	EndDate        string  `json:"endDate"`   // Last day of the period within the requested range
+	TotalSlots     int     `json:"totalSlots"`
+	BookedSlots    int     `json:"bookedSlots"`
+	AvailableSlots int     `json:"availableSlots"`
+	Utilization    float64 `json:"utilization"`
 }
 
 // BusinessCalendarResponse is the structure for the business calendar API response.
 type BusinessCalendarResponse struct {
-	BusinessID string                     `json:"businessId"`
-	StartDate  string                     `json:"startDate"` // "YYYY-MM-DD"
-	EndDate    string                     `json:"endDate"`   // "YYYY-MM-DD"
-	Days       []DailyCalendarSlotSummary `json:"days"`
+	BusinessID  string                     `json:"businessId"`
+	StartDate   string                     `json:"startDate"` // "YYYY-MM-DD"
+	EndDate     string                     `json:"endDate"`   // "YYYY-MM-DD"
+	Granularity CalendarGranularity        `json:"granularity"`
+	Days        []DailyCalendarSlotSummary `json:"days"`
+	Periods     []CalendarPeriodSummary    `json:"periods,omitempty"` // Weekly or monthly rollups of Days
+	Services    []CalendarService          `json:"services"`          // Legend of the business's active services
 	// TODO: Consider adding overall summary statistics if useful
 }
 
+// calendarUtilization returns the share of slots that are booked.
+func calendarUtilization(bookedSlots, totalSlots int) float64 {
+	if totalSlots == 0 {
+		return 0
+	}
+	return float64(bookedSlots) / float64(totalSlots)
+}
+
+// rollUpCalendarDays aggregates consecutive daily summaries into ISO weeks or calendar months.
+// Periods at the edges of the range only cover the days that were requested.
+func rollUpCalendarDays(days []DailyCalendarSlotSummary, granularity CalendarGranularity) []CalendarPeriodSummary {
+	var periods []CalendarPeriodSummary
+	for _, day := range days {
+		date, err := time.Parse("2006-01-02", day.Date)
+		if err != nil {
+			continue
+		}
+		period := date.Format("2006-01")
+		if granularity == CalendarGranularityWeek {
+			year, week := date.ISOWeek()
+			period = fmt.Sprintf("%d-W%02d", year, week)
+		}
+
+		if len(periods) == 0 || periods[len(periods)-1].Period != period {
+			periods = append(periods, CalendarPeriodSummary{Period: period, StartDate: day.Date})
+		}
+		summary := &periods[len(periods)-1]
+		summary.EndDate = day.Date
+		summary.TotalSlots += day.TotalSlots
+		summary.BookedSlots += day.BookedSlots
+		summary.AvailableSlots += day.AvailableSlots
+	}
+	for i := range periods {
+		periods[i].Utilization = calendarUtilization(periods[i].BookedSlots, periods[i].TotalSlots)
+	}
+	return periods
+}
+
+// CalendarService is a calendar legend entry, so UIs can color bookings by service.
+type CalendarService struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Color      string `json:"color,omitempty"`
+	ShortLabel string `json:"shortLabel,omitempty"`
+}
+
+// calendarServices lists the business's active services with their display metadata.
+func (s *AvailabilityService) calendarServices(ctx context.Context, businessID string) ([]CalendarService, error) {
+	serviceDefs, err := s.availabilityRepo.GetActiveServiceDefinitionsForBusiness(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+	services := make([]CalendarService, 0, len(serviceDefs))
+	for _, serviceDef := range serviceDefs {
+		services = append(services, CalendarService{ID: serviceDef.ID, Name: serviceDef.Name, Color: serviceDef.Color, ShortLabel: serviceDef.ShortLabel})
+	}
+	return services, nil
+}
+
 // GetBusinessCalendar generates a daily summary of slot availability for a business.
-func (s *AvailabilityService) GetBusinessCalendar(ctx context.Context, businessID string, startDate time.Time, endDate time.Time) (*BusinessCalendarResponse, error) {
-	s.logger.Info("Getting business calendar", "businessID", businessID, "startDate", startDate.Format("2006-01-02"), "endDate", endDate.Format("2006-01-02"))
+// With week or month granularity the days are also rolled up into Periods.
+func (s *AvailabilityService) GetBusinessCalendar(ctx context.Context, businessID string, startDate time.Time, endDate time.Time, granularity CalendarGranularity) (*BusinessCalendarResponse, error) {
+	s.logger.InfoContext(ctx, "Getting business calendar", "businessID", businessID, "startDate", startDate.Format("2006-01-02"), "endDate", endDate.Format("2006-01-02"), "granularity", granularity)
 
 	if businessID == "" {
 		return nil, fmt.Errorf("businessID cannot be empty")
@@ -657,22 +2843,36 @@ func (s *AvailabilityService) GetBusinessCalendar(ctx context.Context, businessI
 	if startDate.After(endDate) {
 		return nil, fmt.Errorf("startDate cannot be after endDate")
 	}
+	if granularity == "" {
+		granularity = CalendarGranularityDay
+	}
+	if granularity != CalendarGranularityDay && granularity != CalendarGranularityWeek && granularity != CalendarGranularityMonth {
+		return nil, fmt.Errorf("invalid granularity %q: must be day, week or month", granularity)
+	}
+
+	services, err := s.calendarServices(ctx, businessID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to get services for calendar", "businessID", businessID, "error", err)
+		return nil, fmt.Errorf("could not get services for %s: %w", businessID, err)
+	}
 
-	// 1. Fetch all availability rules for the business.
+	// 1. Fetch all active availability rules for the business; deactivated ones produce no slots.
 	// No day filter here, as we need rules for all days to iterate through the date range.
-	allRules, err := s.availabilityRepo.GetAvailabilityRulesFiltered(ctx, businessID, "") // Empty dayOfWeek means get all for business
+	allRules, err := s.availabilityRepo.GetActiveAvailabilityRules(ctx, businessID, "") // Empty dayOfWeek means get all for business
 	if err != nil {
-		s.logger.Error("Failed to get all availability rules for business", "businessID", businessID, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to get all availability rules for business", "businessID", businessID, "error", err)
 		return nil, fmt.Errorf("could not get availability rules for %s: %w", businessID, err)
 	}
 	if len(allRules) == 0 {
-		s.logger.Info("No availability rules found for business, calendar will be empty", "businessID", businessID)
+		s.logger.InfoContext(ctx, "No availability rules found for business, calendar will be empty", "businessID", businessID)
 		// Return an empty calendar response for the date range
 		resp := &BusinessCalendarResponse{
-			BusinessID: businessID,
-			StartDate:  startDate.Format("2006-01-02"),
-			EndDate:    endDate.Format("2006-01-02"),
-			Days:       []DailyCalendarSlotSummary{},
+			BusinessID:  businessID,
+			StartDate:   startDate.Format("2006-01-02"),
+			EndDate:     endDate.Format("2006-01-02"),
+			Granularity: granularity,
+			Days:        []DailyCalendarSlotSummary{},
+			Services:    services,
 		}
 		// Populate 'Days' with entries for each day in the range, showing 0 slots
 		currentDate := startDate
@@ -685,6 +2885,9 @@ func (s *AvailabilityService) GetBusinessCalendar(ctx context.Context, businessI
 			})
 			currentDate = currentDate.AddDate(0, 0, 1)
 		}
+		if granularity != CalendarGranularityDay {
+			resp.Periods = rollUpCalendarDays(resp.Days, granularity)
+		}
 		return resp, nil
 	}
 
@@ -700,7 +2903,7 @@ func (s *AvailabilityService) GetBusinessCalendar(ctx context.Context, businessI
 	queryEndDate := endDate.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
 	allBookings, err := s.bookingRepo.GetBookingsForBusinessByDateRangeAndStatuses(ctx, businessID, startDate, queryEndDate, bookingStatuses)
 	if err != nil {
-		s.logger.Error("Failed to fetch bookings for calendar", "businessID", businessID, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to fetch bookings for calendar", "businessID", businessID, "error", err)
 		return nil, fmt.Errorf("could not fetch bookings for calendar: %w", err)
 	}
 
@@ -774,9 +2977,9 @@ func (s *AvailabilityService) GetBusinessCalendar(ctx context.Context, businessI
 				etH, etM, _ := parseHHMM(rule.EndTime)
 				periodStart := time.Date(currentDate.Year(), currentDate.Month(), currentDate.Day(), stH, stM, 0, 0, loc)
 				periodEnd := time.Date(currentDate.Year(), currentDate.Month(), currentDate.Day(), etH, etM, 0, 0, loc)
-				bufferDuration := time.Duration(rule.BufferMinutes) * time.Minute
+				bufferBefore, bufferAfter := rule.Buffers()
 
-				slotStart := periodStart
+				slotStart := periodStart.Add(bufferBefore)
 				for {
 					slotEnd := slotStart.Add(standardServiceDuration)
 					if slotEnd.After(periodEnd) {
@@ -801,7 +3004,7 @@ func (s *AvailabilityService) GetBusinessCalendar(ctx context.Context, businessI
 					if isBooked {
 						dailyBookedSlots++
 					}
-					slotStart = slotEnd.Add(bufferDuration)
+					slotStart = slotEnd.Add(bufferAfter + bufferBefore)
 				}
 			}
 		}
@@ -811,18 +3014,24 @@ func (s *AvailabilityService) GetBusinessCalendar(ctx context.Context, businessI
 			TotalSlots:     dailyTotalSlots,
 			BookedSlots:    dailyBookedSlots,
 			AvailableSlots: dailyTotalSlots - dailyBookedSlots,
+			Utilization:    calendarUtilization(dailyBookedSlots, dailyTotalSlots),
 		})
 		currentDate = currentDate.AddDate(0, 0, 1) // Move to next day
 	}
 
 	response := &BusinessCalendarResponse{
-		BusinessID: businessID,
-		StartDate:  startDate.Format("2006-01-02"),
-		EndDate:    endDate.Format("2006-01-02"),
-		Days:       dailySummaries,
+		BusinessID:  businessID,
+		StartDate:   startDate.Format("2006-01-02"),
+		EndDate:     endDate.Format("2006-01-02"),
+		Granularity: granularity,
+		Days:        dailySummaries,
+		Services:    services,
+	}
+	if granularity != CalendarGranularityDay {
+		response.Periods = rollUpCalendarDays(dailySummaries, granularity)
 	}
 
-	s.logger.Info("Business calendar generated", "businessID", businessID, "daysCount", len(dailySummaries))
+	s.logger.InfoContext(ctx, "Business calendar generated", "businessID", businessID, "daysCount", len(dailySummaries))
 	return response, nil
 }
 
@@ -832,7 +3041,7 @@ func (s *AvailabilityService) GetBusinessCalendar(ctx context.Context, businessI
 // The new "business.service.created" event is handled by NatsEventHandlers.HandleBusinessServiceCreated.
 // This might need to be updated or removed if its functionality is covered by HandleBusinessServiceCreated's upsert.
 // For now, keeping it as a distinct handler for a potentially different "service.updated" event.
-// s.logger.Info("Handling service.updated event (distinct from business.service.created)")
+// s.logger.InfoContext(ctx, "Handling service.updated event (distinct from business.service.created)")
 // Example: payload might only contain changes, not full service definition
 // Or it might be an event internal to scheduling service.
 // If it's from Business Service and implies an update to ServiceDefinition, its logic would be similar
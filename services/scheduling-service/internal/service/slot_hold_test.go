@@ -0,0 +1,414 @@
+package service_test
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/slotwise/scheduling-service/internal/models"
+	"github.com/slotwise/scheduling-service/internal/repository"
+	"github.com/slotwise/scheduling-service/internal/service"
+	"github.com/slotwise/scheduling-service/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+const testSlotHoldTTL = 500 * time.Millisecond
+
+type SlotHoldTestSuite struct {
+	suite.Suite
+	DB                  *gorm.DB
+	Redis               *redis.Client
+	AvailabilityService *service.AvailabilityService
+	BookingService      *service.BookingService
+	TestLogger          *logger.Logger
+}
+
+func (suite *SlotHoldTestSuite) SetupSuite() {
+	suite.TestLogger = logger.New("debug")
+	dsn := "host=localhost user=postgres password=postgres dbname=slotwise_scheduling_test port=5432 sslmode=disable"
+	if envURL := os.Getenv("TEST_DATABASE_URL"); envURL != "" {
+		dsn = envURL
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		suite.T().Fatalf("Failed to connect to PostgreSQL: %v", err)
+	}
+	suite.DB = db
+	err = suite.DB.AutoMigrate(&models.Business{}, &models.ServiceDefinition{}, &models.AvailabilityRule{}, &models.AvailabilityVersion{}, &models.Booking{}, &models.OutboxEvent{}, &models.BookingStatusHistory{}, &models.CustomerPreference{})
+	assert.NoError(suite.T(), err)
+
+	// Use a separate Redis database so tests never touch development data
+	redisURL := "redis://localhost:6379/15"
+	if envURL := os.Getenv("TEST_REDIS_URL"); envURL != "" {
+		redisURL = envURL
+	}
+	opt, err := redis.ParseURL(redisURL)
+	if err != nil {
+		suite.T().Fatalf("Invalid Redis URL: %v", err)
+	}
+	suite.Redis = redis.NewClient(opt)
+	if err := suite.Redis.Ping(context.Background()).Err(); err != nil {
+		suite.T().Fatalf("Failed to connect to Redis: %v", err)
+	}
+
+	availabilityRepo := repository.NewAvailabilityRepository(suite.DB)
+	bookingRepo := repository.NewBookingRepository(suite.DB)
+	publisher := NewMockEventPublisher()
+	suite.AvailabilityService = service.NewAvailabilityService(availabilityRepo, bookingRepo, nil, repository.NewSlotHoldRepository(suite.Redis), testSlotHoldTTL, 0, publisher, nil, suite.TestLogger)
+	suite.BookingService = service.NewBookingService(
+		bookingRepo,
+		suite.AvailabilityService,
+		availabilityRepo,
+		repository.NewCustomerPreferenceRepository(suite.DB),
+		service.NewOutboxRelay(repository.NewOutboxRepository(suite.DB), publisher, suite.TestLogger),
+		publisher,
+		&MockNotificationClient{},
+		nil,
+		suite.TestLogger,
+	)
+}
+
+func (suite *SlotHoldTestSuite) TearDownSuite() {
+	suite.Redis.Close()
+	sqlDB, _ := suite.DB.DB()
+	sqlDB.Close()
+}
+
+func (suite *SlotHoldTestSuite) SetupTest() {
+	suite.Redis.FlushDB(context.Background())
+	suite.DB.Exec("DELETE FROM service_definitions")
+	suite.DB.Exec("DELETE FROM availability_rules")
+	suite.DB.Exec("DELETE FROM availability_versions")
+	suite.DB.Exec("DELETE FROM bookings")
+	suite.DB.Exec("DELETE FROM outbox_events")
+
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_hold", BusinessID: "biz_hold", Name: "Hold Service", DurationMinutes: 30, IsActive: true})
+	suite.DB.Create(&models.AvailabilityRule{BusinessID: "biz_hold", DayOfWeek: models.Monday, StartTime: "09:00", EndTime: "10:00"})
+}
+
+// 2030-03-04 is a Monday
+func holdTestTime(hhmm string) time.Time {
+	t, _ := time.Parse(time.RFC3339, "2030-03-04T"+hhmm+":00Z")
+	return t
+}
+
+func slotStarts(slots []service.APISlot) []time.Time {
+	starts := make([]time.Time, 0, len(slots))
+	for _, slot := range slots {
+		starts = append(starts, slot.StartTime)
+	}
+	return starts
+}
+
+func (suite *SlotHoldTestSuite) TestHeldSlotDisappearsFromAvailability() {
+	t := suite.T()
+	ctx := context.Background()
+
+	hold, err := suite.AvailabilityService.HoldSlot(ctx, "biz_hold", "svc_hold", "user_holder", holdTestTime("09:00"))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, hold.ID)
+	assert.Equal(t, holdTestTime("09:30"), hold.EndTime.UTC())
+
+	slots, err := suite.AvailabilityService.GetAvailableSlots(ctx, "biz_hold", "svc_hold", holdTestTime("00:00"))
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{holdTestTime("09:30")}, slotStarts(slots))
+
+	// A second customer cannot hold or book the same slot
+	_, err = suite.AvailabilityService.HoldSlot(ctx, "biz_hold", "svc_hold", "user_other", holdTestTime("09:00"))
+	assert.Error(t, err)
+	_, err = suite.BookingService.CreateBooking(ctx, service.CreateBookingRequest{
+		BusinessID: "biz_hold", ServiceID: "svc_hold", CustomerID: "cust_other", StartTime: holdTestTime("09:00"),
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "not available due to a conflict")
+	}
+}
+
+func (suite *SlotHoldTestSuite) TestHeldSlotFreesOnExpiry() {
+	t := suite.T()
+	ctx := context.Background()
+
+	_, err := suite.AvailabilityService.HoldSlot(ctx, "biz_hold", "svc_hold", "user_holder", holdTestTime("09:00"))
+	assert.NoError(t, err)
+
+	time.Sleep(testSlotHoldTTL + 300*time.Millisecond)
+
+	slots, err := suite.AvailabilityService.GetAvailableSlots(ctx, "biz_hold", "svc_hold", holdTestTime("00:00"))
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{holdTestTime("09:00"), holdTestTime("09:30")}, slotStarts(slots))
+}
+
+func (suite *SlotHoldTestSuite) TestHolderCanBookHeldSlot() {
+	t := suite.T()
+	ctx := context.Background()
+
+	hold, err := suite.AvailabilityService.HoldSlot(ctx, "biz_hold", "svc_hold", "user_holder", holdTestTime("09:00"))
+	assert.NoError(t, err)
+
+	booking, err := suite.BookingService.CreateBooking(ctx, service.CreateBookingRequest{
+		BusinessID: "biz_hold", ServiceID: "svc_hold", CustomerID: "cust_holder", StartTime: holdTestTime("09:00"), HoldID: hold.ID, RequestedBy: "user_holder",
+	})
+	assert.NoError(t, err)
+	assert.NotNil(t, booking)
+
+	// The hold is released once the booking exists
+	keys, err := suite.Redis.Keys(ctx, "slot_hold:*").Result()
+	assert.NoError(t, err)
+	assert.Empty(t, keys)
+}
+
+func (suite *SlotHoldTestSuite) TestOtherUserCannotBookWithHoldID() {
+	t := suite.T()
+	ctx := context.Background()
+
+	hold, err := suite.AvailabilityService.HoldSlot(ctx, "biz_hold", "svc_hold", "user_holder", holdTestTime("09:00"))
+	assert.NoError(t, err)
+	assert.Equal(t, "user_holder", hold.UserID)
+
+	_, err = suite.BookingService.CreateBooking(ctx, service.CreateBookingRequest{
+		BusinessID: "biz_hold", ServiceID: "svc_hold", CustomerID: "cust_other", StartTime: holdTestTime("09:00"), HoldID: hold.ID, RequestedBy: "user_other",
+	})
+	var conflict *service.BookingConflictError
+	if assert.ErrorAs(t, err, &conflict) {
+		assert.True(t, conflict.Held)
+	}
+
+	// The hold is still in place for its owner
+	holds, err := repository.NewSlotHoldRepository(suite.Redis).GetByBusinessID(ctx, "biz_hold")
+	assert.NoError(t, err)
+	assert.Len(t, holds, 1)
+}
+
+func (suite *SlotHoldTestSuite) TestHoldSlotCapsHoldsPerUser() {
+	t := suite.T()
+	ctx := context.Background()
+	suite.DB.Model(&models.AvailabilityRule{}).Where("business_id = ?", "biz_hold").Update("end_time", "11:00")
+
+	for _, start := range []string{"09:00", "09:30", "10:00"} {
+		_, err := suite.AvailabilityService.HoldSlot(ctx, "biz_hold", "svc_hold", "user_holder", holdTestTime(start))
+		assert.NoError(t, err)
+	}
+	_, err := suite.AvailabilityService.HoldSlot(ctx, "biz_hold", "svc_hold", "user_holder", holdTestTime("10:30"))
+	assert.ErrorIs(t, err, service.ErrTooManyHolds)
+
+	// Other users are not affected
+	_, err = suite.AvailabilityService.HoldSlot(ctx, "biz_hold", "svc_hold", "user_other", holdTestTime("10:30"))
+	assert.NoError(t, err)
+}
+
+func (suite *SlotHoldTestSuite) TestHoldsAreIndexedPerBusiness() {
+	t := suite.T()
+	ctx := context.Background()
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_hold_other", BusinessID: "biz_hold_other", Name: "Other Hold Service", DurationMinutes: 30, IsActive: true})
+	suite.DB.Create(&models.AvailabilityRule{BusinessID: "biz_hold_other", DayOfWeek: models.Monday, StartTime: "09:00", EndTime: "10:00"})
+
+	hold, err := suite.AvailabilityService.HoldSlot(ctx, "biz_hold", "svc_hold", "user_holder", holdTestTime("09:00"))
+	assert.NoError(t, err)
+	_, err = suite.AvailabilityService.HoldSlot(ctx, "biz_hold_other", "svc_hold_other", "user_holder", holdTestTime("09:30"))
+	assert.NoError(t, err)
+
+	members, err := suite.Redis.ZRange(ctx, "slot_holds:biz_hold", 0, -1).Result()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"slot_hold:biz_hold:" + strconv.FormatInt(holdTestTime("09:00").Unix(), 10)}, members)
+
+	// Each business only sees its own hold
+	holdRepo := repository.NewSlotHoldRepository(suite.Redis)
+	holds, err := holdRepo.GetByBusinessID(ctx, "biz_hold")
+	assert.NoError(t, err)
+	if assert.Len(t, holds, 1) {
+		assert.Equal(t, hold.ID, holds[0].ID)
+	}
+
+	// Releasing the hold takes it out of the index too
+	assert.NoError(t, holdRepo.Release(ctx, hold))
+	members, err = suite.Redis.ZRange(ctx, "slot_holds:biz_hold", 0, -1).Result()
+	assert.NoError(t, err)
+	assert.Empty(t, members)
+	holds, err = holdRepo.GetByBusinessID(ctx, "biz_hold_other")
+	assert.NoError(t, err)
+	assert.Len(t, holds, 1)
+}
+
+func (suite *SlotHoldTestSuite) TestExpiredHoldsLeaveTheIndex() {
+	t := suite.T()
+	ctx := context.Background()
+
+	_, err := suite.AvailabilityService.HoldSlot(ctx, "biz_hold", "svc_hold", "user_holder", holdTestTime("09:00"))
+	assert.NoError(t, err)
+
+	time.Sleep(testSlotHoldTTL + 300*time.Millisecond)
+
+	holds, err := repository.NewSlotHoldRepository(suite.Redis).GetByBusinessID(ctx, "biz_hold")
+	assert.NoError(t, err)
+	assert.Empty(t, holds)
+	exists, err := suite.Redis.Exists(ctx, "slot_holds:biz_hold").Result()
+	assert.NoError(t, err)
+	assert.Zero(t, exists)
+}
+
+func (suite *SlotHoldTestSuite) TestHoldSlotUsesTheBusinessLocalDay() {
+	t := suite.T()
+	ctx := context.Background()
+	// Monday 09:00 in Auckland (UTC+13 in March) is still Sunday in UTC
+	suite.DB.Save(&models.Business{ID: "biz_hold_nz", Name: "Auckland Shop", Status: "ACTIVE", AcceptingBookings: true, AllowBackToBack: true, Timezone: "Pacific/Auckland"})
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_hold_nz", BusinessID: "biz_hold_nz", Name: "Auckland Service", DurationMinutes: 30, IsActive: true})
+	suite.DB.Create(&models.AvailabilityRule{BusinessID: "biz_hold_nz", DayOfWeek: models.Monday, StartTime: "09:00", EndTime: "10:00"})
+	auckland, err := time.LoadLocation("Pacific/Auckland")
+	if err != nil {
+		t.Skip("timezone data unavailable")
+	}
+	start := time.Date(2030, 3, 4, 9, 0, 0, 0, auckland).UTC()
+	assert.Equal(t, time.Sunday, start.Weekday())
+
+	hold, err := suite.AvailabilityService.HoldSlot(ctx, "biz_hold_nz", "svc_hold_nz", "user_holder", start)
+	if assert.NoError(t, err) {
+		assert.True(t, start.Equal(hold.StartTime))
+		assert.True(t, start.Add(30*time.Minute).Equal(hold.EndTime))
+	}
+
+	// A start given with another offset still falls on the same business-local day
+	_, err = suite.AvailabilityService.HoldSlot(ctx, "biz_hold_nz", "svc_hold_nz", "user_holder", start.Add(30*time.Minute).In(time.FixedZone("UTC-5", -5*60*60)))
+	assert.NoError(t, err)
+}
+
+func (suite *SlotHoldTestSuite) TestCheckAndReserve_OneWinnerForLastSlot() {
+	t := suite.T()
+	ctx := context.Background()
+	// 09:00 is booked, leaving 09:30 as the last open slot
+	suite.DB.Create(&models.Booking{
+		BusinessID: "biz_hold", ServiceID: "svc_hold", CustomerID: "cust_booked",
+		StartTime: holdTestTime("09:00"), EndTime: holdTestTime("09:30"), Status: models.BookingStatusConfirmed,
+	})
+
+	const callers = 10
+	var wg sync.WaitGroup
+	var won atomic.Int32
+	errs := make(chan error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := suite.AvailabilityService.CheckAndReserve(ctx, "biz_hold", "svc_hold", "user_holder", holdTestTime("09:30")); err != nil {
+				errs <- err
+				return
+			}
+			won.Add(1)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	assert.Equal(t, int32(1), won.Load(), "Exactly one caller gets the last slot")
+	for err := range errs {
+		assert.Contains(t, err.Error(), "not available")
+	}
+	slots, err := suite.AvailabilityService.GetAvailableSlots(ctx, "biz_hold", "svc_hold", holdTestTime("00:00"))
+	assert.NoError(t, err)
+	assert.Empty(t, slots)
+}
+
+func (suite *SlotHoldTestSuite) TestCheckAndReserve_OverlappingSlotsOneWinner() {
+	t := suite.T()
+	ctx := context.Background()
+	suite.DB.Create(&models.ServiceDefinition{ID: "svc_hold_long", BusinessID: "biz_hold", Name: "Long Hold Service", DurationMinutes: 60, IsActive: true})
+	suite.DB.Model(&models.AvailabilityRule{}).Where("business_id = ?", "biz_hold").Update("end_time", "10:30")
+
+	// A 60-minute hold at 09:00 and a 30-minute hold at 09:30 cannot both fit, though their keys differ
+	var wg sync.WaitGroup
+	var won atomic.Int32
+	for _, req := range []struct {
+		serviceID string
+		start     string
+	}{{"svc_hold_long", "09:00"}, {"svc_hold", "09:30"}, {"svc_hold_long", "09:00"}, {"svc_hold", "09:30"}} {
+		wg.Add(1)
+		go func(serviceID, start string) {
+			defer wg.Done()
+			if _, err := suite.AvailabilityService.CheckAndReserve(ctx, "biz_hold", serviceID, "user_holder", holdTestTime(start)); err == nil {
+				won.Add(1)
+			}
+		}(req.serviceID, req.start)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), won.Load())
+}
+
+func (suite *SlotHoldTestSuite) TestCreateBookingAndCheckAndReserve_OneWinnerForSameSlot() {
+	t := suite.T()
+	ctx := context.Background()
+	// 09:30 is booked, so 09:00 is the only slot left and bookings and holds race for it
+	suite.DB.Create(&models.Booking{
+		BusinessID: "biz_hold", ServiceID: "svc_hold", CustomerID: "cust_booked",
+		StartTime: holdTestTime("09:30"), EndTime: holdTestTime("10:00"), Status: models.BookingStatusConfirmed,
+	})
+
+	var wg sync.WaitGroup
+	var won atomic.Int32
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var err error
+			if i%2 == 0 {
+				_, err = suite.BookingService.CreateBooking(ctx, service.CreateBookingRequest{
+					BusinessID: "biz_hold", ServiceID: "svc_hold", CustomerID: "cust_racer", StartTime: holdTestTime("09:00"),
+				})
+			} else {
+				_, err = suite.AvailabilityService.CheckAndReserve(ctx, "biz_hold", "svc_hold", "user_holder", holdTestTime("09:00"))
+			}
+			if err == nil {
+				won.Add(1)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), won.Load(), "Exactly one booking or hold gets the slot")
+	var booked int64
+	suite.DB.Model(&models.Booking{}).Where("business_id = ? AND start_time = ?", "biz_hold", holdTestTime("09:00")).Count(&booked)
+	keys, err := suite.Redis.Keys(ctx, "slot_hold:*").Result()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, int(booked)+len(keys), "The slot is taken once, by a booking or a hold")
+}
+
+func (suite *SlotHoldTestSuite) TestCreateBooking_ConcurrentBookingsOneWinner() {
+	t := suite.T()
+	ctx := context.Background()
+
+	const callers = 5
+	var wg sync.WaitGroup
+	var won atomic.Int32
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := suite.BookingService.CreateBooking(ctx, service.CreateBookingRequest{
+				BusinessID: "biz_hold", ServiceID: "svc_hold", CustomerID: "cust_racer", StartTime: holdTestTime("09:00"),
+			})
+			if err == nil {
+				won.Add(1)
+				return
+			}
+			var conflict *service.BookingConflictError
+			assert.ErrorAs(t, err, &conflict)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), won.Load())
+	var booked int64
+	suite.DB.Model(&models.Booking{}).Where("business_id = ? AND start_time = ?", "biz_hold", holdTestTime("09:00")).Count(&booked)
+	assert.Equal(t, int64(1), booked)
+}
+
+func TestSlotHoldTestSuite(t *testing.T) {
+	suite.Run(t, new(SlotHoldTestSuite))
+}
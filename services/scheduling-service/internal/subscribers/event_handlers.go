@@ -2,14 +2,25 @@ package subscribers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/slotwise/scheduling-service/internal/models"
+	"github.com/slotwise/scheduling-service/internal/repository"
 	"github.com/slotwise/scheduling-service/pkg/logger"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
+// ErrCurrencyMismatch is returned when a service is priced in a different currency than its business.
+var ErrCurrencyMismatch = errors.New("service currency does not match business currency")
+
+// ErrInvalidServiceDuration is returned when a service's duration is not a positive number of minutes.
+var ErrInvalidServiceDuration = errors.New("service duration must be positive")
+
 // NatsEventHandlers holds dependencies for handling NATS events.
 type NatsEventHandlers struct {
 	DB     *gorm.DB
@@ -28,12 +39,19 @@ type BusinessServiceCreatedPayload struct {
 	BusinessID     string `json:"businessId"`
 	ServiceID      string `json:"serviceId"`
 	ServiceDetails struct {
-		Name            string  `json:"name"`
-		Description     *string `json:"description"` // Pointer to handle optional field
-		DurationMinutes int     `json:"durationMinutes"`
-		Price           float64 `json:"price"` // Assuming price from NATS might be float
-		Currency        string  `json:"currency"`
-		IsActive        *bool   `json:"isActive"` // Pointer to handle optional field
+		Name            string                 `json:"name"`
+		Description     *string                `json:"description"` // Pointer to handle optional field
+		DurationMinutes int                    `json:"durationMinutes"`
+		Price           float64                `json:"price"` // Assuming price from NATS might be float
+		Currency        string                 `json:"currency"`
+		Category        string                 `json:"category"`       // Optional, used by marketplace search
+		IsActive        *bool                  `json:"isActive"`       // Pointer to handle optional field
+		MetadataSchema  *models.MetadataSchema `json:"metadataSchema"` // Optional custom booking fields
+		RequiresPayment *bool                  `json:"requiresPayment"`
+		Color           string                 `json:"color"`      // Optional hex color for calendars, e.g. "#4F46E5"
+		ShortLabel      string                 `json:"shortLabel"` // Optional abbreviation for calendars
+		CancellationFee *float64               `json:"cancellationFee"` // Optional override of the business's fee, like price in major units
+		Capacity        *int                   `json:"capacity"`        // Optional places per slot for group services; defaults to 1
 		// Add other fields if they become part of the event
 	} `json:"serviceDetails"`
 }
@@ -45,12 +63,72 @@ type AvailabilityRulePayload struct {
 	EndTime   string `json:"endTime"`   // "HH:MM"
 }
 
+// legacyAvailabilityVersionFloor is where versions that are publish times in Unix milliseconds start.
+// The business service used to send those instead of its per-business counter; a stored one is replaced
+// by the next event whatever its version, so the counter takes over.
+const legacyAvailabilityVersionFloor int64 = 1_000_000_000_000
+
 // BusinessAvailabilityUpdatedPayload matches the 'business.availability.updated' event.
 type BusinessAvailabilityUpdatedPayload struct {
-	BusinessID string                  `json:"businessId"`
+	BusinessID string                    `json:"businessId"`
+	Version    int64                     `json:"version"` // The business's counter, increased with every update; 0 for publishers that do not send one
 	Rules      []AvailabilityRulePayload `json:"rules"`
 }
 
+// UserCreatedPayload matches the fields of the 'user.created' event used for notifications.
+type UserCreatedPayload struct {
+	UserID             string `json:"userId"`
+	Email              string `json:"email"`
+	FirstName          string `json:"firstName"`
+	LastName           string `json:"lastName"`
+	Language           string `json:"language"`
+	EmailNotifications *bool  `json:"emailNotifications"` // Pointer to handle events from older publishers
+	SMSNotifications   *bool  `json:"smsNotifications"`
+}
+
+// UserUpdatedPayload matches the 'user.updated' event. Changes holds only the fields that changed;
+// the auth service sends both names when either changes.
+type UserUpdatedPayload struct {
+	UserID  string `json:"userId"`
+	Changes struct {
+		FirstName          *string `json:"firstName"`
+		LastName           *string `json:"lastName"`
+		Language           *string `json:"language"`
+		EmailNotifications *bool   `json:"emailNotifications"`
+		SMSNotifications   *bool   `json:"smsNotifications"`
+	} `json:"changes"`
+}
+
+// BusinessEventEnvelope matches the envelope the Business Service wraps its
+// 'slotwise.business.*' lifecycle events in.
+type BusinessEventEnvelope struct {
+	ID   string            `json:"id"`
+	Type string            `json:"type"` // e.g., "business.created"
+	Data BusinessEventData `json:"data"`
+}
+
+// BusinessEventData holds the fields of the business lifecycle events used here.
+type BusinessEventData struct {
+	BusinessID string `json:"businessId"`
+	Name       string `json:"name"`     // Set on business.created
+	Status     string `json:"status"`   // Set on business.created
+	Currency   string `json:"currency"` // Set on business.created
+	Timezone   string `json:"timezone"` // Set on business.created
+	Changes    struct {
+		Name                         *string  `json:"name"`
+		Status                       *string  `json:"status"`
+		Currency                     *string  `json:"currency"`
+		CancellationCutoffHours      *int     `json:"cancellationCutoffHours"`
+		CancellationFeeWindowHours   *int     `json:"cancellationFeeWindowHours"`
+		CancellationFee              *float64 `json:"cancellationFee"` // In major units, like service prices
+		Timezone                     *string  `json:"timezone"`
+		AllowBackToBack              *bool    `json:"allowBackToBack"`
+		MinimumNoticeMinutes         *int     `json:"minimumNoticeMinutes"`
+		MaxBookingsPerDay            *int     `json:"maxBookingsPerDay"`
+		MaxBookingsPerCustomerPerDay *int     `json:"maxBookingsPerCustomerPerDay"`
+	} `json:"changes"` // Set on business.updated
+}
+
 // --- Event Handler Functions ---
 
 // HandleBusinessServiceCreated processes the 'business.service.created' event.
@@ -63,13 +141,19 @@ func (h *NatsEventHandlers) HandleBusinessServiceCreated(data []byte) error {
 
 	h.Logger.Info("Processing business.service.created event", "serviceId", payload.ServiceID, "businessId", payload.BusinessID)
 
+	// A service without a positive duration can't be laid out as slots
+	if payload.ServiceDetails.DurationMinutes <= 0 {
+		h.Logger.Error("Rejected service with a non-positive duration", "serviceId", payload.ServiceID, "businessId", payload.BusinessID, "durationMinutes", payload.ServiceDetails.DurationMinutes)
+		return fmt.Errorf("%w: got %d minutes", ErrInvalidServiceDuration, payload.ServiceDetails.DurationMinutes)
+	}
+
 	serviceDef := models.ServiceDefinition{
 		ID:              payload.ServiceID,
 		BusinessID:      payload.BusinessID,
 		Name:            payload.ServiceDetails.Name,
 		DurationMinutes: payload.ServiceDetails.DurationMinutes,
-		Price:           int64(payload.ServiceDetails.Price * 100), // Convert to cents
 		Currency:        payload.ServiceDetails.Currency,
+		Category:        strings.TrimSpace(payload.ServiceDetails.Category),
 	}
 	if payload.ServiceDetails.Description != nil {
 		serviceDef.Description = *payload.ServiceDetails.Description
@@ -79,14 +163,40 @@ func (h *NatsEventHandlers) HandleBusinessServiceCreated(data []byte) error {
 	} else {
 		serviceDef.IsActive = true // Default to active if not provided
 	}
+	serviceDef.MetadataSchema = payload.ServiceDetails.MetadataSchema
+	serviceDef.RequiresPayment = payload.ServiceDetails.RequiresPayment
+	serviceDef.Capacity = 1
+	if payload.ServiceDetails.Capacity != nil && *payload.ServiceDetails.Capacity > 1 {
+		serviceDef.Capacity = *payload.ServiceDetails.Capacity
+	}
+	serviceDef.Color, serviceDef.ShortLabel = serviceDisplay(payload.ServiceDetails.Color, payload.ServiceDetails.ShortLabel)
+	if payload.ServiceDetails.Color != "" && serviceDef.Color == "" {
+		h.Logger.Warn("Ignoring invalid service color", "serviceId", payload.ServiceID, "color", payload.ServiceDetails.Color)
+	}
 
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		currency, err := reconcileBusinessCurrency(tx, payload.BusinessID, serviceDef.Currency)
+		if err != nil {
+			return err
+		}
+		serviceDef.Currency = currency
+		// Amounts are stored in minor units, which depend on the currency
+		serviceDef.Price = models.ToMinorUnits(payload.ServiceDetails.Price, currency)
+		if payload.ServiceDetails.CancellationFee != nil {
+			fee := models.ToMinorUnits(*payload.ServiceDetails.CancellationFee, currency)
+			serviceDef.CancellationFee = &fee
+		}
 
-	// Upsert logic: Create or Update on conflict on ID
-	err := h.DB.Clauses(clause.OnConflict{
-		Columns:   []clause.Column{{Name: "id"}},
-		DoUpdates: clause.AssignmentColumns([]string{"business_id", "name", "description", "duration_minutes", "price", "currency", "is_active", "updated_at"}),
-	}).Create(&serviceDef).Error
-
+		// Upsert logic: Create or Update on conflict on ID
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"business_id", "name", "description", "duration_minutes", "price", "currency", "category", "is_active", "metadata_schema", "requires_payment", "color", "short_label", "cancellation_fee", "capacity", "updated_at"}),
+		}).Create(&serviceDef).Error
+	})
+	if errors.Is(err, ErrCurrencyMismatch) {
+		h.Logger.Error("Rejected service priced in a different currency than its business", "error", err, "serviceId", payload.ServiceID, "businessId", payload.BusinessID)
+		return err
+	}
 	if err != nil {
 		h.Logger.Error("Failed to upsert ServiceDefinition", "error", err, "serviceId", payload.ServiceID)
 		return fmt.Errorf("upsert ServiceDefinition: %w", err)
@@ -96,6 +206,57 @@ func (h *NatsEventHandlers) HandleBusinessServiceCreated(data []byte) error {
 	return nil
 }
 
+// serviceColorPattern matches the "#RRGGBB" colors calendar UIs can use directly.
+var serviceColorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// maxShortLabelLength is the longest short label kept; longer ones are cut.
+const maxShortLabelLength = 16
+
+// serviceDisplay normalizes a service's display metadata. An invalid color is dropped rather than
+// failing the event, since it only affects presentation.
+func serviceDisplay(color, shortLabel string) (string, string) {
+	color = strings.TrimSpace(color)
+	if serviceColorPattern.MatchString(color) {
+		color = strings.ToUpper(color)
+	} else {
+		color = ""
+	}
+
+	shortLabel = strings.TrimSpace(shortLabel)
+	if runes := []rune(shortLabel); len(runes) > maxShortLabelLength {
+		shortLabel = string(runes[:maxShortLabelLength])
+	}
+	return color, shortLabel
+}
+
+// reconcileBusinessCurrency checks a service's currency against its business's and returns the currency to store.
+// A business without a currency adopts the service's; a service without one is priced in the business's.
+// The business row is locked so concurrent services of a new business cannot adopt different currencies.
+func reconcileBusinessCurrency(tx *gorm.DB, businessID, serviceCurrency string) (string, error) {
+	serviceCurrency = strings.ToUpper(strings.TrimSpace(serviceCurrency))
+
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.Business{ID: businessID}).Error; err != nil {
+		return "", fmt.Errorf("ensure business %s: %w", businessID, err)
+	}
+	var business models.Business
+	if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).First(&business, "id = ?", businessID).Error; err != nil {
+		return "", fmt.Errorf("lock business %s: %w", businessID, err)
+	}
+
+	switch {
+	case business.Currency == "" && serviceCurrency != "":
+		if err := tx.Unscoped().Model(&business).Update("currency", serviceCurrency).Error; err != nil {
+			return "", fmt.Errorf("set currency of business %s: %w", businessID, err)
+		}
+		return serviceCurrency, nil
+	case serviceCurrency == "":
+		return business.Currency, nil
+	case serviceCurrency != business.Currency:
+		return "", fmt.Errorf("%w: service is priced in %s but business %s uses %s", ErrCurrencyMismatch, serviceCurrency, businessID, business.Currency)
+	}
+	return serviceCurrency, nil
+}
+
 // HandleBusinessAvailabilityUpdated processes the 'business.availability.updated' event.
 func (h *NatsEventHandlers) HandleBusinessAvailabilityUpdated(data []byte) error {
 	var payload BusinessAvailabilityUpdatedPayload
@@ -104,10 +265,33 @@ func (h *NatsEventHandlers) HandleBusinessAvailabilityUpdated(data []byte) error
 		return fmt.Errorf("unmarshal BusinessAvailabilityUpdatedPayload: %w", err)
 	}
 
-	h.Logger.Info("Processing business.availability.updated event", "businessId", payload.BusinessID)
+	h.Logger.Info("Processing business.availability.updated event", "businessId", payload.BusinessID, "version", payload.Version)
 
 	// Atomically update: delete all existing rules for the business and create new ones
+	stale := false
 	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		// Record the version first; the conditional upsert only touches the row if this event is newer,
+		// and its row lock serialises concurrent updates for the same business.
+		if payload.Version > 0 {
+			result := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "business_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"version", "updated_at"}),
+				Where: clause.Where{Exprs: []clause.Expression{
+					clause.Expr{
+						SQL:  "availability_versions.version < excluded.version OR availability_versions.version >= ?",
+						Vars: []interface{}{legacyAvailabilityVersionFloor},
+					},
+				}},
+			}).Create(&models.AvailabilityVersion{BusinessID: payload.BusinessID, Version: payload.Version})
+			if result.Error != nil {
+				return fmt.Errorf("record availability version: %w", result.Error)
+			}
+			if result.RowsAffected == 0 {
+				stale = true
+				return nil
+			}
+		}
+
 		// Delete existing rules
 		if err := tx.Where("business_id = ?", payload.BusinessID).Delete(&models.AvailabilityRule{}).Error; err != nil {
 			return fmt.Errorf("delete old availability rules: %w", err)
@@ -115,33 +299,302 @@ func (h *NatsEventHandlers) HandleBusinessAvailabilityUpdated(data []byte) error
 
 		// Create new rules if any
 		if len(payload.Rules) > 0 {
-			newRules := make([]models.AvailabilityRule, len(payload.Rules))
-			for i, rulePayload := range payload.Rules {
+			newRules := make([]models.AvailabilityRule, 0, len(payload.Rules))
+			seen := make(map[AvailabilityRulePayload]bool, len(payload.Rules))
+			for _, rulePayload := range payload.Rules {
+				// A business has one rule per day and hours, so repeats in the event are dropped
+				if seen[rulePayload] {
+					continue
+				}
+				seen[rulePayload] = true
+
 				// Basic validation for DayOfWeek enum can be added here if necessary
 				dayOfWeekModel := models.DayOfWeekString(rulePayload.DayOfWeek) // Cast string to models.DayOfWeekString
 				
 				// Add more validation if needed (e.g. time format, start < end)
 				// Though Business Service should have validated this.
 
-				newRules[i] = models.AvailabilityRule{
+				newRules = append(newRules, models.AvailabilityRule{
 					BusinessID: payload.BusinessID,
 					DayOfWeek:  dayOfWeekModel,
 					StartTime:  rulePayload.StartTime,
 					EndTime:    rulePayload.EndTime,
-				}
+				})
 			}
 			if err := tx.Create(&newRules).Error; err != nil {
 				return fmt.Errorf("create new availability rules: %w", err)
 			}
 		}
-		return nil
+		return repository.BumpAvailabilityVersion(tx, payload.BusinessID)
 	})
 
 	if err != nil {
 		h.Logger.Error("Failed to process BusinessAvailabilityUpdated event transaction", "error", err, "businessId", payload.BusinessID)
 		return err
 	}
+	if stale {
+		h.Logger.Warn("Ignoring out-of-order business.availability.updated event", "businessId", payload.BusinessID, "version", payload.Version)
+		return nil
+	}
 
 	h.Logger.Info("Successfully processed business.availability.updated event", "businessId", payload.BusinessID)
 	return nil
 }
+
+// HandleUserCreated processes the 'user.created' event, storing the customer's
+// contact details and notification preferences.
+func (h *NatsEventHandlers) HandleUserCreated(data []byte) error {
+	var payload UserCreatedPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		h.Logger.Error("Failed to unmarshal UserCreatedPayload", "error", err, "rawData", string(data))
+		return fmt.Errorf("unmarshal UserCreatedPayload: %w", err)
+	}
+
+	h.Logger.Info("Processing user.created event", "userId", payload.UserID)
+
+	pref := models.CustomerPreference{
+		CustomerID:         payload.UserID,
+		Email:              payload.Email,
+		Name:               strings.TrimSpace(payload.FirstName + " " + payload.LastName),
+		Language:           payload.Language,
+		EmailNotifications: true, // Default to opted in if not provided
+	}
+	if pref.Language == "" {
+		pref.Language = models.DefaultLanguage
+	}
+	if payload.EmailNotifications != nil {
+		pref.EmailNotifications = *payload.EmailNotifications
+	}
+	if payload.SMSNotifications != nil {
+		pref.SMSNotifications = *payload.SMSNotifications
+	}
+
+	err := h.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "customer_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"email", "name", "language", "email_notifications", "sms_notifications", "updated_at"}),
+	}).Create(&pref).Error
+
+	if err != nil {
+		h.Logger.Error("Failed to upsert CustomerPreference", "error", err, "userId", payload.UserID)
+		return fmt.Errorf("upsert CustomerPreference: %w", err)
+	}
+
+	h.Logger.Info("Successfully processed user.created event", "userId", payload.UserID)
+	return nil
+}
+
+// HandleUserUpdated processes the 'user.updated' event, applying the changed name, language and
+// notification preferences to the customer's stored preferences. Changes to fields kept only by the
+// auth service, and users without stored preferences, are ignored.
+func (h *NatsEventHandlers) HandleUserUpdated(data []byte) error {
+	var payload UserUpdatedPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		h.Logger.Error("Failed to unmarshal UserUpdatedPayload", "error", err, "rawData", string(data))
+		return fmt.Errorf("unmarshal UserUpdatedPayload: %w", err)
+	}
+
+	h.Logger.Info("Processing user.updated event", "userId", payload.UserID)
+
+	changes := payload.Changes
+	updates := map[string]interface{}{}
+	if changes.FirstName != nil || changes.LastName != nil {
+		var firstName, lastName string
+		if changes.FirstName != nil {
+			firstName = *changes.FirstName
+		}
+		if changes.LastName != nil {
+			lastName = *changes.LastName
+		}
+		updates["name"] = strings.TrimSpace(firstName + " " + lastName)
+	}
+	if changes.Language != nil {
+		language := *changes.Language
+		if language == "" {
+			language = models.DefaultLanguage
+		}
+		updates["language"] = language
+	}
+	if changes.EmailNotifications != nil {
+		updates["email_notifications"] = *changes.EmailNotifications
+	}
+	if changes.SMSNotifications != nil {
+		updates["sms_notifications"] = *changes.SMSNotifications
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+	updates["updated_at"] = time.Now()
+
+	result := h.DB.Model(&models.CustomerPreference{}).Where("customer_id = ?", payload.UserID).Updates(updates)
+	if result.Error != nil {
+		h.Logger.Error("Failed to update CustomerPreference", "error", result.Error, "userId", payload.UserID)
+		return fmt.Errorf("update CustomerPreference: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		h.Logger.Warn("No stored preferences for updated user", "userId", payload.UserID)
+		return nil
+	}
+
+	h.Logger.Info("Successfully processed user.updated event", "userId", payload.UserID)
+	return nil
+}
+
+// HandleBusinessCreated processes the 'slotwise.business.created' event.
+func (h *NatsEventHandlers) HandleBusinessCreated(data []byte) error {
+	var envelope BusinessEventEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		h.Logger.Error("Failed to unmarshal business.created event", "error", err, "rawData", string(data))
+		return fmt.Errorf("unmarshal business.created event: %w", err)
+	}
+
+	h.Logger.Info("Processing business.created event", "businessId", envelope.Data.BusinessID)
+
+	business := models.Business{ID: envelope.Data.BusinessID, Name: envelope.Data.Name, Status: envelope.Data.Status}
+	columns := []string{"name", "status", "updated_at"}
+	if envelope.Data.Currency != "" {
+		business.Currency = strings.ToUpper(envelope.Data.Currency)
+		columns = append(columns, "currency")
+	}
+	if envelope.Data.Timezone != "" {
+		business.Timezone = envelope.Data.Timezone
+		columns = append(columns, "timezone")
+	}
+	err := h.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns(columns),
+	}).Create(&business).Error
+	if err != nil {
+		h.Logger.Error("Failed to upsert Business", "error", err, "businessId", business.ID)
+		return fmt.Errorf("upsert Business: %w", err)
+	}
+
+	h.Logger.Info("Successfully processed business.created event", "businessId", business.ID)
+	return nil
+}
+
+// HandleBusinessUpdated processes the 'slotwise.business.updated' event.
+// Only the fields present in the event's changes are updated.
+func (h *NatsEventHandlers) HandleBusinessUpdated(data []byte) error {
+	var envelope BusinessEventEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		h.Logger.Error("Failed to unmarshal business.updated event", "error", err, "rawData", string(data))
+		return fmt.Errorf("unmarshal business.updated event: %w", err)
+	}
+
+	h.Logger.Info("Processing business.updated event", "businessId", envelope.Data.BusinessID)
+
+	business := models.Business{ID: envelope.Data.BusinessID}
+	columns := []string{"updated_at"}
+	if envelope.Data.Changes.Name != nil {
+		business.Name = *envelope.Data.Changes.Name
+		columns = append(columns, "name")
+	}
+	if envelope.Data.Changes.Status != nil {
+		business.Status = *envelope.Data.Changes.Status
+		columns = append(columns, "status")
+	}
+	if envelope.Data.Changes.Currency != nil {
+		business.Currency = strings.ToUpper(*envelope.Data.Changes.Currency)
+		columns = append(columns, "currency")
+	}
+	if envelope.Data.Changes.CancellationCutoffHours != nil {
+		business.CancellationCutoffHours = envelope.Data.Changes.CancellationCutoffHours
+		columns = append(columns, "cancellation_cutoff_hours")
+	}
+	if envelope.Data.Changes.CancellationFeeWindowHours != nil {
+		business.CancellationFeeWindowHours = envelope.Data.Changes.CancellationFeeWindowHours
+		columns = append(columns, "cancellation_fee_window_hours")
+	}
+	if envelope.Data.Changes.CancellationFee != nil {
+		// Converted to minor units once the business's currency is known, below
+		columns = append(columns, "cancellation_fee")
+	}
+	if envelope.Data.Changes.Timezone != nil {
+		business.Timezone = *envelope.Data.Changes.Timezone
+		columns = append(columns, "timezone")
+	}
+	if envelope.Data.Changes.MinimumNoticeMinutes != nil {
+		business.MinimumNoticeMinutes = envelope.Data.Changes.MinimumNoticeMinutes
+		columns = append(columns, "minimum_notice_minutes")
+	}
+	if envelope.Data.Changes.MaxBookingsPerDay != nil {
+		business.MaxBookingsPerDay = envelope.Data.Changes.MaxBookingsPerDay
+		columns = append(columns, "max_bookings_per_day")
+	}
+	if envelope.Data.Changes.MaxBookingsPerCustomerPerDay != nil {
+		business.MaxBookingsPerCustomerPerDay = envelope.Data.Changes.MaxBookingsPerCustomerPerDay
+		columns = append(columns, "max_bookings_per_customer_per_day")
+	}
+
+	// A new timezone moves every slot, a new status can hide them all and the back-to-back setting shows or
+	// hides slots next to bookings, so slots cached against the current availability version are stale
+	slotsChanged := envelope.Data.Changes.Timezone != nil || envelope.Data.Changes.Status != nil || envelope.Data.Changes.AllowBackToBack != nil
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if envelope.Data.Changes.CancellationFee != nil {
+			currency := business.Currency // Set if this update changes it
+			if currency == "" {
+				var stored models.Business
+				if err := tx.Unscoped().Select("currency").Where("id = ?", business.ID).Limit(1).Find(&stored).Error; err != nil {
+					return fmt.Errorf("load Business currency: %w", err)
+				}
+				currency = stored.Currency
+			}
+			fee := models.ToMinorUnits(*envelope.Data.Changes.CancellationFee, currency)
+			business.CancellationFee = &fee
+		}
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "id"}},
+			DoUpdates: clause.AssignmentColumns(columns),
+		}).Create(&business).Error
+		if err != nil {
+			return fmt.Errorf("upsert Business: %w", err)
+		}
+		// Set separately: the upsert would insert the column's default in place of false
+		if envelope.Data.Changes.AllowBackToBack != nil {
+			err := tx.Model(&models.Business{}).Where("id = ?", business.ID).Update("allow_back_to_back", *envelope.Data.Changes.AllowBackToBack).Error
+			if err != nil {
+				return fmt.Errorf("update Business back-to-back setting: %w", err)
+			}
+		}
+		if slotsChanged {
+			return repository.BumpAvailabilityVersion(tx, business.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		h.Logger.Error("Failed to process business.updated event", "error", err, "businessId", business.ID)
+		return err
+	}
+
+	h.Logger.Info("Successfully processed business.updated event", "businessId", business.ID, "slotsChanged", slotsChanged)
+	return nil
+}
+
+// HandleBusinessDeleted processes the 'slotwise.business.deleted' event. The deletion is recorded
+// even for a business that was never synced, so its orphaned services stop serving slots.
+func (h *NatsEventHandlers) HandleBusinessDeleted(data []byte) error {
+	var envelope BusinessEventEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		h.Logger.Error("Failed to unmarshal business.deleted event", "error", err, "rawData", string(data))
+		return fmt.Errorf("unmarshal business.deleted event: %w", err)
+	}
+
+	h.Logger.Info("Processing business.deleted event", "businessId", envelope.Data.BusinessID)
+
+	business := models.Business{
+		ID:        envelope.Data.BusinessID,
+		DeletedAt: gorm.DeletedAt{Time: time.Now(), Valid: true},
+	}
+	err := h.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"deleted_at", "updated_at"}),
+	}).Create(&business).Error
+	if err != nil {
+		h.Logger.Error("Failed to record Business deletion", "error", err, "businessId", business.ID)
+		return fmt.Errorf("record Business deletion: %w", err)
+	}
+
+	h.Logger.Info("Successfully processed business.deleted event", "businessId", business.ID)
+	return nil
+}
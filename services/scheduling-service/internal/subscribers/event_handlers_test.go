@@ -31,7 +31,7 @@ func (suite *EventHandlersTestSuite) SetupSuite() {
 	suite.DB = db
 
 	// AutoMigrate the schema
-	err = suite.DB.AutoMigrate(&models.ServiceDefinition{}, &models.AvailabilityRule{})
+	err = suite.DB.AutoMigrate(&models.Business{}, &models.ServiceDefinition{}, &models.AvailabilityRule{}, &models.AvailabilityVersion{}, &models.CustomerPreference{})
 	assert.NoError(suite.T(), err)
 
 	suite.Handlers = subscribers.NewNatsEventHandlers(suite.DB, suite.TestLogger)
@@ -46,6 +46,9 @@ func (suite *EventHandlersTestSuite) SetupTest() {
 	// Clean up tables before each test
 	suite.DB.Exec("DELETE FROM service_definitions")
 	suite.DB.Exec("DELETE FROM availability_rules")
+	suite.DB.Exec("DELETE FROM availability_versions")
+	suite.DB.Exec("DELETE FROM customer_preferences")
+	suite.DB.Exec("DELETE FROM businesses")
 }
 
 func (suite *EventHandlersTestSuite) TestHandleBusinessServiceCreated_NewService() {
@@ -54,17 +57,25 @@ func (suite *EventHandlersTestSuite) TestHandleBusinessServiceCreated_NewService
 		BusinessID: "biz1",
 		ServiceID:  "svc1",
 		ServiceDetails: struct {
-			Name            string  `json:"name"`
-			Description     *string `json:"description"`
-			DurationMinutes int     `json:"durationMinutes"`
-			Price           float64 `json:"price"`
-			Currency        string  `json:"currency"`
-			IsActive        *bool   `json:"isActive"`
+			Name            string                 `json:"name"`
+			Description     *string                `json:"description"`
+			DurationMinutes int                    `json:"durationMinutes"`
+			Price           float64                `json:"price"`
+			Currency        string                 `json:"currency"`
+			Category        string                 `json:"category"`
+			IsActive        *bool                  `json:"isActive"`
+			MetadataSchema  *models.MetadataSchema `json:"metadataSchema"`
+			RequiresPayment *bool                  `json:"requiresPayment"`
+			Color           string                 `json:"color"`
+			ShortLabel      string                 `json:"shortLabel"`
+			CancellationFee *float64               `json:"cancellationFee"`
+			Capacity        *int                   `json:"capacity"`
 		}{
 			Name:            "Test Service",
 			DurationMinutes: 60,
 			Price:           100.00,
 			Currency:        "USD",
+			Category:        " Massage ",
 		},
 	}
 	isActive := true
@@ -81,8 +92,10 @@ func (suite *EventHandlersTestSuite) TestHandleBusinessServiceCreated_NewService
 	assert.NoError(t, err)
 	assert.Equal(t, "Test Service", serviceDef.Name)
 	assert.Equal(t, 60, serviceDef.DurationMinutes)
+	assert.Equal(t, 1, serviceDef.Capacity, "Services without a capacity are one-to-one")
 	assert.Equal(t, int64(10000), serviceDef.Price) // 100.00 * 100
 	assert.Equal(t, "Test Description", serviceDef.Description)
+	assert.Equal(t, "Massage", serviceDef.Category)
 	assert.True(t, serviceDef.IsActive)
 }
 
@@ -101,12 +114,19 @@ func (suite *EventHandlersTestSuite) TestHandleBusinessServiceCreated_UpdateServ
 		BusinessID: "biz-update",
 		ServiceID:  "svc-update",
 		ServiceDetails: struct {
-			Name            string  `json:"name"`
-			Description     *string `json:"description"`
-			DurationMinutes int     `json:"durationMinutes"`
-			Price           float64 `json:"price"`
-			Currency        string  `json:"currency"`
-			IsActive        *bool   `json:"isActive"`
+			Name            string                 `json:"name"`
+			Description     *string                `json:"description"`
+			DurationMinutes int                    `json:"durationMinutes"`
+			Price           float64                `json:"price"`
+			Currency        string                 `json:"currency"`
+			Category        string                 `json:"category"`
+			IsActive        *bool                  `json:"isActive"`
+			MetadataSchema  *models.MetadataSchema `json:"metadataSchema"`
+			RequiresPayment *bool                  `json:"requiresPayment"`
+			Color           string                 `json:"color"`
+			ShortLabel      string                 `json:"shortLabel"`
+			CancellationFee *float64               `json:"cancellationFee"`
+			Capacity        *int                   `json:"capacity"`
 		}{
 			Name:            "New Name",
 			DurationMinutes: 45,
@@ -130,6 +150,89 @@ func (suite *EventHandlersTestSuite) TestHandleBusinessServiceCreated_UpdateServ
 	assert.False(t, serviceDef.IsActive)
 }
 
+func serviceCreatedEvent(businessID, serviceID, currency string) []byte {
+	payload := subscribers.BusinessServiceCreatedPayload{BusinessID: businessID, ServiceID: serviceID}
+	payload.ServiceDetails.Name = "Priced Service"
+	payload.ServiceDetails.DurationMinutes = 30
+	payload.ServiceDetails.Price = 20.00
+	payload.ServiceDetails.Currency = currency
+	eventData, _ := json.Marshal(payload)
+	return eventData
+}
+
+func (suite *EventHandlersTestSuite) TestHandleBusinessServiceCreated_StoresDisplayMetadata() {
+	t := suite.T()
+	payload := subscribers.BusinessServiceCreatedPayload{BusinessID: "biz-color", ServiceID: "svc-color"}
+	payload.ServiceDetails.Name = "Colour Treatment"
+	payload.ServiceDetails.DurationMinutes = 90
+	payload.ServiceDetails.Currency = "USD"
+	payload.ServiceDetails.Color = "#4f46e5"
+	payload.ServiceDetails.ShortLabel = "  Colour  "
+	eventData, _ := json.Marshal(payload)
+	assert.NoError(t, suite.Handlers.HandleBusinessServiceCreated(eventData))
+
+	var serviceDef models.ServiceDefinition
+	assert.NoError(t, suite.DB.First(&serviceDef, "id = ?", "svc-color").Error)
+	assert.Equal(t, "#4F46E5", serviceDef.Color)
+	assert.Equal(t, "Colour", serviceDef.ShortLabel)
+
+	// A color the UI cannot use is dropped instead of rejecting the service
+	payload.ServiceDetails.Color = "blue"
+	eventData, _ = json.Marshal(payload)
+	assert.NoError(t, suite.Handlers.HandleBusinessServiceCreated(eventData))
+	assert.NoError(t, suite.DB.First(&serviceDef, "id = ?", "svc-color").Error)
+	assert.Empty(t, serviceDef.Color)
+}
+
+func (suite *EventHandlersTestSuite) TestHandleBusinessServiceCreated_RejectsCurrencyMismatch() {
+	t := suite.T()
+	created := []byte(`{"id":"evt1","type":"business.created","data":{"businessId":"biz-eur","name":"Euro Salon","status":"ACTIVE","currency":"EUR"}}`)
+	assert.NoError(t, suite.Handlers.HandleBusinessCreated(created))
+
+	assert.NoError(t, suite.Handlers.HandleBusinessServiceCreated(serviceCreatedEvent("biz-eur", "svc-eur", "eur")))
+
+	err := suite.Handlers.HandleBusinessServiceCreated(serviceCreatedEvent("biz-eur", "svc-usd", "USD"))
+	assert.ErrorIs(t, err, subscribers.ErrCurrencyMismatch)
+
+	var count int64
+	suite.DB.Model(&models.ServiceDefinition{}).Where("id = ?", "svc-usd").Count(&count)
+	assert.Equal(t, int64(0), count, "mismatched service must not be stored")
+
+	var serviceDef models.ServiceDefinition
+	assert.NoError(t, suite.DB.First(&serviceDef, "id = ?", "svc-eur").Error)
+	assert.Equal(t, "EUR", serviceDef.Currency)
+}
+
+func (suite *EventHandlersTestSuite) TestHandleBusinessServiceCreated_RejectsNonPositiveDuration() {
+	t := suite.T()
+	for _, duration := range []int{0, -30} {
+		payload := subscribers.BusinessServiceCreatedPayload{BusinessID: "biz-duration", ServiceID: "svc-zero"}
+		payload.ServiceDetails.Name = "Broken Service"
+		payload.ServiceDetails.DurationMinutes = duration
+		payload.ServiceDetails.Currency = "USD"
+		eventData, _ := json.Marshal(payload)
+
+		err := suite.Handlers.HandleBusinessServiceCreated(eventData)
+		assert.ErrorIs(t, err, subscribers.ErrInvalidServiceDuration, "duration %d", duration)
+	}
+
+	var count int64
+	suite.DB.Model(&models.ServiceDefinition{}).Where("id = ?", "svc-zero").Count(&count)
+	assert.Equal(t, int64(0), count, "a service without a positive duration must not be stored")
+}
+
+func (suite *EventHandlersTestSuite) TestHandleBusinessServiceCreated_FirstServiceSetsCurrency() {
+	t := suite.T()
+	assert.NoError(t, suite.Handlers.HandleBusinessServiceCreated(serviceCreatedEvent("biz-new", "svc-first", "USD")))
+
+	var business models.Business
+	assert.NoError(t, suite.DB.First(&business, "id = ?", "biz-new").Error)
+	assert.Equal(t, "USD", business.Currency)
+
+	err := suite.Handlers.HandleBusinessServiceCreated(serviceCreatedEvent("biz-new", "svc-second", "GBP"))
+	assert.ErrorIs(t, err, subscribers.ErrCurrencyMismatch)
+}
+
 func (suite *EventHandlersTestSuite) TestHandleBusinessAvailabilityUpdated_NewRules() {
 	t := suite.T()
 	payload := subscribers.BusinessAvailabilityUpdatedPayload{
@@ -207,6 +310,224 @@ func (suite *EventHandlersTestSuite) TestHandleBusinessAvailabilityUpdated_Clear
 	assert.Len(t, rules, 0)
 }
 
+func (suite *EventHandlersTestSuite) TestHandleBusinessAvailabilityUpdated_IgnoresOlderVersion() {
+	t := suite.T()
+	businessID := "biz-avail-4"
+
+	newer, _ := json.Marshal(subscribers.BusinessAvailabilityUpdatedPayload{
+		BusinessID: businessID,
+		Version:    200,
+		Rules:      []subscribers.AvailabilityRulePayload{{DayOfWeek: "MONDAY", StartTime: "10:00", EndTime: "16:00"}},
+	})
+	older, _ := json.Marshal(subscribers.BusinessAvailabilityUpdatedPayload{
+		BusinessID: businessID,
+		Version:    100,
+		Rules:      []subscribers.AvailabilityRulePayload{{DayOfWeek: "TUESDAY", StartTime: "08:00", EndTime: "12:00"}},
+	})
+	assert.NoError(t, suite.Handlers.HandleBusinessAvailabilityUpdated(newer))
+	assert.NoError(t, suite.Handlers.HandleBusinessAvailabilityUpdated(older))
+
+	var rules []models.AvailabilityRule
+	suite.DB.Where("business_id = ?", businessID).Find(&rules)
+	if assert.Len(t, rules, 1) {
+		assert.Equal(t, models.Monday, rules[0].DayOfWeek)
+		assert.Equal(t, "10:00", rules[0].StartTime)
+	}
+
+	var version models.AvailabilityVersion
+	assert.NoError(t, suite.DB.First(&version, "business_id = ?", businessID).Error)
+	assert.Equal(t, int64(200), version.Version)
+	assert.Equal(t, int64(1), version.Revision, "The ignored snapshot does not bump the revision")
+}
+
+func (suite *EventHandlersTestSuite) TestHandleBusinessAvailabilityUpdated_AppliesNewerVersion() {
+	t := suite.T()
+	businessID := "biz-avail-5"
+
+	older, _ := json.Marshal(subscribers.BusinessAvailabilityUpdatedPayload{
+		BusinessID: businessID,
+		Version:    100,
+		Rules:      []subscribers.AvailabilityRulePayload{{DayOfWeek: "TUESDAY", StartTime: "08:00", EndTime: "12:00"}},
+	})
+	newer, _ := json.Marshal(subscribers.BusinessAvailabilityUpdatedPayload{
+		BusinessID: businessID,
+		Version:    200,
+		Rules:      []subscribers.AvailabilityRulePayload{{DayOfWeek: "MONDAY", StartTime: "10:00", EndTime: "16:00"}},
+	})
+	assert.NoError(t, suite.Handlers.HandleBusinessAvailabilityUpdated(older))
+	assert.NoError(t, suite.Handlers.HandleBusinessAvailabilityUpdated(newer))
+
+	var rules []models.AvailabilityRule
+	suite.DB.Where("business_id = ?", businessID).Find(&rules)
+	if assert.Len(t, rules, 1) {
+		assert.Equal(t, models.Monday, rules[0].DayOfWeek)
+	}
+
+	var version models.AvailabilityVersion
+	assert.NoError(t, suite.DB.First(&version, "business_id = ?", businessID).Error)
+	assert.Equal(t, int64(200), version.Version)
+	assert.Equal(t, int64(2), version.Revision, "Each applied snapshot bumps the revision")
+}
+
+func (suite *EventHandlersTestSuite) TestHandleBusinessAvailabilityUpdated_CounterReplacesTimestampVersion() {
+	t := suite.T()
+	businessID := "biz-avail-6"
+
+	// Versions used to be publish times in milliseconds; the per-business counter that replaced them is smaller
+	legacy, _ := json.Marshal(subscribers.BusinessAvailabilityUpdatedPayload{
+		BusinessID: businessID,
+		Version:    1760529600000,
+		Rules:      []subscribers.AvailabilityRulePayload{{DayOfWeek: "TUESDAY", StartTime: "08:00", EndTime: "12:00"}},
+	})
+	counted := func(version int64, startTime string) []byte {
+		data, _ := json.Marshal(subscribers.BusinessAvailabilityUpdatedPayload{
+			BusinessID: businessID,
+			Version:    version,
+			Rules:      []subscribers.AvailabilityRulePayload{{DayOfWeek: "MONDAY", StartTime: startTime, EndTime: "16:00"}},
+		})
+		return data
+	}
+	assert.NoError(t, suite.Handlers.HandleBusinessAvailabilityUpdated(legacy))
+	assert.NoError(t, suite.Handlers.HandleBusinessAvailabilityUpdated(counted(3, "10:00")))
+	// From here on the counter orders updates as usual
+	assert.NoError(t, suite.Handlers.HandleBusinessAvailabilityUpdated(counted(2, "11:00")))
+
+	var rules []models.AvailabilityRule
+	suite.DB.Where("business_id = ?", businessID).Find(&rules)
+	if assert.Len(t, rules, 1) {
+		assert.Equal(t, models.Monday, rules[0].DayOfWeek)
+		assert.Equal(t, "10:00", rules[0].StartTime)
+	}
+
+	var version models.AvailabilityVersion
+	assert.NoError(t, suite.DB.First(&version, "business_id = ?", businessID).Error)
+	assert.Equal(t, int64(3), version.Version)
+}
+
+func (suite *EventHandlersTestSuite) TestAmountsAreStoredInTheCurrencysMinorUnits() {
+	t := suite.T()
+
+	// The yen has no minor unit, so amounts are stored as they are rather than in hundredths
+	created := []byte(`{"businessId":"biz-jpy","serviceId":"svc-jpy","serviceDetails":{"name":"Cut","durationMinutes":30,"price":4500,"currency":"JPY","cancellationFee":1000}}`)
+	assert.NoError(t, suite.Handlers.HandleBusinessServiceCreated(created))
+	var serviceDef models.ServiceDefinition
+	assert.NoError(t, suite.DB.First(&serviceDef, "id = ?", "svc-jpy").Error)
+	assert.Equal(t, int64(4500), serviceDef.Price)
+	if assert.NotNil(t, serviceDef.CancellationFee) {
+		assert.Equal(t, int64(1000), *serviceDef.CancellationFee)
+	}
+
+	updated := []byte(`{"id":"evt-jpy","type":"business.updated","data":{"businessId":"biz-jpy","changes":{"cancellationFee":2000}}}`)
+	assert.NoError(t, suite.Handlers.HandleBusinessUpdated(updated))
+	var business models.Business
+	assert.NoError(t, suite.DB.First(&business, "id = ?", "biz-jpy").Error)
+	if assert.NotNil(t, business.CancellationFee) {
+		assert.Equal(t, int64(2000), *business.CancellationFee)
+	}
+
+	// A fee sent together with a new currency is read in that currency
+	updated = []byte(`{"id":"evt-kwd","type":"business.updated","data":{"businessId":"biz-jpy","changes":{"currency":"KWD","cancellationFee":1.5}}}`)
+	assert.NoError(t, suite.Handlers.HandleBusinessUpdated(updated))
+	business = models.Business{}
+	assert.NoError(t, suite.DB.First(&business, "id = ?", "biz-jpy").Error)
+	if assert.NotNil(t, business.CancellationFee) {
+		assert.Equal(t, int64(1500), *business.CancellationFee)
+	}
+}
+
+func (suite *EventHandlersTestSuite) TestHandleBusinessLifecycleEvents() {
+	t := suite.T()
+
+	created := []byte(`{"id":"evt1","type":"business.created","data":{"businessId":"biz-life","name":"Life Salon","status":"PENDING_SETUP"}}`)
+	assert.NoError(t, suite.Handlers.HandleBusinessCreated(created))
+
+	updated := []byte(`{"id":"evt2","type":"business.updated","data":{"businessId":"biz-life","changes":{"status":"ACTIVE"}}}`)
+	assert.NoError(t, suite.Handlers.HandleBusinessUpdated(updated))
+
+	var business models.Business
+	assert.NoError(t, suite.DB.First(&business, "id = ?", "biz-life").Error)
+	assert.Equal(t, "Life Salon", business.Name)
+	assert.Equal(t, "ACTIVE", business.Status)
+	assert.True(t, business.IsActive())
+
+	deleted := []byte(`{"id":"evt3","type":"business.deleted","data":{"businessId":"biz-life","ownerId":"owner1"}}`)
+	assert.NoError(t, suite.Handlers.HandleBusinessDeleted(deleted))
+
+	business = models.Business{}
+	assert.NoError(t, suite.DB.Unscoped().First(&business, "id = ?", "biz-life").Error)
+	assert.True(t, business.DeletedAt.Valid)
+	assert.False(t, business.IsActive())
+}
+
+func (suite *EventHandlersTestSuite) TestHandleBusinessDeleted_UnknownBusiness() {
+	t := suite.T()
+
+	deleted := []byte(`{"id":"evt4","type":"business.deleted","data":{"businessId":"biz-never-synced"}}`)
+	assert.NoError(t, suite.Handlers.HandleBusinessDeleted(deleted))
+
+	var business models.Business
+	assert.NoError(t, suite.DB.Unscoped().First(&business, "id = ?", "biz-never-synced").Error)
+	assert.True(t, business.DeletedAt.Valid)
+}
+
+func (suite *EventHandlersTestSuite) TestHandleUserCreated_StoresPreferences() {
+	t := suite.T()
+	eventData := []byte(`{"userId":"user-quiet","email":"quiet@example.com","firstName":"Quinn","lastName":"Quiet","language":"de","emailNotifications":false,"smsNotifications":true}`)
+	err := suite.Handlers.HandleUserCreated(eventData)
+	assert.NoError(t, err)
+
+	var pref models.CustomerPreference
+	err = suite.DB.First(&pref, "customer_id = ?", "user-quiet").Error
+	assert.NoError(t, err)
+	assert.Equal(t, "quiet@example.com", pref.Email)
+	assert.Equal(t, "Quinn Quiet", pref.Name)
+	assert.Equal(t, "de", pref.Language)
+	assert.False(t, pref.EmailNotifications)
+	assert.True(t, pref.SMSNotifications)
+}
+
+func (suite *EventHandlersTestSuite) TestHandleUserCreated_DefaultsWhenPreferencesMissing() {
+	t := suite.T()
+	eventData := []byte(`{"userId":"user-legacy","email":"legacy@example.com"}`)
+	err := suite.Handlers.HandleUserCreated(eventData)
+	assert.NoError(t, err)
+
+	var pref models.CustomerPreference
+	err = suite.DB.First(&pref, "customer_id = ?", "user-legacy").Error
+	assert.NoError(t, err)
+	assert.Equal(t, models.DefaultLanguage, pref.Language)
+	assert.True(t, pref.EmailNotifications)
+	assert.False(t, pref.SMSNotifications)
+}
+
+func (suite *EventHandlersTestSuite) TestHandleUserUpdated_AppliesChangedFields() {
+	t := suite.T()
+	err := suite.Handlers.HandleUserCreated([]byte(`{"userId":"user-moved","email":"moved@example.com","firstName":"Mo","lastName":"Ved","language":"en","emailNotifications":true}`))
+	assert.NoError(t, err)
+
+	err = suite.Handlers.HandleUserUpdated([]byte(`{"userId":"user-moved","changes":{"firstName":"Mo","lastName":"Ventura","language":"fr","emailNotifications":false}}`))
+	assert.NoError(t, err)
+
+	var pref models.CustomerPreference
+	err = suite.DB.First(&pref, "customer_id = ?", "user-moved").Error
+	assert.NoError(t, err)
+	assert.Equal(t, "Mo Ventura", pref.Name)
+	assert.Equal(t, "fr", pref.Language)
+	assert.False(t, pref.EmailNotifications)
+	assert.False(t, pref.SMSNotifications, "Fields not in the changes are left alone")
+	assert.Equal(t, "moved@example.com", pref.Email)
+}
+
+func (suite *EventHandlersTestSuite) TestHandleUserUpdated_UnknownUserIgnored() {
+	t := suite.T()
+	err := suite.Handlers.HandleUserUpdated([]byte(`{"userId":"user-unknown","changes":{"language":"fr"}}`))
+	assert.NoError(t, err)
+
+	var count int64
+	suite.DB.Model(&models.CustomerPreference{}).Where("customer_id = ?", "user-unknown").Count(&count)
+	assert.Zero(t, count, "An update does not create preferences")
+}
+
 func TestEventHandlersTestSuite(t *testing.T) {
 	suite.Run(t, new(EventHandlersTestSuite))
 }
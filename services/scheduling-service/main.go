@@ -22,6 +22,7 @@ import (
 	"github.com/slotwise/scheduling-service/internal/repository"
 	"github.com/slotwise/scheduling-service/internal/service"
 	"github.com/slotwise/scheduling-service/internal/subscribers" // Added import
+	"github.com/slotwise/scheduling-service/pkg/clock"
 	"github.com/slotwise/scheduling-service/pkg/events"
 	"github.com/slotwise/scheduling-service/pkg/logger"
 	"github.com/slotwise/scheduling-service/pkg/scheduler"
@@ -81,29 +82,44 @@ func main() {
 	// Initialize repositories
 	bookingRepo := repository.NewBookingRepository(db)
 	availabilityRepo := repository.NewAvailabilityRepository(db)
+	outboxRepo := repository.NewOutboxRepository(db)
+	customerPrefRepo := repository.NewCustomerPreferenceRepository(db)
 
 	// Initialize cache repository
 	cacheRepo := repository.NewCacheRepository(redisClient)
+	slotHoldRepo := repository.NewSlotHoldRepository(redisClient)
 
 	// Initialize services
 	// AvailabilityService now needs BookingRepository
-	availabilityService := service.NewAvailabilityService(availabilityRepo, bookingRepo, cacheRepo, eventPublisher, logger)
+	availabilityService := service.NewAvailabilityService(availabilityRepo, bookingRepo, cacheRepo, slotHoldRepo, cfg.SlotHoldTTL, cfg.MaxSlotsPerDay, eventPublisher, clock.Real{}, logger)
+	if dayparts, err := service.NewDaypartBoundaries(cfg.DaypartAfternoonStart, cfg.DaypartEveningStart); err != nil {
+		logger.Warn("Ignoring invalid daypart boundaries, using defaults", "error", err)
+	} else {
+		availabilityService.SetDaypartBoundaries(dayparts)
+	}
 
 	// Initialize Notification Client
 	notificationClient := client.NewNotificationServiceClient(cfg)
 
 	// BookingService now needs AvailabilityRepository for service definitions and NotificationClient
-	bookingService := service.NewBookingService(bookingRepo, availabilityService, availabilityRepo, eventPublisher, notificationClient, logger)
+	// Booking events go through the transactional outbox so they survive a crash before publishing
+	outboxRelay := service.NewOutboxRelay(outboxRepo, eventPublisher, logger)
+	bookingService := service.NewBookingService(bookingRepo, availabilityService, availabilityRepo, customerPrefRepo, outboxRelay, eventPublisher, notificationClient, clock.Real{}, logger)
+	if len(cfg.ExchangeRates) > 0 {
+		bookingService.SetExchangeRateSource(service.StaticExchangeRates(cfg.ExchangeRates))
+	}
+
+	// Owners get one morning summary of the day's bookings, also delivered through the outbox
+	dailyDigestService := service.NewDailyDigestService(availabilityRepo, bookingRepo, outboxRepo, outboxRelay, clock.Real{}, logger)
 
 	// Initialize background scheduler
-	cronScheduler := scheduler.New(bookingService, logger)
+	cronScheduler := scheduler.New(bookingService, outboxRelay, dailyDigestService, cfg.PendingPaymentTimeout, clock.Real{}, logger)
 	cronScheduler.Start()
 	defer cronScheduler.Stop()
 
 	// Initialize handlers
 	bookingHandler := handlers.NewBookingHandler(bookingService, logger)
 	availabilityHandler := handlers.NewAvailabilityHandler(availabilityService, logger)
-	healthHandler := handlers.NewHealthHandler(db, redisClient, natsConn, logger)
 
 	// Setup event subscribers first, as SubscriptionManager needs it.
 	var eventSubscriber *events.Subscriber
@@ -113,6 +129,17 @@ func main() {
 		eventSubscriber = events.NewSubscriber(natsConn, logger)
 		// Initialize WebSocket SubscriptionManager and run it
 		subscriptionManager = realtime.NewSubscriptionManager(logger, eventSubscriber) // Pass eventSubscriber
+		subscriptionManager.MaxEventBytes = cfg.Realtime.MaxEventBytes
+		if cfg.Realtime.DeadLetterSubject != "" {
+			subscriptionManager.DeadLetterPublisher = eventPublisher
+			subscriptionManager.DeadLetterSubject = cfg.Realtime.DeadLetterSubject
+		}
+		// Callback registrations are kept in the database so integrators keep receiving updates across restarts
+		subscriptionManager.CallbackStore = repository.NewRealtimeCallbackRepository(db)
+		subscriptionManager.AllowPrivateCallbacks = cfg.Realtime.AllowPrivateCallbacks
+		if err := subscriptionManager.LoadCallbacks(context.Background()); err != nil {
+			logger.Error("Failed to load realtime callbacks", "error", err)
+		}
 		go subscriptionManager.Run()
 		subscriptionManager.StartEventSubscriptions() // Start NATS subscriptions for the manager
 	} else {
@@ -120,7 +147,22 @@ func main() {
 	}
 
 	// Initialize WebSocket handler
-	webSocketHandler := handlers.NewWebSocketHandler(subscriptionManager, logger)
+	// Browsers may only connect from allowlisted origins in production; development stays permissive
+	originPolicy := handlers.OriginPolicy{AllowedOrigins: cfg.AllowedOrigins, Enforce: cfg.Environment == "production"}
+	if originPolicy.Enforce && len(originPolicy.AllowedOrigins) == 0 {
+		logger.Warn("ALLOWED_ORIGINS is empty, browser WebSocket connections will be rejected")
+	}
+	// Access tokens are checked against the auth service's issuer, audience and revocation denylist
+	tokenValidator := &middleware.TokenValidator{
+		Secret:   cfg.JWT.Secret,
+		Issuer:   cfg.JWT.Issuer,
+		Audience: cfg.JWT.Audience,
+		Denylist: repository.NewTokenDenylistRepository(redisClient),
+	}
+	webSocketHandler := handlers.NewWebSocketHandler(subscriptionManager, originPolicy, tokenValidator, logger)
+
+	// Initialize admin handler (WebSocket client management)
+	adminHandler := handlers.NewAdminHandler(subscriptionManager, logger)
 
 	// Initialize NATS event handlers (from subscribers package)
 	natsEventHandlers := subscribers.NewNatsEventHandlers(db, logger)
@@ -136,25 +178,53 @@ func main() {
 	// Note: `setupEventSubscribers` might need adjustment if some subscriptions
 	// are now handled by SubscriptionManager. For now, assuming it's for other event handlers.
 
+	// Readiness reports the state of the subscriptions registered above
+	var subscriptionReporter handlers.SubscriptionStatusReporter
+	if eventSubscriber != nil {
+		subscriptionReporter = eventSubscriber
+	}
+	healthHandler := handlers.NewHealthHandler(db, redisClient, natsConn, subscriptionReporter, requiredSubscriptions, logger)
+	// A nil manager must stay a nil interface, or the metrics handler would call into it
+	var realtimeStats handlers.RealtimeStatsReporter
+	if subscriptionManager != nil {
+		realtimeStats = subscriptionManager
+	}
+	metricsHandler := handlers.NewMetricsHandler(cronScheduler, realtimeStats, logger)
+
 	// Setup Gin router
 	if cfg.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
 	router := gin.New()
+	// Only believe X-Forwarded-For from our own proxies; the per-IP rate limit keys on the client IP
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		logger.Fatal("Invalid TRUSTED_PROXIES", "error", err)
+	}
 	router.Use(gin.Recovery())
 	router.Use(middleware.Logger(logger))
-	router.Use(middleware.CORS())
+	router.Use(middleware.CORS(middleware.CORSConfig{
+		AllowOrigins:     cfg.CORS.AllowedOrigins,
+		AllowMethods:     cfg.CORS.AllowedMethods,
+		AllowHeaders:     cfg.CORS.AllowedHeaders,
+		AllowCredentials: cfg.CORS.AllowCredentials,
+		MaxAge:           cfg.CORS.MaxAge,
+	}))
 	router.Use(middleware.RequestID())
+	router.Use(middleware.MaxBodyBytes(middleware.DefaultMaxBodyBytes))
 
 	// Health check routes
 	router.GET("/health", healthHandler.Health)
 	router.GET("/health/ready", healthHandler.Ready)
 	router.GET("/health/live", healthHandler.Live)
+	router.GET("/metrics", metricsHandler.Metrics) // Background job runs, for dashboards and stale-job alerts
 
 	// WebSocket route (can be outside /api/v1 if preferred)
 	router.GET("/ws/availability", webSocketHandler.HandleConnections)
 
+	// Public availability endpoints are unauthenticated, so they get a per-IP limit against scraping
+	publicRateLimit := middleware.RateLimit(cfg.PublicRateLimit.Requests, cfg.PublicRateLimit.Window)
+
 	// API routes
 	v1 := router.Group("/api/v1")
 	{
@@ -163,17 +233,21 @@ func main() {
 		// TODO: Add appropriate auth middleware for these routes.
 		// Example: bookings.Use(middleware.RequireAuth())
 		{
-			bookings.POST("", bookingHandler.CreateBooking)                        // POST /api/v1/bookings
+			bookings.POST("", middleware.RequireAuth(tokenValidator), bookingHandler.CreateBooking) // Customers for themselves; the business for guests
 			bookings.GET("/:bookingId", bookingHandler.GetBookingByID)             // GET /api/v1/bookings/:bookingId
-			bookings.GET("", bookingHandler.ListBookings)                          // GET /api/v1/bookings?customerId=... or ?businessId=...
-			bookings.PUT("/:bookingId/status", bookingHandler.UpdateBookingStatus) // PUT /api/v1/bookings/:bookingId/status
+			bookings.GET("", middleware.RequireAuth(tokenValidator), bookingHandler.ListBookings) // ?customerId= for the customer, ?businessId= for its owners
+			bookings.PUT("/:bookingId/status", middleware.RequireAuth(tokenValidator), middleware.RequireBusinessOwner(""), bookingHandler.UpdateBookingStatus) // Admins, or owners for their own bookings
+			bookings.PUT("/status-bulk", middleware.RequireAuth(tokenValidator), middleware.RequireBusinessOwner(""), bookingHandler.BulkUpdateBookingStatus) // Admins, or owners for their own bookings
+			bookings.GET("/:bookingId/history", middleware.RequireAuth(tokenValidator), middleware.RequireBusinessOwner(""), bookingHandler.GetBookingStatusHistory) // Admins, or owners for their own bookings
+			bookings.DELETE("/:bookingId", middleware.RequireAuth(tokenValidator), bookingHandler.CancelBooking) // DELETE /api/v1/bookings/:bookingId (customer cancellation)
+			bookings.DELETE("/:bookingId/pending", middleware.RequireAuth(tokenValidator), bookingHandler.CancelPendingBooking) // Customer drops an unpaid booking
+			bookings.POST("/:bookingId/resend-confirmation", middleware.RequireAuth(tokenValidator), bookingHandler.ResendConfirmation) // Customer or business
+			bookings.POST("/:bookingId/reschedule", middleware.RequireAuth(tokenValidator), bookingHandler.RescheduleBooking) // Customer, business owner or admin
 
 			// Remove or update old stubbed routes if they are different:
 			// bookings.GET("/:id", bookingHandler.GetBooking) // This was likely the old GetBookingByID
 			// bookings.PUT("/:id", bookingHandler.UpdateBooking) // This was likely the old UpdateBookingStatus or a general update
-			// bookings.DELETE("/:id", bookingHandler.CancelBooking) // This might map to UpdateBookingStatus with "CANCELLED"
 			// bookings.POST("/:id/confirm", bookingHandler.ConfirmBooking) // This might map to UpdateBookingStatus with "CONFIRMED"
-			// bookings.POST("/:id/reschedule", bookingHandler.RescheduleBooking) // Future feature
 		}
 
 		// Availability routes
@@ -182,17 +256,35 @@ func main() {
 			availability.GET("/", availabilityHandler.GetAvailability) // Existing general availability endpoint
 			// Add other existing availability rule/exception routes if they are still relevant
 			// For example:
-			availability.POST("/rules", availabilityHandler.CreateAvailabilityRule) // Registering the new endpoint
-			// availability.PUT("/rules/:id", availabilityHandler.UpdateAvailabilityRule)
+			availability.GET("/rules", availabilityHandler.ListAvailabilityRules)   // GET /api/v1/availability/rules?businessId=...
+			// Rule changes need an owner or admin; the handlers check that the rule's business is the caller's
+			availability.POST("/rules", middleware.RequireAuth(tokenValidator), middleware.RequireBusinessOwner(""), availabilityHandler.CreateAvailabilityRule) // Registering the new endpoint
+			availability.GET("/rules/:id", availabilityHandler.GetAvailabilityRule)     // Single rule with audit metadata
+			availability.PATCH("/rules/:id", middleware.RequireAuth(tokenValidator), middleware.RequireBusinessOwner(""), availabilityHandler.UpdateAvailabilityRule) // Partial update
+			availability.PUT("/rules/:id/active", middleware.RequireAuth(tokenValidator), middleware.RequireBusinessOwner(""), availabilityHandler.SetAvailabilityRuleActive) // Deactivate or reactivate without deleting
+			availability.POST("/rules/preview", middleware.RequireAuth(tokenValidator), middleware.RequireBusinessOwner(""), availabilityHandler.PreviewRuleChange)  // Bookings a proposed schedule would leave out of hours
+			availability.DELETE("/rules", middleware.RequireAuth(tokenValidator), middleware.RequireBusinessOwner(""), availabilityHandler.DeleteAvailabilityRulesForDay) // DELETE /api/v1/availability/rules?businessId=...&day=SUNDAY
+			availability.POST("/snapshot", publicRateLimit, availabilityHandler.GetAvailabilitySnapshot)       // POST /api/v1/availability/snapshot (many businesses at once)
 			// ...
 		}
 
 		// Route for business calendar
-		v1.GET("/businesses/:businessId/calendar", availabilityHandler.GetBusinessCalendarHandler)
-
-		// Internal API for scheduling service (e.g. for slot generation)
+		v1.GET("/businesses/:businessId/calendar", publicRateLimit, availabilityHandler.GetBusinessCalendarHandler)
+		v1.GET("/businesses", publicRateLimit, availabilityHandler.SearchBusinesses)                                 // Public: marketplace search by service category and date
+		v1.GET("/businesses/:businessId/has-availability", publicRateLimit, availabilityHandler.HasAvailability) // Public: any open slot across services
+		v1.GET("/businesses/:businessId/services", publicRateLimit, availabilityHandler.ListBusinessServices)       // Public: active services with hasUpcomingAvailability
+		// Pause or resume new bookings: PUT /api/v1/businesses/:businessId/accepting-bookings {"acceptingBookings": false}
+		v1.PUT("/businesses/:businessId/accepting-bookings", middleware.RequireAuth(tokenValidator), middleware.RequireBusinessOwner("businessId"), availabilityHandler.SetAcceptingBookings)
+		// Revenue from completed bookings: GET /api/v1/businesses/:businessId/revenue?from=YYYY-MM-DD&to=YYYY-MM-DD
+		v1.GET("/businesses/:businessId/revenue", middleware.RequireAuth(tokenValidator), middleware.RequireBusinessOwner("businessId"), bookingHandler.GetRevenueSummary)
+		// Dashboard headline numbers in the business timezone: GET /api/v1/businesses/:businessId/stats
+		v1.GET("/businesses/:businessId/stats", middleware.RequireAuth(tokenValidator), middleware.RequireBusinessOwner("businessId"), bookingHandler.GetDashboardStats)
+		// Bring over bookings from another system: POST /api/v1/businesses/:businessId/bookings/import {"bookings": [...]}
+		v1.POST("/businesses/:businessId/bookings/import", middleware.RequireAuth(tokenValidator), middleware.RequireBusinessOwner("businessId"), middleware.MaxBodyBytes(handlers.MaxImportBodyBytes), bookingHandler.ImportBookings)
+
+		// Internal API for other services (e.g. for slot generation); callers send an X-Service-Token
 		internal := v1.Group("/internal")
-		// Add auth middleware if needed for internal APIs, e.g. service-to-service auth
+		internal.Use(middleware.RequireServiceToken(cfg.ServiceAuthTokens))
 		{
 			internalAvailability := internal.Group("/availability")
 			{
@@ -202,7 +294,22 @@ func main() {
 
 		// Publicly accessible slots endpoint for a specific service
 		// GET /api/v1/services/:serviceId/slots?date=YYYY-MM-DD&businessId=...
-		v1.GET("/services/:serviceId/slots", availabilityHandler.GetPublicSlotsForService)
+		v1.GET("/services/:serviceId/slots", publicRateLimit, availabilityHandler.GetPublicSlotsForService)
+		// POST /api/v1/services/:serviceId/hold holds a slot while the customer completes checkout
+		v1.POST("/services/:serviceId/hold", publicRateLimit, middleware.RequireAuth(tokenValidator), availabilityHandler.HoldSlot)
+		// GET /api/v1/services/:serviceId/alternatives?businessId=...&startTime=...&count=3 suggests the closest open slots
+		v1.GET("/services/:serviceId/alternatives", publicRateLimit, availabilityHandler.SuggestAlternatives)
+
+		// Admin routes (require an admin access token from the auth service)
+		admin := v1.Group("/admin")
+		admin.Use(middleware.RequireAuth(tokenValidator), middleware.RequireAdmin())
+		{
+			admin.GET("/ws/clients", adminHandler.ListWebSocketClients)
+			admin.DELETE("/ws/clients/:clientId", adminHandler.DisconnectWebSocketClient)
+			admin.GET("/ws/callbacks", adminHandler.ListCallbacks)
+			admin.PUT("/ws/callbacks/:businessId", adminHandler.RegisterCallback)
+			admin.DELETE("/ws/callbacks/:businessId", adminHandler.UnregisterCallback)
+		}
 	}
 
 	// Create HTTP server
@@ -240,6 +347,16 @@ func main() {
 	logger.Info("Scheduling Service stopped")
 }
 
+// requiredSubscriptions must be active for /health/ready to report ready
+var requiredSubscriptions = []string{
+	"payment.succeeded",
+	"payment.failed",
+	"business.service.created",
+	events.BookingConfirmedEvent, // Realtime WebSocket updates
+	events.BookingCancelledEvent,
+	events.AvailabilityRuleUpdatedEvent,
+}
+
 // Updated function signature to include NatsEventHandlers
 func setupEventSubscribers(
 	subscriber *events.Subscriber,
@@ -271,5 +388,27 @@ func setupEventSubscribers(
 		return fmt.Errorf("failed to subscribe to business.availability.updated: %w", err)
 	}
 
+	// Track business lifecycle so services of deleted businesses stop serving slots
+	if err := subscriber.Subscribe("slotwise.business.created", natsEventHandlers.HandleBusinessCreated); err != nil {
+		return fmt.Errorf("failed to subscribe to slotwise.business.created: %w", err)
+	}
+
+	if err := subscriber.Subscribe("slotwise.business.updated", natsEventHandlers.HandleBusinessUpdated); err != nil {
+		return fmt.Errorf("failed to subscribe to slotwise.business.updated: %w", err)
+	}
+
+	if err := subscriber.Subscribe("slotwise.business.deleted", natsEventHandlers.HandleBusinessDeleted); err != nil {
+		return fmt.Errorf("failed to subscribe to slotwise.business.deleted: %w", err)
+	}
+
+	// Keep customer notification preferences in sync with the Auth Service
+	if err := subscriber.Subscribe("user.created", natsEventHandlers.HandleUserCreated); err != nil {
+		return fmt.Errorf("failed to subscribe to user.created: %w", err)
+	}
+
+	if err := subscriber.Subscribe("user.updated", natsEventHandlers.HandleUserUpdated); err != nil {
+		return fmt.Errorf("failed to subscribe to user.updated: %w", err)
+	}
+
 	return nil
 }
@@ -0,0 +1,60 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the current time. Services take a Clock instead of calling time.Now
+// so that time-dependent behavior can be tested deterministically.
+type Clock interface {
+	Now() time.Time
+}
+
+// Real is a Clock backed by the system time.
+type Real struct{}
+
+// Now returns the current system time.
+func (Real) Now() time.Time {
+	return time.Now()
+}
+
+// OrReal returns c, or a Real clock if c is nil.
+func OrReal(c Clock) Clock {
+	if c == nil {
+		return Real{}
+	}
+	return c
+}
+
+// FakeClock is a Clock for tests that only moves when told to.
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFake creates a fake clock set to now.
+func NewFake(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the fake clock's current time.
+func (f *FakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Advance moves the fake clock forward by d.
+func (f *FakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
+
+// Set moves the fake clock to now.
+func (f *FakeClock) Set(now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = now
+}
@@ -3,6 +3,8 @@ package events
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
+	"sync"
 
 	"github.com/nats-io/nats.go"
 	"github.com/slotwise/scheduling-service/internal/config"
@@ -24,6 +26,17 @@ type NullPublisher struct {
 type Subscriber struct {
 	conn   *nats.Conn
 	logger *logger.Logger
+
+	mu            sync.Mutex
+	subscriptions map[string]*nats.Subscription
+	failures      map[string]error // Subjects whose last subscription attempt failed
+}
+
+// SubscriptionStatus is the registration state of the subscription to a subject
+type SubscriptionStatus struct {
+	Subject string `json:"subject"`
+	Active  bool   `json:"active"`
+	Error   string `json:"error,omitempty"`
 }
 
 // Connect connects to NATS
@@ -75,34 +88,62 @@ func (p *Publisher) Publish(subject string, data interface{}) error {
 // NewSubscriber creates a new event subscriber
 func NewSubscriber(conn *nats.Conn, logger *logger.Logger) *Subscriber {
 	return &Subscriber{
-		conn:   conn,
-		logger: logger,
+		conn:          conn,
+		logger:        logger,
+		subscriptions: make(map[string]*nats.Subscription),
+		failures:      make(map[string]error),
 	}
 }
 
 // Subscribe subscribes to events on a subject
 func (s *Subscriber) Subscribe(subject string, handler func([]byte) error) error {
-	_, err := s.conn.Subscribe(subject, func(msg *nats.Msg) {
+	sub, err := s.conn.Subscribe(subject, func(msg *nats.Msg) {
 		if err := handler(msg.Data); err != nil {
 			s.logger.Error("Failed to handle event", "subject", subject, "error", err)
 		}
 	})
 
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	if err != nil {
+		s.failures[subject] = err
 		return fmt.Errorf("failed to subscribe to subject %s: %w", subject, err)
 	}
+	s.subscriptions[subject] = sub
+	delete(s.failures, subject)
 
 	s.logger.Debug("Subscribed to subject", "subject", subject)
 	return nil
 }
 
+// SubscriptionStatuses reports every subject a subscription was attempted for, sorted by subject.
+// A subscription is inactive if it failed to register or has since been closed.
+func (s *Subscriber) SubscriptionStatuses() []SubscriptionStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]SubscriptionStatus, 0, len(s.subscriptions)+len(s.failures))
+	for subject, sub := range s.subscriptions {
+		statuses = append(statuses, SubscriptionStatus{Subject: subject, Active: sub.IsValid()})
+	}
+	for subject, err := range s.failures {
+		statuses = append(statuses, SubscriptionStatus{Subject: subject, Error: err.Error()})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Subject < statuses[j].Subject })
+	return statuses
+}
+
 // Event Subjects
 const (
 	BookingRequestedEvent = "booking.requested"
 	BookingConfirmedEvent = "booking.confirmed"
 	BookingCancelledEvent = "booking.cancelled"
+	// BookingRescheduledEvent is published when a booking moves to a new time
+	BookingRescheduledEvent = "booking.rescheduled"
 	SlotReservedEvent     = "slot.reserved"
 	// AvailabilityRuleUpdatedEvent is published when availability rules change
 	AvailabilityRuleUpdatedEvent = "availability.rule.updated"
+	// BusinessDailyDigestEvent summarises a business's bookings for the day, once each morning
+	BusinessDailyDigestEvent = "business.daily_digest"
 	// Add other event subjects as needed
 )
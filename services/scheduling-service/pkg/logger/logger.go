@@ -1,6 +1,8 @@
 package logger
 
 import (
+	"context"
+	"io"
 	"log/slog"
 	"os"
 )
@@ -12,6 +14,11 @@ type Logger struct {
 
 // New creates a new logger with the specified level
 func New(level string) *Logger {
+	return NewWithWriter(level, os.Stdout)
+}
+
+// NewWithWriter creates a new logger with the specified level that writes JSON lines to w
+func NewWithWriter(level string, w io.Writer) *Logger {
 	var logLevel slog.Level
 	switch level {
 	case "debug":
@@ -30,7 +37,7 @@ func New(level string) *Logger {
 		Level: logLevel,
 	}
 
-	handler := slog.NewJSONHandler(os.Stdout, opts)
+	handler := contextHandler{Handler: slog.NewJSONHandler(w, opts)}
 	logger := slog.New(handler)
 
 	return &Logger{Logger: logger}
@@ -41,3 +48,37 @@ func (l *Logger) Fatal(msg string, args ...any) {
 	l.Error(msg, args...)
 	os.Exit(1)
 }
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying a request's correlation ID. Lines logged with that
+// context through the context-taking methods (InfoContext, ErrorContext...) include it as request_id.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the correlation ID carried by ctx, or "" if there is none.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// contextHandler adds the request ID carried by a record's context to the record.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		record.AddAttrs(slog.String("request_id", requestID))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{Handler: h.Handler.WithGroup(name)}
+}
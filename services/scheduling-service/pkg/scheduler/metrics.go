@@ -0,0 +1,79 @@
+package scheduler
+
+import (
+	"sync"
+	"time"
+)
+
+// staleAfterIntervals is how many intervals a job may go without finishing a run before it is reported stale.
+const staleAfterIntervals = 3
+
+// JobStats is a snapshot of a background job's runs, served on /metrics.
+type JobStats struct {
+	Name            string     `json:"name"`
+	IntervalSeconds float64    `json:"intervalSeconds"`
+	Runs            int64      `json:"runs"`
+	Errors          int64      `json:"errors"`
+	LastRunAt       *time.Time `json:"lastRunAt,omitempty"` // Start of the last finished run
+	LastDurationMs  int64      `json:"lastDurationMs"`
+	LastProcessed   int        `json:"lastProcessed"` // Records the last finished run touched
+	ProcessedTotal  int64      `json:"processedTotal"`
+	LastError       string     `json:"lastError,omitempty"` // Error of the last run; cleared by a successful one
+	// RunningSince is the heartbeat of a run in progress: a run that never finishes keeps it in the past.
+	RunningSince *time.Time `json:"runningSince,omitempty"`
+	// Stale is set when no run has finished, or the current one has been running, for staleAfterIntervals intervals.
+	Stale bool `json:"stale"`
+}
+
+// jobMetrics accumulates the runs of one job. Runs of a job may overlap, so it is guarded by a mutex.
+type jobMetrics struct {
+	mu    sync.Mutex
+	stats JobStats
+	since time.Time // Reference for staleness before the first run finishes
+}
+
+func newJobMetrics(name string, interval time.Duration, since time.Time) *jobMetrics {
+	return &jobMetrics{stats: JobStats{Name: name, IntervalSeconds: interval.Seconds()}, since: since}
+}
+
+// begin records the heartbeat of a run starting at startedAt.
+func (m *jobMetrics) begin(startedAt time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stats.RunningSince = &startedAt
+}
+
+// finish records the outcome of the run that started at startedAt.
+func (m *jobMetrics) finish(startedAt time.Time, duration time.Duration, processed int, err error) JobStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stats.Runs++
+	m.stats.LastRunAt = &startedAt
+	m.stats.LastDurationMs = duration.Milliseconds()
+	m.stats.LastProcessed = processed
+	m.stats.ProcessedTotal += int64(processed)
+	m.stats.LastError = ""
+	if err != nil {
+		m.stats.Errors++
+		m.stats.LastError = err.Error()
+	}
+	if m.stats.RunningSince != nil && m.stats.RunningSince.Equal(startedAt) {
+		m.stats.RunningSince = nil
+	}
+	return m.stats
+}
+
+// snapshot returns the job's stats as of now.
+func (m *jobMetrics) snapshot(now time.Time) JobStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats := m.stats
+	staleAfter := time.Duration(staleAfterIntervals * stats.IntervalSeconds * float64(time.Second))
+	lastFinished := m.since
+	if stats.LastRunAt != nil {
+		lastFinished = *stats.LastRunAt
+	}
+	stats.Stale = now.Sub(lastFinished) > staleAfter ||
+		(stats.RunningSince != nil && now.Sub(*stats.RunningSince) > staleAfter)
+	return stats
+}
@@ -0,0 +1,90 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/slotwise/scheduling-service/pkg/clock"
+	"github.com/slotwise/scheduling-service/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func jobNamed(s *Scheduler, name string) *job {
+	for _, j := range s.jobs {
+		if j.name == name {
+			return j
+		}
+	}
+	return nil
+}
+
+func jobStatsNamed(s *Scheduler, name string) JobStats {
+	for _, stats := range s.JobStats() {
+		if stats.Name == name {
+			return stats
+		}
+	}
+	return JobStats{}
+}
+
+func TestRunJob_RecordsFailuresAndHeartbeat(t *testing.T) {
+	fakeClock := clock.NewFake(time.Date(2030, 1, 7, 8, 0, 0, 0, time.UTC))
+	s := New(nil, nil, nil, time.Minute, fakeClock, logger.New("debug"))
+	var alerted []JobStats
+	s.OnJobFailure = func(stats JobStats, err error) { alerted = append(alerted, stats) }
+
+	var duringRun JobStats
+	fail := true
+	s.addJob("test_job", time.Minute, func(context.Context) (int, error) {
+		duringRun = jobStatsNamed(s, "test_job")
+		fakeClock.Advance(2 * time.Second)
+		if fail {
+			return 3, errors.New("database unavailable")
+		}
+		return 5, nil
+	})
+	testJob := jobNamed(s, "test_job")
+
+	s.runJob(context.Background(), testJob)
+	if assert.NotNil(t, duringRun.RunningSince, "a run in progress has a heartbeat") {
+		assert.True(t, duringRun.RunningSince.Equal(time.Date(2030, 1, 7, 8, 0, 0, 0, time.UTC)))
+	}
+	stats := jobStatsNamed(s, "test_job")
+	assert.Equal(t, int64(1), stats.Runs)
+	assert.Equal(t, int64(1), stats.Errors)
+	assert.Equal(t, "database unavailable", stats.LastError)
+	assert.Equal(t, 3, stats.LastProcessed)
+	assert.Equal(t, int64(2000), stats.LastDurationMs)
+	assert.Nil(t, stats.RunningSince)
+	require.Len(t, alerted, 1)
+	assert.Equal(t, "test_job", alerted[0].Name)
+
+	fail = false
+	s.runJob(context.Background(), testJob)
+	stats = jobStatsNamed(s, "test_job")
+	assert.Equal(t, int64(2), stats.Runs)
+	assert.Equal(t, int64(1), stats.Errors)
+	assert.Empty(t, stats.LastError)
+	assert.Equal(t, int64(8), stats.ProcessedTotal)
+	assert.Len(t, alerted, 1, "successful runs do not alert")
+
+	// No run for three intervals
+	assert.False(t, stats.Stale)
+	fakeClock.Advance(3*time.Minute + time.Second)
+	assert.True(t, jobStatsNamed(s, "test_job").Stale)
+}
+
+func TestJobStats_ListsRegisteredJobs(t *testing.T) {
+	s := New(nil, nil, nil, time.Minute, clock.NewFake(time.Now()), logger.New("debug"))
+
+	var names []string
+	for _, stats := range s.JobStats() {
+		names = append(names, stats.Name)
+		assert.Zero(t, stats.Runs)
+		assert.False(t, stats.Stale, "jobs are not stale right after start")
+	}
+	assert.Equal(t, []string{JobExpirePendingBookings, JobRelayOutbox, JobDailyDigests}, names)
+}
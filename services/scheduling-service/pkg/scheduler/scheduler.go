@@ -1,41 +1,122 @@
 package scheduler
 
 import (
+	"context"
+	"time"
+
 	"github.com/robfig/cron/v3"
 	"github.com/slotwise/scheduling-service/internal/service"
+	"github.com/slotwise/scheduling-service/pkg/clock"
 	"github.com/slotwise/scheduling-service/pkg/logger"
 )
 
+// Names of the background jobs, as reported in JobStats.
+const (
+	JobExpirePendingBookings = "expire_pending_bookings"
+	JobRelayOutbox           = "relay_outbox"
+	JobDailyDigests          = "daily_digests"
+)
+
+// job is a background task run every interval; run returns how many records it processed.
+type job struct {
+	name     string
+	interval time.Duration
+	run      func(ctx context.Context) (int, error)
+	metrics  *jobMetrics
+}
+
 // Scheduler handles background scheduling tasks
 type Scheduler struct {
-	cron           *cron.Cron
-	bookingService *service.BookingService
-	logger         *logger.Logger
+	cron                  *cron.Cron
+	bookingService        *service.BookingService
+	outboxRelay           *service.OutboxRelay
+	dailyDigestService    *service.DailyDigestService
+	pendingPaymentTimeout time.Duration // Bookings awaiting payment longer than this are cancelled
+	jobs                  []*job
+	// OnJobFailure, if set, is called after every failed job run, e.g. to page someone.
+	OnJobFailure func(stats JobStats, err error)
+	clock        clock.Clock
+	logger       *logger.Logger
 }
 
-// New creates a new scheduler
-func New(bookingService *service.BookingService, logger *logger.Logger) *Scheduler {
-	return &Scheduler{
-		cron:           cron.New(),
-		bookingService: bookingService,
-		logger:         logger,
+// New creates a new scheduler. A nil clock uses the system clock.
+func New(bookingService *service.BookingService, outboxRelay *service.OutboxRelay, dailyDigestService *service.DailyDigestService, pendingPaymentTimeout time.Duration, clk clock.Clock, logger *logger.Logger) *Scheduler {
+	s := &Scheduler{
+		cron:                  cron.New(),
+		bookingService:        bookingService,
+		outboxRelay:           outboxRelay,
+		dailyDigestService:    dailyDigestService,
+		pendingPaymentTimeout: pendingPaymentTimeout,
+		clock:                 clock.OrReal(clk),
+		logger:                logger,
 	}
+
+	// Cancel bookings whose payment never arrived so their slots become bookable again
+	s.addJob(JobExpirePendingBookings, time.Minute, s.expirePendingBookings)
+	// Relay booking events that were committed but not yet published to NATS
+	s.addJob(JobRelayOutbox, 10*time.Second, func(ctx context.Context) (int, error) {
+		return s.outboxRelay.RelayPendingEvents(ctx)
+	})
+	// Send each business its morning digest; businesses are checked in their own timezone
+	s.addJob(JobDailyDigests, 15*time.Minute, s.sendDailyDigests)
+	return s
+}
+
+// addJob registers a job to run every interval once the scheduler starts.
+func (s *Scheduler) addJob(name string, interval time.Duration, run func(ctx context.Context) (int, error)) {
+	s.jobs = append(s.jobs, &job{name: name, interval: interval, run: run, metrics: newJobMetrics(name, interval, s.clock.Now())})
 }
 
 // Start starts the scheduler
 func (s *Scheduler) Start() {
-	// TODO: Add actual scheduled tasks
 	s.logger.Info("Starting background scheduler")
-	
-	// Example: Add a task that runs every minute
-	s.cron.AddFunc("@every 1m", func() {
-		s.logger.Debug("Running scheduled task")
-		// TODO: Implement actual scheduled tasks
-	})
-	
+
+	for _, j := range s.jobs {
+		j := j
+		if _, err := s.cron.AddFunc("@every "+j.interval.String(), func() { s.runJob(context.Background(), j) }); err != nil {
+			s.logger.Error("Failed to schedule background job", "job", j.name, "error", err)
+		}
+	}
+
 	s.cron.Start()
 }
 
+// runJob runs a job once, recording its heartbeat, outcome and the number of records it processed.
+func (s *Scheduler) runJob(ctx context.Context, j *job) {
+	startedAt := s.clock.Now()
+	j.metrics.begin(startedAt)
+	processed, err := j.run(ctx)
+	stats := j.metrics.finish(startedAt, s.clock.Now().Sub(startedAt), processed, err)
+	if err != nil {
+		s.logger.Error("Background job failed", "job", j.name, "processed", processed, "errors", stats.Errors, "error", err)
+		if s.OnJobFailure != nil {
+			s.OnJobFailure(stats, err)
+		}
+		return
+	}
+	s.logger.Debug("Background job finished", "job", j.name, "processed", processed, "durationMs", stats.LastDurationMs)
+}
+
+// JobStats returns a snapshot of every background job's runs, in registration order.
+func (s *Scheduler) JobStats() []JobStats {
+	now := s.clock.Now()
+	stats := make([]JobStats, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		stats = append(stats, j.metrics.snapshot(now))
+	}
+	return stats
+}
+
+// expirePendingBookings cancels bookings that have awaited payment for longer than the timeout.
+func (s *Scheduler) expirePendingBookings(ctx context.Context) (int, error) {
+	return s.bookingService.ExpirePendingBookings(ctx, s.clock.Now().Add(-s.pendingPaymentTimeout))
+}
+
+// sendDailyDigests issues the daily digest of every business whose morning has begun.
+func (s *Scheduler) sendDailyDigests(ctx context.Context) (int, error) {
+	return s.dailyDigestService.SendDailyDigests(ctx)
+}
+
 // Stop stops the scheduler
 func (s *Scheduler) Stop() {
 	s.logger.Info("Stopping background scheduler")
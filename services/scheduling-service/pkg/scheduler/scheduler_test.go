@@ -0,0 +1,207 @@
+package scheduler
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/slotwise/scheduling-service/internal/models"
+	"github.com/slotwise/scheduling-service/internal/repository"
+	"github.com/slotwise/scheduling-service/internal/service"
+	"github.com/slotwise/scheduling-service/pkg/clock"
+	"github.com/slotwise/scheduling-service/pkg/events"
+	"github.com/slotwise/scheduling-service/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+const testPendingPaymentTimeout = 30 * time.Minute
+
+// recordingPublisher records published subjects
+type recordingPublisher struct {
+	subjects []string
+}
+
+func (p *recordingPublisher) Publish(subject string, data interface{}) error {
+	p.subjects = append(p.subjects, subject)
+	return nil
+}
+
+type SchedulerTestSuite struct {
+	suite.Suite
+	DB        *gorm.DB
+	Clock     *clock.FakeClock
+	Publisher *recordingPublisher
+	Scheduler *Scheduler
+}
+
+func (suite *SchedulerTestSuite) SetupSuite() {
+	dsn := "host=localhost user=postgres password=postgres dbname=slotwise_scheduling_test port=5432 sslmode=disable"
+	if envURL := os.Getenv("TEST_DATABASE_URL"); envURL != "" {
+		dsn = envURL
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		suite.T().Fatalf("Failed to connect to PostgreSQL: %v", err)
+	}
+	suite.DB = db
+	err = suite.DB.AutoMigrate(&models.Business{}, &models.ServiceDefinition{}, &models.AvailabilityVersion{}, &models.Booking{}, &models.OutboxEvent{}, &models.BookingStatusHistory{}, &models.CustomerPreference{}, &models.DailyDigest{})
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *SchedulerTestSuite) TearDownSuite() {
+	sqlDB, _ := suite.DB.DB()
+	sqlDB.Close()
+}
+
+func (suite *SchedulerTestSuite) SetupTest() {
+	suite.DB.Exec("DELETE FROM bookings")
+	suite.DB.Exec("DELETE FROM outbox_events")
+	suite.DB.Exec("DELETE FROM booking_status_history")
+	suite.DB.Exec("DELETE FROM daily_digests")
+	suite.DB.Exec("DELETE FROM businesses")
+	suite.DB.Exec("DELETE FROM availability_versions")
+
+	testLogger := logger.New("debug")
+	suite.Clock = clock.NewFake(time.Now())
+	suite.Publisher = &recordingPublisher{}
+	outboxRepo := repository.NewOutboxRepository(suite.DB)
+	bookingRepo := repository.NewBookingRepository(suite.DB)
+	availabilityRepo := repository.NewAvailabilityRepository(suite.DB)
+	outboxRelay := service.NewOutboxRelay(outboxRepo, suite.Publisher, testLogger)
+	bookingService := service.NewBookingService(
+		bookingRepo,
+		nil,
+		availabilityRepo,
+		repository.NewCustomerPreferenceRepository(suite.DB),
+		outboxRelay,
+		suite.Publisher,
+		nil,
+		suite.Clock,
+		testLogger,
+	)
+	dailyDigestService := service.NewDailyDigestService(availabilityRepo, bookingRepo, outboxRepo, outboxRelay, suite.Clock, testLogger)
+	suite.Scheduler = New(bookingService, outboxRelay, dailyDigestService, testPendingPaymentTimeout, suite.Clock, testLogger)
+}
+
+func (suite *SchedulerTestSuite) TestAdvancingClockExpiresPendingBookings() {
+	t := suite.T()
+	ctx := context.Background()
+	startTime := time.Now().Add(48 * time.Hour)
+	pending := models.Booking{
+		BusinessID: "biz_expiry", ServiceID: "svc_expiry", CustomerID: "cust_expiry",
+		StartTime: startTime, EndTime: startTime.Add(time.Hour), Status: models.BookingStatusPendingPayment,
+	}
+	confirmed := models.Booking{
+		BusinessID: "biz_expiry", ServiceID: "svc_expiry", CustomerID: "cust_paid",
+		StartTime: startTime.Add(2 * time.Hour), EndTime: startTime.Add(3 * time.Hour), Status: models.BookingStatusConfirmed,
+	}
+	suite.DB.Create(&pending)
+	suite.DB.Create(&confirmed)
+
+	// Still within the payment window
+	suite.Clock.Advance(testPendingPaymentTimeout - time.Minute)
+	expired, err := suite.Scheduler.expirePendingBookings(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, expired)
+
+	// Past the payment window
+	suite.Clock.Advance(2 * time.Minute)
+	expired, err = suite.Scheduler.expirePendingBookings(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, expired)
+
+	var dbPending, dbConfirmed models.Booking
+	assert.NoError(t, suite.DB.First(&dbPending, "id = ?", pending.ID).Error)
+	assert.NoError(t, suite.DB.First(&dbConfirmed, "id = ?", confirmed.ID).Error)
+	assert.Equal(t, models.BookingStatusCancelled, dbPending.Status)
+	assert.Equal(t, models.BookingStatusConfirmed, dbConfirmed.Status)
+	assert.Contains(t, suite.Publisher.subjects, events.BookingCancelledEvent)
+}
+
+// digestsByBusiness counts the daily digest events recorded for each business.
+func (suite *SchedulerTestSuite) digestsByBusiness() map[string]int {
+	var digests []models.OutboxEvent
+	suite.DB.Where("subject = ?", events.BusinessDailyDigestEvent).Find(&digests)
+	counts := make(map[string]int)
+	for _, digest := range digests {
+		counts[digest.AggregateID]++
+	}
+	return counts
+}
+
+func (suite *SchedulerTestSuite) TestDailyDigestOncePerBusinessPerDay() {
+	t := suite.T()
+	ctx := context.Background()
+	suite.DB.Create(&models.Business{ID: "biz_digest_utc", Name: "London Cuts", Status: "ACTIVE", Timezone: "Europe/London"})
+	suite.DB.Create(&models.Business{ID: "biz_digest_ny", Name: "Brooklyn Cuts", Status: "ACTIVE", Timezone: "America/New_York"})
+	bookingStart := time.Date(2030, 1, 7, 15, 0, 0, 0, time.UTC)
+	suite.DB.Create(&models.Booking{
+		BusinessID: "biz_digest_ny", ServiceID: "svc_digest", CustomerID: "cust_digest",
+		StartTime: bookingStart, EndTime: bookingStart.Add(time.Hour), Status: models.BookingStatusConfirmed,
+	})
+
+	// 08:00 in London, 03:00 in New York: only London's morning has begun
+	suite.Clock.Set(time.Date(2030, 1, 7, 8, 0, 0, 0, time.UTC))
+	sent, err := suite.Scheduler.sendDailyDigests(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	assert.Equal(t, map[string]int{"biz_digest_utc": 1}, suite.digestsByBusiness())
+
+	// 07:00 in New York; London already had today's digest
+	suite.Clock.Set(time.Date(2030, 1, 7, 12, 0, 0, 0, time.UTC))
+	sent, err = suite.Scheduler.sendDailyDigests(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+
+	// Later runs the same day send nothing
+	suite.Clock.Advance(6 * time.Hour)
+	sent, err = suite.Scheduler.sendDailyDigests(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, sent)
+	assert.Equal(t, map[string]int{"biz_digest_utc": 1, "biz_digest_ny": 1}, suite.digestsByBusiness())
+
+	// The next morning both get a new one
+	suite.Clock.Set(time.Date(2030, 1, 8, 13, 0, 0, 0, time.UTC))
+	sent, err = suite.Scheduler.sendDailyDigests(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, sent)
+	assert.Equal(t, map[string]int{"biz_digest_utc": 2, "biz_digest_ny": 2}, suite.digestsByBusiness())
+
+	var nyDigest models.OutboxEvent
+	assert.NoError(t, suite.DB.Where("subject = ? AND aggregate_id = ?", events.BusinessDailyDigestEvent, "biz_digest_ny").Order("created_at asc").First(&nyDigest).Error)
+	assert.Contains(t, nyDigest.Payload, `"date": "2030-01-07"`)
+	assert.Contains(t, nyDigest.Payload, `"bookingCount": 1`)
+	assert.Contains(t, suite.Publisher.subjects, events.BusinessDailyDigestEvent)
+}
+
+func (suite *SchedulerTestSuite) TestExpiryJobRecordsRunMetrics() {
+	t := suite.T()
+	startTime := time.Now().Add(48 * time.Hour)
+	suite.DB.Create(&models.Booking{
+		BusinessID: "biz_job", ServiceID: "svc_job", CustomerID: "cust_job",
+		StartTime: startTime, EndTime: startTime.Add(time.Hour), Status: models.BookingStatusPendingPayment,
+	})
+	suite.Clock.Advance(testPendingPaymentTimeout + time.Minute)
+	ranAt := suite.Clock.Now()
+
+	suite.Scheduler.runJob(context.Background(), jobNamed(suite.Scheduler, JobExpirePendingBookings))
+
+	stats := jobStatsNamed(suite.Scheduler, JobExpirePendingBookings)
+	if assert.NotNil(t, stats.LastRunAt) {
+		assert.True(t, ranAt.Equal(*stats.LastRunAt))
+	}
+	assert.Equal(t, int64(1), stats.Runs)
+	assert.Equal(t, 1, stats.LastProcessed)
+	assert.Equal(t, int64(1), stats.ProcessedTotal)
+	assert.Equal(t, int64(0), stats.Errors)
+	assert.Nil(t, stats.RunningSince)
+	assert.False(t, stats.Stale)
+}
+
+func TestSchedulerTestSuite(t *testing.T) {
+	suite.Run(t, new(SchedulerTestSuite))
+}
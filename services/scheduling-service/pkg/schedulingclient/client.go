@@ -0,0 +1,273 @@
+// Package schedulingclient is a typed client for other services calling the scheduling service's HTTP API.
+package schedulingclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout bounds each request made by a client created without its own http.Client.
+const DefaultTimeout = 10 * time.Second
+
+// Client calls the scheduling service.
+type Client struct {
+	httpClient  *http.Client
+	baseURL     string
+	bearerToken string
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithBearerToken sends token as a bearer token with every request. Creating and listing bookings
+// require one: a customer's access token, or the business owner's for guest bookings.
+func WithBearerToken(token string) Option {
+	return func(c *Client) {
+		c.bearerToken = token
+	}
+}
+
+// New creates a client for the scheduling service at baseURL, e.g. "http://scheduling-service:8080".
+// A nil httpClient uses one with DefaultTimeout.
+func New(baseURL string, httpClient *http.Client, opts ...Option) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: DefaultTimeout}
+	}
+	c := &Client{httpClient: httpClient, baseURL: strings.TrimRight(baseURL, "/")}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Slot is a bookable time slot.
+type Slot struct {
+	StartTime      time.Time `json:"startTime"`
+	EndTime        time.Time `json:"endTime"`
+	Available      bool      `json:"available"`
+	ConflictReason string    `json:"conflictReason,omitempty"`
+}
+
+// SlotsResponse lists the slots of a service on one day.
+type SlotsResponse struct {
+	Slots               []Slot    `json:"slots"`
+	LastUpdated         time.Time `json:"lastUpdated"`
+	AvailabilityVersion int64     `json:"availabilityVersion"` // Changes whenever the business's rules or bookings do
+	Truncated           bool      `json:"truncated"`           // Set when only the first slots of the day were returned
+}
+
+// GuestContact holds the contact details of a guest booked without an account.
+type GuestContact struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Phone string `json:"phone,omitempty"`
+}
+
+// CreateBookingRequest is the payload for CreateBooking. Either CustomerID or Guest is required.
+type CreateBookingRequest struct {
+	BusinessID       string                 `json:"businessId"`
+	ServiceID        string                 `json:"serviceId"`
+	CustomerID       string                 `json:"customerId,omitempty"`
+	StartTime        time.Time              `json:"startTime"`
+	CustomerLanguage string                 `json:"customerLanguage,omitempty"`
+	Metadata         map[string]interface{} `json:"metadata,omitempty"`
+	HoldID           string                 `json:"holdId,omitempty"`
+	Guest            *GuestContact          `json:"guest,omitempty"`
+	DryRun           bool                   `json:"dryRun,omitempty"` // Validate only; the returned booking is not saved and has no ID
+}
+
+// Booking is a booking as returned by the scheduling service.
+type Booking struct {
+	ID               string                 `json:"id"`
+	BusinessID       string                 `json:"businessId"`
+	ServiceID        string                 `json:"serviceId"`
+	CustomerID       string                 `json:"customerId"`
+	StartTime        time.Time              `json:"startTime"`
+	EndTime          time.Time              `json:"endTime"`
+	Status           string                 `json:"status"` // e.g. "PENDING_PAYMENT", "CONFIRMED"
+	SeriesID         *string                `json:"seriesId,omitempty"`
+	TotalAmount      *int64                 `json:"totalAmount,omitempty"` // In the currency's minor units, e.g. cents
+	Currency         string                 `json:"currency"`
+	Metadata         map[string]interface{} `json:"metadata,omitempty"`
+	CustomerLanguage string                 `json:"customerLanguage"`
+	GuestName        *string                `json:"guestName,omitempty"`
+	GuestEmail       *string                `json:"guestEmail,omitempty"`
+	GuestPhone       *string                `json:"guestPhone,omitempty"`
+	ServiceName      string                 `json:"serviceName,omitempty"`
+	CreatedAt        time.Time              `json:"createdAt"`
+	UpdatedAt        time.Time              `json:"updatedAt"`
+}
+
+// CalendarDay summarises the slots of one day.
+type CalendarDay struct {
+	Date           string  `json:"date"` // "YYYY-MM-DD"
+	TotalSlots     int     `json:"totalSlots"`
+	BookedSlots    int     `json:"bookedSlots"`
+	AvailableSlots int     `json:"availableSlots"`
+	Utilization    float64 `json:"utilization"`
+}
+
+// CalendarPeriod rolls up the days of one ISO week or calendar month.
+type CalendarPeriod struct {
+	Period         string  `json:"period"` // "2024-W10" for weeks, "2024-03" for months
+	StartDate      string  `json:"startDate"`
+	EndDate        string  `json:"endDate"`
+	TotalSlots     int     `json:"totalSlots"`
+	BookedSlots    int     `json:"bookedSlots"`
+	AvailableSlots int     `json:"availableSlots"`
+	Utilization    float64 `json:"utilization"`
+}
+
+// CalendarService is an entry in a calendar's service legend.
+type CalendarService struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Color      string `json:"color,omitempty"`
+	ShortLabel string `json:"shortLabel,omitempty"`
+}
+
+// Calendar summarises a business's slots over a date range.
+type Calendar struct {
+	BusinessID  string            `json:"businessId"`
+	StartDate   string            `json:"startDate"`
+	EndDate     string            `json:"endDate"`
+	Granularity string            `json:"granularity"`
+	Days        []CalendarDay     `json:"days"`
+	Periods     []CalendarPeriod  `json:"periods,omitempty"`
+	Services    []CalendarService `json:"services"`
+}
+
+// Calendar granularities accepted by GetCalendar.
+const (
+	GranularityDay   = "day"
+	GranularityWeek  = "week"
+	GranularityMonth = "month"
+)
+
+// APIError is returned when the scheduling service answers with an error status.
+type APIError struct {
+	StatusCode int
+	Message    string
+	// Code says why a booking could not be created, e.g. "CONFLICT" or "BUSINESS_PAUSED"; empty for other errors.
+	Code string
+	// Set on booking conflicts (409): the clashing time and, if one was found, the nearest open start time.
+	ConflictStart      *time.Time
+	ConflictEnd        *time.Time
+	SuggestedStartTime *time.Time
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("scheduling service returned status %d: %s", e.StatusCode, e.Message)
+}
+
+// errorResponse is the error body of the scheduling API.
+type errorResponse struct {
+	Error    string `json:"error"`
+	Code     string `json:"code"`
+	Conflict *struct {
+		StartTime time.Time `json:"startTime"`
+		EndTime   time.Time `json:"endTime"`
+	} `json:"conflict"`
+	SuggestedStartTime *time.Time `json:"suggestedStartTime"`
+}
+
+// GetSlots lists the slots of a service on date's calendar day.
+func (c *Client) GetSlots(ctx context.Context, businessID, serviceID string, date time.Time) (*SlotsResponse, error) {
+	query := url.Values{"businessId": {businessID}, "date": {date.Format("2006-01-02")}}
+	var slots SlotsResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/services/"+url.PathEscape(serviceID)+"/slots", query, nil, &slots); err != nil {
+		return nil, err
+	}
+	return &slots, nil
+}
+
+// CreateBooking books a slot, or with req.DryRun only checks that it could be booked. It needs a client
+// created WithBearerToken; without one the request fails with an *APIError with status 401.
+// A conflicting booking fails with an *APIError with status 409, code "CONFLICT" and the conflict details;
+// other unbookable slots carry their own code.
+func (c *Client) CreateBooking(ctx context.Context, req CreateBookingRequest) (*Booking, error) {
+	var booking Booking
+	if err := c.do(ctx, http.MethodPost, "/api/v1/bookings", nil, req, &booking); err != nil {
+		return nil, err
+	}
+	return &booking, nil
+}
+
+// GetCalendar summarises a business's slots from start to end, both inclusive. An empty granularity means days.
+func (c *Client) GetCalendar(ctx context.Context, businessID string, start, end time.Time, granularity string) (*Calendar, error) {
+	query := url.Values{"start": {start.Format("2006-01-02")}, "end": {end.Format("2006-01-02")}}
+	if granularity != "" {
+		query.Set("granularity", granularity)
+	}
+	var calendar Calendar
+	if err := c.do(ctx, http.MethodGet, "/api/v1/businesses/"+url.PathEscape(businessID)+"/calendar", query, nil, &calendar); err != nil {
+		return nil, err
+	}
+	return &calendar, nil
+}
+
+// do sends a request with body as JSON, if not nil, and decodes a successful response into out.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	if c.baseURL == "" {
+		return fmt.Errorf("scheduling service URL is not configured")
+	}
+
+	endpoint := c.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	var reqBody io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reqBody = bytes.NewReader(payload)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, endpoint, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if c.bearerToken != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.bearerToken)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("request to scheduling service failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		apiErr := &APIError{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+		var errResp errorResponse
+		if json.NewDecoder(resp.Body).Decode(&errResp) == nil {
+			if errResp.Error != "" {
+				apiErr.Message = errResp.Error
+			}
+			apiErr.Code = errResp.Code
+			if errResp.Conflict != nil {
+				apiErr.ConflictStart = &errResp.Conflict.StartTime
+				apiErr.ConflictEnd = &errResp.Conflict.EndTime
+			}
+			apiErr.SuggestedStartTime = errResp.SuggestedStartTime
+		}
+		return apiErr
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w (status: %d)", err, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package schedulingclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/slotwise/scheduling-service/pkg/schedulingclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSlots(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/api/v1/services/svc-1/slots", r.URL.Path)
+		assert.Equal(t, "biz-1", r.URL.Query().Get("businessId"))
+		assert.Equal(t, "2024-03-11", r.URL.Query().Get("date"))
+		assert.Empty(t, r.Header.Get("Authorization"), "No token is sent unless one was given")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"slots": [
+				{"startTime": "2024-03-11T09:00:00Z", "endTime": "2024-03-11T09:30:00Z", "available": true},
+				{"startTime": "2024-03-11T09:30:00Z", "endTime": "2024-03-11T10:00:00Z", "available": false, "conflictReason": "booked"}
+			],
+			"lastUpdated": "2024-03-10T12:00:00Z",
+			"availabilityVersion": 7,
+			"truncated": false
+		}`))
+	}))
+	defer server.Close()
+
+	client := schedulingclient.New(server.URL+"/", nil)
+	date := time.Date(2024, 3, 11, 15, 0, 0, 0, time.UTC)
+
+	resp, err := client.GetSlots(context.Background(), "biz-1", "svc-1", date)
+	require.NoError(t, err)
+	require.Len(t, resp.Slots, 2)
+	assert.True(t, resp.Slots[0].Available)
+	assert.Equal(t, time.Date(2024, 3, 11, 9, 0, 0, 0, time.UTC), resp.Slots[0].StartTime)
+	assert.Equal(t, "booked", resp.Slots[1].ConflictReason)
+	assert.Equal(t, int64(7), resp.AvailabilityVersion)
+	assert.Equal(t, time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC), resp.LastUpdated)
+}
+
+func TestCreateBooking(t *testing.T) {
+	start := time.Date(2024, 3, 11, 9, 0, 0, 0, time.UTC)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/v1/bookings", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "Bearer owner-token", r.Header.Get("Authorization"))
+
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "biz-1", body["businessId"])
+		assert.Equal(t, "svc-1", body["serviceId"])
+		assert.Equal(t, "2024-03-11T09:00:00Z", body["startTime"])
+		assert.Equal(t, map[string]interface{}{"name": "Guest", "email": "guest@example.com"}, body["guest"])
+		assert.NotContains(t, body, "customerId")
+		assert.NotContains(t, body, "dryRun")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{
+			"id": "booking-1",
+			"businessId": "biz-1",
+			"serviceId": "svc-1",
+			"customerId": "",
+			"startTime": "2024-03-11T09:00:00Z",
+			"endTime": "2024-03-11T09:30:00Z",
+			"status": "PENDING_PAYMENT",
+			"totalAmount": 5000,
+			"currency": "USD",
+			"guestEmail": "guest@example.com"
+		}`))
+	}))
+	defer server.Close()
+
+	client := schedulingclient.New(server.URL, nil, schedulingclient.WithBearerToken("owner-token"))
+	booking, err := client.CreateBooking(context.Background(), schedulingclient.CreateBookingRequest{
+		BusinessID: "biz-1",
+		ServiceID:  "svc-1",
+		StartTime:  start,
+		Guest:      &schedulingclient.GuestContact{Name: "Guest", Email: "guest@example.com"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "booking-1", booking.ID)
+	assert.Equal(t, "PENDING_PAYMENT", booking.Status)
+	assert.Equal(t, start.Add(30*time.Minute), booking.EndTime)
+	require.NotNil(t, booking.TotalAmount)
+	assert.Equal(t, int64(5000), *booking.TotalAmount)
+	require.NotNil(t, booking.GuestEmail)
+	assert.Equal(t, "guest@example.com", *booking.GuestEmail)
+}
+
+func TestCreateBooking_Conflict(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{
+			"error": "booking conflict: time slot not available",
+			"code": "CONFLICT",
+			"conflict": {"startTime": "2024-03-11T09:00:00Z", "endTime": "2024-03-11T09:30:00Z"},
+			"suggestedStartTime": "2024-03-11T09:30:00Z"
+		}`))
+	}))
+	defer server.Close()
+
+	client := schedulingclient.New(server.URL, nil)
+	booking, err := client.CreateBooking(context.Background(), schedulingclient.CreateBookingRequest{
+		BusinessID: "biz-1",
+		ServiceID:  "svc-1",
+		CustomerID: "cust-1",
+		StartTime:  time.Date(2024, 3, 11, 9, 0, 0, 0, time.UTC),
+	})
+	assert.Nil(t, booking)
+
+	var apiErr *schedulingclient.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusConflict, apiErr.StatusCode)
+	assert.Equal(t, "booking conflict: time slot not available", apiErr.Message)
+	assert.Equal(t, "CONFLICT", apiErr.Code)
+	require.NotNil(t, apiErr.ConflictStart)
+	assert.Equal(t, time.Date(2024, 3, 11, 9, 0, 0, 0, time.UTC), *apiErr.ConflictStart)
+	require.NotNil(t, apiErr.SuggestedStartTime)
+	assert.Equal(t, time.Date(2024, 3, 11, 9, 30, 0, 0, time.UTC), *apiErr.SuggestedStartTime)
+}
+
+func TestGetCalendar(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/api/v1/businesses/biz-1/calendar", r.URL.Path)
+		assert.Equal(t, "2024-03-11", r.URL.Query().Get("start"))
+		assert.Equal(t, "2024-03-17", r.URL.Query().Get("end"))
+		assert.Equal(t, "week", r.URL.Query().Get("granularity"))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"businessId": "biz-1",
+			"startDate": "2024-03-11",
+			"endDate": "2024-03-17",
+			"granularity": "week",
+			"days": [{"date": "2024-03-11", "totalSlots": 16, "bookedSlots": 4, "availableSlots": 12, "utilization": 0.25}],
+			"periods": [{"period": "2024-W11", "startDate": "2024-03-11", "endDate": "2024-03-17", "totalSlots": 16, "bookedSlots": 4, "availableSlots": 12, "utilization": 0.25}],
+			"services": [{"id": "svc-1", "name": "Haircut", "color": "#ff0000"}]
+		}`))
+	}))
+	defer server.Close()
+
+	client := schedulingclient.New(server.URL, nil)
+	calendar, err := client.GetCalendar(context.Background(), "biz-1",
+		time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 17, 0, 0, 0, 0, time.UTC),
+		schedulingclient.GranularityWeek)
+	require.NoError(t, err)
+	require.Len(t, calendar.Days, 1)
+	assert.Equal(t, 4, calendar.Days[0].BookedSlots)
+	assert.Equal(t, 0.25, calendar.Days[0].Utilization)
+	require.Len(t, calendar.Periods, 1)
+	assert.Equal(t, "2024-W11", calendar.Periods[0].Period)
+	require.Len(t, calendar.Services, 1)
+	assert.Equal(t, "Haircut", calendar.Services[0].Name)
+}
+
+func TestClient_ErrorWithoutJSONBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte("upstream unavailable"))
+	}))
+	defer server.Close()
+
+	client := schedulingclient.New(server.URL, nil)
+	_, err := client.GetSlots(context.Background(), "biz-1", "svc-1", time.Now())
+
+	var apiErr *schedulingclient.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+	assert.Equal(t, "Bad Gateway", apiErr.Message)
+}
+
+func TestClient_RespectsContextDeadline(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := schedulingclient.New(server.URL, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := client.GetSlots(ctx, "biz-1", "svc-1", time.Now())
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestClient_MissingBaseURL(t *testing.T) {
+	client := schedulingclient.New("", nil)
+	_, err := client.GetCalendar(context.Background(), "biz-1", time.Now(), time.Now(), "")
+	assert.Error(t, err)
+}